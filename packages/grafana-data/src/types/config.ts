@@ -4,6 +4,7 @@ import { GrafanaTheme } from './theme';
 import { SystemDateFormatSettings } from '../datetime';
 import { GrafanaTheme2 } from '../themes';
 import { MapLayerOptions } from '../geo/layer';
+import { PluginAdvisory, PluginError } from './plugin';
 
 /**
  * Describes the build information that will be available via the Grafana configuration.
@@ -69,6 +70,22 @@ export interface LicenseInfo {
   edition: GrafanaEdition;
 }
 
+/**
+ * Describes the branding configured for the current running instance of Grafana.
+ *
+ * @public
+ */
+export interface BrandingSettings {
+  appTitle: string;
+  loginTitle: string;
+  loginLogo: string;
+  loginBackground: string;
+  menuLogo: string;
+  favIcon: string;
+  appleTouchIcon: string;
+  footerLinks: Array<{ text: string; url: string; icon?: string }>;
+}
+
 /**
  * Describes Sentry integration config
  *
@@ -87,7 +104,9 @@ export interface SentryConfig {
  * @public
  */
 export interface GrafanaConfig {
+  /** @deprecated use datasourcesByUid, data source names are not unique across renames */
   datasources: { [str: string]: DataSourceInstanceSettings };
+  datasourcesByUid: { [uid: string]: DataSourceInstanceSettings };
   panels: { [key: string]: PanelPluginMeta };
   minRefreshInterval: string;
   appSubUrl: string;
@@ -107,6 +126,7 @@ export interface GrafanaConfig {
   alertingMinInterval: number;
   authProxyEnabled: boolean;
   exploreEnabled: boolean;
+  queryHistoryEnabled: boolean;
   ldapEnabled: boolean;
   sigV4AuthEnabled: boolean;
   samlEnabled: boolean;
@@ -125,8 +145,13 @@ export interface GrafanaConfig {
   theme: GrafanaTheme;
   theme2: GrafanaTheme2;
   pluginsToPreload: string[];
+  pluginErrors: PluginError[];
+  pluginAdvisories: Record<string, PluginAdvisory[]>;
+  pluginAssetVersions?: Record<string, string>;
+  csrfToken: string;
   featureToggles: FeatureToggles;
   licenseInfo: LicenseInfo;
+  branding: BrandingSettings;
   http2Enabled: boolean;
   dateFormats?: SystemDateFormatSettings;
   sentry: SentryConfig;
@@ -36,5 +36,5 @@ export * from './live';
 export * from './variables';
 export * from './geometry';
 export { isUnsignedPluginSignature } from './pluginSignature';
-export { GrafanaConfig, BuildInfo, FeatureToggles, LicenseInfo } from './config';
+export { GrafanaConfig, BuildInfo, FeatureToggles, LicenseInfo, BrandingSettings } from './config';
 export * from './alerts';
@@ -41,6 +41,9 @@ export enum PluginErrorCode {
   missingSignature = 'signatureMissing',
   invalidSignature = 'signatureInvalid',
   modifiedSignature = 'signatureModified',
+  loadingFailed = 'loadingFailed',
+  incompatibleGrafanaVersion = 'incompatibleGrafanaVersion',
+  angularNotSupported = 'angularNotSupported',
 }
 
 /** Describes error returned from Grafana plugins API call */
@@ -49,6 +52,17 @@ export interface PluginError {
   pluginId: string;
 }
 
+/** Describes a security advisory affecting the installed version of a plugin */
+export interface PluginAdvisory {
+  id: string;
+  pluginId: string;
+  affectedVersions: string;
+  fixedVersion?: string;
+  severity: string;
+  summary: string;
+  url?: string;
+}
+
 export interface PluginMeta<T extends KeyValue = {}> {
   id: string;
   name: string;
@@ -65,6 +79,7 @@ export interface PluginMeta<T extends KeyValue = {}> {
   dependencies?: PluginDependencies;
 
   // Filled in by the backend
+  dependencyStatus?: PluginDependencyStatus;
   jsonData?: T;
   secureJsonData?: KeyValue;
   enabled?: boolean;
@@ -77,6 +92,12 @@ export interface PluginMeta<T extends KeyValue = {}> {
   signatureType?: PluginSignatureType;
   signatureOrg?: string;
   live?: boolean;
+  /** Feature toggles enabled for the plugin on this instance, with the [plugin.<id>] feature_toggles setting */
+  featureToggles?: string[];
+  /** Locales the plugin has translations for, served from public/plugins/<id>/locales */
+  locales?: string[];
+  /** Subresource Integrity hash of the module of the plugin, verified when loading it */
+  moduleIntegrity?: string;
 }
 
 interface PluginDependencyInfo {
@@ -92,6 +113,11 @@ export interface PluginDependencies {
   plugins: PluginDependencyInfo[];
 }
 
+export interface PluginDependencyStatus {
+  grafanaVersionSatisfied: boolean;
+  unsatisfiedPlugins: PluginDependencyInfo[];
+}
+
 export enum PluginIncludeType {
   dashboard = 'dashboard',
   page = 'page',
@@ -108,6 +134,7 @@ export interface PluginInclude {
   icon?: string;
 
   role?: string; // "Viewer", Admin, editor???
+  action?: string; // access control action required instead of the role
   addToNav?: boolean; // Show in the sidebar... only if type=page?
 
   // Angular app pages
@@ -1,8 +1,10 @@
 import { merge } from 'lodash';
 import {
+  BrandingSettings,
   BuildInfo,
   createTheme,
   DataSourceInstanceSettings,
+  DataSourcePluginMeta,
   FeatureToggles,
   GrafanaConfig,
   GrafanaTheme,
@@ -10,6 +12,8 @@ import {
   LicenseInfo,
   MapLayerOptions,
   PanelPluginMeta,
+  PluginAdvisory,
+  PluginError,
   systemDateFormats,
   SystemDateFormatSettings,
 } from '@grafana/data';
@@ -21,6 +25,7 @@ export interface AzureSettings {
 
 export class GrafanaBootConfig implements GrafanaConfig {
   datasources: { [str: string]: DataSourceInstanceSettings } = {};
+  datasourcesByUid: { [uid: string]: DataSourceInstanceSettings } = {};
   panels: { [key: string]: PanelPluginMeta } = {};
   minRefreshInterval = '';
   appUrl = '';
@@ -41,6 +46,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
   alertingMinInterval = 1;
   authProxyEnabled = false;
   exploreEnabled = false;
+  queryHistoryEnabled = false;
   ldapEnabled = false;
   sigV4AuthEnabled = false;
   samlEnabled = false;
@@ -60,6 +66,10 @@ export class GrafanaBootConfig implements GrafanaConfig {
   theme: GrafanaTheme;
   theme2: GrafanaTheme2;
   pluginsToPreload: string[] = [];
+  pluginErrors: PluginError[] = [];
+  pluginAdvisories: Record<string, PluginAdvisory[]> = {};
+  pluginAssetVersions?: Record<string, string>;
+  csrfToken = '';
   featureToggles: FeatureToggles = {
     accesscontrol: false,
     trimDefaults: false,
@@ -71,6 +81,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
     fullRangeLogsVolume: false,
   };
   licenseInfo: LicenseInfo = {} as LicenseInfo;
+  branding: BrandingSettings = {} as BrandingSettings;
   rendererAvailable = false;
   rendererVersion = '';
   http2Enabled = false;
@@ -107,6 +118,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
 
     const defaults = {
       datasources: {},
+      datasourcesByUid: {},
       windowTitlePrefix: 'Grafana - ',
       panels: {},
       newPanelTitle: 'Panel Title',
@@ -125,6 +137,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
       disableSanitizeHtml: false,
     };
 
+    resolveDataSourcePluginMeta(options);
     merge(this, defaults, options);
 
     if (this.dateFormats) {
@@ -133,6 +146,27 @@ export class GrafanaBootConfig implements GrafanaConfig {
   }
 }
 
+/**
+ * Data sources in the frontend settings reference their plugin meta by plugin ID instead of embedding it, to keep
+ * the payload small. This sets the meta of each data source from the pluginMeta map of the settings.
+ *
+ * @internal
+ */
+export function resolveDataSourcePluginMeta(settings: {
+  datasources?: Record<string, DataSourceInstanceSettings & { pluginId?: string }>;
+  datasourcesByUid?: Record<string, DataSourceInstanceSettings & { pluginId?: string }>;
+  pluginMeta?: Record<string, DataSourcePluginMeta>;
+}) {
+  const pluginMeta = settings.pluginMeta ?? {};
+  for (const dataSources of [settings.datasources, settings.datasourcesByUid]) {
+    for (const ds of Object.values(dataSources ?? {})) {
+      if (!ds.meta && ds.pluginId && pluginMeta[ds.pluginId]) {
+        ds.meta = pluginMeta[ds.pluginId];
+      }
+    }
+  }
+}
+
 const bootData = (window as any).grafanaBootData || {
   settings: {},
   user: {},
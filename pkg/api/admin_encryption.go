@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+// POST /api/admin/encryption/rotate-data-keys
+func (hs *HTTPServer) AdminRotateDataKeys(c *models.ReqContext) response.Response {
+	if err := hs.SecretsService.RotateDataKeys(c.Req.Context()); err != nil {
+		if errors.Is(err, secretsManager.ErrReEncryptionInProgress) {
+			return response.Error(http.StatusConflict, "Secrets are being re-encrypted, try again once it's finished", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to rotate data keys", err)
+	}
+
+	return response.JSON(http.StatusAccepted, hs.SecretsService.ReEncryptionStatus())
+}
+
+// GET /api/admin/encryption/re-encryption
+func (hs *HTTPServer) AdminGetReEncryptionStatus(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.SecretsService.ReEncryptionStatus())
+}
+
+// GET /api/admin/encryption/data-keys
+func (hs *HTTPServer) AdminGetDataKeys(c *models.ReqContext) response.Response {
+	inventory, err := hs.SecretsService.DataKeyInventory(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list data keys", err)
+	}
+
+	return response.JSON(http.StatusOK, inventory)
+}
+
+// GET /api/admin/encryption/expiring-secrets
+func (hs *HTTPServer) AdminGetExpiringSecrets(c *models.ReqContext) response.Response {
+	within := hs.Cfg.SecretsExpiryWarningPeriod
+	if value := c.Query("within"); value != "" {
+		var err error
+		if within, err = time.ParseDuration(value); err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid within duration", err)
+		}
+	}
+
+	expiring, err := hs.SecretExpiry.ExpiringSecrets(c.Req.Context(), within)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list expiring secrets", err)
+	}
+
+	return response.JSON(http.StatusOK, expiring)
+}
+
+// GET /api/admin/encryption/export
+func (hs *HTTPServer) AdminExportSecrets(c *models.ReqContext) response.Response {
+	bundle, skipped, err := hs.SecretsService.ExportSecrets(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export secrets", err)
+	}
+	if skipped > 0 {
+		hs.log.Warn("Secrets encrypted with the secret key were not exported", "rows", skipped)
+	}
+
+	return response.JSON(http.StatusOK, bundle)
+}
+
+// POST /api/admin/encryption/import
+func (hs *HTTPServer) AdminImportSecrets(c *models.ReqContext, bundle secrets.SecretsBundle) response.Response {
+	result, err := hs.SecretsService.ImportSecrets(c.Req.Context(), &bundle)
+	if err != nil {
+		if errors.Is(err, secretsManager.ErrInvalidSecretsBundle) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to import secrets", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
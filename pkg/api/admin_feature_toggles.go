@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/admin/feature-toggles
+func (hs *HTTPServer) AdminGetFeatureToggles(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, dtos.FeatureToggles{Toggles: hs.FeatureToggles.GetToggles()})
+}
+
+// PATCH /api/admin/feature-toggles
+func (hs *HTTPServer) AdminUpdateFeatureToggles(c *models.ReqContext, cmd dtos.FeatureToggles) response.Response {
+	if len(cmd.Toggles) == 0 {
+		return response.Error(http.StatusBadRequest, "No feature toggles provided", nil)
+	}
+
+	hs.FeatureToggles.SetToggles(cmd.Toggles)
+	return response.Success("Feature toggles updated")
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// POST /api/admin/plugins/:id/restart
+func (hs *HTTPServer) AdminRestartPlugin(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":id"]
+
+	restarter, ok := hs.BackendPluginManager.(backendplugin.Restarter)
+	if !ok {
+		return response.Error(http.StatusNotImplemented, "Restarting plugins is not supported", nil)
+	}
+
+	if err := restarter.RestartPlugin(c.Req.Context(), pluginID); err != nil {
+		if errors.Is(err, backendplugin.ErrPluginNotRegistered) {
+			return response.Error(http.StatusNotFound, "Backend plugin not found", err)
+		}
+		if errors.Is(err, backendplugin.ErrPluginNotManaged) {
+			return response.Error(http.StatusBadRequest, "Backend plugin is not managed by Grafana and cannot be restarted", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to restart backend plugin", err)
+	}
+
+	return response.Success("Plugin restarted")
+}
+
+// GET /api/admin/health/plugins
+func (hs *HTTPServer) AdminGetPluginsHealth(c *models.ReqContext) response.Response {
+	reporter, ok := hs.BackendPluginManager.(backendplugin.StatusReporter)
+	if !ok {
+		return response.Error(http.StatusNotImplemented, "Plugin health is not supported", nil)
+	}
+
+	result := dtos.PluginsHealth{Status: "ok", Plugins: []dtos.PluginHealth{}}
+	for pluginID, status := range reporter.PluginStatuses() {
+		health := dtos.PluginHealth{
+			Id:                  pluginID,
+			Managed:             status.Managed,
+			Running:             status.Running,
+			Restarts:            status.Failures.Restarts,
+			Unavailable:         status.Failures.Unavailable,
+			HealthCheckFailures: status.Failures.HealthCheckFailures,
+			DataSources:         []dtos.DataSourceHealth{},
+		}
+		if p := hs.PluginManager.GetPlugin(pluginID); p != nil {
+			health.Name = p.Name
+			health.Type = p.Type
+			health.Signature = p.Signature
+			health.SignatureType = p.SignatureType
+		}
+
+		degraded := status.Managed && !status.Running
+		if health.Signature == plugins.PluginSignatureInvalid || health.Signature == plugins.PluginSignatureModified {
+			degraded = true
+		}
+		for uid, ds := range status.DataSources {
+			health.DataSources = append(health.DataSources, dtos.DataSourceHealth{
+				Uid:       uid,
+				Name:      ds.Name,
+				Status:    ds.Status.String(),
+				Message:   ds.Message,
+				CheckedAt: ds.CheckedAt,
+			})
+			if ds.Status == backend.HealthStatusError {
+				degraded = true
+			}
+		}
+		sort.Slice(health.DataSources, func(i, j int) bool { return health.DataSources[i].Name < health.DataSources[j].Name })
+
+		if degraded {
+			result.Status = "degraded"
+		}
+		result.Plugins = append(result.Plugins, health)
+	}
+	sort.Slice(result.Plugins, func(i, j int) bool { return result.Plugins[i].Id < result.Plugins[j].Id })
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// GET /api/admin/plugins/disk-usage
+func (hs *HTTPServer) AdminGetPluginsDiskUsage(_ *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.PluginManager.DiskUsage())
+}
+
+// GET /api/admin/plugins/:id/install-report
+func (hs *HTTPServer) AdminGetPluginInstallReport(c *models.ReqContext) response.Response {
+	report, exists := hs.PluginManager.InstallReport(web.Params(c.Req)[":id"])
+	if !exists {
+		return response.Error(http.StatusNotFound, "No install report for the plugin", nil)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// AdminGetPluginRoles returns the roles declared by the plugin, and whether they're registered, i.e. granted to the
+// users with the basic roles they're granted to. The roles aren't registered when access control is disabled.
+//
+// GET /api/admin/plugins/:id/roles
+func (hs *HTTPServer) AdminGetPluginRoles(c *models.ReqContext) response.Response {
+	plugin := hs.PluginManager.GetPlugin(web.Params(c.Req)[":id"])
+	if plugin == nil {
+		return response.Error(http.StatusNotFound, "Plugin not found", nil)
+	}
+
+	roles := make([]dtos.PluginRole, 0, len(plugin.Roles))
+	for _, registration := range plugin.Roles {
+		name := plugins.PluginRoleName(plugin.Id, registration.Role.Name)
+		_, registered := accesscontrol.FixedRoles[name]
+		roles = append(roles, dtos.PluginRole{
+			Name:        name,
+			DisplayName: registration.Role.DisplayName,
+			Description: registration.Role.Description,
+			Permissions: registration.Role.Permissions,
+			Grants:      registration.Grants,
+			Registered:  registered && !hs.AccessControl.IsDisabled(),
+		})
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+// GET /api/admin/plugins/:id/config
+func (hs *HTTPServer) AdminGetPluginConfig(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":id"]
+
+	inspector, ok := hs.BackendPluginManager.(backendplugin.ConfigInspector)
+	if !ok {
+		return response.Error(http.StatusNotImplemented, "Inspecting plugin configuration is not supported", nil)
+	}
+
+	config, err := inspector.PluginConfig(pluginID)
+	if err != nil {
+		if errors.Is(err, backendplugin.ErrPluginNotRegistered) {
+			return response.Error(http.StatusNotFound, "Backend plugin not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get backend plugin configuration", err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.PluginConfig{
+		Id:       pluginID,
+		Env:      config.Env,
+		Settings: config.Settings,
+	})
+}
+
+// maxPluginBackupUploadSize bounds the size of the plugin backups uploaded to be restored.
+const maxPluginBackupUploadSize = 2 << 30
+
+// AdminBackupPlugins returns a gzipped tarball of the installed external plugins, with their versions, and of the
+// plugin settings of all organizations.
+//
+// GET /api/admin/plugins/backup
+func (hs *HTTPServer) AdminBackupPlugins(c *models.ReqContext) {
+	backup, err := os.CreateTemp("", "plugin-backup-*.tar.gz")
+	if err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to back up plugins", err)
+		return
+	}
+	defer func() {
+		if err := backup.Close(); err != nil {
+			hs.log.Warn("Failed to close plugin backup", "path", backup.Name(), "error", err)
+		}
+		if err := os.Remove(backup.Name()); err != nil {
+			hs.log.Warn("Failed to remove plugin backup", "path", backup.Name(), "error", err)
+		}
+	}()
+
+	if err := hs.PluginManager.BackupPlugins(c.Req.Context(), backup); err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to back up plugins", err)
+		return
+	}
+	if _, err := backup.Seek(0, io.SeekStart); err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to back up plugins", err)
+		return
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("grafana-plugins-%s.tar.gz", now.Format("20060102-150405"))
+	c.Resp.Header().Set("Content-Type", "application/gzip")
+	c.Resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(c.Resp, c.Req, name, now, backup)
+}
+
+// AdminRestorePlugins restores the plugin backup of the request body, returned by the backup endpoint: the plugins
+// which aren't installed are installed, and the plugin settings of the existing organizations are restored.
+//
+// POST /api/admin/plugins/restore
+func (hs *HTTPServer) AdminRestorePlugins(c *models.ReqContext) response.Response {
+	backup, err := os.CreateTemp("", "plugin-backup-*.tar.gz")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to store plugin backup", err)
+	}
+	defer func() {
+		if err := os.Remove(backup.Name()); err != nil {
+			hs.log.Warn("Failed to remove plugin backup", "path", backup.Name(), "error", err)
+		}
+	}()
+
+	n, err := io.Copy(backup, io.LimitReader(c.Req.Body, maxPluginBackupUploadSize+1))
+	if closeErr := backup.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to store plugin backup", err)
+	}
+	if n == 0 {
+		return response.Error(http.StatusBadRequest, "Plugin backup missing from the request body", nil)
+	}
+	if n > maxPluginBackupUploadSize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Plugin backup too large", nil)
+	}
+
+	report, err := hs.PluginManager.RestorePlugins(c.Req.Context(), backup.Name())
+	if err != nil {
+		if errors.Is(err, plugins.ErrInvalidPluginBackup) {
+			return response.Error(http.StatusBadRequest, "Invalid plugin backup", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to restore plugin backup", err)
+	}
+	hs.deleteBootDataPanelsCache()
+
+	return response.JSON(http.StatusOK, report)
+}
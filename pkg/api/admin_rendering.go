@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/admin/rendering
+func (hs *HTTPServer) AdminGetRenderingStatus(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.RenderService.Status(c.Req.Context()))
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
+	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
 var plog = log.New("api")
@@ -82,8 +84,8 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/plugins", reqSignedIn, hs.Index)
 	r.Get("/plugins/:id/", reqSignedIn, hs.Index)
 	r.Get("/plugins/:id/edit", reqSignedIn, hs.Index) // deprecated
-	r.Get("/plugins/:id/page/:page", reqSignedIn, hs.Index)
-	r.Get("/a/:id/*", reqSignedIn, hs.Index) // App Root Page
+	r.Get("/plugins/:id/page/:page", reqSignedIn, hs.checkPluginPageAccess, hs.Index)
+	r.Get("/a/:id/*", reqSignedIn, hs.checkPluginPageAccess, hs.Index) // App Root Page
 	r.Get("/a/:id", reqSignedIn, hs.Index)
 
 	r.Get("/d/:uid/:slug", reqSignedIn, redirectFromLegacyPanelEditURL, hs.Index)
@@ -223,6 +225,11 @@ func (hs *HTTPServer) registerRoutes() {
 			// prefs
 			orgRoute.Get("/preferences", reqOrgAdmin, routing.Wrap(GetOrgPreferences))
 			orgRoute.Put("/preferences", reqOrgAdmin, bind(dtos.UpdatePrefsCmd{}), routing.Wrap(UpdateOrgPreferences))
+
+			// frontend settings overrides
+			orgRoute.Get("/frontend-settings", reqOrgAdmin, routing.Wrap(hs.GetOrgFrontendSettingsOverrides))
+			orgRoute.Put("/frontend-settings", reqOrgAdmin, bind(orgsettings.FrontendSettingsOverrides{}), routing.Wrap(hs.UpdateOrgFrontendSettingsOverrides))
+			orgRoute.Delete("/frontend-settings", reqOrgAdmin, routing.Wrap(hs.DeleteOrgFrontendSettingsOverrides))
 		})
 
 		// current org without requirement of user to be org admin
@@ -271,7 +278,7 @@ func (hs *HTTPServer) registerRoutes() {
 		// Data sources
 		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
 			datasourceRoute.Get("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourcesAll)), routing.Wrap(hs.GetDataSources))
-			datasourceRoute.Post("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesCreate)), quota("data_source"), bind(models.AddDataSourceCommand{}), routing.Wrap(AddDataSource))
+			datasourceRoute.Post("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesCreate)), quota("data_source"), bind(models.AddDataSourceCommand{}), routing.Wrap(hs.AddDataSource))
 			datasourceRoute.Put("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesWrite, ScopeDatasourceID)), bind(models.UpdateDataSourceCommand{}), routing.Wrap(hs.UpdateDataSource))
 			datasourceRoute.Delete("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceID)), routing.Wrap(hs.DeleteDataSourceById))
 			datasourceRoute.Delete("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceUID)), routing.Wrap(hs.DeleteDataSourceByUID))
@@ -285,24 +292,51 @@ func (hs *HTTPServer) registerRoutes() {
 
 		apiRoute.Get("/plugins", routing.Wrap(hs.GetPluginList))
 		apiRoute.Get("/plugins/:pluginId/settings", routing.Wrap(hs.GetPluginSettingByID))
+		apiRoute.Get("/plugins/:pluginId/nav", routing.Wrap(hs.GetPluginNav))
 		apiRoute.Get("/plugins/:pluginId/markdown/:name", routing.Wrap(hs.GetPluginMarkdown))
+		apiRoute.Get("/plugins/:pluginId/docs/:name", routing.Wrap(hs.GetPluginDoc))
 		apiRoute.Get("/plugins/:pluginId/health", routing.Wrap(hs.CheckHealth))
 		apiRoute.Any("/plugins/:pluginId/resources", hs.CallResource)
 		apiRoute.Any("/plugins/:pluginId/resources/*", hs.CallResource)
-		apiRoute.Get("/plugins/errors", routing.Wrap(hs.GetPluginErrorsList))
+		apiRoute.Get("/plugins/errors", authorize(reqSignedIn, ac.EvalPermission(ActionPluginsErrorsRead)), routing.Wrap(hs.GetPluginErrorsList))
+		apiRoute.Get("/plugins/catalog", routing.Wrap(hs.SearchPluginCatalog))
+		apiRoute.Get("/plugins/deprecations", reqGrafanaAdmin, routing.Wrap(hs.GetPluginDeprecationReport))
+		apiRoute.Get("/plugins/startup-profile", reqGrafanaAdmin, routing.Wrap(hs.GetPluginStartupProfile))
+		apiRoute.Post("/plugins/validate", reqOrgAdmin, routing.Wrap(hs.ValidatePluginArchive))
+		if hs.Cfg.PluginsConformanceTests {
+			apiRoute.Post("/plugins/:pluginId/conformance", reqGrafanaAdmin, routing.Wrap(hs.RunPluginConformanceSuite))
+		}
 
 		apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
-			pluginRoute.Post("/:pluginId/install", bind(dtos.InstallPluginCommand{}), routing.Wrap(hs.InstallPlugin))
-			pluginRoute.Post("/:pluginId/uninstall", routing.Wrap(hs.UninstallPlugin))
-		}, reqGrafanaAdmin)
+			pluginRoute.Post("/:pluginId/install", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionPluginsInstall, ScopePluginID)), bind(dtos.InstallPluginCommand{}), routing.Wrap(hs.InstallPlugin))
+			pluginRoute.Post("/:pluginId/uninstall", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionPluginsUninstall, ScopePluginID)), routing.Wrap(hs.UninstallPlugin))
+			pluginRoute.Post("/:pluginId/settings", authorize(reqOrgAdmin, ac.EvalPermission(ActionPluginsConfigure, ScopePluginID)), bind(models.UpdatePluginSettingCmd{}), routing.Wrap(hs.UpdatePluginSetting))
+			pluginRoute.Get("/:pluginId/settings/versions", authorize(reqOrgAdmin, ac.EvalPermission(ActionPluginsConfigure, ScopePluginID)), routing.Wrap(hs.GetPluginSettingVersions))
+			pluginRoute.Get("/:pluginId/settings/versions/:version", authorize(reqOrgAdmin, ac.EvalPermission(ActionPluginsConfigure, ScopePluginID)), routing.Wrap(hs.GetPluginSettingVersion))
+			pluginRoute.Post("/:pluginId/settings/restore", authorize(reqOrgAdmin, ac.EvalPermission(ActionPluginsConfigure, ScopePluginID)), bind(models.RestorePluginSettingVersionCmd{}), routing.Wrap(hs.RestorePluginSettingVersion))
+		})
+
+		apiRoute.Group("/plugins/:pluginId/encryption", func(encryptionRoute routing.RouteRegister) {
+			encryptionRoute.Post("/encrypt", bind(dtos.PluginEncryptionCmd{}), routing.Wrap(hs.PluginEncrypt))
+			encryptionRoute.Post("/decrypt", bind(dtos.PluginEncryptionCmd{}), routing.Wrap(hs.PluginDecrypt))
+			encryptionRoute.Post("/sign", bind(dtos.PluginEncryptionCmd{}), routing.Wrap(hs.PluginSign))
+			encryptionRoute.Post("/verify", bind(dtos.PluginEncryptionCmd{}), routing.Wrap(hs.PluginVerify))
+		}, authorize(reqOrgAdmin, ac.EvalPermission(ActionPluginsEncrypt, ScopePluginID)), hs.checkPluginEncryptionCSRF)
 
 		apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
 			pluginRoute.Get("/:pluginId/dashboards/", routing.Wrap(hs.GetPluginDashboards))
-			pluginRoute.Post("/:pluginId/settings", bind(models.UpdatePluginSettingCmd{}), routing.Wrap(hs.UpdatePluginSetting))
+			pluginRoute.Post("/:pluginId/dashboards/:dashboardId/pin", routing.Wrap(hs.PinPluginDashboard))
+			pluginRoute.Delete("/:pluginId/dashboards/:dashboardId/pin", routing.Wrap(hs.UnpinPluginDashboard))
 			pluginRoute.Get("/:pluginId/metrics", routing.Wrap(hs.CollectPluginMetrics))
+			pluginRoute.Get("/:pluginId/signature", routing.Wrap(hs.GetPluginSignatureDetails))
 		}, reqOrgAdmin)
 
 		apiRoute.Get("/frontend/settings/", hs.GetFrontendSettings)
+		apiRoute.Group("/bootdata", func(bootDataRoute routing.RouteRegister) {
+			bootDataRoute.Get("/settings", routing.Wrap(hs.GetBootDataSettings))
+			bootDataRoute.Get("/datasources", routing.Wrap(hs.GetBootDataDataSources))
+			bootDataRoute.Get("/panels", routing.Wrap(hs.GetBootDataPanels))
+		})
 		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, hs.ProxyDataSourceRequest)
 		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, hs.ProxyDataSourceRequest)
 		apiRoute.Any("/datasources/:id/resources", hs.CallDatasourceResource)
@@ -339,6 +373,7 @@ func (hs *HTTPServer) registerRoutes() {
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
 			dashboardRoute.Post("/import", bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.ImportDashboard))
+			dashboardRoute.Post("/import/preview", bind(dtos.ImportDashboardCommand{}), routing.Wrap(hs.PreviewImportDashboard))
 
 			dashboardRoute.Group("/id/:dashboardId", func(dashIdRoute routing.RouteRegister) {
 				dashIdRoute.Get("/versions", routing.Wrap(GetDashboardVersions))
@@ -454,6 +489,23 @@ func (hs *HTTPServer) registerRoutes() {
 	// admin api
 	r.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Get("/settings", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSettings))
+		adminRoute.Get("/feature-toggles", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetFeatureToggles))
+		adminRoute.Patch("/feature-toggles", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsWrite)), bind(dtos.FeatureToggles{}), routing.Wrap(hs.AdminUpdateFeatureToggles))
+		adminRoute.Post("/encryption/rotate-data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminRotateDataKeys))
+		adminRoute.Get("/encryption/re-encryption", reqGrafanaAdmin, routing.Wrap(hs.AdminGetReEncryptionStatus))
+		adminRoute.Get("/encryption/data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminGetDataKeys))
+		adminRoute.Get("/encryption/expiring-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminGetExpiringSecrets))
+		adminRoute.Get("/encryption/export", reqGrafanaAdmin, routing.Wrap(hs.AdminExportSecrets))
+		adminRoute.Post("/encryption/import", reqGrafanaAdmin, bind(secrets.SecretsBundle{}), routing.Wrap(hs.AdminImportSecrets))
+		adminRoute.Get("/rendering", reqGrafanaAdmin, routing.Wrap(hs.AdminGetRenderingStatus))
+		adminRoute.Post("/plugins/:id/restart", reqGrafanaAdmin, routing.Wrap(hs.AdminRestartPlugin))
+		adminRoute.Get("/plugins/:id/config", reqGrafanaAdmin, routing.Wrap(hs.AdminGetPluginConfig))
+		adminRoute.Get("/plugins/disk-usage", reqGrafanaAdmin, routing.Wrap(hs.AdminGetPluginsDiskUsage))
+		adminRoute.Get("/plugins/:id/install-report", reqGrafanaAdmin, routing.Wrap(hs.AdminGetPluginInstallReport))
+		adminRoute.Get("/plugins/:id/roles", reqGrafanaAdmin, routing.Wrap(hs.AdminGetPluginRoles))
+		adminRoute.Get("/health/plugins", reqGrafanaAdmin, routing.Wrap(hs.AdminGetPluginsHealth))
+		adminRoute.Get("/plugins/backup", reqGrafanaAdmin, hs.AdminBackupPlugins)
+		adminRoute.Post("/plugins/restore", reqGrafanaAdmin, routing.Wrap(hs.AdminRestorePlugins))
 		adminRoute.Get("/stats", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(AdminGetStats))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, bind(dtos.PauseAllAlertsCommand{}), routing.Wrap(PauseAllAlerts))
 
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The boot data endpoints split the frontend settings into parts that can be fetched and cached
+// independently. Settings and data sources depend on the signed in user, while panels are cached per
+// organization on the server and only filtered by the user's permissions.
+
+// GET /api/bootdata/settings
+func (hs *HTTPServer) GetBootDataSettings(c *models.ReqContext) response.Response {
+	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get enabled plugins", err)
+	}
+
+	overrides, err := hs.getOrgFrontendSettingsOverrides(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get frontend settings overrides", err)
+	}
+
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user permissions", err)
+	}
+
+	return response.JSON(http.StatusOK, hs.getBootDataSettings(c, enabledPlugins, overrides, access))
+}
+
+// GET /api/bootdata/datasources
+func (hs *HTTPServer) GetBootDataDataSources(c *models.ReqContext) response.Response {
+	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get enabled plugins", err)
+	}
+
+	overrides, err := hs.getOrgFrontendSettingsOverrides(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get frontend settings overrides", err)
+	}
+
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user permissions", err)
+	}
+
+	dataSources, err := hs.getBootDataDataSources(c, enabledPlugins, overrides, access)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data sources", err)
+	}
+
+	return response.JSON(http.StatusOK, dataSources)
+}
+
+// GET /api/bootdata/panels
+func (hs *HTTPServer) GetBootDataPanels(c *models.ReqContext) response.Response {
+	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get enabled plugins", err)
+	}
+
+	overrides, err := hs.getOrgFrontendSettingsOverrides(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get frontend settings overrides", err)
+	}
+
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user permissions", err)
+	}
+
+	panels := filterBootDataPanels(hs.getBootDataPanels(c.OrgId, enabledPlugins, overrides), enabledPlugins, access)
+	return response.JSON(http.StatusOK, panels).SetHeader("Cache-Control", "private, max-age=60")
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// csrfTokenHeader is the header of the CSRF token sent by the frontend with the state-changing plugin resource calls.
+const csrfTokenHeader = "X-Grafana-CSRF-Token"
+
+// resourceAllowedMethodsSetting is the key of the plugin settings, i.e. of the [plugin.<plugin id>] section of the
+// configuration, of the comma-separated list of the HTTP methods of the resource calls forwarded to the plugin.
+const resourceAllowedMethodsSetting = "resource_allowed_methods"
+
+// csrfToken returns the CSRF token of the session of the request, or an empty string if it isn't authenticated by a
+// session cookie. The token is bound to the session rather than to its auth token, so it's still valid once the auth
+// token is rotated.
+func csrfToken(c *models.ReqContext) string {
+	if c.UserToken == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	mac.Write([]byte("csrf:" + strconv.FormatInt(c.UserToken.Id, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// checkResourceCSRF protects the state-changing resource calls authenticated by a session cookie against cross-site
+// request forgery: the request must originate from Grafana or one of the trusted origins, and carry the CSRF token of
+// the session. Writes the error response and returns false otherwise.
+func (hs *HTTPServer) checkResourceCSRF(c *models.ReqContext) bool {
+	if isSafeMethod(c.Req.Method) || c.UserToken == nil {
+		return true
+	}
+
+	if !hs.isTrustedOrigin(c.Req) {
+		c.JsonApiErr(http.StatusForbidden, "Origin not allowed", nil)
+		return false
+	}
+	token := c.Req.Header.Get(csrfTokenHeader)
+	if token == "" || !hmac.Equal([]byte(token), []byte(csrfToken(c))) {
+		c.JsonApiErr(http.StatusForbidden, "Invalid CSRF token", nil)
+		return false
+	}
+	return true
+}
+
+// isTrustedOrigin returns whether the origin of the request, or its referer if the origin isn't set, is Grafana
+// itself or one of the trusted origins.
+func (hs *HTTPServer) isTrustedOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" || origin == "null" {
+		origin = req.Header.Get("Referer")
+	}
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+	if appURL, err := url.Parse(hs.Cfg.AppURL); err == nil && strings.EqualFold(u.Host, appURL.Host) {
+		return true
+	}
+	for _, trusted := range hs.Cfg.CSRFTrustedOrigins {
+		if strings.EqualFold(u.Host, trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkResourceMethod checks that the method of a resource call is allowed by the settings of the plugin. Writes the
+// error response and returns false otherwise.
+func (hs *HTTPServer) checkResourceMethod(c *models.ReqContext, pluginID string) bool {
+	allowed := util.SplitString(hs.Cfg.PluginSettings[pluginID][resourceAllowedMethodsSetting])
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, method := range allowed {
+		if strings.EqualFold(method, c.Req.Method) {
+			return true
+		}
+	}
+	c.JsonApiErr(http.StatusMethodNotAllowed, "Method not allowed for plugin resource", nil)
+	return false
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer_checkResourceCSRF(t *testing.T) {
+	hs := &HTTPServer{Cfg: setting.NewCfg()}
+	hs.Cfg.AppURL = "https://grafana.example.com/"
+	hs.Cfg.CSRFTrustedOrigins = []string{"example.org:8443"}
+
+	newContext := func(method string, headers map[string]string) (*models.ReqContext, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "http://localhost:3000/api/plugins/test-app/resources/items", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		return &models.ReqContext{
+			Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(method, rec)},
+			SignedInUser: &models.SignedInUser{},
+			UserToken:    &models.UserToken{Id: 1},
+		}, rec
+	}
+	session, _ := newContext(http.MethodGet, nil)
+	token := csrfToken(session)
+
+	t.Run("Should allow safe methods and requests not authenticated by a session cookie", func(t *testing.T) {
+		c, _ := newContext(http.MethodGet, nil)
+		require.True(t, hs.checkResourceCSRF(c))
+
+		c, _ = newContext(http.MethodPost, nil)
+		c.UserToken = nil
+		require.True(t, hs.checkResourceCSRF(c))
+	})
+
+	t.Run("Should allow requests with a trusted origin and the CSRF token", func(t *testing.T) {
+		for _, origin := range []string{"http://localhost:3000", "https://grafana.example.com", "https://example.org:8443"} {
+			c, _ := newContext(http.MethodPost, map[string]string{"Origin": origin, csrfTokenHeader: token})
+			require.True(t, hs.checkResourceCSRF(c), origin)
+		}
+
+		c, _ := newContext(http.MethodDelete, map[string]string{"Referer": "http://localhost:3000/a/test-app",
+			csrfTokenHeader: token})
+		require.True(t, hs.checkResourceCSRF(c))
+	})
+
+	t.Run("Should reject requests from other origins", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"Origin": "https://evil.example.com", csrfTokenHeader: token},
+			{"Origin": "null", csrfTokenHeader: token},
+			{csrfTokenHeader: token},
+		} {
+			c, rec := newContext(http.MethodPost, headers)
+			require.False(t, hs.checkResourceCSRF(c))
+			require.Equal(t, http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("Should reject requests without the CSRF token of the session", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, map[string]string{"Origin": "http://localhost:3000"})
+		require.False(t, hs.checkResourceCSRF(c))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+
+		c, rec = newContext(http.MethodPost, map[string]string{"Origin": "http://localhost:3000",
+			csrfTokenHeader: token})
+		c.UserToken = &models.UserToken{Id: 2}
+		require.False(t, hs.checkResourceCSRF(c))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestHTTPServer_checkResourceMethod(t *testing.T) {
+	hs := &HTTPServer{Cfg: setting.NewCfg()}
+	hs.Cfg.PluginSettings = setting.PluginSettings{
+		"test-app": {resourceAllowedMethodsSetting: "GET, POST"},
+	}
+
+	check := func(pluginID, method string) (bool, int) {
+		rec := httptest.NewRecorder()
+		c := &models.ReqContext{Context: &web.Context{
+			Req:  httptest.NewRequest(method, "/api/plugins/test-app/resources/items", nil),
+			Resp: web.NewResponseWriter(method, rec),
+		}}
+		return hs.checkResourceMethod(c, pluginID), rec.Code
+	}
+
+	ok, _ := check("test-app", http.MethodPost)
+	require.True(t, ok)
+	ok, code := check("test-app", http.MethodDelete)
+	require.False(t, ok)
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+	ok, _ = check("other-app", http.MethodDelete)
+	require.True(t, ok)
+}
@@ -12,10 +12,12 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
-	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/services/datasourcehealth"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -29,6 +31,15 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 		return response.Error(500, "Failed to query datasources", err)
 	}
 
+	var healthStatuses map[int64]datasourcehealth.Status
+	if hs.DataSourceHealth != nil && !hs.DataSourceHealth.IsDisabled() {
+		statuses, err := hs.DataSourceHealth.GetStatuses(c.Req.Context(), c.OrgId)
+		if err != nil {
+			datasourcesLogger.Warn("Failed to get data source health", "orgId", c.OrgId, "error", err)
+		}
+		healthStatuses = statuses
+	}
+
 	result := make(dtos.DataSourceList, 0)
 	for _, ds := range query.Result {
 		dsItem := dtos.DataSourceListItemDTO{
@@ -56,6 +67,15 @@ func (hs *HTTPServer) GetDataSources(c *models.ReqContext) response.Response {
 			dsItem.TypeLogoUrl = "public/img/icn-datasource.svg"
 		}
 
+		if health, ok := healthStatuses[ds.Id]; ok {
+			dsItem.Health = &dtos.DataSourceHealthCheck{
+				Status:    health.Status,
+				Message:   health.Message,
+				LatencyMs: health.LatencyMs,
+				Checked:   health.Checked,
+			}
+		}
+
 		result = append(result, dsItem)
 	}
 
@@ -209,12 +229,17 @@ func validateURL(tp string, u string) response.Response {
 	return nil
 }
 
-func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) response.Response {
+func (hs *HTTPServer) AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) response.Response {
 	datasourcesLogger.Debug("Received command to add data source", "url", cmd.Url)
 	cmd.OrgId = c.OrgId
 	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
 		return resp
 	}
+	if plugin := hs.PluginManager.GetDataSource(cmd.Type); plugin != nil {
+		if err := plugin.SettingsSchema.Validate(jsonDataMap(cmd.JsonData), cmd.SecureJsonData, nil); err != nil {
+			return pluginSettingsValidationError(err)
+		}
+	}
 
 	if err := bus.Dispatch(&cmd); err != nil {
 		if errors.Is(err, models.ErrDataSourceNameExists) || errors.Is(err, models.ErrDataSourceUidExists) {
@@ -241,7 +266,15 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 		return resp
 	}
 
-	err := hs.fillWithSecureJSONData(c.Req.Context(), &cmd)
+	if resp := hs.validateDataSourceSettings(c.Req.Context(), cmd); resp != nil {
+		return resp
+	}
+
+	if err := fillWithSecureJSONExpiry(c.Req.Context(), &cmd); err != nil {
+		return response.Error(500, "Failed to update datasource", err)
+	}
+
+	err := hs.fillWithSecureJSONData(c, &cmd)
 	if err != nil {
 		return response.Error(500, "Failed to update datasource", err)
 	}
@@ -278,12 +311,49 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 	})
 }
 
-func (hs *HTTPServer) fillWithSecureJSONData(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
+// validateDataSourceSettings validates the updated settings of the data source against the settings schema of its
+// plugin, with the secure JSON data already stored satisfying the required secure fields.
+func (hs *HTTPServer) validateDataSourceSettings(ctx context.Context, cmd models.UpdateDataSourceCommand) response.Response {
+	plugin := hs.PluginManager.GetDataSource(cmd.Type)
+	if plugin == nil || plugin.SettingsSchema == nil {
+		return nil
+	}
+
+	var storedSecureKeys []string
+	if plugin.SettingsSchema.SecureJSONData != nil {
+		ds, err := getRawDataSourceById(ctx, cmd.Id, cmd.OrgId)
+		if err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				return response.Error(404, "Data source not found", nil)
+			}
+			return response.Error(500, "Failed to update datasource", err)
+		}
+		for key := range ds.SecureJsonData {
+			storedSecureKeys = append(storedSecureKeys, key)
+		}
+	}
+
+	if err := plugin.SettingsSchema.Validate(jsonDataMap(cmd.JsonData), cmd.SecureJsonData, storedSecureKeys); err != nil {
+		return pluginSettingsValidationError(err)
+	}
+	return nil
+}
+
+func jsonDataMap(jsonData *simplejson.Json) map[string]interface{} {
+	if jsonData == nil {
+		return nil
+	}
+	return jsonData.MustMap()
+}
+
+// fillWithSecureJSONData fills the secure JSON data of the command with the stored secrets which aren't replaced.
+// The decryption is audited as made by the signed in user.
+func (hs *HTTPServer) fillWithSecureJSONData(c *models.ReqContext, cmd *models.UpdateDataSourceCommand) error {
 	if len(cmd.SecureJsonData) == 0 {
 		return nil
 	}
 
-	ds, err := getRawDataSourceById(ctx, cmd.Id, cmd.OrgId)
+	ds, err := getRawDataSourceById(c.Req.Context(), cmd.Id, cmd.OrgId)
 	if err != nil {
 		return err
 	}
@@ -292,7 +362,18 @@ func (hs *HTTPServer) fillWithSecureJSONData(ctx context.Context, cmd *models.Up
 		return models.ErrDatasourceIsReadOnly
 	}
 
-	secureJSONData, err := hs.EncryptionService.DecryptJsonData(ctx, ds.SecureJsonData, setting.SecretKey)
+	ctx := secrets.WithAuditSecret(c.Req.Context(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: ds.OrgId,
+		UID:   ds.Uid,
+		Name:  ds.Name,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{
+		Service: "api",
+		UserID:  c.UserId,
+		Login:   c.Login,
+	})
+	secureJSONData, err := hs.SecretsService.DecryptJsonData(ctx, ds.SecureJsonData)
 	if err != nil {
 		return err
 	}
@@ -306,6 +387,23 @@ func (hs *HTTPServer) fillWithSecureJSONData(ctx context.Context, cmd *models.Up
 	return nil
 }
 
+// fillWithSecureJSONExpiry sets the expiry dates of the secrets of the data source once it's updated, keeping the
+// dates of the secrets which aren't replaced. Must be called before the secure JSON data is filled with the secrets
+// which aren't replaced.
+func fillWithSecureJSONExpiry(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
+	if len(cmd.SecureJsonData) == 0 && cmd.SecureJsonExpiry == nil {
+		return nil
+	}
+
+	ds, err := getRawDataSourceById(ctx, cmd.Id, cmd.OrgId)
+	if err != nil {
+		return err
+	}
+
+	cmd.SecureJsonExpiry = models.UpdateSecureJsonExpiry(ds.SecureJsonExpiry, cmd.SecureJsonData, cmd.SecureJsonExpiry)
+	return nil
+}
+
 func getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*models.DataSource, error) {
 	query := models.GetDataSourceQuery{
 		Id:    id,
@@ -386,9 +484,16 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 		return
 	}
 
-	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.decryptSecureJsonDataFn())
+	// the secrets are only decrypted for the calls forwarded to the plugin
+	resourcePath := web.Params(c.Req)["*"]
+	if !hs.checkResourceCall(c, &plugin.PluginBase, resourcePath) {
+		return
+	}
+
+	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.decryptSecureJsonDataFn(c, ds))
 	if err != nil {
 		c.JsonApiErr(500, "Unable to process datasource instance model", err)
+		return
 	}
 
 	pCtx := backend.PluginContext{
@@ -397,7 +502,7 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 		PluginID:                   plugin.Id,
 		DataSourceInstanceSettings: dsInstanceSettings,
 	}
-	hs.BackendPluginManager.CallResource(pCtx, c, web.Params(c.Req)["*"])
+	hs.BackendPluginManager.CallResource(pCtx, c, resourcePath)
 }
 
 func convertModelToDtos(ds *models.DataSource) dtos.DataSource {
@@ -421,6 +526,7 @@ func convertModelToDtos(ds *models.DataSource) dtos.DataSource {
 		SecureJsonFields:  map[string]bool{},
 		Version:           ds.Version,
 		ReadOnly:          ds.ReadOnly,
+		SecureJsonExpiry:  ds.SecureJsonExpiry,
 	}
 
 	for k, v := range ds.SecureJsonData {
@@ -450,7 +556,7 @@ func (hs *HTTPServer) CheckDatasourceHealth(c *models.ReqContext) response.Respo
 		return response.Error(500, "Unable to find datasource plugin", err)
 	}
 
-	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.decryptSecureJsonDataFn())
+	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.decryptSecureJsonDataFn(c, ds))
 	if err != nil {
 		return response.Error(500, "Unable to get datasource model", err)
 	}
@@ -489,11 +595,34 @@ func (hs *HTTPServer) CheckDatasourceHealth(c *models.ReqContext) response.Respo
 	return response.JSON(200, payload)
 }
 
-func (hs *HTTPServer) decryptSecureJsonDataFn() func(map[string][]byte) map[string]string {
+// decryptSecureJsonDataFn returns a function decrypting the secure JSON data of the data source with the secrets
+// service, for the request of a backend plugin. The decryptions are audited as made by the signed in user for the
+// plugin of the data source.
+func (hs *HTTPServer) decryptSecureJsonDataFn(c *models.ReqContext,
+	ds *models.DataSource) func(map[string][]byte) map[string]string {
+	ctx := secrets.WithAuditSecret(c.Req.Context(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: ds.OrgId,
+		UID:   ds.Uid,
+		Name:  ds.Name,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{
+		Service:  "api",
+		UserID:   c.UserId,
+		Login:    c.Login,
+		PluginID: ds.Type,
+	})
+
 	return func(m map[string][]byte) map[string]string {
-		decryptedJsonData, err := hs.EncryptionService.DecryptJsonData(context.Background(), m, setting.SecretKey)
+		decrypted, err := hs.SecretsService.DecryptMany(ctx, m)
 		if err != nil {
 			hs.log.Error("Failed to decrypt secure json data", "error", err)
+			return nil
+		}
+
+		decryptedJsonData := make(map[string]string, len(decrypted))
+		for key, value := range decrypted {
+			decryptedJsonData[key] = string(value)
 		}
 		return decryptedJsonData
 	}
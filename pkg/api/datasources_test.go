@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,9 +12,16 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -78,9 +86,10 @@ func TestAddDataSource_InvalidURL(t *testing.T) {
 	defer bus.ClearBusHandlers()
 
 	sc := setupScenarioContext(t, "/api/datasources")
+	hs := &HTTPServer{PluginManager: &fakePluginManager{}}
 
 	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: "Test",
 			Url:  "invalid:url",
 		})
@@ -108,9 +117,10 @@ func TestAddDataSource_URLWithoutProtocol(t *testing.T) {
 	})
 
 	sc := setupScenarioContext(t, "/api/datasources")
+	hs := &HTTPServer{PluginManager: &fakePluginManager{}}
 
 	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: name,
 			Url:  url,
 		})
@@ -126,9 +136,10 @@ func TestUpdateDataSource_InvalidURL(t *testing.T) {
 	defer bus.ClearBusHandlers()
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
+	hs := &HTTPServer{PluginManager: &fakePluginManager{}}
 
 	sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: "Test",
 			Url:  "invalid:url",
 		})
@@ -156,9 +167,10 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 	})
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
+	hs := &HTTPServer{PluginManager: &fakePluginManager{}}
 
 	sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: name,
 			Url:  url,
 		})
@@ -169,6 +181,40 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 	assert.Equal(t, 200, sc.resp.Code)
 }
 
+// Adding data sources with settings not matching the settings schema of their plugin should lead to an error.
+func TestAddDataSource_InvalidSettings(t *testing.T) {
+	defer bus.ClearBusHandlers()
+
+	var schema plugins.SettingsSchema
+	err := json.Unmarshal([]byte(`{"jsonData": {"required": ["region"], "properties": {"region": {"type": "string"}}}}`), &schema)
+	require.NoError(t, err)
+
+	ds := &plugins.DataSourcePlugin{}
+	ds.Id = "test"
+	ds.SettingsSchema = &schema
+
+	sc := setupScenarioContext(t, "/api/datasources")
+	hs := &HTTPServer{PluginManager: &fakePluginManager{dataSources: []*plugins.DataSourcePlugin{ds}}}
+
+	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
+			Name:     "Test",
+			Type:     "test",
+			Url:      "http://localhost:5432",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"region": 1}),
+		})
+	}))
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+	require.Equal(t, 400, sc.resp.Code)
+	var body struct {
+		Errors []plugins.SettingsFieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &body))
+	require.Equal(t, []plugins.SettingsFieldError{{Field: "jsonData.region", Message: "must be a string"}}, body.Errors)
+}
+
 func TestAPI_Datasources_AccessControl(t *testing.T) {
 	testDatasource := models.DataSource{
 		Id:     3,
@@ -499,3 +545,57 @@ func TestAPI_Datasources_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+type fakeDataSourceCache struct {
+	datasources.CacheService
+
+	dataSource *models.DataSource
+}
+
+func (c *fakeDataSourceCache) GetDatasource(int64, *models.SignedInUser, bool) (*models.DataSource, error) {
+	return c.dataSource, nil
+}
+
+type countingAuditSink struct {
+	decryptions int
+}
+
+func (s *countingAuditSink) RecordDecryption(context.Context, secrets.DecryptionEvent) {
+	s.decryptions++
+}
+
+func TestCallDatasourceResource_deniedCallsDontDecrypt(t *testing.T) {
+	auditSink := &countingAuditSink{}
+	secretsService := secretsManager.SetupTestService(t, nil)
+	secretsService.RegisterAuditSink(auditSink)
+	hs := &HTTPServer{
+		Cfg:           setting.NewCfg(),
+		AccessControl: accesscontrolmock.New().WithDisabled(),
+		PluginManager: &fakePluginManager{dataSources: []*plugins.DataSourcePlugin{{
+			FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{
+				Id:        "test-datasource",
+				Resources: []*plugins.PluginResourceRoute{{Path: "admin/*", ReqRole: models.ROLE_ADMIN}},
+			}},
+		}}},
+		DataSourceCache: &fakeDataSourceCache{dataSource: &models.DataSource{
+			Id:             1,
+			OrgId:          testOrgID,
+			Type:           "test-datasource",
+			JsonData:       simplejson.New(),
+			SecureJsonData: map[string][]byte{"password": []byte("secret")},
+		}},
+		SecretsService: secretsService,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/datasources/1/resources/admin/x", nil)
+	req = web.SetURLParams(req, map[string]string{":id": "1", "*": "admin/x"})
+	rec := httptest.NewRecorder()
+	c := &models.ReqContext{
+		Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(http.MethodGet, rec)},
+		SignedInUser: &models.SignedInUser{OrgId: testOrgID, OrgRole: models.ROLE_VIEWER},
+	}
+
+	hs.CallDatasourceResource(c)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Zero(t, auditSink.decryptions)
+}
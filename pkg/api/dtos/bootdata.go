@@ -0,0 +1,20 @@
+package dtos
+
+import "github.com/grafana/grafana/pkg/plugins"
+
+// BootDataDataSources is the payload served by /api/bootdata/datasources. It contains the data
+// sources the signed in user has access to and is therefore never shared between users.
+type BootDataDataSources struct {
+	DefaultDatasource string                                 `json:"defaultDatasource"`
+	Datasources       map[string]*FrontendSettingsDataSource `json:"datasources"`
+	DatasourcesByUid  map[string]*FrontendSettingsDataSource `json:"datasourcesByUid"`
+	PluginMeta        map[string]*plugins.DataSourcePlugin   `json:"pluginMeta"`
+	PluginsToPreload  []string                               `json:"pluginsToPreload"`
+}
+
+// BootDataPanels is the payload served by /api/bootdata/panels. It only depends on the
+// organization and is cached between requests.
+type BootDataPanels struct {
+	Panels           map[string]*FrontendSettingsPanel `json:"panels"`
+	PluginsToPreload []string                          `json:"pluginsToPreload"`
+}
@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -28,6 +29,8 @@ type DataSource struct {
 	SecureJsonFields  map[string]bool  `json:"secureJsonFields"`
 	Version           int              `json:"version"`
 	ReadOnly          bool             `json:"readOnly"`
+	// SecureJsonExpiry are the dates the secrets expire or are due for rotation, by key.
+	SecureJsonExpiry map[string]time.Time `json:"secureJsonExpiry,omitempty"`
 }
 
 type DataSourceListItemDTO struct {
@@ -47,6 +50,15 @@ type DataSourceListItemDTO struct {
 	IsDefault   bool             `json:"isDefault"`
 	JsonData    *simplejson.Json `json:"jsonData,omitempty"`
 	ReadOnly    bool             `json:"readOnly"`
+	// Health is the result of the last background health check of the data source, if any.
+	Health *DataSourceHealthCheck `json:"health,omitempty"`
+}
+
+type DataSourceHealthCheck struct {
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	LatencyMs int64     `json:"latencyMs"`
+	Checked   time.Time `json:"checked"`
 }
 
 type DataSourceList []DataSourceListItemDTO
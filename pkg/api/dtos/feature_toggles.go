@@ -0,0 +1,5 @@
+package dtos
+
+type FeatureToggles struct {
+	Toggles map[string]bool `json:"toggles"`
+}
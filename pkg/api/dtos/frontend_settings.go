@@ -0,0 +1,179 @@
+package dtos
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// FrontendSettingsDTO is the payload served by /api/frontend/settings and embedded
+// into the index page for frontend initialisation. /api/bootdata/settings serves the
+// same payload without the data sources and panels.
+type FrontendSettingsDTO struct {
+	DefaultDatasource string `json:"defaultDatasource,omitempty"`
+	// DefaultTheme is the theme of the users who haven't chosen one, the default theme of the organization if set.
+	DefaultTheme string `json:"defaultTheme"`
+	// Datasources is keyed by data source name. Deprecated: use DatasourcesByUid.
+	Datasources map[string]*FrontendSettingsDataSource `json:"datasources,omitempty"`
+	// DatasourcesByUid contains the same data sources as Datasources, keyed by UID. Built-in data
+	// sources without a UID are only part of Datasources.
+	DatasourcesByUid map[string]*FrontendSettingsDataSource `json:"datasourcesByUid,omitempty"`
+	// PluginMeta contains the plugin meta of the data sources, keyed by plugin ID.
+	PluginMeta         map[string]*plugins.DataSourcePlugin `json:"pluginMeta,omitempty"`
+	MinRefreshInterval string                               `json:"minRefreshInterval"`
+	Panels             map[string]*FrontendSettingsPanel    `json:"panels,omitempty"`
+	AppUrl             string                               `json:"appUrl"`
+	AppSubUrl          string                               `json:"appSubUrl"`
+	AllowOrgCreate     bool                                 `json:"allowOrgCreate"`
+	AuthProxyEnabled   bool                                 `json:"authProxyEnabled"`
+	LdapEnabled        bool                                 `json:"ldapEnabled"`
+	SigV4AuthEnabled   bool                                 `json:"sigV4AuthEnabled"`
+	ExploreEnabled     bool                                 `json:"exploreEnabled"`
+	LiveEnabled        bool                                 `json:"liveEnabled"`
+	AutoAssignOrg      bool                                 `json:"autoAssignOrg"`
+	VerifyEmailEnabled bool                                 `json:"verifyEmailEnabled"`
+
+	// QueryHistoryEnabled is whether the query history of Explore is stored through the query history API.
+	QueryHistoryEnabled bool `json:"queryHistoryEnabled"`
+
+	AlertingEnabled            bool   `json:"alertingEnabled"`
+	AlertingErrorOrTimeout     string `json:"alertingErrorOrTimeout"`
+	AlertingNoDataOrNullValues string `json:"alertingNoDataOrNullValues"`
+	AlertingMinInterval        int64  `json:"alertingMinInterval"`
+	UnifiedAlertingEnabled     bool   `json:"unifiedAlertingEnabled"`
+
+	GoogleAnalyticsId                   string `json:"googleAnalyticsId"`
+	RudderstackWriteKey                 string `json:"rudderstackWriteKey"`
+	RudderstackDataPlaneUrl             string `json:"rudderstackDataPlaneUrl"`
+	ApplicationInsightsConnectionString string `json:"applicationInsightsConnectionString"`
+	ApplicationInsightsEndpointUrl      string `json:"applicationInsightsEndpointUrl"`
+
+	DisableLoginForm        bool   `json:"disableLoginForm"`
+	DisableUserSignUp       bool   `json:"disableUserSignUp"`
+	LoginHint               string `json:"loginHint"`
+	PasswordHint            string `json:"passwordHint"`
+	ExternalUserMngInfo     string `json:"externalUserMngInfo"`
+	ExternalUserMngLinkUrl  string `json:"externalUserMngLinkUrl"`
+	ExternalUserMngLinkName string `json:"externalUserMngLinkName"`
+	ViewersCanEdit          bool   `json:"viewersCanEdit"`
+	EditorsCanAdmin         bool   `json:"editorsCanAdmin"`
+	DisableSanitizeHtml     bool   `json:"disableSanitizeHtml"`
+
+	PluginsToPreload []string `json:"pluginsToPreload"`
+	// PluginErrors lists the plugins that failed signature validation or loading.
+	PluginErrors []plugins.PluginError `json:"pluginErrors"`
+	// PluginAdvisories lists the security advisories affecting the installed plugins, by plugin ID.
+	PluginAdvisories map[string][]plugins.PluginAdvisory `json:"pluginAdvisories"`
+	// PluginAssetVersions are the versions of the assets of the external plugins, by plugin ID, with versioned asset
+	// URLs only.
+	PluginAssetVersions map[string]string `json:"pluginAssetVersions,omitempty"`
+	// CSRFToken is the token of the session sent with the state-changing plugin resource calls.
+	CSRFToken string `json:"csrfToken"`
+
+	BuildInfo   FrontendSettingsBuildInfo   `json:"buildInfo"`
+	LicenseInfo FrontendSettingsLicenseInfo `json:"licenseInfo"`
+
+	FeatureToggles                   map[string]bool `json:"featureToggles"`
+	RendererAvailable                bool            `json:"rendererAvailable"`
+	RendererVersion                  string          `json:"rendererVersion"`
+	Http2Enabled                     bool            `json:"http2Enabled"`
+	Sentry                           setting.Sentry  `json:"sentry"`
+	PluginCatalogURL                 string          `json:"pluginCatalogURL"`
+	PluginAdminEnabled               bool            `json:"pluginAdminEnabled"`
+	PluginAdminExternalManageEnabled bool            `json:"pluginAdminExternalManageEnabled"`
+	ExpressionsEnabled               bool            `json:"expressionsEnabled"`
+	AwsAllowedAuthProviders          []string        `json:"awsAllowedAuthProviders"`
+	AwsAssumeRoleEnabled             bool            `json:"awsAssumeRoleEnabled"`
+
+	Azure    FrontendSettingsAzure   `json:"azure"`
+	Caching  FrontendSettingsCaching `json:"caching"`
+	Branding setting.Branding        `json:"branding"`
+
+	GeomapDefaultBaseLayerConfig map[string]interface{} `json:"geomapDefaultBaseLayerConfig,omitempty"`
+	GeomapDisableCustomBaseLayer bool                   `json:"geomapDisableCustomBaseLayer,omitempty"`
+
+	// DateFormats is only set when the settings are embedded into the index page.
+	DateFormats *setting.DateFormats `json:"dateFormats,omitempty"`
+
+	// Login page only settings.
+	OAuth       map[string]interface{} `json:"oauth,omitempty"`
+	SamlEnabled bool                   `json:"samlEnabled,omitempty"`
+	SamlName    string                 `json:"samlName,omitempty"`
+	LoginError  string                 `json:"loginError,omitempty"`
+}
+
+type FrontendSettingsBuildInfo struct {
+	HideVersion   bool   `json:"hideVersion"`
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	Buildstamp    int64  `json:"buildstamp"`
+	Edition       string `json:"edition"`
+	LatestVersion string `json:"latestVersion"`
+	HasUpdate     bool   `json:"hasUpdate"`
+	Env           string `json:"env"`
+	IsEnterprise  bool   `json:"isEnterprise"`
+}
+
+type FrontendSettingsLicenseInfo struct {
+	HasLicense      bool   `json:"hasLicense"`
+	HasValidLicense bool   `json:"hasValidLicense"`
+	Expiry          int64  `json:"expiry"`
+	StateInfo       string `json:"stateInfo"`
+	LicenseUrl      string `json:"licenseUrl"`
+	Edition         string `json:"edition"`
+}
+
+type FrontendSettingsAzure struct {
+	Cloud                  string `json:"cloud"`
+	ManagedIdentityEnabled bool   `json:"managedIdentityEnabled"`
+}
+
+type FrontendSettingsCaching struct {
+	Enabled bool `json:"enabled"`
+}
+
+// FrontendSettingsDataSource describes a data source as seen by the frontend. Built-in data
+// sources only populate the type, name and plugin ID fields (and id/uid for the Grafana data source).
+// The plugin meta isn't serialized with every data source, but once per plugin in the plugin meta
+// map of the payload where it's referenced by PluginId.
+type FrontendSettingsDataSource struct {
+	Id        int64                     `json:"id,omitempty"`
+	Uid       string                    `json:"uid,omitempty"`
+	Type      string                    `json:"type"`
+	Name      string                    `json:"name"`
+	Url       string                    `json:"url,omitempty"`
+	IsDefault bool                      `json:"isDefault"`
+	Access    models.DsAccess           `json:"access,omitempty"`
+	PluginId  string                    `json:"pluginId"`
+	Meta      *plugins.DataSourcePlugin `json:"-"`
+	JsonData  *simplejson.Json          `json:"jsonData,omitempty"`
+
+	BasicAuth       string `json:"basicAuth,omitempty"`
+	WithCredentials bool   `json:"withCredentials,omitempty"`
+
+	// InfluxDB
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// InfluxDB and Elasticsearch
+	Database string `json:"database,omitempty"`
+}
+
+type FrontendSettingsPanel struct {
+	Module        string                        `json:"module"`
+	BaseUrl       string                        `json:"baseUrl"`
+	Name          string                        `json:"name"`
+	Id            string                        `json:"id"`
+	Info          plugins.PluginInfo            `json:"info"`
+	HideFromList  bool                          `json:"hideFromList"`
+	Sort          int                           `json:"sort"`
+	SkipDataQuery bool                          `json:"skipDataQuery"`
+	State         plugins.PluginState           `json:"state"`
+	Signature     plugins.PluginSignatureStatus `json:"signature"`
+	Dev           bool                          `json:"dev"`
+
+	FeatureToggles  []string `json:"featureToggles,omitempty"`
+	Locales         []string `json:"locales,omitempty"`
+	ModuleIntegrity string   `json:"moduleIntegrity,omitempty"`
+}
@@ -8,7 +8,7 @@ import (
 
 type IndexViewData struct {
 	User                    *CurrentUser
-	Settings                map[string]interface{}
+	Settings                *FrontendSettingsDTO
 	AppUrl                  string
 	AppSubUrl               string
 	GoogleAnalyticsId       string
@@ -1,6 +1,8 @@
 package dtos
 
 import (
+	"time"
+
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/plugins"
 )
@@ -19,12 +21,20 @@ type PluginSetting struct {
 	JsonData      map[string]interface{}      `json:"jsonData"`
 	DefaultNavUrl string                      `json:"defaultNavUrl"`
 
+	DependencyStatus plugins.DependencyStatus `json:"dependencyStatus"`
+
 	LatestVersion string                        `json:"latestVersion"`
 	HasUpdate     bool                          `json:"hasUpdate"`
+	Advisories    []plugins.PluginAdvisory      `json:"advisories"`
 	State         plugins.PluginState           `json:"state"`
 	Signature     plugins.PluginSignatureStatus `json:"signature"`
 	SignatureType plugins.PluginSignatureType   `json:"signatureType"`
 	SignatureOrg  string                        `json:"signatureOrg"`
+	Dev           bool                          `json:"dev"`
+
+	FeatureToggles  []string `json:"featureToggles,omitempty"`
+	Locales         []string `json:"locales,omitempty"`
+	ModuleIntegrity string   `json:"moduleIntegrity,omitempty"`
 }
 
 type PluginListItem struct {
@@ -36,12 +46,17 @@ type PluginListItem struct {
 	Info          *plugins.PluginInfo           `json:"info"`
 	LatestVersion string                        `json:"latestVersion"`
 	HasUpdate     bool                          `json:"hasUpdate"`
+	Advisories    []plugins.PluginAdvisory      `json:"advisories"`
 	DefaultNavUrl string                        `json:"defaultNavUrl"`
 	Category      string                        `json:"category"`
 	State         plugins.PluginState           `json:"state"`
 	Signature     plugins.PluginSignatureStatus `json:"signature"`
 	SignatureType plugins.PluginSignatureType   `json:"signatureType"`
 	SignatureOrg  string                        `json:"signatureOrg"`
+	Dev           bool                          `json:"dev"`
+
+	Dependencies     *plugins.PluginDependencies `json:"dependencies"`
+	DependencyStatus plugins.DependencyStatus    `json:"dependencyStatus"`
 }
 
 type PluginList []PluginListItem
@@ -71,3 +86,58 @@ type ImportDashboardCommand struct {
 type InstallPluginCommand struct {
 	Version string `json:"version"`
 }
+
+// PluginsHealth is the health of the backend plugins and of their data sources.
+type PluginsHealth struct {
+	// Status is "ok", or "degraded" if a plugin isn't running, has an invalid signature, or a data source failed
+	// its last health check.
+	Status  string         `json:"status"`
+	Plugins []PluginHealth `json:"plugins"`
+}
+
+type PluginHealth struct {
+	Id                  string                        `json:"id"`
+	Name                string                        `json:"name"`
+	Type                string                        `json:"type"`
+	Signature           plugins.PluginSignatureStatus `json:"signature"`
+	SignatureType       plugins.PluginSignatureType   `json:"signatureType"`
+	Managed             bool                          `json:"managed"`
+	Running             bool                          `json:"running"`
+	Restarts            int64                         `json:"restarts"`
+	Unavailable         int64                         `json:"unavailable"`
+	HealthCheckFailures int64                         `json:"healthCheckFailures"`
+	DataSources         []DataSourceHealth            `json:"dataSources"`
+}
+
+type DataSourceHealth struct {
+	Uid       string    `json:"uid"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// PluginConfig is the configuration a backend plugin received, with the secret values masked.
+type PluginConfig struct {
+	Id       string            `json:"id"`
+	Env      map[string]string `json:"env"`
+	Settings map[string]string `json:"settings"`
+}
+
+// PluginRole is a role declared by a plugin, named after the fixed role registered for it.
+type PluginRole struct {
+	Name        string                     `json:"name"`
+	DisplayName string                     `json:"displayName,omitempty"`
+	Description string                     `json:"description"`
+	Permissions []plugins.PluginPermission `json:"permissions"`
+	Grants      []string                   `json:"grants"`
+	// Registered is whether the role is registered, which it isn't when access control is disabled.
+	Registered bool `json:"registered"`
+}
+
+// PluginEncryptionCmd is the payload of the encryption API of app plugins. The data and signature are encoded in
+// base64.
+type PluginEncryptionCmd struct {
+	Data      []byte `json:"data,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+}
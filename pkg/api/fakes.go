@@ -6,6 +6,13 @@ type fakePluginManager struct {
 	plugins.Manager
 
 	staticRoutes []*plugins.PluginStaticRoute
+	dataSources  []*plugins.DataSourcePlugin
+	plugins      []*plugins.PluginBase
+	errors       []plugins.PluginError
+}
+
+func (pm *fakePluginManager) Plugins() []*plugins.PluginBase {
+	return pm.plugins
 }
 
 func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
@@ -13,9 +20,18 @@ func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
 }
 
 func (pm *fakePluginManager) GetDataSource(id string) *plugins.DataSourcePlugin {
+	for _, ds := range pm.dataSources {
+		if ds.Id == id {
+			return ds
+		}
+	}
 	return nil
 }
 
+func (pm *fakePluginManager) DataSources() []*plugins.DataSourcePlugin {
+	return pm.dataSources
+}
+
 func (pm *fakePluginManager) Renderer() *plugins.RendererPlugin {
 	return nil
 }
@@ -23,3 +39,7 @@ func (pm *fakePluginManager) Renderer() *plugins.RendererPlugin {
 func (pm *fakePluginManager) StaticRoutes() []*plugins.PluginStaticRoute {
 	return pm.staticRoutes
 }
+
+func (pm *fakePluginManager) ScanningErrors() []plugins.PluginError {
+	return pm.errors
+}
@@ -203,19 +203,21 @@ func updateFolderScenario(t *testing.T, desc string, url string, routePattern st
 type fakeFolderService struct {
 	dashboards.FolderService
 
-	GetFoldersResult     []*models.Folder
-	GetFoldersError      error
-	GetFolderByUIDResult *models.Folder
-	GetFolderByUIDError  error
-	GetFolderByIDResult  *models.Folder
-	GetFolderByIDError   error
-	CreateFolderResult   *models.Folder
-	CreateFolderError    error
-	UpdateFolderResult   *models.Folder
-	UpdateFolderError    error
-	DeleteFolderResult   *models.Folder
-	DeleteFolderError    error
-	DeletedFolderUids    []string
+	GetFoldersResult       []*models.Folder
+	GetFoldersError        error
+	GetFolderByUIDResult   *models.Folder
+	GetFolderByUIDError    error
+	GetFolderByIDResult    *models.Folder
+	GetFolderByIDError     error
+	GetFolderByTitleResult *models.Folder
+	GetFolderByTitleError  error
+	CreateFolderResult     *models.Folder
+	CreateFolderError      error
+	UpdateFolderResult     *models.Folder
+	UpdateFolderError      error
+	DeleteFolderResult     *models.Folder
+	DeleteFolderError      error
+	DeletedFolderUids      []string
 }
 
 func (s *fakeFolderService) GetFolders(ctx context.Context, limit int64, page int64) ([]*models.Folder, error) {
@@ -230,6 +232,10 @@ func (s *fakeFolderService) GetFolderByUID(ctx context.Context, uid string) (*mo
 	return s.GetFolderByUIDResult, s.GetFolderByUIDError
 }
 
+func (s *fakeFolderService) GetFolderByTitle(ctx context.Context, title string) (*models.Folder, error) {
+	return s.GetFolderByTitleResult, s.GetFolderByTitleError
+}
+
 func (s *fakeFolderService) CreateFolder(ctx context.Context, title, uid string) (*models.Folder, error) {
 	return s.CreateFolderResult, s.CreateFolderError
 }
@@ -2,20 +2,33 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 	"github.com/grafana/grafana/pkg/util"
 )
 
-func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plugins.EnabledPlugins) (map[string]interface{}, error) {
+const (
+	// bootDataPanelsCacheTTL is how long the panels section of the boot data is cached for an organization.
+	bootDataPanelsCacheTTL = time.Minute
+	// bootDataPanelsCacheKeyPrefix is the prefix of the cache keys of the panels of the organizations.
+	bootDataPanelsCacheKeyPrefix = "bootdata-panels-"
+)
+
+func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plugins.EnabledPlugins,
+	access *frontendSettingsAccess) (map[string]*dtos.FrontendSettingsDataSource, error) {
 	orgDataSources := make([]*models.DataSource, 0)
 
 	if c.OrgId != 0 {
@@ -42,23 +55,34 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 		}
 	}
 
-	dataSources := make(map[string]interface{})
+	dataSources := make(map[string]*dtos.FrontendSettingsDataSource)
 
 	for _, ds := range orgDataSources {
+		if !access.canQueryDataSource(ds) {
+			continue
+		}
+
 		url := ds.Url
+		dsAccess := ds.Access
+
+		// credentials of data sources with browser access are only sent to the browser when explicitly enabled,
+		// otherwise these data sources are accessed through the data source proxy
+		if dsAccess == models.DS_ACCESS_DIRECT && !hs.Cfg.DataSourcesExposeDirectAccessCredentials && hasDirectAccessCredentials(ds) {
+			dsAccess = models.DS_ACCESS_PROXY
+		}
 
-		if ds.Access == models.DS_ACCESS_PROXY {
+		if dsAccess == models.DS_ACCESS_PROXY {
 			url = "/api/datasources/proxy/" + strconv.FormatInt(ds.Id, 10)
 		}
 
-		dsMap := map[string]interface{}{
-			"id":        ds.Id,
-			"uid":       ds.Uid,
-			"type":      ds.Type,
-			"name":      ds.Name,
-			"url":       url,
-			"isDefault": ds.IsDefault,
-			"access":    ds.Access,
+		dsDTO := &dtos.FrontendSettingsDataSource{
+			Id:        ds.Id,
+			Uid:       ds.Uid,
+			Type:      ds.Type,
+			Name:      ds.Name,
+			Url:       url,
+			IsDefault: ds.IsDefault,
+			Access:    dsAccess,
 		}
 
 		meta, exists := enabledPlugins.DataSources[ds.Type]
@@ -66,41 +90,45 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 			log.Errorf(3, "Could not find plugin definition for data source: %v", ds.Type)
 			continue
 		}
-		dsMap["meta"] = meta
+		if !access.canUsePlugin(meta.Id) {
+			continue
+		}
+		dsDTO.Meta = meta
+		dsDTO.PluginId = meta.Id
 
 		jsonData := ds.JsonData
 		if jsonData == nil {
 			jsonData = simplejson.New()
 		}
 
-		dsMap["jsonData"] = jsonData
+		dsDTO.JsonData = jsonData
 
-		if ds.Access == models.DS_ACCESS_DIRECT {
+		if dsAccess == models.DS_ACCESS_DIRECT {
 			if ds.BasicAuth {
-				dsMap["basicAuth"] = util.GetBasicAuthHeader(
+				dsDTO.BasicAuth = util.GetBasicAuthHeader(
 					ds.BasicAuthUser,
 					hs.DataSourcesService.DecryptedBasicAuthPassword(ds),
 				)
 			}
 			if ds.WithCredentials {
-				dsMap["withCredentials"] = ds.WithCredentials
+				dsDTO.WithCredentials = ds.WithCredentials
 			}
 
 			if ds.Type == models.DS_INFLUXDB_08 {
-				dsMap["username"] = ds.User
-				dsMap["password"] = hs.DataSourcesService.DecryptedPassword(ds)
-				dsMap["url"] = url + "/db/" + ds.Database
+				dsDTO.Username = ds.User
+				dsDTO.Password = hs.DataSourcesService.DecryptedPassword(ds)
+				dsDTO.Url = url + "/db/" + ds.Database
 			}
 
 			if ds.Type == models.DS_INFLUXDB {
-				dsMap["username"] = ds.User
-				dsMap["password"] = hs.DataSourcesService.DecryptedPassword(ds)
-				dsMap["url"] = url
+				dsDTO.Username = ds.User
+				dsDTO.Password = hs.DataSourcesService.DecryptedPassword(ds)
+				dsDTO.Url = url
 			}
 		}
 
 		if (ds.Type == models.DS_INFLUXDB) || (ds.Type == models.DS_ES) {
-			dsMap["database"] = ds.Database
+			dsDTO.Database = ds.Database
 		}
 
 		if ds.Type == models.DS_PROMETHEUS {
@@ -108,21 +136,22 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 			jsonData.Set("directUrl", ds.Url)
 		}
 
-		dataSources[ds.Name] = dsMap
+		dataSources[ds.Name] = dsDTO
 	}
 
 	// add data sources that are built in (meaning they are not added via data sources page, nor have any entry in
 	// the datasource table)
 	for _, ds := range hs.PluginManager.DataSources() {
-		if ds.BuiltIn {
-			info := map[string]interface{}{
-				"type": ds.Type,
-				"name": ds.Name,
-				"meta": hs.PluginManager.GetDataSource(ds.Id),
+		if ds.BuiltIn && access.canUsePlugin(ds.Id) {
+			info := &dtos.FrontendSettingsDataSource{
+				Type:     ds.Type,
+				Name:     ds.Name,
+				PluginId: ds.Id,
+				Meta:     hs.PluginManager.GetDataSource(ds.Id),
 			}
 			if ds.Name == grafanads.DatasourceName {
-				info["id"] = grafanads.DatasourceID
-				info["uid"] = grafanads.DatasourceUID
+				info.Id = grafanads.DatasourceID
+				info.Uid = grafanads.DatasourceUID
 			}
 			dataSources[ds.Name] = info
 		}
@@ -131,62 +160,186 @@ func (hs *HTTPServer) getFSDataSources(c *models.ReqContext, enabledPlugins *plu
 	return dataSources, nil
 }
 
+// hasDirectAccessCredentials returns whether a data source with browser access would need its decrypted
+// credentials to be sent to the browser.
+func hasDirectAccessCredentials(ds *models.DataSource) bool {
+	if ds.BasicAuth {
+		return true
+	}
+
+	switch ds.Type {
+	case models.DS_INFLUXDB_08, models.DS_INFLUXDB:
+		return ds.User != "" || ds.Password != "" || len(ds.SecureJsonData) > 0
+	}
+	return false
+}
+
 // getFrontendSettingsMap returns a json object with all the settings needed for front end initialisation.
-func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]interface{}, error) {
+func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (*dtos.FrontendSettingsDTO, error) {
 	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
 	if err != nil {
 		return nil, err
 	}
 
-	pluginsToPreload := []string{}
-	for _, app := range enabledPlugins.Apps {
-		if app.Preload {
-			pluginsToPreload = append(pluginsToPreload, app.Module)
-		}
+	overrides, err := hs.getOrgFrontendSettingsOverrides(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return nil, err
 	}
 
-	dataSources, err := hs.getFSDataSources(c, enabledPlugins)
+	dataSources, err := hs.getBootDataDataSources(c, enabledPlugins, overrides, access)
+	if err != nil {
+		return nil, err
+	}
+
+	panels := filterBootDataPanels(hs.getBootDataPanels(c.OrgId, enabledPlugins, overrides), enabledPlugins, access)
+
+	frontendSettings := hs.getBootDataSettings(c, enabledPlugins, overrides, access)
+	frontendSettings.DefaultDatasource = dataSources.DefaultDatasource
+	frontendSettings.Datasources = dataSources.Datasources
+	frontendSettings.DatasourcesByUid = dataSources.DatasourcesByUid
+	frontendSettings.PluginMeta = dataSources.PluginMeta
+	frontendSettings.Panels = panels.Panels
+
+	// the preload order depends on all plugins, so it can't be combined from the separate parts
+	preloadCandidates := getAppPreloadCandidates(enabledPlugins, access)
+	preloadCandidates = append(preloadCandidates, getDataSourcePreloadCandidates(dataSources.Datasources)...)
+	preloadCandidates = append(preloadCandidates, getPanelPreloadCandidates(enabledPlugins, panels.Panels)...)
+	frontendSettings.PluginsToPreload = getPluginsToPreload(preloadCandidates)
+
+	return frontendSettings, nil
+}
+
+// getBootDataDataSources returns the data sources available to the signed in user.
+func (hs *HTTPServer) getBootDataDataSources(c *models.ReqContext, enabledPlugins *plugins.EnabledPlugins,
+	overrides *orgsettings.FrontendSettingsOverrides, access *frontendSettingsAccess) (*dtos.BootDataDataSources, error) {
+	dataSources, err := hs.getFSDataSources(c, enabledPlugins, access)
 	if err != nil {
 		return nil, err
 	}
 
 	defaultDS := "-- Grafana --"
+	dataSourcesByUID := make(map[string]*dtos.FrontendSettingsDataSource, len(dataSources))
+	pluginMeta := make(map[string]*plugins.DataSourcePlugin)
 	for n, ds := range dataSources {
-		dsM := ds.(map[string]interface{})
-		if isDefault, _ := dsM["isDefault"].(bool); isDefault {
+		if ds.Meta != nil {
+			pluginMeta[ds.PluginId] = ds.Meta
+		}
+
+		if ds.IsDefault {
 			defaultDS = n
 		}
 
-		meta := dsM["meta"].(*plugins.DataSourcePlugin)
-		if meta.Preload {
-			pluginsToPreload = append(pluginsToPreload, meta.Module)
+		if ds.Uid != "" {
+			dataSourcesByUID[ds.Uid] = ds
 		}
 	}
 
-	panels := map[string]interface{}{}
+	if _, exists := dataSources[overrides.DefaultDatasource]; exists {
+		defaultDS = overrides.DefaultDatasource
+	}
+
+	return &dtos.BootDataDataSources{
+		DefaultDatasource: defaultDS,
+		Datasources:       dataSources,
+		DatasourcesByUid:  dataSourcesByUID,
+		PluginMeta:        pluginMeta,
+		PluginsToPreload:  getPluginsToPreload(getDataSourcePreloadCandidates(dataSources)),
+	}, nil
+}
+
+// getBootDataPanels returns the panels enabled for the organization. The result only depends on the
+// organization, so it's cached and shared between requests; callers must not modify it.
+func (hs *HTTPServer) getBootDataPanels(orgID int64, enabledPlugins *plugins.EnabledPlugins,
+	overrides *orgsettings.FrontendSettingsOverrides) *dtos.BootDataPanels {
+	cacheKey := bootDataPanelsCacheKey(orgID)
+	if cached, found := hs.CacheService.Get(cacheKey); found {
+		return cached.(*dtos.BootDataPanels)
+	}
+
+	sortOrder := getPanelSortOrder(hs.Cfg.PanelsSortOrder, overrides.PanelSortOrder)
+	panels := map[string]*dtos.FrontendSettingsPanel{}
 	for _, panel := range enabledPlugins.Panels {
 		if panel.State == plugins.PluginStateAlpha && !hs.Cfg.PluginsEnableAlpha {
 			continue
 		}
 
-		if panel.Preload {
-			pluginsToPreload = append(pluginsToPreload, panel.Module)
+		panels[panel.Id] = &dtos.FrontendSettingsPanel{
+			Module:        panel.Module,
+			BaseUrl:       panel.BaseUrl,
+			Name:          panel.Name,
+			Id:            panel.Id,
+			Info:          panel.Info,
+			HideFromList:  panel.HideFromList,
+			Sort:          getPanelSort(sortOrder, panel.Id),
+			SkipDataQuery: panel.SkipDataQuery,
+			State:         panel.State,
+			Signature:     panel.Signature,
+			Dev:           panel.IsDevPlugin,
+
+			FeatureToggles:  panel.FeatureToggles,
+			Locales:         panel.Locales,
+			ModuleIntegrity: panel.ModuleIntegrity,
+		}
+	}
+
+	for _, hidden := range [][]string{hs.Cfg.PanelsHidden, overrides.HiddenPanels} {
+		for _, id := range hidden {
+			if panel, exists := panels[id]; exists {
+				panel.HideFromList = true
+			}
+		}
+	}
+
+	result := &dtos.BootDataPanels{
+		Panels:           panels,
+		PluginsToPreload: getPluginsToPreload(getPanelPreloadCandidates(enabledPlugins, panels)),
+	}
+	hs.CacheService.Set(cacheKey, result, bootDataPanelsCacheTTL)
+	return result
+}
+
+func bootDataPanelsCacheKey(orgID int64) string {
+	return fmt.Sprintf("%s%d", bootDataPanelsCacheKeyPrefix, orgID)
+}
+
+// deleteBootDataPanelsCache removes the cached panels of all organizations, e.g. when a plugin is installed or
+// uninstalled.
+func (hs *HTTPServer) deleteBootDataPanelsCache() {
+	for key := range hs.CacheService.Items() {
+		if strings.HasPrefix(key, bootDataPanelsCacheKeyPrefix) {
+			hs.CacheService.Delete(key)
 		}
+	}
+}
+
+// filterBootDataPanels removes the panels the user isn't allowed to use from the panels of the organization.
+func filterBootDataPanels(orgPanels *dtos.BootDataPanels, enabledPlugins *plugins.EnabledPlugins,
+	access *frontendSettingsAccess) *dtos.BootDataPanels {
+	if !access.enabled {
+		return orgPanels
+	}
 
-		panels[panel.Id] = map[string]interface{}{
-			"module":        panel.Module,
-			"baseUrl":       panel.BaseUrl,
-			"name":          panel.Name,
-			"id":            panel.Id,
-			"info":          panel.Info,
-			"hideFromList":  panel.HideFromList,
-			"sort":          getPanelSort(panel.Id),
-			"skipDataQuery": panel.SkipDataQuery,
-			"state":         panel.State,
-			"signature":     panel.Signature,
+	panels := make(map[string]*dtos.FrontendSettingsPanel, len(orgPanels.Panels))
+	for id, panel := range orgPanels.Panels {
+		if access.canUsePlugin(id) {
+			panels[id] = panel
 		}
 	}
 
+	return &dtos.BootDataPanels{
+		Panels:           panels,
+		PluginsToPreload: getPluginsToPreload(getPanelPreloadCandidates(enabledPlugins, panels)),
+	}
+}
+
+// getBootDataSettings returns the frontend settings without the data sources and panels.
+func (hs *HTTPServer) getBootDataSettings(c *models.ReqContext, enabledPlugins *plugins.EnabledPlugins,
+	overrides *orgsettings.FrontendSettingsOverrides, access *frontendSettingsAccess) *dtos.FrontendSettingsDTO {
 	hideVersion := hs.Cfg.AnonymousHideVersion && !c.IsSignedIn
 	version := setting.BuildVersion
 	commit := setting.BuildCommit
@@ -200,130 +353,231 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 
 	hasAccess := accesscontrol.HasAccess(hs.AccessControl, c)
 
-	jsonObj := map[string]interface{}{
-		"defaultDatasource":                   defaultDS,
-		"datasources":                         dataSources,
-		"minRefreshInterval":                  setting.MinRefreshInterval,
-		"panels":                              panels,
-		"appUrl":                              hs.Cfg.AppURL,
-		"appSubUrl":                           hs.Cfg.AppSubURL,
-		"allowOrgCreate":                      (setting.AllowUserOrgCreate && c.IsSignedIn) || c.IsGrafanaAdmin,
-		"authProxyEnabled":                    setting.AuthProxyEnabled,
-		"ldapEnabled":                         hs.Cfg.LDAPEnabled,
-		"alertingEnabled":                     setting.AlertingEnabled,
-		"alertingErrorOrTimeout":              setting.AlertingErrorOrTimeout,
-		"alertingNoDataOrNullValues":          setting.AlertingNoDataOrNullValues,
-		"alertingMinInterval":                 setting.AlertingMinInterval,
-		"liveEnabled":                         hs.Cfg.LiveMaxConnections != 0,
-		"autoAssignOrg":                       setting.AutoAssignOrg,
-		"verifyEmailEnabled":                  setting.VerifyEmailEnabled,
-		"sigV4AuthEnabled":                    setting.SigV4AuthEnabled,
-		"exploreEnabled":                      setting.ExploreEnabled,
-		"googleAnalyticsId":                   setting.GoogleAnalyticsId,
-		"rudderstackWriteKey":                 setting.RudderstackWriteKey,
-		"rudderstackDataPlaneUrl":             setting.RudderstackDataPlaneUrl,
-		"applicationInsightsConnectionString": hs.Cfg.ApplicationInsightsConnectionString,
-		"applicationInsightsEndpointUrl":      hs.Cfg.ApplicationInsightsEndpointUrl,
-		"disableLoginForm":                    setting.DisableLoginForm,
-		"disableUserSignUp":                   !setting.AllowUserSignUp,
-		"loginHint":                           setting.LoginHint,
-		"passwordHint":                        setting.PasswordHint,
-		"externalUserMngInfo":                 setting.ExternalUserMngInfo,
-		"externalUserMngLinkUrl":              setting.ExternalUserMngLinkUrl,
-		"externalUserMngLinkName":             setting.ExternalUserMngLinkName,
-		"viewersCanEdit":                      setting.ViewersCanEdit,
-		"editorsCanAdmin":                     hs.Cfg.EditorsCanAdmin,
-		"disableSanitizeHtml":                 hs.Cfg.DisableSanitizeHtml,
-		"pluginsToPreload":                    pluginsToPreload,
-		"buildInfo": map[string]interface{}{
-			"hideVersion":   hideVersion,
-			"version":       version,
-			"commit":        commit,
-			"buildstamp":    buildstamp,
-			"edition":       hs.License.Edition(),
-			"latestVersion": hs.PluginManager.GrafanaLatestVersion(),
-			"hasUpdate":     hs.PluginManager.GrafanaHasUpdate(),
-			"env":           setting.Env,
-			"isEnterprise":  hs.License.HasValidLicense(),
+	frontendSettings := &dtos.FrontendSettingsDTO{
+		DefaultTheme:                        hs.Cfg.DefaultTheme,
+		MinRefreshInterval:                  setting.MinRefreshInterval,
+		AppUrl:                              hs.Cfg.AppURL,
+		AppSubUrl:                           hs.Cfg.AppSubURL,
+		AllowOrgCreate:                      (setting.AllowUserOrgCreate && c.IsSignedIn) || c.IsGrafanaAdmin,
+		AuthProxyEnabled:                    setting.AuthProxyEnabled,
+		LdapEnabled:                         hs.Cfg.LDAPEnabled,
+		AlertingEnabled:                     setting.AlertingEnabled,
+		AlertingErrorOrTimeout:              setting.AlertingErrorOrTimeout,
+		AlertingNoDataOrNullValues:          setting.AlertingNoDataOrNullValues,
+		AlertingMinInterval:                 setting.AlertingMinInterval,
+		LiveEnabled:                         hs.Cfg.LiveMaxConnections != 0,
+		AutoAssignOrg:                       setting.AutoAssignOrg,
+		VerifyEmailEnabled:                  setting.VerifyEmailEnabled,
+		SigV4AuthEnabled:                    setting.SigV4AuthEnabled,
+		ExploreEnabled:                      setting.ExploreEnabled,
+		QueryHistoryEnabled:                 hs.Cfg.QueryHistoryEnabled,
+		GoogleAnalyticsId:                   setting.GoogleAnalyticsId,
+		RudderstackWriteKey:                 setting.RudderstackWriteKey,
+		RudderstackDataPlaneUrl:             setting.RudderstackDataPlaneUrl,
+		ApplicationInsightsConnectionString: hs.Cfg.ApplicationInsightsConnectionString,
+		ApplicationInsightsEndpointUrl:      hs.Cfg.ApplicationInsightsEndpointUrl,
+		DisableLoginForm:                    setting.DisableLoginForm,
+		DisableUserSignUp:                   !setting.AllowUserSignUp,
+		LoginHint:                           setting.LoginHint,
+		PasswordHint:                        setting.PasswordHint,
+		ExternalUserMngInfo:                 setting.ExternalUserMngInfo,
+		ExternalUserMngLinkUrl:              setting.ExternalUserMngLinkUrl,
+		ExternalUserMngLinkName:             setting.ExternalUserMngLinkName,
+		ViewersCanEdit:                      setting.ViewersCanEdit,
+		EditorsCanAdmin:                     hs.Cfg.EditorsCanAdmin,
+		DisableSanitizeHtml:                 hs.Cfg.DisableSanitizeHtml,
+		PluginsToPreload:                    getPluginsToPreload(getAppPreloadCandidates(enabledPlugins, access)),
+		PluginErrors:                        hs.getFrontendPluginErrors(c),
+		PluginAdvisories:                    hs.getFrontendPluginAdvisories(c),
+		PluginAssetVersions:                 hs.getPluginAssetVersions(),
+		CSRFToken:                           csrfToken(c),
+		BuildInfo: dtos.FrontendSettingsBuildInfo{
+			HideVersion:   hideVersion,
+			Version:       version,
+			Commit:        commit,
+			Buildstamp:    buildstamp,
+			Edition:       hs.License.Edition(),
+			LatestVersion: hs.PluginManager.GrafanaLatestVersion(),
+			HasUpdate:     hs.PluginManager.GrafanaHasUpdate(),
+			Env:           setting.Env,
+			IsEnterprise:  hs.License.HasValidLicense(),
 		},
-		"licenseInfo": map[string]interface{}{
-			"hasLicense":      hs.License.HasLicense(),
-			"hasValidLicense": hs.License.HasValidLicense(),
-			"expiry":          hs.License.Expiry(),
-			"stateInfo":       hs.License.StateInfo(),
-			"licenseUrl":      hs.License.LicenseURL(hasAccess(accesscontrol.ReqGrafanaAdmin, accesscontrol.LicensingPageReaderAccess)),
-			"edition":         hs.License.Edition(),
+		LicenseInfo: dtos.FrontendSettingsLicenseInfo{
+			HasLicense:      hs.License.HasLicense(),
+			HasValidLicense: hs.License.HasValidLicense(),
+			Expiry:          hs.License.Expiry(),
+			StateInfo:       hs.License.StateInfo(),
+			LicenseUrl:      hs.License.LicenseURL(hasAccess(accesscontrol.ReqGrafanaAdmin, accesscontrol.LicensingPageReaderAccess)),
+			Edition:         hs.License.Edition(),
 		},
-		"featureToggles":                   hs.Cfg.FeatureToggles,
-		"rendererAvailable":                hs.RenderService.IsAvailable(),
-		"rendererVersion":                  hs.RenderService.Version(),
-		"http2Enabled":                     hs.Cfg.Protocol == setting.HTTP2Scheme,
-		"sentry":                           hs.Cfg.Sentry,
-		"pluginCatalogURL":                 hs.Cfg.PluginCatalogURL,
-		"pluginAdminEnabled":               hs.Cfg.PluginAdminEnabled,
-		"pluginAdminExternalManageEnabled": hs.Cfg.PluginAdminEnabled && hs.Cfg.PluginAdminExternalManageEnabled,
-		"expressionsEnabled":               hs.Cfg.ExpressionsEnabled,
-		"awsAllowedAuthProviders":          hs.Cfg.AWSAllowedAuthProviders,
-		"awsAssumeRoleEnabled":             hs.Cfg.AWSAssumeRoleEnabled,
-		"azure": map[string]interface{}{
-			"cloud":                  hs.Cfg.Azure.Cloud,
-			"managedIdentityEnabled": hs.Cfg.Azure.ManagedIdentityEnabled,
+		FeatureToggles:                   hs.FeatureToggles.GetToggles(),
+		RendererAvailable:                hs.RenderService.IsAvailable(),
+		RendererVersion:                  hs.RenderService.Version(),
+		Http2Enabled:                     hs.Cfg.Protocol == setting.HTTP2Scheme,
+		Sentry:                           hs.Cfg.Sentry,
+		PluginCatalogURL:                 hs.Cfg.PluginCatalogURL,
+		PluginAdminEnabled:               hs.Cfg.PluginAdminEnabled,
+		PluginAdminExternalManageEnabled: hs.Cfg.PluginAdminEnabled && hs.Cfg.PluginAdminExternalManageEnabled,
+		ExpressionsEnabled:               hs.Cfg.ExpressionsEnabled,
+		AwsAllowedAuthProviders:          hs.Cfg.AWSAllowedAuthProviders,
+		AwsAssumeRoleEnabled:             hs.Cfg.AWSAssumeRoleEnabled,
+		Azure: dtos.FrontendSettingsAzure{
+			Cloud:                  hs.Cfg.Azure.Cloud,
+			ManagedIdentityEnabled: hs.Cfg.Azure.ManagedIdentityEnabled,
 		},
-		"caching": map[string]bool{
-			"enabled": hs.Cfg.SectionWithEnvOverrides("caching").Key("enabled").MustBool(true),
+		Caching: dtos.FrontendSettingsCaching{
+			Enabled: hs.Cfg.SectionWithEnvOverrides("caching").Key("enabled").MustBool(true),
 		},
-		"unifiedAlertingEnabled": hs.Cfg.UnifiedAlerting.Enabled,
+		Branding:               hs.Cfg.Branding,
+		UnifiedAlertingEnabled: hs.Cfg.UnifiedAlerting.Enabled,
 	}
 
+	applyOrgFeatureToggleOverrides(frontendSettings, overrides)
+	applyOrgDefaultThemeOverride(frontendSettings, overrides)
+
 	if hs.Cfg.GeomapDefaultBaseLayerConfig != nil {
-		jsonObj["geomapDefaultBaseLayerConfig"] = hs.Cfg.GeomapDefaultBaseLayerConfig
+		frontendSettings.GeomapDefaultBaseLayerConfig = hs.Cfg.GeomapDefaultBaseLayerConfig
 	}
 	if !hs.Cfg.GeomapEnableCustomBaseLayers {
-		jsonObj["geomapDisableCustomBaseLayer"] = true
+		frontendSettings.GeomapDisableCustomBaseLayer = true
+	}
+
+	return frontendSettings
+}
+
+// getFrontendPluginErrors returns the plugin errors shown as warnings in the UI. They're only
+// exposed to signed in users as they reveal which plugins are installed.
+func (hs *HTTPServer) getFrontendPluginErrors(c *models.ReqContext) []plugins.PluginError {
+	if !c.IsSignedIn {
+		return []plugins.PluginError{}
+	}
+
+	return hs.PluginManager.ScanningErrors()
+}
+
+// getFrontendPluginAdvisories returns the security advisories affecting the installed plugins, shown as warnings in
+// the UI. Like the plugin errors, they're only exposed to signed in users.
+func (hs *HTTPServer) getFrontendPluginAdvisories(c *models.ReqContext) map[string][]plugins.PluginAdvisory {
+	advisories := map[string][]plugins.PluginAdvisory{}
+	if !c.IsSignedIn {
+		return advisories
+	}
+
+	for _, p := range hs.PluginManager.Plugins() {
+		if len(p.Advisories) > 0 {
+			advisories[p.Id] = p.Advisories
+		}
+	}
+	return advisories
+}
+
+// getPluginAssetVersions returns the versions of the assets of the plugins, which the frontend adds to the URLs of
+// their assets so they're cached as immutable.
+func (hs *HTTPServer) getPluginAssetVersions() map[string]string {
+	if !hs.Cfg.PluginsVersionedAssetURLs {
+		return nil
+	}
+
+	versions := map[string]string{}
+	for _, p := range hs.PluginManager.Plugins() {
+		if p.AssetVersion != "" {
+			versions[p.Id] = p.AssetVersion
+		}
+	}
+	return versions
+}
+
+// getPluginsToPreload returns the modules of the plugins that should be preloaded, in the order the
+// frontend should load them.
+func getPluginsToPreload(candidates []*plugins.PluginBase) []string {
+	pluginsToPreload := []string{}
+	for _, p := range plugins.SortPreloadPlugins(candidates) {
+		pluginsToPreload = append(pluginsToPreload, p.Module)
+	}
+	return pluginsToPreload
+}
+
+func getAppPreloadCandidates(enabledPlugins *plugins.EnabledPlugins, access *frontendSettingsAccess) []*plugins.PluginBase {
+	candidates := []*plugins.PluginBase{}
+	for _, app := range enabledPlugins.Apps {
+		if app.Preload && access.canUsePlugin(app.Id) {
+			candidates = append(candidates, &app.PluginBase)
+		}
+	}
+	return candidates
+}
+
+func getDataSourcePreloadCandidates(dataSources map[string]*dtos.FrontendSettingsDataSource) []*plugins.PluginBase {
+	candidates := []*plugins.PluginBase{}
+	for _, ds := range dataSources {
+		if ds.Meta != nil && ds.Meta.Preload {
+			candidates = append(candidates, &ds.Meta.PluginBase)
+		}
+	}
+	return candidates
+}
+
+// getPanelPreloadCandidates returns the preloaded panels among the ones that are exposed to the frontend.
+func getPanelPreloadCandidates(enabledPlugins *plugins.EnabledPlugins, panels map[string]*dtos.FrontendSettingsPanel) []*plugins.PluginBase {
+	candidates := []*plugins.PluginBase{}
+	for _, panel := range enabledPlugins.Panels {
+		if _, exists := panels[panel.Id]; exists && panel.Preload {
+			candidates = append(candidates, &panel.PluginBase)
+		}
+	}
+	return candidates
+}
+
+// applyOrgFeatureToggleOverrides merges the org specific feature toggles into the frontend settings.
+func applyOrgFeatureToggleOverrides(settings *dtos.FrontendSettingsDTO, overrides *orgsettings.FrontendSettingsOverrides) {
+	if len(overrides.FeatureToggles) == 0 {
+		return
+	}
+
+	// copy the toggles so that the instance wide configuration isn't modified
+	featureToggles := make(map[string]bool, len(settings.FeatureToggles)+len(overrides.FeatureToggles))
+	for name, enabled := range settings.FeatureToggles {
+		featureToggles[name] = enabled
+	}
+	for name, enabled := range overrides.FeatureToggles {
+		featureToggles[name] = enabled
+	}
+	settings.FeatureToggles = featureToggles
+}
+
+// applyOrgDefaultThemeOverride replaces the instance wide default theme with the one of the organization, if set.
+func applyOrgDefaultThemeOverride(settings *dtos.FrontendSettingsDTO, overrides *orgsettings.FrontendSettingsOverrides) {
+	if overrides.DefaultTheme != "" {
+		settings.DefaultTheme = overrides.DefaultTheme
 	}
+}
 
-	return jsonObj, nil
+// defaultPanelSort is the sort value of panels that are not part of the configured sort order.
+const defaultPanelSort = 100
+
+// getPanelSortOrder returns the sort value of each panel listed in the sort order configuration. Panels
+// listed by the organization come first, followed by the ones in the instance wide configuration.
+func getPanelSortOrder(global []string, org []string) map[string]int {
+	sortOrder := make(map[string]int, len(global)+len(org))
+	for _, ids := range [][]string{org, global} {
+		for _, id := range ids {
+			if _, exists := sortOrder[id]; !exists {
+				sortOrder[id] = len(sortOrder) + 1
+			}
+		}
+	}
+	return sortOrder
 }
 
-func getPanelSort(id string) int {
-	sort := 100
-	switch id {
-	case "timeseries":
-		sort = 1
-	case "barchart":
-		sort = 2
-	case "stat":
-		sort = 3
-	case "gauge":
-		sort = 4
-	case "bargauge":
-		sort = 5
-	case "table":
-		sort = 6
-	case "singlestat":
-		sort = 7
-	case "piechart":
-		sort = 8
-	case "state-timeline":
-		sort = 9
-	case "heatmap":
-		sort = 10
-	case "status-history":
-		sort = 11
-	case "histogram":
-		sort = 12
-	case "graph":
-		sort = 13
-	case "text":
-		sort = 14
-	case "alertlist":
-		sort = 15
-	case "dashlist":
-		sort = 16
-	case "news":
-		sort = 17
-	}
-	return sort
+func getPanelSort(sortOrder map[string]int, id string) int {
+	if sort, exists := sortOrder[id]; exists {
+		return sort
+	}
+
+	if len(sortOrder) >= defaultPanelSort {
+		return len(sortOrder) + 1
+	}
+	return defaultPanelSort
 }
 
 func (hs *HTTPServer) GetFrontendSettings(c *models.ReqContext) {
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// frontendSettingsAccess decides which data sources and plugins are part of the frontend settings of the
+// signed in user. The permissions are fetched once per request, as they are evaluated for every data
+// source and plugin. With access control disabled everything enabled for the organization is exposed.
+type frontendSettingsAccess struct {
+	enabled     bool
+	permissions map[string]map[string]struct{}
+}
+
+func (hs *HTTPServer) getFrontendSettingsAccess(c *models.ReqContext) (*frontendSettingsAccess, error) {
+	if hs.AccessControl.IsDisabled() {
+		return &frontendSettingsAccess{}, nil
+	}
+
+	permissions, err := hs.AccessControl.GetUserPermissions(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &frontendSettingsAccess{
+		enabled:     true,
+		permissions: accesscontrol.GroupScopesByAction(permissions),
+	}, nil
+}
+
+// canQueryDataSource returns whether the data source can be queried by the user.
+func (a *frontendSettingsAccess) canQueryDataSource(ds *models.DataSource) bool {
+	return a.evaluate(accesscontrol.EvalAny(
+		accesscontrol.EvalPermission(ActionDatasourcesQuery, accesscontrol.Scope("datasources", "id", strconv.FormatInt(ds.Id, 10))),
+		accesscontrol.EvalPermission(ActionDatasourcesQuery, accesscontrol.Scope("datasources", "uid", ds.Uid)),
+		accesscontrol.EvalPermission(ActionDatasourcesQuery, accesscontrol.Scope("datasources", "name", ds.Name)),
+	))
+}
+
+// canUsePlugin returns whether the panel, data source or app plugin can be used by the user.
+func (a *frontendSettingsAccess) canUsePlugin(pluginID string) bool {
+	return a.evaluate(accesscontrol.EvalPermission(ActionPluginsRead, accesscontrol.Scope("plugins", "id", pluginID)))
+}
+
+func (a *frontendSettingsAccess) evaluate(evaluator accesscontrol.Evaluator) bool {
+	if !a.enabled {
+		return true
+	}
+
+	hasAccess, err := evaluator.Evaluate(a.permissions)
+	if err != nil {
+		plog.Error("Failed to evaluate frontend settings access", "permission", evaluator.String(), "error", err)
+		return false
+	}
+	return hasAccess
+}
@@ -1,19 +1,29 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/manager"
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/featuretoggles"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -50,12 +60,19 @@ func setupTestEnvironment(t *testing.T, cfg *setting.Cfg) (*web.Mux, *HTTPServer
 		SQLStore:      sqlStore,
 		PluginManager: pm,
 		AccessControl: accesscontrolmock.New().WithDisabled(),
+		CacheService:  localcache.ProvideService(),
+
+		OrgSettingsService: orgsettings.ProvideService(kvstore.ProvideService(sqlStore)),
+		FeatureToggles:     featuretoggles.New(cfg.FeatureToggles),
 	}
 
 	m := web.New()
 	m.Use(getContextHandler(t, cfg).Middleware)
 	m.UseMiddleware(web.Renderer(filepath.Join(setting.StaticRootPath, "views"), "[[", "]]"))
 	m.Get("/api/frontend/settings/", hs.GetFrontendSettings)
+	m.Get("/api/bootdata/settings", routing.Wrap(hs.GetBootDataSettings))
+	m.Get("/api/bootdata/datasources", routing.Wrap(hs.GetBootDataDataSources))
+	m.Get("/api/bootdata/panels", routing.Wrap(hs.GetBootDataPanels))
 
 	return m, hs
 }
@@ -128,3 +145,326 @@ func TestHTTPServer_GetFrontendSettings_hideVersionAnonyomus(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPServer_GetFrontendSettings_contract(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.PluginCatalogURL = "https://grafana.com/grafana/plugins/"
+	cfg.FeatureToggles = map[string]bool{"someToggle": true}
+	m, _ := setupTestEnvironment(t, cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/frontend/settings", nil)
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	got := dtos.FrontendSettingsDTO{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Equal(t, "-- Grafana --", got.DefaultDatasource)
+	assert.Equal(t, cfg.PluginCatalogURL, got.PluginCatalogURL)
+	assert.Equal(t, map[string]bool{"someToggle": true}, got.FeatureToggles)
+	assert.NotNil(t, got.PluginsToPreload)
+
+	raw := map[string]json.RawMessage{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &raw))
+	for _, key := range []string{"datasources", "panels", "buildInfo", "licenseInfo", "featureToggles", "azure", "caching", "pluginErrors", "pluginAdvisories"} {
+		assert.Contains(t, raw, key)
+	}
+	assert.NotContains(t, raw, "dateFormats")
+}
+
+func TestHTTPServer_GetBootData(t *testing.T) {
+	cfg := setting.NewCfg()
+	m, hs := setupTestEnvironment(t, cfg)
+	grafanaPlugin := &plugins.DataSourcePlugin{
+		FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "grafana", Type: "datasource", Name: grafanads.DatasourceName}},
+		BuiltIn:            true,
+	}
+	hs.PluginManager = &fakePluginManager{
+		Manager:     hs.PluginManager,
+		dataSources: []*plugins.DataSourcePlugin{grafanaPlugin},
+	}
+
+	get := func(t *testing.T, url string, result interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		recorder := httptest.NewRecorder()
+		m.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), result))
+		return recorder
+	}
+
+	t.Run("Settings don't include data sources and panels", func(t *testing.T) {
+		raw := map[string]json.RawMessage{}
+		get(t, "/api/bootdata/settings", &raw)
+		assert.Contains(t, raw, "buildInfo")
+		assert.NotContains(t, raw, "datasources")
+		assert.NotContains(t, raw, "panels")
+		assert.NotContains(t, raw, "defaultDatasource")
+	})
+
+	t.Run("Data sources include the default data source", func(t *testing.T) {
+		got := dtos.BootDataDataSources{}
+		get(t, "/api/bootdata/datasources", &got)
+		assert.Equal(t, "-- Grafana --", got.DefaultDatasource)
+		assert.Contains(t, got.Datasources, "-- Grafana --")
+		assert.Contains(t, got.DatasourcesByUid, grafanads.DatasourceUID)
+		grafana := got.Datasources["-- Grafana --"]
+		require.NotNil(t, grafana)
+		assert.Equal(t, "grafana", grafana.PluginId)
+		assert.Nil(t, grafana.Meta, "plugin meta should only be part of the plugin meta map")
+		assert.Contains(t, got.PluginMeta, "grafana")
+	})
+
+	t.Run("Panels are cached", func(t *testing.T) {
+		got := dtos.BootDataPanels{}
+		recorder := get(t, "/api/bootdata/panels", &got)
+		assert.Equal(t, "private, max-age=60", recorder.Header().Get("Cache-Control"))
+
+		_, found := hs.CacheService.Get(bootDataPanelsCacheKey(0))
+		assert.True(t, found)
+	})
+}
+
+func TestGetBootDataDataSources_builtIn(t *testing.T) {
+	grafanaPlugin := &plugins.DataSourcePlugin{
+		FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "grafana", Type: "datasource", Name: grafanads.DatasourceName}},
+		BuiltIn:            true,
+	}
+	hs := &HTTPServer{
+		Cfg:           setting.NewCfg(),
+		PluginManager: &fakePluginManager{dataSources: []*plugins.DataSourcePlugin{grafanaPlugin}},
+	}
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{}}
+
+	got, err := hs.getBootDataDataSources(c, &plugins.EnabledPlugins{}, &orgsettings.FrontendSettingsOverrides{}, &frontendSettingsAccess{})
+	require.NoError(t, err)
+
+	require.Contains(t, got.Datasources, grafanads.DatasourceName)
+	grafana := got.Datasources[grafanads.DatasourceName]
+	assert.Equal(t, "grafana", grafana.PluginId)
+	assert.Same(t, grafana, got.DatasourcesByUid[grafanads.DatasourceUID])
+	assert.Same(t, grafanaPlugin, got.PluginMeta["grafana"])
+
+	raw, err := json.Marshal(grafana)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), `"meta"`, "plugin meta should only be part of the plugin meta map")
+}
+
+func TestGetBootDataPanels_orgOverrides(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.PanelsSortOrder = []string{"timeseries", "graph"}
+	cfg.PanelsHidden = []string{"singlestat"}
+	hs := &HTTPServer{
+		Cfg:          cfg,
+		CacheService: localcache.ProvideService(),
+	}
+	enabledPlugins := &plugins.EnabledPlugins{
+		Panels: []*plugins.PanelPlugin{
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "graph", Module: "app/plugins/panel/graph/module"}}},
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "timeseries", Module: "app/plugins/panel/timeseries/module"}}},
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "singlestat", Module: "app/plugins/panel/singlestat/module"}}},
+		},
+	}
+
+	panels := hs.getBootDataPanels(1, enabledPlugins, &orgsettings.FrontendSettingsOverrides{
+		HiddenPanels:   []string{"graph", "unknown"},
+		PanelSortOrder: []string{"graph"},
+	})
+	assert.True(t, panels.Panels["graph"].HideFromList)
+	assert.False(t, panels.Panels["timeseries"].HideFromList)
+	assert.True(t, panels.Panels["singlestat"].HideFromList)
+	assert.Equal(t, 1, panels.Panels["graph"].Sort)
+	assert.Equal(t, 2, panels.Panels["timeseries"].Sort)
+	assert.Equal(t, defaultPanelSort, panels.Panels["singlestat"].Sort)
+
+	cached := hs.getBootDataPanels(1, enabledPlugins, &orgsettings.FrontendSettingsOverrides{})
+	assert.Same(t, panels, cached)
+
+	other := hs.getBootDataPanels(2, enabledPlugins, &orgsettings.FrontendSettingsOverrides{})
+	assert.False(t, other.Panels["graph"].HideFromList)
+	assert.Equal(t, 2, other.Panels["graph"].Sort)
+}
+
+func TestGetBootDataPanels_cacheInvalidation(t *testing.T) {
+	hs := &HTTPServer{
+		Cfg:                setting.NewCfg(),
+		CacheService:       localcache.ProvideService(),
+		OrgSettingsService: orgsettings.ProvideService(kvstore.ProvideService(sqlstore.InitTestDB(t))),
+	}
+	enabledPlugins := &plugins.EnabledPlugins{
+		Panels: []*plugins.PanelPlugin{
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "graph", Module: "app/plugins/panel/graph/module"}}},
+		},
+	}
+	getPanels := func(t *testing.T, orgID int64) *dtos.BootDataPanels {
+		t.Helper()
+		overrides, err := hs.getOrgFrontendSettingsOverrides(context.Background(), orgID)
+		require.NoError(t, err)
+		return hs.getBootDataPanels(orgID, enabledPlugins, overrides)
+	}
+
+	t.Run("Overrides are visible on the next request", func(t *testing.T) {
+		require.False(t, getPanels(t, 1).Panels["graph"].HideFromList)
+
+		c := &models.ReqContext{
+			Context:      &web.Context{Req: httptest.NewRequest(http.MethodPut, "/api/org/frontend-settings", nil)},
+			SignedInUser: &models.SignedInUser{OrgId: 1},
+		}
+		resp := hs.UpdateOrgFrontendSettingsOverrides(c, orgsettings.FrontendSettingsOverrides{HiddenPanels: []string{"graph"}})
+		require.Equal(t, http.StatusOK, resp.Status())
+		assert.True(t, getPanels(t, 1).Panels["graph"].HideFromList)
+
+		resp = hs.DeleteOrgFrontendSettingsOverrides(c)
+		require.Equal(t, http.StatusOK, resp.Status())
+		assert.False(t, getPanels(t, 1).Panels["graph"].HideFromList)
+	})
+
+	t.Run("Installing or uninstalling a plugin removes the panels of all organizations", func(t *testing.T) {
+		getPanels(t, 1)
+		getPanels(t, 2)
+		hs.CacheService.Set("other", true, bootDataPanelsCacheTTL)
+
+		hs.deleteBootDataPanelsCache()
+
+		for _, orgID := range []int64{1, 2} {
+			_, found := hs.CacheService.Get(bootDataPanelsCacheKey(orgID))
+			assert.False(t, found)
+		}
+		_, found := hs.CacheService.Get("other")
+		assert.True(t, found)
+	})
+}
+
+func TestFilterBootDataPanels(t *testing.T) {
+	enabledPlugins := &plugins.EnabledPlugins{
+		Panels: []*plugins.PanelPlugin{
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "graph", Module: "app/plugins/panel/graph/module", Preload: true}}},
+			{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "secret", Module: "plugins/secret/module", Preload: true}}},
+		},
+	}
+	orgPanels := &dtos.BootDataPanels{
+		Panels: map[string]*dtos.FrontendSettingsPanel{
+			"graph":  {Id: "graph"},
+			"secret": {Id: "secret"},
+		},
+		PluginsToPreload: []string{"app/plugins/panel/graph/module", "plugins/secret/module"},
+	}
+
+	t.Run("Access control disabled", func(t *testing.T) {
+		assert.Same(t, orgPanels, filterBootDataPanels(orgPanels, enabledPlugins, &frontendSettingsAccess{}))
+	})
+
+	t.Run("Access control enabled", func(t *testing.T) {
+		access := &frontendSettingsAccess{
+			enabled:     true,
+			permissions: map[string]map[string]struct{}{ActionPluginsRead: {"plugins:id:graph": {}}},
+		}
+
+		panels := filterBootDataPanels(orgPanels, enabledPlugins, access)
+		assert.Equal(t, []string{"graph"}, panelIDs(panels.Panels))
+		assert.Equal(t, []string{"app/plugins/panel/graph/module"}, panels.PluginsToPreload)
+		assert.Len(t, orgPanels.Panels, 2, "cached panels should not be modified")
+	})
+}
+
+func TestFrontendSettingsAccess(t *testing.T) {
+	ds := &models.DataSource{Id: 1, Uid: "abc", Name: "Prometheus"}
+
+	t.Run("Access control disabled", func(t *testing.T) {
+		access := &frontendSettingsAccess{}
+		assert.True(t, access.canQueryDataSource(ds))
+		assert.True(t, access.canUsePlugin("graph"))
+	})
+
+	tests := []struct {
+		desc       string
+		scope      string
+		canQueryDS bool
+	}{
+		{desc: "All data sources", scope: "datasources:*", canQueryDS: true},
+		{desc: "Data source ID", scope: "datasources:id:1", canQueryDS: true},
+		{desc: "Data source UID", scope: "datasources:uid:abc", canQueryDS: true},
+		{desc: "Data source name", scope: "datasources:name:Prometheus", canQueryDS: true},
+		{desc: "Other data source", scope: "datasources:uid:def", canQueryDS: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			access := &frontendSettingsAccess{
+				enabled: true,
+				permissions: map[string]map[string]struct{}{
+					ActionDatasourcesQuery: {tt.scope: {}},
+					ActionPluginsRead:      {"plugins:id:graph": {}},
+				},
+			}
+			assert.Equal(t, tt.canQueryDS, access.canQueryDataSource(ds))
+			assert.True(t, access.canUsePlugin("graph"))
+			assert.False(t, access.canUsePlugin("table"))
+		})
+	}
+}
+
+func panelIDs(panels map[string]*dtos.FrontendSettingsPanel) []string {
+	keys := make([]string, 0, len(panels))
+	for id := range panels {
+		keys = append(keys, id)
+	}
+	return keys
+}
+
+func TestApplyOrgFeatureToggleOverrides(t *testing.T) {
+	globalToggles := map[string]bool{"live": true, "tempoSearch": false}
+	settings := &dtos.FrontendSettingsDTO{FeatureToggles: globalToggles}
+
+	applyOrgFeatureToggleOverrides(settings, &orgsettings.FrontendSettingsOverrides{
+		FeatureToggles: map[string]bool{"tempoSearch": true},
+	})
+
+	assert.Equal(t, map[string]bool{"live": true, "tempoSearch": true}, settings.FeatureToggles)
+	assert.Equal(t, map[string]bool{"live": true, "tempoSearch": false}, globalToggles, "global toggles should not be modified")
+}
+
+func TestApplyOrgDefaultThemeOverride(t *testing.T) {
+	settings := &dtos.FrontendSettingsDTO{DefaultTheme: "dark"}
+
+	applyOrgDefaultThemeOverride(settings, &orgsettings.FrontendSettingsOverrides{})
+	assert.Equal(t, "dark", settings.DefaultTheme)
+
+	applyOrgDefaultThemeOverride(settings, &orgsettings.FrontendSettingsOverrides{DefaultTheme: "light"})
+	assert.Equal(t, "light", settings.DefaultTheme)
+}
+
+func TestGetPanelSort(t *testing.T) {
+	sortOrder := getPanelSortOrder([]string{"timeseries", "table", "graph"}, []string{"table", "piechart"})
+
+	assert.Equal(t, 1, getPanelSort(sortOrder, "table"))
+	assert.Equal(t, 2, getPanelSort(sortOrder, "piechart"))
+	assert.Equal(t, 3, getPanelSort(sortOrder, "timeseries"))
+	assert.Equal(t, 4, getPanelSort(sortOrder, "graph"))
+	assert.Equal(t, defaultPanelSort, getPanelSort(sortOrder, "news"))
+}
+
+func TestHasDirectAccessCredentials(t *testing.T) {
+	tests := []struct {
+		desc     string
+		ds       *models.DataSource
+		expected bool
+	}{
+		{desc: "No credentials", ds: &models.DataSource{Type: models.DS_PROMETHEUS}, expected: false},
+		{desc: "Basic auth", ds: &models.DataSource{Type: models.DS_PROMETHEUS, BasicAuth: true}, expected: true},
+		{desc: "InfluxDB without user", ds: &models.DataSource{Type: models.DS_INFLUXDB}, expected: false},
+		{desc: "InfluxDB with user", ds: &models.DataSource{Type: models.DS_INFLUXDB, User: "admin"}, expected: true},
+		{
+			desc:     "InfluxDB 0.8 with encrypted password",
+			ds:       &models.DataSource{Type: models.DS_INFLUXDB_08, SecureJsonData: map[string][]byte{"password": []byte("secret")}},
+			expected: true,
+		},
+		{desc: "Elasticsearch with user", ds: &models.DataSource{Type: models.DS_ES, User: "elastic"}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, hasDirectAccessCredentials(test.ds))
+		})
+	}
+}
@@ -2,10 +2,15 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
@@ -20,3 +25,193 @@ func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
 	hs.CacheService.Set(cacheKey, healthy, time.Second*5)
 	return healthy
 }
+
+// Statuses of the detailed health report and of its subsystems. Grafana is failing when it can't serve requests, i.e.
+// when the database or the default encryption provider are failing, and degraded when other subsystems are.
+const (
+	healthStatusOK       = "ok"
+	healthStatusDegraded = "degraded"
+	healthStatusFailing  = "failing"
+	healthStatusDisabled = "disabled"
+)
+
+// detailedHealthCacheTTL is how long the detailed health report is cached, so that frequent probes don't check the
+// encryption providers and the renderer on each request.
+const detailedHealthCacheTTL = 10 * time.Second
+
+// detailedHealth is the health of Grafana with the breakdown by subsystem.
+type detailedHealth struct {
+	Status     string                     `json:"status"`
+	Subsystems map[string]subsystemHealth `json:"subsystems"`
+}
+
+type subsystemHealth struct {
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// healthzDetailsHandler returns the health of the database, the encryption providers, the image renderer and the
+// plugins. The status code is 503 if Grafana is failing, and 200 otherwise, also when it's degraded.
+func (hs *HTTPServer) healthzDetailsHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/healthz/details" {
+		return
+	}
+
+	health := hs.detailedHealth(ctx.Req.Context())
+	status := http.StatusOK
+	if health.Status == healthStatusFailing {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, health)
+}
+
+func (hs *HTTPServer) detailedHealth(ctx context.Context) *detailedHealth {
+	const cacheKey = "detailed-health"
+
+	if cached, found := hs.CacheService.Get(cacheKey); found {
+		return cached.(*detailedHealth)
+	}
+
+	health := &detailedHealth{Status: healthStatusOK, Subsystems: map[string]subsystemHealth{}}
+	health.Subsystems["database"] = subsystemHealth{Status: healthStatusOK}
+	if !hs.databaseHealthy(ctx) {
+		health.Subsystems["database"] = subsystemHealth{Status: healthStatusFailing}
+	}
+	if hs.SecretsService != nil {
+		health.Subsystems["secrets"] = hs.secretsHealth(ctx)
+	}
+	if hs.RenderService != nil {
+		health.Subsystems["renderer"] = hs.rendererHealth(ctx)
+	}
+	if hs.PluginManager != nil {
+		health.Subsystems["plugins"] = hs.pluginsHealth()
+	}
+
+	for _, subsystem := range health.Subsystems {
+		switch {
+		case subsystem.Status == healthStatusFailing:
+			health.Status = healthStatusFailing
+		case subsystem.Status == healthStatusDegraded && health.Status == healthStatusOK:
+			health.Status = healthStatusDegraded
+		}
+	}
+
+	hs.CacheService.Set(cacheKey, health, detailedHealthCacheTTL)
+	return health
+}
+
+// secretsHealth is failing if the default encryption provider, which encrypts the new data keys, isn't reachable,
+// and degraded if another configured provider isn't.
+func (hs *HTTPServer) secretsHealth(ctx context.Context) subsystemHealth {
+	health := subsystemHealth{Status: healthStatusOK}
+	providers := map[string]interface{}{}
+	for id, result := range hs.SecretsService.CheckProviders(ctx) {
+		if result.Error == nil {
+			providers[id] = healthStatusOK
+			continue
+		}
+		hs.log.Warn("Encryption provider health check failed", "provider", id, "error", result.Error)
+		providers[id] = healthStatusFailing
+		if result.Default {
+			health.Status = healthStatusFailing
+		} else if health.Status == healthStatusOK {
+			health.Status = healthStatusDegraded
+		}
+	}
+	health.Details = map[string]interface{}{"providers": providers}
+	return health
+}
+
+// rendererHealth is disabled when no image renderer is installed, and degraded when its health check fails.
+func (hs *HTTPServer) rendererHealth(ctx context.Context) subsystemHealth {
+	status := hs.RenderService.Status(ctx)
+	if !status.Available {
+		return subsystemHealth{Status: healthStatusDisabled}
+	}
+
+	health := subsystemHealth{
+		Status:  healthStatusOK,
+		Details: map[string]interface{}{"mode": status.Mode, "recentFailures": status.RecentFailures},
+	}
+	if status.Health != backend.HealthStatusOk.String() {
+		health.Status = healthStatusDegraded
+		health.Message = status.HealthMessage
+	}
+	return health
+}
+
+// pluginsHealth reports the number of plugins, the ones quarantined, i.e. which weren't loaded because of their
+// signature or compatibility, and the failed ones, i.e. managed backend plugins whose process isn't running. The
+// plugins are degraded when some of them failed.
+func (hs *HTTPServer) pluginsHealth() subsystemHealth {
+	failed := 0
+	if reporter, ok := hs.BackendPluginManager.(backendplugin.StatusReporter); ok {
+		for _, status := range reporter.PluginStatuses() {
+			if status.Managed && !status.Running {
+				failed++
+			}
+		}
+	}
+
+	health := subsystemHealth{
+		Status: healthStatusOK,
+		Details: map[string]interface{}{
+			"loaded":      len(hs.PluginManager.Plugins()),
+			"quarantined": len(hs.PluginManager.ScanningErrors()),
+			"failed":      failed,
+		},
+	}
+	if failed > 0 {
+		health.Status = healthStatusDegraded
+		health.Message = fmt.Sprintf("%d backend plugins not running", failed)
+	}
+	return health
+}
+
+// readiness is whether Grafana is ready to serve traffic, with the plugins it's waiting for.
+type readiness struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message,omitempty"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// readyzHandler returns 200 once Grafana is ready to serve traffic, i.e. once the backend plugins are registered and
+// the plugins required by the ready_required_plugins setting are running, and 503 otherwise. Unlike /healthz, which
+// only reports that the web server is up, it's meant for the readiness probes of load balancers and orchestrators.
+func (hs *HTTPServer) readyzHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	if notHeadOrGet || ctx.Req.URL.Path != "/readyz" {
+		return
+	}
+
+	ready := hs.readiness()
+	status := http.StatusOK
+	if ready.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, ready)
+}
+
+func (hs *HTTPServer) readiness() readiness {
+	reporter, ok := hs.BackendPluginManager.(backendplugin.ReadinessReporter)
+	if !ok || !reporter.PluginsRegistered() {
+		return readiness{Status: "not ready", Message: "backend plugins not registered"}
+	}
+
+	statuses := map[string]backendplugin.PluginStatus{}
+	if statusReporter, ok := hs.BackendPluginManager.(backendplugin.StatusReporter); ok {
+		statuses = statusReporter.PluginStatuses()
+	}
+	var pending []string
+	for _, pluginID := range hs.Cfg.PluginsReadyRequired {
+		if status, exists := statuses[pluginID]; !exists || !status.Running {
+			pending = append(pending, pluginID)
+		}
+	}
+	if len(pending) > 0 {
+		return readiness{Status: "not ready", Message: "required plugins not running", Pending: pending}
+	}
+	return readiness{Status: "ready"}
+}
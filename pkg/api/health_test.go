@@ -11,6 +11,8 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/require"
@@ -168,6 +170,135 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+func TestHealthAPI_VulnerablePlugins(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t, func(cfg *setting.Cfg) {
+		cfg.BuildVersion = "7.4.0"
+		cfg.BuildCommit = "59906ab1bf"
+		cfg.UpdateCheckAdvisoriesURL = "https://advisories.example.com/plugins.json"
+	})
+	hs.PluginManager = &fakePluginManager{plugins: []*plugins.PluginBase{
+		{Id: "test-panel", Advisories: []plugins.PluginAdvisory{{ID: "GHSA-1234", PluginID: "test-panel"}}},
+		{Id: "test-app"},
+	}}
+
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDBHealthQuery) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	expectedBody := `
+		{
+			"database": "ok",
+			"version": "7.4.0",
+			"commit": "59906ab1bf",
+			"vulnerablePlugins": ["test-panel"]
+		}
+	`
+	require.JSONEq(t, expectedBody, rec.Body.String())
+}
+
+func TestHealthAPI_Details(t *testing.T) {
+	t.Run("Should report the subsystems", func(t *testing.T) {
+		m, hs := setupHealthAPITestEnvironment(t)
+		hs.PluginManager = &fakePluginManager{
+			plugins: []*plugins.PluginBase{{Id: "test-app"}, {Id: "test-panel"}},
+			errors:  []plugins.PluginError{{PluginID: "unsigned-panel"}},
+		}
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDBHealthQuery) error {
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/healthz/details", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 200, rec.Code)
+		expectedBody := `
+			{
+				"status": "ok",
+				"subsystems": {
+					"database": {"status": "ok"},
+					"plugins": {"status": "ok", "details": {"loaded": 2, "quarantined": 1, "failed": 0}}
+				}
+			}
+		`
+		require.JSONEq(t, expectedBody, rec.Body.String())
+	})
+
+	t.Run("Should be unavailable when the database is failing", func(t *testing.T) {
+		m, _ := setupHealthAPITestEnvironment(t)
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDBHealthQuery) error {
+			return errors.New("bad")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/healthz/details", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		require.Equal(t, 503, rec.Code)
+		expectedBody := `
+			{
+				"status": "failing",
+				"subsystems": {
+					"database": {"status": "failing"}
+				}
+			}
+		`
+		require.JSONEq(t, expectedBody, rec.Body.String())
+	})
+}
+
+func TestHealthAPI_Ready(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t, func(cfg *setting.Cfg) {
+		cfg.PluginsReadyRequired = []string{"test-datasource"}
+	})
+
+	ready := func() (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	backendPM := &fakeBackendPluginManager{statuses: map[string]backendplugin.PluginStatus{}}
+	hs.BackendPluginManager = backendPM
+	code, body := ready()
+	require.Equal(t, 503, code)
+	require.JSONEq(t, `{"status": "not ready", "message": "backend plugins not registered"}`, body)
+
+	backendPM.registered = true
+	backendPM.statuses["test-datasource"] = backendplugin.PluginStatus{Managed: true}
+	code, body = ready()
+	require.Equal(t, 503, code)
+	require.JSONEq(t, `{"status": "not ready", "message": "required plugins not running", "pending": ["test-datasource"]}`, body)
+
+	backendPM.statuses["test-datasource"] = backendplugin.PluginStatus{Managed: true, Running: true}
+	code, body = ready()
+	require.Equal(t, 200, code)
+	require.JSONEq(t, `{"status": "ready"}`, body)
+}
+
+type fakeBackendPluginManager struct {
+	backendplugin.Manager
+
+	registered bool
+	statuses   map[string]backendplugin.PluginStatus
+}
+
+func (m *fakeBackendPluginManager) PluginsRegistered() bool {
+	return m.registered
+}
+
+func (m *fakeBackendPluginManager) PluginStatuses() map[string]backendplugin.PluginStatus {
+	return m.statuses
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*web.Mux, *HTTPServer) {
 	t.Helper()
 
@@ -185,5 +316,7 @@ func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*we
 	}
 
 	m.Get("/api/health", hs.apiHealthHandler)
+	m.Get("/healthz/details", hs.healthzDetailsHandler)
+	m.Get("/readyz", hs.readyzHandler)
 	return m, hs
 }
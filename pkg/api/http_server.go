@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -30,14 +31,20 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	_ "github.com/grafana/grafana/pkg/plugins/backendplugin/manager"
+	"github.com/grafana/grafana/pkg/plugins/pluginassets"
+	"github.com/grafana/grafana/pkg/plugins/plugincatalog"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/plugins/plugindashboards"
+	"github.com/grafana/grafana/pkg/plugins/plugindocs"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasourcehealth"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/featuretoggles"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
@@ -47,11 +54,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
+	"github.com/grafana/grafana/pkg/services/pluginencryption"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/secretexpiry"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -111,6 +122,16 @@ type HTTPServer struct {
 	tracingService         *tracing.TracingService
 	internalMetricsSvc     *metrics.InternalMetricsService
 	searchUsersService     searchusers.Service
+	OrgSettingsService     *orgsettings.Service
+	FeatureToggles         *featuretoggles.Service
+	SecretsService         *secretsManager.SecretsService
+	PluginDashboardService *plugindashboards.Service
+	PluginDocsService      *plugindocs.Service
+	PluginCatalogService   *plugincatalog.Service
+	DataSourceHealth       *datasourcehealth.Service
+	SecretExpiry           *secretexpiry.Service
+	PluginEncryption       *pluginencryption.Service
+	PluginAssets           *pluginassets.Service
 }
 
 type ServerOptions struct {
@@ -135,7 +156,12 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	internalMetricsSvc *metrics.InternalMetricsService, quotaService *quota.QuotaService,
 	socialService social.Service, oauthTokenService oauthtoken.OAuthTokenService,
 	encryptionService encryption.Service, searchUsersService searchusers.Service,
-	dataSourcesService *datasources.Service) (*HTTPServer, error) {
+	dataSourcesService *datasources.Service, orgSettingsService *orgsettings.Service,
+	featureToggles *featuretoggles.Service, secretsService *secretsManager.SecretsService,
+	pluginDashboardService *plugindashboards.Service, pluginDocsService *plugindocs.Service,
+	dataSourceHealth *datasourcehealth.Service, pluginCatalogService *plugincatalog.Service,
+	secretExpiry *secretexpiry.Service, pluginEncryption *pluginencryption.Service,
+	pluginAssets *pluginassets.Service) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
 
@@ -184,6 +210,16 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		EncryptionService:      encryptionService,
 		DataSourcesService:     dataSourcesService,
 		searchUsersService:     searchUsersService,
+		OrgSettingsService:     orgSettingsService,
+		FeatureToggles:         featureToggles,
+		SecretsService:         secretsService,
+		PluginDashboardService: pluginDashboardService,
+		PluginDocsService:      pluginDocsService,
+		DataSourceHealth:       dataSourceHealth,
+		PluginCatalogService:   pluginCatalogService,
+		SecretExpiry:           secretExpiry,
+		PluginEncryption:       pluginEncryption,
+		PluginAssets:           pluginAssets,
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
@@ -425,6 +461,8 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	// These endpoints are used for monitoring the Grafana instance
 	// and should not be redirected or rejected.
 	m.Use(hs.healthzHandler)
+	m.Use(hs.healthzDetailsHandler)
+	m.Use(hs.readyzHandler)
 	m.Use(hs.apiHealthHandler)
 	m.Use(hs.metricsEndpoint)
 
@@ -491,6 +529,9 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	if !hs.Cfg.AnonymousHideVersion {
 		data.Set("version", hs.Cfg.BuildVersion)
 		data.Set("commit", hs.Cfg.BuildCommit)
+		if hs.Cfg.UpdateCheckAdvisoriesURL != "" {
+			data.Set("vulnerablePlugins", hs.vulnerablePluginIDs())
+		}
 	}
 
 	if !hs.databaseHealthy(ctx.Req.Context()) {
@@ -513,6 +554,18 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	}
 }
 
+// vulnerablePluginIDs returns the IDs of the installed plugins affected by security advisories.
+func (hs *HTTPServer) vulnerablePluginIDs() []string {
+	ids := []string{}
+	for _, p := range hs.PluginManager.Plugins() {
+		if len(p.Advisories) > 0 {
+			ids = append(ids, p.Id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func (hs *HTTPServer) mapStatic(m *web.Mux, rootDir string, dir string, prefix string) {
 	headers := func(c *web.Context) {
 		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"html/template"
 	"sort"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/navlinks"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -69,54 +71,26 @@ func (hs *HTTPServer) getAppLinks(c *models.ReqContext) ([]*dtos.NavLink, error)
 	if err != nil {
 		return nil, err
 	}
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return nil, err
+	}
 
 	appLinks := []*dtos.NavLink{}
 	for _, plugin := range enabledPlugins.Apps {
-		if !plugin.Pinned {
+		if !plugin.Pinned || !access.canUsePlugin(plugin.Id) {
 			continue
 		}
 
-		appLink := &dtos.NavLink{
-			Text:       plugin.Name,
-			Id:         "plugin-page-" + plugin.Id,
-			Url:        plugin.DefaultNavUrl,
-			Img:        plugin.Info.Logos.Small,
-			SortWeight: dtos.WeightPlugin,
-		}
-
-		for _, include := range plugin.Includes {
-			if !c.HasUserRole(include.Role) {
-				continue
-			}
-
-			if include.Type == "page" && include.AddToNav {
-				var link *dtos.NavLink
-				if len(include.Path) > 0 {
-					link = &dtos.NavLink{
-						Url:  hs.Cfg.AppSubURL + include.Path,
-						Text: include.Name,
-					}
-					if include.DefaultNav {
-						appLink.Url = link.Url // Overwrite the hardcoded page logic
-					}
-				} else {
-					link = &dtos.NavLink{
-						Url:  hs.Cfg.AppSubURL + "/plugins/" + plugin.Id + "/page/" + include.Slug,
-						Text: include.Name,
-					}
-				}
-				link.Icon = include.Icon
-				appLink.Children = append(appLink.Children, link)
-			}
-
-			if include.Type == "dashboard" && include.AddToNav {
-				link := &dtos.NavLink{
-					Url:  hs.Cfg.AppSubURL + include.GetSlugOrUIDLink(),
-					Text: include.Name,
-				}
-				appLink.Children = append(appLink.Children, link)
+		// the menu only lists the pages and dashboards added to the navigation
+		appLink := hs.getAppNavLink(c, plugin)
+		children := make([]*dtos.NavLink, 0, len(appLink.Children))
+		for _, child := range appLink.Children {
+			if !child.HideFromMenu {
+				children = append(children, child)
 			}
 		}
+		appLink.Children = children
 
 		if len(appLink.Children) > 0 {
 			appLinks = append(appLinks, appLink)
@@ -131,6 +105,56 @@ func (hs *HTTPServer) getAppLinks(c *models.ReqContext) ([]*dtos.NavLink, error)
 	return appLinks, nil
 }
 
+// getAppNavLink returns the navigation of an app plugin, built from the pages and dashboards it includes which the
+// user can access, see canAccessPluginInclude. The includes which aren't added to the navigation are hidden from the menu.
+func (hs *HTTPServer) getAppNavLink(c *models.ReqContext, plugin *plugins.AppPlugin) *dtos.NavLink {
+	appLink := &dtos.NavLink{
+		Text:       plugin.Name,
+		Id:         "plugin-page-" + plugin.Id,
+		Url:        plugin.DefaultNavUrl,
+		Img:        plugin.Info.Logos.Small,
+		SortWeight: dtos.WeightPlugin,
+		Children:   []*dtos.NavLink{},
+	}
+
+	for _, include := range plugin.Includes {
+		if !hs.canAccessPluginInclude(c, include) {
+			continue
+		}
+
+		switch include.Type {
+		case "page":
+			link := &dtos.NavLink{
+				Id:           "plugin-page-" + plugin.Id + "-" + include.Slug,
+				Text:         include.Name,
+				Icon:         include.Icon,
+				HideFromMenu: !include.AddToNav,
+			}
+			if len(include.Path) > 0 {
+				link.Url = hs.Cfg.AppSubURL + include.Path
+				if include.DefaultNav && include.AddToNav {
+					appLink.Url = link.Url // Overwrite the hardcoded page logic
+				}
+			} else {
+				link.Url = hs.Cfg.AppSubURL + "/plugins/" + plugin.Id + "/page/" + include.Slug
+			}
+			appLink.Children = append(appLink.Children, link)
+		case "dashboard":
+			dashboardID := include.Slug
+			if len(include.UID) > 0 {
+				dashboardID = include.UID
+			}
+			appLink.Children = append(appLink.Children, &dtos.NavLink{
+				Id:           "plugin-dashboard-" + plugin.Id + "-" + dashboardID,
+				Text:         include.Name,
+				Url:          hs.Cfg.AppSubURL + include.GetSlugOrUIDLink(),
+				HideFromMenu: !include.AddToNav,
+			})
+		}
+	}
+	return appLink
+}
+
 func (hs *HTTPServer) getNavTree(c *models.ReqContext, hasEditPerm bool) ([]*dtos.NavLink, error) {
 	hasAccess := ac.HasAccess(hs.AccessControl, c)
 	navTree := []*dtos.NavLink{}
@@ -437,9 +461,9 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 		return nil, err
 	}
 
-	settings["dateFormats"] = hs.Cfg.DateFormats
+	settings.DateFormats = &hs.Cfg.DateFormats
 
-	prefsQuery := models.GetPreferencesWithDefaultsQuery{User: c.SignedInUser}
+	prefsQuery := models.GetPreferencesWithDefaultsQuery{User: c.SignedInUser, DefaultTheme: settings.DefaultTheme}
 	if err := bus.DispatchCtx(c.Req.Context(), &prefsQuery); err != nil {
 		return nil, err
 	}
@@ -461,7 +485,7 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 	if c.IsRenderCall && !hs.Cfg.ServeFromSubPath {
 		appURL = fmt.Sprintf("%s://localhost:%s", hs.Cfg.Protocol, hs.Cfg.HTTPPort)
 		appSubURL = ""
-		settings["appSubUrl"] = ""
+		settings.AppSubUrl = ""
 	}
 
 	navTree, err := hs.getNavTree(c, hasEditPerm)
@@ -510,6 +534,8 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 		LoadingLogo:             "public/img/grafana_icon.svg",
 	}
 
+	hs.applyBranding(&data)
+
 	if hs.Cfg.FeatureToggles["accesscontrol"] {
 		userPermissions, err := hs.AccessControl.GetUserPermissions(c.Req.Context(), c.SignedInUser)
 		if err != nil {
@@ -545,6 +571,23 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 	return &data, nil
 }
 
+// applyBranding replaces the default title and icons of the index page with the configured ones.
+func (hs *HTTPServer) applyBranding(data *dtos.IndexViewData) {
+	branding := hs.Cfg.Branding
+	if branding.AppTitle != "" {
+		data.AppTitle = branding.AppTitle
+	}
+	if branding.FavIcon != "" {
+		// nolint:gosec
+		// The URL is set by the server administrator and not by users.
+		data.FavIcon = template.URL(branding.FavIcon)
+	}
+	if branding.AppleTouchIcon != "" {
+		// nolint:gosec
+		data.AppleTouchIcon = template.URL(branding.AppleTouchIcon)
+	}
+}
+
 func (hs *HTTPServer) Index(c *models.ReqContext) {
 	data, err := hs.setIndexViewData(c)
 	if err != nil {
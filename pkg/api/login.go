@@ -96,9 +96,9 @@ func (hs *HTTPServer) LoginView(c *models.ReqContext) {
 		enabledOAuths[key] = map[string]string{"name": oauth.Name}
 	}
 
-	viewData.Settings["oauth"] = enabledOAuths
-	viewData.Settings["samlEnabled"] = hs.samlEnabled()
-	viewData.Settings["samlName"] = hs.samlName()
+	viewData.Settings.OAuth = enabledOAuths
+	viewData.Settings.SamlEnabled = hs.samlEnabled()
+	viewData.Settings.SamlName = hs.samlName()
 
 	if loginError, ok := hs.tryGetEncryptedCookie(c, loginErrorCookieName); ok {
 		// this cookie is only set whenever an OAuth login fails
@@ -106,7 +106,7 @@ func (hs *HTTPServer) LoginView(c *models.ReqContext) {
 		// and the view should return immediately before attempting
 		// to login again via OAuth and enter to a redirect loop
 		cookies.DeleteCookie(c.Resp, loginErrorCookieName, hs.CookieOptionsFromCfg)
-		viewData.Settings["loginError"] = loginError
+		viewData.Settings.LoginError = loginError
 		c.HTML(200, getViewIndex(), viewData)
 		return
 	}
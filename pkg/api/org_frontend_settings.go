@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
+)
+
+// GET /api/org/frontend-settings
+func (hs *HTTPServer) GetOrgFrontendSettingsOverrides(c *models.ReqContext) response.Response {
+	overrides, err := hs.OrgSettingsService.GetFrontendSettingsOverrides(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to get frontend settings overrides", err)
+	}
+
+	return response.JSON(200, overrides)
+}
+
+// PUT /api/org/frontend-settings
+func (hs *HTTPServer) UpdateOrgFrontendSettingsOverrides(c *models.ReqContext, overrides orgsettings.FrontendSettingsOverrides) response.Response {
+	if overrides.DefaultTheme != "" && overrides.DefaultTheme != lightName && overrides.DefaultTheme != darkName {
+		return response.Error(400, "Default theme must be light or dark", nil)
+	}
+
+	if overrides.DefaultDatasource != "" {
+		query := models.GetDataSourceQuery{Name: overrides.DefaultDatasource, OrgId: c.OrgId}
+		if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+			if errors.Is(err, models.ErrDataSourceNotFound) {
+				return response.Error(400, "Default data source not found", err)
+			}
+			return response.Error(500, "Failed to query data source", err)
+		}
+	}
+
+	if err := hs.OrgSettingsService.SaveFrontendSettingsOverrides(c.Req.Context(), c.OrgId, &overrides); err != nil {
+		return response.Error(500, "Failed to save frontend settings overrides", err)
+	}
+	hs.CacheService.Delete(bootDataPanelsCacheKey(c.OrgId))
+
+	return response.Success("Frontend settings overrides updated")
+}
+
+// DELETE /api/org/frontend-settings
+func (hs *HTTPServer) DeleteOrgFrontendSettingsOverrides(c *models.ReqContext) response.Response {
+	if err := hs.OrgSettingsService.DeleteFrontendSettingsOverrides(c.Req.Context(), c.OrgId); err != nil {
+		return response.Error(500, "Failed to delete frontend settings overrides", err)
+	}
+	hs.CacheService.Delete(bootDataPanelsCacheKey(c.OrgId))
+
+	return response.Success("Frontend settings overrides deleted")
+}
+
+func (hs *HTTPServer) getOrgFrontendSettingsOverrides(ctx context.Context, orgID int64) (*orgsettings.FrontendSettingsOverrides, error) {
+	if orgID == 0 {
+		return &orgsettings.FrontendSettingsOverrides{}, nil
+	}
+
+	return hs.OrgSettingsService.GetFrontendSettingsOverrides(ctx, orgID)
+}
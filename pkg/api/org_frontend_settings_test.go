@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/orgsettings"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateOrgFrontendSettingsOverrides_invalidDefaultTheme(t *testing.T) {
+	hs := &HTTPServer{}
+	c := &models.ReqContext{
+		Context:      &web.Context{Req: httptest.NewRequest(http.MethodPut, "/api/org/frontend-settings", nil)},
+		SignedInUser: &models.SignedInUser{OrgId: testOrgID},
+	}
+
+	resp := hs.UpdateOrgFrontendSettingsOverrides(c, orgsettings.FrontendSettingsOverrides{DefaultTheme: "blue"})
+	assert.Equal(t, http.StatusBadRequest, resp.Status())
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// declarePluginRoles declares the roles of the loaded plugins to the AccessControl service. The plugins installed
+// afterwards have their roles registered when Grafana restarts.
+func (hs *HTTPServer) declarePluginRoles() error {
+	var registrations []ac.RoleRegistration
+	for _, plugin := range hs.PluginManager.Plugins() {
+		registrations = append(registrations, plugin.RoleRegistrations()...)
+	}
+	if len(registrations) == 0 {
+		return nil
+	}
+	return hs.AccessControl.DeclareFixedRoles(registrations...)
+}
+
+// hasPluginAccess returns whether the user can access an include or a resource route of a plugin. With access
+// control enabled, the user needs the action required by the include or the route, if any, e.g. granted by one of
+// the roles of the plugin, otherwise the fallback decides, i.e. the role of the include or the route.
+func (hs *HTTPServer) hasPluginAccess(c *models.ReqContext, fallback func(*models.ReqContext) bool, action string) bool {
+	if action == "" {
+		return fallback(c)
+	}
+	return ac.HasAccess(hs.AccessControl, c)(fallback, ac.EvalPermission(action))
+}
+
+func (hs *HTTPServer) canAccessPluginInclude(c *models.ReqContext, include *plugins.PluginInclude) bool {
+	return hs.hasPluginAccess(c, func(c *models.ReqContext) bool {
+		return c.HasUserRole(include.Role)
+	}, include.Action)
+}
+
+// checkPluginPageAccess redirects the users who can't access the page of the app plugin they request to the home
+// page, the page being either /plugins/:id/page/:page or the path of the page, under /a/:id.
+func (hs *HTTPServer) checkPluginPageAccess(c *models.ReqContext) {
+	params := web.Params(c.Req)
+	app := hs.PluginManager.GetApp(params[":id"])
+	if app == nil {
+		return
+	}
+
+	urlPath := strings.TrimPrefix(c.Req.URL.Path, hs.Cfg.AppSubURL)
+	for _, include := range app.Includes {
+		if include.Type != "page" {
+			continue
+		}
+		if (params[":page"] == "" || include.Slug != params[":page"]) && (include.Path == "" || include.Path != urlPath) {
+			continue
+		}
+		if !hs.canAccessPluginInclude(c, include) {
+			c.Redirect(hs.Cfg.AppSubURL + "/")
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/pluginencryption"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// checkPluginEncryptionCSRF protects the encryption API of app plugins against cross-site request forgery, like the
+// resource calls of the plugins.
+func (hs *HTTPServer) checkPluginEncryptionCSRF(c *models.ReqContext) {
+	hs.checkResourceCSRF(c)
+}
+
+// PluginEncrypt encrypts data for an app plugin, to be stored by the plugin and decrypted by PluginDecrypt.
+func (hs *HTTPServer) PluginEncrypt(c *models.ReqContext, cmd dtos.PluginEncryptionCmd) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if resp := hs.checkEncryptionPlugin(c, pluginID); resp != nil {
+		return resp
+	}
+
+	encrypted, err := hs.PluginEncryption.Encrypt(c.Req.Context(), pluginID, c.OrgId, pluginEncryptionCaller(c), cmd.Data)
+	if err != nil {
+		return pluginEncryptionError(err, "Failed to encrypt data")
+	}
+	return response.JSON(200, dtos.PluginEncryptionCmd{Data: encrypted})
+}
+
+// PluginDecrypt decrypts data encrypted by PluginEncrypt for the same app plugin and organization.
+func (hs *HTTPServer) PluginDecrypt(c *models.ReqContext, cmd dtos.PluginEncryptionCmd) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if resp := hs.checkEncryptionPlugin(c, pluginID); resp != nil {
+		return resp
+	}
+
+	decrypted, err := hs.PluginEncryption.Decrypt(c.Req.Context(), pluginID, c.OrgId, pluginEncryptionCaller(c), cmd.Data)
+	if err != nil {
+		return pluginEncryptionError(err, "Failed to decrypt data")
+	}
+	return response.JSON(200, dtos.PluginEncryptionCmd{Data: decrypted})
+}
+
+// PluginSign signs data for an app plugin, to be verified by PluginVerify.
+func (hs *HTTPServer) PluginSign(c *models.ReqContext, cmd dtos.PluginEncryptionCmd) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if resp := hs.checkEncryptionPlugin(c, pluginID); resp != nil {
+		return resp
+	}
+
+	signature, err := hs.PluginEncryption.Sign(pluginID, c.OrgId, pluginEncryptionCaller(c), cmd.Data)
+	if err != nil {
+		return pluginEncryptionError(err, "Failed to sign data")
+	}
+	return response.JSON(200, dtos.PluginEncryptionCmd{Signature: signature})
+}
+
+// PluginVerify verifies a signature returned by PluginSign for the same app plugin and organization.
+func (hs *HTTPServer) PluginVerify(c *models.ReqContext, cmd dtos.PluginEncryptionCmd) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if resp := hs.checkEncryptionPlugin(c, pluginID); resp != nil {
+		return resp
+	}
+
+	valid, err := hs.PluginEncryption.Verify(pluginID, c.OrgId, pluginEncryptionCaller(c), cmd.Data, cmd.Signature)
+	if err != nil {
+		return pluginEncryptionError(err, "Failed to verify signature")
+	}
+	return response.JSON(200, util.DynMap{"valid": valid})
+}
+
+// checkEncryptionPlugin checks that the plugin is an app enabled in the organization of the user, and returns the
+// error response otherwise.
+func (hs *HTTPServer) checkEncryptionPlugin(c *models.ReqContext, pluginID string) response.Response {
+	app := hs.PluginManager.GetApp(pluginID)
+	if app == nil {
+		return response.Error(404, "App plugin not found", nil)
+	}
+	if app.AutoEnabled {
+		return nil
+	}
+
+	query := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrPluginSettingNotFound) {
+			return response.Error(403, "App plugin not enabled", nil)
+		}
+		return response.Error(500, "Failed to get plugin settings", err)
+	}
+	if !query.Result.Enabled {
+		return response.Error(403, "App plugin not enabled", nil)
+	}
+	return nil
+}
+
+// pluginEncryptionCaller identifies the API key or the user calling, so that the calls are rate limited per caller.
+func pluginEncryptionCaller(c *models.ReqContext) string {
+	if c.ApiKeyId != 0 {
+		return "apikey:" + strconv.FormatInt(c.ApiKeyId, 10)
+	}
+	return "user:" + strconv.FormatInt(c.UserId, 10)
+}
+
+func pluginEncryptionError(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, pluginencryption.ErrRateLimited):
+		return response.Error(http.StatusTooManyRequests, "Plugin encryption rate limit exceeded", nil)
+	case errors.Is(err, pluginencryption.ErrInvalidPayload):
+		return response.Error(400, "Data not encrypted for this plugin", nil)
+	}
+	return response.Error(500, message, err)
+}
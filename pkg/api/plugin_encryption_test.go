@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pluginEncryptionURLs = []string{
+	"/api/plugins/test-app/encryption/encrypt",
+	"/api/plugins/test-app/encryption/decrypt",
+	"/api/plugins/test-app/encryption/sign",
+	"/api/plugins/test-app/encryption/verify",
+}
+
+func TestAPI_PluginEncryption_LegacyAccessControl(t *testing.T) {
+	for _, role := range []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR} {
+		for _, url := range pluginEncryptionURLs {
+			t.Run(string(role)+" should not be able to call "+url, func(t *testing.T) {
+				cfg := setting.NewCfg()
+				hs := &HTTPServer{
+					Cfg:           cfg,
+					RouteRegister: routing.NewRouteRegister(),
+					AccessControl: accesscontrolmock.New().WithDisabled(),
+				}
+
+				sc := setupScenarioContext(t, url)
+				sc.m.Use(func(c *models.ReqContext) {
+					c.UserId = testUserID
+					c.OrgId = testOrgID
+					c.OrgRole = role
+					c.IsSignedIn = true
+				})
+				hs.registerRoutes()
+				hs.RouteRegister.Register(sc.m.Router)
+
+				sc.resp = httptest.NewRecorder()
+				var err error
+				sc.req, err = http.NewRequest(http.MethodPost, url, strings.NewReader("{}"))
+				require.NoError(t, err)
+				sc.req.Header.Set("Content-Type", "application/json")
+
+				sc.exec()
+				assert.Equal(t, http.StatusForbidden, sc.resp.Code)
+			})
+		}
+	}
+}
+
+func TestAPI_PluginEncryption_AccessControl(t *testing.T) {
+	var tests []accessControlTestCase
+	for _, url := range pluginEncryptionURLs {
+		tests = append(tests,
+			accessControlTestCase{
+				desc:         "should not be able to call " + url + " without encrypt permission",
+				expectedCode: http.StatusForbidden,
+				url:          url,
+				method:       http.MethodPost,
+				permissions:  []*accesscontrol.Permission{{Action: ActionPluginsRead, Scope: ScopePluginsAll}},
+			},
+			accessControlTestCase{
+				desc:         "should not be able to call " + url + " with encrypt permission on another plugin",
+				expectedCode: http.StatusForbidden,
+				url:          url,
+				method:       http.MethodPost,
+				permissions:  []*accesscontrol.Permission{{Action: ActionPluginsEncrypt, Scope: "plugins:id:other-app"}},
+			},
+		)
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sc, _ := setupAccessControlScenarioContext(t, setting.NewCfg(), test.url, test.permissions)
+
+			sc.resp = httptest.NewRecorder()
+			var err error
+			sc.req, err = http.NewRequest(test.method, test.url, strings.NewReader("{}"))
+			require.NoError(t, err)
+			sc.req.Header.Set("Content-Type", "application/json")
+
+			sc.exec()
+			assert.Equal(t, test.expectedCode, sc.resp.Code)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/plugins/:pluginId/settings/versions
+func (hs *HTTPServer) GetPluginSettingVersions(c *models.ReqContext) response.Response {
+	query := models.GetPluginSettingVersionsQuery{
+		PluginId: web.Params(c.Req)[":pluginId"],
+		OrgId:    c.OrgId,
+		Limit:    c.QueryInt("limit"),
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get plugin settings versions", err)
+	}
+
+	logins := map[int64]string{}
+	result := make([]*models.PluginSettingVersionDTO, 0, len(query.Result))
+	for _, version := range query.Result {
+		result = append(result, pluginSettingVersionDTO(c.Req.Context(), version, logins))
+	}
+	return response.JSON(200, result)
+}
+
+// GET /api/plugins/:pluginId/settings/versions/:version
+func (hs *HTTPServer) GetPluginSettingVersion(c *models.ReqContext) response.Response {
+	query := models.GetPluginSettingVersionQuery{
+		PluginId: web.Params(c.Req)[":pluginId"],
+		OrgId:    c.OrgId,
+		Version:  int(c.ParamsInt64(":version")),
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrPluginSettingVersionNotFound) {
+			return response.Error(404, "Plugin settings version not found", nil)
+		}
+		return response.Error(500, "Failed to get plugin settings version", err)
+	}
+	return response.JSON(200, pluginSettingVersionDTO(c.Req.Context(), query.Result, map[int64]string{}))
+}
+
+// POST /api/plugins/:pluginId/settings/restore
+func (hs *HTTPServer) RestorePluginSettingVersion(c *models.ReqContext, cmd models.RestorePluginSettingVersionCmd) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	if app := hs.PluginManager.GetApp(pluginID); app == nil {
+		return response.Error(404, "Plugin not installed", nil)
+	}
+
+	cmd.PluginId = pluginID
+	cmd.OrgId = c.OrgId
+	cmd.UserId = c.UserId
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, models.ErrPluginSettingVersionNotFound) {
+			return response.Error(404, "Plugin settings version not found", nil)
+		}
+		return response.Error(500, "Failed to restore plugin settings", err)
+	}
+	// restoring the enabled state of an app changes the panels available to the organization
+	hs.CacheService.Delete(bootDataPanelsCacheKey(c.OrgId))
+
+	result := util.DynMap{"message": "Plugin settings restored"}
+	if cmd.Result != nil {
+		result["version"] = cmd.Result.Version
+	}
+	return response.JSON(200, result)
+}
+
+// pluginSettingVersionDTO returns the version without its secure JSON data, with the login of its creator, looked up
+// once per user.
+func pluginSettingVersionDTO(ctx context.Context, version *models.PluginSettingVersion, logins map[int64]string) *models.PluginSettingVersionDTO {
+	creator := anonString
+	if version.CreatedBy > 0 {
+		if login, ok := logins[version.CreatedBy]; ok {
+			creator = login
+		} else {
+			creator = getUserLogin(ctx, version.CreatedBy)
+			logins[version.CreatedBy] = creator
+		}
+	}
+
+	secureJsonFields := map[string]bool{}
+	for key := range version.SecureJsonData {
+		secureJsonFields[key] = true
+	}
+
+	return &models.PluginSettingVersionDTO{
+		Version:          version.Version,
+		RestoredFrom:     version.RestoredFrom,
+		Enabled:          version.Enabled,
+		Pinned:           version.Pinned,
+		JsonData:         version.JsonData,
+		SecureJsonFields: secureJsonFields,
+		PluginVersion:    version.PluginVersion,
+		Created:          version.Created,
+		CreatedBy:        creator,
+	}
+}
@@ -61,7 +61,18 @@ func ApplyRoute(ctx context.Context, req *http.Request, proxyPath string, route
 		logger.Error("Failed to set plugin route body content", "error", err)
 	}
 
-	if tokenProvider, err := getTokenProvider(ctx, cfg, ds, route, data); err != nil {
+	applyRouteToken(ctx, req, cfg, dataSourceTokenOwner(ds), route, data)
+
+	if cfg.DataProxyLogging {
+		logger.Debug("Requesting", "url", req.URL.String())
+	}
+}
+
+// applyRouteToken sets the authorization header of the request to the access token of the route, if it has token
+// authentication. The tokens are cached for the owner of the route.
+func applyRouteToken(ctx context.Context, req *http.Request, cfg *setting.Cfg, owner tokenOwner,
+	route *plugins.AppPluginRoute, data templateData) {
+	if tokenProvider, err := getTokenProvider(ctx, cfg, owner, route, data); err != nil {
 		logger.Error("Failed to resolve auth token provider", "error", err)
 	} else if tokenProvider != nil {
 		if token, err := tokenProvider.GetAccessToken(); err != nil {
@@ -70,18 +81,14 @@ func ApplyRoute(ctx context.Context, req *http.Request, proxyPath string, route
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 		}
 	}
-
-	if cfg.DataProxyLogging {
-		logger.Debug("Requesting", "url", req.URL.String())
-	}
 }
 
-func getTokenProvider(ctx context.Context, cfg *setting.Cfg, ds DSInfo, pluginRoute *plugins.AppPluginRoute,
+func getTokenProvider(ctx context.Context, cfg *setting.Cfg, owner tokenOwner, pluginRoute *plugins.AppPluginRoute,
 	data templateData) (accessTokenProvider, error) {
 	authType := pluginRoute.AuthType
 
 	// Plugin can override authentication type specified in route configuration
-	if authTypeOverride, ok := ds.JSONData["authenticationType"].(string); ok && authTypeOverride != "" {
+	if authTypeOverride, ok := data.JsonData["authenticationType"].(string); ok && authTypeOverride != "" {
 		authType = authTypeOverride
 	}
 
@@ -105,24 +112,24 @@ func getTokenProvider(ctx context.Context, cfg *setting.Cfg, ds DSInfo, pluginRo
 		if jwtTokenAuth == nil {
 			return nil, fmt.Errorf("'jwtTokenAuth' not configured for authentication type '%s'", authType)
 		}
-		provider := newGceAccessTokenProvider(ctx, ds, pluginRoute, jwtTokenAuth)
+		provider := newGceAccessTokenProvider(ctx, owner, pluginRoute, jwtTokenAuth)
 		return provider, nil
 
 	case "jwt":
 		if jwtTokenAuth == nil {
 			return nil, fmt.Errorf("'jwtTokenAuth' not configured for authentication type '%s'", authType)
 		}
-		provider := newJwtAccessTokenProvider(ctx, ds, pluginRoute, jwtTokenAuth)
+		provider := newJwtAccessTokenProvider(ctx, owner, pluginRoute, jwtTokenAuth)
 		return provider, nil
 
 	case "":
 		// Fallback to authentication methods when authentication type isn't explicitly configured
 		if tokenAuth != nil {
-			provider := newGenericAccessTokenProvider(ds, pluginRoute, tokenAuth)
+			provider := newGenericAccessTokenProvider(owner, pluginRoute, tokenAuth)
 			return provider, nil
 		}
 		if jwtTokenAuth != nil {
-			provider := newJwtAccessTokenProvider(ctx, ds, pluginRoute, jwtTokenAuth)
+			provider := newJwtAccessTokenProvider(ctx, owner, pluginRoute, jwtTokenAuth)
 			return provider, nil
 		}
 
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -251,7 +252,19 @@ func (proxy *DataSourceProxy) director(req *http.Request) {
 		}
 	}
 
-	secureJsonData, err := proxy.dataSourcesService.EncryptionService.DecryptJsonData(req.Context(), proxy.ds.SecureJsonData, setting.SecretKey)
+	ctx := secrets.WithAuditSecret(req.Context(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: proxy.ds.OrgId,
+		UID:   proxy.ds.Uid,
+		Name:  proxy.ds.Name,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{
+		Service:  "dataproxy",
+		UserID:   proxy.ctx.UserId,
+		Login:    proxy.ctx.Login,
+		PluginID: proxy.ds.Type,
+	})
+	secureJsonData, err := proxy.dataSourcesService.SecretsService.DecryptJsonData(ctx, proxy.ds.SecureJsonData)
 	if err != nil {
 		logger.Error("Error interpolating proxy url", "error", err)
 		return
@@ -9,7 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
-	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
@@ -22,7 +22,7 @@ type templateData struct {
 
 // NewApiPluginProxy create a plugin proxy
 func NewApiPluginProxy(ctx *models.ReqContext, proxyPath string, route *plugins.AppPluginRoute,
-	appID string, cfg *setting.Cfg, encryptionService encryption.Service) *httputil.ReverseProxy {
+	appID string, cfg *setting.Cfg, secretsService secrets.Service) *httputil.ReverseProxy {
 	director := func(req *http.Request) {
 		query := models.GetPluginSettingByIdQuery{OrgId: ctx.OrgId, PluginId: appID}
 		if err := bus.Dispatch(&query); err != nil {
@@ -30,7 +30,18 @@ func NewApiPluginProxy(ctx *models.ReqContext, proxyPath string, route *plugins.
 			return
 		}
 
-		secureJsonData, err := encryptionService.DecryptJsonData(ctx.Req.Context(), query.Result.SecureJsonData, setting.SecretKey)
+		decryptCtx := secrets.WithAuditSecret(ctx.Req.Context(), secrets.AuditSecret{
+			Kind:  secrets.SecretKindPluginSetting,
+			OrgID: ctx.OrgId,
+			Name:  appID,
+		})
+		decryptCtx = secrets.WithAuditRequester(decryptCtx, secrets.AuditRequester{
+			Service:  "pluginproxy",
+			UserID:   ctx.UserId,
+			Login:    ctx.Login,
+			PluginID: appID,
+		})
+		secureJsonData, err := secretsService.DecryptJsonData(decryptCtx, query.Result.SecureJsonData)
 		if err != nil {
 			ctx.JsonApiErr(500, "Failed to decrypt plugin settings", err)
 			return
@@ -81,6 +92,8 @@ func NewApiPluginProxy(ctx *models.ReqContext, proxyPath string, route *plugins.
 		if err := setBodyContent(req, route, data); err != nil {
 			logger.Error("Failed to set plugin route body content", "error", err)
 		}
+
+		applyRouteToken(ctx.Req.Context(), req, cfg, appTokenOwner(ctx.OrgId, appID, query.Result.Updated), route, data)
 	}
 
 	return &httputil.ReverseProxy{Director: director}
@@ -0,0 +1,102 @@
+package pluginproxy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+const (
+	// tokenExpiryMargin is how long before they expire the cached tokens are fetched again.
+	tokenExpiryMargin = 10 * time.Second
+	// defaultTokenTTL is how long the tokens without an expiry are cached.
+	defaultTokenTTL = 5 * time.Minute
+)
+
+// routeTokens is the cache of the access tokens of the plugin routes with token authentication, shared by the
+// data source proxy, the app plugin proxy and the plugins applying the routes to their resource calls.
+var routeTokens = newTokenCache()
+
+// tokenOwner is the data source, or the app plugin of an organization, the access tokens of its routes are cached
+// for. The tokens are fetched again when the owner is updated.
+type tokenOwner struct {
+	key     string
+	updated time.Time
+}
+
+func dataSourceTokenOwner(ds DSInfo) tokenOwner {
+	return tokenOwner{key: fmt.Sprintf("datasource/%d", ds.ID), updated: ds.Updated}
+}
+
+func appTokenOwner(orgID int64, appID string, updated time.Time) tokenOwner {
+	return tokenOwner{key: fmt.Sprintf("app/%d/%s", orgID, appID), updated: updated}
+}
+
+type tokenCacheKey struct {
+	owner  string
+	path   string
+	method string
+}
+
+func newTokenCacheKey(owner tokenOwner, route *plugins.AppPluginRoute) tokenCacheKey {
+	return tokenCacheKey{owner: owner.key, path: route.Path, method: route.Method}
+}
+
+type tokenCacheEntry struct {
+	// the mutex of the entry is held while the token is fetched, so that concurrent requests of a route fetch a
+	// single token without blocking the requests of the other routes.
+	sync.Mutex
+	updated     time.Time
+	accessToken string
+	expiresOn   time.Time
+}
+
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[tokenCacheKey]*tokenCacheEntry
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{entries: map[tokenCacheKey]*tokenCacheEntry{}}
+}
+
+// getAccessToken returns the cached access token of the route of the owner, or fetches a new one if there's none,
+// if it expires soon, or if the owner has been updated since it was fetched. fetch returns the token and its
+// expiry, which is zero if unknown.
+func (c *tokenCache) getAccessToken(owner tokenOwner, route *plugins.AppPluginRoute,
+	fetch func() (string, time.Time, error)) (string, error) {
+	key := newTokenCacheKey(owner, route)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &tokenCacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.Lock()
+	defer entry.Unlock()
+
+	if entry.accessToken != "" && entry.updated.Equal(owner.updated) &&
+		entry.expiresOn.After(timeNow().Add(tokenExpiryMargin)) {
+		logger.Debug("Using token from cache", "owner", key.owner, "path", key.path)
+		return entry.accessToken, nil
+	}
+
+	accessToken, expiresOn, err := fetch()
+	if err != nil {
+		return "", err
+	}
+	if expiresOn.IsZero() {
+		expiresOn = timeNow().Add(defaultTokenTTL)
+	}
+
+	entry.updated = owner.updated
+	entry.accessToken = accessToken
+	entry.expiresOn = expiresOn
+	logger.Info("Got new access token", "owner", key.owner, "path", key.path, "expiresOn", expiresOn)
+	return accessToken, nil
+}
@@ -9,36 +9,37 @@ import (
 )
 
 type gceAccessTokenProvider struct {
-	datasourceId      int64
-	datasourceUpdated time.Time
-	ctx               context.Context
-	route             *plugins.AppPluginRoute
-	authParams        *plugins.JwtTokenAuth
+	owner      tokenOwner
+	ctx        context.Context
+	route      *plugins.AppPluginRoute
+	authParams *plugins.JwtTokenAuth
 }
 
-func newGceAccessTokenProvider(ctx context.Context, ds DSInfo, pluginRoute *plugins.AppPluginRoute,
+func newGceAccessTokenProvider(ctx context.Context, owner tokenOwner, pluginRoute *plugins.AppPluginRoute,
 	authParams *plugins.JwtTokenAuth) *gceAccessTokenProvider {
 	return &gceAccessTokenProvider{
-		datasourceId:      ds.ID,
-		datasourceUpdated: ds.Updated,
-		ctx:               ctx,
-		route:             pluginRoute,
-		authParams:        authParams,
+		owner:      owner,
+		ctx:        ctx,
+		route:      pluginRoute,
+		authParams: authParams,
 	}
 }
 
 func (provider *gceAccessTokenProvider) GetAccessToken() (string, error) {
+	return routeTokens.getAccessToken(provider.owner, provider.route, provider.fetchAccessToken)
+}
+
+func (provider *gceAccessTokenProvider) fetchAccessToken() (string, time.Time, error) {
 	tokenSrc, err := google.DefaultTokenSource(provider.ctx, provider.authParams.Scopes...)
 	if err != nil {
 		logger.Error("Failed to get default token from meta data server", "error", err)
-		return "", err
-	} else {
-		token, err := tokenSrc.Token()
-		if err != nil {
-			logger.Error("Failed to get default access token from meta data server", "error", err)
-			return "", err
-		} else {
-			return token.AccessToken, nil
-		}
+		return "", time.Time{}, err
+	}
+
+	token, err := tokenSrc.Token()
+	if err != nil {
+		logger.Error("Failed to get default access token from meta data server", "error", err)
+		return "", time.Time{}, err
 	}
+	return token.AccessToken, token.Expiry, nil
 }
@@ -3,32 +3,18 @@ package pluginproxy
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/plugins"
 )
 
-var (
-	tokenCache = tokenCacheType{
-		cache: map[string]*jwtToken{},
-	}
-)
-
-type tokenCacheType struct {
-	cache map[string]*jwtToken
-	sync.Mutex
-}
-
 type genericAccessTokenProvider struct {
-	datasourceId      int64
-	datasourceUpdated time.Time
-	route             *plugins.AppPluginRoute
-	authParams        *plugins.JwtTokenAuth
+	owner      tokenOwner
+	route      *plugins.AppPluginRoute
+	authParams *plugins.JwtTokenAuth
 }
 
 type jwtToken struct {
@@ -48,7 +34,6 @@ func (token *jwtToken) UnmarshalJSON(b []byte) error {
 	}
 
 	token.AccessToken = t.AccessToken
-	token.ExpiresOn = timeNow()
 
 	if t.ExpiresOn != nil {
 		expiresOn, err := t.ExpiresOn.Int64()
@@ -67,26 +52,20 @@ func (token *jwtToken) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-func newGenericAccessTokenProvider(ds DSInfo, pluginRoute *plugins.AppPluginRoute,
+func newGenericAccessTokenProvider(owner tokenOwner, pluginRoute *plugins.AppPluginRoute,
 	authParams *plugins.JwtTokenAuth) *genericAccessTokenProvider {
 	return &genericAccessTokenProvider{
-		datasourceId:      ds.ID,
-		datasourceUpdated: ds.Updated,
-		route:             pluginRoute,
-		authParams:        authParams,
+		owner:      owner,
+		route:      pluginRoute,
+		authParams: authParams,
 	}
 }
 
 func (provider *genericAccessTokenProvider) GetAccessToken() (string, error) {
-	tokenCache.Lock()
-	defer tokenCache.Unlock()
-	if cachedToken, found := tokenCache.cache[provider.getAccessTokenCacheKey()]; found {
-		if cachedToken.ExpiresOn.After(timeNow().Add(time.Second * 10)) {
-			logger.Info("Using token from cache")
-			return cachedToken.AccessToken, nil
-		}
-	}
+	return routeTokens.getAccessToken(provider.owner, provider.route, provider.fetchAccessToken)
+}
 
+func (provider *genericAccessTokenProvider) fetchAccessToken() (string, time.Time, error) {
 	tokenUrl := provider.authParams.Url
 
 	params := make(url.Values)
@@ -96,14 +75,14 @@ func (provider *genericAccessTokenProvider) GetAccessToken() (string, error) {
 
 	getTokenReq, err := http.NewRequest("POST", tokenUrl, bytes.NewBufferString(params.Encode()))
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 	getTokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	getTokenReq.Header.Set("Content-Length", strconv.Itoa(len(params.Encode())))
 
 	resp, err := client.Do(getTokenReq)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	defer func() {
@@ -114,14 +93,8 @@ func (provider *genericAccessTokenProvider) GetAccessToken() (string, error) {
 
 	var token jwtToken
 	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	tokenCache.cache[provider.getAccessTokenCacheKey()] = &token
-	logger.Info("Got new access token", "ExpiresOn", token.ExpiresOn)
-	return token.AccessToken, nil
-}
-
-func (provider *genericAccessTokenProvider) getAccessTokenCacheKey() string {
-	return fmt.Sprintf("%v_%v_%v_%v", provider.datasourceId, provider.datasourceUpdated.Unix(), provider.route.Path, provider.route.Method)
+	return token.AccessToken, token.ExpiresOn, nil
 }
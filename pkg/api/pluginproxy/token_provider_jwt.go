@@ -2,8 +2,6 @@ package pluginproxy
 
 import (
 	"context"
-	"fmt"
-	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/plugins"
@@ -11,46 +9,28 @@ import (
 	"golang.org/x/oauth2/jwt"
 )
 
-var (
-	oauthJwtTokenCache = oauthJwtTokenCacheType{
-		cache: map[string]*oauth2.Token{},
-	}
-)
-
-type oauthJwtTokenCacheType struct {
-	cache map[string]*oauth2.Token
-	sync.Mutex
-}
-
 type jwtAccessTokenProvider struct {
-	datasourceId      int64
-	datasourceUpdated time.Time
-	ctx               context.Context
-	route             *plugins.AppPluginRoute
-	authParams        *plugins.JwtTokenAuth
+	owner      tokenOwner
+	ctx        context.Context
+	route      *plugins.AppPluginRoute
+	authParams *plugins.JwtTokenAuth
 }
 
-func newJwtAccessTokenProvider(ctx context.Context, ds DSInfo, pluginRoute *plugins.AppPluginRoute,
+func newJwtAccessTokenProvider(ctx context.Context, owner tokenOwner, pluginRoute *plugins.AppPluginRoute,
 	authParams *plugins.JwtTokenAuth) *jwtAccessTokenProvider {
 	return &jwtAccessTokenProvider{
-		datasourceId:      ds.ID,
-		datasourceUpdated: ds.Updated,
-		ctx:               ctx,
-		route:             pluginRoute,
-		authParams:        authParams,
+		owner:      owner,
+		ctx:        ctx,
+		route:      pluginRoute,
+		authParams: authParams,
 	}
 }
 
 func (provider *jwtAccessTokenProvider) GetAccessToken() (string, error) {
-	oauthJwtTokenCache.Lock()
-	defer oauthJwtTokenCache.Unlock()
-	if cachedToken, found := oauthJwtTokenCache.cache[provider.getAccessTokenCacheKey()]; found {
-		if cachedToken.Expiry.After(timeNow().Add(time.Second * 10)) {
-			logger.Debug("Using token from cache")
-			return cachedToken.AccessToken, nil
-		}
-	}
+	return routeTokens.getAccessToken(provider.owner, provider.route, provider.fetchAccessToken)
+}
 
+func (provider *jwtAccessTokenProvider) fetchAccessToken() (string, time.Time, error) {
 	conf := &jwt.Config{}
 
 	if val, ok := provider.authParams.Params["client_email"]; ok {
@@ -69,14 +49,10 @@ func (provider *jwtAccessTokenProvider) GetAccessToken() (string, error) {
 
 	token, err := getTokenSource(conf, provider.ctx)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	oauthJwtTokenCache.cache[provider.getAccessTokenCacheKey()] = token
-
-	logger.Info("Got new access token", "ExpiresOn", token.Expiry)
-
-	return token.AccessToken, nil
+	return token.AccessToken, token.Expiry, nil
 }
 
 // getTokenSource gets a token source.
@@ -90,7 +66,3 @@ var getTokenSource = func(conf *jwt.Config, ctx context.Context) (*oauth2.Token,
 
 	return token, nil
 }
-
-func (provider *jwtAccessTokenProvider) getAccessTokenCacheKey() string {
-	return fmt.Sprintf("%v_%v_%v_%v", provider.datasourceId, provider.datasourceUpdated.Unix(), provider.route.Path, provider.route.Method)
-}
@@ -3,6 +3,7 @@ package pluginproxy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -60,6 +61,7 @@ func TestAccessToken_pluginWithJWTTokenAuthRoute(t *testing.T) {
 		})
 
 		getTokenSource = fn
+		clearTokenCache()
 	}
 
 	ds := DSInfo{ID: 1, Updated: time.Now()}
@@ -68,7 +70,7 @@ func TestAccessToken_pluginWithJWTTokenAuthRoute(t *testing.T) {
 		setUp(t, func(conf *jwt.Config, ctx context.Context) (*oauth2.Token, error) {
 			return &oauth2.Token{AccessToken: "abc"}, nil
 		})
-		provider := newJwtAccessTokenProvider(context.Background(), ds, pluginRoute, authParams)
+		provider := newJwtAccessTokenProvider(context.Background(), dataSourceTokenOwner(ds), pluginRoute, authParams)
 		token, err := provider.GetAccessToken()
 		require.NoError(t, err)
 
@@ -87,7 +89,7 @@ func TestAccessToken_pluginWithJWTTokenAuthRoute(t *testing.T) {
 			return &oauth2.Token{AccessToken: "abc"}, nil
 		})
 
-		provider := newJwtAccessTokenProvider(context.Background(), ds, pluginRoute, authParams)
+		provider := newJwtAccessTokenProvider(context.Background(), dataSourceTokenOwner(ds), pluginRoute, authParams)
 		_, err := provider.GetAccessToken()
 		require.NoError(t, err)
 	})
@@ -98,7 +100,7 @@ func TestAccessToken_pluginWithJWTTokenAuthRoute(t *testing.T) {
 				AccessToken: "abc",
 				Expiry:      time.Now().Add(1 * time.Minute)}, nil
 		})
-		provider := newJwtAccessTokenProvider(context.Background(), ds, pluginRoute, authParams)
+		provider := newJwtAccessTokenProvider(context.Background(), dataSourceTokenOwner(ds), pluginRoute, authParams)
 		token1, err := provider.GetAccessToken()
 		require.NoError(t, err)
 		assert.Equal(t, "abc", token1)
@@ -169,7 +171,7 @@ func TestAccessToken_pluginWithTokenAuthRoute(t *testing.T) {
 
 		mockTimeNow(time.Now())
 		defer resetTimeNow()
-		provider := newGenericAccessTokenProvider(DSInfo{}, pluginRoute, authParams)
+		provider := newGenericAccessTokenProvider(dataSourceTokenOwner(DSInfo{}), pluginRoute, authParams)
 
 		testCases := []tokenTestDescription{
 			{
@@ -233,13 +235,13 @@ func TestAccessToken_pluginWithTokenAuthRoute(t *testing.T) {
 				assert.Equal(t, token["access_token"], accessToken)
 				assert.Equal(t, 1, authCalls)
 
-				tokenCache.Lock()
-				v, ok := tokenCache.cache[provider.getAccessTokenCacheKey()]
-				tokenCache.Unlock()
+				routeTokens.mu.Lock()
+				v, ok := routeTokens.entries[newTokenCacheKey(provider.owner, provider.route)]
+				routeTokens.mu.Unlock()
 
 				assert.True(t, ok)
-				assert.Equal(t, testCase.expectedExpiresOn, v.ExpiresOn.Unix())
-				assert.Equal(t, token["access_token"], v.AccessToken)
+				assert.Equal(t, testCase.expectedExpiresOn, v.expiresOn.Unix())
+				assert.Equal(t, token["access_token"], v.accessToken)
 			})
 		}
 	})
@@ -251,7 +253,7 @@ func TestAccessToken_pluginWithTokenAuthRoute(t *testing.T) {
 
 		mockTimeNow(time.Now())
 		defer resetTimeNow()
-		provider := newGenericAccessTokenProvider(DSInfo{}, pluginRoute, authParams)
+		provider := newGenericAccessTokenProvider(dataSourceTokenOwner(DSInfo{}), pluginRoute, authParams)
 
 		token = map[string]interface{}{
 			"access_token":  "2YotnFZFEjr1zCsicMWpAA",
@@ -271,10 +273,94 @@ func TestAccessToken_pluginWithTokenAuthRoute(t *testing.T) {
 	})
 }
 
+func TestTokenCache(t *testing.T) {
+	route := &plugins.AppPluginRoute{Path: "api", Method: "GET"}
+	updated := time.Now()
+	owner := dataSourceTokenOwner(DSInfo{ID: 1, Updated: updated})
+
+	var fetches int
+	fetch := func(accessToken string, expiresOn time.Time) func() (string, time.Time, error) {
+		return func() (string, time.Time, error) {
+			fetches++
+			return accessToken, expiresOn, nil
+		}
+	}
+
+	t.Run("should cache tokens per owner and route", func(t *testing.T) {
+		clearTokenCache()
+		fetches = 0
+
+		token, err := routeTokens.getAccessToken(owner, route, fetch("abc", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", token)
+
+		token, err = routeTokens.getAccessToken(owner, route, fetch("def", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", token)
+		assert.Equal(t, 1, fetches)
+
+		otherRoute := &plugins.AppPluginRoute{Path: "api", Method: "POST"}
+		token, err = routeTokens.getAccessToken(owner, otherRoute, fetch("ghi", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "ghi", token)
+
+		app := appTokenOwner(1, "my-app", updated)
+		token, err = routeTokens.getAccessToken(app, route, fetch("jkl", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "jkl", token)
+		assert.Equal(t, 3, fetches)
+	})
+
+	t.Run("should fetch a new token when the owner is updated", func(t *testing.T) {
+		clearTokenCache()
+		fetches = 0
+
+		_, err := routeTokens.getAccessToken(owner, route, fetch("abc", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+
+		updatedOwner := dataSourceTokenOwner(DSInfo{ID: 1, Updated: updated.Add(time.Minute)})
+		token, err := routeTokens.getAccessToken(updatedOwner, route, fetch("def", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "def", token)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("should cache tokens without expiry for the default duration", func(t *testing.T) {
+		clearTokenCache()
+		fetches = 0
+		mockTimeNow(time.Now())
+		defer resetTimeNow()
+
+		_, err := routeTokens.getAccessToken(owner, route, fetch("abc", time.Time{}))
+		require.NoError(t, err)
+		_, err = routeTokens.getAccessToken(owner, route, fetch("abc", time.Time{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, fetches)
+
+		mockTimeNow(timeNow().Add(defaultTokenTTL))
+		_, err = routeTokens.getAccessToken(owner, route, fetch("abc", time.Time{}))
+		require.NoError(t, err)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("should not cache tokens that failed to be fetched", func(t *testing.T) {
+		clearTokenCache()
+
+		_, err := routeTokens.getAccessToken(owner, route, func() (string, time.Time, error) {
+			return "", time.Time{}, errors.New("unauthorized")
+		})
+		require.Error(t, err)
+
+		token, err := routeTokens.getAccessToken(owner, route, fetch("abc", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", token)
+	})
+}
+
 func clearTokenCache() {
-	tokenCache.Lock()
-	defer tokenCache.Unlock()
-	tokenCache.cache = map[string]*jwtToken{}
+	routeTokens.mu.Lock()
+	defer routeTokens.mu.Unlock()
+	routeTokens.entries = map[tokenCacheKey]*tokenCacheEntry{}
 	token = map[string]interface{}{}
 }
 
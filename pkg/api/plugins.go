@@ -1,22 +1,36 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/plugins/manager/installer"
+	"github.com/grafana/grafana/pkg/plugins/pluginassets"
+	"github.com/grafana/grafana/pkg/plugins/plugincatalog"
+	"github.com/grafana/grafana/pkg/plugins/plugindashboards"
+	"github.com/grafana/grafana/pkg/plugins/plugindocs"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -65,11 +79,16 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) response.Response {
 			Info:          &pluginDef.Info,
 			LatestVersion: pluginDef.GrafanaNetVersion,
 			HasUpdate:     pluginDef.GrafanaNetHasUpdate,
+			Advisories:    pluginAdvisories(pluginDef),
 			DefaultNavUrl: pluginDef.DefaultNavUrl,
 			State:         pluginDef.State,
 			Signature:     pluginDef.Signature,
 			SignatureType: pluginDef.SignatureType,
 			SignatureOrg:  pluginDef.SignatureOrg,
+			Dev:           pluginDef.IsDevPlugin,
+
+			Dependencies:     &pluginDef.Dependencies,
+			DependencyStatus: plugins.CheckDependencies(pluginDef, hs.Cfg.BuildVersion, hs.PluginManager.GetPlugin),
 		}
 
 		if pluginSetting, exists := pluginSettingsMap[pluginDef.Id]; exists {
@@ -100,6 +119,15 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) response.Response {
 	return response.JSON(200, result)
 }
 
+// pluginAdvisories returns the security advisories affecting the installed version of the plugin, never nil so that
+// they're serialized as an empty list.
+func pluginAdvisories(p *plugins.PluginBase) []plugins.PluginAdvisory {
+	if p.Advisories == nil {
+		return []plugins.PluginAdvisory{}
+	}
+	return p.Advisories
+}
+
 func (hs *HTTPServer) GetPluginSettingByID(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
@@ -120,10 +148,17 @@ func (hs *HTTPServer) GetPluginSettingByID(c *models.ReqContext) response.Respon
 		DefaultNavUrl: def.DefaultNavUrl,
 		LatestVersion: def.GrafanaNetVersion,
 		HasUpdate:     def.GrafanaNetHasUpdate,
+		Advisories:    pluginAdvisories(def),
 		State:         def.State,
 		Signature:     def.Signature,
 		SignatureType: def.SignatureType,
 		SignatureOrg:  def.SignatureOrg,
+		Dev:           def.IsDevPlugin,
+
+		FeatureToggles:   def.FeatureToggles,
+		Locales:          def.Locales,
+		ModuleIntegrity:  def.ModuleIntegrity,
+		DependencyStatus: plugins.CheckDependencies(def, hs.Cfg.BuildVersion, hs.PluginManager.GetPlugin),
 	}
 
 	if app := hs.PluginManager.GetApp(def.Id); app != nil {
@@ -148,19 +183,63 @@ func (hs *HTTPServer) GetPluginSettingByID(c *models.ReqContext) response.Respon
 func (hs *HTTPServer) UpdatePluginSetting(c *models.ReqContext, cmd models.UpdatePluginSettingCmd) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
-	if app := hs.PluginManager.GetApp(pluginID); app == nil {
+	app := hs.PluginManager.GetApp(pluginID)
+	if app == nil {
 		return response.Error(404, "Plugin not installed", nil)
 	}
 
 	cmd.OrgId = c.OrgId
 	cmd.PluginId = pluginID
+	cmd.UserId = c.UserId
+	if app.SettingsSchema != nil {
+		storedSecureKeys, err := storedPluginSettingSecureKeys(c.Req.Context(), c.OrgId, pluginID)
+		if err != nil {
+			return response.Error(500, "Failed to get plugin settings", err)
+		}
+		if err := app.SettingsSchema.Validate(cmd.JsonData, cmd.SecureJsonData, storedSecureKeys); err != nil {
+			return pluginSettingsValidationError(err)
+		}
+	}
+
 	if err := bus.Dispatch(&cmd); err != nil {
 		return response.Error(500, "Failed to update plugin setting", err)
 	}
+	// enabling or disabling an app changes the panels available to the organization
+	hs.CacheService.Delete(bootDataPanelsCacheKey(c.OrgId))
 
 	return response.Success("Plugin settings updated")
 }
 
+// storedPluginSettingSecureKeys returns the keys of the secure JSON data stored in the settings of the plugin.
+func storedPluginSettingSecureKeys(ctx context.Context, orgID int64, pluginID string) ([]string, error) {
+	query := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		if errors.Is(err, models.ErrPluginSettingNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(query.Result.SecureJsonData))
+	for key := range query.Result.SecureJsonData {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// pluginSettingsValidationError returns the field errors of settings not matching the settings schema of their
+// plugin.
+func pluginSettingsValidationError(err error) response.Response {
+	var validationErr plugins.SettingsValidationError
+	if !errors.As(err, &validationErr) {
+		return response.Error(500, "Failed to validate plugin settings", err)
+	}
+	return response.JSON(400, util.DynMap{
+		"message": "Invalid plugin settings",
+		"errors":  validationErr.Fields,
+	})
+}
+
 func (hs *HTTPServer) GetPluginDashboards(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
@@ -174,9 +253,48 @@ func (hs *HTTPServer) GetPluginDashboards(c *models.ReqContext) response.Respons
 		return response.Error(500, "Failed to get plugin dashboards", err)
 	}
 
+	pinned, err := hs.PluginDashboardService.PinnedDashboards(c.Req.Context(), c.OrgId, pluginID)
+	if err != nil {
+		return response.Error(500, "Failed to get pinned plugin dashboards", err)
+	}
+	for _, dashboard := range list {
+		dashboard.Pinned = dashboard.DashboardId != 0 && pinned[dashboard.DashboardId]
+	}
+
 	return response.JSON(200, list)
 }
 
+// POST /api/plugins/:pluginId/dashboards/:dashboardId/pin
+func (hs *HTTPServer) PinPluginDashboard(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	dashboardID := c.ParamsInt64(":dashboardId")
+
+	err := hs.PluginDashboardService.PinDashboard(c.Req.Context(), c.OrgId, pluginID, dashboardID)
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return response.Error(404, "Dashboard not found", err)
+		}
+		if errors.Is(err, plugindashboards.ErrNotPluginDashboard) {
+			return response.Error(400, err.Error(), err)
+		}
+		return response.Error(500, "Failed to pin plugin dashboard", err)
+	}
+
+	return response.Success("Plugin dashboard pinned")
+}
+
+// DELETE /api/plugins/:pluginId/dashboards/:dashboardId/pin
+func (hs *HTTPServer) UnpinPluginDashboard(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	dashboardID := c.ParamsInt64(":dashboardId")
+
+	if err := hs.PluginDashboardService.UnpinDashboard(c.Req.Context(), c.OrgId, pluginID, dashboardID); err != nil {
+		return response.Error(500, "Failed to unpin plugin dashboard", err)
+	}
+
+	return response.Success("Plugin dashboard unpinned")
+}
+
 func (hs *HTTPServer) GetPluginMarkdown(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 	name := web.Params(c.Req)[":name"]
@@ -204,6 +322,39 @@ func (hs *HTTPServer) GetPluginMarkdown(c *models.ReqContext) response.Response
 	return resp
 }
 
+func (hs *HTTPServer) GetPluginDoc(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+	name := web.Params(c.Req)[":name"]
+
+	doc, err := hs.PluginDocsService.GetDoc(c.Req.Context(), pluginID, name)
+	if err != nil {
+		if errors.Is(err, plugindocs.ErrUnknownDoc) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		if errors.Is(err, plugindocs.ErrDocNotFound) {
+			return response.Error(http.StatusNotFound, "Plugin doc not found", nil)
+		}
+		return response.Error(http.StatusBadGateway, "Could not get plugin doc", err)
+	}
+
+	return response.JSON(http.StatusOK, doc)
+}
+
+func (hs *HTTPServer) GetPluginSignatureDetails(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	details, err := hs.PluginManager.SignatureDetails(pluginID)
+	if err != nil {
+		var notFound plugins.PluginNotFoundError
+		if errors.As(err, &notFound) {
+			return response.Error(http.StatusNotFound, notFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to verify plugin signature", err)
+	}
+
+	return response.JSON(http.StatusOK, details)
+}
+
 func (hs *HTTPServer) ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDashboardCommand) response.Response {
 	var err error
 	if apiCmd.PluginId == "" && apiCmd.Dashboard == nil {
@@ -218,13 +369,18 @@ func (hs *HTTPServer) ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDa
 		}
 	}
 
-	dashInfo, dash, err := hs.PluginManager.ImportDashboard(apiCmd.PluginId, apiCmd.Path, c.OrgId, apiCmd.FolderId,
+	folderID, errResp := hs.importDashboardFolder(c, apiCmd, true)
+	if errResp != nil {
+		return errResp
+	}
+
+	dashInfo, dash, err := hs.PluginManager.ImportDashboard(apiCmd.PluginId, apiCmd.Path, c.OrgId, folderID,
 		apiCmd.Dashboard, apiCmd.Overwrite, apiCmd.Inputs, c.SignedInUser, hs.DataService)
 	if err != nil {
 		return hs.dashboardSaveErrorToApiResponse(err)
 	}
 
-	err = hs.LibraryPanelService.ImportLibraryPanelsForDashboard(c.Req.Context(), c.SignedInUser, dash, apiCmd.FolderId)
+	err = hs.LibraryPanelService.ImportLibraryPanelsForDashboard(c.Req.Context(), c.SignedInUser, dash, folderID)
 	if err != nil {
 		return response.Error(500, "Error while importing library panels", err)
 	}
@@ -237,6 +393,77 @@ func (hs *HTTPServer) ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDa
 	return response.JSON(200, dashInfo)
 }
 
+// importDashboardFolder returns the ID of the folder to import the dashboard in, i.e. the folder with the UID of
+// the command if set, its folder ID otherwise, or the default folder of the plugin dashboards if neither is set.
+func (hs *HTTPServer) importDashboardFolder(c *models.ReqContext, apiCmd dtos.ImportDashboardCommand,
+	createDefault bool) (int64, response.Response) {
+	if apiCmd.FolderUid != "" {
+		s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+		folder, err := s.GetFolderByUID(c.Req.Context(), apiCmd.FolderUid)
+		if err != nil {
+			return 0, apierrors.ToFolderErrorResponse(err)
+		}
+		return folder.Id, nil
+	}
+
+	if apiCmd.FolderId != 0 || apiCmd.PluginId == "" {
+		return apiCmd.FolderId, nil
+	}
+
+	folderID, err := hs.PluginDashboardService.DefaultDashboardFolder(c.Req.Context(), c.SignedInUser, apiCmd.PluginId,
+		createDefault)
+	if err != nil {
+		if errors.Is(err, models.ErrFolderAccessDenied) {
+			return 0, apierrors.ToFolderErrorResponse(err)
+		}
+		return 0, response.Error(500, "Failed to get the folder of the plugin dashboards", err)
+	}
+	return folderID, nil
+}
+
+// PreviewImportDashboard returns what importing the dashboard would create or overwrite, e.g. the conflicts
+// with existing dashboards and the data sources bound to its inputs, without importing it.
+//
+// POST /api/dashboards/import/preview
+func (hs *HTTPServer) PreviewImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDashboardCommand) response.Response {
+	if apiCmd.PluginId == "" && apiCmd.Dashboard == nil {
+		return response.Error(422, "Dashboard must be set", nil)
+	}
+
+	folderID, errResp := hs.importDashboardFolder(c, apiCmd, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	preview, err := hs.PluginManager.PreviewImportDashboard(c.Req.Context(), apiCmd.PluginId, apiCmd.Path, c.OrgId,
+		folderID, apiCmd.Dashboard, apiCmd.Overwrite, apiCmd.Inputs)
+	if err != nil {
+		var inputErr *manager.DashboardInputMissingError
+		if errors.As(err, &inputErr) {
+			return response.Error(422, inputErr.Error(), nil)
+		}
+		var notFoundErr plugins.PluginNotFoundError
+		if errors.As(err, &notFoundErr) {
+			return response.Error(404, "Plugin not found", nil)
+		}
+		return response.Error(500, "Failed to preview dashboard import", err)
+	}
+
+	// the default folder of the plugin dashboards is only created by the import
+	if folderID == 0 && apiCmd.FolderId == 0 && apiCmd.FolderUid == "" && apiCmd.PluginId != "" {
+		title, err := hs.PluginDashboardService.DefaultDashboardFolderTitle(c.Req.Context(), c.OrgId, apiCmd.PluginId)
+		if err != nil {
+			return response.Error(500, "Failed to get the folder of the plugin dashboards", err)
+		}
+		if title != "" {
+			preview.FolderTitle = title
+			preview.CreateFolder = true
+		}
+	}
+
+	return response.JSON(200, preview)
+}
+
 // CollectPluginMetrics collect metrics from a plugin.
 //
 // /api/plugins/:pluginId/metrics
@@ -265,14 +492,25 @@ func (hs *HTTPServer) getPluginAssets(c *models.ReqContext) {
 	pluginID := web.Params(c.Req)[":pluginId"]
 	plugin := hs.PluginManager.GetPlugin(pluginID)
 	if plugin == nil {
+		// the plugin may have been installed by another instance, which synced its assets to the object storage
+		if hs.PluginAssets.Enabled() {
+			hs.serveSyncedPluginAsset(c, pluginID, filepath.Clean(web.Params(c.Req)["*"]))
+			return
+		}
 		c.JsonApiErr(404, "Plugin not found", nil)
 		return
 	}
 
 	requestedFile := filepath.Clean(web.Params(c.Req)["*"])
+	// the namespaces of the translations, e.g. locales/panel.json, are served in the locale negotiated with the browser
+	locale := ""
+	if filepath.Dir(requestedFile) == plugins.LocalesDir && len(plugin.Locales) > 0 {
+		locale = plugins.NegotiateLocale(c.Req.Header.Get("Accept-Language"), plugin.Locales)
+		requestedFile = filepath.Join(plugins.LocalesDir, locale, filepath.Base(requestedFile))
+	}
 	pluginFilePath := filepath.Join(plugin.PluginDir, requestedFile)
 
-	if !plugin.IncludedInSignature(requestedFile) {
+	if !plugin.IsDevPlugin && !plugin.IncludedInSignature(requestedFile) {
 		hs.log.Warn("Access to requested plugin file will be forbidden in upcoming Grafana versions as the file "+
 			"is not included in the plugin signature", "file", requestedFile)
 	}
@@ -283,6 +521,11 @@ func (hs *HTTPServer) getPluginAssets(c *models.ReqContext) {
 	f, err := os.Open(pluginFilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			// the plugin may have been installed by another instance, which synced its assets to the object storage
+			if hs.PluginAssets.Enabled() {
+				hs.servePluginAssetFromStorage(c, plugin, requestedFile, locale)
+				return
+			}
 			c.JsonApiErr(404, "Plugin file not found", err)
 			return
 		}
@@ -301,13 +544,83 @@ func (hs *HTTPServer) getPluginAssets(c *models.ReqContext) {
 		return
 	}
 
-	if hs.Cfg.Env == setting.Dev {
+	hs.setPluginAssetHeaders(c, plugin, fi.ModTime(), fi.Size(), locale)
+	http.ServeContent(c.Resp, c.Req, pluginFilePath, fi.ModTime(), f)
+}
+
+// serveSyncedPluginAsset serves the file of a plugin that isn't installed on this instance from the object storage,
+// in the version synced last.
+func (hs *HTTPServer) serveSyncedPluginAsset(c *models.ReqContext, pluginID string, requestedFile string) {
+	plugin, err := hs.PluginAssets.SyncedPlugin(c.Req.Context(), pluginID)
+	if err != nil {
+		if errors.Is(err, pluginassets.ErrAssetNotFound) {
+			c.JsonApiErr(404, "Plugin not found", nil)
+			return
+		}
+		c.JsonApiErr(500, "Could not get plugin from object storage", err)
+		return
+	}
+
+	hs.servePluginAssetFromStorage(c, plugin, requestedFile, "")
+}
+
+// servePluginAssetFromStorage serves the plugin file from the object storage the plugin assets are synced to.
+func (hs *HTTPServer) servePluginAssetFromStorage(c *models.ReqContext, plugin *plugins.PluginBase, requestedFile string,
+	locale string) {
+	r, info, err := hs.PluginAssets.Open(c.Req.Context(), plugin, requestedFile)
+	if err != nil {
+		if errors.Is(err, pluginassets.ErrAssetNotFound) {
+			c.JsonApiErr(404, "Plugin file not found", err)
+			return
+		}
+		c.JsonApiErr(500, "Could not open plugin file from object storage", err)
+		return
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			hs.log.Error("Failed to close plugin file from object storage", "err", err)
+		}
+	}()
+
+	// the content is buffered as http.ServeContent seeks it to serve ranges
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		c.JsonApiErr(500, "Could not read plugin file from object storage", err)
+		return
+	}
+
+	if info.ContentType != "" {
+		c.Resp.Header().Set("Content-Type", info.ContentType)
+	}
+	hs.setPluginAssetHeaders(c, plugin, info.ModTime, int64(len(content)), locale)
+	http.ServeContent(c.Resp, c.Req, requestedFile, info.ModTime, bytes.NewReader(content))
+}
+
+// setPluginAssetHeaders sets the caching headers of the plugin file, and its language when it's a translation.
+func (hs *HTTPServer) setPluginAssetHeaders(c *models.ReqContext, plugin *plugins.PluginBase, modTime time.Time,
+	size int64, locale string) {
+	switch {
+	// the assets of the plugins in development are reloaded as soon as they are built
+	case hs.Cfg.Env == setting.Dev || plugin.IsDevPlugin:
 		c.Resp.Header().Set("Cache-Control", "max-age=0, must-revalidate, no-cache")
-	} else {
-		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
+		c.Resp.Header().Set("ETag", pluginAssetETag(modTime, size))
+	// the versioned URLs change whenever the plugin is updated
+	case plugin.AssetVersion != "" && c.Query("_v") == plugin.AssetVersion:
+		c.Resp.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	default:
+		c.Resp.Header().Set("Cache-Control", "no-cache")
+		c.Resp.Header().Set("ETag", pluginAssetETag(modTime, size))
+	}
+	if locale != "" {
+		c.Resp.Header().Set("Content-Language", locale)
+		c.Resp.Header().Add("Vary", "Accept-Language")
 	}
+}
 
-	http.ServeContent(c.Resp, c.Req, pluginFilePath, fi.ModTime(), f)
+// pluginAssetETag returns a weak ETag of the plugin file, from its size and modification time, which
+// http.ServeContent checks against the If-None-Match header of the request.
+func pluginAssetETag(modTime time.Time, size int64) string {
+	return fmt.Sprintf(`W/"%x-%x"`, modTime.UnixNano(), size)
 }
 
 // CheckHealth returns the health of a plugin.
@@ -357,6 +670,12 @@ func (hs *HTTPServer) CheckHealth(c *models.ReqContext) response.Response {
 func (hs *HTTPServer) CallResource(c *models.ReqContext) {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
+	// the plugin settings, with their secrets, are only decrypted for the calls forwarded to the plugin
+	resourcePath := web.Params(c.Req)["*"]
+	if !hs.checkResourceCall(c, hs.PluginManager.GetPlugin(pluginID), resourcePath) {
+		return
+	}
+
 	pCtx, found, err := hs.PluginContextProvider.Get(pluginID, "", c.SignedInUser, false)
 	if err != nil {
 		c.JsonApiErr(500, "Failed to get plugin settings", err)
@@ -366,13 +685,185 @@ func (hs *HTTPServer) CallResource(c *models.ReqContext) {
 		c.JsonApiErr(404, "Plugin not found", nil)
 		return
 	}
-	hs.BackendPluginManager.CallResource(pCtx, c, web.Params(c.Req)["*"])
+	hs.BackendPluginManager.CallResource(pCtx, c, resourcePath)
+}
+
+// checkResourceCall checks that a resource call can be forwarded to the plugin, see checkResourceCSRF,
+// checkResourceMethod and checkResourceRoute. Writes the error response and returns false otherwise.
+func (hs *HTTPServer) checkResourceCall(c *models.ReqContext, plugin *plugins.PluginBase, resourcePath string) bool {
+	if plugin == nil {
+		c.JsonApiErr(404, "Plugin not found", nil)
+		return false
+	}
+	return hs.checkResourceCSRF(c) && hs.checkResourceMethod(c, plugin.Id) &&
+		hs.checkResourceRoute(c, plugin, resourcePath)
+}
+
+// checkResourceRoute checks that a resource call matches one of the resource routes declared by the plugin, and that
+// the user has the role or the action required by the route. Plugins not declaring resource routes can be called on any path,
+// unless resource routes are required for external plugins. The paths which aren't clean are rejected, since the
+// plugin receives them as they are rather than as they were matched. Writes the error response and returns false
+// otherwise.
+func (hs *HTTPServer) checkResourceRoute(c *models.ReqContext, plugin *plugins.PluginBase, resourcePath string) bool {
+	if len(plugin.Resources) == 0 {
+		if hs.Cfg.PluginsRequireResourceRoutes && !plugin.IsCorePlugin {
+			c.JsonApiErr(404, "Plugin resource not found", nil)
+			return false
+		}
+		return true
+	}
+
+	if !plugins.IsCleanResourcePath(resourcePath) {
+		c.JsonApiErr(400, "Invalid plugin resource path", nil)
+		return false
+	}
+
+	route := plugins.MatchResourceRoute(plugin.Resources, c.Req.Method, resourcePath)
+	if route == nil {
+		c.JsonApiErr(404, "Plugin resource not found", nil)
+		return false
+	}
+	if !hs.hasPluginAccess(c, func(c *models.ReqContext) bool {
+		return !route.ReqRole.IsValid() || c.HasUserRole(route.ReqRole)
+	}, route.ReqAction) {
+		c.JsonApiErr(403, "Access denied to plugin resource", nil)
+		return false
+	}
+	return true
 }
 
 func (hs *HTTPServer) GetPluginErrorsList(_ *models.ReqContext) response.Response {
 	return response.JSON(200, hs.PluginManager.ScanningErrors())
 }
 
+// GetPluginDeprecationReport returns the installed plugins relying on deprecated frontend APIs, e.g. AngularJS, with
+// the timeline of their removal.
+func (hs *HTTPServer) GetPluginDeprecationReport(_ *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.PluginManager.DeprecationReport())
+}
+
+// maxPluginArchiveUploadSize bounds the size of the plugin archives uploaded to be validated.
+const maxPluginArchiveUploadSize = 256 << 20
+
+// GetPluginNav returns the navigation of the app plugin for the signed in user, built from the pages and dashboards
+// it includes which the user's role gives access to, so that the frontend doesn't rebuild it from the includes.
+//
+// GET /api/plugins/:pluginId/nav
+func (hs *HTTPServer) GetPluginNav(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	enabledPlugins, err := hs.PluginManager.GetEnabledPlugins(c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get enabled plugins", err)
+	}
+	var app *plugins.AppPlugin
+	for _, plugin := range enabledPlugins.Apps {
+		if plugin.Id == pluginID {
+			app = plugin
+			break
+		}
+	}
+	if app == nil {
+		return response.Error(http.StatusNotFound, "App plugin not found or not enabled", nil)
+	}
+
+	access, err := hs.getFrontendSettingsAccess(c)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get permissions", err)
+	}
+	if !access.canUsePlugin(pluginID) {
+		return response.Error(http.StatusForbidden, "Access denied to the app plugin", nil)
+	}
+
+	return response.JSON(http.StatusOK, hs.getAppNavLink(c, app))
+}
+
+// ValidatePluginArchive validates the plugin archive of the request body, e.g. a plugin build in CI, and returns the
+// lint results.
+//
+// POST /api/plugins/validate
+func (hs *HTTPServer) ValidatePluginArchive(c *models.ReqContext) response.Response {
+	archive, err := os.CreateTemp("", "plugin-archive-*.zip")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to store plugin archive", err)
+	}
+	defer func() {
+		if err := os.Remove(archive.Name()); err != nil {
+			hs.log.Warn("Failed to remove plugin archive", "path", archive.Name(), "error", err)
+		}
+	}()
+
+	n, err := io.Copy(archive, io.LimitReader(c.Req.Body, maxPluginArchiveUploadSize+1))
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to store plugin archive", err)
+	}
+	if n == 0 {
+		return response.Error(http.StatusBadRequest, "Plugin archive missing from the request body", nil)
+	}
+	if n > maxPluginArchiveUploadSize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Plugin archive too large", nil)
+	}
+
+	report, err := hs.PluginManager.ValidateArchive(c.Req.Context(), archive.Name())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to validate plugin archive", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// RunPluginConformanceSuite runs the conformance suite against the backend of the plugin, and returns the results
+// of its checks. The checks are run against the data source of the datasourceUid query parameter, if any.
+//
+// POST /api/plugins/:pluginId/conformance
+func (hs *HTTPServer) RunPluginConformanceSuite(c *models.ReqContext) response.Response {
+	pluginID := web.Params(c.Req)[":pluginId"]
+
+	pCtx, found, err := hs.PluginContextProvider.Get(pluginID, c.Query("datasourceUid"), c.SignedInUser, false)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get plugin settings", err)
+	}
+	if !found {
+		return response.Error(http.StatusNotFound, "Plugin not found", nil)
+	}
+
+	report, err := hs.PluginManager.RunConformanceSuite(c.Req.Context(), pCtx)
+	if err != nil {
+		var notFound plugins.PluginNotFoundError
+		switch {
+		case errors.As(err, &notFound):
+			return response.Error(http.StatusNotFound, "Plugin not found", err)
+		case errors.Is(err, backendplugin.ErrPluginNotRegistered):
+			return response.Error(http.StatusBadRequest, "Plugin has no backend", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to run plugin conformance suite", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// GetPluginStartupProfile returns the time each plugin took to start up, by phase, slowest first.
+func (hs *HTTPServer) GetPluginStartupProfile(_ *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.PluginManager.StartupProfile())
+}
+
+func (hs *HTTPServer) SearchPluginCatalog(c *models.ReqContext) response.Response {
+	result, err := hs.PluginCatalogService.Search(c.Req.Context(), plugincatalog.SearchQuery{
+		OrgID: c.OrgId,
+		Query: c.Query("query"),
+		Type:  c.Query("type"),
+	})
+	if err != nil {
+		return response.Error(http.StatusBadGateway, "Could not search the plugin catalog", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
 func (hs *HTTPServer) InstallPlugin(c *models.ReqContext, dto dtos.InstallPluginCommand) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
 
@@ -397,17 +888,51 @@ func (hs *HTTPServer) InstallPlugin(c *models.ReqContext, dto dtos.InstallPlugin
 		if errors.Is(err, plugins.ErrInstallCorePlugin) {
 			return response.Error(http.StatusForbidden, "Cannot install or change a Core plugin", err)
 		}
+		if errors.Is(err, plugins.ErrInstallNotAllowed) {
+			return response.Error(http.StatusForbidden, "Installing the plugin is not allowed", err)
+		}
+		var incompatibleErr plugins.IncompatibleGrafanaVersionError
+		if errors.As(err, &incompatibleErr) {
+			return response.Error(http.StatusConflict, "Plugin is not compatible with this Grafana version", err)
+		}
+		var verificationErr plugins.PluginVerificationError
+		if errors.As(err, &verificationErr) {
+			return response.Error(http.StatusUnprocessableEntity, "Plugin failed verification and was not installed", err)
+		}
+		var quotaErr plugins.PluginDiskQuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return response.Error(http.StatusUnprocessableEntity, "Plugin exceeds its disk quota and was not installed", err)
+		}
+		var archiveErr plugins.PluginArchiveSecurityError
+		if errors.As(err, &archiveErr) {
+			return response.JSON(http.StatusUnprocessableEntity, util.DynMap{
+				"message": "Plugin archive failed the security inspection and was not extracted",
+				"report":  archiveErr.Report,
+			})
+		}
+		var vulnerableErr plugins.VulnerablePluginVersionError
+		if errors.As(err, &vulnerableErr) {
+			return response.JSON(http.StatusForbidden, util.DynMap{
+				"message":    "Plugin version is affected by security advisories",
+				"advisories": vulnerableErr.Advisories,
+			})
+		}
 
 		return response.Error(http.StatusInternalServerError, "Failed to install plugin", err)
 	}
+	hs.deleteBootDataPanelsCache()
 
 	return response.JSON(http.StatusOK, []byte{})
 }
 
 func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 	pluginID := web.Params(c.Req)[":pluginId"]
+	dashboardsAction, err := plugindashboards.ParseUninstalledDashboardsAction(c.Query("dashboards"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), nil)
+	}
 
-	err := hs.PluginManager.Uninstall(c.Req.Context(), pluginID)
+	err = hs.PluginManager.Uninstall(c.Req.Context(), pluginID)
 	if err != nil {
 		if errors.Is(err, plugins.ErrPluginNotInstalled) {
 			return response.Error(http.StatusNotFound, "Plugin not installed", err)
@@ -421,6 +946,12 @@ func (hs *HTTPServer) UninstallPlugin(c *models.ReqContext) response.Response {
 
 		return response.Error(http.StatusInternalServerError, "Failed to uninstall plugin", err)
 	}
+	hs.deleteBootDataPanelsCache()
+
+	err = hs.PluginDashboardService.RemoveUninstalledPluginDashboards(c.Req.Context(), pluginID, dashboardsAction)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Plugin uninstalled but failed to remove its dashboards", err)
+	}
 	return response.JSON(http.StatusOK, []byte{})
 }
 
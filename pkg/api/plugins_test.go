@@ -1,22 +1,32 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/plugins/plugindashboards"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func Test_GetPluginAssets(t *testing.T) {
@@ -151,6 +161,72 @@ func Test_GetPluginAssets(t *testing.T) {
 				assert.Empty(t, l.warnings)
 			})
 	})
+
+	t.Run("Given a request for a versioned plugin file", func(t *testing.T) {
+		service := &pluginManager{
+			plugins: map[string]*plugins.PluginBase{
+				pluginID: {
+					Id:           pluginID,
+					PluginDir:    pluginDir,
+					AssetVersion: "abcdef",
+				},
+			},
+		}
+		l := &logger{}
+
+		url := fmt.Sprintf("/public/plugins/%s/%s", pluginID, requestedFile)
+		pluginAssetScenario(t, "When calling GET on", url, "/public/plugins/:pluginId/*", service, l,
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"_v": "abcdef"}).exec()
+
+				require.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, "public, max-age=31536000, immutable", sc.resp.Header().Get("Cache-Control"))
+
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"_v": "outdated"}).exec()
+
+				require.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, "no-cache", sc.resp.Header().Get("Cache-Control"))
+				etag := sc.resp.Header().Get("ETag")
+				require.NotEmpty(t, etag)
+
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{})
+				sc.req.Header.Set("If-None-Match", etag)
+				sc.exec()
+
+				require.Equal(t, 304, sc.resp.Code)
+			})
+	})
+
+	t.Run("Given a request for a translation namespace", func(t *testing.T) {
+		localesDir := t.TempDir()
+		for locale, body := range map[string]string{"en-US": `{"title":"Title"}`, "fr-FR": `{"title":"Titre"}`} {
+			require.NoError(t, os.MkdirAll(filepath.Join(localesDir, "locales", locale), 0750))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(localesDir, "locales", locale, "panel.json"), []byte(body), 0600))
+		}
+		service := &pluginManager{
+			plugins: map[string]*plugins.PluginBase{
+				pluginID: {
+					Id:        pluginID,
+					PluginDir: localesDir,
+					Locales:   []string{"en-US", "fr-FR"},
+				},
+			},
+		}
+		l := &logger{}
+
+		url := fmt.Sprintf("/public/plugins/%s/locales/panel.json", pluginID)
+		pluginAssetScenario(t, "When calling GET on", url, "/public/plugins/:pluginId/*", service, l,
+			func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{})
+				sc.req.Header.Set("Accept-Language", "fr-CA,fr;q=0.9,en;q=0.8")
+				sc.exec()
+
+				require.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, `{"title":"Titre"}`, sc.resp.Body.String())
+				assert.Equal(t, "fr-FR", sc.resp.Header().Get("Content-Language"))
+				assert.Equal(t, "Accept-Language", sc.resp.Header().Get("Vary"))
+			})
+	})
 }
 
 func callGetPluginAsset(sc *scenarioContext) {
@@ -199,3 +275,286 @@ type logger struct {
 func (l *logger) Warn(msg string, ctx ...interface{}) {
 	l.warnings = append(l.warnings, msg)
 }
+
+type fakeInstallPluginManager struct {
+	pluginManager
+
+	installed []string
+}
+
+func (pm *fakeInstallPluginManager) Install(_ context.Context, pluginID, _ string) error {
+	pm.installed = append(pm.installed, pluginID)
+	return nil
+}
+
+func TestAPI_PluginManagement_AccessControl(t *testing.T) {
+	tests := []accessControlTestCase{
+		{
+			desc:         "should be able to install plugin with install permission",
+			expectedCode: http.StatusOK,
+			url:          "/api/plugins/test-app/install",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsInstall, Scope: "plugins:id:test-app"}},
+		},
+		{
+			desc:         "should be able to install plugin with install permission on all plugins",
+			expectedCode: http.StatusOK,
+			url:          "/api/plugins/test-app/install",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsInstall, Scope: ScopePluginsAll}},
+		},
+		{
+			desc:         "should not be able to install plugin with install permission on another plugin",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/plugins/test-app/install",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsInstall, Scope: "plugins:id:other-app"}},
+		},
+		{
+			desc:         "should not be able to install plugin without install permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/plugins/test-app/install",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsUninstall, Scope: ScopePluginsAll}},
+		},
+		{
+			desc:         "should not be able to uninstall plugin without uninstall permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/plugins/test-app/uninstall",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsInstall, Scope: ScopePluginsAll}},
+		},
+		{
+			desc:         "should not be able to configure plugin without configure permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/plugins/test-app/settings",
+			method:       http.MethodPost,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsRead, Scope: ScopePluginsAll}},
+		},
+		{
+			desc:         "should be able to read plugin errors with errors read permission",
+			expectedCode: http.StatusOK,
+			url:          "/api/plugins/errors",
+			method:       http.MethodGet,
+			permissions:  []*accesscontrol.Permission{{Action: ActionPluginsErrorsRead}},
+		},
+		{
+			desc:         "should not be able to read plugin errors without errors read permission",
+			expectedCode: http.StatusForbidden,
+			url:          "/api/plugins/errors",
+			method:       http.MethodGet,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sc, hs := setupAccessControlScenarioContext(t, setting.NewCfg(), test.url, test.permissions)
+			pm := &fakeInstallPluginManager{}
+			hs.PluginManager = pm
+
+			sc.resp = httptest.NewRecorder()
+			var err error
+			sc.req, err = http.NewRequest(test.method, test.url, strings.NewReader("{}"))
+			require.NoError(t, err)
+			sc.req.Header.Set("Content-Type", "application/json")
+
+			sc.exec()
+
+			assert.Equal(t, test.expectedCode, sc.resp.Code)
+			if test.expectedCode == http.StatusOK && test.url == "/api/plugins/test-app/install" {
+				assert.Equal(t, []string{"test-app"}, pm.installed)
+			}
+		})
+	}
+}
+
+func TestAPI_PinPluginDashboard(t *testing.T) {
+	hs := &HTTPServer{PluginDashboardService: &plugindashboards.Service{}}
+	url := "/api/plugins/test-app/dashboards/1/pin"
+	routePattern := "/api/plugins/:pluginId/dashboards/:dashboardId/pin"
+
+	loggedInUserScenarioWithRole(t, "When pinning a missing dashboard", "GET", url, routePattern, models.ROLE_ADMIN,
+		func(sc *scenarioContext) {
+			bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetDashboardQuery) error {
+				return models.ErrDashboardNotFound
+			})
+
+			sc.handlerFunc = hs.PinPluginDashboard
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, http.StatusNotFound, sc.resp.Code)
+		})
+
+	loggedInUserScenarioWithRole(t, "When pinning a dashboard of another plugin", "GET", url, routePattern, models.ROLE_ADMIN,
+		func(sc *scenarioContext) {
+			bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetDashboardQuery) error {
+				query.Result = &models.Dashboard{Id: query.Id, OrgId: query.OrgId, PluginId: "other-app"}
+				return nil
+			})
+
+			sc.handlerFunc = hs.PinPluginDashboard
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		})
+}
+
+func TestHTTPServer_importDashboardFolder(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetPluginSettingByIdQuery) error {
+		query.Result = &models.PluginSetting{JsonData: map[string]interface{}{"dashboardFolder": "Kubernetes"}}
+		return nil
+	})
+	origNewFolderService := dashboards.NewFolderService
+	t.Cleanup(func() {
+		dashboards.NewFolderService = origNewFolderService
+	})
+
+	hs := &HTTPServer{PluginDashboardService: &plugindashboards.Service{}}
+	c := &models.ReqContext{
+		Context:      &web.Context{Req: httptest.NewRequest(http.MethodPost, "/api/dashboards/import", nil)},
+		SignedInUser: &models.SignedInUser{UserId: testUserID, OrgId: testOrgID, OrgRole: models.ROLE_EDITOR},
+	}
+
+	t.Run("Should import in the folder of the command", func(t *testing.T) {
+		mockFolderService(&fakeFolderService{GetFolderByUIDResult: &models.Folder{Id: 2}})
+
+		folderID, resp := hs.importDashboardFolder(c, dtos.ImportDashboardCommand{PluginId: "test-app", FolderUid: "abc"}, true)
+		require.Nil(t, resp)
+		assert.Equal(t, int64(2), folderID)
+
+		folderID, resp = hs.importDashboardFolder(c, dtos.ImportDashboardCommand{PluginId: "test-app", FolderId: 4}, true)
+		require.Nil(t, resp)
+		assert.Equal(t, int64(4), folderID)
+	})
+
+	t.Run("Should import in the existing default folder of the plugin", func(t *testing.T) {
+		mockFolderService(&fakeFolderService{GetFolderByTitleResult: &models.Folder{Id: 3, Title: "Kubernetes"}})
+
+		folderID, resp := hs.importDashboardFolder(c, dtos.ImportDashboardCommand{PluginId: "test-app"}, true)
+		require.Nil(t, resp)
+		assert.Equal(t, int64(3), folderID)
+	})
+
+	t.Run("Should import in the General folder when previewing with a missing default folder", func(t *testing.T) {
+		mockFolderService(&fakeFolderService{GetFolderByTitleError: models.ErrFolderNotFound})
+
+		folderID, resp := hs.importDashboardFolder(c, dtos.ImportDashboardCommand{PluginId: "test-app"}, false)
+		require.Nil(t, resp)
+		assert.Equal(t, int64(0), folderID)
+	})
+
+	t.Run("Should deny the default folder of the plugin the user can't access", func(t *testing.T) {
+		mockFolderService(&fakeFolderService{GetFolderByTitleError: models.ErrFolderAccessDenied})
+
+		_, resp := hs.importDashboardFolder(c, dtos.ImportDashboardCommand{PluginId: "test-app"}, true)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.Status())
+	})
+}
+
+func TestHTTPServer_checkResourceRoute(t *testing.T) {
+	hs := &HTTPServer{Cfg: setting.NewCfg(), AccessControl: accesscontrolmock.New().WithDisabled()}
+	plugin := &plugins.PluginBase{
+		Id: "test-app",
+		Resources: []*plugins.PluginResourceRoute{
+			{Path: "public/*", ReqRole: models.ROLE_VIEWER},
+			{Path: "admin/*", ReqRole: models.ROLE_ADMIN},
+		},
+	}
+	newContext := func() (*models.ReqContext, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/plugins/test-app/resources/public/x", nil)
+		rec := httptest.NewRecorder()
+		return &models.ReqContext{
+			Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(http.MethodGet, rec)},
+			SignedInUser: &models.SignedInUser{OrgRole: models.ROLE_VIEWER},
+		}, rec
+	}
+
+	c, _ := newContext()
+	require.True(t, hs.checkResourceRoute(c, plugin, "public/x"))
+
+	c, rec := newContext()
+	require.False(t, hs.checkResourceRoute(c, plugin, "admin/x"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	for _, resourcePath := range []string{"public/../admin/x", "public/./x", "public//x"} {
+		c, rec := newContext()
+		require.False(t, hs.checkResourceRoute(c, plugin, resourcePath), resourcePath)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, resourcePath)
+	}
+}
+
+func TestGetAppNavLink(t *testing.T) {
+	hs := &HTTPServer{Cfg: &setting.Cfg{AppSubURL: "/grafana"}}
+	app := &plugins.AppPlugin{
+		FrontendPluginBase: plugins.FrontendPluginBase{
+			PluginBase: plugins.PluginBase{
+				Id:   "test-app",
+				Name: "Test App",
+				Includes: []*plugins.PluginInclude{
+					{Type: "page", Name: "Overview", Slug: "overview", Role: models.ROLE_VIEWER, AddToNav: true},
+					{Type: "page", Name: "Explore", Slug: "explore", Path: "/a/test-app/explore", Role: models.ROLE_VIEWER,
+						AddToNav: true, DefaultNav: true},
+					{Type: "page", Name: "Config", Slug: "config", Role: models.ROLE_ADMIN, AddToNav: true},
+					{Type: "dashboard", Name: "Details", Slug: "details", UID: "abc", Role: models.ROLE_VIEWER},
+					{Type: "datasource", Name: "Test", Role: models.ROLE_VIEWER},
+				},
+			},
+		},
+	}
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgRole: models.ROLE_EDITOR}}
+
+	link := hs.getAppNavLink(c, app)
+	assert.Equal(t, "plugin-page-test-app", link.Id)
+	assert.Equal(t, "/grafana/a/test-app/explore", link.Url)
+	assert.Equal(t, []*dtos.NavLink{
+		{Id: "plugin-page-test-app-overview", Text: "Overview", Url: "/grafana/plugins/test-app/page/overview"},
+		{Id: "plugin-page-test-app-explore", Text: "Explore", Url: "/grafana/a/test-app/explore"},
+		{Id: "plugin-dashboard-test-app-abc", Text: "Details", Url: "/grafana/d/abc", HideFromMenu: true},
+	}, link.Children)
+}
+
+func TestGetAppNavLink_AccessControl(t *testing.T) {
+	app := &plugins.AppPlugin{
+		FrontendPluginBase: plugins.FrontendPluginBase{
+			PluginBase: plugins.PluginBase{
+				Id:   "test-app",
+				Name: "Test App",
+				Includes: []*plugins.PluginInclude{
+					{Type: "page", Name: "Overview", Slug: "overview", Role: models.ROLE_VIEWER, AddToNav: true},
+					{Type: "page", Name: "Reports", Slug: "reports", Role: models.ROLE_VIEWER, AddToNav: true,
+						Action: "test-app.reports:read"},
+					{Type: "page", Name: "Config", Slug: "config", Role: models.ROLE_ADMIN, AddToNav: true,
+						Action: "test-app:configure"},
+				},
+			},
+		},
+	}
+	navIDs := func(link *dtos.NavLink) []string {
+		var ids []string
+		for _, child := range link.Children {
+			ids = append(ids, child.Id)
+		}
+		return ids
+	}
+	c := &models.ReqContext{
+		Context:      &web.Context{Req: httptest.NewRequest(http.MethodGet, "/api/plugins/test-app/nav", nil)},
+		SignedInUser: &models.SignedInUser{OrgRole: models.ROLE_VIEWER},
+	}
+
+	t.Run("Should require the action of the includes", func(t *testing.T) {
+		hs := &HTTPServer{
+			Cfg: &setting.Cfg{},
+			AccessControl: accesscontrolmock.New().WithPermissions([]*accesscontrol.Permission{
+				{Action: "test-app:configure"},
+			}),
+		}
+		assert.Equal(t, []string{"plugin-page-test-app-overview", "plugin-page-test-app-config"},
+			navIDs(hs.getAppNavLink(c, app)))
+	})
+
+	t.Run("Should require the role of the includes when access control is disabled", func(t *testing.T) {
+		hs := &HTTPServer{Cfg: &setting.Cfg{}, AccessControl: accesscontrolmock.New().WithDisabled()}
+		assert.Equal(t, []string{"plugin-page-test-app-overview", "plugin-page-test-app-reports"},
+			navIDs(hs.getAppNavLink(c, app)))
+	})
+}
@@ -14,6 +14,14 @@ const (
 	ActionDatasourcesWrite  = "datasources:write"
 	ActionDatasourcesDelete = "datasources:delete"
 	ActionDatasourcesIDRead = "datasources.id:read"
+	ActionDatasourcesQuery  = "datasources:query"
+
+	ActionPluginsRead       = "plugins:read"
+	ActionPluginsInstall    = "plugins:install"
+	ActionPluginsUninstall  = "plugins:uninstall"
+	ActionPluginsConfigure  = "plugins:configure"
+	ActionPluginsErrorsRead = "plugins.errors:read"
+	ActionPluginsEncrypt    = "plugins:encrypt"
 )
 
 // API related scopes
@@ -28,6 +36,9 @@ var (
 	ScopeDatasourceID   = accesscontrol.Scope("datasources", "id", accesscontrol.Parameter(":id"))
 	ScopeDatasourceUID  = accesscontrol.Scope("datasources", "uid", accesscontrol.Parameter(":uid"))
 	ScopeDatasourceName = accesscontrol.Scope("datasources", "name", accesscontrol.Parameter(":name"))
+
+	ScopePluginsAll = accesscontrol.Scope("plugins", "*")
+	ScopePluginID   = accesscontrol.Scope("plugins", "id", accesscontrol.Parameter(":pluginId"))
 )
 
 // declareFixedRoles declares to the AccessControl service fixed roles and their
@@ -86,9 +97,98 @@ func (hs *HTTPServer) declareFixedRoles() error {
 			},
 			Grants: []string{string(models.ROLE_VIEWER)},
 		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:datasources:querier",
+				Description: "Gives access to query datasources",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: ActionDatasourcesQuery,
+						Scope:  ScopeDatasourcesAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_VIEWER)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:plugins:reader",
+				Description: "Gives access to use panel, data source and app plugins",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: ActionPluginsRead,
+						Scope:  ScopePluginsAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_VIEWER)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:plugins:errors:reader",
+				Description: "Gives access to read plugin errors",
+				Permissions: []accesscontrol.Permission{
+					{Action: ActionPluginsErrorsRead},
+				},
+			},
+			Grants: []string{string(models.ROLE_VIEWER)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:plugins:writer",
+				Description: "Gives access to configure plugins of the organization",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: ActionPluginsConfigure,
+						Scope:  ScopePluginsAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_ADMIN)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:plugins:encrypter",
+				Description: "Gives access to encrypt, decrypt and sign data with the keys of app plugins",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: ActionPluginsEncrypt,
+						Scope:  ScopePluginsAll,
+					},
+				},
+			},
+			Grants: []string{string(models.ROLE_ADMIN)},
+		},
+		{
+			Role: accesscontrol.RoleDTO{
+				Version:     1,
+				Name:        "fixed:plugins:admin",
+				Description: "Gives access to install and uninstall plugins, and to read plugin errors",
+				Permissions: []accesscontrol.Permission{
+					{
+						Action: ActionPluginsInstall,
+						Scope:  ScopePluginsAll,
+					},
+					{
+						Action: ActionPluginsUninstall,
+						Scope:  ScopePluginsAll,
+					},
+					{Action: ActionPluginsErrorsRead},
+				},
+			},
+			Grants: []string{accesscontrol.RoleGrafanaAdmin},
+		},
 	}
 
-	return hs.AccessControl.DeclareFixedRoles(registrations...)
+	if err := hs.AccessControl.DeclareFixedRoles(registrations...); err != nil {
+		return err
+	}
+	return hs.declarePluginRoles()
 }
 
 // Evaluators
@@ -6,6 +6,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
@@ -124,6 +125,23 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "plugins-backup",
+		Usage: "plugins-backup <file>",
+		Description: `plugins-backup writes the plugins installed in the plugins directory, with their versions,
+and the plugin settings of all organizations to a gzipped tarball. The secure JSON data of
+the plugin settings isn't part of the backup, export it with grafana-cli secrets export.`,
+		Action: runDbCommand(backupPluginsCommand),
+	},
+	{
+		Name:  "plugins-restore",
+		Usage: "plugins-restore <file>",
+		Description: `plugins-restore installs the plugins of a backup written by plugins-backup which aren't
+installed yet, and restores the plugin settings of the existing organizations. Plugins which
+aren't signed, unless allowed with allow_loading_unsigned_plugins, or whose signature is
+invalid or modified are uninstalled.`,
+		Action: runDbCommand(restorePluginsCommand),
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your db",
@@ -137,6 +155,87 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var secretsCommands = []*cli.Command{
+	{
+		Name:  "migrate",
+		Usage: "migrate --from <provider> --to <provider>",
+		Description: `migrate re-encrypts the stored secrets protected by one encryption provider with
+another one. Use legacy as provider for the secrets encrypted with the secret key only.
+Secrets already migrated are skipped, so an interrupted migration resumes where it
+stopped when the command is run again.`,
+		Action: runDbCommand(secretsmigrations.MigrateProvider),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "encryption provider currently protecting the secrets",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "encryption provider to protect the secrets with",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "report the secrets to migrate without changing them",
+				Value: false,
+			},
+		},
+	},
+	{
+		Name:  "export",
+		Usage: "export <file>",
+		Description: `export writes the secrets encrypted with data keys and these data keys, encrypted by
+their encryption provider, to a bundle that can be imported by another Grafana instance
+with access to the same encryption providers.`,
+		Action: runDbCommand(secretsmigrations.ExportSecrets),
+	},
+	{
+		Name:  "import",
+		Usage: "import <file>",
+		Description: `import replaces the secrets of the data sources, plugin settings and alert notification
+channels with the ones of a bundle written by export. Rows are matched by organization and
+UID, or plugin ID for plugin settings.`,
+		Action: runDbCommand(secretsmigrations.ImportSecrets),
+	},
+	{
+		Name:  "validate",
+		Usage: "check that all the stored secrets can be decrypted",
+		Description: `validate decrypts the secrets of the data sources, plugin settings and alert notification
+channels, reports the ones that can't be decrypted and the rows still encrypted with the
+secret key only. Nothing is written.`,
+		Action: runDbCommand(secretsmigrations.ValidateSecrets),
+	},
+	{
+		Name:  "rotate",
+		Usage: "deactivate the data keys and re-encrypt the secrets with new ones",
+		Description: `rotate deactivates the data keys, so that new data keys are created for the next secrets,
+and re-encrypts the stored secrets with new data keys. Use --scope, multiple times if needed,
+to only rotate the data keys of some scopes. Secrets encrypted with the secret key only are
+left unchanged.`,
+		Action: runDbCommand(secretsmigrations.RotateDataKeys),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "scope",
+				Usage: "scope of the data keys to rotate, e.g. root or org:1",
+			},
+		},
+	},
+	{
+		Name:  "re-encrypt",
+		Usage: "re-encrypt [--scope <scope>]",
+		Description: `re-encrypt re-encrypts the stored secrets with the current data keys of their scope,
+created by the configured encryption provider. Use --scope, multiple times if needed, to only
+re-encrypt the secrets of some scopes. Secrets encrypted with the secret key only are left
+unchanged.`,
+		Action: runDbCommand(secretsmigrations.ReEncryptSecrets),
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "scope",
+				Usage: "scope of the data keys of the secrets to re-encrypt, e.g. root or org:1",
+			},
+		},
+	},
+}
+
 var cueCommands = []*cli.Command{
 	{
 		Name:   "validate-schema",
@@ -195,6 +294,11 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "secrets",
+		Usage:       "Manage the encryption of secrets",
+		Subcommands: secretsCommands,
+	},
 	{
 		Name:        "cue",
 		Usage:       "Cue validation commands",
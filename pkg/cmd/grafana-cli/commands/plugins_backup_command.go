@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager"
+	"github.com/grafana/grafana/pkg/plugins/manager/installer"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// backupPluginsCommand writes a backup of the installed external plugins and of the plugin settings to the file
+// given as argument.
+func backupPluginsCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("the path of the plugin backup is required")
+	}
+
+	cfg := sqlStore.Cfg
+	if cfg.BuildVersion == "" {
+		cfg.BuildVersion = services.GrafanaVersion
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errutil.Wrap("failed to create the plugin backup", err)
+	}
+	if err := manager.WritePluginBackup(context.Background(), cfg, sqlStore, f); err != nil {
+		_ = f.Close()
+		return errutil.Wrap("failed to back up plugins", err)
+	}
+	if err := f.Close(); err != nil {
+		return errutil.Wrap("failed to write the plugin backup", err)
+	}
+
+	logger.Infof("%s Backed up the plugins of %s to %s\n", color.GreenString("✔"), cfg.PluginsPath, path)
+	return nil
+}
+
+// restorePluginsCommand restores the plugin backup given as argument.
+func restorePluginsCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("the path of the plugin backup is required")
+	}
+
+	i := installer.New(false, services.GrafanaVersion, services.Logger)
+	report, err := manager.RestorePluginBackup(context.Background(), sqlStore.Cfg, sqlStore, i, path)
+	if err != nil {
+		return errutil.Wrap("failed to restore the plugin backup", err)
+	}
+
+	for _, result := range report.Plugins {
+		switch result.Status {
+		case plugins.PluginRestoreInstalled:
+			logger.Infof("%s Installed %s v%s\n", color.GreenString("✔"), result.PluginID, result.Version)
+		case plugins.PluginRestoreSkipped:
+			logger.Infof("Skipped %s: %s\n", result.PluginID, result.Message)
+		default:
+			logger.Errorf("%s Failed to restore %s v%s: %s\n", color.RedString("✗"), result.PluginID, result.Version,
+				result.Message)
+		}
+	}
+	logger.Infof("Restored the settings of %d plugins\n", report.RestoredSettings)
+	if report.SkippedSettings > 0 {
+		logger.Warnf("Warning: The settings of %d plugins were not restored, as their organization doesn't exist in this instance.\n",
+			report.SkippedSettings)
+	}
+	logger.Info(color.GreenString("Please restart Grafana to load the restored plugins.\n"))
+	return nil
+}
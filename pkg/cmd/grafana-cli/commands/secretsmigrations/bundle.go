@@ -0,0 +1,84 @@
+package secretsmigrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ExportSecrets writes the secrets encrypted with data keys and these data keys to the file given as argument.
+func ExportSecrets(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("the path of the secrets bundle is required")
+	}
+
+	secretsService, err := newSecretsService(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	bundle, skipped, err := secretsService.ExportSecrets(context.Background())
+	if err != nil {
+		return errutil.Wrap("failed to export secrets", err)
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the bundle only contains encrypted values, but it's still restricted to the current user
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return errutil.Wrap("failed to write the secrets bundle", err)
+	}
+
+	logger.Infof("%s Exported the secrets of %d rows and %d data keys to %s\n", color.GreenString("✔"),
+		len(bundle.Secrets), len(bundle.DataKeys), path)
+	if skipped > 0 {
+		logger.Warnf("Warning: The secrets of %d rows are encrypted with the secret key and were not exported. "+
+			"Migrate them with grafana-cli secrets migrate --from legacy --to <provider> to export them.\n", skipped)
+	}
+	return nil
+}
+
+// ImportSecrets replaces the secrets of the data sources, plugin settings and alert notification channels with
+// the ones of the bundle given as argument.
+func ImportSecrets(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("the path of the secrets bundle is required")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errutil.Wrap("failed to read the secrets bundle", err)
+	}
+	var bundle secrets.SecretsBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return errutil.Wrap("failed to parse the secrets bundle", err)
+	}
+
+	secretsService, err := newSecretsService(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	result, err := secretsService.ImportSecrets(context.Background(), &bundle)
+	if err != nil {
+		return errutil.Wrap("failed to import secrets", err)
+	}
+
+	logger.Infof("%s Imported the secrets of %d rows and %d data keys\n", color.GreenString("✔"), result.Imported, result.DataKeys)
+	if result.NotFound > 0 {
+		logger.Warnf("Warning: The secrets of %d rows were not imported, as the rows don't exist in this instance.\n", result.NotFound)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package secretsmigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ValidateSecrets checks that every stored secret can be decrypted, and reports the rows still encrypted
+// with the secret key only.
+func ValidateSecrets(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	secretsService, err := newSecretsService(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	results, err := secretsService.ValidateSecrets(context.Background())
+	if err != nil {
+		return errutil.Wrap("failed to validate secrets", err)
+	}
+
+	logger.Info("\n")
+	var failed, legacy int
+	for _, result := range results {
+		logger.Infof("%s Validated the secrets of %d rows of %s\n", color.GreenString("✔"), result.Processed, result.Column.Table)
+		for _, failure := range result.Failures {
+			logger.Infof("%s Failed to decrypt %s of %s %d (org %d, %s): %s\n", color.RedString("✗"), failure.Key,
+				result.Column.Table, failure.Id, failure.OrgId, failure.RowKey, failure.Error)
+		}
+		if result.Legacy > 0 {
+			logger.Infof("%s %d rows of %s are encrypted with the secret key only\n", color.YellowString("!"), result.Legacy,
+				result.Column.Table)
+		}
+		failed += len(result.Failures)
+		legacy += int(result.Legacy)
+	}
+
+	if legacy > 0 {
+		logger.Warnf("\nWarning: Migrate the secrets encrypted with the secret key with grafana-cli secrets migrate --from legacy --to <provider>.\n")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d secrets can't be decrypted", failed)
+	}
+	return nil
+}
+
+// RotateDataKeys deactivates the data keys and re-encrypts the stored secrets with new data keys, only the
+// ones of the scopes given by the scope flag if any.
+func RotateDataKeys(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	return reEncryptSecrets(sqlStore, true, c.StringSlice("scope"))
+}
+
+// ReEncryptSecrets re-encrypts the stored secrets with the current data keys of their scope, only the ones of
+// the scopes given by the scope flag if any.
+func ReEncryptSecrets(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	return reEncryptSecrets(sqlStore, false, c.StringSlice("scope"))
+}
+
+func reEncryptSecrets(sqlStore *sqlstore.SQLStore, rotate bool, scopes []string) error {
+	secretsService, err := newSecretsService(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	status, err := secretsService.ReEncryptSecrets(context.Background(), rotate, scopes)
+	if err != nil {
+		return errutil.Wrap("failed to re-encrypt secrets", err)
+	}
+
+	logReEncryptionStatus(status)
+	if status.Error != "" {
+		return fmt.Errorf("failed to re-encrypt secrets: %s", status.Error)
+	}
+	if status.Failed > 0 {
+		return fmt.Errorf("failed to re-encrypt the secrets of %d rows, run the command again to retry them", status.Failed)
+	}
+	return nil
+}
+
+func logReEncryptionStatus(status secrets.ReEncryptionStatus) {
+	logger.Info("\n")
+	logger.Infof("%s Re-encrypted the secrets of %d of %d rows in %s\n", color.GreenString("✔"), status.ReEncrypted,
+		status.Processed, status.Finished.Sub(status.Started))
+	if status.Failed > 0 {
+		logger.Infof("%s Failed to re-encrypt the secrets of %d rows\n", color.RedString("✗"), status.Failed)
+	}
+}
@@ -0,0 +1,86 @@
+package secretsmigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// MigrateProvider re-encrypts the secrets protected by the encryption provider given by the
+// from flag with the one given by the to flag. Safe to execute multiple times.
+func MigrateProvider(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	from, to := c.String("from"), c.String("to")
+	if from == "" || to == "" {
+		return fmt.Errorf("both the --from and --to encryption providers are required")
+	}
+	dryRun := c.Bool("dry-run")
+
+	secretsService, err := newSecretsService(sqlStore)
+	if err != nil {
+		return err
+	}
+
+	results, err := secretsService.MigrateProvider(context.Background(), from, to, dryRun)
+
+	logger.Info("\n")
+	var failed int64
+	for _, result := range results {
+		failed += result.Failed
+		verb := "Migrated"
+		if dryRun {
+			verb = "Would migrate"
+		}
+		logger.Infof("%s %s secrets of %d of %d rows of %s\n", color.GreenString("✔"), verb, result.Migrated,
+			result.Processed, result.Column.Table)
+		if result.Failed > 0 {
+			logger.Infof("%s Failed to migrate the secrets of %d rows of %s\n", color.RedString("✗"), result.Failed,
+				result.Column.Table)
+		}
+	}
+	if err != nil {
+		return errutil.Wrap("failed to migrate secrets", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to migrate the secrets of %d rows, run the command again to retry them", failed)
+	}
+
+	if !dryRun && to != manager.LegacyProvider && secretsService.DefaultProvider() != to {
+		logger.Warnf("\nWarning: New secrets are still encrypted with the %s encryption provider. Set provider = %s "+
+			"in the [security.encryption] section of the configuration to use the %s provider for new secrets.\n",
+			secretsService.DefaultProvider(), to, to)
+	}
+	return nil
+}
+
+// newSecretsService returns a secrets service without the plugin encryption provider, as plugins aren't
+// loaded by the CLI.
+func newSecretsService(sqlStore *sqlstore.SQLStore) (*manager.SecretsService, error) {
+	secretsStore, err := database.ProvideService(sqlStore)
+	if err != nil {
+		return nil, errutil.Wrap("failed to initialize the secrets store", err)
+	}
+
+	secretsService, err := manager.ProvideSecretsService(
+		secretsStore,
+		bus.GetBus(),
+		ossencryption.ProvideService(),
+		&setting.OSSImpl{Cfg: sqlStore.Cfg},
+		sqlStore.Cfg,
+		nil,
+	)
+	if err != nil {
+		return nil, errutil.Wrap("failed to initialize the secrets service", err)
+	}
+	return secretsService, nil
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"runtime"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -106,8 +107,17 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingQueueWaiting is a metric gauge for image rendering requests waiting for a free rendering slot
+	MRenderingQueueWaiting prometheus.Gauge
+
+	// MRenderingRejectedTotal is a metric counter for image rendering requests rejected by the rendering queue, labeled by reason
+	MRenderingRejectedTotal *prometheus.CounterVec
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
+
+	// MPluginSettingsCacheRequests is a metric counter for plugin settings cache lookups, labeled by result
+	MPluginSettingsCacheRequests *prometheus.CounterVec
 )
 
 // Timers
@@ -186,6 +196,14 @@ var (
 
 	grafanaPluginBuildInfoDesc *prometheus.GaugeVec
 
+	// grafanaPluginStartupDuration is a metric of the duration of each phase of the startup of the plugins, labeled by
+	// pluginId and phase
+	grafanaPluginStartupDuration *prometheus.GaugeVec
+
+	// grafanaPluginDiskUsage is a metric of the disk usage of the plugins installed in the plugins directory, labeled
+	// by pluginId
+	grafanaPluginDiskUsage *prometheus.GaugeVec
+
 	// StatsTotalLibraryPanels is a metric of total number of library panels stored in Grafana.
 	StatsTotalLibraryPanels prometheus.Gauge
 
@@ -392,6 +410,15 @@ func init() {
 		[]string{"status", "type"},
 	)
 
+	MPluginSettingsCacheRequests = newCounterVecStartingAtZero(
+		prometheus.CounterOpts{
+			Name:      "plugin_settings_cache_requests_total",
+			Help:      "counter for plugin settings cache lookups",
+			Namespace: ExporterName,
+		},
+		[]string{"result"}, "hit", "miss",
+	)
+
 	MRenderingSummary = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Name:       "rendering_request_duration_milliseconds",
@@ -408,6 +435,21 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingQueueWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "rendering_queue_waiting",
+		Help:      "number of rendering requests waiting for a free rendering slot",
+		Namespace: ExporterName,
+	})
+
+	MRenderingRejectedTotal = newCounterVecStartingAtZero(
+		prometheus.CounterOpts{
+			Name:      "rendering_rejected_total",
+			Help:      "counter for rendering requests rejected by the rendering queue",
+			Namespace: ExporterName,
+		},
+		[]string{"reason"}, "queue_full", "user_quota", "org_quota",
+	)
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -518,6 +560,18 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"plugin_id", "plugin_type", "version", "signature_status"})
 
+	grafanaPluginStartupDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "plugin_startup_duration_seconds",
+		Help:      "Duration of each phase of the startup of a plugin, i.e. scan, signature, backend_start and initialize",
+		Namespace: ExporterName,
+	}, []string{"plugin_id", "phase"})
+
+	grafanaPluginDiskUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "plugin_disk_usage_bytes",
+		Help:      "Disk usage of a plugin installed in the plugins directory, as of its last check",
+		Namespace: ExporterName,
+	}, []string{"plugin_id"})
+
 	StatsTotalDashboardVersions = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard_versions",
 		Help:      "total amount of dashboard versions in the database",
@@ -601,6 +655,14 @@ func SetPluginBuildInformation(pluginID, pluginType, version, signatureStatus st
 	grafanaPluginBuildInfoDesc.WithLabelValues(pluginID, pluginType, version, signatureStatus).Set(1)
 }
 
+func SetPluginStartupDuration(pluginID, phase string, duration time.Duration) {
+	grafanaPluginStartupDuration.WithLabelValues(pluginID, phase).Set(duration.Seconds())
+}
+
+func SetPluginDiskUsage(pluginID string, size int64) {
+	grafanaPluginDiskUsage.WithLabelValues(pluginID).Set(float64(size))
+}
+
 func initMetricVars() {
 	prometheus.MustRegister(
 		MInstanceStart,
@@ -637,6 +699,9 @@ func initMetricVars() {
 		MRenderingRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
+		MRenderingQueueWaiting,
+		MRenderingRejectedTotal,
+		MPluginSettingsCacheRequests,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
@@ -655,6 +720,8 @@ func initMetricVars() {
 		StatsTotalDataSources,
 		grafanaBuildVersion,
 		grafanaPluginBuildInfoDesc,
+		grafanaPluginStartupDuration,
+		grafanaPluginDiskUsage,
 		StatsTotalDashboardVersions,
 		StatsTotalAnnotations,
 		MAccessEvaluationCount,
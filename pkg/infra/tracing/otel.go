@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	jaegerexporter "go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// initOpenTelemetry registers the global OpenTelemetry tracer provider, exporting the spans to the same Jaeger
+// agent as the OpenTracing spans, and the W3C trace context propagator.
+func (ts *TracingService) initOpenTelemetry() error {
+	host, port, err := net.SplitHostPort(ts.address)
+	if err != nil {
+		return err
+	}
+
+	exp, err := jaegerexporter.New(jaegerexporter.WithAgentEndpoint(
+		jaegerexporter.WithAgentHost(host),
+		jaegerexporter.WithAgentPort(port),
+	))
+	if err != nil {
+		return err
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String("grafana")}
+	for tag, value := range ts.customTags {
+		attrs = append(attrs, attribute.String(tag, value))
+	}
+
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithBatcher(exp),
+		tracesdk.WithSampler(tracesdk.ParentBased(ts.otelRootSampler())),
+		tracesdk.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	ts.tracerProvider = tp
+
+	return nil
+}
+
+// otelRootSampler returns the sampler of the OpenTelemetry spans without parent, the spans with a parent are
+// sampled if their parent is. Rate limiting and remote samplers sample all of them.
+func (ts *TracingService) otelRootSampler() tracesdk.Sampler {
+	switch ts.samplerType {
+	case "const":
+		if ts.samplerParam < 1 {
+			return tracesdk.NeverSample()
+		}
+		return tracesdk.AlwaysSample()
+	case "probabilistic":
+		return tracesdk.TraceIDRatioBased(ts.samplerParam)
+	default:
+		return tracesdk.AlwaysSample()
+	}
+}
+
+// ContextWithOpenTracingParent returns a context where the OpenTracing span of the context, if any, is the remote
+// parent of the OpenTelemetry spans started with it, so that they're part of the same trace as the HTTP request
+// spans. The context is returned as is if it already has an OpenTelemetry span.
+func ContextWithOpenTracingParent(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ctx
+	}
+	jaegerCtx, ok := span.Context().(jaeger.SpanContext)
+	if !ok || !jaegerCtx.IsValid() {
+		return ctx
+	}
+
+	var traceID trace.TraceID
+	binary.BigEndian.PutUint64(traceID[:8], jaegerCtx.TraceID().High)
+	binary.BigEndian.PutUint64(traceID[8:], jaegerCtx.TraceID().Low)
+	var spanID trace.SpanID
+	binary.BigEndian.PutUint64(spanID[:], uint64(jaegerCtx.SpanID()))
+
+	var flags trace.TraceFlags
+	if jaegerCtx.IsSampled() {
+		flags = trace.FlagsSampled
+	}
+
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	}))
+}
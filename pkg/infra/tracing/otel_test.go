@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextWithOpenTracingParent(t *testing.T) {
+	t.Run("Without span should return the context as is", func(t *testing.T) {
+		ctx := ContextWithOpenTracingParent(context.Background())
+		require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+
+	t.Run("With Jaeger span should use it as the remote parent", func(t *testing.T) {
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+		defer func() { require.NoError(t, closer.Close()) }()
+
+		span := tracer.StartSpan("request")
+		defer span.Finish()
+		jaegerCtx := span.Context().(jaeger.SpanContext)
+
+		ctx := ContextWithOpenTracingParent(opentracing.ContextWithSpan(context.Background(), span))
+		spanCtx := trace.SpanContextFromContext(ctx)
+		require.True(t, spanCtx.IsValid())
+		require.True(t, spanCtx.IsRemote())
+		require.True(t, spanCtx.IsSampled())
+		require.Contains(t, spanCtx.TraceID().String(), jaegerCtx.TraceID().String())
+	})
+}
@@ -13,6 +13,7 @@ import (
 	opentracing "github.com/opentracing/opentracing-go"
 	jaegercfg "github.com/uber/jaeger-client-go/config"
 	"github.com/uber/jaeger-client-go/zipkin"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const (
@@ -30,7 +31,10 @@ func ProvideService(cfg *setting.Cfg) (*TracingService, error) {
 	}
 
 	if ts.enabled {
-		return ts, ts.initGlobalTracer()
+		if err := ts.initGlobalTracer(); err != nil {
+			return nil, err
+		}
+		return ts, ts.initOpenTelemetry()
 	}
 
 	return ts, nil
@@ -47,6 +51,7 @@ type TracingService struct {
 	closer                   io.Closer
 	zipkinPropagation        bool
 	disableSharedZipkinSpans bool
+	tracerProvider           *tracesdk.TracerProvider
 
 	Cfg *setting.Cfg
 }
@@ -142,6 +147,12 @@ func (ts *TracingService) initGlobalTracer() error {
 func (ts *TracingService) Run(ctx context.Context) error {
 	<-ctx.Done()
 
+	if ts.tracerProvider != nil {
+		if err := ts.tracerProvider.Shutdown(context.Background()); err != nil {
+			ts.log.Warn("Failed to shut down OpenTelemetry tracer provider", "err", err)
+		}
+	}
+
 	if ts.closer != nil {
 		ts.log.Info("Closing tracing")
 		return ts.closer.Close()
@@ -13,6 +13,16 @@ type Report struct {
 	HasValidLicense bool                   `json:"hasValidLicense"`
 	Packaging       string                 `json:"packaging"`
 	UsageStatsId    string                 `json:"usageStatsId"`
+	// Plugins are the installed plugins published on grafana.com, unless disabled with reporting_plugins_enabled.
+	Plugins []PluginReport `json:"plugins,omitempty"`
+}
+
+// PluginReport is an installed plugin of a usage report.
+type PluginReport struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Version   string `json:"version"`
+	Signature string `json:"signature"`
 }
 
 type MetricsFunc func() (map[string]interface{}, error)
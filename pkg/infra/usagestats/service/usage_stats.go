@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 )
 
 var usageStatsURL = "https://stats.grafana.org/grafana-usage-report"
@@ -92,6 +94,10 @@ func (uss *UsageStats) GetUsageReport(ctx context.Context) (usagestats.Report, e
 	metrics["stats.edition.oss.count"] = ossEditionCount
 	metrics["stats.edition.enterprise.count"] = enterpriseEditionCount
 
+	if uss.Cfg.ReportingPluginsEnabled {
+		report.Plugins = uss.pluginInventory(metrics)
+	}
+
 	uss.registerExternalMetrics(metrics)
 
 	// must run after registration of external metrics
@@ -336,6 +342,45 @@ func (uss *UsageStats) ShouldBeReported(dsType string) bool {
 	return ds.Signature.IsValid() || ds.Signature.IsInternal()
 }
 
+// pluginInventory returns the installed plugins to report, and adds the counters of the installed plugins by
+// signature status, and of the plugins which failed to load by error, to the metrics. Like for the data sources, only
+// the plugins with a valid public signature, i.e. published on grafana.com, are identified, since the IDs of private
+// or unsigned plugins could be sensitive information. Core plugins are left out, their version is the one of Grafana.
+func (uss *UsageStats) pluginInventory(metrics map[string]interface{}) []usagestats.PluginReport {
+	inventory := []usagestats.PluginReport{}
+	signatures := map[plugins.PluginSignatureStatus]int{}
+	for _, p := range uss.PluginManager.Plugins() {
+		if p.Signature.IsInternal() {
+			continue
+		}
+		signatures[p.Signature]++
+		if !p.Signature.IsValid() || p.SignatureType == plugins.PrivateType {
+			continue
+		}
+		inventory = append(inventory, usagestats.PluginReport{
+			ID:        p.Id,
+			Type:      p.Type,
+			Version:   p.Info.Version,
+			Signature: string(p.Signature),
+		})
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].ID < inventory[j].ID
+	})
+
+	for signature, count := range signatures {
+		metrics["stats.plugins.signature."+string(signature)+".count"] = count
+	}
+	scanningErrors := map[plugins.ErrorCode]int{}
+	for _, e := range uss.PluginManager.ScanningErrors() {
+		scanningErrors[e.ErrorCode]++
+	}
+	for code, count := range scanningErrors {
+		metrics["stats.plugins.errors."+string(code)+".count"] = count
+	}
+	return inventory
+}
+
 func (uss *UsageStats) GetUsageStatsId(ctx context.Context) string {
 	anonId, ok, err := uss.kvStore.Get(ctx, "anonymous_id")
 	if err != nil {
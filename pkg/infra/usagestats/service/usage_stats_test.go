@@ -552,11 +552,48 @@ func TestMetrics(t *testing.T) {
 	})
 }
 
+func TestPluginInventory(t *testing.T) {
+	uss := createService(t, setting.Cfg{ReportingPluginsEnabled: true})
+	uss.PluginManager = &fakePluginManager{
+		plugins: []*plugins.PluginBase{
+			{Id: "prometheus", Type: "datasource", Signature: plugins.PluginSignatureInternal},
+			{Id: "grafana-piechart-panel", Type: "panel", Signature: plugins.PluginSignatureValid,
+				SignatureType: plugins.GrafanaType, Info: plugins.PluginInfo{Version: "1.6.2"}},
+			{Id: "acme-datasource", Type: "datasource", Signature: plugins.PluginSignatureValid,
+				SignatureType: plugins.PrivateType, Info: plugins.PluginInfo{Version: "2.0.0"}},
+			{Id: "acme-panel", Type: "panel", Signature: plugins.PluginSignatureUnsigned},
+		},
+		scanningErrors: []plugins.PluginError{{ErrorCode: "signatureModified", PluginID: "acme-app"}},
+	}
+
+	metrics := map[string]interface{}{}
+	inventory := uss.pluginInventory(metrics)
+
+	require.Equal(t, []usagestats.PluginReport{
+		{ID: "grafana-piechart-panel", Type: "panel", Version: "1.6.2", Signature: "valid"},
+	}, inventory)
+	require.Equal(t, map[string]interface{}{
+		"stats.plugins.signature.valid.count":          2,
+		"stats.plugins.signature.unsigned.count":       1,
+		"stats.plugins.errors.signatureModified.count": 1,
+	}, metrics)
+}
+
 type fakePluginManager struct {
 	manager.PluginManager
 
-	dataSources map[string]*plugins.DataSourcePlugin
-	panels      map[string]*plugins.PanelPlugin
+	dataSources    map[string]*plugins.DataSourcePlugin
+	panels         map[string]*plugins.PanelPlugin
+	plugins        []*plugins.PluginBase
+	scanningErrors []plugins.PluginError
+}
+
+func (pm *fakePluginManager) Plugins() []*plugins.PluginBase {
+	return pm.plugins
+}
+
+func (pm *fakePluginManager) ScanningErrors() []plugins.PluginError {
+	return pm.scanningErrors
 }
 
 func (pm *fakePluginManager) DataSourceCount() int {
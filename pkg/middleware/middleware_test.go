@@ -105,7 +105,7 @@ func TestMiddlewareContext(t *testing.T) {
 			t.Log("Handler called")
 			data := &dtos.IndexViewData{
 				User:     &dtos.CurrentUser{},
-				Settings: map[string]interface{}{},
+				Settings: &dtos.FrontendSettingsDTO{},
 				NavTree:  []*dtos.NavLink{},
 			}
 			t.Log("Calling HTML", "data", data)
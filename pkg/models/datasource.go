@@ -54,6 +54,8 @@ type DataSource struct {
 	SecureJsonData    map[string][]byte `json:"secureJsonData"`
 	ReadOnly          bool              `json:"readOnly"`
 	Uid               string            `json:"uid"`
+	// SecureJsonExpiry are the dates the secrets of the secure JSON data expire or are due for rotation, by key.
+	SecureJsonExpiry map[string]time.Time `json:"secureJsonExpiry"`
 
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
@@ -79,6 +81,8 @@ type AddDataSourceCommand struct {
 	JsonData          *simplejson.Json  `json:"jsonData"`
 	SecureJsonData    map[string]string `json:"secureJsonData"`
 	Uid               string            `json:"uid"`
+	// SecureJsonExpiry are the dates the secrets expire or are due for rotation, by key.
+	SecureJsonExpiry map[string]time.Time `json:"secureJsonExpiry"`
 
 	OrgId                   int64             `json:"-"`
 	ReadOnly                bool              `json:"-"`
@@ -105,6 +109,9 @@ type UpdateDataSourceCommand struct {
 	SecureJsonData    map[string]string `json:"secureJsonData"`
 	Version           int               `json:"version"`
 	Uid               string            `json:"uid"`
+	// SecureJsonExpiry sets the dates the secrets expire or are due for rotation, by key. A null date removes the
+	// date of the secret. The dates of the replaced secrets are removed unless they're set again.
+	SecureJsonExpiry map[string]time.Time `json:"secureJsonExpiry"`
 
 	OrgId                   int64             `json:"-"`
 	Id                      int64             `json:"-"`
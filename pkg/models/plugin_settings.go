@@ -6,7 +6,8 @@ import (
 )
 
 var (
-	ErrPluginSettingNotFound = errors.New("plugin setting not found")
+	ErrPluginSettingNotFound        = errors.New("plugin setting not found")
+	ErrPluginSettingVersionNotFound = errors.New("plugin setting version not found")
 )
 
 type PluginSetting struct {
@@ -18,11 +19,45 @@ type PluginSetting struct {
 	JsonData       map[string]interface{}
 	SecureJsonData map[string][]byte
 	PluginVersion  string
+	// SecureJsonExpiry are the dates the secrets of the secure JSON data expire or are due for rotation, by key.
+	SecureJsonExpiry map[string]time.Time
 
 	Created time.Time
 	Updated time.Time
 }
 
+// PluginSettingVersion is a version of the settings of a plugin for an organization, saved whenever they're updated
+// so that they can be rolled back. The secure JSON data is saved encrypted, as in the settings.
+type PluginSettingVersion struct {
+	Id       int64
+	OrgId    int64
+	PluginId string
+	Version  int
+	// RestoredFrom is the version the settings were rolled back to, if any.
+	RestoredFrom   int
+	Enabled        bool
+	Pinned         bool
+	JsonData       map[string]interface{}
+	SecureJsonData map[string][]byte
+	PluginVersion  string
+
+	Created   time.Time
+	CreatedBy int64
+}
+
+// PluginSettingVersionDTO is a version of the settings of a plugin, with the keys of its secure JSON data only.
+type PluginSettingVersionDTO struct {
+	Version          int                    `json:"version"`
+	RestoredFrom     int                    `json:"restoredFrom"`
+	Enabled          bool                   `json:"enabled"`
+	Pinned           bool                   `json:"pinned"`
+	JsonData         map[string]interface{} `json:"jsonData"`
+	SecureJsonFields map[string]bool        `json:"secureJsonFields"`
+	PluginVersion    string                 `json:"pluginVersion"`
+	Created          time.Time              `json:"created"`
+	CreatedBy        string                 `json:"createdBy"`
+}
+
 // ----------------------
 // COMMANDS
 
@@ -33,12 +68,28 @@ type UpdatePluginSettingCmd struct {
 	JsonData       map[string]interface{} `json:"jsonData"`
 	SecureJsonData map[string]string      `json:"secureJsonData"`
 	PluginVersion  string                 `json:"version"`
+	// SecureJsonExpiry sets the dates the secrets expire or are due for rotation, by key. A null date removes the
+	// date of the secret. The dates of the replaced secrets are removed unless they're set again.
+	SecureJsonExpiry map[string]time.Time `json:"secureJsonExpiry"`
 
 	PluginId                string            `json:"-"`
 	OrgId                   int64             `json:"-"`
+	UserId                  int64             `json:"-"`
 	EncryptedSecureJsonData map[string][]byte `json:"-"`
 }
 
+// RestorePluginSettingVersionCmd rolls the settings of a plugin back to one of their versions. The restored settings
+// are saved as a new version, the Result, unless the history of the settings is disabled.
+type RestorePluginSettingVersionCmd struct {
+	Version int `json:"version" binding:"Required"`
+
+	PluginId string `json:"-"`
+	OrgId    int64  `json:"-"`
+	UserId   int64  `json:"-"`
+
+	Result *PluginSettingVersion `json:"-"`
+}
+
 // specific command, will only update version
 type UpdatePluginSettingVersionCmd struct {
 	PluginVersion string
@@ -63,8 +114,38 @@ type GetPluginSettingByIdQuery struct {
 	Result   *PluginSetting
 }
 
+// GetPluginSettingVersionsQuery returns the versions of the settings of a plugin, the latest first.
+type GetPluginSettingVersionsQuery struct {
+	PluginId string
+	OrgId    int64
+	Limit    int
+
+	Result []*PluginSettingVersion
+}
+
+type GetPluginSettingVersionQuery struct {
+	PluginId string
+	OrgId    int64
+	Version  int
+
+	Result *PluginSettingVersion
+}
+
 type PluginStateChangedEvent struct {
 	PluginId string
 	OrgId    int64
 	Enabled  bool
 }
+
+// PluginUpgradedEvent is published when an installed plugin is upgraded to another version.
+type PluginUpgradedEvent struct {
+	PluginId        string
+	PreviousVersion string
+	Version         string
+}
+
+// PluginSettingUpdatedEvent is published whenever the settings of a plugin are saved for an organization.
+type PluginSettingUpdatedEvent struct {
+	PluginId string
+	OrgId    int64
+}
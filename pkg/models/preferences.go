@@ -31,6 +31,8 @@ type GetPreferencesQuery struct {
 
 type GetPreferencesWithDefaultsQuery struct {
 	User *SignedInUser
+	// DefaultTheme replaces the instance wide default theme, e.g. with the default theme of the organization.
+	DefaultTheme string
 
 	Result *Preferences
 }
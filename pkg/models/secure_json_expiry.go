@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UpdateSecureJsonExpiry returns the expiry dates of the secrets of secure JSON data once it's updated: the dates of
+// the replaced secrets are removed, then the updated dates are set, a zero date removing the date of its secret.
+func UpdateSecureJsonExpiry(current map[string]time.Time, replaced map[string]string,
+	updated map[string]time.Time) map[string]time.Time {
+	expiry := make(map[string]time.Time, len(current)+len(updated))
+	for key, date := range current {
+		if _, ok := replaced[key]; !ok {
+			expiry[key] = date
+		}
+	}
+	for key, date := range updated {
+		if date.IsZero() {
+			delete(expiry, key)
+			continue
+		}
+		expiry[key] = date
+	}
+	return expiry
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateSecureJsonExpiry(t *testing.T) {
+	march := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	current := map[string]time.Time{"password": march, "token": march, "apiKey": march}
+
+	expiry := UpdateSecureJsonExpiry(current,
+		map[string]string{"password": "new password", "token": "new token"},
+		map[string]time.Time{"token": june, "apiKey": {}, "certificate": june})
+
+	require.Equal(t, map[string]time.Time{"token": june, "certificate": june}, expiry)
+	require.Equal(t, march, current["password"])
+	require.Empty(t, UpdateSecureJsonExpiry(nil, nil, nil))
+}
@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// PluginAdvisory is a security advisory of the plugin advisories feed, affecting a range of versions of a plugin.
+type PluginAdvisory struct {
+	ID       string `json:"id"`
+	PluginID string `json:"pluginId"`
+	// AffectedVersions is the version constraint matching the affected versions, e.g. "< 1.2.3" or
+	// ">= 1.0.0, < 1.2.3".
+	AffectedVersions string `json:"affectedVersions"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Severity         string `json:"severity"`
+	Summary          string `json:"summary"`
+	URL              string `json:"url,omitempty"`
+}
+
+// Affects returns whether the version of the plugin is affected by the advisory. Versions which can't be parsed
+// aren't affected.
+func (a PluginAdvisory) Affects(pluginVersion string) bool {
+	constraints, err := version.NewConstraint(a.AffectedVersions)
+	if err != nil {
+		return false
+	}
+	v, err := version.NewVersion(pluginVersion)
+	if err != nil {
+		return false
+	}
+	return constraints.Check(v)
+}
+
+// AffectingAdvisories returns the advisories affecting the version of the plugin.
+func AffectingAdvisories(advisories []PluginAdvisory, pluginID, pluginVersion string) []PluginAdvisory {
+	var affecting []PluginAdvisory
+	for _, a := range advisories {
+		if a.PluginID == pluginID && a.Affects(pluginVersion) {
+			affecting = append(affecting, a)
+		}
+	}
+	return affecting
+}
+
+// VulnerablePluginVersionError is returned when installing a version of a plugin affected by security advisories,
+// if installing vulnerable versions is blocked.
+type VulnerablePluginVersionError struct {
+	PluginID   string
+	Version    string
+	Advisories []PluginAdvisory
+}
+
+func (e VulnerablePluginVersionError) Error() string {
+	ids := make([]string, 0, len(e.Advisories))
+	for _, a := range e.Advisories {
+		ids = append(ids, a.ID)
+	}
+	return fmt.Sprintf("version %s of plugin '%s' is affected by security advisories %s", e.Version, e.PluginID,
+		strings.Join(ids, ", "))
+}
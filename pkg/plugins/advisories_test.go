@@ -0,0 +1,35 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginAdvisory_Affects(t *testing.T) {
+	advisory := PluginAdvisory{ID: "GHSA-1234", PluginID: "test-panel", AffectedVersions: ">= 1.0.0, < 1.2.3"}
+
+	require.True(t, advisory.Affects("1.0.0"))
+	require.True(t, advisory.Affects("1.2.2"))
+	require.False(t, advisory.Affects("1.2.3"))
+	require.False(t, advisory.Affects("0.9.0"))
+	require.False(t, advisory.Affects("not a version"))
+
+	invalid := PluginAdvisory{ID: "GHSA-5678", PluginID: "test-panel", AffectedVersions: "not a constraint"}
+	require.False(t, invalid.Affects("1.0.0"))
+}
+
+func TestAffectingAdvisories(t *testing.T) {
+	advisories := []PluginAdvisory{
+		{ID: "GHSA-1", PluginID: "test-panel", AffectedVersions: "< 2.0.0"},
+		{ID: "GHSA-2", PluginID: "test-panel", AffectedVersions: "< 1.0.0"},
+		{ID: "GHSA-3", PluginID: "test-app", AffectedVersions: "< 2.0.0"},
+	}
+
+	affecting := AffectingAdvisories(advisories, "test-panel", "1.5.0")
+	require.Equal(t, []PluginAdvisory{advisories[0]}, affecting)
+	require.Empty(t, AffectingAdvisories(advisories, "test-panel", "2.0.0"))
+
+	err := VulnerablePluginVersionError{PluginID: "test-panel", Version: "1.5.0", Advisories: affecting}
+	require.EqualError(t, err, "version 1.5.0 of plugin 'test-panel' is affected by security advisories GHSA-1")
+}
@@ -0,0 +1,57 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PluginArchiveViolationKind is the kind of a security violation found when inspecting a plugin archive.
+type PluginArchiveViolationKind string
+
+const (
+	// PluginArchivePathTraversal is an archive member written outside of the plugins directory.
+	PluginArchivePathTraversal PluginArchiveViolationKind = "path-traversal"
+	// PluginArchiveSymlinkEscape is a symlink whose target is outside of the plugin directory.
+	PluginArchiveSymlinkEscape PluginArchiveViolationKind = "symlink-escape"
+	// PluginArchiveSymlinkChain is a symlink whose target goes through another symlink of the archive, which is
+	// resolved by the file system once extracted rather than checked.
+	PluginArchiveSymlinkChain PluginArchiveViolationKind = "symlink-chain"
+	// PluginArchiveUnexpectedExecutable is an executable file which isn't the backend executable of the plugin.
+	PluginArchiveUnexpectedExecutable PluginArchiveViolationKind = "unexpected-executable"
+	// PluginArchiveWorldWritable is a file or directory writable by all users.
+	PluginArchiveWorldWritable PluginArchiveViolationKind = "world-writable"
+)
+
+// PluginArchiveViolation is a security violation found when inspecting a plugin archive.
+type PluginArchiveViolation struct {
+	Kind    PluginArchiveViolationKind `json:"kind"`
+	Path    string                     `json:"path"`
+	Message string                     `json:"message"`
+}
+
+// PluginArchiveReport is the security report of the inspection of a plugin archive, before its extraction.
+type PluginArchiveReport struct {
+	PluginID      string                   `json:"pluginId"`
+	ArchiveSHA256 string                   `json:"archiveSha256"`
+	Inspected     time.Time                `json:"inspected"`
+	Files         int                      `json:"files"`
+	Violations    []PluginArchiveViolation `json:"violations"`
+	// Extracted is whether the archive was extracted, i.e. whether it passed the inspection.
+	Extracted bool `json:"extracted"`
+}
+
+// PluginArchiveSecurityError is returned when a plugin archive fails the security inspection, in which case it isn't
+// extracted.
+type PluginArchiveSecurityError struct {
+	Report PluginArchiveReport
+}
+
+func (e PluginArchiveSecurityError) Error() string {
+	violations := make([]string, 0, len(e.Report.Violations))
+	for _, v := range e.Report.Violations {
+		violations = append(violations, fmt.Sprintf("%s: %s", v.Path, v.Message))
+	}
+	return fmt.Sprintf("archive of plugin '%s' failed the security inspection: %s", e.Report.PluginID,
+		strings.Join(violations, ", "))
+}
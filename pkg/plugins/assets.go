@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// ComputeAssetVersion returns the version of the assets of the plugin, used in their URLs when versioned asset URLs
+// are enabled: a hash of the version of the plugin, its module and its manifest, so that it changes whenever the
+// plugin is updated or rebuilt.
+func ComputeAssetVersion(p *PluginBase) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(p.Info.Version))
+	for _, file := range []string{"module.js", "MANIFEST.txt"} {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the files are based on the plugin folder
+		// structure on disk and not user input.
+		content, err := os.ReadFile(filepath.Join(p.PluginDir, file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		h.Write([]byte{0})
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// ComputeModuleIntegrity returns the Subresource Integrity hash of the module of the plugin, which the frontend
+// verifies the module against when loading it, e.g. from a CDN, or an empty string if the plugin has no module.js.
+func ComputeModuleIntegrity(p *PluginBase) (string, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the file is based on the plugin folder
+	// structure on disk and not user input.
+	content, err := os.ReadFile(filepath.Join(p.PluginDir, "module.js"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	sum := sha512.Sum384(content)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,47 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeAssetVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte("define([], function() {})"), 0600))
+	p := &PluginBase{Info: PluginInfo{Version: "1.0.0"}, PluginDir: dir}
+
+	version, err := ComputeAssetVersion(p)
+	require.NoError(t, err)
+	require.Len(t, version, 16)
+
+	t.Run("Should change with the version of the plugin", func(t *testing.T) {
+		updated := &PluginBase{Info: PluginInfo{Version: "1.0.1"}, PluginDir: dir}
+		updatedVersion, err := ComputeAssetVersion(updated)
+		require.NoError(t, err)
+		require.NotEqual(t, version, updatedVersion)
+	})
+
+	t.Run("Should change with the module of the plugin", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte("define([], function() { })"), 0600))
+		rebuiltVersion, err := ComputeAssetVersion(p)
+		require.NoError(t, err)
+		require.NotEqual(t, version, rebuiltVersion)
+	})
+}
+
+func TestComputeModuleIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	p := &PluginBase{PluginDir: dir}
+
+	integrity, err := ComputeModuleIntegrity(p)
+	require.NoError(t, err)
+	require.Empty(t, integrity)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte("alert('Hello, world.');"), 0600))
+	integrity, err = ComputeModuleIntegrity(p)
+	require.NoError(t, err)
+	require.Equal(t, "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO", integrity)
+}
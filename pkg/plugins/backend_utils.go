@@ -2,8 +2,12 @@ package plugins
 
 import (
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/grpcplugin"
 )
 
 func ComposePluginStartCommand(executable string) string {
@@ -17,3 +21,13 @@ func ComposePluginStartCommand(executable string) string {
 
 	return fmt.Sprintf("%s_%s_%s%s", executable, os, strings.ToLower(arch), extension)
 }
+
+// newBackendPluginFactory returns the factory of the backend of the plugin. The backend of a development plugin can be
+// attached to a process started outside of Grafana, e.g. under a debugger.
+func newBackendPluginFactory(base *PluginBase, pluginID, executable string) backendplugin.PluginFactoryFunc {
+	fullpath := filepath.Join(base.PluginDir, ComposePluginStartCommand(executable))
+	if base.IsDevPlugin {
+		return grpcplugin.NewDevBackendPlugin(pluginID, fullpath)
+	}
+	return grpcplugin.NewBackendPlugin(pluginID, fullpath)
+}
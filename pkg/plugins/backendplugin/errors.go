@@ -11,4 +11,6 @@ var (
 	ErrPluginUnavailable = errors.New("plugin unavailable")
 	// ErrMethodNotImplemented error returned when plugin method not implemented.
 	ErrMethodNotImplemented = errors.New("method not implemented")
+	// ErrPluginNotManaged error returned when plugin is not managed by Grafana.
+	ErrPluginNotManaged = errors.New("plugin not managed")
 )
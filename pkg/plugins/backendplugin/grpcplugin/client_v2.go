@@ -24,6 +24,7 @@ type clientV2 struct {
 	grpcplugin.DataClient
 	grpcplugin.StreamClient
 	pluginextensionv2.RendererPlugin
+	pluginextensionv2.SecretsManagerPlugin
 }
 
 func newClientV2(descriptor PluginDescriptor, logger log.Logger, rpcClient plugin.ClientProtocol) (pluginClient, error) {
@@ -52,6 +53,11 @@ func newClientV2(descriptor PluginDescriptor, logger log.Logger, rpcClient plugi
 		return nil, err
 	}
 
+	rawSecretsManager, err := rpcClient.Dispense("secretsmanager")
+	if err != nil {
+		return nil, err
+	}
+
 	c := clientV2{}
 	if rawDiagnostics != nil {
 		if diagnosticsClient, ok := rawDiagnostics.(grpcplugin.DiagnosticsClient); ok {
@@ -83,12 +89,24 @@ func newClientV2(descriptor PluginDescriptor, logger log.Logger, rpcClient plugi
 		}
 	}
 
+	if rawSecretsManager != nil {
+		if secretsManagerPlugin, ok := rawSecretsManager.(pluginextensionv2.SecretsManagerPlugin); ok {
+			c.SecretsManagerPlugin = secretsManagerPlugin
+		}
+	}
+
 	if descriptor.startRendererFn != nil {
 		if err := descriptor.startRendererFn(descriptor.pluginID, c.RendererPlugin, logger); err != nil {
 			return nil, err
 		}
 	}
 
+	if descriptor.startSecretsManagerFn != nil {
+		if err := descriptor.startSecretsManagerFn(descriptor.pluginID, c.SecretsManagerPlugin, logger); err != nil {
+			return nil, err
+		}
+	}
+
 	return &c, nil
 }
 
@@ -22,9 +22,11 @@ type pluginClient interface {
 
 type grpcPlugin struct {
 	descriptor     PluginDescriptor
-	clientFactory  func() *plugin.Client
+	clientFactory  func() (*plugin.Client, bool)
 	client         *plugin.Client
+	rpcClient      plugin.ClientProtocol
 	pluginClient   pluginClient
+	standalone     bool
 	logger         log.Logger
 	mutex          sync.RWMutex
 	decommissioned bool
@@ -36,8 +38,18 @@ func newPlugin(descriptor PluginDescriptor) backendplugin.PluginFactoryFunc {
 		return &grpcPlugin{
 			descriptor: descriptor,
 			logger:     logger,
-			clientFactory: func() *plugin.Client {
-				return plugin.NewClient(newClientConfig(descriptor.executablePath, env, logger, descriptor.versionedPlugins))
+			clientFactory: func() (*plugin.Client, bool) {
+				if descriptor.standaloneDir != "" {
+					reattach, err := standaloneReattachConfig(descriptor.standaloneDir)
+					if err != nil {
+						logger.Warn("Failed to attach to standalone plugin process, starting the plugin executable",
+							"error", err)
+					} else if reattach != nil {
+						logger.Info("Attaching to standalone plugin process", "address", reattach.Addr, "pid", reattach.Pid)
+						return plugin.NewClient(newStandaloneClientConfig(reattach, logger, descriptor.versionedPlugins)), true
+					}
+				}
+				return plugin.NewClient(newClientConfig(descriptor.executablePath, env, logger, descriptor.versionedPlugins)), false
 			},
 		}, nil
 	}
@@ -55,11 +67,12 @@ func (p *grpcPlugin) Start(ctx context.Context) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.client = p.clientFactory()
+	p.client, p.standalone = p.clientFactory()
 	rpcClient, err := p.client.Client()
 	if err != nil {
 		return err
 	}
+	p.rpcClient = rpcClient
 
 	if p.client.NegotiatedVersion() < 2 {
 		return errors.New("plugin protocol version not supported")
@@ -91,6 +104,10 @@ func (p *grpcPlugin) Stop(ctx context.Context) error {
 	if p.client != nil {
 		p.client.Kill()
 	}
+	// the standalone process isn't killed, only the connection to it is closed
+	if p.standalone && p.rpcClient != nil {
+		return p.rpcClient.Close()
+	}
 	return nil
 }
 
@@ -98,6 +115,13 @@ func (p *grpcPlugin) IsManaged() bool {
 	return p.descriptor.managed
 }
 
+// IsStandalone returns whether the plugin is attached to a process started outside of Grafana.
+func (p *grpcPlugin) IsStandalone() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.standalone
+}
+
 func (p *grpcPlugin) Exited() bool {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
@@ -107,6 +131,21 @@ func (p *grpcPlugin) Exited() bool {
 	return true
 }
 
+// PID returns the ID of the plugin process, and false if the process isn't running.
+func (p *grpcPlugin) PID() (int, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.client == nil || p.client.Exited() {
+		return 0, false
+	}
+	reattach := p.client.ReattachConfig()
+	if reattach == nil || reattach.Pid == 0 {
+		return 0, false
+	}
+	return reattach.Pid, true
+}
+
 func (p *grpcPlugin) Decommission() error {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
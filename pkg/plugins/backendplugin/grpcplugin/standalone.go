@@ -0,0 +1,93 @@
+package grpcplugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/grpcplugin"
+	"github.com/grafana/grafana/pkg/infra/log"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+const (
+	// standaloneAddressFile is the discovery file, in the directory of the plugin executable, of the address of a
+	// plugin process started outside of Grafana, e.g. under a debugger.
+	standaloneAddressFile = "standalone.txt"
+	// standalonePIDFile is the discovery file of the ID of the standalone plugin process.
+	standalonePIDFile = "pid.txt"
+)
+
+// standaloneReattachConfig returns the configuration attaching to the standalone plugin process advertised by the
+// discovery files of the directory, or nil if there are none.
+func standaloneReattachConfig(dir string) (*goplugin.ReattachConfig, error) {
+	// We can ignore gosec G304 here, since the directory is the one of a development plugin
+	// nolint:gosec
+	rawAddr, err := os.ReadFile(filepath.Join(dir, standaloneAddressFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	addr, err := parseStandaloneAddress(strings.TrimSpace(string(rawAddr)))
+	if err != nil {
+		return nil, err
+	}
+
+	// the process ID is required, since go-plugin watches the process to detect when it exits
+	// nolint:gosec
+	rawPID, err := os.ReadFile(filepath.Join(dir, standalonePIDFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the process ID of the standalone plugin: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(rawPID)))
+	if err != nil || pid <= 0 {
+		return nil, fmt.Errorf("invalid process ID %q of the standalone plugin", strings.TrimSpace(string(rawPID)))
+	}
+
+	// go-plugin kills the advertised process when it can't connect to it, and the discovery files outlive the
+	// process, so make sure the address isn't stale
+	conn, err := net.DialTimeout(addr.Network(), addr.String(), time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("standalone plugin isn't listening on %s: %w", addr, err)
+	}
+	_ = conn.Close()
+
+	return &goplugin.ReattachConfig{
+		Protocol:        goplugin.ProtocolGRPC,
+		ProtocolVersion: grpcplugin.ProtocolVersion,
+		Addr:            addr,
+		Pid:             pid,
+		// the process is left running when the plugin is stopped, its lifecycle is up to the plugin developer
+		Test: true,
+	}, nil
+}
+
+// parseStandaloneAddress parses a TCP address, e.g. 127.0.0.1:50051, or the path of a Unix socket.
+func parseStandaloneAddress(addr string) (net.Addr, error) {
+	if filepath.IsAbs(addr) {
+		return net.ResolveUnixAddr("unix", addr)
+	}
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q of the standalone plugin: %w", addr, err)
+	}
+	return tcpAddr, nil
+}
+
+func newStandaloneClientConfig(reattach *goplugin.ReattachConfig, logger log.Logger,
+	versionedPlugins map[int]goplugin.PluginSet) *goplugin.ClientConfig {
+	return &goplugin.ClientConfig{
+		Reattach:         reattach,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: versionedPlugins,
+		Logger:           logWrapper{Logger: logger},
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+	}
+}
@@ -0,0 +1,66 @@
+package grpcplugin
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandaloneReattachConfig(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	writeFiles := func(t *testing.T, addr, pid string) string {
+		t.Helper()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, standaloneAddressFile), []byte(addr+"\n"), 0600))
+		if pid != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, standalonePIDFile), []byte(pid+"\n"), 0600))
+		}
+		return dir
+	}
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("Should attach to the advertised process", func(t *testing.T) {
+		reattach, err := standaloneReattachConfig(writeFiles(t, listener.Addr().String(), pid))
+		require.NoError(t, err)
+		require.NotNil(t, reattach)
+		require.Equal(t, goplugin.ProtocolGRPC, reattach.Protocol)
+		require.Equal(t, listener.Addr().String(), reattach.Addr.String())
+		require.Equal(t, os.Getpid(), reattach.Pid)
+		require.True(t, reattach.Test)
+	})
+
+	t.Run("Should start the executable without discovery files", func(t *testing.T) {
+		reattach, err := standaloneReattachConfig(t.TempDir())
+		require.NoError(t, err)
+		require.Nil(t, reattach)
+	})
+
+	t.Run("Should reject invalid discovery files", func(t *testing.T) {
+		_, err := standaloneReattachConfig(writeFiles(t, listener.Addr().String(), ""))
+		require.Error(t, err)
+
+		_, err = standaloneReattachConfig(writeFiles(t, listener.Addr().String(), "0"))
+		require.Error(t, err)
+
+		_, err = standaloneReattachConfig(writeFiles(t, "not an address", pid))
+		require.Error(t, err)
+	})
+
+	t.Run("Should reject stale addresses", func(t *testing.T) {
+		stale, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := stale.Addr().String()
+		require.NoError(t, stale.Close())
+
+		_, err = standaloneReattachConfig(writeFiles(t, addr, pid))
+		require.Error(t, err)
+	})
+}
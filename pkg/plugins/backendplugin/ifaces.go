@@ -2,6 +2,7 @@ package backendplugin
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -32,6 +33,103 @@ type Manager interface {
 	Get(pluginID string) (Plugin, bool)
 }
 
+// FailureReporter reports the failures of the backend plugins.
+type FailureReporter interface {
+	// PluginFailures returns the failures of each registered backend plugin since Grafana started.
+	PluginFailures() map[string]PluginFailures
+}
+
+// PluginFailures are the numbers of failures of a backend plugin.
+type PluginFailures struct {
+	// Restarts is the number of times the plugin process has been restarted after it exited.
+	Restarts int64
+	// Unavailable is the number of requests that failed because the plugin was unavailable.
+	Unavailable int64
+	// HealthCheckFailures is the number of failed health checks, or of health checks reporting an error.
+	HealthCheckFailures int64
+}
+
+// StatusReporter reports the status of the backend plugins.
+type StatusReporter interface {
+	// PluginStatuses returns the status of each registered backend plugin.
+	PluginStatuses() map[string]PluginStatus
+}
+
+// ReadinessReporter reports whether the backend plugins are ready to serve requests.
+type ReadinessReporter interface {
+	// PluginsRegistered returns whether the core and installed backend plugins have been registered.
+	PluginsRegistered() bool
+}
+
+// PluginStatus is the status of a backend plugin.
+type PluginStatus struct {
+	// Managed is whether the plugin process is managed by Grafana.
+	Managed bool
+	// Running is whether the plugin process is running.
+	Running bool
+	// Failures are the failures of the plugin since Grafana started.
+	Failures PluginFailures
+	// DataSources are the results of the last health check of the data sources of the plugin, by data source UID.
+	DataSources map[string]DataSourceHealth
+}
+
+// DataSourceHealth is the result of the last health check of a data source.
+type DataSourceHealth struct {
+	Name      string
+	Status    backend.HealthStatus
+	Message   string
+	CheckedAt time.Time
+}
+
+// Restarter restarts the process of backend plugins.
+type Restarter interface {
+	// RestartPlugin stops the process of a managed backend plugin and starts a new one.
+	RestartPlugin(ctx context.Context, pluginID string) error
+}
+
+// Upgrader upgrades backend plugins without interrupting the requests to them.
+type Upgrader interface {
+	// UpgradePlugin starts the backend plugin created by the factory alongside the registered one, and replaces the
+	// registered one once the new one is healthy. The registered one is kept if the new one can't be started or
+	// isn't healthy.
+	UpgradePlugin(ctx context.Context, pluginID string, factory PluginFactoryFunc) error
+}
+
+// CanaryRegistrar registers canary versions of backend plugins.
+type CanaryRegistrar interface {
+	// RegisterCanary registers and starts the backend of the canary version of a registered plugin, which serves the
+	// requests of the organizations routed to it.
+	RegisterCanary(ctx context.Context, pluginID string, factory PluginFactoryFunc) error
+}
+
+// ConfigInspector inspects the configuration of the backend plugins.
+type ConfigInspector interface {
+	// PluginConfig returns the configuration the backend plugin received, with the secret values masked.
+	PluginConfig(pluginID string) (PluginConfig, error)
+}
+
+// PluginConfig is the configuration a backend plugin received.
+type PluginConfig struct {
+	// Env are the environment variables of the plugin process, by name.
+	Env map[string]string
+	// Settings are the settings of the [plugin.<plugin id>] section of the configuration.
+	Settings map[string]string
+}
+
+// ProcessPlugin is implemented by the backend plugins running in their own process.
+type ProcessPlugin interface {
+	// PID returns the ID of the plugin process, and false if the process isn't running.
+	PID() (int, bool)
+}
+
+// StandalonePlugin is implemented by the backend plugins which can attach to a plugin process started outside of
+// Grafana, e.g. under a debugger.
+type StandalonePlugin interface {
+	// IsStandalone returns whether the plugin is attached to a standalone process, which is neither restarted nor
+	// killed by Grafana.
+	IsStandalone() bool
+}
+
 // Plugin is the backend plugin interface.
 type Plugin interface {
 	PluginID() string
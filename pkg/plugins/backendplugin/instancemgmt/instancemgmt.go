@@ -0,0 +1,327 @@
+// Package instancemgmt manages the configured instances of the backend plugins running in Grafana: an instance per
+// organization for app plugins, and per data source for data source plugins.
+package instancemgmt
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// evictionInterval is how often the idle instances are evicted.
+const evictionInterval = time.Minute
+
+var timeNow = time.Now
+
+// Instance is a configured instance of a backend plugin.
+type Instance interface{}
+
+// InstanceDisposer is implemented by the instances holding resources, such as connections, to release when they're
+// disposed: when their settings change, when their data source is deleted, or when they're evicted.
+type InstanceDisposer interface {
+	Dispose()
+}
+
+// InstanceFactoryFunc creates an instance of a backend plugin configured with the settings of the plugin context.
+type InstanceFactoryFunc func(pluginContext backend.PluginContext) (Instance, error)
+
+// InstanceKey identifies the instance of a plugin for an organization, or for a data source of an organization.
+// DataSourceID is 0 for app plugins.
+type InstanceKey struct {
+	PluginID     string
+	OrgID        int64
+	DataSourceID int64
+}
+
+func (k InstanceKey) String() string {
+	if k.DataSourceID == 0 {
+		return fmt.Sprintf("%s/org/%d", k.PluginID, k.OrgID)
+	}
+	return fmt.Sprintf("%s/org/%d/datasource/%d", k.PluginID, k.OrgID, k.DataSourceID)
+}
+
+// NewInstanceKey returns the key of the instance of the plugin context.
+func NewInstanceKey(pluginContext backend.PluginContext) InstanceKey {
+	key := InstanceKey{PluginID: pluginContext.PluginID, OrgID: pluginContext.OrgID}
+	if ds := pluginContext.DataSourceInstanceSettings; ds != nil {
+		key.DataSourceID = ds.ID
+	}
+	return key
+}
+
+// settingsUpdated returns when the settings of the plugin context were last updated.
+func settingsUpdated(pluginContext backend.PluginContext) time.Time {
+	if ds := pluginContext.DataSourceInstanceSettings; ds != nil {
+		return ds.Updated
+	}
+	if app := pluginContext.AppInstanceSettings; app != nil {
+		return app.Updated
+	}
+	return time.Time{}
+}
+
+type cachedInstance struct {
+	// the mutex of the instance is held while it's created, so that concurrent requests of an organization or a
+	// data source create a single instance without blocking the requests of the others.
+	sync.Mutex
+	instance Instance
+	updated  time.Time
+	lastUsed time.Time
+	// removed is set when the instance is removed from the cache, for the requests which got it before.
+	removed bool
+}
+
+// InstanceInfo describes a cached instance.
+type InstanceInfo struct {
+	Key      InstanceKey
+	Updated  time.Time
+	LastUsed time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, bus bus.Bus) *Manager {
+	m := newManager(cfg)
+
+	bus.AddEventListener(m.handlePluginSettingUpdated)
+	bus.AddEventListener(m.handlePluginStateChanged)
+	bus.AddEventListener(m.handleDataSourceDeleted)
+
+	return m
+}
+
+func newManager(cfg *setting.Cfg) *Manager {
+	return &Manager{
+		idleTimeout:   cfg.PluginInstanceIdleTimeout,
+		heapThreshold: uint64(cfg.PluginInstanceEvictionHeapSizeMB) << 20,
+		heapSize:      heapSize,
+		factories:     map[string]InstanceFactoryFunc{},
+		instances:     map[InstanceKey]*cachedInstance{},
+		logger:        log.New("plugins.instancemgmt"),
+	}
+}
+
+// Manager creates the instances of the registered plugins on first use, and caches them until their settings
+// change. Idle instances are evicted, only when the heap exceeds a threshold if one is configured.
+type Manager struct {
+	idleTimeout   time.Duration
+	heapThreshold uint64
+	heapSize      func() uint64
+
+	factoriesMu sync.RWMutex
+	factories   map[string]InstanceFactoryFunc
+	mu          sync.Mutex
+	instances   map[InstanceKey]*cachedInstance
+	logger      log.Logger
+}
+
+// Register registers the factory creating the instances of the plugin.
+func (m *Manager) Register(pluginID string, factory InstanceFactoryFunc) error {
+	m.factoriesMu.Lock()
+	defer m.factoriesMu.Unlock()
+
+	if _, exists := m.factories[pluginID]; exists {
+		return fmt.Errorf("instance factory of plugin %s already registered", pluginID)
+	}
+	m.factories[pluginID] = factory
+	return nil
+}
+
+// Unregister unregisters the factory of the plugin, and disposes the instances of the plugin.
+func (m *Manager) Unregister(pluginID string) {
+	m.factoriesMu.Lock()
+	delete(m.factories, pluginID)
+	m.factoriesMu.Unlock()
+
+	m.disposeMatching(func(key InstanceKey) bool { return key.PluginID == pluginID })
+}
+
+// Get returns the instance of the plugin context. A new instance is created if there's none, or if the settings of
+// the plugin context have been updated since the cached one was created, in which case the cached one is disposed.
+func (m *Manager) Get(pluginContext backend.PluginContext) (Instance, error) {
+	m.factoriesMu.RLock()
+	factory, ok := m.factories[pluginContext.PluginID]
+	m.factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no instance factory registered for plugin %s", pluginContext.PluginID)
+	}
+
+	key := NewInstanceKey(pluginContext)
+	updated := settingsUpdated(pluginContext)
+
+	var ci *cachedInstance
+	for {
+		m.mu.Lock()
+		ci, ok = m.instances[key]
+		if !ok {
+			ci = &cachedInstance{}
+			m.instances[key] = ci
+		}
+		m.mu.Unlock()
+
+		ci.Lock()
+		if !ci.removed {
+			break
+		}
+		ci.Unlock()
+	}
+	defer ci.Unlock()
+
+	ci.lastUsed = timeNow()
+	if ci.instance != nil && ci.updated.Equal(updated) {
+		return ci.instance, nil
+	}
+
+	if ci.instance != nil {
+		m.logger.Debug("Settings updated, disposing instance", "instance", key)
+		dispose(ci.instance)
+		ci.instance = nil
+	}
+
+	instance, err := factory(pluginContext)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Debug("Created instance", "instance", key)
+	ci.instance = instance
+	ci.updated = updated
+	return instance, nil
+}
+
+// Instances returns the cached instances, sorted by key.
+func (m *Manager) Instances() []InstanceInfo {
+	entries := m.entries()
+	infos := make([]InstanceInfo, 0, len(entries))
+	for key, ci := range entries {
+		ci.Lock()
+		if ci.instance != nil && !ci.removed {
+			infos = append(infos, InstanceInfo{Key: key, Updated: ci.updated, LastUsed: ci.lastUsed})
+		}
+		ci.Unlock()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key.String() < infos[j].Key.String() })
+	return infos
+}
+
+// Run evicts the idle instances periodically, and disposes all the instances when Grafana stops.
+func (m *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(evictionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.evictIdle()
+		case <-ctx.Done():
+			m.disposeMatching(func(InstanceKey) bool { return true })
+			return ctx.Err()
+		}
+	}
+}
+
+// evictIdle disposes the instances which haven't been used for the idle timeout, if the heap exceeds the threshold
+// or if there's none.
+func (m *Manager) evictIdle() {
+	if m.idleTimeout <= 0 {
+		return
+	}
+	if m.heapThreshold > 0 {
+		size := m.heapSize()
+		if size < m.heapThreshold {
+			return
+		}
+		m.logger.Debug("Heap above threshold, evicting idle instances", "heapSize", size, "threshold", m.heapThreshold)
+	}
+
+	idleSince := timeNow().Add(-m.idleTimeout)
+	evicted := m.disposeMatchingInstances(func(key InstanceKey, ci *cachedInstance) bool {
+		return ci.lastUsed.Before(idleSince)
+	})
+	if evicted > 0 {
+		m.logger.Info("Evicted idle plugin instances", "count", evicted)
+	}
+}
+
+func (m *Manager) disposeMatching(match func(key InstanceKey) bool) int {
+	return m.disposeMatchingInstances(func(key InstanceKey, _ *cachedInstance) bool { return match(key) })
+}
+
+// disposeMatchingInstances removes the matching instances from the cache and disposes them, once they're created
+// if they're being created.
+func (m *Manager) disposeMatchingInstances(match func(key InstanceKey, ci *cachedInstance) bool) int {
+	disposed := 0
+	for key, ci := range m.entries() {
+		ci.Lock()
+		if !ci.removed && match(key, ci) {
+			ci.removed = true
+			m.mu.Lock()
+			delete(m.instances, key)
+			m.mu.Unlock()
+
+			if ci.instance != nil {
+				m.logger.Debug("Disposing instance", "instance", key)
+				dispose(ci.instance)
+				ci.instance = nil
+				disposed++
+			}
+		}
+		ci.Unlock()
+	}
+	return disposed
+}
+
+// entries returns a copy of the cache, to lock the instances one by one without holding the lock of the cache.
+func (m *Manager) entries() map[InstanceKey]*cachedInstance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make(map[InstanceKey]*cachedInstance, len(m.instances))
+	for key, ci := range m.instances {
+		entries[key] = ci
+	}
+	return entries
+}
+
+func (m *Manager) handlePluginSettingUpdated(event *models.PluginSettingUpdatedEvent) error {
+	m.disposeMatching(func(key InstanceKey) bool {
+		return key.PluginID == event.PluginId && key.OrgID == event.OrgId && key.DataSourceID == 0
+	})
+	return nil
+}
+
+func (m *Manager) handlePluginStateChanged(event *models.PluginStateChangedEvent) error {
+	if event.Enabled {
+		return nil
+	}
+	m.disposeMatching(func(key InstanceKey) bool {
+		return key.PluginID == event.PluginId && key.OrgID == event.OrgId
+	})
+	return nil
+}
+
+func (m *Manager) handleDataSourceDeleted(event *events.DataSourceDeleted) error {
+	m.disposeMatching(func(key InstanceKey) bool {
+		return key.OrgID == event.OrgID && key.DataSourceID == event.ID
+	})
+	return nil
+}
+
+func dispose(instance Instance) {
+	if disposer, ok := instance.(InstanceDisposer); ok {
+		disposer.Dispose()
+	}
+}
+
+func heapSize() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
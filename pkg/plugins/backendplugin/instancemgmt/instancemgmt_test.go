@@ -0,0 +1,267 @@
+package instancemgmt
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstance struct {
+	pluginContext backend.PluginContext
+	disposed      bool
+}
+
+func (i *testInstance) Dispose() {
+	i.disposed = true
+}
+
+func newTestManager(t *testing.T, cfg *setting.Cfg) (*Manager, *int) {
+	t.Helper()
+
+	m := newManager(cfg)
+	created := 0
+	err := m.Register("test-app", func(pluginContext backend.PluginContext) (Instance, error) {
+		created++
+		return &testInstance{pluginContext: pluginContext}, nil
+	})
+	require.NoError(t, err)
+	err = m.Register("test-datasource", func(pluginContext backend.PluginContext) (Instance, error) {
+		created++
+		return &testInstance{pluginContext: pluginContext}, nil
+	})
+	require.NoError(t, err)
+	return m, &created
+}
+
+func appContext(orgID int64, updated time.Time) backend.PluginContext {
+	return backend.PluginContext{
+		PluginID:            "test-app",
+		OrgID:               orgID,
+		AppInstanceSettings: &backend.AppInstanceSettings{Updated: updated},
+	}
+}
+
+func dataSourceContext(orgID, dsID int64, updated time.Time) backend.PluginContext {
+	return backend.PluginContext{
+		PluginID:                   "test-datasource",
+		OrgID:                      orgID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: dsID, Updated: updated},
+	}
+}
+
+func TestManager(t *testing.T) {
+	updated := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Should create an instance per organization for app plugins", func(t *testing.T) {
+		m, created := newTestManager(t, &setting.Cfg{})
+
+		first, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+		again, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+		other, err := m.Get(appContext(2, updated))
+		require.NoError(t, err)
+
+		require.Same(t, first, again)
+		require.NotSame(t, first, other)
+		require.Equal(t, 2, *created)
+		require.Equal(t, int64(2), other.(*testInstance).pluginContext.OrgID)
+	})
+
+	t.Run("Should create an instance per data source for data source plugins", func(t *testing.T) {
+		m, created := newTestManager(t, &setting.Cfg{})
+
+		first, err := m.Get(dataSourceContext(1, 1, updated))
+		require.NoError(t, err)
+		other, err := m.Get(dataSourceContext(1, 2, updated))
+		require.NoError(t, err)
+
+		require.NotSame(t, first, other)
+		require.Equal(t, 2, *created)
+		instances := m.Instances()
+		require.Len(t, instances, 2)
+		require.Equal(t, InstanceKey{PluginID: "test-datasource", OrgID: 1, DataSourceID: 1}, instances[0].Key)
+		require.Equal(t, InstanceKey{PluginID: "test-datasource", OrgID: 1, DataSourceID: 2}, instances[1].Key)
+		require.Equal(t, updated, instances[0].Updated)
+	})
+
+	t.Run("Should dispose the instance and create a new one when the settings are updated", func(t *testing.T) {
+		m, created := newTestManager(t, &setting.Cfg{})
+
+		first, err := m.Get(dataSourceContext(1, 1, updated))
+		require.NoError(t, err)
+		updatedInstance, err := m.Get(dataSourceContext(1, 1, updated.Add(time.Minute)))
+		require.NoError(t, err)
+
+		require.NotSame(t, first, updatedInstance)
+		require.True(t, first.(*testInstance).disposed)
+		require.False(t, updatedInstance.(*testInstance).disposed)
+		require.Equal(t, 2, *created)
+	})
+
+	t.Run("Should return an error for a plugin without a factory", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+
+		_, err := m.Get(backend.PluginContext{PluginID: "unknown", OrgID: 1})
+		require.Error(t, err)
+		require.Error(t, m.Register("test-app", nil))
+	})
+
+	t.Run("Should not cache the instance if the factory fails", func(t *testing.T) {
+		m := newManager(&setting.Cfg{})
+		calls := 0
+		err := m.Register("test-app", func(pluginContext backend.PluginContext) (Instance, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("failed")
+			}
+			return &testInstance{}, nil
+		})
+		require.NoError(t, err)
+
+		_, err = m.Get(appContext(1, updated))
+		require.Error(t, err)
+		instance, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+		require.NotNil(t, instance)
+	})
+
+	t.Run("Should create a single instance for concurrent requests", func(t *testing.T) {
+		m, created := newTestManager(t, &setting.Cfg{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := m.Get(appContext(1, updated))
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, 1, *created)
+	})
+
+	t.Run("Should dispose the instances of an organization when the app settings are updated", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+
+		org1, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+		org2, err := m.Get(appContext(2, updated))
+		require.NoError(t, err)
+
+		err = m.handlePluginSettingUpdated(&models.PluginSettingUpdatedEvent{PluginId: "test-app", OrgId: 1})
+		require.NoError(t, err)
+
+		require.True(t, org1.(*testInstance).disposed)
+		require.False(t, org2.(*testInstance).disposed)
+		require.Len(t, m.Instances(), 1)
+	})
+
+	t.Run("Should dispose the instances of a disabled plugin", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+
+		instance, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+
+		err = m.handlePluginStateChanged(&models.PluginStateChangedEvent{PluginId: "test-app", OrgId: 1, Enabled: true})
+		require.NoError(t, err)
+		require.False(t, instance.(*testInstance).disposed)
+
+		err = m.handlePluginStateChanged(&models.PluginStateChangedEvent{PluginId: "test-app", OrgId: 1, Enabled: false})
+		require.NoError(t, err)
+		require.True(t, instance.(*testInstance).disposed)
+	})
+
+	t.Run("Should dispose the instance of a deleted data source", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+
+		deleted, err := m.Get(dataSourceContext(1, 1, updated))
+		require.NoError(t, err)
+		other, err := m.Get(dataSourceContext(1, 2, updated))
+		require.NoError(t, err)
+
+		err = m.handleDataSourceDeleted(&events.DataSourceDeleted{ID: 1, OrgID: 1})
+		require.NoError(t, err)
+
+		require.True(t, deleted.(*testInstance).disposed)
+		require.False(t, other.(*testInstance).disposed)
+	})
+
+	t.Run("Should dispose the instances of an unregistered plugin", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+
+		instance, err := m.Get(appContext(1, updated))
+		require.NoError(t, err)
+
+		m.Unregister("test-app")
+
+		require.True(t, instance.(*testInstance).disposed)
+		_, err = m.Get(appContext(1, updated))
+		require.Error(t, err)
+	})
+}
+
+func TestManager_evictIdle(t *testing.T) {
+	t.Cleanup(func() { timeNow = time.Now })
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	getInstances := func(t *testing.T, m *Manager) (*testInstance, *testInstance) {
+		t.Helper()
+
+		idle, err := m.Get(appContext(1, time.Time{}))
+		require.NoError(t, err)
+		now = now.Add(20 * time.Minute)
+		used, err := m.Get(appContext(2, time.Time{}))
+		require.NoError(t, err)
+		now = now.Add(20 * time.Minute)
+		return idle.(*testInstance), used.(*testInstance)
+	}
+
+	t.Run("Should evict the instances unused for the idle timeout", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{PluginInstanceIdleTimeout: 30 * time.Minute})
+		idle, used := getInstances(t, m)
+
+		m.evictIdle()
+
+		require.True(t, idle.disposed)
+		require.False(t, used.disposed)
+		require.Len(t, m.Instances(), 1)
+	})
+
+	t.Run("Should not evict the idle instances if the heap is below the threshold", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{
+			PluginInstanceIdleTimeout:        30 * time.Minute,
+			PluginInstanceEvictionHeapSizeMB: 512,
+		})
+		heap := uint64(256 << 20)
+		m.heapSize = func() uint64 { return heap }
+		idle, used := getInstances(t, m)
+
+		m.evictIdle()
+		require.False(t, idle.disposed)
+
+		heap = 1024 << 20
+		m.evictIdle()
+		require.True(t, idle.disposed)
+		require.False(t, used.disposed)
+	})
+
+	t.Run("Should not evict the instances without an idle timeout", func(t *testing.T) {
+		m, _ := newTestManager(t, &setting.Cfg{})
+		idle, _ := getInstances(t, m)
+
+		m.evictIdle()
+
+		require.False(t, idle.disposed)
+	})
+}
@@ -3,6 +3,8 @@ package instrumentation
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -12,6 +14,19 @@ import (
 var (
 	pluginRequestCounter  *prometheus.CounterVec
 	pluginRequestDuration *prometheus.SummaryVec
+
+	dataSourceRequestCounter  *prometheus.CounterVec
+	dataSourceRequestDuration *prometheus.SummaryVec
+)
+
+// otherDataSourceLabel is the data source label of the requests to the data sources beyond the label limit.
+const otherDataSourceLabel = "other"
+
+var (
+	// DefaultDurationBuckets are the default buckets, in seconds, of the plugin request duration histogram.
+	DefaultDurationBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+	// DurationLabels are the labels the plugin request duration histogram can have, all of them by default.
+	DurationLabels = []string{"plugin_id", "endpoint", "status"}
 )
 
 func init() {
@@ -28,11 +43,165 @@ func init() {
 		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 	}, []string{"plugin_id", "endpoint"})
 
-	prometheus.MustRegister(pluginRequestCounter, pluginRequestDuration)
+	dataSourceRequestCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_datasource_request_total",
+		Help:      "The total amount of plugin requests by data source",
+	}, []string{"plugin_id", "datasource_uid", "endpoint", "status"})
+
+	dataSourceRequestDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  "grafana",
+		Name:       "plugin_datasource_request_duration_milliseconds",
+		Help:       "Plugin request duration by data source",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, []string{"plugin_id", "datasource_uid", "endpoint"})
+
+	prometheus.MustRegister(pluginRequestCounter, pluginRequestDuration, dataSourceRequestCounter, dataSourceRequestDuration)
+
+	durationHistogram = newDurationHistogram(DefaultDurationBuckets, DurationLabels)
+	prometheus.MustRegister(durationHistogram.vec)
+}
+
+// requestDurationHistogram is the plugin request duration histogram, with the buckets and labels of the configuration.
+type requestDurationHistogram struct {
+	vec    *prometheus.HistogramVec
+	labels []string
+}
+
+var (
+	durationHistogramMu sync.RWMutex
+	durationHistogram   *requestDurationHistogram
+)
+
+func newDurationHistogram(buckets []float64, labels []string) *requestDurationHistogram {
+	return &requestDurationHistogram{
+		vec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Name:      "plugin_request_duration_seconds",
+			Help:      "Plugin request duration histogram",
+			Buckets:   buckets,
+		}, labels),
+		labels: labels,
+	}
+}
+
+// ConfigureDurationHistogram replaces the plugin request duration histogram with one with the buckets, in seconds,
+// and a subset of the DurationLabels, since the default buckets don't fit the plugins whose requests take less than a
+// millisecond or minutes. Empty buckets or labels keep the defaults.
+func ConfigureDurationHistogram(buckets []float64, labels []string) error {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	if len(labels) == 0 {
+		labels = DurationLabels
+	}
+	for i := range buckets {
+		if buckets[i] <= 0 || (i > 0 && buckets[i] <= buckets[i-1]) {
+			return fmt.Errorf("buckets must be positive and in increasing order: %v", buckets)
+		}
+	}
+	seen := map[string]bool{}
+	for _, label := range labels {
+		if !isDurationLabel(label) {
+			return fmt.Errorf("unknown label %q, expected one of %v", label, DurationLabels)
+		}
+		if seen[label] {
+			return fmt.Errorf("label %q is set twice", label)
+		}
+		seen[label] = true
+	}
+
+	durationHistogramMu.Lock()
+	defer durationHistogramMu.Unlock()
+	histogram := newDurationHistogram(buckets, labels)
+	prometheus.Unregister(durationHistogram.vec)
+	if err := prometheus.Register(histogram.vec); err != nil {
+		prometheus.MustRegister(durationHistogram.vec)
+		return err
+	}
+	durationHistogram = histogram
+	return nil
+}
+
+func isDurationLabel(label string) bool {
+	for _, l := range DurationLabels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func observeDuration(pluginID, endpoint, status string, elapsed time.Duration) {
+	durationHistogramMu.RLock()
+	histogram := durationHistogram
+	durationHistogramMu.RUnlock()
+
+	values := make([]string, 0, len(histogram.labels))
+	for _, label := range histogram.labels {
+		switch label {
+		case "plugin_id":
+			values = append(values, pluginID)
+		case "endpoint":
+			values = append(values, endpoint)
+		case "status":
+			values = append(values, status)
+		}
+	}
+	histogram.vec.WithLabelValues(values...).Observe(elapsed.Seconds())
+}
+
+// dataSourceLabels keeps the data source UIDs used as metric labels, up to the limit.
+type dataSourceLabels struct {
+	mu    sync.Mutex
+	limit int
+	uids  map[string]struct{}
+}
+
+// label returns the metric label of the data source, which is its UID unless the label limit is reached.
+func (l *dataSourceLabels) label(uid string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.uids[uid]; ok {
+		return uid
+	}
+	if len(l.uids) >= l.limit {
+		return otherDataSourceLabel
+	}
+	l.uids[uid] = struct{}{}
+	return uid
 }
 
-// instrumentPluginRequest instruments success rate and latency of `fn`
-func instrumentPluginRequest(pluginID string, endpoint string, fn func() error) error {
+var (
+	dsLabelsMu sync.RWMutex
+	dsLabels   *dataSourceLabels
+)
+
+// EnableDataSourceLabels enables the metrics of the plugin requests by data source, with at most limit distinct
+// data source UIDs as labels to bound their cardinality.
+func EnableDataSourceLabels(limit int) {
+	dsLabelsMu.Lock()
+	defer dsLabelsMu.Unlock()
+	dsLabels = &dataSourceLabels{limit: limit, uids: map[string]struct{}{}}
+}
+
+func dataSourceLabel(pCtx *backend.PluginContext) (string, bool) {
+	if pCtx == nil || pCtx.DataSourceInstanceSettings == nil || pCtx.DataSourceInstanceSettings.UID == "" {
+		return "", false
+	}
+
+	dsLabelsMu.RLock()
+	labels := dsLabels
+	dsLabelsMu.RUnlock()
+	if labels == nil {
+		return "", false
+	}
+	return labels.label(pCtx.DataSourceInstanceSettings.UID), true
+}
+
+// instrumentPluginRequest instruments success rate and latency of `fn`, by data source as well if enabled
+func instrumentPluginRequest(pluginID string, pCtx *backend.PluginContext, endpoint string, fn func() error) error {
 	status := "ok"
 
 	start := time.Now()
@@ -42,31 +211,39 @@ func instrumentPluginRequest(pluginID string, endpoint string, fn func() error)
 		status = "error"
 	}
 
-	elapsed := time.Since(start) / time.Millisecond
+	duration := time.Since(start)
+	observeDuration(pluginID, endpoint, status, duration)
+
+	elapsed := duration / time.Millisecond
 	pluginRequestDuration.WithLabelValues(pluginID, endpoint).Observe(float64(elapsed))
 	pluginRequestCounter.WithLabelValues(pluginID, endpoint, status).Inc()
 
+	if dsLabel, ok := dataSourceLabel(pCtx); ok {
+		dataSourceRequestDuration.WithLabelValues(pluginID, dsLabel, endpoint).Observe(float64(elapsed))
+		dataSourceRequestCounter.WithLabelValues(pluginID, dsLabel, endpoint, status).Inc()
+	}
+
 	return err
 }
 
 // InstrumentCollectMetrics instruments collectMetrics.
 func InstrumentCollectMetrics(pluginID string, fn func() error) error {
-	return instrumentPluginRequest(pluginID, "collectMetrics", fn)
+	return instrumentPluginRequest(pluginID, nil, "collectMetrics", fn)
 }
 
 // InstrumentCheckHealthRequest instruments checkHealth.
-func InstrumentCheckHealthRequest(pluginID string, fn func() error) error {
-	return instrumentPluginRequest(pluginID, "checkHealth", fn)
+func InstrumentCheckHealthRequest(pCtx backend.PluginContext, fn func() error) error {
+	return instrumentPluginRequest(pCtx.PluginID, &pCtx, "checkHealth", fn)
 }
 
 // InstrumentCallResourceRequest instruments callResource.
-func InstrumentCallResourceRequest(pluginID string, fn func() error) error {
-	return instrumentPluginRequest(pluginID, "callResource", fn)
+func InstrumentCallResourceRequest(pCtx backend.PluginContext, fn func() error) error {
+	return instrumentPluginRequest(pCtx.PluginID, &pCtx, "callResource", fn)
 }
 
 // InstrumentQueryDataRequest instruments success rate and latency of query data requests.
-func InstrumentQueryDataRequest(pluginID string, fn func() error) error {
-	return instrumentPluginRequest(pluginID, "queryData", fn)
+func InstrumentQueryDataRequest(pCtx backend.PluginContext, fn func() error) error {
+	return instrumentPluginRequest(pCtx.PluginID, &pCtx, "queryData", fn)
 }
 
 // InstrumentQueryDataHandler wraps a backend.QueryDataHandler with instrumentation of success rate and latency.
@@ -77,7 +254,7 @@ func InstrumentQueryDataHandler(handler backend.QueryDataHandler) backend.QueryD
 
 	return backend.QueryDataHandlerFunc(func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 		var resp *backend.QueryDataResponse
-		err := InstrumentQueryDataRequest(req.PluginContext.PluginID, func() (innerErr error) {
+		err := InstrumentQueryDataRequest(req.PluginContext, func() (innerErr error) {
 			resp, innerErr = handler.QueryData(ctx, req)
 			return
 		})
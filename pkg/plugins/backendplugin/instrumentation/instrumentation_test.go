@@ -0,0 +1,70 @@
+package instrumentation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceLabel(t *testing.T) {
+	pCtx := func(uid string) *backend.PluginContext {
+		return &backend.PluginContext{
+			PluginID:                   "postgres",
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: uid},
+		}
+	}
+
+	t.Run("Should not label by data source unless enabled", func(t *testing.T) {
+		_, ok := dataSourceLabel(pCtx("a"))
+		require.False(t, ok)
+	})
+
+	t.Run("Should label by data source up to the limit", func(t *testing.T) {
+		EnableDataSourceLabels(2)
+		t.Cleanup(func() { dsLabels = nil })
+
+		for _, tc := range []struct{ uid, expected string }{
+			{uid: "a", expected: "a"},
+			{uid: "b", expected: "b"},
+			{uid: "c", expected: otherDataSourceLabel},
+		} {
+			label, ok := dataSourceLabel(pCtx(tc.uid))
+			require.True(t, ok)
+			require.Equal(t, tc.expected, label)
+		}
+
+		label, ok := dataSourceLabel(pCtx("a"))
+		require.True(t, ok)
+		require.Equal(t, "a", label)
+
+		_, ok = dataSourceLabel(&backend.PluginContext{PluginID: "postgres"})
+		require.False(t, ok)
+	})
+}
+
+func TestConfigureDurationHistogram(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, ConfigureDurationHistogram(nil, nil))
+	})
+
+	t.Run("Should reject invalid buckets and labels", func(t *testing.T) {
+		require.Error(t, ConfigureDurationHistogram([]float64{1, 0.5}, nil))
+		require.Error(t, ConfigureDurationHistogram([]float64{0, 1}, nil))
+		require.Error(t, ConfigureDurationHistogram(nil, []string{"plugin_id", "datasource_uid"}))
+		require.Error(t, ConfigureDurationHistogram(nil, []string{"status", "status"}))
+	})
+
+	t.Run("Should observe the durations with the configured labels", func(t *testing.T) {
+		require.NoError(t, ConfigureDurationHistogram([]float64{0.0001, 0.001}, []string{"endpoint", "status"}))
+
+		pCtx := backend.PluginContext{PluginID: "test"}
+		require.NoError(t, InstrumentQueryDataRequest(pCtx, func() error { return nil }))
+		require.Error(t, InstrumentQueryDataRequest(pCtx, func() error { return errors.New("failed") }))
+		require.NoError(t, InstrumentCheckHealthRequest(pCtx, func() error { return nil }))
+
+		require.Equal(t, 3, testutil.CollectAndCount(durationHistogram.vec))
+	})
+}
@@ -0,0 +1,89 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// Keys of the plugin settings, i.e. of the [plugin.<plugin id>] section of the configuration, routing the requests
+// of organizations to the canary version of the plugin: the requests of the listed organizations, and a percentage
+// of the requests of the other ones.
+const (
+	canaryOrgIDsSetting     = "canary_org_ids"
+	canaryPercentageSetting = "canary_percentage"
+)
+
+var _ backendplugin.CanaryRegistrar = &Manager{}
+
+// RegisterCanary registers and starts the backend of the canary version of a plugin. The requests are routed to it
+// according to the canary settings of the plugin, as long as the plugin itself is registered.
+func (m *Manager) RegisterCanary(ctx context.Context, pluginID string, factory backendplugin.PluginFactoryFunc) error {
+	m.logger.Debug("Registering canary backend plugin", "pluginId", pluginID)
+	m.pluginsMu.Lock()
+	if _, exists := m.canaries[pluginID]; exists {
+		m.pluginsMu.Unlock()
+		return fmt.Errorf("canary backend plugin %s already registered", pluginID)
+	}
+
+	p, err := factory(pluginID, m.logger.New("pluginId", pluginID, "canary", true), m.getPluginEnvVars(pluginID))
+	if err != nil {
+		m.pluginsMu.Unlock()
+		return err
+	}
+	if m.canaries == nil {
+		m.canaries = map[string]backendplugin.Plugin{}
+	}
+	m.canaries[pluginID] = p
+	m.pluginsMu.Unlock()
+
+	m.start(ctx, p)
+	m.logger.Debug("Canary backend plugin registered", "pluginId", pluginID)
+	return nil
+}
+
+// getForOrg returns the backend plugin serving the requests of the organization: the canary version of the plugin if
+// the request is routed to it, the plugin otherwise.
+func (m *Manager) getForOrg(pluginID string, orgID int64) (backendplugin.Plugin, bool) {
+	p, registered := m.Get(pluginID)
+	if !registered {
+		return nil, false
+	}
+
+	m.pluginsMu.RLock()
+	canary, exists := m.canaries[pluginID]
+	m.pluginsMu.RUnlock()
+	if !exists || canary.IsDecommissioned() || !m.routedToCanary(pluginID, orgID) {
+		return p, true
+	}
+	return canary, true
+}
+
+// routedToCanary returns whether the request of the organization is routed to the canary version of the plugin.
+func (m *Manager) routedToCanary(pluginID string, orgID int64) bool {
+	if m.Cfg == nil {
+		return false
+	}
+	settings := m.Cfg.PluginSettings[pluginID]
+
+	for _, id := range strings.Split(settings[canaryOrgIDsSetting], ",") {
+		if parsed, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err == nil && parsed == orgID {
+			return true
+		}
+	}
+
+	value, ok := settings[canaryPercentageSetting]
+	if !ok {
+		return false
+	}
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		m.logger.Warn("Invalid canary percentage", "pluginId", pluginID, "value", value)
+		return false
+	}
+	return rand.Float64()*100 < percentage
+}
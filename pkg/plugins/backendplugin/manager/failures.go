@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+var _ backendplugin.FailureReporter = &Manager{}
+
+// PluginFailures returns the failures of each registered backend plugin since Grafana started.
+func (m *Manager) PluginFailures() map[string]backendplugin.PluginFailures {
+	m.failuresMu.Lock()
+	defer m.failuresMu.Unlock()
+
+	failures := make(map[string]backendplugin.PluginFailures, len(m.failures))
+	for pluginID, f := range m.failures {
+		failures[pluginID] = *f
+	}
+	return failures
+}
+
+func (m *Manager) recordFailure(pluginID string, record func(f *backendplugin.PluginFailures)) {
+	m.failuresMu.Lock()
+	defer m.failuresMu.Unlock()
+
+	if m.failures == nil {
+		m.failures = map[string]*backendplugin.PluginFailures{}
+	}
+	f, ok := m.failures[pluginID]
+	if !ok {
+		f = &backendplugin.PluginFailures{}
+		m.failures[pluginID] = f
+	}
+	record(f)
+}
+
+func (m *Manager) recordRestart(pluginID string) {
+	m.recordFailure(pluginID, func(f *backendplugin.PluginFailures) { f.Restarts++ })
+}
+
+// recordCallFailure records the request to the plugin that failed because the plugin was unavailable.
+func (m *Manager) recordCallFailure(pluginID string, err error) {
+	if errors.Is(err, backendplugin.ErrPluginUnavailable) {
+		m.recordFailure(pluginID, func(f *backendplugin.PluginFailures) { f.Unavailable++ })
+	}
+}
+
+// recordHealthCheck records the failed health check of the plugin, or the health check reporting an error.
+func (m *Manager) recordHealthCheck(pluginID string, res *backend.CheckHealthResult, err error) {
+	m.recordCallFailure(pluginID, err)
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return
+	}
+	if err != nil || (res != nil && res.Status == backend.HealthStatusError) {
+		m.recordFailure(pluginID, func(f *backendplugin.PluginFailures) { f.HealthCheckFailures++ })
+	}
+}
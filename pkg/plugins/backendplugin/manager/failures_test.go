@@ -0,0 +1,28 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginFailures(t *testing.T) {
+	m := &Manager{}
+	require.Empty(t, m.PluginFailures())
+
+	m.recordRestart("test")
+	m.recordCallFailure("test", backendplugin.ErrPluginUnavailable)
+	m.recordCallFailure("test", errors.New("query failed"))
+	m.recordHealthCheck("test", &backend.CheckHealthResult{Status: backend.HealthStatusError}, nil)
+	m.recordHealthCheck("test", &backend.CheckHealthResult{Status: backend.HealthStatusOk}, nil)
+	m.recordHealthCheck("test", nil, backendplugin.ErrMethodNotImplemented)
+	m.recordHealthCheck("other", nil, backendplugin.ErrPluginUnavailable)
+
+	require.Equal(t, map[string]backendplugin.PluginFailures{
+		"test":  {Restarts: 1, Unavailable: 1, HealthCheckFailures: 1},
+		"other": {Unavailable: 1, HealthCheckFailures: 1},
+	}, m.PluginFailures())
+}
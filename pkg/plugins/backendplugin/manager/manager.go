@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
@@ -22,10 +23,15 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func ProvideService(cfg *setting.Cfg, licensing models.Licensing,
 	pluginRequestValidator models.PluginRequestValidator) *Manager {
+	if cfg.PluginDataSourceMetricLabels {
+		instrumentation.EnableDataSourceLabels(cfg.PluginDataSourceMetricLabelLimit)
+	}
+
 	s := &Manager{
 		Cfg:                    cfg,
 		License:                licensing,
@@ -33,6 +39,15 @@ func ProvideService(cfg *setting.Cfg, licensing models.Licensing,
 		logger:                 log.New("plugins.backend"),
 		plugins:                map[string]backendplugin.Plugin{},
 	}
+
+	if err := instrumentation.ConfigureDurationHistogram(cfg.PluginMetricsDurationBuckets,
+		cfg.PluginMetricsDurationLabels); err != nil {
+		s.logger.Error("Invalid plugin request duration histogram settings, using the defaults", "err", err)
+	}
+	if err := prometheus.Register(&processCollector{manager: s}); err != nil {
+		s.logger.Debug("Failed to register plugin process metrics", "err", err)
+	}
+
 	return s
 }
 
@@ -42,10 +57,20 @@ type Manager struct {
 	PluginRequestValidator models.PluginRequestValidator
 	pluginsMu              sync.RWMutex
 	plugins                map[string]backendplugin.Plugin
+	canaries               map[string]backendplugin.Plugin
+	factories              map[string]backendplugin.PluginFactoryFunc
+	envs                   map[string][]string
 	logger                 log.Logger
+	failuresMu             sync.Mutex
+	failures               map[string]*backendplugin.PluginFailures
+	healthMu               sync.Mutex
+	lastHealth             map[string]map[string]backendplugin.DataSourceHealth
+	// running is set to 1 once the manager runs.
+	running int32
 }
 
 func (m *Manager) Run(ctx context.Context) error {
+	atomic.StoreInt32(&m.running, 1)
 	<-ctx.Done()
 	m.stop(ctx)
 	return ctx.Err()
@@ -61,29 +86,7 @@ func (m *Manager) Register(pluginID string, factory backendplugin.PluginFactoryF
 		return fmt.Errorf("backend plugin %s already registered", pluginID)
 	}
 
-	hostEnv := []string{
-		fmt.Sprintf("GF_VERSION=%s", m.Cfg.BuildVersion),
-		fmt.Sprintf("GF_EDITION=%s", m.License.Edition()),
-	}
-
-	if m.License.HasLicense() {
-		hostEnv = append(
-			hostEnv,
-			fmt.Sprintf("GF_ENTERPRISE_LICENSE_PATH=%s", m.Cfg.EnterpriseLicensePath),
-		)
-
-		if envProvider, ok := m.License.(models.LicenseEnvironment); ok {
-			for k, v := range envProvider.Environment() {
-				hostEnv = append(hostEnv, fmt.Sprintf("%s=%s", k, v))
-			}
-		}
-	}
-
-	hostEnv = append(hostEnv, m.getAWSEnvironmentVariables()...)
-	hostEnv = append(hostEnv, m.getAzureEnvironmentVariables()...)
-
-	pluginSettings := getPluginSettings(pluginID, m.Cfg)
-	env := pluginSettings.ToEnv("GF_PLUGIN", hostEnv)
+	env := m.getPluginEnvVars(pluginID)
 
 	pluginLogger := m.logger.New("pluginId", pluginID)
 	plugin, err := factory(pluginID, pluginLogger, env)
@@ -92,6 +95,14 @@ func (m *Manager) Register(pluginID string, factory backendplugin.PluginFactoryF
 	}
 
 	m.plugins[pluginID] = plugin
+	if m.factories == nil {
+		m.factories = map[string]backendplugin.PluginFactoryFunc{}
+	}
+	m.factories[pluginID] = factory
+	if m.envs == nil {
+		m.envs = map[string][]string{}
+	}
+	m.envs[pluginID] = env
 	m.logger.Debug("Backend plugin registered", "pluginId", pluginID)
 	return nil
 }
@@ -134,11 +145,37 @@ func (m *Manager) UnregisterAndStop(ctx context.Context, pluginID string) error
 	}
 
 	delete(m.plugins, pluginID)
+	delete(m.factories, pluginID)
+	delete(m.envs, pluginID)
 
 	m.logger.Debug("Backend plugin unregistered", "pluginId", pluginID)
 	return nil
 }
 
+// RestartPlugin stops the process of a managed backend plugin, and registers and starts a new one with the same
+// factory.
+func (m *Manager) RestartPlugin(ctx context.Context, pluginID string) error {
+	m.pluginsMu.RLock()
+	p, registered := m.plugins[pluginID]
+	factory := m.factories[pluginID]
+	m.pluginsMu.RUnlock()
+	if !registered || p.IsDecommissioned() || factory == nil {
+		return backendplugin.ErrPluginNotRegistered
+	}
+
+	if !p.IsManaged() {
+		return backendplugin.ErrPluginNotManaged
+	}
+
+	m.logger.Info("Restarting backend plugin", "pluginId", pluginID)
+	if err := m.UnregisterAndStop(ctx, pluginID); err != nil {
+		return err
+	}
+
+	// like at startup, the new process outlives the request restarting it
+	return m.RegisterAndStart(context.Background(), pluginID, factory)
+}
+
 func (m *Manager) IsRegistered(pluginID string) bool {
 	p, _ := m.Get(pluginID)
 
@@ -157,6 +194,44 @@ func (m *Manager) Get(pluginID string) (backendplugin.Plugin, bool) {
 	return p, ok
 }
 
+// getPluginEnvVars returns the environment variables of the process of the backend plugin: the Grafana version and
+// edition, the license, the AWS and Azure settings, and the settings of the plugin. When secrets are forbidden in the
+// environment, the license environment variables and the settings looking like secrets are left out, the plugins
+// receive their credentials with each request instead.
+func (m *Manager) getPluginEnvVars(pluginID string) []string {
+	hostEnv := []string{
+		fmt.Sprintf("GF_VERSION=%s", m.Cfg.BuildVersion),
+		fmt.Sprintf("GF_EDITION=%s", m.License.Edition()),
+	}
+
+	if m.License.HasLicense() {
+		hostEnv = append(
+			hostEnv,
+			fmt.Sprintf("GF_ENTERPRISE_LICENSE_PATH=%s", m.Cfg.EnterpriseLicensePath),
+		)
+
+		if envProvider, ok := m.License.(models.LicenseEnvironment); ok && !m.Cfg.PluginsForbidEnvSecrets {
+			for k, v := range envProvider.Environment() {
+				hostEnv = append(hostEnv, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+	}
+
+	hostEnv = append(hostEnv, m.getAWSEnvironmentVariables()...)
+	hostEnv = append(hostEnv, m.getAzureEnvironmentVariables()...)
+
+	pluginSettings := getPluginSettings(pluginID, m.Cfg)
+	if m.Cfg.PluginsForbidEnvSecrets {
+		var removed []string
+		pluginSettings, removed = pluginSettings.withoutSecrets()
+		if len(removed) > 0 {
+			m.logger.Warn("Plugin settings looking like secrets aren't passed to the plugin", "pluginId", pluginID,
+				"keys", strings.Join(removed, ","))
+		}
+	}
+	return pluginSettings.ToEnv("GF_PLUGIN", hostEnv)
+}
+
 func (m *Manager) getAWSEnvironmentVariables() []string {
 	variables := []string{}
 	if m.Cfg.AWSAssumeRoleEnabled {
@@ -190,7 +265,7 @@ func (m *Manager) start(ctx context.Context, p backendplugin.Plugin) {
 		return
 	}
 
-	if err := startPluginAndRestartKilledProcesses(ctx, p); err != nil {
+	if err := m.startPluginAndRestartKilledProcesses(ctx, p); err != nil {
 		p.Logger().Error("Failed to start plugin", "error", err)
 	}
 }
@@ -208,15 +283,22 @@ func (m *Manager) StartPlugin(ctx context.Context, pluginID string) error {
 		return errors.New("backend plugin is managed and cannot be manually started")
 	}
 
-	return startPluginAndRestartKilledProcesses(ctx, p)
+	return m.startPluginAndRestartKilledProcesses(ctx, p)
 }
 
-// stop stops all managed backend plugins
+// stop stops all managed backend plugins, including the canary versions
 func (m *Manager) stop(ctx context.Context) {
 	m.pluginsMu.RLock()
 	defer m.pluginsMu.RUnlock()
 	var wg sync.WaitGroup
+	plugins := make([]backendplugin.Plugin, 0, len(m.plugins)+len(m.canaries))
 	for _, p := range m.plugins {
+		plugins = append(plugins, p)
+	}
+	for _, p := range m.canaries {
+		plugins = append(plugins, p)
+	}
+	for _, p := range plugins {
 		wg.Add(1)
 		go func(p backendplugin.Plugin, ctx context.Context) {
 			defer wg.Done()
@@ -231,14 +313,20 @@ func (m *Manager) stop(ctx context.Context) {
 }
 
 // CollectMetrics collects metrics from a registered backend plugin.
-func (m *Manager) CollectMetrics(ctx context.Context, pluginID string) (*backend.CollectMetricsResult, error) {
+func (m *Manager) CollectMetrics(ctx context.Context, pluginID string) (res *backend.CollectMetricsResult, err error) {
 	p, registered := m.Get(pluginID)
 	if !registered {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
 
+	ctx, span := startSpan(ctx, "collect_metrics", pluginID, nil)
+	defer func() {
+		m.recordCallFailure(pluginID, err)
+		endSpan(span, err)
+	}()
+
 	var resp *backend.CollectMetricsResult
-	err := instrumentation.InstrumentCollectMetrics(p.PluginID(), func() (innerErr error) {
+	err = instrumentation.InstrumentCollectMetrics(p.PluginID(), func() (innerErr error) {
 		resp, innerErr = p.CollectMetrics(ctx)
 		return
 	})
@@ -250,13 +338,13 @@ func (m *Manager) CollectMetrics(ctx context.Context, pluginID string) (*backend
 }
 
 // CheckHealth checks the health of a registered backend plugin.
-func (m *Manager) CheckHealth(ctx context.Context, pluginContext backend.PluginContext) (*backend.CheckHealthResult, error) {
+func (m *Manager) CheckHealth(ctx context.Context, pluginContext backend.PluginContext) (res *backend.CheckHealthResult, err error) {
 	var dsURL string
 	if pluginContext.DataSourceInstanceSettings != nil {
 		dsURL = pluginContext.DataSourceInstanceSettings.URL
 	}
 
-	err := m.PluginRequestValidator.Validate(dsURL, nil)
+	err = m.PluginRequestValidator.Validate(dsURL, nil)
 	if err != nil {
 		return &backend.CheckHealthResult{
 			Status:  http.StatusForbidden,
@@ -264,13 +352,21 @@ func (m *Manager) CheckHealth(ctx context.Context, pluginContext backend.PluginC
 		}, nil
 	}
 
-	p, registered := m.Get(pluginContext.PluginID)
+	p, registered := m.getForOrg(pluginContext.PluginID, pluginContext.OrgID)
 	if !registered {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
+	pluginContext, _, _, _ = m.withTenantHeader(pluginContext)
+
+	ctx, span := startSpan(ctx, "check_health", pluginContext.PluginID, &pluginContext)
+	defer func() {
+		m.recordHealthCheck(pluginContext.PluginID, res, err)
+		m.recordLastHealth(pluginContext, res, err)
+		endSpan(span, err)
+	}()
 
 	var resp *backend.CheckHealthResult
-	err = instrumentation.InstrumentCheckHealthRequest(p.PluginID(), func() (innerErr error) {
+	err = instrumentation.InstrumentCheckHealthRequest(pluginContext, func() (innerErr error) {
 		resp, innerErr = p.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pluginContext})
 		return
 	})
@@ -290,17 +386,50 @@ func (m *Manager) CheckHealth(ctx context.Context, pluginContext backend.PluginC
 	return resp, nil
 }
 
-func (m *Manager) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	p, registered := m.Get(req.PluginContext.PluginID)
+func (m *Manager) QueryData(ctx context.Context, req *backend.QueryDataRequest) (res *backend.QueryDataResponse, err error) {
+	var dsURL string
+	if req.PluginContext.DataSourceInstanceSettings != nil {
+		dsURL = req.PluginContext.DataSourceInstanceSettings.URL
+	}
+
+	if err := m.PluginRequestValidator.Validate(dsURL, nil); err != nil {
+		return nil, errutil.Wrap("access denied", err)
+	}
+
+	p, registered := m.getForOrg(req.PluginContext.PluginID, req.PluginContext.OrgID)
 	if !registered {
 		return nil, backendplugin.ErrPluginNotRegistered
 	}
 
+	if pCtx, name, value, ok := m.withTenantHeader(req.PluginContext); ok {
+		tenantReq := *req
+		tenantReq.PluginContext = pCtx
+		tenantReq.Headers = make(map[string]string, len(req.Headers)+1)
+		for k, v := range req.Headers {
+			tenantReq.Headers[k] = v
+		}
+		tenantReq.Headers[name] = value
+		req = &tenantReq
+	}
+
+	ctx, span := startSpan(ctx, "query_data", req.PluginContext.PluginID, &req.PluginContext)
+	defer func() {
+		m.recordCallFailure(req.PluginContext.PluginID, err)
+		endSpan(span, err)
+	}()
+
 	var resp *backend.QueryDataResponse
-	err := instrumentation.InstrumentQueryDataRequest(p.PluginID(), func() (innerErr error) {
+	logRequest := m.requestLogSampled(req.PluginContext.PluginID)
+	start := time.Now()
+	err = instrumentation.InstrumentQueryDataRequest(req.PluginContext, func() (innerErr error) {
 		resp, innerErr = p.QueryData(ctx, req)
 		return
 	})
+	elapsed := time.Since(start)
+	m.logSlowQuery(req, elapsed, err)
+	if logRequest {
+		m.logQueryData(req, resp, elapsed, err)
+	}
 
 	if err != nil {
 		if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
@@ -314,6 +443,7 @@ func (m *Manager) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, errutil.Wrap("failed to query data", err)
 	}
 
+	m.limitQueryResponses(req, resp)
 	return resp, nil
 }
 
@@ -321,12 +451,18 @@ type keepCookiesJSONModel struct {
 	KeepCookies []string `json:"keepCookies"`
 }
 
-func (m *Manager) callResourceInternal(w http.ResponseWriter, req *http.Request, pCtx backend.PluginContext) error {
-	p, registered := m.Get(pCtx.PluginID)
+func (m *Manager) callResourceInternal(w http.ResponseWriter, req *http.Request, pCtx backend.PluginContext) (err error) {
+	p, registered := m.getForOrg(pCtx.PluginID, pCtx.OrgID)
 	if !registered {
 		return backendplugin.ErrPluginNotRegistered
 	}
 
+	ctx, span := startSpan(req.Context(), "call_resource", pCtx.PluginID, &pCtx)
+	defer func() {
+		m.recordCallFailure(pCtx.PluginID, err)
+		endSpan(span, err)
+	}()
+
 	keepCookieModel := keepCookiesJSONModel{}
 	if dis := pCtx.DataSourceInstanceSettings; dis != nil {
 		err := json.Unmarshal(dis.JSONData, &keepCookieModel)
@@ -338,6 +474,11 @@ func (m *Manager) callResourceInternal(w http.ResponseWriter, req *http.Request,
 	proxyutil.ClearCookieHeader(req, keepCookieModel.KeepCookies)
 	proxyutil.PrepareProxyRequest(req)
 
+	if tenantPCtx, name, value, ok := m.withTenantHeader(pCtx); ok {
+		pCtx = tenantPCtx
+		req.Header.Set(name, value)
+	}
+
 	body, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read request body: %w", err)
@@ -352,8 +493,8 @@ func (m *Manager) callResourceInternal(w http.ResponseWriter, req *http.Request,
 		Body:          body,
 	}
 
-	return instrumentation.InstrumentCallResourceRequest(p.PluginID(), func() error {
-		childCtx, cancel := context.WithCancel(req.Context())
+	return instrumentation.InstrumentCallResourceRequest(pCtx, func() error {
+		childCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 		stream := newCallResourceResponseStream(childCtx)
 
@@ -373,7 +514,7 @@ func (m *Manager) callResourceInternal(w http.ResponseWriter, req *http.Request,
 			wg.Done()
 		}()
 
-		if err := p.CallResource(req.Context(), crReq, stream); err != nil {
+		if err := p.CallResource(ctx, crReq, stream); err != nil {
 			return err
 		}
 
@@ -480,21 +621,27 @@ func flushStream(plugin backendplugin.Plugin, stream callResourceClientResponseS
 	}
 }
 
-func startPluginAndRestartKilledProcesses(ctx context.Context, p backendplugin.Plugin) error {
+func (m *Manager) startPluginAndRestartKilledProcesses(ctx context.Context, p backendplugin.Plugin) error {
 	if err := p.Start(ctx); err != nil {
 		return err
 	}
 
+	m.restartKilledProcesses(ctx, p)
+
+	return nil
+}
+
+// restartKilledProcesses restarts the process of the started backend plugin whenever it exits, until the plugin is
+// decommissioned.
+func (m *Manager) restartKilledProcesses(ctx context.Context, p backendplugin.Plugin) {
 	go func(ctx context.Context, p backendplugin.Plugin) {
-		if err := restartKilledProcess(ctx, p); err != nil {
+		if err := restartKilledProcess(ctx, p, func() { m.recordRestart(p.PluginID()) }); err != nil {
 			p.Logger().Error("Attempt to restart killed plugin process failed", "error", err)
 		}
 	}(ctx, p)
-
-	return nil
 }
 
-func restartKilledProcess(ctx context.Context, p backendplugin.Plugin) error {
+func restartKilledProcess(ctx context.Context, p backendplugin.Plugin, onRestart func()) error {
 	ticker := time.NewTicker(time.Second * 1)
 
 	for {
@@ -514,7 +661,14 @@ func restartKilledProcess(ctx context.Context, p backendplugin.Plugin) error {
 				continue
 			}
 
+			// the lifecycle of a standalone process is up to the plugin developer
+			if sp, ok := p.(backendplugin.StandalonePlugin); ok && sp.IsStandalone() {
+				p.Logger().Warn("Standalone plugin process exited, reload the plugin to attach to a new process")
+				return nil
+			}
+
 			p.Logger().Debug("Restarting plugin")
+			onRestart()
 			if err := p.Start(ctx); err != nil {
 				p.Logger().Error("Failed to restart plugin", "error", err)
 				continue
@@ -3,6 +3,7 @@ package manager
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -304,6 +305,202 @@ func TestManager(t *testing.T) {
 			})
 		})
 	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Plugin registration scenario when secrets are forbidden in the environment", func(t *testing.T) {
+			ctx.license.edition = "Enterprise"
+			ctx.license.hasLicense = true
+			ctx.license.tokenRaw = "testtoken"
+			ctx.cfg.EnterpriseLicensePath = "/license.txt"
+			ctx.cfg.PluginsForbidEnvSecrets = true
+			ctx.cfg.PluginSettings = setting.PluginSettings{
+				testPluginID: {"url": "http://localhost", "api_token": "secret-token"},
+			}
+
+			err := ctx.manager.RegisterAndStart(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+
+			require.Contains(t, ctx.env, "GF_ENTERPRISE_LICENSE_PATH=/license.txt")
+			require.Contains(t, ctx.env, "GF_PLUGIN_URL=http://localhost")
+			for _, kv := range ctx.env {
+				require.NotContains(t, kv, "testtoken")
+				require.NotContains(t, kv, "secret-token")
+			}
+		})
+	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Restart plugin scenario", func(t *testing.T) {
+			err := ctx.manager.RestartPlugin(context.Background(), testPluginID)
+			require.Equal(t, backendplugin.ErrPluginNotRegistered, err)
+
+			err = ctx.manager.RegisterAndStart(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			oldPlugin := ctx.plugin
+
+			err = ctx.manager.RestartPlugin(context.Background(), testPluginID)
+			require.NoError(t, err)
+			require.True(t, oldPlugin.IsDecommissioned())
+			require.Equal(t, 1, oldPlugin.stopCount)
+			require.NotSame(t, oldPlugin, ctx.plugin)
+			require.Equal(t, 1, ctx.plugin.startCount)
+
+			p, registered := ctx.manager.Get(testPluginID)
+			require.True(t, registered)
+			require.Same(t, ctx.plugin, p)
+		})
+	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Upgrade plugin scenario", func(t *testing.T) {
+			t.Cleanup(func() { upgradeDrainPeriod = 30 * time.Second })
+			upgradeDrainPeriod = 0
+
+			err := ctx.manager.UpgradePlugin(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			oldPlugin := ctx.plugin
+			require.Equal(t, 1, oldPlugin.startCount)
+
+			err = ctx.manager.UpgradePlugin(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			require.NotSame(t, oldPlugin, ctx.plugin)
+			require.Equal(t, 1, ctx.plugin.startCount)
+			require.True(t, oldPlugin.IsDecommissioned())
+			require.Eventually(t, func() bool {
+				oldPlugin.mutex.RLock()
+				defer oldPlugin.mutex.RUnlock()
+				return oldPlugin.stopCount == 1
+			}, time.Second, 10*time.Millisecond)
+
+			p, registered := ctx.manager.Get(testPluginID)
+			require.True(t, registered)
+			require.Same(t, ctx.plugin, p)
+
+			t.Run("Should keep the registered plugin when the new one isn't healthy", func(t *testing.T) {
+				registeredPlugin := ctx.plugin
+				unhealthyFactory := func(pluginID string, logger log.Logger, env []string) (backendplugin.Plugin, error) {
+					p, err := ctx.factory(pluginID, logger, env)
+					ctx.plugin.CheckHealthHandlerFunc = func(context.Context, *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+						return nil, backendplugin.ErrPluginUnavailable
+					}
+					return p, err
+				}
+
+				err := ctx.manager.UpgradePlugin(context.Background(), testPluginID, unhealthyFactory)
+				require.ErrorIs(t, err, backendplugin.ErrPluginUnavailable)
+				require.True(t, ctx.plugin.IsDecommissioned())
+				require.Equal(t, 1, ctx.plugin.stopCount)
+
+				p, registered := ctx.manager.Get(testPluginID)
+				require.True(t, registered)
+				require.Same(t, registeredPlugin, p)
+			})
+		})
+	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Request validation scenario", func(t *testing.T) {
+			err := ctx.manager.RegisterAndStart(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			ctx.plugin.QueryDataHandlerFunc = func(context.Context, *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+				return backend.NewQueryDataResponse(), nil
+			}
+			validationErr := errors.New("data source host not allowed")
+			ctx.manager.PluginRequestValidator = &testPluginRequestValidator{err: validationErr}
+
+			pCtx := backend.PluginContext{
+				PluginID:                   testPluginID,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{URL: "http://169.254.169.254"},
+			}
+			_, err = ctx.manager.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+			require.ErrorIs(t, err, validationErr)
+
+			res, err := ctx.manager.CheckHealth(context.Background(), pCtx)
+			require.NoError(t, err)
+			require.Equal(t, backend.HealthStatus(http.StatusForbidden), res.Status)
+
+			ctx.manager.PluginRequestValidator = &testPluginRequestValidator{}
+			_, err = ctx.manager.QueryData(context.Background(), &backend.QueryDataRequest{PluginContext: pCtx})
+			require.NoError(t, err)
+		})
+	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Canary plugin scenario", func(t *testing.T) {
+			healthCheckHandler := func(message string) backend.CheckHealthHandlerFunc {
+				return func(context.Context, *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+					return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: message}, nil
+				}
+			}
+			checkHealth := func(orgID int64) string {
+				res, err := ctx.manager.CheckHealth(context.Background(), backend.PluginContext{PluginID: testPluginID, OrgID: orgID})
+				require.NoError(t, err)
+				return res.Message
+			}
+
+			err := ctx.manager.RegisterAndStart(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			ctx.plugin.CheckHealthHandlerFunc = healthCheckHandler("stable")
+
+			err = ctx.manager.RegisterCanary(context.Background(), testPluginID, ctx.factory)
+			require.NoError(t, err)
+			require.Equal(t, 1, ctx.plugin.startCount)
+			ctx.plugin.CheckHealthHandlerFunc = healthCheckHandler("canary")
+
+			err = ctx.manager.RegisterCanary(context.Background(), testPluginID, ctx.factory)
+			require.Error(t, err)
+
+			require.Equal(t, "stable", checkHealth(2))
+
+			ctx.cfg.PluginSettings = setting.PluginSettings{testPluginID: {"canary_org_ids": "2, 3"}}
+			require.Equal(t, "canary", checkHealth(2))
+			require.Equal(t, "canary", checkHealth(3))
+			require.Equal(t, "stable", checkHealth(1))
+
+			ctx.cfg.PluginSettings[testPluginID]["canary_percentage"] = "100"
+			require.Equal(t, "canary", checkHealth(1))
+		})
+	})
+
+	newManagerScenario(t, false, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Restart unmanaged plugin scenario", func(t *testing.T) {
+			err := ctx.manager.Register(testPluginID, ctx.factory)
+			require.NoError(t, err)
+
+			err = ctx.manager.RestartPlugin(context.Background(), testPluginID)
+			require.Equal(t, backendplugin.ErrPluginNotManaged, err)
+		})
+	})
+
+	newManagerScenario(t, true, func(t *testing.T, ctx *managerScenarioCtx) {
+		t.Run("Plugin config scenario", func(t *testing.T) {
+			ctx.license.edition = "Enterprise"
+			ctx.license.hasLicense = true
+			ctx.license.tokenRaw = "testtoken"
+			ctx.cfg.BuildVersion = "7.0.0"
+			ctx.cfg.PluginSettings = setting.PluginSettings{
+				testPluginID: {"path": "/plugins/test", "url": "http://localhost", "api_token": "secret-token"},
+			}
+
+			_, err := ctx.manager.PluginConfig(testPluginID)
+			require.Equal(t, backendplugin.ErrPluginNotRegistered, err)
+
+			err = ctx.manager.Register(testPluginID, ctx.factory)
+			require.NoError(t, err)
+
+			config, err := ctx.manager.PluginConfig(testPluginID)
+			require.NoError(t, err)
+			require.Equal(t, "7.0.0", config.Env["GF_VERSION"])
+			require.Equal(t, "*********", config.Env["GF_ENTERPRISE_LICENSE_TEXT"])
+			require.Equal(t, "http://localhost", config.Env["GF_PLUGIN_URL"])
+			require.Equal(t, "*********", config.Env["GF_PLUGIN_API_TOKEN"])
+			require.Equal(t, map[string]string{
+				"path":      "/plugins/test",
+				"url":       "http://localhost",
+				"api_token": "*********",
+			}, config.Settings)
+		})
+	})
 }
 
 type managerScenarioCtx struct {
@@ -353,12 +550,25 @@ func newManagerScenario(t *testing.T, managed bool, fn func(t *testing.T, ctx *m
 	fn(t, ctx)
 }
 
+func TestRestartKilledProcess(t *testing.T) {
+	t.Run("Shouldn't restart standalone plugin processes", func(t *testing.T) {
+		p := &testPlugin{pluginID: testPluginID, logger: log.New("test"), managed: true, standalone: true, exited: true}
+		restarts := 0
+
+		err := restartKilledProcess(context.Background(), p, func() { restarts++ })
+		require.NoError(t, err)
+		require.Zero(t, restarts)
+		require.Zero(t, p.startCount)
+	})
+}
+
 type testPlugin struct {
 	pluginID       string
 	logger         log.Logger
 	startCount     int
 	stopCount      int
 	managed        bool
+	standalone     bool
 	exited         bool
 	decommissioned bool
 	backend.CollectMetricsHandlerFunc
@@ -395,6 +605,10 @@ func (tp *testPlugin) IsManaged() bool {
 	return tp.managed
 }
 
+func (tp *testPlugin) IsStandalone() bool {
+	return tp.standalone
+}
+
 func (tp *testPlugin) Exited() bool {
 	tp.mutex.RLock()
 	defer tp.mutex.RUnlock()
@@ -504,8 +718,10 @@ func (t *testLicensingService) Environment() map[string]string {
 	return map[string]string{"GF_ENTERPRISE_LICENSE_TEXT": t.tokenRaw}
 }
 
-type testPluginRequestValidator struct{}
+type testPluginRequestValidator struct {
+	err error
+}
 
 func (t *testPluginRequestValidator) Validate(string, *http.Request) error {
-	return nil
+	return t.err
 }
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+const masked = "*********"
+
+// PluginConfig returns the environment variables the registered backend plugin received, and its settings, with
+// the values of the keys looking like secrets, and the license environment variables, masked.
+func (m *Manager) PluginConfig(pluginID string) (backendplugin.PluginConfig, error) {
+	m.pluginsMu.RLock()
+	env, registered := m.envs[pluginID]
+	m.pluginsMu.RUnlock()
+	if !registered {
+		return backendplugin.PluginConfig{}, backendplugin.ErrPluginNotRegistered
+	}
+
+	licenseKeys := map[string]bool{}
+	if envProvider, ok := m.License.(models.LicenseEnvironment); ok {
+		for k := range envProvider.Environment() {
+			licenseKeys[k] = true
+		}
+	}
+
+	config := backendplugin.PluginConfig{
+		Env:      make(map[string]string, len(env)),
+		Settings: make(map[string]string, len(m.Cfg.PluginSettings[pluginID])),
+	}
+	for _, kv := range env {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if licenseKeys[parts[0]] || isSecretKey(parts[0], nil) {
+			parts[1] = masked
+		}
+		config.Env[parts[0]] = parts[1]
+	}
+	for k, v := range m.Cfg.PluginSettings[pluginID] {
+		if isSecretKey(k, nil) {
+			v = masked
+		}
+		config.Settings[k] = v
+	}
+
+	return config, nil
+}
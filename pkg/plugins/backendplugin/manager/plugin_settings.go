@@ -3,6 +3,7 @@ package manager
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/setting"
@@ -26,12 +27,32 @@ func (ps pluginSettings) ToEnv(prefix string, hostEnv []string) []string {
 	return env
 }
 
+// withoutSecrets returns the settings without the ones whose keys look like secrets, and the sorted keys of the
+// removed ones.
+func (ps pluginSettings) withoutSecrets() (pluginSettings, []string) {
+	kept := pluginSettings{}
+	var removed []string
+	for k, v := range ps {
+		if isSecretKey(k, nil) {
+			removed = append(removed, k)
+			continue
+		}
+		kept[k] = v
+	}
+	sort.Strings(removed)
+	return kept, removed
+}
+
 func getPluginSettings(plugID string, cfg *setting.Cfg) pluginSettings {
 	ps := pluginSettings{}
 	for k, v := range cfg.PluginSettings[plugID] {
 		if k == "path" || strings.ToLower(k) == "id" {
 			continue
 		}
+		// the feature toggles are passed to the plugin as a comma-separated list, whatever their separators
+		if k == "feature_toggles" {
+			v = strings.Join(cfg.PluginFeatureToggles(plugID), ",")
+		}
 
 		ps[k] = v
 	}
@@ -94,3 +94,22 @@ func TestPluginSettings(t *testing.T) {
 		})
 	})
 }
+
+func TestPluginSettings_featureToggles(t *testing.T) {
+	cfg := &setting.Cfg{
+		PluginSettings: setting.PluginSettings{
+			"plugin": map[string]string{"feature_toggles": "newEditor  liveTail, streaming"},
+		},
+	}
+
+	env := getPluginSettings("plugin", cfg).ToEnv("GF_PLUGIN", nil)
+	require.Equal(t, []string{"GF_PLUGIN_FEATURE_TOGGLES=newEditor,liveTail,streaming"}, env)
+}
+
+func TestPluginSettings_withoutSecrets(t *testing.T) {
+	ps := pluginSettings{"url": "http://localhost", "api_token": "token", "basicAuthPassword": "password"}
+
+	kept, removed := ps.withoutSecrets()
+	require.Equal(t, pluginSettings{"url": "http://localhost"}, kept)
+	require.Equal(t, []string{"api_token", "basicAuthPassword"}, removed)
+}
@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+var (
+	pluginProcessCPUSeconds = prometheus.NewDesc("grafana_plugin_process_cpu_seconds_total",
+		"Total user and system CPU time spent by the plugin process in seconds.", []string{"plugin_id"}, nil)
+	pluginProcessResidentMemory = prometheus.NewDesc("grafana_plugin_process_resident_memory_bytes",
+		"Resident memory size of the plugin process in bytes.", []string{"plugin_id"}, nil)
+	pluginProcessOpenFDs = prometheus.NewDesc("grafana_plugin_process_open_fds",
+		"Number of open file descriptors of the plugin process.", []string{"plugin_id"}, nil)
+	pluginProcessThreads = prometheus.NewDesc("grafana_plugin_process_threads",
+		"Number of OS threads of the plugin process.", []string{"plugin_id"}, nil)
+)
+
+// processCollector collects the resource usage of the processes of the running backend plugins. The usage is read
+// from procfs, so it's only collected on Linux.
+type processCollector struct {
+	manager *Manager
+}
+
+func (c *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pluginProcessCPUSeconds
+	ch <- pluginProcessResidentMemory
+	ch <- pluginProcessOpenFDs
+	ch <- pluginProcessThreads
+}
+
+func (c *processCollector) Collect(ch chan<- prometheus.Metric) {
+	c.manager.pluginsMu.RLock()
+	plugins := make([]backendplugin.Plugin, 0, len(c.manager.plugins))
+	for _, p := range c.manager.plugins {
+		plugins = append(plugins, p)
+	}
+	c.manager.pluginsMu.RUnlock()
+
+	for _, p := range plugins {
+		processPlugin, ok := p.(backendplugin.ProcessPlugin)
+		if !ok || p.IsDecommissioned() {
+			continue
+		}
+		pid, running := processPlugin.PID()
+		if !running {
+			continue
+		}
+
+		proc, err := procfs.NewProc(pid)
+		if err != nil {
+			continue
+		}
+		stat, err := proc.Stat()
+		if err != nil {
+			c.manager.logger.Debug("Failed to read plugin process stat", "pluginId", p.PluginID(), "pid", pid, "err", err)
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(pluginProcessCPUSeconds, prometheus.CounterValue, stat.CPUTime(), p.PluginID())
+		ch <- prometheus.MustNewConstMetric(pluginProcessResidentMemory, prometheus.GaugeValue, float64(stat.ResidentMemory()), p.PluginID())
+		ch <- prometheus.MustNewConstMetric(pluginProcessThreads, prometheus.GaugeValue, float64(stat.NumThreads), p.PluginID())
+		if fds, err := proc.FileDescriptorsLen(); err == nil {
+			ch <- prometheus.MustNewConstMetric(pluginProcessOpenFDs, prometheus.GaugeValue, float64(fds), p.PluginID())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package manager
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testProcessPlugin struct {
+	*testPlugin
+	pid int
+}
+
+func (tp *testProcessPlugin) PID() (int, bool) {
+	return tp.pid, tp.pid != 0
+}
+
+func TestProcessCollector(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("plugin process metrics are only collected on Linux")
+	}
+
+	m := &Manager{
+		logger: log.New("test"),
+		plugins: map[string]backendplugin.Plugin{
+			"running": &testProcessPlugin{testPlugin: &testPlugin{pluginID: "running"}, pid: os.Getpid()},
+			"stopped": &testProcessPlugin{testPlugin: &testPlugin{pluginID: "stopped"}},
+			"core":    &testPlugin{pluginID: "core"},
+		},
+	}
+
+	// the four process metrics of the running plugin process only
+	require.Equal(t, 4, testutil.CollectAndCount(&processCollector{manager: m}))
+}
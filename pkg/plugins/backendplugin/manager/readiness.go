@@ -0,0 +1,15 @@
+package manager
+
+import (
+	"sync/atomic"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+var _ backendplugin.ReadinessReporter = &Manager{}
+
+// PluginsRegistered returns whether the backend plugins have been registered. The core plugins and the installed
+// plugins are registered while the services are created, so they all are once the manager runs.
+func (m *Manager) PluginsRegistered() bool {
+	return atomic.LoadInt32(&m.running) == 1
+}
@@ -0,0 +1,27 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginsRegistered(t *testing.T) {
+	m := &Manager{logger: log.New("test"), plugins: map[string]backendplugin.Plugin{}}
+	require.False(t, m.PluginsRegistered())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = m.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, m.PluginsRegistered, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// Keys of the plugin settings, i.e. of the [plugin.<plugin id>] section of the configuration, enabling the logging
+// of the query data requests of the plugin and their responses, for a sample of them.
+const (
+	requestLogSetting           = "log_requests"
+	requestLogSampleRateSetting = "log_requests_sample_rate"
+)
+
+const redacted = "[REDACTED]"
+
+var requestLogger = log.New("plugins.requestlog")
+
+// requestLogSampled returns whether the query data request to the plugin should be logged.
+func (m *Manager) requestLogSampled(pluginID string) bool {
+	if m.Cfg == nil {
+		return false
+	}
+	settings := m.Cfg.PluginSettings[pluginID]
+	if enabled, _ := strconv.ParseBool(settings[requestLogSetting]); !enabled {
+		return false
+	}
+
+	rate := 1.0
+	if value, ok := settings[requestLogSampleRateSetting]; ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			m.logger.Warn("Invalid request log sample rate", "pluginId", pluginID, "value", value)
+			return false
+		}
+		rate = parsed
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// logQueryData logs the query data request with the secure values redacted, and a summary of the frames of the
+// response.
+func (m *Manager) logQueryData(req *backend.QueryDataRequest, resp *backend.QueryDataResponse, elapsed time.Duration, err error) {
+	secureKeys := map[string]bool{}
+	ctx := []interface{}{
+		"pluginId", req.PluginContext.PluginID,
+		"orgId", req.PluginContext.OrgID,
+		"duration", elapsed,
+	}
+	if ds := req.PluginContext.DataSourceInstanceSettings; ds != nil {
+		for k := range ds.DecryptedSecureJSONData {
+			secureKeys[strings.ToLower(k)] = true
+		}
+		ctx = append(ctx, "datasourceUid", ds.UID, "datasourceName", ds.Name, "datasourceUrl", ds.URL)
+	}
+	if req.PluginContext.User != nil {
+		ctx = append(ctx, "user", req.PluginContext.User.Login)
+	}
+
+	headers := make(map[string]string, len(req.Headers))
+	for k := range req.Headers {
+		headers[k] = redacted
+	}
+	ctx = append(ctx, "headers", headers, "queries", redactQueries(req.Queries, secureKeys))
+
+	if err != nil {
+		ctx = append(ctx, "error", err)
+	}
+	if resp != nil {
+		ctx = append(ctx, "responses", summarizeResponses(resp))
+	}
+
+	requestLogger.Info("Query data request", ctx...)
+}
+
+type queryLogEntry struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType,omitempty"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Interval      time.Duration   `json:"interval"`
+	Model         json.RawMessage `json:"model,omitempty"`
+}
+
+// redactQueries returns the queries for the log, with the values of the keys of their models matching the secure
+// JSON data of the data source, or looking like secrets, redacted.
+func redactQueries(queries []backend.DataQuery, secureKeys map[string]bool) string {
+	entries := make([]queryLogEntry, 0, len(queries))
+	for _, q := range queries {
+		entry := queryLogEntry{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			From:          q.TimeRange.From,
+			To:            q.TimeRange.To,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+		}
+
+		var model interface{}
+		if err := json.Unmarshal(q.JSON, &model); err == nil {
+			if redactedModel, err := json.Marshal(redactValue(model, secureKeys)); err == nil {
+				entry.Model = redactedModel
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	out, err := json.Marshal(entries)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}, secureKeys map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if isSecretKey(k, secureKeys) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(value, secureKeys)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value, secureKeys)
+		}
+	}
+	return v
+}
+
+func isSecretKey(key string, secureKeys map[string]bool) bool {
+	key = strings.ToLower(key)
+	if secureKeys[key] {
+		return true
+	}
+	for _, secret := range []string{"password", "secret", "token", "apikey", "api_key", "credential"} {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+type frameSummary struct {
+	Name   string   `json:"name,omitempty"`
+	Fields []string `json:"fields"`
+	Rows   int      `json:"rows"`
+}
+
+type responseSummary struct {
+	RefID  string         `json:"refId"`
+	Error  string         `json:"error,omitempty"`
+	Frames []frameSummary `json:"frames"`
+}
+
+// summarizeResponses returns the names and types of the fields and the number of rows of the frames of the
+// responses, without their values.
+func summarizeResponses(resp *backend.QueryDataResponse) string {
+	summaries := make([]responseSummary, 0, len(resp.Responses))
+	for refID, r := range resp.Responses {
+		summary := responseSummary{RefID: refID, Frames: []frameSummary{}}
+		if r.Error != nil {
+			summary.Error = r.Error.Error()
+		}
+		for _, frame := range r.Frames {
+			fs := frameSummary{Name: frame.Name, Fields: make([]string, 0, len(frame.Fields)), Rows: frame.Rows()}
+			for _, field := range frame.Fields {
+				fs.Fields = append(fs.Fields, field.Name+":"+field.Type().ItemTypeString())
+			}
+			summary.Frames = append(summary.Frames, fs)
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].RefID < summaries[j].RefID })
+
+	out, err := json.Marshal(summaries)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogSampled(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.PluginSettings = setting.PluginSettings{
+		"all":     {"log_requests": "true"},
+		"none":    {"log_requests": "true", "log_requests_sample_rate": "0"},
+		"invalid": {"log_requests": "true", "log_requests_sample_rate": "often"},
+	}
+	m := &Manager{Cfg: cfg, logger: log.New("test")}
+
+	require.True(t, m.requestLogSampled("all"))
+	require.False(t, m.requestLogSampled("none"))
+	require.False(t, m.requestLogSampled("invalid"))
+	require.False(t, m.requestLogSampled("disabled"))
+}
+
+func TestRedactQueries(t *testing.T) {
+	queries := []backend.DataQuery{{
+		RefID: "A",
+		JSON:  []byte(`{"rawSql":"SELECT 1","auth":{"apiKey":"key","user":"admin"},"customSecret":"value"}`),
+	}}
+
+	logged := redactQueries(queries, map[string]bool{"customsecret": true})
+	require.Contains(t, logged, `"rawSql":"SELECT 1"`)
+	require.Contains(t, logged, `"user":"admin"`)
+	require.NotContains(t, logged, `"key"`)
+	require.NotContains(t, logged, `"value"`)
+	require.Contains(t, logged, `"apiKey":"[REDACTED]"`)
+	require.Contains(t, logged, `"customSecret":"[REDACTED]"`)
+}
+
+func TestSummarizeResponses(t *testing.T) {
+	resp := backend.NewQueryDataResponse()
+	resp.Responses["B"] = backend.DataResponse{Frames: data.Frames{}}
+	resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("series", data.NewField("value", nil, []float64{1, 2, 3})),
+	}}
+
+	require.Equal(t,
+		`[{"refId":"A","frames":[{"name":"series","fields":["value:float64"],"rows":3}]},{"refId":"B","frames":[]}]`,
+		summarizeResponses(resp))
+}
@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// limitQueryResponses truncates the frames of the responses of the queries whose decoded size exceeds the maximum
+// response size of the plugin, so that a single query can't balloon the memory of Grafana or of the consumers of its
+// response. The truncated frames carry a warning notice, shown in the panels.
+func (m *Manager) limitQueryResponses(req *backend.QueryDataRequest, resp *backend.QueryDataResponse) {
+	if resp == nil {
+		return
+	}
+	limit := int64(m.Cfg.PluginMaxQueryResponseSizeMB(req.PluginContext.PluginID)) << 20
+	if limit <= 0 {
+		return
+	}
+
+	for refID, dr := range resp.Responses {
+		size, truncated := truncateFrames(dr.Frames, limit)
+		if truncated == 0 {
+			continue
+		}
+		m.logger.Warn("Truncated query response exceeding the maximum response size", "pluginId",
+			req.PluginContext.PluginID, "refId", refID, "size", size, "limit", limit, "truncatedRows", truncated)
+	}
+}
+
+// truncateFrames drops the rows of the frames beyond the limit, in bytes, of their decoded size, and returns the size
+// of the frames before truncation and the number of rows dropped.
+func truncateFrames(frames data.Frames, limit int64) (int64, int) {
+	var size int64
+	truncated := 0
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		rows := frame.Rows()
+		kept := rows
+		for row := 0; row < rows; row++ {
+			size += rowSize(frame, row)
+			if size > limit && kept == rows {
+				kept = row
+			}
+		}
+		if kept == rows {
+			continue
+		}
+
+		for _, field := range frame.Fields {
+			// deleting the last row doesn't copy the others
+			for i := field.Len() - 1; i >= kept; i-- {
+				field.Delete(i)
+			}
+		}
+		truncated += rows - kept
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text: fmt.Sprintf("Response truncated to %d of %d rows since the query response exceeds the maximum size of %d MB",
+				kept, rows, limit>>20),
+		})
+	}
+	return size, truncated
+}
+
+func rowSize(frame *data.Frame, row int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		if row < field.Len() {
+			size += valueSize(field, row)
+		}
+	}
+	return size
+}
+
+// valueSize returns the approximate decoded size of the value of the field, in bytes.
+func valueSize(field *data.Field, idx int) int64 {
+	v, ok := field.ConcreteAt(idx)
+	if !ok {
+		return 1
+	}
+	switch v := v.(type) {
+	case string:
+		return int64(len(v))
+	case json.RawMessage:
+		return int64(len(v))
+	case time.Time:
+		return 24
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_limitQueryResponses(t *testing.T) {
+	newFrame := func(rows int) *data.Frame {
+		values := make([]string, rows)
+		for i := range values {
+			values[i] = strings.Repeat("a", 1<<10)
+		}
+		return data.NewFrame("test", data.NewField("value", nil, values))
+	}
+	newResponse := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{newFrame(512), newFrame(1024)}},
+			"B": {Frames: data.Frames{newFrame(256)}},
+		}}
+	}
+	req := &backend.QueryDataRequest{PluginContext: backend.PluginContext{PluginID: "test"}}
+
+	t.Run("Should truncate the frames of the responses exceeding the limit", func(t *testing.T) {
+		m := &Manager{Cfg: &setting.Cfg{PluginMaxQueryResponseSizeMB: 1}, logger: log.New("test")}
+		resp := newResponse()
+		m.limitQueryResponses(req, resp)
+
+		frames := resp.Responses["A"].Frames
+		require.Equal(t, 512, frames[0].Rows())
+		require.Nil(t, frames[0].Meta)
+		require.Equal(t, 512, frames[1].Rows())
+		require.Len(t, frames[1].Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frames[1].Meta.Notices[0].Severity)
+		require.Contains(t, frames[1].Meta.Notices[0].Text, "512 of 1024 rows")
+
+		require.Equal(t, 256, resp.Responses["B"].Frames[0].Rows())
+		require.Nil(t, resp.Responses["B"].Frames[0].Meta)
+	})
+
+	t.Run("Should use the limit of the plugin", func(t *testing.T) {
+		m := &Manager{Cfg: &setting.Cfg{
+			PluginMaxQueryResponseSizeMB: 1,
+			PluginSettings:               setting.PluginSettings{"test": {"max_query_response_size_mb": "0"}},
+		}, logger: log.New("test")}
+		resp := newResponse()
+		m.limitQueryResponses(req, resp)
+		require.Equal(t, 1024, resp.Responses["A"].Frames[1].Rows())
+	})
+}
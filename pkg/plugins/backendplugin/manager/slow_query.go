@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var slowQueryLogger = log.New("plugins.slowquery")
+
+// logSlowQuery logs the query data request if it took longer than the slow query threshold.
+func (m *Manager) logSlowQuery(req *backend.QueryDataRequest, elapsed time.Duration, err error) {
+	threshold := m.Cfg.PluginSlowQueryThreshold
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+
+	refIDs := make([]string, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		refIDs = append(refIDs, q.RefID)
+	}
+
+	ctx := []interface{}{
+		"pluginId", req.PluginContext.PluginID,
+		"orgId", req.PluginContext.OrgID,
+		"duration", elapsed,
+		"queries", len(req.Queries),
+		"refIds", strings.Join(refIDs, ","),
+		"shapeHash", queryShapeHash(req.Queries),
+	}
+	if ds := req.PluginContext.DataSourceInstanceSettings; ds != nil {
+		ctx = append(ctx, "datasourceUid", ds.UID, "datasourceName", ds.Name)
+	}
+	if req.PluginContext.User != nil {
+		ctx = append(ctx, "user", req.PluginContext.User.Login)
+	}
+	if err != nil {
+		ctx = append(ctx, "error", err)
+	}
+
+	slowQueryLogger.Warn("Slow query", ctx...)
+}
+
+// queryShapeHash returns a hash of the shape of the queries, i.e. of their types and of the structure of their
+// JSON models without the values, so that the same queries with e.g. other time ranges have the same hash.
+func queryShapeHash(queries []backend.DataQuery) string {
+	h := fnv.New64a()
+	for _, q := range queries {
+		var model interface{}
+		if err := json.Unmarshal(q.JSON, &model); err != nil {
+			model = nil
+		}
+		delete(modelAsMap(model), "refId")
+		_, _ = fmt.Fprintf(h, "%s|%s;", q.QueryType, jsonShape(model))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func modelAsMap(model interface{}) map[string]interface{} {
+	m, _ := model.(map[string]interface{})
+	return m
+}
+
+// jsonShape returns the structure of the decoded JSON value, with the types of the scalar values.
+func jsonShape(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, k+":"+jsonShape(v[k]))
+		}
+		return "{" + strings.Join(fields, ",") + "}"
+	case []interface{}:
+		// arrays have the shape of their distinct element shapes, whatever their length
+		seen := map[string]bool{}
+		var elems []string
+		for _, e := range v {
+			shape := jsonShape(e)
+			if !seen[shape] {
+				seen[shape] = true
+				elems = append(elems, shape)
+			}
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,29 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryShapeHash(t *testing.T) {
+	query := func(queryType string, json string) []backend.DataQuery {
+		return []backend.DataQuery{{RefID: "A", QueryType: queryType, JSON: []byte(json)}}
+	}
+
+	hash := queryShapeHash(query("", `{"refId":"A","rawSql":"SELECT 1","format":"table","hide":false,"tags":["a","b"]}`))
+
+	t.Run("Should be the same for queries with other values", func(t *testing.T) {
+		require.Equal(t, hash, queryShapeHash(query("", `{"refId":"B","format":"time_series","rawSql":"SELECT 2","hide":true,"tags":["c"]}`)))
+	})
+
+	t.Run("Should differ for queries with another structure", func(t *testing.T) {
+		require.NotEqual(t, hash, queryShapeHash(query("", `{"refId":"A","rawSql":"SELECT 1","format":"table","hide":false}`)))
+		require.NotEqual(t, hash, queryShapeHash(query("", `{"refId":"A","rawSql":"SELECT 1","format":"table","hide":"false","tags":["a"]}`)))
+	})
+
+	t.Run("Should differ for queries of another type", func(t *testing.T) {
+		require.NotEqual(t, hash, queryShapeHash(query("logs", `{"refId":"A","rawSql":"SELECT 1","format":"table","hide":false,"tags":["a","b"]}`)))
+	})
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+var _ backendplugin.StatusReporter = &Manager{}
+
+// PluginStatuses returns the status of each registered backend plugin, with the results of the last health checks
+// of its data sources.
+func (m *Manager) PluginStatuses() map[string]backendplugin.PluginStatus {
+	m.pluginsMu.RLock()
+	plugins := make([]backendplugin.Plugin, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		if !p.IsDecommissioned() {
+			plugins = append(plugins, p)
+		}
+	}
+	m.pluginsMu.RUnlock()
+
+	failures := m.PluginFailures()
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+
+	statuses := make(map[string]backendplugin.PluginStatus, len(plugins))
+	for _, p := range plugins {
+		status := backendplugin.PluginStatus{
+			Managed:     p.IsManaged(),
+			Running:     !p.Exited(),
+			Failures:    failures[p.PluginID()],
+			DataSources: map[string]backendplugin.DataSourceHealth{},
+		}
+		for uid, health := range m.lastHealth[p.PluginID()] {
+			status.DataSources[uid] = health
+		}
+		statuses[p.PluginID()] = status
+	}
+	return statuses
+}
+
+// recordLastHealth records the result of the health check of the data source, if any.
+func (m *Manager) recordLastHealth(pCtx backend.PluginContext, res *backend.CheckHealthResult, err error) {
+	ds := pCtx.DataSourceInstanceSettings
+	if ds == nil || errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return
+	}
+
+	health := backendplugin.DataSourceHealth{
+		Name:      ds.Name,
+		Status:    backend.HealthStatusError,
+		CheckedAt: time.Now(),
+	}
+	switch {
+	case err != nil:
+		health.Message = err.Error()
+	case res != nil:
+		health.Status = res.Status
+		health.Message = res.Message
+	}
+
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+
+	if m.lastHealth == nil {
+		m.lastHealth = map[string]map[string]backendplugin.DataSourceHealth{}
+	}
+	if m.lastHealth[pCtx.PluginID] == nil {
+		m.lastHealth[pCtx.PluginID] = map[string]backendplugin.DataSourceHealth{}
+	}
+	m.lastHealth[pCtx.PluginID][ds.UID] = health
+}
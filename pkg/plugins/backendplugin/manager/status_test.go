@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginStatuses(t *testing.T) {
+	m := &Manager{
+		logger: log.New("test"),
+		plugins: map[string]backendplugin.Plugin{
+			"running": &testPlugin{pluginID: "running", managed: true},
+			"exited":  &testPlugin{pluginID: "exited", managed: true, exited: true},
+			"removed": &testPlugin{pluginID: "removed", managed: true, decommissioned: true},
+		},
+	}
+
+	m.recordRestart("exited")
+	m.recordLastHealth(backend.PluginContext{
+		PluginID:                   "running",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds1", Name: "DS 1"},
+	}, &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Data source is working"}, nil)
+	m.recordLastHealth(backend.PluginContext{
+		PluginID:                   "running",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds2", Name: "DS 2"},
+	}, nil, errors.New("connection refused"))
+	m.recordLastHealth(backend.PluginContext{
+		PluginID:                   "running",
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds3", Name: "DS 3"},
+	}, nil, backendplugin.ErrMethodNotImplemented)
+	m.recordLastHealth(backend.PluginContext{PluginID: "running"}, &backend.CheckHealthResult{Status: backend.HealthStatusOk}, nil)
+
+	statuses := m.PluginStatuses()
+	require.Len(t, statuses, 2)
+
+	running := statuses["running"]
+	require.True(t, running.Managed)
+	require.True(t, running.Running)
+	require.Len(t, running.DataSources, 2)
+	require.Equal(t, backend.HealthStatusOk, running.DataSources["ds1"].Status)
+	require.Equal(t, "Data source is working", running.DataSources["ds1"].Message)
+	require.Equal(t, "DS 2", running.DataSources["ds2"].Name)
+	require.Equal(t, backend.HealthStatusError, running.DataSources["ds2"].Status)
+	require.Equal(t, "connection refused", running.DataSources["ds2"].Message)
+
+	exited := statuses["exited"]
+	require.False(t, exited.Running)
+	require.Equal(t, int64(1), exited.Failures.Restarts)
+	require.Empty(t, exited.DataSources)
+}
@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/services/datasources/tenancy"
+)
+
+// withTenantHeader returns the plugin context with the tenant header of the data source, if it has one, added to
+// the custom HTTP headers of the data source settings, so that the plugin sends it with its requests to the data
+// source. It returns the name and the value of the header, and false if there's none.
+func (m *Manager) withTenantHeader(pCtx backend.PluginContext) (backend.PluginContext, string, string, bool) {
+	ds := pCtx.DataSourceInstanceSettings
+	if ds == nil || m.Cfg == nil {
+		return pCtx, "", "", false
+	}
+
+	jsonData := map[string]interface{}{}
+	if len(ds.JSONData) > 0 {
+		if err := json.Unmarshal(ds.JSONData, &jsonData); err != nil {
+			return pCtx, "", "", false
+		}
+	}
+	name, value, ok := tenancy.Header(m.Cfg, pCtx.OrgID, jsonData)
+	if !ok {
+		return pCtx, "", "", false
+	}
+
+	secureJSONData := make(map[string]string, len(ds.DecryptedSecureJSONData)+1)
+	for k, v := range ds.DecryptedSecureJSONData {
+		secureJSONData[k] = v
+	}
+	tenancy.ApplyToCustomHeaders(m.Cfg, pCtx.OrgID, jsonData, secureJSONData)
+
+	jsonDataBytes, err := json.Marshal(jsonData)
+	if err != nil {
+		m.logger.Warn("Failed to add tenant header to data source settings", "uid", ds.UID, "error", err)
+		return pCtx, "", "", false
+	}
+
+	settings := *ds
+	settings.JSONData = jsonDataBytes
+	settings.DecryptedSecureJSONData = secureJSONData
+	pCtx.DataSourceInstanceSettings = &settings
+	return pCtx, name, value, true
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_withTenantHeader(t *testing.T) {
+	m := &Manager{
+		Cfg:    &setting.Cfg{DataSourceTenants: map[int64]string{2: "tenant-b"}},
+		logger: log.New("test"),
+	}
+
+	t.Run("data source without tenant header", func(t *testing.T) {
+		pCtx := backend.PluginContext{OrgID: 1, DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{"httpHeaderName1":"X-Custom"}`),
+		}}
+
+		result, _, _, ok := m.withTenantHeader(pCtx)
+		require.False(t, ok)
+		require.Same(t, pCtx.DataSourceInstanceSettings, result.DataSourceInstanceSettings)
+	})
+
+	t.Run("data source with mapped tenant", func(t *testing.T) {
+		settings := &backend.DataSourceInstanceSettings{
+			JSONData:                []byte(`{"tenantHeaderName":"X-Scope-OrgID","tenantIdSource":"mapping","httpHeaderName1":"X-Custom"}`),
+			DecryptedSecureJSONData: map[string]string{"httpHeaderValue1": "custom"},
+		}
+		pCtx := backend.PluginContext{OrgID: 2, DataSourceInstanceSettings: settings}
+
+		result, name, value, ok := m.withTenantHeader(pCtx)
+		require.True(t, ok)
+		require.Equal(t, "X-Scope-OrgID", name)
+		require.Equal(t, "tenant-b", value)
+
+		opts, err := result.DataSourceInstanceSettings.HTTPClientOptions()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"X-Custom": "custom", "X-Scope-OrgID": "tenant-b"}, opts.Headers)
+
+		// the settings of the original context are left as is
+		jsonData := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(settings.JSONData, &jsonData))
+		require.NotContains(t, jsonData, "httpHeaderName2")
+		require.Equal(t, map[string]string{"httpHeaderValue1": "custom"}, settings.DecryptedSecureJSONData)
+	})
+}
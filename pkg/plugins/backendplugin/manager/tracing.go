@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/plugins/backendplugin/manager")
+
+// startSpan starts the span of a call to a backend plugin, with the plugin ID, and the data source UID and the
+// organization ID if any, as attributes. The trace context is propagated to the plugin in the gRPC metadata of
+// the returned context.
+func startSpan(ctx context.Context, name string, pluginID string, pCtx *backend.PluginContext) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(tracing.ContextWithOpenTracingParent(ctx), "plugins."+name,
+		trace.WithSpanKind(trace.SpanKindClient))
+
+	span.SetAttributes(attribute.String("plugin_id", pluginID))
+	if pCtx != nil {
+		if pCtx.OrgID != 0 {
+			span.SetAttributes(attribute.Int64("org_id", pCtx.OrgID))
+		}
+		if pCtx.DataSourceInstanceSettings != nil {
+			span.SetAttributes(attribute.String("datasource_uid", pCtx.DataSourceInstanceSettings.UID))
+		}
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for k, v := range carrier {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+
+	return ctx, span
+}
+
+// endSpan ends the span of a call to a backend plugin, which failed if err isn't nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestStartSpan(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05, 0x06},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+
+	pCtx := backend.PluginContext{
+		OrgID:                      1,
+		PluginID:                   testPluginID,
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds-uid"},
+	}
+	ctx, span := startSpan(ctx, "query_data", testPluginID, &pCtx)
+	defer endSpan(span, nil)
+
+	require.Equal(t, parent.TraceID(), span.SpanContext().TraceID())
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	traceparent := md.Get("traceparent")
+	require.Len(t, traceparent, 1)
+	require.Contains(t, traceparent[0], parent.TraceID().String())
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+var _ backendplugin.Upgrader = &Manager{}
+
+const upgradeHealthCheckTimeout = 30 * time.Second
+
+// upgradeDrainPeriod is how long the previous version of an upgraded plugin keeps running after the requests are
+// routed to the new version, so that the requests it's serving complete.
+var upgradeDrainPeriod = 30 * time.Second
+
+// UpgradePlugin starts the managed backend plugin created by the factory alongside the registered one and, once the
+// new plugin answers a health check, routes the requests of the plugin to it and stops the previous one after
+// upgradeDrainPeriod. The plugin is registered and started as usual if it isn't registered yet.
+func (m *Manager) UpgradePlugin(ctx context.Context, pluginID string, factory backendplugin.PluginFactoryFunc) error {
+	previous, registered := m.Get(pluginID)
+	if !registered {
+		return m.RegisterAndStart(ctx, pluginID, factory)
+	}
+
+	m.logger.Info("Upgrading backend plugin", "pluginId", pluginID)
+	env := m.getPluginEnvVars(pluginID)
+	p, err := factory(pluginID, m.logger.New("pluginId", pluginID), env)
+	if err != nil {
+		return err
+	}
+	if !p.IsManaged() {
+		return backendplugin.ErrPluginNotManaged
+	}
+
+	if err := p.Start(ctx); err != nil {
+		m.decommissionAndStop(p)
+		return fmt.Errorf("failed to start new plugin version: %w", err)
+	}
+	if err := checkUpgradeHealth(ctx, p); err != nil {
+		m.decommissionAndStop(p)
+		return err
+	}
+
+	m.pluginsMu.Lock()
+	m.plugins[pluginID] = p
+	if m.factories == nil {
+		m.factories = map[string]backendplugin.PluginFactoryFunc{}
+	}
+	m.factories[pluginID] = factory
+	if m.envs == nil {
+		m.envs = map[string][]string{}
+	}
+	m.envs[pluginID] = env
+	m.pluginsMu.Unlock()
+
+	m.restartKilledProcesses(ctx, p)
+	m.logger.Info("Backend plugin upgraded", "pluginId", pluginID)
+
+	// decommissioning stops restarting the previous process, which keeps serving the requests it received until
+	// it's stopped
+	if err := previous.Decommission(); err != nil {
+		return err
+	}
+	time.AfterFunc(upgradeDrainPeriod, func() {
+		if err := previous.Stop(context.Background()); err != nil {
+			previous.Logger().Error("Failed to stop previous plugin version", "error", err)
+		}
+	})
+	return nil
+}
+
+// checkUpgradeHealth checks the health of the new version of a plugin with a plugin context without settings. Only
+// errors of the call fail the check, a health check reporting an error is expected without settings.
+func checkUpgradeHealth(ctx context.Context, p backendplugin.Plugin) error {
+	if p.Exited() {
+		return errors.New("new plugin version exited")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, upgradeHealthCheckTimeout)
+	defer cancel()
+	_, err := p.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: backend.PluginContext{PluginID: p.PluginID()}})
+	if err != nil && !errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return fmt.Errorf("new plugin version health check failed: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) decommissionAndStop(p backendplugin.Plugin) {
+	if err := p.Decommission(); err != nil {
+		p.Logger().Error("Failed to decommission plugin", "error", err)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		p.Logger().Error("Failed to stop plugin", "error", err)
+	}
+}
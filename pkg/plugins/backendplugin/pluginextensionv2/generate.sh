@@ -13,4 +13,4 @@ DIR="$( cd -P "$( dirname "$SOURCE" )" && pwd )"
 
 cd "$DIR"
 
-protoc -I ./ rendererv2.proto --go_out=plugins=grpc:./
\ No newline at end of file
+protoc -I ./ rendererv2.proto secretsmanager.proto --go_out=plugins=grpc:./
\ No newline at end of file
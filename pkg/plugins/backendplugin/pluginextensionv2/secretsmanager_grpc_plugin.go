@@ -0,0 +1,31 @@
+package pluginextensionv2
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+type SecretsManagerPlugin interface {
+	SecretsManagerClient
+}
+
+type SecretsManagerGRPCPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+}
+
+func (p *SecretsManagerGRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	return nil
+}
+
+func (p *SecretsManagerGRPCPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &SecretsManagerGRPCClient{NewSecretsManagerClient(c)}, nil
+}
+
+type SecretsManagerGRPCClient struct {
+	SecretsManagerClient
+}
+
+var _ SecretsManagerClient = &SecretsManagerGRPCClient{}
+var _ plugin.GRPCPlugin = &SecretsManagerGRPCPlugin{}
@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidPluginBackup is returned when restoring an archive which isn't a plugin backup.
+var ErrInvalidPluginBackup = errors.New("invalid plugin backup")
+
+// PluginBackup is the manifest of a backup of the installed external plugins and of the plugin settings of all
+// organizations. The secure JSON data of the plugin settings isn't part of backups.
+type PluginBackup struct {
+	GrafanaVersion string                `json:"grafanaVersion"`
+	Created        time.Time             `json:"created"`
+	Plugins        []PluginBackupPlugin  `json:"plugins"`
+	Settings       []PluginBackupSetting `json:"settings"`
+}
+
+// PluginBackupPlugin is an installed external plugin of a backup.
+type PluginBackupPlugin struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// PluginBackupSetting is the plugin setting of an organization of a backup.
+type PluginBackupSetting struct {
+	OrgID         int64                  `json:"orgId"`
+	PluginID      string                 `json:"pluginId"`
+	Enabled       bool                   `json:"enabled"`
+	Pinned        bool                   `json:"pinned"`
+	JSONData      map[string]interface{} `json:"jsonData"`
+	PluginVersion string                 `json:"version"`
+}
+
+// PluginRestoreStatus is the outcome of the restoration of a plugin of a backup.
+type PluginRestoreStatus string
+
+const (
+	PluginRestoreInstalled PluginRestoreStatus = "installed"
+	PluginRestoreSkipped   PluginRestoreStatus = "skipped"
+	PluginRestoreFailed    PluginRestoreStatus = "failed"
+)
+
+// PluginRestoreResult is the outcome of the restoration of a plugin of a backup.
+type PluginRestoreResult struct {
+	PluginID string              `json:"pluginId"`
+	Version  string              `json:"version"`
+	Status   PluginRestoreStatus `json:"status"`
+	Message  string              `json:"message,omitempty"`
+}
+
+// PluginRestoreReport is the result of the restoration of a plugin backup.
+type PluginRestoreReport struct {
+	Plugins []PluginRestoreResult `json:"plugins"`
+	// RestoredSettings is the number of plugin settings restored, and SkippedSettings the number of plugin settings
+	// of organizations missing from the instance.
+	RestoredSettings int `json:"restoredSettings"`
+	SkippedSettings  int `json:"skippedSettings"`
+}
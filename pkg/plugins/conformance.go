@@ -0,0 +1,44 @@
+package plugins
+
+// PluginConformanceStatus is the outcome of a check of the conformance suite.
+type PluginConformanceStatus string
+
+const (
+	PluginConformancePassed PluginConformanceStatus = "passed"
+	PluginConformanceFailed PluginConformanceStatus = "failed"
+	// PluginConformanceSkipped checks are the ones of the capabilities the plugin doesn't implement.
+	PluginConformanceSkipped PluginConformanceStatus = "skipped"
+)
+
+// The checks of the conformance suite run against backend plugins.
+const (
+	// PluginConformanceCheckHealth checks that health checks return a known status and JSON details.
+	PluginConformanceCheckHealth = "health"
+	// PluginConformanceCheckQueryData checks that queries are answered by reference ID with well-formed data frames.
+	PluginConformanceCheckQueryData = "queryData"
+	// PluginConformanceCheckResources checks that resource calls are answered with a valid HTTP status, and that
+	// unknown resources aren't found.
+	PluginConformanceCheckResources = "resources"
+	// PluginConformanceCheckStreams checks that subscriptions to unknown streams are rejected, and that running
+	// streams stop when their context is canceled.
+	PluginConformanceCheckStreams = "streams"
+)
+
+// PluginConformanceResult is the result of a check of the conformance suite.
+type PluginConformanceResult struct {
+	Check   string                  `json:"check"`
+	Status  PluginConformanceStatus `json:"status"`
+	Message string                  `json:"message,omitempty"`
+	// Duration is the duration of the check in milliseconds.
+	Duration float64 `json:"duration"`
+}
+
+// PluginConformanceReport is the result of the conformance suite run against a backend plugin. The plugin is
+// compatible with the Grafana version if none of the checks failed.
+type PluginConformanceReport struct {
+	PluginID       string                    `json:"pluginId"`
+	PluginVersion  string                    `json:"pluginVersion"`
+	GrafanaVersion string                    `json:"grafanaVersion"`
+	Compatible     bool                      `json:"compatible"`
+	Results        []PluginConformanceResult `json:"results"`
+}
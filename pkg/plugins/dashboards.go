@@ -14,4 +14,55 @@ type PluginDashboardInfoDTO struct {
 	Description      string `json:"description"`
 	Path             string `json:"path"`
 	Removed          bool   `json:"removed"`
+	// Pinned is true if the imported dashboard is excluded from the automatic updates of the plugin dashboards.
+	Pinned bool `json:"pinned"`
+}
+
+// DashboardImportPreview describes what importing a dashboard would create or overwrite.
+type DashboardImportPreview struct {
+	PluginId    string `json:"pluginId"`
+	Path        string `json:"path"`
+	Title       string `json:"title"`
+	Uid         string `json:"uid"`
+	FolderId    int64  `json:"folderId"`
+	FolderTitle string `json:"folderTitle"`
+	// CreateFolder is true if the folder doesn't exist yet and would be created by the import.
+	CreateFolder bool `json:"createFolder"`
+	// Action is what the import would do, i.e. DashboardImportCreate, DashboardImportOverwrite or
+	// DashboardImportConflict if the import would fail.
+	Action   string `json:"action"`
+	Conflict string `json:"conflict,omitempty"`
+	// Existing is the dashboard that would be overwritten or that the import conflicts with, if known.
+	Existing    *DashboardImportExisting    `json:"existing,omitempty"`
+	DataSources []DashboardImportDataSource `json:"dataSources"`
+}
+
+const (
+	DashboardImportCreate    = "create"
+	DashboardImportOverwrite = "overwrite"
+	DashboardImportConflict  = "conflict"
+)
+
+type DashboardImportExisting struct {
+	Id       int64  `json:"id"`
+	Uid      string `json:"uid"`
+	Title    string `json:"title"`
+	Url      string `json:"url"`
+	FolderId int64  `json:"folderId"`
+	PluginId string `json:"pluginId"`
+	Version  int    `json:"version"`
+}
+
+// DashboardImportDataSource describes the data source a data source input of the dashboard would be bound to.
+type DashboardImportDataSource struct {
+	Input    string `json:"input"`
+	PluginId string `json:"pluginId"`
+	Value    string `json:"value"`
+	// Found is false if no data source of the organization matches the value of the input.
+	Found          bool   `json:"found"`
+	DataSourceUid  string `json:"dataSourceUid,omitempty"`
+	DataSourceName string `json:"dataSourceName,omitempty"`
+	DataSourceType string `json:"dataSourceType,omitempty"`
+	// TypeMismatch is true if the data source isn't of the type expected by the input.
+	TypeMismatch bool `json:"typeMismatch"`
 }
@@ -3,10 +3,8 @@ package plugins
 import (
 	"context"
 	"encoding/json"
-	"path/filepath"
 
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
-	"github.com/grafana/grafana/pkg/plugins/backendplugin/grpcplugin"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -38,9 +36,7 @@ func (p *DataSourcePlugin) Load(decoder *json.Decoder, base *PluginBase, backend
 	}
 
 	if p.Backend {
-		cmd := ComposePluginStartCommand(p.Executable)
-		fullpath := filepath.Join(base.PluginDir, cmd)
-		factory := grpcplugin.NewBackendPlugin(p.Id, fullpath)
+		factory := newBackendPluginFactory(base, p.Id, p.Executable)
 		if err := backendPluginManager.RegisterAndStart(context.Background(), p.Id, factory); err != nil {
 			return nil, errutil.Wrapf(err, "failed to register backend plugin")
 		}
@@ -0,0 +1,85 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// DependencyStatus describes whether the dependencies of a plugin, as declared in its plugin.json,
+// are satisfied by the running Grafana instance and the installed plugins.
+type DependencyStatus struct {
+	GrafanaVersionSatisfied bool                   `json:"grafanaVersionSatisfied"`
+	UnsatisfiedPlugins      []PluginDependencyItem `json:"unsatisfiedPlugins"`
+}
+
+// CheckDependencies checks the dependencies of a plugin against the Grafana version and the installed
+// plugins, returned by getPlugin. Version requirements that can't be parsed are considered satisfied.
+func CheckDependencies(plugin *PluginBase, grafanaVersion string, getPlugin func(id string) *PluginBase) DependencyStatus {
+	status := DependencyStatus{
+		GrafanaVersionSatisfied: IsGrafanaVersionCompatible(plugin.Dependencies.GrafanaVersion, grafanaVersion),
+		UnsatisfiedPlugins:      []PluginDependencyItem{},
+	}
+
+	for _, dep := range plugin.Dependencies.Plugins {
+		installed := getPlugin(dep.Id)
+		if installed == nil || !versionSatisfies(installed.Info.Version, minVersionConstraint(dep.Version)) {
+			status.UnsatisfiedPlugins = append(status.UnsatisfiedPlugins, dep)
+		}
+	}
+
+	return status
+}
+
+// IsGrafanaVersionCompatible returns whether the Grafana version is in the range of the dependencies.grafanaVersion
+// of a plugin. Ranges and versions that can't be parsed, such as the ones of development builds, are considered
+// compatible.
+func IsGrafanaVersionCompatible(grafanaVersionRange, grafanaVersion string) bool {
+	return versionSatisfies(grafanaVersion, grafanaVersionRange)
+}
+
+// IncompatibleGrafanaVersionError is returned when installing a plugin which declares it isn't compatible with the
+// Grafana version, and incompatible plugins are blocked.
+type IncompatibleGrafanaVersionError struct {
+	PluginID       string
+	GrafanaVersion string
+}
+
+func (e IncompatibleGrafanaVersionError) Error() string {
+	return fmt.Sprintf("plugin '%s' is not compatible with Grafana %s", e.PluginID, e.GrafanaVersion)
+}
+
+// minVersionConstraint turns the version of a plugin dependency into a constraint. Dependencies declared
+// with a plain version, as most plugins do, require at least that version.
+func minVersionConstraint(v string) string {
+	if _, err := semver.NewVersion(v); err == nil {
+		return ">=" + v
+	}
+	return v
+}
+
+func versionSatisfies(v string, constraint string) bool {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" {
+		return true
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return true
+	}
+
+	parsed, err := semver.NewVersion(v)
+	if err != nil {
+		return true
+	}
+
+	// pre-releases of Grafana and plugins satisfy the constraints of the version they lead up to
+	release, err := parsed.SetPrerelease("")
+	if err != nil {
+		return true
+	}
+
+	return c.Check(&release)
+}
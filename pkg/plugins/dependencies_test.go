@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	installed := map[string]*PluginBase{
+		"grafana-piechart-panel": {Id: "grafana-piechart-panel", Info: PluginInfo{Version: "1.6.2"}},
+	}
+	getPlugin := func(id string) *PluginBase {
+		return installed[id]
+	}
+
+	t.Run("Grafana version", func(t *testing.T) {
+		for _, tc := range []struct {
+			constraint string
+			version    string
+			satisfied  bool
+		}{
+			{constraint: "", version: "8.2.0", satisfied: true},
+			{constraint: "*", version: "8.2.0", satisfied: true},
+			{constraint: ">=7.0.0", version: "8.2.0", satisfied: true},
+			{constraint: ">=8.3.0", version: "8.2.0", satisfied: false},
+			{constraint: "8.x.x", version: "8.2.0", satisfied: true},
+			{constraint: "7.x", version: "8.2.0", satisfied: false},
+			{constraint: ">=8.3.0", version: "8.3.0-pre", satisfied: true},
+			{constraint: ">=8.3.0", version: "dev", satisfied: true},
+		} {
+			p := &PluginBase{Dependencies: PluginDependencies{GrafanaVersion: tc.constraint}}
+			status := CheckDependencies(p, tc.version, getPlugin)
+			assert.Equal(t, tc.satisfied, status.GrafanaVersionSatisfied, "constraint %q with version %q", tc.constraint, tc.version)
+		}
+	})
+
+	t.Run("Plugins", func(t *testing.T) {
+		p := &PluginBase{Dependencies: PluginDependencies{Plugins: []PluginDependencyItem{
+			{Id: "grafana-piechart-panel", Version: "1.0.0"},
+			{Id: "grafana-piechart-panel", Version: "2.0.0"},
+			{Id: "grafana-worldmap-panel", Version: "0.3.2"},
+		}}}
+
+		status := CheckDependencies(p, "8.2.0", getPlugin)
+		assert.True(t, status.GrafanaVersionSatisfied)
+		assert.Equal(t, []PluginDependencyItem{
+			{Id: "grafana-piechart-panel", Version: "2.0.0"},
+			{Id: "grafana-worldmap-panel", Version: "0.3.2"},
+		}, status.UnsatisfiedPlugins)
+	})
+}
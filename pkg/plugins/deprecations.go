@@ -0,0 +1,74 @@
+package plugins
+
+import "bytes"
+
+// PluginDeprecation is a deprecated frontend API of Grafana, with the timeline of its removal.
+type PluginDeprecation struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// DeprecatedSince is the Grafana version the API is deprecated since.
+	DeprecatedSince string `json:"deprecatedSince"`
+	// RemovedIn is the Grafana version the API is planned to be removed in.
+	RemovedIn string `json:"removedIn"`
+	// patterns are the strings of the module of a plugin showing it relies on the API.
+	patterns [][]byte
+}
+
+var (
+	// DeprecationAngular is the deprecation of the AngularJS based plugins.
+	DeprecationAngular = PluginDeprecation{
+		ID:              "angular",
+		Description:     "The plugin is based on AngularJS, which is deprecated in favor of React.",
+		DeprecatedSince: "8.0.0",
+		RemovedIn:       "10.0.0",
+		patterns: [][]byte{
+			[]byte("PanelCtrl"), []byte("QueryCtrl"), []byte("ConfigCtrl"), []byte("AnnotationsQueryCtrl"),
+			[]byte("app/plugins/sdk"), []byte("angular.module("),
+		},
+	}
+	// DeprecationLegacyImports is the deprecation of the imports of the internal modules of Grafana.
+	DeprecationLegacyImports = PluginDeprecation{
+		ID:              "legacyImports",
+		Description:     "The plugin imports internal Grafana modules, e.g. app/core, instead of the @grafana packages.",
+		DeprecatedSince: "7.0.0",
+		RemovedIn:       "10.0.0",
+		patterns: [][]byte{
+			[]byte(`"app/core/`), []byte(`'app/core/`), []byte(`"app/features/`), []byte(`'app/features/`),
+		},
+	}
+
+	pluginDeprecations = []PluginDeprecation{DeprecationAngular, DeprecationLegacyImports}
+)
+
+// DetectDeprecations returns the deprecated frontend APIs the module of a plugin, i.e. its module.js, relies on.
+func DetectDeprecations(module []byte) []PluginDeprecation {
+	var detected []PluginDeprecation
+	for _, d := range pluginDeprecations {
+		for _, p := range d.patterns {
+			if bytes.Contains(module, p) {
+				detected = append(detected, d)
+				break
+			}
+		}
+	}
+	return detected
+}
+
+// HasDeprecation returns whether the deprecation is in the list.
+func HasDeprecation(deprecations []PluginDeprecation, id string) bool {
+	for _, d := range deprecations {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginDeprecationReport is an installed plugin relying on deprecated frontend APIs.
+type PluginDeprecationReport struct {
+	PluginID     string              `json:"pluginId"`
+	Name         string              `json:"name"`
+	Type         string              `json:"type"`
+	Version      string              `json:"version"`
+	Deprecations []PluginDeprecation `json:"deprecations"`
+}
@@ -0,0 +1,28 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDeprecations(t *testing.T) {
+	for _, tc := range []struct {
+		module   string
+		expected []string
+	}{
+		{module: `define(["@grafana/data","react"],function(e,t){})`, expected: nil},
+		{module: `define(["app/plugins/sdk"],function(e){var t=function(e){e.prototype=Object.create(PanelCtrl)}})`, expected: []string{"angular"}},
+		{module: `define(["@grafana/data","app/core/utils/kbn"],function(e,t){})`, expected: []string{"legacyImports"}},
+		{module: `System.register(['app/core/core', 'app/plugins/sdk'], function(e) {})`, expected: []string{"angular", "legacyImports"}},
+	} {
+		var ids []string
+		for _, d := range DetectDeprecations([]byte(tc.module)) {
+			ids = append(ids, d.ID)
+		}
+		assert.Equal(t, tc.expected, ids, tc.module)
+	}
+
+	assert.True(t, HasDeprecation([]PluginDeprecation{DeprecationAngular}, "angular"))
+	assert.False(t, HasDeprecation([]PluginDeprecation{DeprecationLegacyImports}, "angular"))
+}
@@ -2,15 +2,19 @@ package plugins
 
 import (
 	"context"
+	"io"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 )
 
 // Manager is the plugin manager service interface.
 type Manager interface {
-	// Renderer gets the renderer plugin.
+	// Renderer gets the renderer plugin, or the remote rendering service if no renderer plugin is installed.
 	Renderer() *RendererPlugin
+	// SecretsManager gets the secrets manager plugin.
+	SecretsManager() *SecretsManagerPlugin
 	// GetDataSource gets a data source plugin with a certain ID.
 	GetDataSource(id string) *DataSourcePlugin
 	// GetPlugin gets a plugin with a certain ID.
@@ -47,14 +51,39 @@ type Manager interface {
 	ImportDashboard(pluginID, path string, orgID, folderID int64, dashboardModel *simplejson.Json,
 		overwrite bool, inputs []ImportDashboardInput, user *models.SignedInUser,
 		requestHandler DataRequestHandler) (PluginDashboardInfoDTO, *models.Dashboard, error)
+	// PreviewImportDashboard returns what importing a dashboard would create or overwrite, without importing it.
+	PreviewImportDashboard(ctx context.Context, pluginID, path string, orgID, folderID int64,
+		dashboardModel *simplejson.Json, overwrite bool, inputs []ImportDashboardInput) (DashboardImportPreview, error)
 	// ScanningErrors returns plugin scanning errors encountered.
 	ScanningErrors() []PluginError
+	// DeprecationReport returns the installed plugins relying on deprecated frontend APIs.
+	DeprecationReport() []PluginDeprecationReport
+	// StartupProfile returns the time each plugin took to start up, by phase, slowest first.
+	StartupProfile() []PluginStartupProfile
+	// SignatureDetails verifies the signature of a plugin, and returns the details of its manifest and the result of
+	// the verification of its files.
+	SignatureDetails(pluginID string) (PluginSignatureDetails, error)
+	// ValidateArchive checks the plugins of a plugin archive like they are checked when loaded, without loading
+	// them.
+	ValidateArchive(ctx context.Context, archivePath string) (PluginLintReport, error)
+	// RunConformanceSuite runs the conformance suite against the backend of the plugin of the plugin context, and
+	// returns whether the plugin is compatible with the running Grafana version.
+	RunConformanceSuite(ctx context.Context, pCtx backend.PluginContext) (PluginConformanceReport, error)
+	// BackupPlugins writes a backup of the installed external plugins and of the plugin settings to w.
+	BackupPlugins(ctx context.Context, w io.Writer) error
+	// RestorePlugins installs the plugins of a backup which aren't installed yet, and restores the plugin settings.
+	RestorePlugins(ctx context.Context, archivePath string) (PluginRestoreReport, error)
 	// LoadPluginDashboard loads a plugin dashboard.
 	LoadPluginDashboard(pluginID, path string) (*models.Dashboard, error)
 	// IsAppInstalled returns whether an app is installed.
 	IsAppInstalled(id string) bool
 	// Install installs a plugin.
 	Install(ctx context.Context, pluginID, version string) error
+	// InstallReport returns the security report of the inspection of the archive of the last install of a plugin,
+	// if any.
+	InstallReport(pluginID string) (PluginArchiveReport, bool)
+	// DiskUsage returns the disk usage of the plugins installed in the plugins directory, as of their last check.
+	DiskUsage() []PluginDiskUsage
 	// Uninstall uninstalls a plugin.
 	Uninstall(ctx context.Context, pluginID string) error
 }
@@ -79,6 +108,8 @@ type PluginInstaller interface {
 	Uninstall(ctx context.Context, pluginPath string) error
 	// GetUpdateInfo returns update information if the requested plugin is supported on the running system.
 	GetUpdateInfo(pluginID, version, pluginRepoURL string) (UpdateInfo, error)
+	// ArchiveReport returns the security report of the inspection of the last archive of the plugin, if any.
+	ArchiveReport(pluginID string) (PluginArchiveReport, bool)
 }
 
 type PluginInstallerLogger interface {
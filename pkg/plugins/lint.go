@@ -0,0 +1,43 @@
+package plugins
+
+// PluginLintSeverity is the severity of a lint result: plugins with errors aren't loaded, or not as intended.
+type PluginLintSeverity string
+
+const (
+	PluginLintError   PluginLintSeverity = "error"
+	PluginLintWarning PluginLintSeverity = "warning"
+)
+
+// Rules of the lint results of plugin archives.
+const (
+	PluginLintRuleArchive           = "archive"
+	PluginLintRuleManifest          = "manifest"
+	PluginLintRuleModule            = "module"
+	PluginLintRuleLogos             = "logos"
+	PluginLintRuleScreenshots       = "screenshots"
+	PluginLintRuleIncludes          = "includes"
+	PluginLintRuleSignature         = "signature"
+	PluginLintRuleExecutable        = "executable"
+	PluginLintRuleGrafanaDependency = "grafanaDependency"
+	PluginLintRuleDeprecation       = "deprecation"
+	PluginLintRuleRoles             = "roles"
+	PluginLintRuleLiveChannels      = "liveChannels"
+)
+
+// PluginLintResult is a problem found in a plugin archive.
+type PluginLintResult struct {
+	Rule     string             `json:"rule"`
+	Severity PluginLintSeverity `json:"severity"`
+	PluginID string             `json:"pluginId,omitempty"`
+	// Path is the path of the file of the problem in the archive, if any.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// PluginLintReport is the result of the validation of a plugin archive. The archive is valid if there are no errors.
+type PluginLintReport struct {
+	Valid bool `json:"valid"`
+	// Plugins are the IDs of the plugins found in the archive.
+	Plugins []string           `json:"plugins"`
+	Results []PluginLintResult `json:"results"`
+}
@@ -0,0 +1,62 @@
+package plugins
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// PluginLiveChannel is a rule of the Live channels of a plugin declared in its plugin.json, with the access required
+// to subscribe and to publish to the channels matching its path. When a plugin declares Live channels, only the
+// subscriptions and publications matching one of them are forwarded to the plugin, for its channels and the channels
+// of its data sources.
+type PluginLiveChannel struct {
+	// Path is the pattern of the channel path, within the namespace of the plugin or the data source, using the syntax
+	// of path.Match, e.g. "stream/*".
+	Path string `json:"path"`
+	// Subscribe is the access required to subscribe to the channels, nil if they can't be subscribed to.
+	Subscribe *PluginLiveChannelAccess `json:"subscribe,omitempty"`
+	// Publish is the access required to publish to the channels, nil if the clients can't publish to them.
+	Publish *PluginLiveChannelAccess `json:"publish,omitempty"`
+}
+
+// PluginLiveChannelAccess is the access required to subscribe or publish to Live channels of a plugin.
+type PluginLiveChannelAccess struct {
+	// Role is the role required, any role when empty.
+	Role models.RoleType `json:"role,omitempty"`
+	// Action is the access control action required instead of the role, when access control is enabled.
+	Action string `json:"action,omitempty"`
+}
+
+// MatchLiveChannel returns the first of the Live channels matching the path of a channel, or nil. The path is cleaned
+// first, so that relative elements can't be used to escape a rule.
+func MatchLiveChannel(channels []*PluginLiveChannel, channelPath string) *PluginLiveChannel {
+	channelPath = strings.TrimPrefix(path.Clean("/"+channelPath), "/")
+	for _, channel := range channels {
+		if ok, err := path.Match(strings.TrimPrefix(channel.Path, "/"), channelPath); err == nil && ok {
+			return channel
+		}
+	}
+	return nil
+}
+
+// ValidateLiveChannels returns an error if one of the Live channels declared by the plugin has no path, an invalid
+// pattern, or requires an unknown role.
+func ValidateLiveChannels(channels []*PluginLiveChannel) error {
+	for i, channel := range channels {
+		if channel == nil || channel.Path == "" {
+			return fmt.Errorf("live channel %d has no path", i)
+		}
+		if _, err := path.Match(channel.Path, ""); err != nil {
+			return fmt.Errorf("live channel %q has an invalid path: %w", channel.Path, err)
+		}
+		for _, access := range []*PluginLiveChannelAccess{channel.Subscribe, channel.Publish} {
+			if access != nil && access.Role != "" && !access.Role.IsValid() {
+				return fmt.Errorf("live channel %q requires an invalid role %q", channel.Path, access.Role)
+			}
+		}
+	}
+	return nil
+}
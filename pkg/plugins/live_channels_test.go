@@ -0,0 +1,48 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLiveChannel(t *testing.T) {
+	channels := []*PluginLiveChannel{
+		{Path: "stream/*", Subscribe: &PluginLiveChannelAccess{}},
+		{Path: "/chat", Publish: &PluginLiveChannelAccess{Role: models.ROLE_EDITOR}},
+	}
+
+	tcs := []struct {
+		path     string
+		expected *PluginLiveChannel
+	}{
+		{path: "stream/cpu", expected: channels[0]},
+		{path: "stream/cpu/1"},
+		{path: "stream"},
+		{path: "chat", expected: channels[1]},
+		{path: "stream/../chat", expected: channels[1]},
+		{path: "other"},
+	}
+	for _, tc := range tcs {
+		require.Equal(t, tc.expected, MatchLiveChannel(channels, tc.path), tc.path)
+	}
+
+	require.Nil(t, MatchLiveChannel(nil, "stream/cpu"))
+}
+
+func TestValidateLiveChannels(t *testing.T) {
+	require.NoError(t, ValidateLiveChannels(nil))
+	require.NoError(t, ValidateLiveChannels([]*PluginLiveChannel{
+		{Path: "stream/*", Subscribe: &PluginLiveChannelAccess{Role: models.ROLE_VIEWER}},
+	}))
+
+	for _, channels := range [][]*PluginLiveChannel{
+		{nil},
+		{{Path: ""}},
+		{{Path: "stream/["}},
+		{{Path: "stream/*", Publish: &PluginLiveChannelAccess{Role: "Owner"}}},
+	} {
+		require.Error(t, ValidateLiveChannels(channels))
+	}
+}
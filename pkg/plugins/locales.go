@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocalesDir is the directory of the translations of a plugin, with a directory per locale, e.g. locales/fr-FR, holding
+// a JSON file per namespace, e.g. locales/fr-FR/panel.json.
+const LocalesDir = "locales"
+
+// DefaultLocale is the locale served when none of the locales accepted by the browser is available, if the plugin has
+// translations for it.
+const DefaultLocale = "en-US"
+
+var reLocale = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// DiscoverLocales returns the locales the plugin in the directory has translations for, i.e. the directories of its
+// locales directory named after a language tag and holding JSON files, sorted.
+func DiscoverLocales(pluginDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(pluginDir, LocalesDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var locales []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !reLocale.MatchString(entry.Name()) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(pluginDir, LocalesDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+				locales = append(locales, entry.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+// NegotiateLocale returns the locale of the plugin matching best the Accept-Language header, by preference: the
+// locales accepted by the browser, exactly or by language, e.g. fr-FR for fr-CA, then the default locale, English, and
+// the first locale. It returns an empty string if the plugin has no locales.
+func NegotiateLocale(acceptLanguage string, locales []string) string {
+	if len(locales) == 0 {
+		return ""
+	}
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+		if locale := matchLocale(tag, locales); locale != "" {
+			return locale
+		}
+	}
+	for _, tag := range []string{DefaultLocale, "en"} {
+		if locale := matchLocale(tag, locales); locale != "" {
+			return locale
+		}
+	}
+	return locales[0]
+}
+
+// matchLocale returns the locale equal to the language tag, regardless of case, or else the first locale of the
+// same language.
+func matchLocale(tag string, locales []string) string {
+	for _, locale := range locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+	language := localeLanguage(tag)
+	for _, locale := range locales {
+		if strings.EqualFold(localeLanguage(locale), language) {
+			return locale
+		}
+	}
+	return ""
+}
+
+func localeLanguage(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// parseAcceptLanguage returns the language tags of the Accept-Language header, by decreasing quality, leaving out
+// the rejected ones.
+func parseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+		if quality > 0 {
+			tags = append(tags, weightedTag{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverLocales(t *testing.T) {
+	t.Run("Should discover the locales with translations", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, file := range []string{"fr-FR/panel.json", "en-US/panel.json", "de/README.md", "not a locale/panel.json"} {
+			path := filepath.Join(dir, LocalesDir, file)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte("{}"), 0600))
+		}
+
+		locales, err := DiscoverLocales(dir)
+		require.NoError(t, err)
+		require.Equal(t, []string{"en-US", "fr-FR"}, locales)
+	})
+
+	t.Run("Should return no locales without locales directory", func(t *testing.T) {
+		locales, err := DiscoverLocales(t.TempDir())
+		require.NoError(t, err)
+		require.Empty(t, locales)
+	})
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	locales := []string{"de-DE", "en-US", "fr-FR", "pt-BR", "pt-PT"}
+
+	for _, tc := range []struct {
+		acceptLanguage string
+		locales        []string
+		expected       string
+	}{
+		{acceptLanguage: "fr-FR", locales: locales, expected: "fr-FR"},
+		{acceptLanguage: "pt-pt", locales: locales, expected: "pt-PT"},
+		{acceptLanguage: "fr-CA,fr;q=0.9", locales: locales, expected: "fr-FR"},
+		{acceptLanguage: "es;q=0.9,de;q=0.8,pt-PT;q=0.95", locales: locales, expected: "pt-PT"},
+		{acceptLanguage: "fr;q=0,de", locales: locales, expected: "de-DE"},
+		{acceptLanguage: "es", locales: locales, expected: "en-US"},
+		{acceptLanguage: "", locales: locales, expected: "en-US"},
+		{acceptLanguage: "es", locales: []string{"ja-JP", "ko-KR"}, expected: "ja-JP"},
+		{acceptLanguage: "fr", locales: nil, expected: ""},
+	} {
+		require.Equal(t, tc.expected, NegotiateLocale(tc.acceptLanguage, tc.locales), tc.acceptLanguage)
+	}
+}
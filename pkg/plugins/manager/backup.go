@@ -0,0 +1,483 @@
+package manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// pluginBackupManifest is the name of the manifest of plugin backups.
+	pluginBackupManifest = "backup.json"
+	// pluginBackupPluginsDir is the directory of the zip archives of the plugins in plugin backups.
+	pluginBackupPluginsDir = "plugins"
+	// maxPluginBackupManifestSize bounds the size of the manifest read from plugin backups.
+	maxPluginBackupManifestSize = 32 << 20
+)
+
+var backupLog = log.New("plugins.backup")
+
+// BackupPlugins writes a backup of the installed external plugins and of the plugin settings of all organizations,
+// as a gzipped tarball, to w.
+func (pm *PluginManager) BackupPlugins(ctx context.Context, w io.Writer) error {
+	return WritePluginBackup(ctx, pm.Cfg, pm.SQLStore, w)
+}
+
+// RestorePlugins restores a plugin backup written by BackupPlugins, and loads the restored plugins.
+func (pm *PluginManager) RestorePlugins(ctx context.Context, archivePath string) (plugins.PluginRestoreReport, error) {
+	report, err := RestorePluginBackup(ctx, pm.Cfg, pm.SQLStore, pm.pluginInstaller, archivePath)
+	if err != nil {
+		return report, err
+	}
+
+	if err := pm.initExternalPlugins(); err != nil {
+		return report, err
+	}
+	pm.log.Info("Restored plugin backup", "plugins", len(report.Plugins), "settings", report.RestoredSettings)
+	return report, nil
+}
+
+// WritePluginBackup writes a backup of the plugins installed in the plugins directory and of the plugin settings of
+// all organizations, as a gzipped tarball, to w. Each plugin is stored as a zip archive, which can be installed with
+// the plugin installer.
+func WritePluginBackup(ctx context.Context, cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, w io.Writer) error {
+	installed, err := installedPlugins(cfg.PluginsPath)
+	if err != nil {
+		return errutil.Wrap("failed to list the installed plugins", err)
+	}
+	settings, err := sqlStore.GetAllPluginSettings()
+	if err != nil {
+		return errutil.Wrap("failed to get the plugin settings", err)
+	}
+
+	backup := plugins.PluginBackup{
+		GrafanaVersion: cfg.BuildVersion,
+		Created:        time.Now(),
+		Plugins:        make([]plugins.PluginBackupPlugin, 0, len(installed)),
+		Settings:       make([]plugins.PluginBackupSetting, 0, len(settings)),
+	}
+	dirs := make([]string, 0, len(installed))
+	for dir := range installed {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		backup.Plugins = append(backup.Plugins, plugins.PluginBackupPlugin{
+			ID:      installed[dir].Id,
+			Version: installed[dir].Info.Version,
+		})
+	}
+	for _, s := range settings {
+		backup.Settings = append(backup.Settings, plugins.PluginBackupSetting{
+			OrgID:         s.OrgId,
+			PluginID:      s.PluginId,
+			Enabled:       s.Enabled,
+			Pinned:        s.Pinned,
+			JSONData:      s.JsonData,
+			PluginVersion: s.PluginVersion,
+		})
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, pluginBackupManifest, data); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writePluginBackupArchive(tw, installed[dir].Id, dir); err != nil {
+			return errutil.Wrapf(err, "failed to back up plugin '%s'", installed[dir].Id)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// RestorePluginBackup installs the plugins of a plugin backup which aren't installed yet with the plugin installer,
+// and restores the plugin settings of the organizations of the instance. The signature of the restored plugins is
+// validated like the one of the plugins installed from the catalog: the plugins failing validation are uninstalled.
+// The plugin settings keep their secure JSON data, if any.
+func RestorePluginBackup(ctx context.Context, cfg *setting.Cfg, sqlStore *sqlstore.SQLStore,
+	installer plugins.PluginInstaller, archivePath string) (plugins.PluginRestoreReport, error) {
+	report := plugins.PluginRestoreReport{Plugins: []plugins.PluginRestoreResult{}}
+
+	tmpDir, err := os.MkdirTemp("", "plugin-backup-*")
+	if err != nil {
+		return report, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			backupLog.Warn("Failed to remove plugin backup directory", "path", tmpDir, "error", err)
+		}
+	}()
+
+	backup, err := readPluginBackup(archivePath, tmpDir)
+	if err != nil {
+		return report, err
+	}
+	installed, err := installedPlugins(cfg.PluginsPath)
+	if err != nil {
+		return report, errutil.Wrap("failed to list the installed plugins", err)
+	}
+	installedVersions := make(map[string]string, len(installed))
+	for _, plugin := range installed {
+		installedVersions[plugin.Id] = plugin.Info.Version
+	}
+
+	for _, plugin := range backup.Plugins {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		result := restorePlugin(ctx, cfg, installer, plugin, installedVersions, tmpDir)
+		if result.Status == plugins.PluginRestoreFailed {
+			backupLog.Warn("Failed to restore plugin", "pluginId", plugin.ID, "version", plugin.Version,
+				"error", result.Message)
+		}
+		report.Plugins = append(report.Plugins, result)
+	}
+
+	for _, s := range backup.Settings {
+		query := models.GetOrgByIdQuery{Id: s.OrgID}
+		if err := sqlstore.GetOrgById(&query); err != nil {
+			if errors.Is(err, models.ErrOrgNotFound) {
+				report.SkippedSettings++
+				continue
+			}
+			return report, err
+		}
+
+		err := sqlStore.UpdatePluginSetting(&models.UpdatePluginSettingCmd{
+			OrgId:         s.OrgID,
+			PluginId:      s.PluginID,
+			Enabled:       s.Enabled,
+			Pinned:        s.Pinned,
+			JsonData:      s.JSONData,
+			PluginVersion: s.PluginVersion,
+		})
+		if err != nil {
+			return report, errutil.Wrapf(err, "failed to restore the settings of plugin '%s'", s.PluginID)
+		}
+		report.RestoredSettings++
+	}
+	return report, nil
+}
+
+func restorePlugin(ctx context.Context, cfg *setting.Cfg, installer plugins.PluginInstaller,
+	plugin plugins.PluginBackupPlugin, installedVersions map[string]string, archiveDir string) plugins.PluginRestoreResult {
+	result := plugins.PluginRestoreResult{PluginID: plugin.ID, Version: plugin.Version, Status: plugins.PluginRestoreFailed}
+	if version, exists := installedVersions[plugin.ID]; exists {
+		result.Status = plugins.PluginRestoreSkipped
+		result.Message = fmt.Sprintf("version %s is already installed", version)
+		return result
+	}
+	if !cfg.IsPluginInstallAllowed(plugin.ID) {
+		result.Message = plugins.ErrInstallNotAllowed.Error()
+		return result
+	}
+
+	archive := filepath.Join(archiveDir, plugin.ID+".zip")
+	if _, err := os.Stat(archive); err != nil {
+		result.Message = "plugin archive missing from the backup"
+		return result
+	}
+	if err := installer.Install(ctx, plugin.ID, plugin.Version, cfg.PluginsPath, archive, grafanaComURL); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	pluginDir := filepath.Join(cfg.PluginsPath, plugin.ID)
+	if err := validateRestoredPlugin(cfg, pluginDir); err != nil {
+		if err := installer.Uninstall(ctx, pluginDir); err != nil {
+			backupLog.Error("Failed to uninstall restored plugin", "pluginId", plugin.ID, "error", err)
+		}
+		result.Message = err.Error()
+		return result
+	}
+	result.Status = plugins.PluginRestoreInstalled
+	return result
+}
+
+// validateRestoredPlugin returns an error if the plugin of the directory isn't signed, unless unsigned plugins are
+// allowed to load, or if its signature is invalid or modified.
+func validateRestoredPlugin(cfg *setting.Cfg, dir string) error {
+	plugin, err := readInstalledPlugin(dir)
+	if err != nil {
+		return err
+	}
+	if plugin == nil {
+		return fmt.Errorf("plugin.json missing from the plugin archive")
+	}
+
+	state, err := getPluginSignatureState(backupLog, plugin)
+	if err != nil {
+		return err
+	}
+	switch state.Status {
+	case plugins.PluginSignatureValid:
+		return nil
+	case plugins.PluginSignatureUnsigned:
+		for _, id := range cfg.PluginsAllowUnsigned {
+			if id == plugin.Id {
+				return nil
+			}
+		}
+		return fmt.Errorf("plugin '%s' is unsigned", plugin.Id)
+	default:
+		return fmt.Errorf("plugin '%s' has a %s signature", plugin.Id, state.Status)
+	}
+}
+
+// installedPlugins returns the plugins installed in the plugins directory, by directory.
+func installedPlugins(pluginsPath string) (map[string]*plugins.PluginBase, error) {
+	entries, err := os.ReadDir(pluginsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]*plugins.PluginBase{}, nil
+		}
+		return nil, err
+	}
+
+	installed := make(map[string]*plugins.PluginBase, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(pluginsPath, entry.Name())
+		// the plugin directories can be symlinks
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		plugin, err := readInstalledPlugin(dir)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to read plugin in '%s'", dir)
+		}
+		if plugin != nil {
+			installed[dir] = plugin
+		}
+	}
+	return installed, nil
+}
+
+// readInstalledPlugin reads the manifest of the plugin of the directory, which is either at its root or in its dist
+// directory. It returns nil if the directory isn't a plugin directory.
+func readInstalledPlugin(dir string) (*plugins.PluginBase, error) {
+	for _, manifestDir := range []string{dir, filepath.Join(dir, "dist")} {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path is based on the plugins directory.
+		data, err := os.ReadFile(filepath.Join(manifestDir, "plugin.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		plugin := &plugins.PluginBase{}
+		if err := json.Unmarshal(data, plugin); err != nil {
+			return nil, err
+		}
+		plugin.PluginDir = manifestDir
+		return plugin, nil
+	}
+	return nil, nil
+}
+
+// writePluginBackupArchive writes the files of the plugin directory to a zip archive of the plugins directory of
+// the tarball, below a directory named after the plugin like in the archives of the catalog.
+func writePluginBackupArchive(tw *tar.Writer, pluginID, dir string) error {
+	tmpFile, err := os.CreateTemp("", "plugin-backup-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tmpFile.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			backupLog.Warn("Failed to close plugin archive", "path", tmpFile.Name(), "error", err)
+		}
+		if err := os.Remove(tmpFile.Name()); err != nil {
+			backupLog.Warn("Failed to remove plugin archive", "path", tmpFile.Name(), "error", err)
+		}
+	}()
+
+	zw := zip.NewWriter(tmpFile)
+	err = filepath.Walk(dir, func(filePath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// symlinks aren't extracted by the installer
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(pluginID, filepath.ToSlash(rel))
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path is based on the plugins directory.
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				backupLog.Warn("Failed to close plugin file", "path", filePath, "error", err)
+			}
+		}()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	fi, err := tmpFile.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(pluginBackupPluginsDir, pluginID+".zip"),
+		Mode:     0644,
+		Size:     fi.Size(),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, tmpFile)
+	return err
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// readPluginBackup reads the manifest of a plugin backup, and extracts the zip archives of its plugins to the
+// destination directory.
+func readPluginBackup(archivePath, dest string) (plugins.PluginBackup, error) {
+	var backup plugins.PluginBackup
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the archive is uploaded to a temporary file.
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return backup, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			backupLog.Warn("Failed to close plugin backup", "path", archivePath, "error", err)
+		}
+	}()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return backup, fmt.Errorf("%w: %v", plugins.ErrInvalidPluginBackup, err)
+	}
+	tr := tar.NewReader(gr)
+	hasManifest := false
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return backup, fmt.Errorf("%w: %v", plugins.ErrInvalidPluginBackup, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == pluginBackupManifest:
+			data, err := io.ReadAll(io.LimitReader(tr, maxPluginBackupManifestSize))
+			if err != nil {
+				return backup, fmt.Errorf("%w: %v", plugins.ErrInvalidPluginBackup, err)
+			}
+			if err := json.Unmarshal(data, &backup); err != nil {
+				return backup, fmt.Errorf("%w: %v", plugins.ErrInvalidPluginBackup, err)
+			}
+			hasManifest = true
+		case path.Dir(name) == pluginBackupPluginsDir && strings.HasSuffix(name, ".zip"):
+			if err := extractPluginBackupArchive(tr, filepath.Join(dest, path.Base(name))); err != nil {
+				return backup, err
+			}
+		}
+	}
+	if !hasManifest {
+		return backup, fmt.Errorf("%w: %s missing", plugins.ErrInvalidPluginBackup, pluginBackupManifest)
+	}
+
+	for _, plugin := range backup.Plugins {
+		if plugin.ID == "" || strings.ContainsAny(plugin.ID, `/\`) || strings.HasPrefix(plugin.ID, ".") {
+			return backup, fmt.Errorf("%w: invalid plugin ID %q", plugins.ErrInvalidPluginBackup, plugin.ID)
+		}
+	}
+	return backup, nil
+}
+
+func extractPluginBackupArchive(r io.Reader, dstPath string) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path is the base name of the archive member.
+	f, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		if closeErr := f.Close(); closeErr != nil {
+			backupLog.Warn("Failed to close plugin archive", "path", dstPath, "error", closeErr)
+		}
+		return err
+	}
+	return f.Close()
+}
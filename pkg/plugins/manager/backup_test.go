@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/installer"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_BackupAndRestorePlugins(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	org, err := sqlStore.CreateOrgWithMember("test", 1)
+	require.NoError(t, err)
+	for _, orgID := range []int64{org.Id, org.Id + 1} {
+		require.NoError(t, sqlStore.UpdatePluginSetting(&models.UpdatePluginSettingCmd{
+			OrgId:         orgID,
+			PluginId:      "test",
+			Enabled:       true,
+			JsonData:      map[string]interface{}{"url": "http://localhost:3000"},
+			PluginVersion: "1.0.0",
+		}))
+	}
+
+	writeBackup := func(t *testing.T, pluginsPath string) string {
+		t.Helper()
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = pluginsPath
+			pm.Cfg.BuildVersion = "8.3.0"
+			pm.SQLStore = sqlStore
+		})
+		var backup bytes.Buffer
+		require.NoError(t, pm.BackupPlugins(context.Background(), &backup))
+		path := filepath.Join(t.TempDir(), "backup.tar.gz")
+		require.NoError(t, os.WriteFile(path, backup.Bytes(), 0600))
+		return path
+	}
+	newTarget := func(t *testing.T) *PluginManager {
+		t.Helper()
+		return createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = t.TempDir()
+			pm.SQLStore = sqlStore
+			pm.pluginInstaller = installer.New(false, "8.3.0", installerLog)
+		})
+	}
+
+	t.Run("Should restore the plugins and the settings of the existing organizations", func(t *testing.T) {
+		archive := writeBackup(t, "testdata/valid-v2-signature")
+		pm := newTarget(t)
+
+		report, err := pm.RestorePlugins(context.Background(), archive)
+		require.NoError(t, err)
+		require.Equal(t, []plugins.PluginRestoreResult{
+			{PluginID: "test", Version: "1.0.0", Status: plugins.PluginRestoreInstalled},
+		}, report.Plugins)
+		require.Equal(t, 1, report.RestoredSettings)
+		require.Equal(t, 1, report.SkippedSettings)
+		require.NotNil(t, pm.GetPlugin("test"))
+
+		query := models.GetPluginSettingByIdQuery{OrgId: org.Id, PluginId: "test"}
+		require.NoError(t, sqlStore.GetPluginSettingById(&query))
+		require.True(t, query.Result.Enabled)
+		require.Equal(t, "http://localhost:3000", query.Result.JsonData["url"])
+
+		report, err = pm.RestorePlugins(context.Background(), archive)
+		require.NoError(t, err)
+		require.Equal(t, plugins.PluginRestoreSkipped, report.Plugins[0].Status)
+	})
+
+	t.Run("Should uninstall the plugins failing signature validation", func(t *testing.T) {
+		archive := writeBackup(t, "testdata/invalid-v2-signature")
+		pm := newTarget(t)
+
+		report, err := pm.RestorePlugins(context.Background(), archive)
+		require.NoError(t, err)
+		require.Len(t, report.Plugins, 1)
+		require.Equal(t, plugins.PluginRestoreFailed, report.Plugins[0].Status)
+		require.Nil(t, pm.GetPlugin("test"))
+		require.NoDirExists(t, filepath.Join(pm.Cfg.PluginsPath, "test"))
+	})
+
+	t.Run("Should reject archives which aren't plugin backups", func(t *testing.T) {
+		archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+		require.NoError(t, os.WriteFile(archive, []byte("test"), 0600))
+
+		_, err := newTarget(t).RestorePlugins(context.Background(), archive)
+		require.ErrorIs(t, err, plugins.ErrInvalidPluginBackup)
+	})
+}
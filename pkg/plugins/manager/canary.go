@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// canaryPathSetting is the key of the plugin settings, i.e. of the [plugin.<plugin id>] section of the
+// configuration, of the directory of the canary version of the plugin.
+const canaryPathSetting = "canary_path"
+
+// canaryBackendPluginManager registers the backend of the canary version of a plugin with the CanaryRegistrar.
+type canaryBackendPluginManager struct {
+	backendplugin.Manager
+
+	registrar backendplugin.CanaryRegistrar
+}
+
+func (m *canaryBackendPluginManager) RegisterAndStart(ctx context.Context, pluginID string,
+	factory backendplugin.PluginFactoryFunc) error {
+	return m.registrar.RegisterCanary(ctx, pluginID, factory)
+}
+
+// initCanaryPlugins loads the canary versions of the plugins, for the plugins with a canary path. Only the backend of
+// the canary version is used, to serve the requests routed to it. Failures to load a canary version are logged, and
+// the requests are served by the installed version.
+func (pm *PluginManager) initCanaryPlugins() {
+	registrar, ok := pm.BackendPluginManager.(backendplugin.CanaryRegistrar)
+	if !ok {
+		return
+	}
+
+	for pluginID, settings := range pm.Cfg.PluginSettings {
+		path := settings[canaryPathSetting]
+		if path == "" {
+			continue
+		}
+
+		canary, err := pm.loadCanaryPlugin(registrar, pluginID, path)
+		if err != nil {
+			pm.log.Error("Failed to load canary plugin version", "pluginId", pluginID, "path", path, "error", err)
+			continue
+		}
+		pm.log.Info("Registered canary plugin version", "pluginId", pluginID, "version", canary.Info.Version)
+	}
+}
+
+func (pm *PluginManager) loadCanaryPlugin(registrar backendplugin.CanaryRegistrar, pluginID,
+	path string) (*plugins.PluginBase, error) {
+	plugin := pm.GetPlugin(pluginID)
+	if plugin == nil {
+		return nil, plugins.ErrPluginNotInstalled
+	}
+	if plugin.IsCorePlugin || !plugin.Backend || (plugin.Type != "datasource" && plugin.Type != "app") {
+		return nil, errors.New("only external data source and app plugins with a backend can have a canary version")
+	}
+
+	scanner := &PluginScanner{
+		pluginPath:                    path,
+		cfg:                           pm.Cfg,
+		requireSigned:                 true,
+		log:                           pm.log,
+		plugins:                       map[string]*plugins.PluginBase{},
+		allowUnsignedPluginsCondition: pm.AllowUnsignedPluginsCondition,
+	}
+	jsonFPath := filepath.Join(path, "plugin.json")
+	if err := scanner.loadPlugin(jsonFPath); err != nil {
+		return nil, err
+	}
+	canary := scanner.plugins[filepath.Dir(jsonFPath)]
+	if canary.Id != pluginID || canary.Type != plugin.Type {
+		return nil, fmt.Errorf("found %s plugin %q instead", canary.Type, canary.Id)
+	}
+	if !canary.Backend {
+		return nil, errors.New("canary version has no backend")
+	}
+	if signingError := scanner.validateSignature(canary); signingError != nil {
+		return nil, fmt.Errorf("invalid signature: %s", signingError.ErrorCode)
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `jsonFPath` is based on the configuration.
+	reader, err := os.Open(jsonFPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			pm.log.Warn("Failed to close JSON file", "path", jsonFPath, "err", err)
+		}
+	}()
+
+	var loader plugins.PluginLoader = &plugins.DataSourcePlugin{}
+	if canary.Type == "app" {
+		loader = &plugins.AppPlugin{}
+	}
+	backendPluginManager := &canaryBackendPluginManager{Manager: pm.BackendPluginManager, registrar: registrar}
+	if _, err := loader.Load(json.NewDecoder(reader), canary, backendPluginManager); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
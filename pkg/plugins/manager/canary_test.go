@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type canaryRegistrarBackendPluginManager struct {
+	fakeBackendPluginManager
+
+	canaries []string
+}
+
+func (m *canaryRegistrarBackendPluginManager) RegisterCanary(_ context.Context, pluginID string, _ backendplugin.PluginFactoryFunc) error {
+	m.canaries = append(m.canaries, pluginID)
+	return nil
+}
+
+func TestPluginManager_initCanaryPlugins(t *testing.T) {
+	pluginsPath := t.TempDir()
+	require.NoError(t, writeBackendPlugin(filepath.Join(pluginsPath, "test-datasource"), "test-datasource", "1.0.0"))
+	require.NoError(t, writeBackendPlugin(filepath.Join(pluginsPath, "other-datasource"), "other-datasource", "1.0.0"))
+	canaryPath := t.TempDir()
+	require.NoError(t, writeBackendPlugin(filepath.Join(canaryPath, "test-datasource"), "test-datasource", "2.0.0"))
+
+	backendPluginManager := &canaryRegistrarBackendPluginManager{}
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.PluginsPath = pluginsPath
+		pm.Cfg.PluginsAllowUnsigned = []string{"test-datasource", "other-datasource"}
+		pm.Cfg.PluginSettings = setting.PluginSettings{
+			"test-datasource":  {"canary_path": filepath.Join(canaryPath, "test-datasource")},
+			"other-datasource": {"canary_path": filepath.Join(canaryPath, "test-datasource")},
+			"unknown":          {"canary_path": filepath.Join(canaryPath, "test-datasource")},
+		}
+		pm.BackendPluginManager = backendPluginManager
+	})
+	require.NoError(t, pm.initExternalPlugins())
+
+	pm.initCanaryPlugins()
+
+	require.Equal(t, []string{"test-datasource"}, backendPluginManager.canaries)
+	require.Equal(t, "1.0.0", pm.GetPlugin("test-datasource").Info.Version)
+	require.ElementsMatch(t, []string{"test-datasource", "other-datasource"}, backendPluginManager.registeredPlugins)
+}
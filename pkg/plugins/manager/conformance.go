@@ -0,0 +1,261 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+const (
+	// conformanceCheckTimeout bounds the duration of each check of the conformance suite.
+	conformanceCheckTimeout = 30 * time.Second
+	// conformanceStreamRunDuration is how long a stream runs before its context is canceled.
+	conformanceStreamRunDuration = 100 * time.Millisecond
+	// conformanceStreamStopTimeout is how long a running stream has to stop once its context is canceled.
+	conformanceStreamStopTimeout = 5 * time.Second
+	// conformanceUnknownPath is the path of the resource and of the stream the plugins aren't expected to know.
+	conformanceUnknownPath = "__conformance/unknown"
+)
+
+// conformanceRefIDs are the reference IDs of the queries of the queryData check.
+var conformanceRefIDs = []string{"A", "B"}
+
+// RunConformanceSuite runs the conformance suite against the backend of the plugin of the plugin context, e.g. so
+// that plugin vendors can certify the compatibility of their plugin with the running Grafana version. The checks of
+// the capabilities the plugin doesn't implement are skipped.
+func (pm *PluginManager) RunConformanceSuite(ctx context.Context, pCtx backend.PluginContext) (
+	plugins.PluginConformanceReport, error) {
+	plugin := pm.GetPlugin(pCtx.PluginID)
+	if plugin == nil {
+		return plugins.PluginConformanceReport{}, plugins.PluginNotFoundError{PluginID: pCtx.PluginID}
+	}
+	backendPlugin, registered := pm.BackendPluginManager.Get(pCtx.PluginID)
+	if !registered {
+		return plugins.PluginConformanceReport{}, backendplugin.ErrPluginNotRegistered
+	}
+
+	suite := &conformanceSuite{plugin: backendPlugin, pCtx: pCtx, pluginType: plugin.Type}
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) (plugins.PluginConformanceStatus, string)
+	}{
+		{name: plugins.PluginConformanceCheckHealth, run: suite.checkHealth},
+		{name: plugins.PluginConformanceCheckQueryData, run: suite.checkQueryData},
+		{name: plugins.PluginConformanceCheckResources, run: suite.checkResources},
+		{name: plugins.PluginConformanceCheckStreams, run: suite.checkStreams},
+	}
+
+	report := plugins.PluginConformanceReport{
+		PluginID:       plugin.Id,
+		PluginVersion:  plugin.Info.Version,
+		GrafanaVersion: pm.Cfg.BuildVersion,
+		Compatible:     true,
+		Results:        make([]plugins.PluginConformanceResult, 0, len(checks)),
+	}
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, conformanceCheckTimeout)
+		start := time.Now()
+		status, message := check.run(checkCtx)
+		cancel()
+
+		report.Results = append(report.Results, plugins.PluginConformanceResult{
+			Check:    check.name,
+			Status:   status,
+			Message:  message,
+			Duration: float64(time.Since(start)) / float64(time.Millisecond),
+		})
+		if status == plugins.PluginConformanceFailed {
+			report.Compatible = false
+		}
+	}
+
+	pm.log.Info("Ran plugin conformance suite", "pluginId", plugin.Id, "version", plugin.Info.Version,
+		"compatible", report.Compatible)
+	return report, nil
+}
+
+// conformanceSuite are the checks of the conformance suite run against a backend plugin.
+type conformanceSuite struct {
+	plugin     backendplugin.Plugin
+	pCtx       backend.PluginContext
+	pluginType string
+}
+
+func (s *conformanceSuite) checkHealth(ctx context.Context) (plugins.PluginConformanceStatus, string) {
+	res, err := s.plugin.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: s.pCtx})
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return plugins.PluginConformanceSkipped, "health checks aren't implemented"
+	}
+	if err != nil {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("health check failed: %v", err)
+	}
+	if res == nil {
+		return plugins.PluginConformanceFailed, "health check returned no result"
+	}
+
+	switch res.Status {
+	case backend.HealthStatusUnknown, backend.HealthStatusOk, backend.HealthStatusError:
+	default:
+		return plugins.PluginConformanceFailed, fmt.Sprintf("health check returned the unknown status %d", res.Status)
+	}
+	if len(res.JSONDetails) > 0 && !json.Valid(res.JSONDetails) {
+		return plugins.PluginConformanceFailed, "health check details aren't valid JSON"
+	}
+	return plugins.PluginConformancePassed, fmt.Sprintf("health check returned the status %s", res.Status)
+}
+
+// checkQueryData sends queries with an empty model: the plugin may answer them with errors, but must answer each of
+// them, by reference ID, with well-formed data frames.
+func (s *conformanceSuite) checkQueryData(ctx context.Context) (plugins.PluginConformanceStatus, string) {
+	now := time.Now()
+	req := &backend.QueryDataRequest{PluginContext: s.pCtx}
+	for _, refID := range conformanceRefIDs {
+		req.Queries = append(req.Queries, backend.DataQuery{
+			RefID:         refID,
+			MaxDataPoints: 100,
+			Interval:      time.Minute,
+			TimeRange:     backend.TimeRange{From: now.Add(-time.Hour), To: now},
+			JSON:          json.RawMessage(fmt.Sprintf(`{"refId":%q}`, refID)),
+		})
+	}
+
+	resp, err := s.plugin.QueryData(ctx, req)
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		if s.pluginType == "datasource" {
+			return plugins.PluginConformanceFailed, "data source plugins must implement queries"
+		}
+		return plugins.PluginConformanceSkipped, "queries aren't implemented"
+	}
+	if err != nil {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("query failed: %v", err)
+	}
+	if resp == nil {
+		return plugins.PluginConformanceFailed, "query returned no response"
+	}
+
+	queryErrors := 0
+	for _, refID := range conformanceRefIDs {
+		res, exists := resp.Responses[refID]
+		if !exists {
+			return plugins.PluginConformanceFailed, fmt.Sprintf("no response for the query %s", refID)
+		}
+		if res.Error != nil {
+			queryErrors++
+		}
+		for i, frame := range res.Frames {
+			if frame == nil {
+				return plugins.PluginConformanceFailed, fmt.Sprintf("frame %d of the query %s is nil", i, refID)
+			}
+			if _, err := frame.RowLen(); err != nil {
+				return plugins.PluginConformanceFailed, fmt.Sprintf("frame %d of the query %s is malformed: %v", i,
+					refID, err)
+			}
+		}
+	}
+	if len(resp.Responses) != len(conformanceRefIDs) {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("%d responses for %d queries", len(resp.Responses),
+			len(conformanceRefIDs))
+	}
+	return plugins.PluginConformancePassed, fmt.Sprintf("%d of %d queries returned an error", queryErrors,
+		len(conformanceRefIDs))
+}
+
+func (s *conformanceSuite) checkResources(ctx context.Context) (plugins.PluginConformanceStatus, string) {
+	sender := &conformanceResourceSender{}
+	err := s.plugin.CallResource(ctx, &backend.CallResourceRequest{
+		PluginContext: s.pCtx,
+		Path:          conformanceUnknownPath,
+		Method:        http.MethodGet,
+		URL:           "/" + conformanceUnknownPath,
+		Headers:       map[string][]string{},
+	}, sender)
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return plugins.PluginConformanceSkipped, "resources aren't implemented"
+	}
+	if err != nil {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("resource call failed: %v", err)
+	}
+	if len(sender.responses) == 0 {
+		return plugins.PluginConformanceFailed, "resource call sent no response"
+	}
+
+	status := sender.responses[0].Status
+	if status < 100 || status > 599 {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("resource call returned the invalid status %d", status)
+	}
+	if status != http.StatusNotFound {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("unknown resource returned the status %d instead of %d",
+			status, http.StatusNotFound)
+	}
+	return plugins.PluginConformancePassed, "unknown resource not found"
+}
+
+// checkStreams subscribes to and runs an unknown stream: the subscription must be rejected, and running the stream
+// must either fail or stop once its context is canceled.
+func (s *conformanceSuite) checkStreams(ctx context.Context) (plugins.PluginConformanceStatus, string) {
+	sub, err := s.plugin.SubscribeStream(ctx, &backend.SubscribeStreamRequest{
+		PluginContext: s.pCtx,
+		Path:          conformanceUnknownPath,
+	})
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return plugins.PluginConformanceSkipped, "streams aren't implemented"
+	}
+	if err != nil {
+		return plugins.PluginConformanceFailed, fmt.Sprintf("stream subscription failed: %v", err)
+	}
+	if sub == nil {
+		return plugins.PluginConformanceFailed, "stream subscription returned no response"
+	}
+	if sub.Status == backend.SubscribeStreamStatusOK {
+		return plugins.PluginConformanceFailed, "subscription to an unknown stream accepted"
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.plugin.RunStream(runCtx, &backend.RunStreamRequest{
+			PluginContext: s.pCtx,
+			Path:          conformanceUnknownPath,
+		}, backend.NewStreamSender(conformancePacketSender{}))
+	}()
+
+	select {
+	case <-done:
+		return plugins.PluginConformancePassed, "unknown stream rejected"
+	case <-time.After(conformanceStreamRunDuration):
+	}
+	cancel()
+	select {
+	case <-done:
+		return plugins.PluginConformancePassed, "stream stopped once canceled"
+	case <-time.After(conformanceStreamStopTimeout):
+		return plugins.PluginConformanceFailed, fmt.Sprintf("stream still running %s after being canceled",
+			conformanceStreamStopTimeout)
+	}
+}
+
+// conformanceResourceSender collects the responses of a resource call.
+type conformanceResourceSender struct {
+	responses []*backend.CallResourceResponse
+}
+
+func (s *conformanceResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.responses = append(s.responses, res)
+	return nil
+}
+
+// conformancePacketSender discards the packets of a stream.
+type conformancePacketSender struct{}
+
+func (conformancePacketSender) Send(*backend.StreamPacket) error {
+	return nil
+}
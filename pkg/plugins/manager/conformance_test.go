@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+type conformanceBackendPlugin struct {
+	backendplugin.Plugin
+
+	queryRefIDs     []string
+	resourceStatus  int
+	subscribeStatus backend.SubscribeStreamStatus
+}
+
+func (p *conformanceBackendPlugin) CheckHealth(_ context.Context, _ *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, JSONDetails: []byte(`{"version":"1.0.0"}`)}, nil
+}
+
+func (p *conformanceBackendPlugin) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, refID := range p.queryRefIDs {
+		resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{
+			data.NewFrame("test", data.NewField("value", nil, []float64{1, 2})),
+		}}
+	}
+	return resp, nil
+}
+
+func (p *conformanceBackendPlugin) CallResource(_ context.Context, _ *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return sender.Send(&backend.CallResourceResponse{Status: p.resourceStatus})
+}
+
+func (p *conformanceBackendPlugin) SubscribeStream(_ context.Context, _ *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if p.subscribeStatus == -1 {
+		return nil, backendplugin.ErrMethodNotImplemented
+	}
+	return &backend.SubscribeStreamResponse{Status: p.subscribeStatus}, nil
+}
+
+func (p *conformanceBackendPlugin) RunStream(ctx context.Context, _ *backend.RunStreamRequest, _ *backend.StreamSender) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestPluginManager_RunConformanceSuite(t *testing.T) {
+	backendPlugin := &conformanceBackendPlugin{}
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.BuildVersion = "8.3.0"
+		pm.BackendPluginManager = &verificationBackendPluginManager{
+			plugins: map[string]backendplugin.Plugin{"test-datasource": backendPlugin},
+		}
+		pm.plugins = map[string]*plugins.PluginBase{
+			"test-datasource": {Id: "test-datasource", Type: "datasource", Backend: true,
+				Info: plugins.PluginInfo{Version: "1.0.0"}},
+			"test-panel": {Id: "test-panel", Type: "panel"},
+		}
+	})
+	pCtx := backend.PluginContext{OrgID: 1, PluginID: "test-datasource"}
+	statuses := func(report plugins.PluginConformanceReport) map[string]plugins.PluginConformanceStatus {
+		statuses := map[string]plugins.PluginConformanceStatus{}
+		for _, r := range report.Results {
+			statuses[r.Check] = r.Status
+		}
+		return statuses
+	}
+
+	t.Run("Should pass a conforming plugin", func(t *testing.T) {
+		*backendPlugin = conformanceBackendPlugin{
+			queryRefIDs:     []string{"A", "B"},
+			resourceStatus:  http.StatusNotFound,
+			subscribeStatus: backend.SubscribeStreamStatusNotFound,
+		}
+
+		report, err := pm.RunConformanceSuite(context.Background(), pCtx)
+		require.NoError(t, err)
+		require.True(t, report.Compatible)
+		require.Equal(t, "1.0.0", report.PluginVersion)
+		require.Equal(t, "8.3.0", report.GrafanaVersion)
+		require.Equal(t, map[string]plugins.PluginConformanceStatus{
+			plugins.PluginConformanceCheckHealth:    plugins.PluginConformancePassed,
+			plugins.PluginConformanceCheckQueryData: plugins.PluginConformancePassed,
+			plugins.PluginConformanceCheckResources: plugins.PluginConformancePassed,
+			plugins.PluginConformanceCheckStreams:   plugins.PluginConformancePassed,
+		}, statuses(report))
+	})
+
+	t.Run("Should report the failed checks", func(t *testing.T) {
+		*backendPlugin = conformanceBackendPlugin{
+			queryRefIDs:     []string{"A"},
+			resourceStatus:  http.StatusOK,
+			subscribeStatus: -1,
+		}
+
+		report, err := pm.RunConformanceSuite(context.Background(), pCtx)
+		require.NoError(t, err)
+		require.False(t, report.Compatible)
+		require.Equal(t, map[string]plugins.PluginConformanceStatus{
+			plugins.PluginConformanceCheckHealth:    plugins.PluginConformancePassed,
+			plugins.PluginConformanceCheckQueryData: plugins.PluginConformanceFailed,
+			plugins.PluginConformanceCheckResources: plugins.PluginConformanceFailed,
+			plugins.PluginConformanceCheckStreams:   plugins.PluginConformanceSkipped,
+		}, statuses(report))
+	})
+
+	t.Run("Should require a backend plugin", func(t *testing.T) {
+		_, err := pm.RunConformanceSuite(context.Background(), backend.PluginContext{PluginID: "test-panel"})
+		require.ErrorIs(t, err, backendplugin.ErrPluginNotRegistered)
+
+		_, err = pm.RunConformanceSuite(context.Background(), backend.PluginContext{PluginID: "unknown"})
+		require.ErrorIs(t, err, plugins.PluginNotFoundError{PluginID: "unknown"})
+	})
+}
@@ -24,14 +24,14 @@ func (e DashboardInputMissingError) Error() string {
 func (pm *PluginManager) ImportDashboard(pluginID, path string, orgID, folderID int64, dashboardModel *simplejson.Json,
 	overwrite bool, inputs []plugins.ImportDashboardInput, user *models.SignedInUser,
 	requestHandler plugins.DataRequestHandler) (plugins.PluginDashboardInfoDTO, *models.Dashboard, error) {
-	var dashboard *models.Dashboard
-	if pluginID != "" {
-		var err error
-		if dashboard, err = pm.LoadPluginDashboard(pluginID, path); err != nil {
-			return plugins.PluginDashboardInfoDTO{}, &models.Dashboard{}, err
-		}
-	} else {
-		dashboard = models.NewDashboardFromJson(dashboardModel)
+	dashboard, err := pm.loadImportDashboard(pluginID, path, dashboardModel)
+	if err != nil {
+		return plugins.PluginDashboardInfoDTO{}, &models.Dashboard{}, err
+	}
+
+	inputs, err = pm.resolveImportInputs(orgID, dashboard.Data, inputs)
+	if err != nil {
+		return plugins.PluginDashboardInfoDTO{}, &models.Dashboard{}, err
 	}
 
 	evaluator := &DashTemplateEvaluator{
@@ -80,6 +80,14 @@ func (pm *PluginManager) ImportDashboard(pluginID, path string, orgID, folderID
 	}, savedDash, nil
 }
 
+// loadImportDashboard loads the dashboard of the plugin, if any, or the given dashboard otherwise.
+func (pm *PluginManager) loadImportDashboard(pluginID, path string, dashboardModel *simplejson.Json) (*models.Dashboard, error) {
+	if pluginID != "" {
+		return pm.LoadPluginDashboard(pluginID, path)
+	}
+	return models.NewDashboardFromJson(dashboardModel), nil
+}
+
 type DashTemplateEvaluator struct {
 	template  *simplejson.Json
 	inputs    []plugins.ImportDashboardInput
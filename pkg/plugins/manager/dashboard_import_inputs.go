@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// resolveImportInputs resolves the inputs of the dashboard template server-side, so that the dashboards of a plugin
+// can be imported without choosing the data sources of each of them:
+//   - data source inputs can be given by the name or the UID of the data source, and for all inputs of a data source
+//     type at once with an input without name and with the plugin ID of the type,
+//   - data source inputs that aren't given default to the default data source of the organization, or to its only
+//     data source of their type,
+//   - constant inputs that aren't given default to their value in the template.
+//
+// Inputs that can't be resolved are left out, which makes the evaluation of the template fail.
+func (pm *PluginManager) resolveImportInputs(orgID int64, template *simplejson.Json,
+	inputs []plugins.ImportDashboardInput) ([]plugins.ImportDashboardInput, error) {
+	resolver := &importInputResolver{pm: pm, orgID: orgID}
+
+	resolved := make([]plugins.ImportDashboardInput, 0, len(inputs))
+	for _, inputDef := range template.Get("__inputs").MustArray() {
+		inputDefJson := simplejson.NewFromAny(inputDef)
+		name := inputDefJson.Get("name").MustString()
+		inputType := inputDefJson.Get("type").MustString()
+		pluginID := inputDefJson.Get("pluginId").MustString()
+
+		input := findImportInput(inputs, name, inputType, pluginID)
+		var value string
+		if input != nil {
+			value = input.Value
+		}
+
+		switch inputType {
+		case "datasource":
+			ds, err := resolver.dataSource(value, pluginID)
+			if err != nil {
+				return nil, err
+			}
+			if ds != nil {
+				value = ds.Name
+			} else if value == "" {
+				continue
+			}
+		case "constant":
+			if input == nil {
+				defaultValue, ok := inputDefJson.CheckGet("value")
+				if !ok {
+					continue
+				}
+				value = defaultValue.MustString()
+			}
+		default:
+			if input == nil {
+				continue
+			}
+		}
+
+		resolved = append(resolved, plugins.ImportDashboardInput{
+			Type:     inputType,
+			PluginId: pluginID,
+			Name:     name,
+			Value:    value,
+		})
+	}
+
+	return resolved, nil
+}
+
+// findImportInput returns the input for the input of the template with the given name and type. Inputs with the
+// name of the template input take precedence over the inputs for all inputs of the plugin, and over the inputs
+// for all inputs of the type.
+func findImportInput(inputs []plugins.ImportDashboardInput, name, inputType, pluginID string) *plugins.ImportDashboardInput {
+	var byPlugin, wildcard *plugins.ImportDashboardInput
+	for i, input := range inputs {
+		if input.Type != inputType {
+			continue
+		}
+
+		switch {
+		case input.Name == name:
+			return &inputs[i]
+		case input.Name == "" && input.PluginId != "" && input.PluginId == pluginID:
+			if byPlugin == nil {
+				byPlugin = &inputs[i]
+			}
+		case input.Name == "*":
+			if wildcard == nil {
+				wildcard = &inputs[i]
+			}
+		}
+	}
+
+	if byPlugin != nil {
+		return byPlugin
+	}
+	return wildcard
+}
+
+// importInputResolver looks up the data sources of the organization for the data source inputs.
+type importInputResolver struct {
+	pm          *PluginManager
+	orgID       int64
+	dataSources []*models.DataSource
+}
+
+// dataSource returns the data source with the name or UID of the value, or the data source the input defaults to
+// if the value is empty. It returns nil if there's no such data source.
+func (r *importInputResolver) dataSource(value, pluginID string) (*models.DataSource, error) {
+	if value != "" {
+		return r.pm.findImportDataSource(r.orgID, value)
+	}
+
+	if r.dataSources == nil {
+		query := models.GetDataSourcesQuery{OrgId: r.orgID}
+		if err := r.pm.SQLStore.GetDataSources(&query); err != nil {
+			return nil, err
+		}
+		r.dataSources = query.Result
+	}
+
+	var match *models.DataSource
+	matches := 0
+	for _, ds := range r.dataSources {
+		if pluginID != "" && ds.Type != pluginID {
+			continue
+		}
+		if ds.IsDefault {
+			return ds, nil
+		}
+		match = ds
+		matches++
+	}
+
+	if matches == 1 && pluginID != "" {
+		return match, nil
+	}
+	return nil, nil
+}
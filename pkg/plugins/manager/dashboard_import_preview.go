@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// PreviewImportDashboard returns what importing the dashboard with the given options would create or overwrite,
+// without persisting anything.
+func (pm *PluginManager) PreviewImportDashboard(ctx context.Context, pluginID, path string, orgID, folderID int64,
+	dashboardModel *simplejson.Json, overwrite bool, inputs []plugins.ImportDashboardInput) (plugins.DashboardImportPreview, error) {
+	dashboard, err := pm.loadImportDashboard(pluginID, path, dashboardModel)
+	if err != nil {
+		return plugins.DashboardImportPreview{}, err
+	}
+
+	inputs, err = pm.resolveImportInputs(orgID, dashboard.Data, inputs)
+	if err != nil {
+		return plugins.DashboardImportPreview{}, err
+	}
+
+	evaluator := &DashTemplateEvaluator{
+		template: dashboard.Data,
+		inputs:   inputs,
+	}
+
+	generatedDash, err := evaluator.Eval()
+	if err != nil {
+		return plugins.DashboardImportPreview{}, err
+	}
+
+	saveCmd := models.SaveDashboardCommand{
+		Dashboard: generatedDash,
+		OrgId:     orgID,
+		Overwrite: overwrite,
+		PluginId:  pluginID,
+		FolderId:  folderID,
+	}
+	dash := saveCmd.GetDashboardModel()
+
+	preview := plugins.DashboardImportPreview{
+		PluginId:    pluginID,
+		Path:        path,
+		Title:       dash.Title,
+		Uid:         dash.Uid,
+		FolderId:    folderID,
+		FolderTitle: models.RootFolderName,
+		Action:      plugins.DashboardImportCreate,
+	}
+
+	if folderID > 0 {
+		query := models.GetDashboardQuery{OrgId: orgID, Id: folderID}
+		if err := bus.DispatchCtx(ctx, &query); err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+			return plugins.DashboardImportPreview{}, err
+		}
+		if query.Result != nil {
+			preview.FolderTitle = query.Result.Title
+		}
+	}
+
+	if preview.DataSources, err = pm.previewImportDataSources(orgID, evaluator); err != nil {
+		return plugins.DashboardImportPreview{}, err
+	}
+
+	var existing *models.Dashboard
+	if dash.Uid != "" {
+		query := models.GetDashboardQuery{OrgId: orgID, Uid: dash.Uid}
+		if err := bus.DispatchCtx(ctx, &query); err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+			return plugins.DashboardImportPreview{}, err
+		}
+		existing = query.Result
+	}
+
+	// The validation of the dashboard store only reads the existing dashboards, it finds the dashboard that
+	// would be overwritten and the conflicts of the import.
+	if _, err := pm.SQLStore.ValidateDashboardBeforeSave(dash, overwrite); err != nil {
+		var dashboardErr models.DashboardErr
+		var pluginErr models.UpdatePluginDashboardError
+		if !errors.As(err, &dashboardErr) && !errors.As(err, &pluginErr) {
+			return plugins.DashboardImportPreview{}, err
+		}
+
+		preview.Action = plugins.DashboardImportConflict
+		preview.Conflict = err.Error()
+	} else if dash.Id > 0 {
+		preview.Action = plugins.DashboardImportOverwrite
+		if existing == nil || existing.Id != dash.Id {
+			query := models.GetDashboardQuery{OrgId: orgID, Id: dash.Id}
+			if err := bus.DispatchCtx(ctx, &query); err != nil {
+				return plugins.DashboardImportPreview{}, err
+			}
+			existing = query.Result
+		}
+	}
+
+	if existing != nil {
+		preview.Existing = &plugins.DashboardImportExisting{
+			Id:       existing.Id,
+			Uid:      existing.Uid,
+			Title:    existing.Title,
+			Url:      existing.GetUrl(),
+			FolderId: existing.FolderId,
+			PluginId: existing.PluginId,
+			Version:  existing.Version,
+		}
+	}
+
+	return preview, nil
+}
+
+// previewImportDataSources returns the data sources the data source inputs of the dashboard template would be
+// bound to. The inputs are matched against the names of the data sources and then their UIDs.
+func (pm *PluginManager) previewImportDataSources(orgID int64, evaluator *DashTemplateEvaluator) ([]plugins.DashboardImportDataSource, error) {
+	dataSources := make([]plugins.DashboardImportDataSource, 0)
+	for _, inputDef := range evaluator.template.Get("__inputs").MustArray() {
+		inputDefJson := simplejson.NewFromAny(inputDef)
+		if inputDefJson.Get("type").MustString() != "datasource" {
+			continue
+		}
+
+		binding := plugins.DashboardImportDataSource{
+			Input:    inputDefJson.Get("name").MustString(),
+			PluginId: inputDefJson.Get("pluginId").MustString(),
+		}
+		if input := evaluator.findInput(binding.Input, "datasource"); input != nil {
+			binding.Value = input.Value
+		}
+
+		ds, err := pm.findImportDataSource(orgID, binding.Value)
+		if err != nil {
+			return nil, err
+		}
+		if ds != nil {
+			binding.Found = true
+			binding.DataSourceUid = ds.Uid
+			binding.DataSourceName = ds.Name
+			binding.DataSourceType = ds.Type
+			binding.TypeMismatch = binding.PluginId != "" && binding.PluginId != ds.Type
+		}
+
+		dataSources = append(dataSources, binding)
+	}
+
+	return dataSources, nil
+}
+
+func (pm *PluginManager) findImportDataSource(orgID int64, value string) (*models.DataSource, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, query := range []*models.GetDataSourceQuery{
+		{OrgId: orgID, Name: value},
+		{OrgId: orgID, Uid: value},
+	} {
+		err := pm.SQLStore.GetDataSource(query)
+		if err == nil {
+			return query.Result, nil
+		}
+		if !errors.Is(err, models.ErrDataSourceNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
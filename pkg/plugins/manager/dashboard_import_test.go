@@ -1,9 +1,11 @@
 package manager
 
 import (
+	"context"
 	"io/ioutil"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -15,6 +17,7 @@ import (
 
 func TestDashboardImport(t *testing.T) {
 	pluginScenario(t, "When importing a plugin dashboard", func(t *testing.T, pm *PluginManager) {
+		pm.SQLStore = sqlstore.InitTestDB(t)
 		origNewDashboardService := dashboards.NewService
 		t.Cleanup(func() {
 			dashboards.NewService = origNewDashboardService
@@ -76,6 +79,100 @@ func TestDashboardImport(t *testing.T) {
 	})
 }
 
+func TestPreviewImportDashboard(t *testing.T) {
+	pluginScenario(t, "When previewing the import of a plugin dashboard", func(t *testing.T, pm *PluginManager) {
+		pm.SQLStore = sqlstore.InitTestDB(t)
+		ctx := context.Background()
+		inputs := []plugins.ImportDashboardInput{
+			{Name: "*", Type: "datasource", Value: "graphite"},
+		}
+
+		preview, err := pm.PreviewImportDashboard(ctx, "test-app", "dashboards/connections.json", 1, 0, nil, false, inputs)
+		require.NoError(t, err)
+		require.Equal(t, plugins.DashboardImportCreate, preview.Action)
+		require.Equal(t, "Nginx Connections", preview.Title)
+		require.Equal(t, "1MHHlVjzz", preview.Uid)
+		require.Equal(t, models.RootFolderName, preview.FolderTitle)
+		require.Nil(t, preview.Existing)
+		require.Equal(t, []plugins.DashboardImportDataSource{
+			{Input: "DS_NAME", PluginId: "graphite", Value: "graphite"},
+		}, preview.DataSources)
+
+		err = pm.SQLStore.AddDataSource(&models.AddDataSourceCommand{OrgId: 1, Name: "graphite", Type: "graphite", Uid: "graphite-uid"})
+		require.NoError(t, err)
+		existing, err := pm.SQLStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": "1MHHlVjzz", "title": "Connections"}),
+		})
+		require.NoError(t, err)
+
+		preview, err = pm.PreviewImportDashboard(ctx, "test-app", "dashboards/connections.json", 1, 0, nil, false, inputs)
+		require.NoError(t, err)
+		require.Equal(t, plugins.DashboardImportConflict, preview.Action)
+		require.NotEmpty(t, preview.Conflict)
+		require.NotNil(t, preview.Existing)
+		require.Equal(t, existing.Id, preview.Existing.Id)
+		require.True(t, preview.DataSources[0].Found)
+		require.Equal(t, "graphite-uid", preview.DataSources[0].DataSourceUid)
+		require.False(t, preview.DataSources[0].TypeMismatch)
+
+		preview, err = pm.PreviewImportDashboard(ctx, "test-app", "dashboards/connections.json", 1, 0, nil, true, inputs)
+		require.NoError(t, err)
+		require.Equal(t, plugins.DashboardImportOverwrite, preview.Action)
+		require.Equal(t, existing.Id, preview.Existing.Id)
+
+		query := models.GetDashboardQuery{OrgId: 1, Uid: "1MHHlVjzz"}
+		require.NoError(t, bus.DispatchCtx(ctx, &query))
+		require.Equal(t, "Connections", query.Result.Title)
+	})
+}
+
+func TestResolveImportInputs(t *testing.T) {
+	pm := newManager(&setting.Cfg{}, sqlstore.InitTestDB(t), &fakeBackendPluginManager{})
+	for _, cmd := range []*models.AddDataSourceCommand{
+		{OrgId: 1, Name: "Prometheus", Type: "prometheus", Uid: "prom-uid"},
+		{OrgId: 1, Name: "Loki A", Type: "loki", Uid: "loki-a-uid"},
+		{OrgId: 1, Name: "Loki B", Type: "loki", Uid: "loki-b-uid"},
+	} {
+		require.NoError(t, pm.SQLStore.AddDataSource(cmd))
+	}
+
+	template, err := simplejson.NewJson([]byte(`{
+		"__inputs": [
+			{"name": "DS_PROM", "type": "datasource", "pluginId": "prometheus"},
+			{"name": "DS_LOKI", "type": "datasource", "pluginId": "loki"},
+			{"name": "DS_GRAPHITE", "type": "datasource", "pluginId": "graphite"},
+			{"name": "VAR_ENV", "type": "constant", "value": "prod"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	t.Run("inputs default to the only data source of their type and the value of the constants", func(t *testing.T) {
+		inputs, err := pm.resolveImportInputs(1, template, nil)
+		require.NoError(t, err)
+		require.Equal(t, []plugins.ImportDashboardInput{
+			{Name: "DS_PROM", Type: "datasource", PluginId: "prometheus", Value: "Prometheus"},
+			{Name: "VAR_ENV", Type: "constant", Value: "prod"},
+		}, inputs)
+	})
+
+	t.Run("inputs can be given by data source UID and plugin ID", func(t *testing.T) {
+		inputs, err := pm.resolveImportInputs(1, template, []plugins.ImportDashboardInput{
+			{Type: "datasource", PluginId: "loki", Value: "loki-b-uid"},
+			{Name: "DS_PROM", Type: "datasource", Value: "prom-uid"},
+			{Name: "*", Type: "datasource", Value: "graphite"},
+			{Name: "VAR_ENV", Type: "constant", Value: "dev"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []plugins.ImportDashboardInput{
+			{Name: "DS_PROM", Type: "datasource", PluginId: "prometheus", Value: "Prometheus"},
+			{Name: "DS_LOKI", Type: "datasource", PluginId: "loki", Value: "Loki B"},
+			{Name: "DS_GRAPHITE", Type: "datasource", PluginId: "graphite", Value: "graphite"},
+			{Name: "VAR_ENV", Type: "constant", Value: "dev"},
+		}, inputs)
+	})
+}
+
 func pluginScenario(t *testing.T, desc string, fn func(*testing.T, *PluginManager)) {
 	t.Helper()
 
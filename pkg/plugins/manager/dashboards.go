@@ -49,6 +49,7 @@ func (pm *PluginManager) GetPluginDashboards(orgID int64, pluginID string) ([]*p
 				res.ImportedUri = "db/" + existingDash.Slug
 				res.ImportedUrl = existingDash.GetUrl()
 				res.ImportedRevision = existingDash.Data.Get("revision").MustInt64(1)
+				res.FolderId = existingDash.FolderId
 				existingMatches[existingDash.Id] = true
 			}
 		}
@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"os"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// detectDeprecations sets the deprecated frontend APIs the module of the plugin relies on, and logs a warning with
+// their removal timeline.
+func (pm *PluginManager) detectDeprecations(plugin *plugins.PluginBase, module string) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `module` is based on the plugin folder structure on
+	// disk and not user input.
+	content, err := os.ReadFile(module)
+	if err != nil {
+		return err
+	}
+
+	plugin.Deprecations = plugins.DetectDeprecations(content)
+	for _, d := range plugin.Deprecations {
+		pm.log.Warn("Plugin relies on deprecated frontend APIs", "id", plugin.Id, "deprecation", d.ID,
+			"deprecatedSince", d.DeprecatedSince, "removedIn", d.RemovedIn, "description", d.Description)
+	}
+	return nil
+}
+
+// DeprecationReport returns the installed plugins relying on deprecated frontend APIs, sorted by plugin ID.
+func (pm *PluginManager) DeprecationReport() []plugins.PluginDeprecationReport {
+	report := []plugins.PluginDeprecationReport{}
+	for _, p := range pm.Plugins() {
+		if len(p.Deprecations) == 0 {
+			continue
+		}
+		report = append(report, plugins.PluginDeprecationReport{
+			PluginID:     p.Id,
+			Name:         p.Name,
+			Type:         p.Type,
+			Version:      p.Info.Version,
+			Deprecations: p.Deprecations,
+		})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].PluginID < report[j].PluginID
+	})
+	return report
+}
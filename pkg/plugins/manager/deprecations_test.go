@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func writePanelPlugin(t *testing.T, dir, pluginID, module string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	pluginJSON := fmt.Sprintf(`{"type": "panel", "name": "Test", "id": %q, "info": {"version": "1.0.0"}}`, pluginID)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(pluginJSON), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module.js"), []byte(module), 0600))
+}
+
+func TestPluginManager_Deprecations(t *testing.T) {
+	pluginsPath := t.TempDir()
+	writePanelPlugin(t, filepath.Join(pluginsPath, "angular-panel"), "angular-panel",
+		`define(["app/plugins/sdk","app/core/utils/kbn"],function(e,t){})`)
+	writePanelPlugin(t, filepath.Join(pluginsPath, "react-panel"), "react-panel",
+		`define(["@grafana/data","react"],function(e,t){})`)
+	newManager := func(t *testing.T, block bool) *PluginManager {
+		return createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = pluginsPath
+			pm.Cfg.PluginsAllowUnsigned = []string{"angular-panel", "react-panel"}
+			pm.Cfg.PluginsBlockAngular = block
+		})
+	}
+
+	t.Run("Should report the plugins relying on deprecated frontend APIs", func(t *testing.T) {
+		pm := newManager(t, false)
+		require.NoError(t, pm.initExternalPlugins())
+
+		require.NotNil(t, pm.GetPlugin("react-panel"))
+		require.Equal(t, []plugins.PluginDeprecationReport{{
+			PluginID:     "angular-panel",
+			Name:         "Test",
+			Type:         "panel",
+			Version:      "1.0.0",
+			Deprecations: []plugins.PluginDeprecation{plugins.DeprecationAngular, plugins.DeprecationLegacyImports},
+		}}, pm.DeprecationReport())
+	})
+
+	t.Run("Should not load Angular plugins when blocked", func(t *testing.T) {
+		pm := newManager(t, true)
+		require.NoError(t, pm.initExternalPlugins())
+
+		require.Nil(t, pm.GetPlugin("angular-panel"))
+		require.NotNil(t, pm.GetPlugin("react-panel"))
+		require.Equal(t, []plugins.PluginError{{ErrorCode: angularNotSupported, PluginID: "angular-panel"}},
+			pm.ScanningErrors())
+		require.Empty(t, pm.DeprecationReport())
+	})
+}
@@ -0,0 +1,293 @@
+package manager
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// devPluginSnapshot is the latest modification time of the files of a directory of the development plugins
+// directory, by kind of file.
+type devPluginSnapshot struct {
+	manifest time.Time
+	backend  time.Time
+	assets   time.Time
+}
+
+// devPluginChange is how a directory of the development plugins directory changed, and how its plugins are updated.
+type devPluginChange int
+
+const (
+	// devPluginAssetsChanged plugins don't need to be updated, their frontend assets are served from disk without
+	// being cached.
+	devPluginAssetsChanged devPluginChange = iota + 1
+	// devPluginBackendChanged plugins have their backend restarted.
+	devPluginBackendChanged
+	// devPluginManifestChanged plugins, or plugins added or removed, are reloaded.
+	devPluginManifestChanged
+)
+
+// devPluginDirs returns the development directories: the development plugins directory of the plugin_dev_mode, and
+// the unsigned_plugin_paths, which are only honored in development, i.e. with the development app mode or the
+// plugin_dev_mode.
+func devPluginDirs(cfg *setting.Cfg) []string {
+	var dirs []string
+	if cfg.PluginDevMode && cfg.PluginsDevPath != "" {
+		dirs = append(dirs, cfg.PluginsDevPath)
+	}
+	if cfg.Env == setting.Dev || cfg.PluginDevMode {
+		dirs = append(dirs, cfg.PluginsUnsignedPaths...)
+	}
+	return dirs
+}
+
+// inDevPluginDir returns whether the directory is in one of the development directories.
+func inDevPluginDir(cfg *setting.Cfg, dir string) bool {
+	for _, devDir := range devPluginDirs(cfg) {
+		rel, err := filepath.Rel(devDir, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDevPluginDir returns whether the plugins of the directory are development plugins, flagged with the dev badge.
+func (pm *PluginManager) isDevPluginDir(dir string) bool {
+	return inDevPluginDir(pm.Cfg, dir)
+}
+
+// scanDevPlugins loads the plugins of the development plugins directory without enforcing their signature, and the
+// plugins of the unsigned plugin paths, which are allowed to be unsigned.
+func (pm *PluginManager) scanDevPlugins() error {
+	if pm.Cfg.PluginDevMode && pm.Cfg.PluginsDevPath != "" {
+		pm.log.Debug("Scanning development plugins directory", "dir", pm.Cfg.PluginsDevPath)
+		if err := pm.scan(pm.Cfg.PluginsDevPath, false); err != nil {
+			return errutil.Wrapf(err, "failed to scan development plugins directory '%s'", pm.Cfg.PluginsDevPath)
+		}
+	}
+
+	if pm.Cfg.Env != setting.Dev && !pm.Cfg.PluginDevMode {
+		return nil
+	}
+	for _, dir := range pm.Cfg.PluginsUnsignedPaths {
+		pm.log.Debug("Scanning unsigned plugins directory", "dir", dir)
+		if err := pm.scan(dir, true); err != nil {
+			return errutil.Wrapf(err, "failed to scan unsigned plugins directory '%s'", dir)
+		}
+	}
+	return nil
+}
+
+// warnUnsignedPluginsSettings logs the unsigned plugins settings which aren't honored.
+func (pm *PluginManager) warnUnsignedPluginsSettings() {
+	var allowList []string
+	for _, id := range pm.Cfg.PluginsAllowUnsigned {
+		if id != "" {
+			allowList = append(allowList, id)
+		}
+	}
+
+	switch {
+	case len(pm.Cfg.PluginsUnsignedPaths) == 0:
+		if len(allowList) > 0 {
+			pm.log.Warn("allow_loading_unsigned_plugins is deprecated, use unsigned_plugin_paths instead",
+				"plugins", allowList)
+		}
+	case pm.Cfg.Env != setting.Dev && !pm.Cfg.PluginDevMode:
+		pm.log.Warn("Ignoring unsigned_plugin_paths, which requires the development app mode or plugin_dev_mode",
+			"paths", pm.Cfg.PluginsUnsignedPaths)
+	case len(allowList) > 0:
+		pm.log.Warn("Ignoring allow_loading_unsigned_plugins since unsigned_plugin_paths is set", "plugins", allowList)
+	}
+}
+
+// watchDevPlugins polls the files of the development plugins directory, and updates its plugins once their files
+// have stopped changing, e.g. when a build is done.
+func (pm *PluginManager) watchDevPlugins(ctx context.Context) {
+	pm.log.Info("Watching development plugins", "dir", pm.Cfg.PluginsDevPath)
+	applied := snapshotDevPlugins(pm.Cfg.PluginsDevPath)
+	last := applied
+
+	ticker := time.NewTicker(pm.Cfg.PluginsDevWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			current := snapshotDevPlugins(pm.Cfg.PluginsDevPath)
+			for dir, change := range devPluginChanges(applied, last, current) {
+				pm.applyDevPluginChange(ctx, dir, change)
+				if snapshot, ok := current[dir]; ok {
+					applied[dir] = snapshot
+				} else {
+					delete(applied, dir)
+				}
+			}
+			last = current
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// devPluginChanges returns the changes of the directories since their plugins were last updated, once they are the
+// same as at the last poll.
+func devPluginChanges(applied, last, current map[string]devPluginSnapshot) map[string]devPluginChange {
+	changes := map[string]devPluginChange{}
+	check := func(dir string) {
+		snapshot, exists := current[dir]
+		if lastSnapshot, existed := last[dir]; snapshot != lastSnapshot || exists != existed {
+			return
+		}
+		appliedSnapshot, loaded := applied[dir]
+		switch {
+		case exists != loaded || snapshot.manifest != appliedSnapshot.manifest:
+			changes[dir] = devPluginManifestChanged
+		case snapshot.backend != appliedSnapshot.backend:
+			changes[dir] = devPluginBackendChanged
+		case snapshot.assets != appliedSnapshot.assets:
+			changes[dir] = devPluginAssetsChanged
+		}
+	}
+
+	for dir := range current {
+		check(dir)
+	}
+	for dir := range applied {
+		if _, exists := current[dir]; !exists {
+			check(dir)
+		}
+	}
+	return changes
+}
+
+// snapshotDevPlugins returns the snapshots of the directories of the development plugins directory. Executable
+// files and the discovery files of standalone backends are considered backend files, and the plugin.json files
+// manifests.
+func snapshotDevPlugins(devPath string) map[string]devPluginSnapshot {
+	snapshots := map[string]devPluginSnapshot{}
+	entries, err := os.ReadDir(devPath)
+	if err != nil {
+		return snapshots
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(devPath, entry.Name())
+		var snapshot devPluginSnapshot
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if d.Name() == "node_modules" || d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			latest := &snapshot.assets
+			if d.Name() == "plugin.json" {
+				latest = &snapshot.manifest
+			} else if info.Mode()&0111 != 0 || strings.HasSuffix(d.Name(), ".exe") || isStandaloneDiscoveryFile(d.Name()) {
+				latest = &snapshot.backend
+			}
+			if info.ModTime().After(*latest) {
+				*latest = info.ModTime()
+			}
+			return nil
+		})
+		snapshots[dir] = snapshot
+	}
+	return snapshots
+}
+
+// isStandaloneDiscoveryFile returns whether the file advertises the backend process of the plugin started outside of
+// Grafana, which the backend is attached to when restarted.
+func isStandaloneDiscoveryFile(name string) bool {
+	return name == "standalone.txt" || name == "pid.txt"
+}
+
+func (pm *PluginManager) applyDevPluginChange(ctx context.Context, dir string, change devPluginChange) {
+	switch change {
+	case devPluginManifestChanged:
+		pm.reloadDevPlugins(ctx, dir)
+	case devPluginBackendChanged:
+		pm.restartDevPlugins(ctx, dir)
+	case devPluginAssetsChanged:
+		pm.log.Info("Development plugin frontend assets changed", "dir", dir)
+	}
+}
+
+// devPlugins returns the loaded plugins of a directory of the development plugins directory.
+func (pm *PluginManager) devPlugins(dir string) []*plugins.PluginBase {
+	var devPlugins []*plugins.PluginBase
+	for _, p := range pm.Plugins() {
+		if !p.IsDevPlugin {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, p.PluginDir); err == nil && !strings.HasPrefix(rel, "..") {
+			devPlugins = append(devPlugins, p)
+		}
+	}
+	return devPlugins
+}
+
+// reloadDevPlugins unloads the plugins of a directory of the development plugins directory, stopping their backends,
+// and loads them again.
+func (pm *PluginManager) reloadDevPlugins(ctx context.Context, dir string) {
+	for _, p := range pm.devPlugins(dir) {
+		if pm.BackendPluginManager.IsRegistered(p.Id) {
+			if err := pm.BackendPluginManager.UnregisterAndStop(ctx, p.Id); err != nil {
+				pm.log.Error("Failed to stop development plugin", "pluginId", p.Id, "error", err)
+			}
+		}
+		if err := pm.unregister(p); err != nil {
+			pm.log.Error("Failed to unload development plugin", "pluginId", p.Id, "error", err)
+		}
+		delete(pm.pluginScanningErrors, p.Id)
+	}
+
+	if err := pm.initExternalPlugins(); err != nil {
+		pm.log.Error("Failed to reload development plugins", "dir", dir, "error", err)
+		return
+	}
+	for _, p := range pm.devPlugins(dir) {
+		pm.log.Info("Reloaded development plugin", "pluginId", p.Id, "version", p.Info.Version)
+	}
+}
+
+// restartDevPlugins restarts the backends of the plugins of a directory of the development plugins directory, or
+// reloads the plugins if the backend plugin manager can't restart backends.
+func (pm *PluginManager) restartDevPlugins(ctx context.Context, dir string) {
+	restarter, ok := pm.BackendPluginManager.(backendplugin.Restarter)
+	if !ok {
+		pm.reloadDevPlugins(ctx, dir)
+		return
+	}
+
+	for _, p := range pm.devPlugins(dir) {
+		if !p.Backend || !pm.BackendPluginManager.IsRegistered(p.Id) {
+			continue
+		}
+		if err := restarter.RestartPlugin(ctx, p.Id); err != nil {
+			pm.log.Error("Failed to restart development plugin", "pluginId", p.Id, "error", err)
+			continue
+		}
+		pm.log.Info("Restarted development plugin", "pluginId", p.Id)
+	}
+}
@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_DevMode(t *testing.T) {
+	devPath, err := filepath.Abs("testdata/unsigned-datasource")
+	require.NoError(t, err)
+
+	t.Run("Should load unsigned development plugins", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = ""
+			pm.Cfg.PluginDevMode = true
+			pm.Cfg.PluginsDevPath = devPath
+		})
+		require.NoError(t, pm.init())
+
+		assert.Empty(t, pm.scanningErrors)
+		plugin := pm.GetPlugin("test")
+		require.NotNil(t, plugin)
+		assert.True(t, plugin.IsDevPlugin)
+		assert.Len(t, pm.devPlugins(devPath), 1)
+	})
+
+	t.Run("Should enforce the signature of development plugins when the mode is disabled", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = ""
+			pm.Cfg.PluginsDevPath = devPath
+		})
+		require.NoError(t, pm.init())
+
+		assert.Nil(t, pm.GetPlugin("test"))
+		assert.False(t, pm.isDevPluginDir(filepath.Join(devPath, "plugin")))
+	})
+}
+
+func TestPluginManager_UnsignedPluginPaths(t *testing.T) {
+	devPath, err := filepath.Abs("testdata/unsigned-datasource")
+	require.NoError(t, err)
+	otherPath, err := filepath.Abs("testdata/unsigned-panel")
+	require.NoError(t, err)
+
+	t.Run("Should only load unsigned plugins from the unsigned plugin paths", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.Env = setting.Dev
+			pm.Cfg.PluginsPath = otherPath
+			pm.Cfg.PluginsUnsignedPaths = []string{devPath}
+			pm.Cfg.PluginsAllowUnsigned = []string{"test-panel"}
+		})
+		require.NoError(t, pm.init())
+
+		plugin := pm.GetPlugin("test")
+		require.NotNil(t, plugin)
+		assert.True(t, plugin.IsDevPlugin)
+		assert.Nil(t, pm.GetPlugin("test-panel"))
+		require.Len(t, pm.scanningErrors, 1)
+	})
+
+	t.Run("Should ignore the unsigned plugin paths in production", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = ""
+			pm.Cfg.PluginsUnsignedPaths = []string{devPath}
+		})
+		require.NoError(t, pm.init())
+
+		assert.Nil(t, pm.GetPlugin("test"))
+		assert.False(t, pm.isDevPluginDir(filepath.Join(devPath, "plugin")))
+	})
+}
+
+func TestSnapshotDevPlugins(t *testing.T) {
+	devPath := t.TempDir()
+	dir := filepath.Join(devPath, "test-app")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dist", "node_modules"), 0750))
+
+	writeFile := func(name string, mode os.FileMode, modTime time.Time) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("test"), mode))
+		require.NoError(t, os.Chmod(path, mode))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)
+	writeFile("dist/plugin.json", 0640, start)
+	writeFile("dist/module.js", 0640, start.Add(time.Minute))
+	writeFile("dist/gpx_test_linux_amd64", 0750, start.Add(2*time.Minute))
+	writeFile("dist/node_modules/ignored.js", 0640, start.Add(time.Hour))
+	require.NoError(t, os.WriteFile(filepath.Join(devPath, "README.md"), []byte("test"), 0640))
+
+	snapshots := snapshotDevPlugins(devPath)
+	require.Equal(t, map[string]devPluginSnapshot{
+		dir: {manifest: start, assets: start.Add(time.Minute), backend: start.Add(2 * time.Minute)},
+	}, snapshots)
+}
+
+func TestDevPluginChanges(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	applied := map[string]devPluginSnapshot{
+		"a": {manifest: start, backend: start, assets: start},
+		"b": {manifest: start, backend: start, assets: start},
+		"c": {manifest: start, backend: start, assets: start},
+		"d": {manifest: start, backend: start, assets: start},
+	}
+	later := start.Add(time.Minute)
+	current := map[string]devPluginSnapshot{
+		"a": {manifest: later, backend: later, assets: later},
+		"b": {manifest: start, backend: later, assets: later},
+		"c": {manifest: start, backend: start, assets: later},
+		"e": {manifest: start},
+	}
+
+	t.Run("Should wait for the files to stop changing", func(t *testing.T) {
+		require.Empty(t, devPluginChanges(applied, applied, current))
+	})
+
+	t.Run("Should return the most significant change of each directory", func(t *testing.T) {
+		require.Equal(t, map[string]devPluginChange{
+			"a": devPluginManifestChanged,
+			"b": devPluginBackendChanged,
+			"c": devPluginAssetsChanged,
+			"d": devPluginManifestChanged,
+			"e": devPluginManifestChanged,
+		}, devPluginChanges(applied, current, current))
+
+		require.Empty(t, devPluginChanges(current, current, current))
+	})
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// DiskUsage returns the disk usage of the plugins installed in the plugins directory, as of their last check.
+func (pm *PluginManager) DiskUsage() []plugins.PluginDiskUsage {
+	pm.diskUsageMu.RLock()
+	defer pm.diskUsageMu.RUnlock()
+
+	usages := make([]plugins.PluginDiskUsage, 0, len(pm.diskUsage))
+	for _, usage := range pm.diskUsage {
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].PluginID < usages[j].PluginID })
+	return usages
+}
+
+// diskQuota returns the disk quota of the plugin in bytes, 0 if the plugin has no quota.
+func (pm *PluginManager) diskQuota(pluginID string) int64 {
+	return int64(pm.Cfg.PluginDiskQuotaMB(pluginID)) << 20
+}
+
+// checkInstallDiskQuota returns a PluginDiskQuotaExceededError if the newly installed plugin uses more disk than its
+// quota.
+func (pm *PluginManager) checkInstallDiskQuota(pluginID string) error {
+	quota := pm.diskQuota(pluginID)
+	if quota == 0 {
+		return nil
+	}
+	size, err := pluginDirSize(filepath.Join(pm.Cfg.PluginsPath, pluginID))
+	if err != nil {
+		return err
+	}
+	if size > quota {
+		return plugins.PluginDiskQuotaExceededError{PluginID: pluginID, Size: size, Quota: quota}
+	}
+	return nil
+}
+
+// checkDiskQuotas updates the disk usage of the plugins installed in the plugins directory, e.g. of the plugins whose
+// caches grow, and flags the plugins exceeding their quota. With the stop action, the backends of these plugins are
+// also stopped, until Grafana restarts or the plugins are installed again.
+func (pm *PluginManager) checkDiskQuotas(ctx context.Context) {
+	installed, err := installedPlugins(pm.Cfg.PluginsPath)
+	if err != nil {
+		pm.log.Error("Failed to list the installed plugins to check their disk usage", "error", err)
+		return
+	}
+
+	pm.diskUsageMu.RLock()
+	previous := pm.diskUsage
+	pm.diskUsageMu.RUnlock()
+
+	usages := make(map[string]plugins.PluginDiskUsage, len(installed))
+	for dir, plugin := range installed {
+		size, err := pluginDirSize(dir)
+		if err != nil {
+			pm.log.Warn("Failed to compute the disk usage of the plugin", "pluginId", plugin.Id, "error", err)
+			continue
+		}
+		metrics.SetPluginDiskUsage(plugin.Id, size)
+
+		usage := plugins.PluginDiskUsage{
+			PluginID: plugin.Id,
+			Size:     size,
+			Quota:    pm.diskQuota(plugin.Id),
+			Checked:  time.Now(),
+		}
+		if usage.Quota > 0 && usage.Size > usage.Quota {
+			usage.Exceeded = true
+			usage.Stopped = previous[plugin.Id].Stopped
+			pm.log.Warn("Plugin exceeds its disk quota", "pluginId", plugin.Id, "size", usage.Size,
+				"quota", usage.Quota)
+			if pm.Cfg.PluginsDiskQuotaAction == "stop" && pm.BackendPluginManager.IsRegistered(plugin.Id) {
+				if err := pm.BackendPluginManager.UnregisterAndStop(ctx, plugin.Id); err != nil {
+					pm.log.Error("Failed to stop plugin exceeding its disk quota", "pluginId", plugin.Id,
+						"error", err)
+				} else {
+					pm.log.Warn("Stopped plugin exceeding its disk quota", "pluginId", plugin.Id)
+					usage.Stopped = true
+				}
+			}
+		}
+		usages[plugin.Id] = usage
+	}
+
+	pm.diskUsageMu.Lock()
+	pm.diskUsage = usages
+	pm.diskUsageMu.Unlock()
+}
+
+// pluginDirSize returns the size of the files of the plugin directory, without following the symlinks it contains.
+func pluginDirSize(dir string) (int64, error) {
+	// the plugin directories can be symlinks
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	err = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/installer"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_DiskQuotas(t *testing.T) {
+	writePlugin := func(t *testing.T, pluginsPath, pluginID string, size int) {
+		t.Helper()
+		dir := filepath.Join(pluginsPath, pluginID)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "cache"), 0750))
+		manifest := `{"id":"` + pluginID + `","type":"datasource","name":"Test","backend":true}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(manifest), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cache", "data"), make([]byte, size), 0600))
+	}
+
+	t.Run("Should flag the plugins exceeding their quota", func(t *testing.T) {
+		backendPM := &fakeBackendPluginManager{registeredPlugins: []string{"test-large"}}
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = t.TempDir()
+			pm.Cfg.PluginsDiskQuotaMB = 1
+			pm.Cfg.PluginsDiskQuotaAction = "flag"
+			pm.Cfg.PluginSettings = setting.PluginSettings{"test-unlimited": {"disk_quota_mb": "0"}}
+			pm.BackendPluginManager = backendPM
+		})
+		writePlugin(t, pm.Cfg.PluginsPath, "test-small", 1024)
+		writePlugin(t, pm.Cfg.PluginsPath, "test-large", 2<<20)
+		writePlugin(t, pm.Cfg.PluginsPath, "test-unlimited", 2<<20)
+
+		pm.checkDiskQuotas(context.Background())
+
+		usages := pm.DiskUsage()
+		require.Len(t, usages, 3)
+		require.Equal(t, "test-large", usages[0].PluginID)
+		require.True(t, usages[0].Exceeded)
+		require.False(t, usages[0].Stopped)
+		require.Equal(t, int64(1<<20), usages[0].Quota)
+		require.Greater(t, usages[0].Size, int64(2<<20))
+		require.Equal(t, "test-small", usages[1].PluginID)
+		require.False(t, usages[1].Exceeded)
+		require.Equal(t, "test-unlimited", usages[2].PluginID)
+		require.False(t, usages[2].Exceeded)
+		require.Zero(t, usages[2].Quota)
+		require.True(t, backendPM.IsRegistered("test-large"))
+	})
+
+	t.Run("Should stop the backend of the plugins exceeding their quota", func(t *testing.T) {
+		backendPM := &fakeBackendPluginManager{registeredPlugins: []string{"test-large"}}
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = t.TempDir()
+			pm.Cfg.PluginsDiskQuotaMB = 1
+			pm.Cfg.PluginsDiskQuotaAction = "stop"
+			pm.BackendPluginManager = backendPM
+		})
+		writePlugin(t, pm.Cfg.PluginsPath, "test-large", 2<<20)
+
+		pm.checkDiskQuotas(context.Background())
+		require.False(t, backendPM.IsRegistered("test-large"))
+		require.True(t, pm.DiskUsage()[0].Stopped)
+
+		pm.checkDiskQuotas(context.Background())
+		require.True(t, pm.DiskUsage()[0].Stopped)
+	})
+
+	t.Run("Should reject the installs exceeding the quota", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = t.TempDir()
+			pm.Cfg.PluginsDiskQuotaMB = 1
+		})
+		writePlugin(t, pm.Cfg.PluginsPath, "test-small", 1024)
+		writePlugin(t, pm.Cfg.PluginsPath, "test-large", 2<<20)
+
+		require.NoError(t, pm.checkInstallDiskQuota("test-small"))
+		err := pm.checkInstallDiskQuota("test-large")
+		var quotaErr plugins.PluginDiskQuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, int64(1<<20), quotaErr.Quota)
+	})
+
+	t.Run("Should reject the archives exceeding the quota before extracting them", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = t.TempDir()
+			pm.Cfg.PluginsDiskQuotaMB = 1
+		})
+		writePlugin(t, pm.Cfg.PluginsPath, "test-large", 1024)
+
+		archive := filepath.Join(t.TempDir(), "test-large.zip")
+		f, err := os.Create(archive)
+		require.NoError(t, err)
+		w := zip.NewWriter(f)
+		manifest := `{"id":"test-large","type":"datasource","name":"Test","info":{"version":"2.0.0"}}`
+		for name, content := range map[string][]byte{
+			"test-large/plugin.json": []byte(manifest),
+			"test-large/data":        make([]byte, 2<<20),
+		} {
+			header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+			header.SetMode(0644)
+			fw, err := w.CreateHeader(header)
+			require.NoError(t, err)
+			_, err = fw.Write(content)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		require.NoError(t, f.Close())
+
+		i := installer.New(false, "8.3.0", installerLog)
+		i.SetDiskQuota(pm.diskQuota)
+		err = i.Install(context.Background(), "test-large", "", pm.Cfg.PluginsPath, archive, "")
+		var quotaErr plugins.PluginDiskQuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, int64(1<<20), quotaErr.Quota)
+
+		// the previous installation is left as it was
+		require.FileExists(t, filepath.Join(pm.Cfg.PluginsPath, "test-large", "cache", "data"))
+		require.NoFileExists(t, filepath.Join(pm.Cfg.PluginsPath, "test-large", "data"))
+	})
+}
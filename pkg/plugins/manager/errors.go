@@ -8,4 +8,8 @@ const (
 	signatureMissing  plugins.ErrorCode = "signatureMissing"
 	signatureModified plugins.ErrorCode = "signatureModified"
 	signatureInvalid  plugins.ErrorCode = "signatureInvalid"
+	loadingFailed     plugins.ErrorCode = "loadingFailed"
+
+	incompatibleGrafanaVersion plugins.ErrorCode = "incompatibleGrafanaVersion"
+	angularNotSupported        plugins.ErrorCode = "angularNotSupported"
 )
@@ -0,0 +1,235 @@
+package installer
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+const (
+	// zipCreatorUnix and zipCreatorMacOSX are the hosts of the archives whose members have Unix permissions, the
+	// permissions of the members of the other archives, e.g. created on Windows, are made up by archive/zip.
+	zipCreatorUnix   = 3
+	zipCreatorMacOSX = 19
+	// maxSymlinkTargetSize bounds the size of the symlink targets read from the archives.
+	maxSymlinkTargetSize = 4096
+)
+
+// reBackendExecutable matches the suffixes of the names of the backend executables of the plugins, one per OS and
+// architecture.
+var reBackendExecutable = regexp.MustCompile(`^_(linux|darwin|windows|freebsd)_(amd64|arm64|arm|386)(\.exe)?$`)
+
+// ArchiveReport returns the security report of the inspection of the last archive of the plugin, if any.
+func (i *Installer) ArchiveReport(pluginID string) (plugins.PluginArchiveReport, bool) {
+	i.reportsMu.RLock()
+	defer i.reportsMu.RUnlock()
+	report, exists := i.reports[pluginID]
+	return report, exists
+}
+
+func (i *Installer) storeArchiveReport(report plugins.PluginArchiveReport) {
+	i.reportsMu.Lock()
+	defer i.reportsMu.Unlock()
+	i.reports[report.PluginID] = report
+}
+
+// inspectArchive inspects the members of the archive of the plugin before any of them is extracted in dest, and
+// reports the members written outside of dest, the symlinks whose target is outside of the plugin directory or goes
+// through another symlink, the executables which aren't the backend executable of the plugin, and the files and
+// directories writable by all users.
+func inspectArchive(archiveFile string, r *zip.Reader, pluginID, dest string) (plugins.PluginArchiveReport, error) {
+	checksum, err := fileSHA256(archiveFile)
+	if err != nil {
+		return plugins.PluginArchiveReport{}, err
+	}
+	report := plugins.PluginArchiveReport{
+		PluginID:      pluginID,
+		ArchiveSHA256: checksum,
+		Inspected:     time.Now(),
+		Violations:    []plugins.PluginArchiveViolation{},
+	}
+	addViolation := func(kind plugins.PluginArchiveViolationKind, zf *zip.File, format string, args ...interface{}) {
+		report.Violations = append(report.Violations, plugins.PluginArchiveViolation{
+			Kind:    kind,
+			Path:    zf.Name,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	pluginDir := filepath.Join(dest, pluginID)
+	executables := backendExecutables(r, pluginID, dest)
+	symlinks := archiveSymlinks(r, pluginID, dest)
+	for _, zf := range r.File {
+		if !zf.FileInfo().IsDir() {
+			report.Files++
+		}
+
+		dstPath := filepath.Clean(filepath.Join(dest, removeGitBuildFromName(zf.Name, pluginID)))
+		if !isArchiveMemberInDir(zf.Name, dest) || !isInDir(dstPath, dest) {
+			addViolation(plugins.PluginArchivePathTraversal, zf, "written outside of the plugins directory")
+			continue
+		}
+
+		// the permissions of the symlinks are those of their target
+		perm, hasPerm := unixPermissions(zf)
+		if hasPerm && perm&0002 != 0 && !isSymlink(zf) {
+			addViolation(plugins.PluginArchiveWorldWritable, zf, "writable by all users (%s)", perm)
+		}
+
+		switch {
+		case isSymlink(zf):
+			target, err := readSymlinkTarget(zf)
+			if err != nil {
+				return plugins.PluginArchiveReport{}, err
+			}
+			switch {
+			case target == "" || filepath.IsAbs(target) ||
+				!isInDir(filepath.Join(filepath.Dir(dstPath), target), pluginDir):
+				addViolation(plugins.PluginArchiveSymlinkEscape, zf, "symlink to %q outside of the plugin directory",
+					target)
+			case throughSymlink(dstPath, target, symlinks):
+				addViolation(plugins.PluginArchiveSymlinkChain, zf, "symlink to %q through another symlink", target)
+			}
+		case zf.FileInfo().IsDir():
+		case (hasPerm && perm&0111 != 0) || strings.EqualFold(filepath.Ext(zf.Name), ".exe"):
+			if !executables[dstPath] {
+				addViolation(plugins.PluginArchiveUnexpectedExecutable, zf,
+					"executable which isn't the backend executable of the plugin")
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// backendExecutables returns the paths, once extracted, of the backend executables of the plugin, i.e. of the files
+// named after the executable of the plugin.json files of the archive and next to them.
+func backendExecutables(r *zip.Reader, pluginID, dest string) map[string]bool {
+	executables := map[string]bool{}
+	var manifests []string
+	for _, zf := range r.File {
+		if filepath.Base(zf.Name) != "plugin.json" || zf.FileInfo().IsDir() {
+			continue
+		}
+		var manifest struct {
+			Executable string `json:"executable"`
+		}
+		if err := readJSONMember(zf, &manifest); err != nil || manifest.Executable == "" {
+			continue
+		}
+		dir := filepath.Dir(filepath.Clean(filepath.Join(dest, removeGitBuildFromName(zf.Name, pluginID))))
+		manifests = append(manifests, filepath.Join(dir, manifest.Executable))
+	}
+
+	for _, zf := range r.File {
+		dstPath := filepath.Clean(filepath.Join(dest, removeGitBuildFromName(zf.Name, pluginID)))
+		for _, prefix := range manifests {
+			if strings.HasPrefix(dstPath, prefix) && reBackendExecutable.MatchString(dstPath[len(prefix):]) {
+				executables[dstPath] = true
+			}
+		}
+	}
+	return executables
+}
+
+func readJSONMember(zf *zip.File, v interface{}) error {
+	src, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	return json.NewDecoder(src).Decode(v)
+}
+
+// archiveSymlinks returns the paths, once extracted, of the symlinks of the archive.
+func archiveSymlinks(r *zip.Reader, pluginID, dest string) map[string]bool {
+	symlinks := map[string]bool{}
+	for _, zf := range r.File {
+		if isSymlink(zf) {
+			symlinks[filepath.Clean(filepath.Join(dest, removeGitBuildFromName(zf.Name, pluginID)))] = true
+		}
+	}
+	return symlinks
+}
+
+// throughSymlink returns whether one of the elements of the target of the symlink extracted at dstPath is another
+// symlink of the archive. The target is checked element by element since the file system resolves the symlinks
+// before the following ".." elements, unlike filepath.Join.
+func throughSymlink(dstPath, target string, symlinks map[string]bool) bool {
+	p := filepath.Dir(dstPath)
+	for _, elem := range strings.Split(filepath.ToSlash(target), "/") {
+		p = filepath.Join(p, elem)
+		if symlinks[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// readSymlinkTarget returns the target of the symlink as it's extracted, i.e. the content of the archive member.
+func readSymlinkTarget(zf *zip.File) (string, error) {
+	src, err := zf.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read symlink %q: %w", zf.Name, err)
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	target, err := ioutil.ReadAll(io.LimitReader(src, maxSymlinkTargetSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read symlink %q: %w", zf.Name, err)
+	}
+	return string(target), nil
+}
+
+// unixPermissions returns the Unix permissions of the archive member, if the archive was created on a Unix host.
+func unixPermissions(zf *zip.File) (os.FileMode, bool) {
+	switch zf.CreatorVersion >> 8 {
+	case zipCreatorUnix, zipCreatorMacOSX:
+		return zf.Mode().Perm(), true
+	default:
+		return 0, false
+	}
+}
+
+// isArchiveMemberInDir returns whether the archive member is extracted in dir, i.e. isn't absolute and doesn't
+// traverse its parents. More info about ZipSlip: http://bit.ly/2MsjAWE
+func isArchiveMemberInDir(name, dir string) bool {
+	// We can ignore gosec G305 here since we check for the ZipSlip vulnerability
+	// nolint:gosec
+	fullPath := filepath.Join(dir, name)
+	return !filepath.IsAbs(name) && isInDir(fullPath, dir) && !strings.HasPrefix(name, ".."+string(os.PathSeparator))
+}
+
+func isInDir(path, dir string) bool {
+	return strings.HasPrefix(filepath.Clean(path), filepath.Clean(dir)+string(os.PathSeparator))
+}
+
+func fileSHA256(path string) (string, error) {
+	// It's safe to ignore gosec warning G304 since the path is the one of the archive being installed
+	// nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
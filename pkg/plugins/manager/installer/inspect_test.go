@@ -0,0 +1,153 @@
+package installer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveMember struct {
+	name    string
+	mode    os.FileMode
+	content string
+}
+
+func writeArchive(t *testing.T, members []archiveMember) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	for _, m := range members {
+		header := &zip.FileHeader{Name: m.name, Method: zip.Deflate}
+		header.SetMode(m.mode)
+		fw, err := w.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(m.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+	return path
+}
+
+func TestInspectArchive(t *testing.T) {
+	manifest := archiveMember{name: "test-datasource/plugin.json", mode: 0644,
+		content: `{"id":"test-datasource","executable":"gpx_test"}`}
+	dest := t.TempDir()
+
+	tcs := []struct {
+		desc       string
+		members    []archiveMember
+		violations []plugins.PluginArchiveViolationKind
+	}{
+		{
+			desc: "Should accept the backend executables and the symlinks in the plugin directory",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/gpx_test_linux_amd64", mode: 0755},
+				{name: "test-datasource/gpx_test_windows_amd64.exe", mode: 0644},
+				{name: "test-datasource/img/", mode: os.ModeDir | 0755},
+				{name: "test-datasource/img/logo.svg", mode: 0644},
+				{name: "test-datasource/logo.svg", mode: os.ModeSymlink | 0777, content: "img/logo.svg"},
+			},
+		},
+		{
+			desc: "Should report the members written outside of the plugins directory",
+			members: []archiveMember{
+				manifest,
+				{name: "../member.txt", mode: 0644},
+				{name: "/member.txt", mode: 0644},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchivePathTraversal, plugins.PluginArchivePathTraversal,
+			},
+		},
+		{
+			desc: "Should report the symlinks escaping the plugin directory",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/passwd", mode: os.ModeSymlink | 0777, content: "/etc/passwd"},
+				{name: "test-datasource/other", mode: os.ModeSymlink | 0777, content: "../other-plugin/module.js"},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchiveSymlinkEscape, plugins.PluginArchiveSymlinkEscape,
+			},
+		},
+		{
+			desc: "Should report the symlinks through other symlinks",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/img/", mode: os.ModeDir | 0755},
+				{name: "test-datasource/img/logo.svg", mode: 0644},
+				{name: "test-datasource/logo.svg", mode: os.ModeSymlink | 0777, content: "img/logo.svg"},
+				{name: "test-datasource/icon.svg", mode: os.ModeSymlink | 0777, content: "logo.svg"},
+				{name: "test-datasource/assets", mode: os.ModeSymlink | 0777, content: "img"},
+				{name: "test-datasource/module.js", mode: os.ModeSymlink | 0777, content: "assets/../module.js"},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchiveSymlinkChain, plugins.PluginArchiveSymlinkChain,
+			},
+		},
+		{
+			desc: "Should check the symlink targets as they are extracted",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/passwd", mode: os.ModeSymlink | 0777, content: "/etc/passwd\n"},
+				{name: "test-datasource/empty", mode: os.ModeSymlink | 0777},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchiveSymlinkEscape, plugins.PluginArchiveSymlinkEscape,
+			},
+		},
+		{
+			desc: "Should report the executables which aren't the backend executable",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/install.sh", mode: 0755},
+				{name: "test-datasource/img/gpx_test_linux_amd64", mode: 0755},
+				{name: "test-datasource/setup.exe", mode: 0644},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchiveUnexpectedExecutable, plugins.PluginArchiveUnexpectedExecutable,
+				plugins.PluginArchiveUnexpectedExecutable,
+			},
+		},
+		{
+			desc: "Should report the world-writable files and directories",
+			members: []archiveMember{
+				manifest,
+				{name: "test-datasource/img/", mode: os.ModeDir | 0777},
+				{name: "test-datasource/module.js", mode: 0666},
+			},
+			violations: []plugins.PluginArchiveViolationKind{
+				plugins.PluginArchiveWorldWritable, plugins.PluginArchiveWorldWritable,
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			archive := writeArchive(t, tc.members)
+			r, err := zip.OpenReader(archive)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = r.Close()
+			})
+
+			report, err := inspectArchive(archive, &r.Reader, "test-datasource", dest)
+			require.NoError(t, err)
+			require.Equal(t, "test-datasource", report.PluginID)
+			require.Len(t, report.ArchiveSHA256, 64)
+
+			var violations []plugins.PluginArchiveViolationKind
+			for _, v := range report.Violations {
+				violations = append(violations, v.Kind)
+			}
+			require.Equal(t, tc.violations, violations)
+		})
+	}
+}
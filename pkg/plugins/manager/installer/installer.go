@@ -3,7 +3,6 @@ package installer
 import (
 	"archive/zip"
 	"bufio"
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
@@ -21,6 +20,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/plugins"
@@ -34,6 +34,12 @@ type Installer struct {
 	httpClientNoTimeout http.Client
 	grafanaVersion      string
 	log                 plugins.PluginInstallerLogger
+	// diskQuota returns the disk quota of a plugin in bytes, 0 if the plugin has no quota.
+	diskQuota func(pluginID string) int64
+
+	// reports are the security reports of the inspection of the last archive of each plugin.
+	reports   map[string]plugins.PluginArchiveReport
+	reportsMu sync.RWMutex
 }
 
 const (
@@ -86,9 +92,16 @@ func New(skipTLSVerify bool, grafanaVersion string, logger plugins.PluginInstall
 		httpClientNoTimeout: makeHttpClient(skipTLSVerify, 0),
 		log:                 logger,
 		grafanaVersion:      grafanaVersion,
+		reports:             map[string]plugins.PluginArchiveReport{},
 	}
 }
 
+// SetDiskQuota sets the function returning the disk quota of a plugin in bytes, 0 if the plugin has no quota. The
+// archives of the plugins whose files exceed their quota are rejected before anything is extracted.
+func (i *Installer) SetDiskQuota(diskQuota func(pluginID string) int64) {
+	i.diskQuota = diskQuota
+}
+
 // Install downloads the plugin code as a zip file from specified URL
 // and then extracts the zip into the provided plugins directory.
 func (i *Installer) Install(ctx context.Context, pluginID, version, pluginsDir, pluginZipURL, pluginRepoURL string) error {
@@ -517,6 +530,36 @@ func (i *Installer) extractFiles(archiveFile string, pluginID string, dest strin
 	}
 	i.log.Debug(fmt.Sprintf("Extracting archive %q to %q...", archiveFile, dest))
 
+	r, err := zip.OpenReader(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			i.log.Warn("failed to close zip file", "err", err)
+		}
+	}()
+
+	// the archive is inspected before anything is extracted, or the existing installation removed
+	report, err := inspectArchive(archiveFile, &r.Reader, pluginID, dest)
+	if err != nil {
+		return errutil.Wrap("failed to inspect plugin archive", err)
+	}
+	i.storeArchiveReport(report)
+	if len(report.Violations) > 0 {
+		return plugins.PluginArchiveSecurityError{Report: report}
+	}
+
+	// the size of the files is checked before anything is extracted, and the bytes written are capped in case the
+	// archive understates it
+	var quota int64
+	if i.diskQuota != nil {
+		quota = i.diskQuota(pluginID)
+	}
+	if size := archiveFilesSize(&r.Reader); quota > 0 && size > quota {
+		return plugins.PluginDiskQuotaExceededError{PluginID: pluginID, Size: size, Quota: quota}
+	}
+
 	existingInstallDir := filepath.Join(dest, pluginID)
 	if _, err := os.Stat(existingInstallDir); !os.IsNotExist(err) {
 		i.log.Debugf("Removing existing installation of plugin %s", existingInstallDir)
@@ -526,24 +569,9 @@ func (i *Installer) extractFiles(archiveFile string, pluginID string, dest strin
 		}
 	}
 
-	r, err := zip.OpenReader(archiveFile)
-	defer func() {
-		if err := r.Close(); err != nil {
-			i.log.Warn("failed to close zip file", "err", err)
-		}
-	}()
-	if err != nil {
-		return err
-	}
+	var written int64
 	for _, zf := range r.File {
-		// We can ignore gosec G305 here since we check for the ZipSlip vulnerability below
-		// nolint:gosec
-		fullPath := filepath.Join(dest, zf.Name)
-
-		// Check for ZipSlip. More Info: http://bit.ly/2MsjAWE
-		if filepath.IsAbs(zf.Name) ||
-			!strings.HasPrefix(fullPath, filepath.Clean(dest)+string(os.PathSeparator)) ||
-			strings.HasPrefix(zf.Name, ".."+string(os.PathSeparator)) {
+		if !isArchiveMemberInDir(zf.Name, dest) {
 			return fmt.Errorf(
 				"archive member %q tries to write outside of plugin directory: %q, this can be a security risk",
 				zf.Name, dest)
@@ -584,7 +612,19 @@ func (i *Installer) extractFiles(archiveFile string, pluginID string, dest strin
 			continue
 		}
 
-		if err := extractFile(zf, dstPath); err != nil {
+		maxSize := int64(-1)
+		if quota > 0 {
+			maxSize = quota - written
+		}
+		n, err := extractFile(zf, dstPath, maxSize)
+		written += n
+		if quota > 0 && written > quota {
+			if err := os.RemoveAll(existingInstallDir); err != nil {
+				i.log.Warn("failed to remove plugin exceeding its disk quota", "err", err)
+			}
+			return plugins.PluginDiskQuotaExceededError{PluginID: pluginID, Size: written, Quota: quota}
+		}
+		if err != nil {
 			return errutil.Wrap("failed to extract file", err)
 		}
 	}
@@ -592,27 +632,36 @@ func (i *Installer) extractFiles(archiveFile string, pluginID string, dest strin
 	return nil
 }
 
+// archiveFilesSize returns the uncompressed size of the regular files of the archive, as declared by the archive.
+func archiveFilesSize(r *zip.Reader) int64 {
+	var size int64
+	for _, zf := range r.File {
+		if zf.Mode().IsRegular() {
+			size += int64(zf.UncompressedSize64)
+		}
+	}
+	return size
+}
+
 func isSymlink(file *zip.File) bool {
 	return file.Mode()&os.ModeSymlink == os.ModeSymlink
 }
 
 func extractSymlink(file *zip.File, filePath string) error {
-	// symlink target is the contents of the file
-	src, err := file.Open()
+	// symlink target is the contents of the file, as checked by inspectArchive
+	target, err := readSymlinkTarget(file)
 	if err != nil {
-		return errutil.Wrap("failed to extract file", err)
-	}
-	buf := new(bytes.Buffer)
-	if _, err := io.Copy(buf, src); err != nil {
-		return errutil.Wrap("failed to copy symlink contents", err)
+		return err
 	}
-	if err := os.Symlink(strings.TrimSpace(buf.String()), filePath); err != nil {
+	if err := os.Symlink(target, filePath); err != nil {
 		return errutil.Wrapf(err, "failed to make symbolic link for %v", filePath)
 	}
 	return nil
 }
 
-func extractFile(file *zip.File, filePath string) (err error) {
+// extractFile extracts the file of the archive, writing at most maxSize+1 bytes unless maxSize is negative, and returns
+// the number of bytes written, so that the callers can tell whether the file exceeds maxSize.
+func extractFile(file *zip.File, filePath string, maxSize int64) (written int64, err error) {
 	fileMode := file.Mode()
 	// This is entry point for backend plugins so we want to make them executable
 	if strings.HasSuffix(filePath, "_linux_amd64") || strings.HasSuffix(filePath, "_darwin_amd64") {
@@ -626,15 +675,15 @@ func extractFile(file *zip.File, filePath string) (err error) {
 	dst, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileMode)
 	if err != nil {
 		if os.IsPermission(err) {
-			return fmt.Errorf(permissionsDeniedMessage, filePath)
+			return 0, fmt.Errorf(permissionsDeniedMessage, filePath)
 		}
 
 		unwrappedError := errors.Unwrap(err)
 		if unwrappedError != nil && strings.EqualFold(unwrappedError.Error(), "text file busy") {
-			return fmt.Errorf("file %q is in use - please stop Grafana, install the plugin and restart Grafana", filePath)
+			return 0, fmt.Errorf("file %q is in use - please stop Grafana, install the plugin and restart Grafana", filePath)
 		}
 
-		return errutil.Wrap("failed to open file", err)
+		return 0, errutil.Wrap("failed to open file", err)
 	}
 	defer func() {
 		err = dst.Close()
@@ -642,14 +691,18 @@ func extractFile(file *zip.File, filePath string) (err error) {
 
 	src, err := file.Open()
 	if err != nil {
-		return errutil.Wrap("failed to extract file", err)
+		return 0, errutil.Wrap("failed to extract file", err)
 	}
 	defer func() {
 		err = src.Close()
 	}()
 
-	_, err = io.Copy(dst, src)
-	return err
+	var r io.Reader = src
+	if maxSize >= 0 {
+		r = io.LimitReader(src, maxSize+1)
+	}
+	written, err = io.Copy(dst, r)
+	return written, err
 }
 
 func removeGitBuildFromName(filename, pluginID string) string {
@@ -0,0 +1,33 @@
+package installer
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFile(t *testing.T) {
+	archive := writeArchive(t, []archiveMember{{name: "test-datasource/module.js", mode: 0644, content: "0123456789"}})
+	r, err := zip.OpenReader(archive)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = r.Close()
+	})
+	dst := filepath.Join(t.TempDir(), "module.js")
+
+	written, err := extractFile(r.File[0], dst, -1)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), written)
+	require.FileExists(t, dst)
+
+	// one byte over the limit tells the file exceeds it
+	written, err = extractFile(r.File[0], dst, 4)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), written)
+
+	written, err = extractFile(r.File[0], dst, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), written)
+}
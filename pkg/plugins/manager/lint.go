@@ -0,0 +1,440 @@
+package manager
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// maxLintArchiveSize bounds the size of the files extracted from a plugin archive to validate.
+const maxLintArchiveSize = 1 << 30
+
+// lintIncludeTypes are the types of the includes of plugin.json.
+var lintIncludeTypes = map[string]bool{"dashboard": true, "page": true, "panel": true, "datasource": true}
+
+// ValidateArchive extracts a plugin archive, e.g. a plugin build in CI, into a temporary directory and checks the
+// plugins it contains like they are checked when loaded, without loading them.
+func (pm *PluginManager) ValidateArchive(ctx context.Context, archivePath string) (plugins.PluginLintReport, error) {
+	dir, err := os.MkdirTemp("", "plugin-lint")
+	if err != nil {
+		return plugins.PluginLintReport{}, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			pm.log.Warn("Failed to remove plugin lint directory", "dir", dir, "error", err)
+		}
+	}()
+
+	l := &pluginLinter{pm: pm, root: dir}
+	if err := l.extract(archivePath); err != nil {
+		l.add(plugins.PluginLintRuleArchive, plugins.PluginLintError, "", "", err.Error())
+		return l.report(), nil
+	}
+
+	var manifests []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && info.Name() == "plugin.json" {
+			manifests = append(manifests, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return plugins.PluginLintReport{}, err
+	}
+	if len(manifests) == 0 {
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintError, "", "", "no plugin.json found in the archive")
+	}
+	for _, manifest := range manifests {
+		if ctx.Err() != nil {
+			return plugins.PluginLintReport{}, ctx.Err()
+		}
+		l.lintPlugin(manifest)
+	}
+	return l.report(), nil
+}
+
+type pluginLinter struct {
+	pm        *PluginManager
+	root      string
+	pluginIDs []string
+	results   []plugins.PluginLintResult
+}
+
+func (l *pluginLinter) add(rule string, severity plugins.PluginLintSeverity, pluginID, path, message string) {
+	l.results = append(l.results, plugins.PluginLintResult{
+		Rule:     rule,
+		Severity: severity,
+		PluginID: pluginID,
+		Path:     path,
+		Message:  message,
+	})
+}
+
+func (l *pluginLinter) report() plugins.PluginLintReport {
+	report := plugins.PluginLintReport{Valid: true, Plugins: l.pluginIDs, Results: l.results}
+	if report.Plugins == nil {
+		report.Plugins = []string{}
+	}
+	if report.Results == nil {
+		report.Results = []plugins.PluginLintResult{}
+	}
+	for _, r := range report.Results {
+		if r.Severity == plugins.PluginLintError {
+			report.Valid = false
+		}
+	}
+	return report
+}
+
+// relPath returns the path of a file in the archive.
+func (l *pluginLinter) relPath(path string) string {
+	rel, err := filepath.Rel(l.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// extract extracts the archive into the root directory of the linter. Unlike when installing plugins, files outside
+// of the root directory and symbolic links are reported rather than skipped.
+func (l *pluginLinter) extract(archivePath string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			l.pm.log.Warn("Failed to close plugin archive", "error", err)
+		}
+	}()
+
+	var size uint64
+	for _, zf := range r.File {
+		// nolint:gosec
+		dstPath := filepath.Join(l.root, zf.Name)
+		if filepath.IsAbs(zf.Name) || !strings.HasPrefix(dstPath, l.root+string(os.PathSeparator)) {
+			l.add(plugins.PluginLintRuleArchive, plugins.PluginLintError, "", zf.Name,
+				"archive member is outside of the plugin directory")
+			continue
+		}
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(dstPath, 0750); err != nil {
+				return err
+			}
+			continue
+		}
+		if zf.Mode()&os.ModeSymlink != 0 {
+			l.add(plugins.PluginLintRuleArchive, plugins.PluginLintError, "", zf.Name,
+				"archive member is a symbolic link, which is skipped when installing the plugin")
+			continue
+		}
+
+		size += zf.UncompressedSize64
+		if size > maxLintArchiveSize {
+			return fmt.Errorf("archive is larger than %d bytes", maxLintArchiveSize)
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0750); err != nil {
+			return err
+		}
+		if err := extractLintFile(zf, dstPath); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", zf.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractLintFile(zf *zip.File, dstPath string) error {
+	src, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	// the permissions are kept, to check that the backend binaries are executable
+	// nolint:gosec
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, zf.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	// nolint:gosec
+	if _, err := io.Copy(dst, io.LimitReader(src, maxLintArchiveSize)); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// lintPlugin checks the plugin of a plugin.json of the archive.
+func (l *pluginLinter) lintPlugin(manifestPath string) {
+	manifestRelPath := l.relPath(manifestPath)
+	// nolint:gosec
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintError, "", manifestRelPath, err.Error())
+		return
+	}
+
+	var plugin plugins.PluginBase
+	var backend struct {
+		Executable string `json:"executable"`
+	}
+	if err := json.Unmarshal(content, &plugin); err != nil {
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintError, "", manifestRelPath,
+			fmt.Sprintf("invalid plugin.json: %s", err))
+		return
+	}
+	_ = json.Unmarshal(content, &backend)
+	if plugin.Id == "" || plugin.Type == "" {
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintError, plugin.Id, manifestRelPath,
+			"plugin.json must set the id and type of the plugin")
+		return
+	}
+	l.pluginIDs = append(l.pluginIDs, plugin.Id)
+	plugin.PluginDir = filepath.Dir(manifestPath)
+
+	switch plugin.Type {
+	case "panel", "datasource", "app", "renderer", "secretsmanager":
+	default:
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintError, plugin.Id, manifestRelPath,
+			fmt.Sprintf("unknown plugin type %q", plugin.Type))
+		return
+	}
+	if plugin.Info.Version == "" {
+		l.add(plugins.PluginLintRuleManifest, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+			"info.version isn't set, the version of the plugin is set when it's built")
+	}
+
+	l.lintModule(&plugin)
+	l.lintAssets(&plugin, manifestRelPath)
+	l.lintIncludes(&plugin, manifestRelPath)
+	l.lintRoles(&plugin, manifestRelPath)
+	l.lintLiveChannels(&plugin, manifestRelPath)
+	l.lintSignature(&plugin)
+	l.lintExecutable(&plugin, backend.Executable, manifestRelPath)
+	l.lintGrafanaDependency(&plugin, manifestRelPath)
+}
+
+func (l *pluginLinter) lintModule(plugin *plugins.PluginBase) {
+	if (&PluginScanner{}).IsBackendOnlyPlugin(plugin.Type) {
+		return
+	}
+	module := filepath.Join(plugin.PluginDir, "module.js")
+	// nolint:gosec
+	content, err := os.ReadFile(module)
+	if err != nil {
+		l.add(plugins.PluginLintRuleModule, plugins.PluginLintError, plugin.Id, l.relPath(module),
+			"module.js not found, the frontend of the plugin can't be loaded")
+		return
+	}
+	for _, d := range plugins.DetectDeprecations(content) {
+		l.add(plugins.PluginLintRuleDeprecation, plugins.PluginLintWarning, plugin.Id, l.relPath(module),
+			fmt.Sprintf("%s, deprecated since Grafana %s and to be removed in Grafana %s", d.Description,
+				d.DeprecatedSince, d.RemovedIn))
+	}
+}
+
+func (l *pluginLinter) lintAssets(plugin *plugins.PluginBase, manifestRelPath string) {
+	if (&PluginScanner{}).IsBackendOnlyPlugin(plugin.Type) {
+		return
+	}
+	logos := []struct{ name, path string }{{"small", plugin.Info.Logos.Small}, {"large", plugin.Info.Logos.Large}}
+	for _, logo := range logos {
+		if logo.path == "" {
+			l.add(plugins.PluginLintRuleLogos, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+				fmt.Sprintf("info.logos.%s isn't set, the default logo is shown", logo.name))
+			continue
+		}
+		l.checkFile(plugins.PluginLintRuleLogos, plugin, manifestRelPath, logo.path,
+			fmt.Sprintf("logo %s", logo.path))
+	}
+	for _, screenshot := range plugin.Info.Screenshots {
+		l.checkFile(plugins.PluginLintRuleScreenshots, plugin, manifestRelPath, screenshot.Path,
+			fmt.Sprintf("screenshot %s", screenshot.Path))
+	}
+}
+
+// checkFile reports an error if the file of a path of plugin.json, relative to the plugin directory, isn't in the
+// archive. URLs aren't checked.
+func (l *pluginLinter) checkFile(rule string, plugin *plugins.PluginBase, manifestRelPath, path, what string) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return
+	}
+	filePath := filepath.Join(plugin.PluginDir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(plugin.PluginDir, filePath); err != nil || strings.HasPrefix(rel, "..") {
+		l.add(rule, plugins.PluginLintError, plugin.Id, manifestRelPath,
+			fmt.Sprintf("%s is outside of the plugin directory", what))
+		return
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		l.add(rule, plugins.PluginLintError, plugin.Id, manifestRelPath, fmt.Sprintf("%s not found", what))
+	}
+}
+
+func (l *pluginLinter) lintIncludes(plugin *plugins.PluginBase, manifestRelPath string) {
+	for i, include := range plugin.Includes {
+		what := fmt.Sprintf("include %d", i)
+		if include.Name != "" {
+			what = fmt.Sprintf("include %q", include.Name)
+		}
+		add := func(message string) {
+			l.add(plugins.PluginLintRuleIncludes, plugins.PluginLintError, plugin.Id, manifestRelPath,
+				fmt.Sprintf("%s %s", what, message))
+		}
+
+		if include.Name == "" {
+			add("has no name")
+		}
+		if !lintIncludeTypes[include.Type] {
+			add(fmt.Sprintf("has an unknown type %q", include.Type))
+			continue
+		}
+		if include.Role != "" && !include.Role.IsValid() {
+			add(fmt.Sprintf("has an unknown role %q", include.Role))
+		}
+
+		switch include.Type {
+		case "dashboard":
+			if include.Path == "" {
+				add("has no path")
+				continue
+			}
+			l.checkFile(plugins.PluginLintRuleIncludes, plugin, manifestRelPath, include.Path,
+				fmt.Sprintf("dashboard %s of %s", include.Path, what))
+			// nolint:gosec
+			if content, err := os.ReadFile(filepath.Join(plugin.PluginDir, filepath.FromSlash(include.Path))); err == nil &&
+				!json.Valid(content) {
+				add(fmt.Sprintf("has an invalid dashboard JSON %s", include.Path))
+			}
+		case "page":
+			if include.Component == "" && include.Path == "" {
+				add("has neither a component nor a path")
+			}
+		}
+	}
+}
+
+func (l *pluginLinter) lintSignature(plugin *plugins.PluginBase) {
+	details, err := getPluginSignatureDetails(l.pm.log, plugin)
+	if err != nil {
+		l.add(plugins.PluginLintRuleSignature, plugins.PluginLintError, plugin.Id, "",
+			fmt.Sprintf("failed to verify the signature: %s", err))
+		return
+	}
+
+	switch details.Status {
+	case plugins.PluginSignatureValid:
+	case plugins.PluginSignatureUnsigned:
+		l.add(plugins.PluginLintRuleSignature, plugins.PluginLintWarning, plugin.Id, "",
+			"plugin is unsigned, it's only loaded if allowed by allow_loading_unsigned_plugins")
+	default:
+		reported := false
+		for _, f := range details.Files {
+			path := l.relPath(filepath.Join(plugin.PluginDir, filepath.FromSlash(f.Path)))
+			switch f.Status {
+			case plugins.PluginFileModified:
+				l.add(plugins.PluginLintRuleSignature, plugins.PluginLintError, plugin.Id, path,
+					"file doesn't match the checksum of MANIFEST.txt")
+			case plugins.PluginFileMissing:
+				l.add(plugins.PluginLintRuleSignature, plugins.PluginLintError, plugin.Id, path,
+					"file listed in MANIFEST.txt not found")
+			case plugins.PluginFileUnsigned:
+				l.add(plugins.PluginLintRuleSignature, plugins.PluginLintError, plugin.Id, path,
+					"file isn't included in MANIFEST.txt")
+			default:
+				continue
+			}
+			reported = true
+		}
+		if !reported {
+			l.add(plugins.PluginLintRuleSignature, plugins.PluginLintError, plugin.Id, "",
+				fmt.Sprintf("signature is %s: %s", details.Status, details.Reason))
+		}
+	}
+}
+
+// lintExecutable checks that the backend plugins declare their executable, and that the archive contains its
+// binaries, named <executable>_<os>_<arch>.
+func (l *pluginLinter) lintExecutable(plugin *plugins.PluginBase, executable, manifestRelPath string) {
+	if executable == "" {
+		if plugin.Backend {
+			l.add(plugins.PluginLintRuleExecutable, plugins.PluginLintError, plugin.Id, manifestRelPath,
+				"backend is set but executable isn't, the backend of the plugin can't be started")
+		}
+		return
+	}
+	if !plugin.Backend && !(&PluginScanner{}).IsBackendOnlyPlugin(plugin.Type) {
+		l.add(plugins.PluginLintRuleExecutable, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+			"executable is set but backend isn't, the backend of the plugin isn't started")
+	}
+
+	prefix := filepath.Join(plugin.PluginDir, filepath.FromSlash(executable)) + "_"
+	binaries, err := filepath.Glob(prefix + "*")
+	if err != nil || len(binaries) == 0 {
+		l.add(plugins.PluginLintRuleExecutable, plugins.PluginLintError, plugin.Id, manifestRelPath,
+			fmt.Sprintf("no binary found for the executable %s, expected %s_<os>_<arch>", executable, executable))
+		return
+	}
+	sort.Strings(binaries)
+
+	current := prefix + runtime.GOOS + "_" + runtime.GOARCH
+	foundCurrent := false
+	for _, binary := range binaries {
+		if strings.TrimSuffix(binary, ".exe") == current {
+			foundCurrent = true
+		}
+		info, err := os.Stat(binary)
+		if err != nil || strings.HasSuffix(binary, ".exe") {
+			continue
+		}
+		if info.Mode()&0111 == 0 {
+			l.add(plugins.PluginLintRuleExecutable, plugins.PluginLintWarning, plugin.Id, l.relPath(binary),
+				"binary isn't executable")
+		}
+	}
+	if !foundCurrent {
+		l.add(plugins.PluginLintRuleExecutable, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+			fmt.Sprintf("no binary for the platform of this server, %s_%s_%s", executable, runtime.GOOS,
+				runtime.GOARCH))
+	}
+}
+
+func (l *pluginLinter) lintRoles(plugin *plugins.PluginBase, manifestRelPath string) {
+	if err := plugins.ValidatePluginRoles(plugin.Id, plugin.Roles); err != nil {
+		l.add(plugins.PluginLintRuleRoles, plugins.PluginLintError, plugin.Id, manifestRelPath, err.Error())
+	}
+}
+
+func (l *pluginLinter) lintLiveChannels(plugin *plugins.PluginBase, manifestRelPath string) {
+	if err := plugins.ValidateLiveChannels(plugin.LiveChannels); err != nil {
+		l.add(plugins.PluginLintRuleLiveChannels, plugins.PluginLintError, plugin.Id, manifestRelPath, err.Error())
+	}
+}
+
+func (l *pluginLinter) lintGrafanaDependency(plugin *plugins.PluginBase, manifestRelPath string) {
+	grafanaVersion := plugin.Dependencies.GrafanaVersion
+	if grafanaVersion == "" {
+		l.add(plugins.PluginLintRuleGrafanaDependency, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+			"dependencies.grafanaVersion isn't set, the plugin is considered compatible with every Grafana version")
+		return
+	}
+	if !plugins.IsGrafanaVersionCompatible(grafanaVersion, l.pm.Cfg.BuildVersion) {
+		l.add(plugins.PluginLintRuleGrafanaDependency, plugins.PluginLintWarning, plugin.Id, manifestRelPath,
+			fmt.Sprintf("dependencies.grafanaVersion %s doesn't include the Grafana version %s", grafanaVersion,
+				l.pm.Cfg.BuildVersion))
+	}
+}
@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_ValidateArchive(t *testing.T) {
+	writeArchive := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "plugin.zip")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		w := zip.NewWriter(f)
+		for name, content := range files {
+			fw, err := w.Create(name)
+			require.NoError(t, err)
+			_, err = fw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		require.NoError(t, f.Close())
+		return path
+	}
+	rules := func(report plugins.PluginLintReport, severity plugins.PluginLintSeverity) []string {
+		var rules []string
+		for _, r := range report.Results {
+			if r.Severity == severity {
+				rules = append(rules, r.Rule)
+			}
+		}
+		return rules
+	}
+
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.BuildVersion = "8.3.0"
+	})
+
+	t.Run("Should accept a valid unsigned plugin", func(t *testing.T) {
+		archive := writeArchive(t, map[string]string{
+			"test-panel/plugin.json": `{"id": "test-panel", "type": "panel", "name": "Test",
+				"info": {"version": "1.0.0", "logos": {"small": "img/logo.svg", "large": "img/logo.svg"}},
+				"dependencies": {"grafanaVersion": ">=8.0.0"}}`,
+			"test-panel/module.js":    "define([], function() {})",
+			"test-panel/img/logo.svg": "<svg/>",
+		})
+
+		report, err := pm.ValidateArchive(context.Background(), archive)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Equal(t, []string{"test-panel"}, report.Plugins)
+		require.Equal(t, []string{plugins.PluginLintRuleSignature}, rules(report, plugins.PluginLintWarning))
+	})
+
+	t.Run("Should report the problems of the plugin", func(t *testing.T) {
+		archive := writeArchive(t, map[string]string{
+			"test-datasource/dist/plugin.json": `{"id": "test-datasource", "type": "datasource", "name": "Test",
+				"backend": true, "info": {"version": "1.0.0", "logos": {"small": "img/missing.svg"}},
+				"includes": [{"name": "Overview", "type": "dashboard", "path": "dashboards/missing.json"},
+					{"name": "Other", "type": "report"}],
+				"dependencies": {"grafanaVersion": ">=9.0.0"}}`,
+		})
+
+		report, err := pm.ValidateArchive(context.Background(), archive)
+		require.NoError(t, err)
+		require.False(t, report.Valid)
+		require.ElementsMatch(t, []string{
+			plugins.PluginLintRuleModule,
+			plugins.PluginLintRuleLogos,
+			plugins.PluginLintRuleIncludes,
+			plugins.PluginLintRuleIncludes,
+			plugins.PluginLintRuleExecutable,
+		}, rules(report, plugins.PluginLintError))
+		require.ElementsMatch(t, []string{
+			plugins.PluginLintRuleLogos,
+			plugins.PluginLintRuleSignature,
+			plugins.PluginLintRuleGrafanaDependency,
+		}, rules(report, plugins.PluginLintWarning))
+		for _, r := range report.Results {
+			require.Equal(t, "test-datasource", r.PluginID)
+		}
+	})
+
+	t.Run("Should report archives without plugins", func(t *testing.T) {
+		report, err := pm.ValidateArchive(context.Background(), writeArchive(t, map[string]string{"README.md": "test"}))
+		require.NoError(t, err)
+		require.False(t, report.Valid)
+		require.Equal(t, []string{plugins.PluginLintRuleManifest}, rules(report, plugins.PluginLintError))
+
+		notArchive := filepath.Join(t.TempDir(), "plugin.zip")
+		require.NoError(t, os.WriteFile(notArchive, []byte("test"), 0600))
+		report, err = pm.ValidateArchive(context.Background(), notArchive)
+		require.NoError(t, err)
+		require.False(t, report.Valid)
+		require.Equal(t, []string{plugins.PluginLintRuleArchive}, rules(report, plugins.PluginLintError))
+	})
+}
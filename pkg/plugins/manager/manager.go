@@ -11,10 +11,12 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/fs"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/manager/installer"
+	"github.com/grafana/grafana/pkg/plugins/pluginassets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -48,6 +51,7 @@ type PluginScanner struct {
 	log                           log.Logger
 	plugins                       map[string]*plugins.PluginBase
 	allowUnsignedPluginsCondition unsignedPluginConditionFunc
+	profiler                      *startupProfiler
 }
 
 type PluginManager struct {
@@ -55,6 +59,7 @@ type PluginManager struct {
 	Cfg                  *setting.Cfg
 	SQLStore             *sqlstore.SQLStore
 	pluginInstaller      plugins.PluginInstaller
+	pluginAssets         *pluginassets.Service
 	log                  log.Logger
 	scanningErrors       []error
 
@@ -63,22 +68,36 @@ type PluginManager struct {
 	AllowUnsignedPluginsCondition unsignedPluginConditionFunc
 	grafanaLatestVersion          string
 	grafanaHasUpdate              bool
+	advisories                    []plugins.PluginAdvisory
+	advisoriesMu                  sync.RWMutex
 	pluginScanningErrors          map[string]plugins.PluginError
-
-	renderer     *plugins.RendererPlugin
-	dataSources  map[string]*plugins.DataSourcePlugin
-	plugins      map[string]*plugins.PluginBase
-	panels       map[string]*plugins.PanelPlugin
-	apps         map[string]*plugins.AppPlugin
-	staticRoutes []*plugins.PluginStaticRoute
-	pluginsMu    sync.RWMutex
+	pluginSettingsCache           *pluginSettingsCache
+	startupProfiler               *startupProfiler
+	// started is set once the plugins are loaded at startup, after which the failure policies don't apply anymore.
+	started bool
+	// diskUsage is the disk usage of the plugins installed in the plugins directory, by plugin ID.
+	diskUsage   map[string]plugins.PluginDiskUsage
+	diskUsageMu sync.RWMutex
+
+	renderer       *plugins.RendererPlugin
+	remoteRenderer *plugins.RendererPlugin
+	secretsManager *plugins.SecretsManagerPlugin
+	dataSources    map[string]*plugins.DataSourcePlugin
+	plugins        map[string]*plugins.PluginBase
+	panels         map[string]*plugins.PanelPlugin
+	apps           map[string]*plugins.AppPlugin
+	staticRoutes   []*plugins.PluginStaticRoute
+	pluginsMu      sync.RWMutex
 }
 
-func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, backendPM backendplugin.Manager) (*PluginManager, error) {
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, backendPM backendplugin.Manager,
+	pluginAssets *pluginassets.Service) (*PluginManager, error) {
 	pm := newManager(cfg, sqlStore, backendPM)
+	pm.pluginAssets = pluginAssets
 	if err := pm.init(); err != nil {
 		return nil, err
 	}
+	bus.AddEventListener(pm.handlePluginSettingUpdated)
 	return pm, nil
 }
 
@@ -92,15 +111,24 @@ func newManager(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, backendPM backend
 		panels:               map[string]*plugins.PanelPlugin{},
 		apps:                 map[string]*plugins.AppPlugin{},
 		pluginScanningErrors: map[string]plugins.PluginError{},
+		pluginSettingsCache:  newPluginSettingsCache(),
+		startupProfiler:      newStartupProfiler(),
 		log:                  log.New("plugins"),
 	}
 }
 
 func (pm *PluginManager) init() error {
 	plog = log.New("plugins")
-	pm.pluginInstaller = installer.New(false, pm.Cfg.BuildVersion, installerLog)
+	pluginInstaller := installer.New(false, pm.Cfg.BuildVersion, installerLog)
+	pluginInstaller.SetDiskQuota(pm.diskQuota)
+	pm.pluginInstaller = pluginInstaller
+
+	if pm.Cfg.RendererUrl != "" {
+		pm.remoteRenderer = plugins.NewRemoteRendererPlugin(pm.Cfg.RendererUrl, pm.Cfg.RendererAuthToken, pm.Cfg.BuildVersion)
+	}
 
 	pm.log.Info("Starting plugin search")
+	pm.warnUnsignedPluginsSettings()
 
 	plugDir := filepath.Join(pm.Cfg.StaticRootPath, "app/plugins")
 	pm.log.Debug("Scanning core plugin directory", "dir", plugDir)
@@ -120,10 +148,21 @@ func (pm *PluginManager) init() error {
 		}
 	}
 
-	return pm.initExternalPlugins()
+	if err := pm.initExternalPlugins(); err != nil {
+		return err
+	}
+
+	pm.initCanaryPlugins()
+	pm.started = true
+	return nil
 }
 
 func (pm *PluginManager) initExternalPlugins() error {
+	// the plugins in development take precedence over their installed versions
+	if err := pm.scanDevPlugins(); err != nil {
+		return err
+	}
+
 	// check if plugins dir exists
 	exists, err := fs.Exists(pm.Cfg.PluginsPath)
 	if err != nil {
@@ -150,22 +189,30 @@ func (pm *PluginManager) initExternalPlugins() error {
 
 	var staticRoutesList []*plugins.PluginStaticRoute
 	for _, panel := range pm.Panels() {
+		start := time.Now()
 		staticRoutes := panel.InitFrontendPlugin(pm.Cfg)
+		pm.startupProfiler.record(panel.Id, plugins.StartupPhaseInitialize, start)
 		staticRoutesList = append(staticRoutesList, staticRoutes...)
 	}
 
 	for _, ds := range pm.DataSources() {
+		start := time.Now()
 		staticRoutes := ds.InitFrontendPlugin(pm.Cfg)
+		pm.startupProfiler.record(ds.Id, plugins.StartupPhaseInitialize, start)
 		staticRoutesList = append(staticRoutesList, staticRoutes...)
 	}
 
 	for _, app := range pm.Apps() {
+		start := time.Now()
 		staticRoutes := app.InitApp(pm.panels, pm.dataSources, pm.Cfg)
+		pm.startupProfiler.record(app.Id, plugins.StartupPhaseInitialize, start)
 		staticRoutesList = append(staticRoutesList, staticRoutes...)
 	}
 
-	if pm.Renderer() != nil {
+	if pm.renderer != nil {
+		start := time.Now()
 		staticRoutes := pm.renderer.InitFrontendPlugin(pm.Cfg)
+		pm.startupProfiler.record(pm.renderer.Id, plugins.StartupPhaseInitialize, start)
 		staticRoutesList = append(staticRoutesList, staticRoutes...)
 	}
 	pm.staticRoutes = staticRoutesList
@@ -182,15 +229,30 @@ func (pm *PluginManager) initExternalPlugins() error {
 }
 
 func (pm *PluginManager) Run(ctx context.Context) error {
+	if pm.Cfg.PluginDevMode && pm.Cfg.PluginsDevPath != "" {
+		go pm.watchDevPlugins(ctx)
+	}
+	go pm.syncPluginAssets(ctx)
+
 	pm.checkForUpdates()
 
-	ticker := time.NewTicker(time.Minute * 10)
+	var diskQuotaTicker <-chan time.Time
+	if pm.Cfg.PluginsDiskQuotaCheckInterval > 0 {
+		pm.checkDiskQuotas(ctx)
+		t := time.NewTicker(pm.Cfg.PluginsDiskQuotaCheckInterval)
+		defer t.Stop()
+		diskQuotaTicker = t.C
+	}
+
+	ticker := time.NewTicker(pm.Cfg.UpdateCheckInterval)
 	run := true
 
 	for run {
 		select {
 		case <-ticker.C:
 			pm.checkForUpdates()
+		case <-diskQuotaTicker:
+			pm.checkDiskQuotas(ctx)
 		case <-ctx.Done():
 			run = false
 		}
@@ -199,13 +261,25 @@ func (pm *PluginManager) Run(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// Renderer returns the renderer plugin installed locally, or the remote rendering service if configured and no
+// renderer plugin is installed.
 func (pm *PluginManager) Renderer() *plugins.RendererPlugin {
 	pm.pluginsMu.RLock()
 	defer pm.pluginsMu.RUnlock()
 
+	if pm.renderer == nil {
+		return pm.remoteRenderer
+	}
 	return pm.renderer
 }
 
+func (pm *PluginManager) SecretsManager() *plugins.SecretsManagerPlugin {
+	pm.pluginsMu.RLock()
+	defer pm.pluginsMu.RUnlock()
+
+	return pm.secretsManager
+}
+
 func (pm *PluginManager) GetDataSource(id string) *plugins.DataSourcePlugin {
 	pm.pluginsMu.RLock()
 	defer pm.pluginsMu.RUnlock()
@@ -320,16 +394,68 @@ func (pm *PluginManager) scanPluginPaths() error {
 	return nil
 }
 
+// isDisabledCorePlugin returns whether the plugin is a core plugin disabled with the disable setting.
+func (pm *PluginManager) isDisabledCorePlugin(plugin *plugins.PluginBase) bool {
+	if !strings.HasPrefix(plugin.PluginDir, pm.Cfg.StaticRootPath) {
+		return false
+	}
+	for _, id := range pm.Cfg.PluginsDisabled {
+		if id == plugin.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// failedPluginsError returns an error listing the plugins of a scan which failed to load, if the failure policy of
+// their class, core or external, aborts startup.
+func (pm *PluginManager) failedPluginsError(scanned map[string]*plugins.PluginBase) error {
+	var failures []string
+	for _, plugin := range scanned {
+		pluginErr, failed := pm.pluginScanningErrors[plugin.Id]
+		if !failed {
+			continue
+		}
+		if loaded := pm.GetPlugin(plugin.Id); loaded != nil && loaded.PluginDir == plugin.PluginDir {
+			continue
+		}
+
+		class, policy := "external", pm.Cfg.PluginsExternalFailurePolicy
+		if strings.HasPrefix(plugin.PluginDir, pm.Cfg.StaticRootPath) {
+			class, policy = "core", pm.Cfg.PluginsCoreFailurePolicy
+		}
+		if policy != "abort" {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s plugin '%s' failed to load: %s", class, plugin.Id,
+			pluginErr.ErrorCode))
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	sort.Strings(failures)
+	return errors.New(strings.Join(failures, ", "))
+}
+
 // scan a directory for plugins.
 func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
+	return pm.scanPlugins(pluginDir, requireSigned, pm.BackendPluginManager, "")
+}
+
+// scanPlugins scans a directory for plugins, registering their backends with the backend plugin manager. The plugin
+// with the reloaded ID is loaded even though it's already installed.
+func (pm *PluginManager) scanPlugins(pluginDir string, requireSigned bool,
+	backendPluginManager backendplugin.Manager, reloadedPluginID string) error {
 	scanner := &PluginScanner{
 		pluginPath:                    pluginDir,
-		backendPluginManager:          pm.BackendPluginManager,
+		backendPluginManager:          backendPluginManager,
 		cfg:                           pm.Cfg,
 		requireSigned:                 requireSigned,
 		log:                           pm.log,
 		plugins:                       map[string]*plugins.PluginBase{},
 		allowUnsignedPluginsCondition: pm.AllowUnsignedPluginsCondition,
+		profiler:                      pm.startupProfiler,
 	}
 
 	// 1st pass: Scan plugins, also mapping plugins to their respective directories
@@ -362,18 +488,25 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 		}
 		pluginsByID[scannedPlugin.Id] = struct{}{}
 
+		if pm.isDisabledCorePlugin(scannedPlugin) {
+			pm.log.Info("Skipping core plugin as it's disabled", "id", scannedPlugin.Id)
+			delete(scanner.plugins, scannedPluginPath)
+			continue
+		}
+
 		// Check if scanning found plugins that are already installed
-		if existing := pm.GetPlugin(scannedPlugin.Id); existing != nil {
+		if existing := pm.GetPlugin(scannedPlugin.Id); existing != nil && existing.Id != reloadedPluginID {
 			pm.log.Debug("Skipping plugin as it's already installed", "plugin", existing.Id, "version", existing.Info.Version)
 			delete(scanner.plugins, scannedPluginPath)
 		}
 	}
 
 	pluginTypes := map[string]interface{}{
-		"panel":      plugins.PanelPlugin{},
-		"datasource": plugins.DataSourcePlugin{},
-		"app":        plugins.AppPlugin{},
-		"renderer":   plugins.RendererPlugin{},
+		"panel":          plugins.PanelPlugin{},
+		"datasource":     plugins.DataSourcePlugin{},
+		"app":            plugins.AppPlugin{},
+		"renderer":       plugins.RendererPlugin{},
+		"secretsmanager": plugins.SecretsManagerPlugin{},
 	}
 
 	// 2nd pass: Validate and register plugins
@@ -402,6 +535,21 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 			continue
 		}
 
+		if !strings.HasPrefix(plugin.PluginDir, pm.Cfg.StaticRootPath) &&
+			!plugins.IsGrafanaVersionCompatible(plugin.Dependencies.GrafanaVersion, pm.Cfg.BuildVersion) {
+			if pm.Cfg.PluginsBlockIncompatible {
+				pm.log.Error("Plugin is not compatible with the Grafana version. Will skip loading", "id", plugin.Id,
+					"grafanaVersion", plugin.Dependencies.GrafanaVersion, "version", pm.Cfg.BuildVersion)
+				pm.pluginScanningErrors[plugin.Id] = plugins.PluginError{
+					ErrorCode: incompatibleGrafanaVersion,
+					PluginID:  plugin.Id,
+				}
+				continue
+			}
+			pm.log.Warn("Plugin is not compatible with the Grafana version", "id", plugin.Id,
+				"grafanaVersion", plugin.Dependencies.GrafanaVersion, "version", pm.Cfg.BuildVersion)
+		}
+
 		pm.log.Debug("Attempting to add plugin", "id", plugin.Id)
 
 		pluginGoType, exists := pluginTypes[plugin.Type]
@@ -423,6 +571,17 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 					"name", plugin.Name,
 					"warning", "Missing module.js, If you loaded this plugin from git, make sure to compile it.",
 					"path", module)
+			} else if err := pm.detectDeprecations(plugin, module); err != nil {
+				return err
+			}
+
+			if pm.Cfg.PluginsBlockAngular && plugins.HasDeprecation(plugin.Deprecations, plugins.DeprecationAngular.ID) {
+				pm.log.Error("Plugin is based on AngularJS, which is blocked. Will skip loading", "id", plugin.Id)
+				pm.pluginScanningErrors[plugin.Id] = plugins.PluginError{
+					ErrorCode: angularNotSupported,
+					PluginID:  plugin.Id,
+				}
+				continue
 			}
 		}
 
@@ -445,7 +604,12 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 
 		// Load the full plugin, and add it to manager
 		if err := pm.loadPlugin(jsonParser, plugin, scanner, loader); err != nil {
-			return err
+			pm.log.Error("Failed to load plugin. Will skip loading", "id", plugin.Id, "err", err)
+			pm.pluginScanningErrors[plugin.Id] = plugins.PluginError{
+				ErrorCode: loadingFailed,
+				PluginID:  plugin.Id,
+			}
+			continue
 		}
 	}
 
@@ -458,12 +622,28 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 		pm.scanningErrors = scanner.errors
 	}
 
+	// the failure policies only apply to startup, not to the plugins installed or reloaded afterwards
+	if !pm.started {
+		return pm.failedPluginsError(scanner.plugins)
+	}
 	return nil
 }
 
 func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugins.PluginBase,
 	scanner *PluginScanner, loader plugins.PluginLoader) error {
-	plug, err := loader.Load(jsonParser, pluginBase, scanner.backendPluginManager)
+	backendPluginManager := &profilingBackendPluginManager{
+		Manager:  scanner.backendPluginManager,
+		profiler: scanner.profiler,
+	}
+	// development plugins are known before loading, their backend can be attached to a standalone process
+	pluginBase.IsDevPlugin = pm.isDevPluginDir(pluginBase.PluginDir)
+	if err := plugins.ValidatePluginRoles(pluginBase.Id, pluginBase.Roles); err != nil {
+		return fmt.Errorf("invalid roles: %w", err)
+	}
+	if err := plugins.ValidateLiveChannels(pluginBase.LiveChannels); err != nil {
+		return fmt.Errorf("invalid live channels: %w", err)
+	}
+	plug, err := loader.Load(jsonParser, pluginBase, backendPluginManager)
 	if err != nil {
 		return err
 	}
@@ -482,6 +662,9 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 	case *plugins.RendererPlugin:
 		pm.renderer = p
 		pb = &p.PluginBase
+	case *plugins.SecretsManagerPlugin:
+		pm.secretsManager = p
+		pb = &p.PluginBase
 	case *plugins.AppPlugin:
 		pm.apps[p.Id] = p
 		pb = &p.PluginBase
@@ -513,8 +696,27 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 	pb.SignatureType = pluginBase.SignatureType
 	pb.SignatureOrg = pluginBase.SignatureOrg
 	pb.SignedFiles = pluginBase.SignedFiles
+	pb.Deprecations = pluginBase.Deprecations
+	pb.IsDevPlugin = pluginBase.IsDevPlugin
+	pb.FeatureToggles = pm.Cfg.PluginFeatureToggles(pb.Id)
+	if pb.Locales, err = plugins.DiscoverLocales(pb.PluginDir); err != nil {
+		pm.log.Warn("Failed to discover the locales of the plugin", "id", pb.Id, "err", err)
+	}
+	if pm.Cfg.PluginsVersionedAssetURLs && !pb.IsCorePlugin && !pb.IsDevPlugin {
+		if pb.AssetVersion, err = plugins.ComputeAssetVersion(pb); err != nil {
+			pm.log.Warn("Failed to compute the asset version of the plugin", "id", pb.Id, "err", err)
+		}
+	}
+	// the modules of the plugins in development change whenever they're rebuilt
+	pb.ModuleIntegrity = ""
+	if !pb.IsCorePlugin && !pb.IsDevPlugin && pm.Cfg.Env != setting.Dev {
+		if pb.ModuleIntegrity, err = plugins.ComputeModuleIntegrity(pb); err != nil {
+			pm.log.Warn("Failed to compute the module integrity of the plugin", "id", pb.Id, "err", err)
+		}
+	}
 
 	pm.plugins[pb.Id] = pb
+	pm.pluginSettingsCache.invalidateAll()
 	pm.log.Debug("Successfully added plugin", "id", pb.Id)
 	return nil
 }
@@ -531,6 +733,10 @@ func (s *PluginScanner) walker(currentPath string, f os.FileInfo, err error) err
 		return util.ErrWalkSkipDir
 	}
 
+	if f.IsDir() && f.Name() == upgradeStagingDirName {
+		return util.ErrWalkSkipDir
+	}
+
 	if f.IsDir() {
 		return nil
 	}
@@ -549,6 +755,7 @@ func (s *PluginScanner) walker(currentPath string, f os.FileInfo, err error) err
 
 func (s *PluginScanner) loadPlugin(pluginJSONFilePath string) error {
 	s.log.Debug("Loading plugin", "path", pluginJSONFilePath)
+	start := time.Now()
 	currentDir := filepath.Dir(pluginJSONFilePath)
 	// nolint:gosec
 	// We can ignore the gosec G304 warning on this one because `currentPath` is based
@@ -573,8 +780,12 @@ func (s *PluginScanner) loadPlugin(pluginJSONFilePath string) error {
 		return errors.New("did not find type or id properties in plugin.json")
 	}
 
+	s.profiler.record(pluginCommon.Id, plugins.StartupPhaseScan, start)
+
 	pluginCommon.PluginDir = filepath.Dir(pluginJSONFilePath)
+	start = time.Now()
 	signatureState, err := getPluginSignatureState(s.log, &pluginCommon)
+	s.profiler.record(pluginCommon.Id, plugins.StartupPhaseSignature, start)
 	if err != nil {
 		s.log.Warn("Could not get plugin signature state", "pluginID", pluginCommon.Id, "err", err)
 		return err
@@ -590,7 +801,7 @@ func (s *PluginScanner) loadPlugin(pluginJSONFilePath string) error {
 }
 
 func (*PluginScanner) IsBackendOnlyPlugin(pluginType string) bool {
-	return pluginType == "renderer"
+	return pluginType == "renderer" || pluginType == "secretsmanager"
 }
 
 // validateSignature validates a plugin's signature.
@@ -657,6 +868,11 @@ func (s *PluginScanner) allowUnsigned(plugin *plugins.PluginBase) bool {
 		return s.allowUnsignedPluginsCondition(plugin)
 	}
 
+	// with unsigned plugin paths, unsigned plugins are only allowed from the development directories
+	if len(s.cfg.PluginsUnsignedPaths) > 0 {
+		return inDevPluginDir(s.cfg, plugin.PluginDir)
+	}
+
 	if s.cfg.Env == setting.Dev {
 		return true
 	}
@@ -670,7 +886,7 @@ func (s *PluginScanner) allowUnsigned(plugin *plugins.PluginBase) bool {
 	return false
 }
 
-// ScanningErrors returns plugin scanning errors encountered.
+// ScanningErrors returns plugin scanning errors encountered, sorted by plugin ID.
 func (pm *PluginManager) ScanningErrors() []plugins.PluginError {
 	scanningErrs := make([]plugins.PluginError, 0)
 	for id, e := range pm.pluginScanningErrors {
@@ -679,6 +895,9 @@ func (pm *PluginManager) ScanningErrors() []plugins.PluginError {
 			PluginID:  id,
 		})
 	}
+	sort.Slice(scanningErrs, func(i, j int) bool {
+		return scanningErrs[i].PluginID < scanningErrs[j].PluginID
+	})
 	return scanningErrs
 }
 
@@ -718,6 +937,26 @@ func (pm *PluginManager) GetPluginMarkdown(pluginId string, name string) ([]byte
 	return data, nil
 }
 
+func (pm *PluginManager) SignatureDetails(pluginID string) (plugins.PluginSignatureDetails, error) {
+	plug, exists := pm.plugins[pluginID]
+	if !exists {
+		return plugins.PluginSignatureDetails{}, plugins.PluginNotFoundError{PluginID: pluginID}
+	}
+
+	if plug.IsCorePlugin || plug.Signature == plugins.PluginSignatureInternal {
+		return plugins.PluginSignatureDetails{
+			Status: plugins.PluginSignatureInternal,
+			Files:  []plugins.PluginFileVerification{},
+		}, nil
+	}
+
+	// descendant plugins are signed by the manifest of their root plugin
+	if plug.Root != nil {
+		plug = plug.Root
+	}
+	return getPluginSignatureDetails(pm.log, plug)
+}
+
 func (pm *PluginManager) StaticRoutes() []*plugins.PluginStaticRoute {
 	return pm.staticRoutes
 }
@@ -725,7 +964,16 @@ func (pm *PluginManager) StaticRoutes() []*plugins.PluginStaticRoute {
 func (pm *PluginManager) Install(ctx context.Context, pluginID, version string) error {
 	plugin := pm.GetPlugin(pluginID)
 
+	if !pm.Cfg.IsPluginInstallAllowed(pluginID) {
+		return plugins.ErrInstallNotAllowed
+	}
+
+	if err := pm.checkVulnerableVersion(pluginID, version); err != nil {
+		return err
+	}
+
 	var pluginZipURL string
+	upgradedBlueGreen := false
 	if plugin != nil {
 		if plugin.IsCorePlugin {
 			return plugins.ErrInstallCorePlugin
@@ -746,26 +994,111 @@ func (pm *PluginManager) Install(ctx context.Context, pluginID, version string)
 
 		pluginZipURL = updateInfo.PluginZipURL
 
+		// the backend of the previous version serves the requests until the new one is healthy when possible
+		if pm.canUpgradeBlueGreen(plugin) {
+			err = pm.upgradeBlueGreen(ctx, plugin, version, pluginZipURL)
+			if err == nil {
+				upgradedBlueGreen = true
+			} else if !errors.Is(err, errBlueGreenUpgradeUnavailable) {
+				return err
+			}
+		}
+
 		// remove existing installation of plugin
-		err = pm.Uninstall(context.Background(), plugin.Id)
+		if !upgradedBlueGreen {
+			err = pm.Uninstall(context.Background(), plugin.Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !upgradedBlueGreen {
+		delete(pm.pluginScanningErrors, pluginID)
+		err := pm.pluginInstaller.Install(ctx, pluginID, version, pm.Cfg.PluginsPath, pluginZipURL, grafanaComURL)
 		if err != nil {
 			return err
 		}
+
+		err = pm.initExternalPlugins()
+		if err != nil {
+			return err
+		}
+
+		if pm.GetPlugin(pluginID) == nil && pm.pluginScanningErrors[pluginID].ErrorCode == incompatibleGrafanaVersion {
+			pm.log.Error("Installed plugin is not compatible with the Grafana version, rolling back", "pluginId", pluginID)
+			pm.rollbackIncompatibleInstall(context.Background(), pluginID, plugin)
+			return plugins.IncompatibleGrafanaVersionError{PluginID: pluginID, GrafanaVersion: pm.Cfg.BuildVersion}
+		}
 	}
 
-	err := pm.pluginInstaller.Install(ctx, pluginID, version, pm.Cfg.PluginsPath, pluginZipURL, grafanaComURL)
-	if err != nil {
-		return err
+	if installed := pm.GetPlugin(pluginID); installed != nil {
+		// the quota is checked again before the backend of the plugin is started for the verification
+		if err := pm.checkInstallDiskQuota(pluginID); err != nil {
+			pm.log.Error("Installed plugin exceeds its disk quota, rolling back", "pluginId", pluginID, "error", err)
+			pm.rollbackInstall(context.Background(), pluginID, plugin)
+			return err
+		}
+		if err := pm.verifyInstall(ctx, installed); err != nil {
+			pm.log.Error("Installed plugin failed verification, rolling back", "pluginId", pluginID, "error", err)
+			pm.rollbackInstall(context.Background(), pluginID, plugin)
+			return plugins.PluginVerificationError{PluginID: pluginID, Err: err}
+		}
+		// the other instances serve the assets of the plugin from the object storage
+		if err := pm.pluginAssets.Sync(ctx, installed); err != nil {
+			pm.log.Error("Failed to sync installed plugin assets, rolling back", "pluginId", pluginID, "error", err)
+			pm.rollbackInstall(context.Background(), pluginID, plugin)
+			return fmt.Errorf("failed to sync the assets of plugin %s: %w", pluginID, err)
+		}
+
+		// the latest version is only known once installed
+		if version == "" {
+			if err := pm.checkVulnerableVersion(pluginID, installed.Info.Version); err != nil {
+				if uninstallErr := pm.Uninstall(context.Background(), pluginID); uninstallErr != nil {
+					plog.Error("Failed to uninstall vulnerable plugin version", "pluginId", pluginID, "error", uninstallErr)
+				}
+				return err
+			}
+		}
+		installed.Advisories = pm.affectingAdvisories(pluginID, installed.Info.Version)
 	}
 
-	err = pm.initExternalPlugins()
-	if err != nil {
-		return err
+	if plugin != nil {
+		// the plugin is installed, so a failure to process the upgrade, e.g. to update its dashboards, is only logged
+		if upgraded := pm.GetPlugin(pluginID); upgraded != nil {
+			err := bus.Publish(&models.PluginUpgradedEvent{
+				PluginId:        pluginID,
+				PreviousVersion: plugin.Info.Version,
+				Version:         upgraded.Info.Version,
+			})
+			if err != nil {
+				plog.Error("Failed to process plugin upgrade", "pluginId", pluginID, "error", err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// InstallReport returns the security report of the inspection of the archive of the last install of the plugin, if
+// any. Archives failing the inspection aren't extracted.
+func (pm *PluginManager) InstallReport(pluginID string) (plugins.PluginArchiveReport, bool) {
+	return pm.pluginInstaller.ArchiveReport(pluginID)
+}
+
+// checkVulnerableVersion returns a VulnerablePluginVersionError if installing vulnerable plugin versions is blocked
+// and the version of the plugin is affected by security advisories.
+func (pm *PluginManager) checkVulnerableVersion(pluginID, version string) error {
+	if !pm.Cfg.PluginsBlockVulnerableInstalls || version == "" {
+		return nil
+	}
+
+	if advisories := pm.affectingAdvisories(pluginID, version); len(advisories) > 0 {
+		return plugins.VulnerablePluginVersionError{PluginID: pluginID, Version: version, Advisories: advisories}
+	}
+	return nil
+}
+
 func (pm *PluginManager) Uninstall(ctx context.Context, pluginID string) error {
 	plugin := pm.GetPlugin(pluginID)
 	if plugin == nil {
@@ -810,9 +1143,12 @@ func (pm *PluginManager) unregister(plugin *plugins.PluginBase) error {
 		delete(pm.apps, plugin.Id)
 	case "renderer":
 		pm.renderer = nil
+	case "secretsmanager":
+		pm.secretsManager = nil
 	}
 
 	delete(pm.plugins, plugin.Id)
+	pm.pluginSettingsCache.invalidateAll()
 
 	pm.removeStaticRoute(plugin.Id)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -421,6 +422,45 @@ func TestPluginManager_Init(t *testing.T) {
 	})
 }
 
+func TestPluginManager_DisabledCorePlugins(t *testing.T) {
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.PluginsPath = ""
+		pm.Cfg.PluginsDisabled = []string{"jaeger", "news"}
+	})
+	require.NoError(t, pm.init())
+
+	assert.Empty(t, pm.scanningErrors)
+	assert.Nil(t, pm.GetDataSource("jaeger"))
+	assert.Nil(t, pm.GetPlugin("news"))
+	assert.NotNil(t, pm.GetDataSource("zipkin"))
+	assert.NotNil(t, pm.GetPlugin("text"))
+}
+
+func TestPluginManager_FailurePolicies(t *testing.T) {
+	t.Run("Should abort startup when an external plugin fails to load", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = "testdata/unsigned-datasource"
+			pm.Cfg.PluginsCoreFailurePolicy = "tolerate"
+			pm.Cfg.PluginsExternalFailurePolicy = "abort"
+		})
+		err := pm.init()
+		require.EqualError(t, err, "external plugin 'test' failed to load: signatureMissing")
+	})
+
+	t.Run("Should tolerate an external plugin failing to load", func(t *testing.T) {
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.PluginsPath = "testdata/unsigned-datasource"
+			pm.Cfg.PluginsCoreFailurePolicy = "abort"
+			pm.Cfg.PluginsExternalFailurePolicy = "tolerate"
+		})
+		require.NoError(t, pm.init())
+
+		assert.Nil(t, pm.GetPlugin("test"))
+		assert.Equal(t, signatureMissing, pm.pluginScanningErrors["test"].ErrorCode)
+		assert.NotNil(t, pm.GetDataSource("zipkin"))
+	})
+}
+
 func TestPluginManager_IsBackendOnlyPlugin(t *testing.T) {
 	pluginScanner := &PluginScanner{}
 
@@ -431,6 +471,7 @@ func TestPluginManager_IsBackendOnlyPlugin(t *testing.T) {
 
 	for _, c := range []testCase{
 		{name: "renderer", isBackendOnly: true},
+		{name: "secretsmanager", isBackendOnly: true},
 		{name: "app", isBackendOnly: false},
 	} {
 		t.Run(fmt.Sprintf("Plugin %s", c.name), func(t *testing.T) {
@@ -441,6 +482,81 @@ func TestPluginManager_IsBackendOnlyPlugin(t *testing.T) {
 	}
 }
 
+func TestPluginManager_ScanningErrors(t *testing.T) {
+	pm := &PluginManager{
+		pluginScanningErrors: map[string]plugins.PluginError{
+			"test-panel":      {ErrorCode: loadingFailed},
+			"test-app":        {ErrorCode: signatureModified},
+			"test-datasource": {ErrorCode: signatureMissing},
+		},
+	}
+
+	assert.Equal(t, []plugins.PluginError{
+		{ErrorCode: signatureModified, PluginID: "test-app"},
+		{ErrorCode: signatureMissing, PluginID: "test-datasource"},
+		{ErrorCode: loadingFailed, PluginID: "test-panel"},
+	}, pm.ScanningErrors())
+}
+
+type incompatiblePluginInstaller struct {
+	fakePluginInstaller
+}
+
+func (i *incompatiblePluginInstaller) Install(_ context.Context, pluginID, _, pluginsDirectory, _, _ string) error {
+	i.installCount++
+	return writeIncompatiblePlugin(filepath.Join(pluginsDirectory, pluginID), pluginID)
+}
+
+func writeIncompatiblePlugin(dir, pluginID string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	pluginJSON := fmt.Sprintf(`{"type": "panel", "name": "Test", "id": %q, "info": {"version": "1.0.0"}, "dependencies": {"grafanaVersion": ">=9.0.0"}}`,
+		pluginID)
+	return os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(pluginJSON), 0600)
+}
+
+func TestPluginManager_GrafanaVersionCompatibility(t *testing.T) {
+	newManager := func(t *testing.T, block bool) *PluginManager {
+		pluginsPath := t.TempDir()
+		require.NoError(t, writeIncompatiblePlugin(filepath.Join(pluginsPath, "test-panel"), "test-panel"))
+		return createManager(t, func(pm *PluginManager) {
+			pm.Cfg.BuildVersion = "8.2.0"
+			pm.Cfg.PluginsPath = pluginsPath
+			pm.Cfg.PluginsAllowUnsigned = []string{"test-panel", "other-panel"}
+			pm.Cfg.PluginsBlockIncompatible = block
+		})
+	}
+
+	t.Run("Should load incompatible plugins by default", func(t *testing.T) {
+		pm := newManager(t, false)
+		require.NoError(t, pm.initExternalPlugins())
+
+		require.NotNil(t, pm.GetPlugin("test-panel"))
+		require.Empty(t, pm.ScanningErrors())
+	})
+
+	t.Run("Should not load incompatible plugins when blocked", func(t *testing.T) {
+		pm := newManager(t, true)
+		require.NoError(t, pm.initExternalPlugins())
+
+		require.Nil(t, pm.GetPlugin("test-panel"))
+		require.Equal(t, []plugins.PluginError{{ErrorCode: incompatibleGrafanaVersion, PluginID: "test-panel"}},
+			pm.ScanningErrors())
+	})
+
+	t.Run("Should remove incompatible plugins after install when blocked", func(t *testing.T) {
+		pm := newManager(t, true)
+		installer := &incompatiblePluginInstaller{}
+		pm.pluginInstaller = installer
+
+		err := pm.Install(context.Background(), "other-panel", "1.0.0")
+		require.Equal(t, plugins.IncompatibleGrafanaVersionError{PluginID: "other-panel", GrafanaVersion: "8.2.0"}, err)
+		require.Equal(t, 1, installer.installCount)
+		require.Equal(t, 1, installer.uninstallCount)
+	})
+}
+
 func TestPluginManager_Installer(t *testing.T) {
 	t.Run("Install plugin after manager init", func(t *testing.T) {
 		fm := &fakeBackendPluginManager{}
@@ -709,6 +825,10 @@ func (f *fakePluginInstaller) GetUpdateInfo(pluginID, version, pluginRepoURL str
 	return plugins.UpdateInfo{}, nil
 }
 
+func (f *fakePluginInstaller) ArchiveReport(pluginID string) (plugins.PluginArchiveReport, bool) {
+	return plugins.PluginArchiveReport{}, false
+}
+
 func createManager(t *testing.T, cbs ...func(*PluginManager)) *PluginManager {
 	t.Helper()
 
@@ -13,7 +13,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -101,6 +103,32 @@ func readPluginManifest(body []byte) (*pluginManifest, error) {
 
 // getPluginSignatureState returns the signature state for a plugin.
 func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugins.PluginSignatureState, error) {
+	details, err := getPluginSignatureDetails(log, plugin)
+	if err != nil {
+		return plugins.PluginSignatureState{Status: details.Status}, err
+	}
+
+	if details.Status != plugins.PluginSignatureValid {
+		return plugins.PluginSignatureState{
+			Status: details.Status,
+		}, nil
+	}
+
+	files := make(plugins.PluginFiles, len(details.Files))
+	for _, f := range details.Files {
+		files[f.Path] = struct{}{}
+	}
+	return plugins.PluginSignatureState{
+		Status:     details.Status,
+		Type:       details.Type,
+		SigningOrg: details.SigningOrg,
+		Files:      files,
+	}, nil
+}
+
+// getPluginSignatureDetails verifies the signature of a plugin, and returns the details of the manifest and the
+// result of the verification of each file.
+func getPluginSignatureDetails(log log.Logger, plugin *plugins.PluginBase) (plugins.PluginSignatureDetails, error) {
 	log.Debug("Getting signature state of plugin", "plugin", plugin.Id, "isBackend", plugin.Backend)
 	manifestPath := filepath.Join(plugin.PluginDir, "MANIFEST.txt")
 
@@ -110,35 +138,49 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 	byteValue, err := ioutil.ReadFile(manifestPath)
 	if err != nil || len(byteValue) < 10 {
 		log.Debug("Plugin is unsigned", "id", plugin.Id)
-		return plugins.PluginSignatureState{
+		return plugins.PluginSignatureDetails{
 			Status: plugins.PluginSignatureUnsigned,
+			Reason: "MANIFEST.txt not found",
 		}, nil
 	}
 
 	manifest, err := readPluginManifest(byteValue)
 	if err != nil {
 		log.Debug("Plugin signature invalid", "id", plugin.Id)
-		return plugins.PluginSignatureState{
+		return plugins.PluginSignatureDetails{
 			Status: plugins.PluginSignatureInvalid,
+			Reason: err.Error(),
 		}, nil
 	}
 
+	details := plugins.PluginSignatureDetails{
+		Status:          plugins.PluginSignatureValid,
+		Type:            manifest.SignatureType,
+		SigningOrg:      manifest.SignedByOrgName,
+		SigningOrgID:    manifest.SignedByOrg,
+		ManifestVersion: manifest.ManifestVersion,
+		KeyID:           manifest.KeyID,
+		SignedAt:        time.Unix(0, manifest.Time*int64(time.Millisecond)).UTC(),
+		RootURLs:        manifest.RootURLs,
+		Files:           []plugins.PluginFileVerification{},
+	}
+
 	// Make sure the versions all match
 	if manifest.Plugin != plugin.Id || manifest.Version != plugin.Info.Version {
-		return plugins.PluginSignatureState{
-			Status: plugins.PluginSignatureModified,
-		}, nil
+		details.Status = plugins.PluginSignatureModified
+		details.Reason = fmt.Sprintf("manifest is for plugin %s version %s", manifest.Plugin, manifest.Version)
+		return details, nil
 	}
 
 	// Validate that private is running within defined root URLs
 	if manifest.SignatureType == plugins.PrivateType {
 		appURL, err := url.Parse(setting.AppUrl)
 		if err != nil {
-			return plugins.PluginSignatureState{}, err
+			return plugins.PluginSignatureDetails{}, err
 		}
 		appSubURL, err := url.Parse(setting.AppSubUrl)
 		if err != nil {
-			return plugins.PluginSignatureState{}, err
+			return plugins.PluginSignatureDetails{}, err
 		}
 		appURLPath := path.Join(appSubURL.RequestURI(), appURL.RequestURI())
 
@@ -147,7 +189,7 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 			rootURL, err := url.Parse(u)
 			if err != nil {
 				log.Warn("Could not parse plugin root URL", "plugin", plugin.Id, "rootUrl", rootURL)
-				return plugins.PluginSignatureState{}, err
+				return plugins.PluginSignatureDetails{}, err
 			}
 
 			if rootURL.Scheme == appURL.Scheme &&
@@ -163,9 +205,9 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		if !foundMatch {
 			log.Warn("Could not find root URL that matches running application URL", "plugin", plugin.Id,
 				"appUrl", appURL, "rootUrls", manifest.RootURLs)
-			return plugins.PluginSignatureState{
-				Status: plugins.PluginSignatureInvalid,
-			}, nil
+			details.Status = plugins.PluginSignatureInvalid
+			details.Reason = "no root URL of the private signature matches the application URL"
+			return details, nil
 		}
 	}
 
@@ -174,12 +216,16 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 	// Verify the manifest contents
 	log.Debug("Verifying contents of plugin manifest", "plugin", plugin.Id)
 	for fp, hash := range manifest.Files {
-		err = verifyHash(plugin.Id, filepath.Join(plugin.PluginDir, fp), hash)
-		if err != nil {
-			return plugins.PluginSignatureState{
-				Status: plugins.PluginSignatureModified,
-			}, nil
+		status := plugins.PluginFileValid
+		if err := verifyHash(plugin.Id, filepath.Join(plugin.PluginDir, fp), hash); err != nil {
+			status = plugins.PluginFileModified
+			if errors.Is(err, errPluginFileNotFound) {
+				status = plugins.PluginFileMissing
+			}
+			details.Status = plugins.PluginSignatureModified
+			details.Reason = "plugin files do not match the manifest"
 		}
+		details.Files = append(details.Files, plugins.PluginFileVerification{Path: fp, Status: status})
 
 		manifestFiles[fp] = struct{}{}
 	}
@@ -188,9 +234,12 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		pluginFiles, err := pluginFilesRequiringVerification(plugin)
 		if err != nil {
 			log.Warn("Could not collect plugin file information in directory", "pluginID", plugin.Id, "dir", plugin.PluginDir)
-			return plugins.PluginSignatureState{
-				Status: plugins.PluginSignatureInvalid,
-			}, err
+			// the signature is invalid, unless already known to be modified
+			if details.Status == plugins.PluginSignatureValid {
+				return plugins.PluginSignatureDetails{
+					Status: plugins.PluginSignatureInvalid,
+				}, err
+			}
 		}
 
 		// Track files missing from the manifest
@@ -198,27 +247,30 @@ func getPluginSignatureState(log log.Logger, plugin *plugins.PluginBase) (plugin
 		for _, f := range pluginFiles {
 			if _, exists := manifestFiles[f]; !exists {
 				unsignedFiles = append(unsignedFiles, f)
+				details.Files = append(details.Files, plugins.PluginFileVerification{Path: f, Status: plugins.PluginFileUnsigned})
 			}
 		}
 
 		if len(unsignedFiles) > 0 {
 			log.Warn("The following files were not included in the signature", "plugin", plugin.Id, "files", unsignedFiles)
-			return plugins.PluginSignatureState{
-				Status: plugins.PluginSignatureModified,
-			}, nil
+			details.Status = plugins.PluginSignatureModified
+			if details.Reason == "" {
+				details.Reason = "plugin files are not included in the manifest"
+			}
 		}
 	}
 
-	// Everything OK
-	log.Debug("Plugin signature valid", "id", plugin.Id)
-	return plugins.PluginSignatureState{
-		Status:     plugins.PluginSignatureValid,
-		Type:       manifest.SignatureType,
-		SigningOrg: manifest.SignedByOrgName,
-		Files:      manifestFiles,
-	}, nil
+	sort.Slice(details.Files, func(i, j int) bool { return details.Files[i].Path < details.Files[j].Path })
+
+	if details.Status == plugins.PluginSignatureValid {
+		// Everything OK
+		log.Debug("Plugin signature valid", "id", plugin.Id)
+	}
+	return details, nil
 }
 
+var errPluginFileNotFound = errors.New("plugin file listed in the manifest was not found")
+
 func verifyHash(pluginID string, path string, hash string) error {
 	// nolint:gosec
 	// We can ignore the gosec G304 warning on this one because `path` is based
@@ -226,7 +278,7 @@ func verifyHash(pluginID string, path string, hash string) error {
 	f, err := os.Open(path)
 	if err != nil {
 		log.Warn("Plugin file listed in the manifest was not found", "plugin", pluginID, "path", path)
-		return fmt.Errorf("plugin file listed in the manifest was not found")
+		return errPluginFileNotFound
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -4,7 +4,9 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -113,6 +115,55 @@ khdr/tZ1PDgRxMqB/u+Vtbpl0xSxgblnrDOYMSI=
 	})
 }
 
+func TestGetPluginSignatureDetails(t *testing.T) {
+	logger := log.New("test")
+
+	t.Run("valid signature", func(t *testing.T) {
+		details, err := getPluginSignatureDetails(logger, &plugins.PluginBase{
+			Id:        "test",
+			Info:      plugins.PluginInfo{Version: "1.0.0"},
+			PluginDir: "testdata/valid-v2-signature/plugin",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureDetails{
+			Status:          plugins.PluginSignatureValid,
+			Type:            plugins.GrafanaType,
+			SigningOrg:      "Grafana Labs",
+			SigningOrgID:    "grafana",
+			ManifestVersion: "2.0.0",
+			KeyID:           "7e4d0c6a708866e7",
+			SignedAt:        time.Unix(0, 1605807330546*int64(time.Millisecond)).UTC(),
+			Files: []plugins.PluginFileVerification{
+				{Path: "plugin.json", Status: plugins.PluginFileValid},
+			},
+		}, details)
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		details, err := getPluginSignatureDetails(logger, &plugins.PluginBase{
+			Id:        "test",
+			Info:      plugins.PluginInfo{Version: "1.0.0"},
+			PluginDir: "testdata/lacking-files/plugin",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureModified, details.Status)
+		assert.Equal(t, "plugin files do not match the manifest", details.Reason)
+		assert.Equal(t, []plugins.PluginFileVerification{
+			{Path: "executable", Status: plugins.PluginFileMissing},
+			{Path: "plugin.json", Status: plugins.PluginFileValid},
+		}, details.Files)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		details, err := getPluginSignatureDetails(logger, &plugins.PluginBase{
+			Id:        "test",
+			PluginDir: "testdata/unsigned-datasource/plugin",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, plugins.PluginSignatureUnsigned, details.Status)
+	})
+}
+
 func fileList(manifest *pluginManifest) []string {
 	var keys []string
 	for k := range manifest.Files {
@@ -0,0 +1,24 @@
+package manager
+
+import "context"
+
+// syncPluginAssets uploads the frontend assets of the installed plugins to the object storage, so that the other
+// instances also serve the plugins present at startup, e.g. installed with the CLI, and not only the ones installed
+// through the API. The core and development plugins are left out, as each instance serves them from its disk.
+func (pm *PluginManager) syncPluginAssets(ctx context.Context) {
+	if !pm.pluginAssets.Enabled() {
+		return
+	}
+
+	for _, p := range pm.Plugins() {
+		if p.IsCorePlugin || p.IsDevPlugin {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := pm.pluginAssets.Sync(ctx, p); err != nil {
+			pm.log.Error("Failed to sync plugin assets", "pluginId", p.Id, "error", err)
+		}
+	}
+}
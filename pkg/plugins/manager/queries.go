@@ -5,7 +5,14 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 )
 
+// GetPluginSettings returns the settings of all plugins for an organization. The result is cached and
+// shared between callers, so it must not be modified.
 func (pm *PluginManager) GetPluginSettings(orgID int64) (map[string]*models.PluginSettingInfoDTO, error) {
+	if pluginMap, exists := pm.pluginSettingsCache.get(orgID); exists {
+		return pluginMap, nil
+	}
+
+	gen := pm.pluginSettingsCache.generation()
 	pluginSettings, err := pm.SQLStore.GetPluginSettings(orgID)
 	if err != nil {
 		return nil, err
@@ -48,6 +55,7 @@ func (pm *PluginManager) GetPluginSettings(orgID int64) (map[string]*models.Plug
 		pluginMap[pluginDef.Id] = opt
 	}
 
+	pm.pluginSettingsCache.set(orgID, gen, pluginMap)
 	return pluginMap, nil
 }
 
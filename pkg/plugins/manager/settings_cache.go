@@ -0,0 +1,121 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// pluginSettingsCacheTTL bounds how long the settings updated by another Grafana instance, which doesn't
+// invalidate the cache of this one, are served stale.
+const pluginSettingsCacheTTL = 5 * time.Second
+
+// pluginSettingsCache caches the plugin settings of each organization, so that the plugin settings
+// don't have to be read from the database on every request. Entries are removed when the plugin
+// settings of an organization are updated, and the whole cache is cleared when plugins are
+// installed or uninstalled since that changes the default settings. Entries also expire after
+// pluginSettingsCacheTTL. A nil cache doesn't cache anything.
+//
+// The settings read from the database are only cached if the cache wasn't invalidated since the
+// read started, see generation, so that a read racing with an update can't cache stale settings.
+type pluginSettingsCache struct {
+	now func() time.Time
+
+	mu    sync.RWMutex
+	gen   uint64
+	byOrg map[int64]pluginSettingsCacheEntry
+}
+
+type pluginSettingsCacheEntry struct {
+	settings map[string]*models.PluginSettingInfoDTO
+	expires  time.Time
+}
+
+func newPluginSettingsCache() *pluginSettingsCache {
+	return &pluginSettingsCache{
+		now:   time.Now,
+		byOrg: map[int64]pluginSettingsCacheEntry{},
+	}
+}
+
+func (c *pluginSettingsCache) get(orgID int64) (map[string]*models.PluginSettingInfoDTO, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.byOrg[orgID]
+	exists = exists && c.now().Before(entry.expires)
+	if exists {
+		metrics.MPluginSettingsCacheRequests.WithLabelValues("hit").Inc()
+	} else {
+		metrics.MPluginSettingsCacheRequests.WithLabelValues("miss").Inc()
+	}
+	return entry.settings, exists
+}
+
+// generation returns the generation of the cache, to be passed to set with the settings read
+// after calling it.
+func (c *pluginSettingsCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.gen
+}
+
+// set caches the settings of the organization, unless the cache was invalidated since gen was
+// returned by generation.
+func (c *pluginSettingsCache) set(orgID int64, gen uint64, settings map[string]*models.PluginSettingInfoDTO) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+	c.byOrg[orgID] = pluginSettingsCacheEntry{settings: settings, expires: c.now().Add(pluginSettingsCacheTTL)}
+}
+
+// invalidate removes the settings of the organization, as well as the settings of all
+// organizations (org ID 0) which include them.
+func (c *pluginSettingsCache) invalidate(orgID int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	delete(c.byOrg, orgID)
+	delete(c.byOrg, 0)
+}
+
+func (c *pluginSettingsCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.byOrg = map[int64]pluginSettingsCacheEntry{}
+}
+
+func (pm *PluginManager) handlePluginSettingUpdated(event *models.PluginSettingUpdatedEvent) error {
+	pm.log.Debug("Invalidating cached plugin settings", "pluginId", event.PluginId, "orgId", event.OrgId)
+	pm.pluginSettingsCache.invalidate(event.OrgId)
+	return nil
+}
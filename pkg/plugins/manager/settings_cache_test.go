@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginSettingsCache(t *testing.T) {
+	settings := map[string]*models.PluginSettingInfoDTO{
+		"test-app": {PluginId: "test-app", Enabled: true},
+	}
+
+	t.Run("Should return cached settings per org", func(t *testing.T) {
+		c := newPluginSettingsCache()
+		c.set(1, 0, settings)
+
+		cached, exists := c.get(1)
+		require.True(t, exists)
+		assert.Equal(t, settings, cached)
+
+		_, exists = c.get(2)
+		assert.False(t, exists)
+	})
+
+	t.Run("Should invalidate org and all orgs settings", func(t *testing.T) {
+		c := newPluginSettingsCache()
+		c.set(0, 0, settings)
+		c.set(1, 0, settings)
+		c.set(2, 0, settings)
+
+		c.invalidate(1)
+
+		_, exists := c.get(0)
+		assert.False(t, exists)
+		_, exists = c.get(1)
+		assert.False(t, exists)
+		_, exists = c.get(2)
+		assert.True(t, exists)
+
+		c.invalidateAll()
+		_, exists = c.get(2)
+		assert.False(t, exists)
+	})
+
+	t.Run("Should expire settings after the TTL", func(t *testing.T) {
+		now := time.Now()
+		c := newPluginSettingsCache()
+		c.now = func() time.Time { return now }
+		c.set(1, 0, settings)
+
+		_, exists := c.get(1)
+		assert.True(t, exists)
+
+		now = now.Add(pluginSettingsCacheTTL)
+		_, exists = c.get(1)
+		assert.False(t, exists)
+	})
+
+	t.Run("Should not cache settings read before an invalidation", func(t *testing.T) {
+		c := newPluginSettingsCache()
+
+		// a read starts, then the settings are updated before it caches what it read
+		gen := c.generation()
+		c.invalidate(1)
+		c.set(1, gen, settings)
+
+		_, exists := c.get(1)
+		assert.False(t, exists)
+
+		// the next read caches the settings
+		c.set(1, c.generation(), settings)
+		_, exists = c.get(1)
+		assert.True(t, exists)
+	})
+
+	t.Run("Should invalidate settings when plugin settings are updated", func(t *testing.T) {
+		pm := &PluginManager{pluginSettingsCache: newPluginSettingsCache(), log: log.New("test")}
+		pm.pluginSettingsCache.set(1, 0, settings)
+
+		err := pm.handlePluginSettingUpdated(&models.PluginSettingUpdatedEvent{PluginId: "test-app", OrgId: 1})
+		require.NoError(t, err)
+		_, exists := pm.pluginSettingsCache.get(1)
+		assert.False(t, exists)
+	})
+
+	t.Run("A nil cache doesn't cache anything", func(t *testing.T) {
+		var c *pluginSettingsCache
+		c.set(1, 0, settings)
+		_, exists := c.get(1)
+		assert.False(t, exists)
+	})
+}
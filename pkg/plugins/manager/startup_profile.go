@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// startupProfiler records the time each plugin takes to start up, by phase, so that the plugins slowing down the
+// startup of Grafana can be found. The durations are also exposed as metrics.
+type startupProfiler struct {
+	mu        sync.Mutex
+	durations map[string]map[string]time.Duration
+}
+
+func newStartupProfiler() *startupProfiler {
+	return &startupProfiler{durations: map[string]map[string]time.Duration{}}
+}
+
+// record records the duration of a phase of the startup of a plugin, which began at start. Nothing is recorded by a
+// nil profiler.
+func (p *startupProfiler) record(pluginID, phase string, start time.Time) {
+	if p == nil {
+		return
+	}
+	duration := time.Since(start)
+
+	p.mu.Lock()
+	if p.durations[pluginID] == nil {
+		p.durations[pluginID] = map[string]time.Duration{}
+	}
+	p.durations[pluginID][phase] = duration
+	p.mu.Unlock()
+
+	metrics.SetPluginStartupDuration(pluginID, phase, duration)
+}
+
+// profile returns the time each plugin took to start up, slowest first.
+func (p *startupProfiler) profile() []plugins.PluginStartupProfile {
+	profile := []plugins.PluginStartupProfile{}
+	if p == nil {
+		return profile
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for pluginID, durations := range p.durations {
+		pluginProfile := plugins.PluginStartupProfile{PluginID: pluginID, Phases: map[string]float64{}}
+		for phase, duration := range durations {
+			ms := float64(duration) / float64(time.Millisecond)
+			pluginProfile.Phases[phase] = ms
+			pluginProfile.Total += ms
+		}
+		profile = append(profile, pluginProfile)
+	}
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].Total != profile[j].Total {
+			return profile[i].Total > profile[j].Total
+		}
+		return profile[i].PluginID < profile[j].PluginID
+	})
+	return profile
+}
+
+// StartupProfile returns the time each plugin took to start up, by phase, slowest first.
+func (pm *PluginManager) StartupProfile() []plugins.PluginStartupProfile {
+	return pm.startupProfiler.profile()
+}
+
+// profilingBackendPluginManager records the time the backend of a plugin takes to start while it's loaded.
+type profilingBackendPluginManager struct {
+	backendplugin.Manager
+
+	profiler *startupProfiler
+}
+
+func (m *profilingBackendPluginManager) RegisterAndStart(ctx context.Context, pluginID string,
+	factory backendplugin.PluginFactoryFunc) error {
+	defer m.profiler.record(pluginID, plugins.StartupPhaseBackendStart, time.Now())
+	return m.Manager.RegisterAndStart(ctx, pluginID, factory)
+}
+
+func (m *profilingBackendPluginManager) StartPlugin(ctx context.Context, pluginID string) error {
+	defer m.profiler.record(pluginID, plugins.StartupPhaseBackendStart, time.Now())
+	return m.Manager.StartPlugin(ctx, pluginID)
+}
@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_StartupProfile(t *testing.T) {
+	pluginsPath := t.TempDir()
+	writePanelPlugin(t, filepath.Join(pluginsPath, "test-panel"), "test-panel", `define([],function(){})`)
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.PluginsPath = pluginsPath
+		pm.Cfg.PluginsAllowUnsigned = []string{"test-panel"}
+	})
+	require.NoError(t, pm.initExternalPlugins())
+
+	profile := pm.StartupProfile()
+	require.Len(t, profile, 1)
+	require.Equal(t, "test-panel", profile[0].PluginID)
+	require.Contains(t, profile[0].Phases, plugins.StartupPhaseScan)
+	require.Contains(t, profile[0].Phases, plugins.StartupPhaseSignature)
+	require.Contains(t, profile[0].Phases, plugins.StartupPhaseInitialize)
+	require.NotContains(t, profile[0].Phases, plugins.StartupPhaseBackendStart)
+}
+
+func TestStartupProfiler(t *testing.T) {
+	p := newStartupProfiler()
+	now := time.Now()
+	p.record("fast-app", plugins.StartupPhaseScan, now.Add(-time.Millisecond))
+	p.record("slow-datasource", plugins.StartupPhaseScan, now.Add(-time.Millisecond))
+	p.record("slow-datasource", plugins.StartupPhaseBackendStart, now.Add(-time.Second))
+
+	profile := p.profile()
+	require.Len(t, profile, 2)
+	require.Equal(t, "slow-datasource", profile[0].PluginID)
+	require.GreaterOrEqual(t, profile[0].Phases[plugins.StartupPhaseBackendStart], float64(1000))
+	require.Equal(t, profile[0].Phases[plugins.StartupPhaseScan]+profile[0].Phases[plugins.StartupPhaseBackendStart],
+		profile[0].Total)
+	require.Equal(t, "fast-app", profile[1].PluginID)
+
+	var nilProfiler *startupProfiler
+	nilProfiler.record("fast-app", plugins.StartupPhaseScan, now)
+	require.Empty(t, nilProfiler.profile())
+}
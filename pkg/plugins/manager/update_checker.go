@@ -2,20 +2,18 @@ package manager
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/hashicorp/go-version"
 )
 
-var (
-	httpClient = http.Client{Timeout: 10 * time.Second}
-)
-
 type grafanaNetPlugin struct {
 	Slug    string `json:"slug"`
 	Version string `json:"version"`
@@ -39,6 +37,8 @@ func (pm *PluginManager) getAllExternalPluginSlugs() string {
 	return strings.Join(result, ",")
 }
 
+// checkForUpdates gets the latest versions of the installed plugins and of Grafana from the update check endpoints,
+// grafana.com and GitHub by default, or an internal feed serving the same responses.
 func (pm *PluginManager) checkForUpdates() {
 	if !pm.Cfg.CheckForUpdates {
 		return
@@ -46,28 +46,27 @@ func (pm *PluginManager) checkForUpdates() {
 
 	pm.log.Debug("Checking for updates")
 
-	pluginSlugs := pm.getAllExternalPluginSlugs()
-	resp, err := httpClient.Get("https://grafana.com/api/plugins/versioncheck?slugIn=" + pluginSlugs + "&grafanaVersion=" + setting.BuildVersion)
-	if err != nil {
-		log.Tracef("Failed to get plugins repo from grafana.com, %v", err.Error())
-		return
+	client := &http.Client{Timeout: pm.Cfg.UpdateCheckTimeout}
+	pm.checkForPluginUpdates(client)
+	pm.checkForGrafanaUpdates(client)
+	if pm.Cfg.UpdateCheckAdvisoriesURL != "" {
+		pm.checkForAdvisories(client)
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Warn("Failed to close response body", "err", err)
-		}
-	}()
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+func (pm *PluginManager) checkForPluginUpdates(client *http.Client) {
+	checkURL, err := updateCheckURL(pm.Cfg.UpdateCheckPluginsURL, url.Values{
+		"slugIn":         []string{pm.getAllExternalPluginSlugs()},
+		"grafanaVersion": []string{setting.BuildVersion},
+	})
 	if err != nil {
-		log.Tracef("Update check failed, reading response from grafana.com, %v", err.Error())
+		pm.log.Warn("Invalid plugins update check URL", "url", pm.Cfg.UpdateCheckPluginsURL, "err", err)
 		return
 	}
 
 	gNetPlugins := []grafanaNetPlugin{}
-	err = json.Unmarshal(body, &gNetPlugins)
-	if err != nil {
-		log.Tracef("Failed to unmarshal plugin repo, reading response from grafana.com, %v", err.Error())
+	if err := pm.getUpdateCheck(client, checkURL, &gNetPlugins); err != nil {
+		log.Tracef("Failed to get the latest plugin versions, %v", err.Error())
 		return
 	}
 
@@ -87,27 +86,12 @@ func (pm *PluginManager) checkForUpdates() {
 			}
 		}
 	}
+}
 
-	resp2, err := httpClient.Get("https://raw.githubusercontent.com/grafana/grafana/main/latest.json")
-	if err != nil {
-		log.Tracef("Failed to get latest.json repo from github.com: %v", err.Error())
-		return
-	}
-	defer func() {
-		if err := resp2.Body.Close(); err != nil {
-			pm.log.Warn("Failed to close response body", "err", err)
-		}
-	}()
-	body, err = ioutil.ReadAll(resp2.Body)
-	if err != nil {
-		log.Tracef("Update check failed, reading response from github.com, %v", err.Error())
-		return
-	}
-
+func (pm *PluginManager) checkForGrafanaUpdates(client *http.Client) {
 	var latest gitHubLatest
-	err = json.Unmarshal(body, &latest)
-	if err != nil {
-		log.Tracef("Failed to unmarshal github.com latest, reading response from github.com: %v", err.Error())
+	if err := pm.getUpdateCheck(client, pm.Cfg.UpdateCheckGrafanaURL, &latest); err != nil {
+		log.Tracef("Failed to get the latest Grafana version, %v", err.Error())
 		return
 	}
 
@@ -125,3 +109,74 @@ func (pm *PluginManager) checkForUpdates() {
 		pm.grafanaHasUpdate = currVersion.LessThan(latestVersion)
 	}
 }
+
+// checkForAdvisories gets the security advisories of the plugin advisories feed, and marks the installed plugins
+// affected by them.
+func (pm *PluginManager) checkForAdvisories(client *http.Client) {
+	var advisories []plugins.PluginAdvisory
+	if err := pm.getUpdateCheck(client, pm.Cfg.UpdateCheckAdvisoriesURL, &advisories); err != nil {
+		pm.log.Warn("Failed to get the plugin security advisories", "err", err)
+		return
+	}
+
+	pm.advisoriesMu.Lock()
+	pm.advisories = advisories
+	pm.advisoriesMu.Unlock()
+
+	for _, plug := range pm.Plugins() {
+		plug.Advisories = plugins.AffectingAdvisories(advisories, plug.Id, plug.Info.Version)
+		if len(plug.Advisories) > 0 {
+			pm.log.Warn("Installed plugin version is affected by security advisories", "pluginId", plug.Id,
+				"version", plug.Info.Version, "advisories", len(plug.Advisories))
+		}
+	}
+}
+
+// affectingAdvisories returns the security advisories affecting the version of the plugin.
+func (pm *PluginManager) affectingAdvisories(pluginID, pluginVersion string) []plugins.PluginAdvisory {
+	pm.advisoriesMu.RLock()
+	defer pm.advisoriesMu.RUnlock()
+	return plugins.AffectingAdvisories(pm.advisories, pluginID, pluginVersion)
+}
+
+// getUpdateCheck gets the JSON response of the update check endpoint into v.
+func (pm *PluginManager) getUpdateCheck(client *http.Client, checkURL string, v interface{}) error {
+	resp, err := client.Get(checkURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			pm.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, checkURL)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response from %s: %w", checkURL, err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal the response from %s: %w", checkURL, err)
+	}
+	return nil
+}
+
+// updateCheckURL returns the update check URL with the params added to its query.
+func updateCheckURL(rawURL string, params url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckForUpdates(t *testing.T) {
+	origBuildVersion := setting.BuildVersion
+	t.Cleanup(func() { setting.BuildVersion = origBuildVersion })
+	setting.BuildVersion = "8.2.0"
+
+	var pluginsQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed/grafana.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"stable": "8.2.1", "testing": "8.3.0-beta1"}`))
+	})
+	mux.HandleFunc("/feed/plugins", func(w http.ResponseWriter, r *http.Request) {
+		pluginsQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[{"slug": "test-panel", "version": "1.1.0"}, {"slug": "test-app", "version": "2.0.0"}]`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	setup := func(t *testing.T) (*PluginManager, *plugins.PluginBase, *plugins.PluginBase) {
+		t.Helper()
+
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.Cfg.CheckForUpdates = true
+			pm.Cfg.UpdateCheckGrafanaURL = server.URL + "/feed/grafana.json"
+			pm.Cfg.UpdateCheckPluginsURL = server.URL + "/feed/plugins?channel=internal"
+		})
+		panel := &plugins.PluginBase{Id: "test-panel", Info: plugins.PluginInfo{Version: "1.0.0"}}
+		app := &plugins.PluginBase{Id: "test-app", Info: plugins.PluginInfo{Version: "2.0.0"}}
+		pm.plugins = map[string]*plugins.PluginBase{panel.Id: panel, app.Id: app}
+		return pm, panel, app
+	}
+
+	t.Run("Should get the latest versions from the configured endpoints", func(t *testing.T) {
+		pm, panel, app := setup(t)
+
+		pm.checkForUpdates()
+
+		require.Equal(t, "8.2.1", pm.GrafanaLatestVersion())
+		require.True(t, pm.GrafanaHasUpdate())
+		require.Equal(t, "1.1.0", panel.GrafanaNetVersion)
+		require.True(t, panel.GrafanaNetHasUpdate)
+		require.Equal(t, "2.0.0", app.GrafanaNetVersion)
+		require.False(t, app.GrafanaNetHasUpdate)
+		require.Contains(t, pluginsQuery, "channel=internal")
+		require.Contains(t, pluginsQuery, "grafanaVersion=8.2.0")
+	})
+
+	t.Run("Should check for Grafana updates if the plugins update check fails", func(t *testing.T) {
+		pm, panel, _ := setup(t)
+		pm.Cfg.UpdateCheckPluginsURL = server.URL + "/feed/missing"
+
+		pm.checkForUpdates()
+
+		require.Equal(t, "8.2.1", pm.GrafanaLatestVersion())
+		require.Empty(t, panel.GrafanaNetVersion)
+	})
+
+	t.Run("Should not check for updates when disabled", func(t *testing.T) {
+		pm, panel, _ := setup(t)
+		pm.Cfg.CheckForUpdates = false
+
+		pm.checkForUpdates()
+
+		require.Empty(t, pm.GrafanaLatestVersion())
+		require.Empty(t, panel.GrafanaNetVersion)
+	})
+}
+
+func TestCheckForAdvisories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id": "GHSA-1234", "pluginId": "test-panel", "affectedVersions": "< 1.1.0", "fixedVersion": "1.1.0", "severity": "high"},
+			{"id": "GHSA-5678", "pluginId": "test-app", "affectedVersions": "< 1.0.0", "severity": "low"}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.UpdateCheckAdvisoriesURL = server.URL
+	})
+	panel := &plugins.PluginBase{Id: "test-panel", Info: plugins.PluginInfo{Version: "1.0.0"}}
+	app := &plugins.PluginBase{Id: "test-app", Info: plugins.PluginInfo{Version: "2.0.0"}}
+	pm.plugins = map[string]*plugins.PluginBase{panel.Id: panel, app.Id: app}
+
+	pm.checkForAdvisories(&http.Client{})
+
+	require.Len(t, panel.Advisories, 1)
+	require.Equal(t, "GHSA-1234", panel.Advisories[0].ID)
+	require.Empty(t, app.Advisories)
+
+	t.Run("Should not block installing vulnerable versions by default", func(t *testing.T) {
+		require.NoError(t, pm.checkVulnerableVersion("test-panel", "1.0.0"))
+	})
+
+	t.Run("Should block installing vulnerable versions when enabled", func(t *testing.T) {
+		pm.Cfg.PluginsBlockVulnerableInstalls = true
+		t.Cleanup(func() { pm.Cfg.PluginsBlockVulnerableInstalls = false })
+
+		err := pm.Install(context.Background(), "test-app", "0.9.0")
+		var vulnerableErr plugins.VulnerablePluginVersionError
+		require.ErrorAs(t, err, &vulnerableErr)
+		require.Equal(t, "GHSA-5678", vulnerableErr.Advisories[0].ID)
+
+		require.NoError(t, pm.checkVulnerableVersion("test-panel", "1.1.0"))
+	})
+}
@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// upgradeStagingDirName is the directory of the plugins directory the new version of a plugin is installed into
+// during a blue/green upgrade. It's skipped when scanning for plugins.
+const upgradeStagingDirName = ".upgrade"
+
+// errBlueGreenUpgradeUnavailable is returned when the plugin files can't be swapped while the previous version runs,
+// e.g. because its executable is locked, in which case the plugin is upgraded by uninstalling it first.
+var errBlueGreenUpgradeUnavailable = errors.New("blue/green upgrade unavailable")
+
+// upgradingBackendPluginManager registers the backend of the plugin being upgraded with the Upgrader, so that the
+// new version is started alongside the previous one, which serves the requests until the new one is healthy.
+type upgradingBackendPluginManager struct {
+	backendplugin.Manager
+
+	upgrader backendplugin.Upgrader
+	pluginID string
+}
+
+func (m *upgradingBackendPluginManager) RegisterAndStart(ctx context.Context, pluginID string,
+	factory backendplugin.PluginFactoryFunc) error {
+	if pluginID != m.pluginID {
+		return m.Manager.RegisterAndStart(ctx, pluginID, factory)
+	}
+	return m.upgrader.UpgradePlugin(ctx, pluginID, factory)
+}
+
+// canUpgradeBlueGreen returns whether the plugin can be upgraded without stopping its backend first: the backend
+// plugin manager supports it, and the plugin is a data source or app with a backend managed by Grafana, installed
+// in the plugins directory.
+func (pm *PluginManager) canUpgradeBlueGreen(plugin *plugins.PluginBase) bool {
+	if _, ok := pm.BackendPluginManager.(backendplugin.Upgrader); !ok {
+		return false
+	}
+	if !plugin.Backend || (plugin.Type != "datasource" && plugin.Type != "app") {
+		return false
+	}
+	if filepath.Dir(plugin.PluginDir) != filepath.Clean(pm.Cfg.PluginsPath) {
+		return false
+	}
+	p, ok := pm.BackendPluginManager.Get(plugin.Id)
+	return ok && p.IsManaged()
+}
+
+// upgradeBlueGreen installs the new version of the plugin next to the previous one and swaps their files. The new
+// version is then loaded and its backend started while the previous one keeps serving the requests, which are
+// routed to the new one once it's healthy. The files of the previous version are restored if the new one fails to
+// load.
+func (pm *PluginManager) upgradeBlueGreen(ctx context.Context, plugin *plugins.PluginBase, version,
+	pluginZipURL string) error {
+	stagingDir := filepath.Join(pm.Cfg.PluginsPath, upgradeStagingDirName)
+	defer func() {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			pm.log.Warn("Failed to remove plugin upgrade staging directory", "dir", stagingDir, "error", err)
+		}
+	}()
+
+	err := pm.pluginInstaller.Install(ctx, plugin.Id, version, stagingDir, pluginZipURL, grafanaComURL)
+	if err != nil {
+		return err
+	}
+
+	backupDir := filepath.Join(stagingDir, plugin.Id+".previous")
+	if err := os.Rename(plugin.PluginDir, backupDir); err != nil {
+		pm.log.Warn("Failed to move previous plugin version", "pluginId", plugin.Id, "error", err)
+		return errBlueGreenUpgradeUnavailable
+	}
+	if err := pm.moveStagedPlugins(plugin, stagingDir, backupDir); err != nil {
+		pm.restorePreviousVersion(plugin, backupDir)
+		return err
+	}
+
+	backendPluginManager := &upgradingBackendPluginManager{
+		Manager:  pm.BackendPluginManager,
+		upgrader: pm.BackendPluginManager.(backendplugin.Upgrader),
+		pluginID: plugin.Id,
+	}
+	delete(pm.pluginScanningErrors, plugin.Id)
+	if err := pm.scanPlugins(plugin.PluginDir, true, backendPluginManager, plugin.Id); err != nil {
+		pm.restorePreviousVersion(plugin, backupDir)
+		return err
+	}
+	if pm.GetPlugin(plugin.Id) == plugin {
+		pm.restorePreviousVersion(plugin, backupDir)
+		scanningErr, ok := pm.pluginScanningErrors[plugin.Id]
+		if ok && scanningErr.ErrorCode == incompatibleGrafanaVersion {
+			return plugins.IncompatibleGrafanaVersionError{PluginID: plugin.Id, GrafanaVersion: pm.Cfg.BuildVersion}
+		}
+		if ok {
+			return fmt.Errorf("failed to load new plugin version: %s", scanningErr.ErrorCode)
+		}
+		return errors.New("failed to load new plugin version")
+	}
+
+	// loads the dependencies installed with the plugin, and updates the static routes
+	return pm.initExternalPlugins()
+}
+
+// moveStagedPlugins moves the plugins installed into the staging directory, i.e. the upgraded plugin and its
+// dependencies, into the plugins directory, replacing the installed dependencies like the installer does.
+func (pm *PluginManager) moveStagedPlugins(plugin *plugins.PluginBase, stagingDir, backupDir string) error {
+	if _, err := os.Stat(filepath.Join(stagingDir, plugin.Id)); err != nil {
+		return fmt.Errorf("new plugin version not installed: %w", err)
+	}
+
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		staged := filepath.Join(stagingDir, entry.Name())
+		if !entry.IsDir() || staged == backupDir {
+			continue
+		}
+		dest := filepath.Join(pm.Cfg.PluginsPath, entry.Name())
+		if entry.Name() == plugin.Id {
+			dest = plugin.PluginDir
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return err
+		}
+		if err := os.Rename(staged, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePreviousVersion puts the files of the previous version of an upgraded plugin back. Failures are only
+// logged, since the error of the upgrade is returned.
+func (pm *PluginManager) restorePreviousVersion(plugin *plugins.PluginBase, backupDir string) {
+	if err := os.RemoveAll(plugin.PluginDir); err != nil {
+		pm.log.Error("Failed to remove new plugin version", "pluginId", plugin.Id, "error", err)
+		return
+	}
+	if err := os.Rename(backupDir, plugin.PluginDir); err != nil {
+		pm.log.Error("Failed to restore previous plugin version", "pluginId", plugin.Id, "error", err)
+	}
+}
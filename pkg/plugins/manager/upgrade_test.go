@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+type upgradeBackendPluginManager struct {
+	verificationBackendPluginManager
+
+	upgraded   []string
+	upgradeErr error
+}
+
+func (m *upgradeBackendPluginManager) UpgradePlugin(_ context.Context, pluginID string, _ backendplugin.PluginFactoryFunc) error {
+	m.upgraded = append(m.upgraded, pluginID)
+	return m.upgradeErr
+}
+
+// stagingPluginInstaller installs a data source plugin with a backend of the requested version.
+type stagingPluginInstaller struct {
+	fakePluginInstaller
+}
+
+func (i *stagingPluginInstaller) Install(_ context.Context, pluginID, version, pluginsDirectory, _, _ string) error {
+	i.installCount++
+	return writeBackendPlugin(filepath.Join(pluginsDirectory, pluginID), pluginID, version)
+}
+
+func writeBackendPlugin(dir, pluginID, version string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	pluginJSON := fmt.Sprintf(`{"type": "datasource", "name": "Test", "id": %q, "backend": true, "executable": "gpx_test", "info": {"version": %q}}`,
+		pluginID, version)
+	return os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(pluginJSON), 0600)
+}
+
+func TestPluginManager_upgradeBlueGreen(t *testing.T) {
+	const pluginID = "test-datasource"
+	pluginsPath := t.TempDir()
+	require.NoError(t, writeBackendPlugin(filepath.Join(pluginsPath, "plugin"), pluginID, "1.0.0"))
+
+	backendPluginManager := &upgradeBackendPluginManager{
+		verificationBackendPluginManager: verificationBackendPluginManager{
+			plugins: map[string]backendplugin.Plugin{pluginID: &verificationBackendPlugin{}},
+		},
+	}
+	installer := &stagingPluginInstaller{}
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.PluginsPath = pluginsPath
+		pm.Cfg.PluginsAllowUnsigned = []string{pluginID}
+		pm.BackendPluginManager = backendPluginManager
+		pm.pluginInstaller = installer
+	})
+	require.NoError(t, pm.initExternalPlugins())
+	require.Equal(t, []string{pluginID}, backendPluginManager.registeredPlugins)
+
+	t.Run("Should start the backend of the new version alongside the previous one", func(t *testing.T) {
+		plugin := pm.GetPlugin(pluginID)
+		require.True(t, pm.canUpgradeBlueGreen(plugin))
+
+		err := pm.upgradeBlueGreen(context.Background(), plugin, "2.0.0", "")
+		require.NoError(t, err)
+
+		upgraded := pm.GetPlugin(pluginID)
+		require.Equal(t, "2.0.0", upgraded.Info.Version)
+		require.Equal(t, plugin.PluginDir, upgraded.PluginDir)
+		require.Equal(t, []string{pluginID}, backendPluginManager.upgraded)
+		require.Equal(t, []string{pluginID}, backendPluginManager.registeredPlugins)
+		require.NoDirExists(t, filepath.Join(pluginsPath, upgradeStagingDirName))
+	})
+
+	t.Run("Should keep the previous version when the new one fails to start", func(t *testing.T) {
+		backendPluginManager.upgradeErr = errors.New("health check failed")
+		plugin := pm.GetPlugin(pluginID)
+
+		err := pm.upgradeBlueGreen(context.Background(), plugin, "3.0.0", "")
+		require.Error(t, err)
+
+		require.Same(t, plugin, pm.GetPlugin(pluginID))
+		pluginJSON, err := os.ReadFile(filepath.Join(plugin.PluginDir, "plugin.json"))
+		require.NoError(t, err)
+		require.Contains(t, string(pluginJSON), `"2.0.0"`)
+		require.NoDirExists(t, filepath.Join(pluginsPath, upgradeStagingDirName))
+	})
+
+	t.Run("Should only upgrade managed backend plugins", func(t *testing.T) {
+		plugin := pm.GetPlugin(pluginID)
+		backendPluginManager.plugins = map[string]backendplugin.Plugin{}
+		require.False(t, pm.canUpgradeBlueGreen(plugin))
+
+		pm.BackendPluginManager = &fakeBackendPluginManager{}
+		require.False(t, pm.canUpgradeBlueGreen(plugin))
+	})
+}
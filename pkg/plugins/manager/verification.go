@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// installVerificationTimeout bounds the health check of a newly installed backend plugin.
+const installVerificationTimeout = 30 * time.Second
+
+// verifyInstall checks that a newly installed plugin works: the assets declared in its plugin.json are served by its
+// static route and, for backend plugins, the plugin process is running and answers a health check.
+func (pm *PluginManager) verifyInstall(ctx context.Context, plugin *plugins.PluginBase) error {
+	if !(&PluginScanner{}).IsBackendOnlyPlugin(plugin.Type) {
+		if err := pm.verifyStaticAssets(plugin); err != nil {
+			return err
+		}
+	}
+
+	if plugin.Backend {
+		return pm.verifyBackend(ctx, plugin)
+	}
+	return nil
+}
+
+func (pm *PluginManager) verifyStaticAssets(plugin *plugins.PluginBase) error {
+	var route *plugins.PluginStaticRoute
+	for _, r := range pm.StaticRoutes() {
+		if r.PluginId == plugin.Id {
+			route = r
+			break
+		}
+	}
+	if route == nil {
+		return errors.New("no static route registered")
+	}
+	if _, err := os.Stat(route.Directory); err != nil {
+		return fmt.Errorf("static route directory not accessible: %w", err)
+	}
+
+	// the asset paths are URLs once the plugin is initialized, only the ones below the base URL of the plugin are
+	// served by its static route
+	assets := []string{plugin.Info.Logos.Small, plugin.Info.Logos.Large}
+	for _, s := range plugin.Info.Screenshots {
+		assets = append(assets, s.Path)
+	}
+	prefix := plugin.BaseUrl + "/"
+	for _, asset := range assets {
+		if !strings.HasPrefix(asset, prefix) {
+			continue
+		}
+		path := filepath.Join(route.Directory, filepath.FromSlash(strings.TrimPrefix(asset, prefix)))
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("asset %s not found", asset)
+		}
+	}
+	return nil
+}
+
+// verifyBackend checks the health of the backend plugin with a plugin context without settings. Only errors of the
+// call fail the verification, a health check reporting an error is expected without settings.
+func (pm *PluginManager) verifyBackend(ctx context.Context, plugin *plugins.PluginBase) error {
+	p, ok := pm.BackendPluginManager.Get(plugin.Id)
+	if !ok {
+		return errors.New("backend plugin not registered")
+	}
+	if p.IsManaged() && p.Exited() {
+		return errors.New("backend plugin process not running")
+	}
+
+	pCtx := backend.PluginContext{PluginID: plugin.Id}
+	switch plugin.Type {
+	case "datasource":
+		pCtx.DataSourceInstanceSettings = &backend.DataSourceInstanceSettings{JSONData: []byte("{}")}
+	case "app":
+		pCtx.AppInstanceSettings = &backend.AppInstanceSettings{JSONData: []byte("{}")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, installVerificationTimeout)
+	defer cancel()
+	_, err := p.CheckHealth(ctx, &backend.CheckHealthRequest{PluginContext: pCtx})
+	if err != nil && !errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return fmt.Errorf("backend plugin health check failed: %w", err)
+	}
+	return nil
+}
+
+// rollbackInstall uninstalls a plugin which failed verification and, if the install was an upgrade, reinstalls the
+// previous version. Failures are only logged, since the verification error is returned.
+func (pm *PluginManager) rollbackInstall(ctx context.Context, pluginID string, previous *plugins.PluginBase) {
+	if err := pm.Uninstall(ctx, pluginID); err != nil {
+		pm.log.Error("Failed to uninstall plugin failing verification", "pluginId", pluginID, "error", err)
+		return
+	}
+	pm.reinstallPreviousVersion(ctx, pluginID, previous)
+}
+
+// rollbackIncompatibleInstall removes the files of a newly installed plugin which wasn't loaded because it isn't
+// compatible with the Grafana version and, if the install was an upgrade, reinstalls the previous version.
+func (pm *PluginManager) rollbackIncompatibleInstall(ctx context.Context, pluginID string, previous *plugins.PluginBase) {
+	if err := pm.pluginInstaller.Uninstall(ctx, filepath.Join(pm.Cfg.PluginsPath, pluginID)); err != nil {
+		pm.log.Error("Failed to remove incompatible plugin", "pluginId", pluginID, "error", err)
+		return
+	}
+	pm.reinstallPreviousVersion(ctx, pluginID, previous)
+}
+
+func (pm *PluginManager) reinstallPreviousVersion(ctx context.Context, pluginID string, previous *plugins.PluginBase) {
+	if previous == nil {
+		return
+	}
+
+	err := pm.pluginInstaller.Install(ctx, pluginID, previous.Info.Version, pm.Cfg.PluginsPath, "", grafanaComURL)
+	if err == nil {
+		err = pm.initExternalPlugins()
+	}
+	if err != nil {
+		pm.log.Error("Failed to reinstall previous plugin version", "pluginId", pluginID,
+			"version", previous.Info.Version, "error", err)
+		return
+	}
+	pm.log.Info("Reinstalled previous plugin version", "pluginId", pluginID, "version", previous.Info.Version)
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/stretchr/testify/require"
+)
+
+type verificationBackendPlugin struct {
+	backendplugin.Plugin
+
+	exited    bool
+	healthErr error
+	req       *backend.CheckHealthRequest
+}
+
+func (p *verificationBackendPlugin) IsManaged() bool {
+	return true
+}
+
+func (p *verificationBackendPlugin) Exited() bool {
+	return p.exited
+}
+
+func (p *verificationBackendPlugin) CheckHealth(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	p.req = req
+	if p.healthErr != nil {
+		return nil, p.healthErr
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "missing settings"}, nil
+}
+
+type verificationBackendPluginManager struct {
+	fakeBackendPluginManager
+
+	plugins map[string]backendplugin.Plugin
+}
+
+func (m *verificationBackendPluginManager) Get(pluginID string) (backendplugin.Plugin, bool) {
+	p, ok := m.plugins[pluginID]
+	return p, ok
+}
+
+func TestPluginManager_verifyInstall(t *testing.T) {
+	t.Run("Should check the assets declared by the plugin", func(t *testing.T) {
+		dir := t.TempDir()
+		pm := createManager(t)
+		pm.staticRoutes = []*plugins.PluginStaticRoute{{PluginId: "test-panel", Directory: dir}}
+		plugin := &plugins.PluginBase{Id: "test-panel", Type: "panel", BaseUrl: "public/plugins/test-panel"}
+		plugin.Info.Logos = plugins.PluginLogos{
+			Small: "public/plugins/test-panel/img/logo.svg",
+			Large: "public/img/icn-panel.svg",
+		}
+
+		err := pm.verifyInstall(context.Background(), plugin)
+		require.EqualError(t, err, "asset public/plugins/test-panel/img/logo.svg not found")
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "img"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "img", "logo.svg"), []byte("<svg/>"), 0600))
+		require.NoError(t, pm.verifyInstall(context.Background(), plugin))
+
+		pm.staticRoutes = nil
+		require.EqualError(t, pm.verifyInstall(context.Background(), plugin), "no static route registered")
+	})
+
+	t.Run("Should check the health of backend plugins", func(t *testing.T) {
+		backendPlugin := &verificationBackendPlugin{}
+		pm := createManager(t, func(pm *PluginManager) {
+			pm.BackendPluginManager = &verificationBackendPluginManager{
+				plugins: map[string]backendplugin.Plugin{"test-secretsmanager": backendPlugin},
+			}
+		})
+		plugin := &plugins.PluginBase{Id: "test-secretsmanager", Type: "secretsmanager", Backend: true}
+
+		require.NoError(t, pm.verifyInstall(context.Background(), plugin))
+		require.Equal(t, "test-secretsmanager", backendPlugin.req.PluginContext.PluginID)
+
+		backendPlugin.healthErr = backendplugin.ErrMethodNotImplemented
+		require.NoError(t, pm.verifyInstall(context.Background(), plugin))
+
+		backendPlugin.healthErr = backendplugin.ErrPluginUnavailable
+		require.ErrorIs(t, pm.verifyInstall(context.Background(), plugin), backendplugin.ErrPluginUnavailable)
+
+		backendPlugin.healthErr = nil
+		backendPlugin.exited = true
+		require.EqualError(t, pm.verifyInstall(context.Background(), plugin), "backend plugin process not running")
+
+		plugin.Id = "unknown"
+		require.EqualError(t, pm.verifyInstall(context.Background(), plugin), "backend plugin not registered")
+	})
+}
+
+func TestPluginManager_rollbackInstall(t *testing.T) {
+	pluginsPath := t.TempDir()
+	installer := &fakePluginInstaller{}
+	pm := createManager(t, func(pm *PluginManager) {
+		pm.Cfg.PluginsPath = pluginsPath
+		pm.pluginInstaller = installer
+	})
+	failing := &plugins.PluginBase{Id: "test-panel", Type: "panel", PluginDir: filepath.Join(pluginsPath, "test-panel"),
+		Info: plugins.PluginInfo{Version: "2.0.0"}}
+	pm.plugins = map[string]*plugins.PluginBase{failing.Id: failing}
+
+	previous := &plugins.PluginBase{Id: "test-panel", Info: plugins.PluginInfo{Version: "1.0.0"}}
+	pm.rollbackInstall(context.Background(), "test-panel", previous)
+
+	require.Nil(t, pm.GetPlugin("test-panel"))
+	require.Equal(t, 1, installer.uninstallCount)
+	require.Equal(t, 1, installer.installCount)
+
+	err := plugins.PluginVerificationError{PluginID: "test-panel", Err: backendplugin.ErrPluginUnavailable}
+	require.True(t, errors.Is(err, backendplugin.ErrPluginUnavailable))
+}
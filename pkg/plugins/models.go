@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -19,6 +20,7 @@ var (
 	ErrUninstallCorePlugin         = errors.New("cannot uninstall a Core plugin")
 	ErrUninstallOutsideOfPluginDir = errors.New("cannot uninstall a plugin outside")
 	ErrPluginNotInstalled          = errors.New("plugin is not installed")
+	ErrInstallNotAllowed           = errors.New("installing the plugin is not allowed")
 )
 
 type PluginNotFoundError struct {
@@ -44,6 +46,45 @@ func (e DuplicatePluginError) Is(err error) bool {
 	return ok
 }
 
+// PluginVerificationError is returned when a newly installed plugin fails verification, in which case the install is
+// rolled back.
+type PluginVerificationError struct {
+	PluginID string
+	Err      error
+}
+
+func (e PluginVerificationError) Error() string {
+	return fmt.Sprintf("plugin '%s' failed verification after install: %s", e.PluginID, e.Err)
+}
+
+func (e PluginVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// PluginDiskQuotaExceededError is returned when the archive of a plugin, or the newly installed plugin, uses more disk
+// than its quota, in which case the archive isn't extracted or the install is rolled back.
+type PluginDiskQuotaExceededError struct {
+	PluginID string
+	Size     int64
+	Quota    int64
+}
+
+func (e PluginDiskQuotaExceededError) Error() string {
+	return fmt.Sprintf("plugin '%s' uses %d bytes of disk, over its quota of %d bytes", e.PluginID, e.Size, e.Quota)
+}
+
+// PluginDiskUsage is the disk usage of a plugin installed in the plugins directory, as of its last check.
+type PluginDiskUsage struct {
+	PluginID string `json:"pluginId"`
+	Size     int64  `json:"size"`
+	// Quota is the disk quota of the plugin in bytes, 0 if the plugin has no quota.
+	Quota    int64 `json:"quota"`
+	Exceeded bool  `json:"exceeded"`
+	// Stopped is whether the backend of the plugin was stopped because the plugin exceeds its quota.
+	Stopped bool      `json:"stopped"`
+	Checked time.Time `json:"checked"`
+}
+
 // PluginLoader can load a plugin.
 type PluginLoader interface {
 	// Load loads a plugin and returns it.
@@ -52,20 +93,28 @@ type PluginLoader interface {
 
 // PluginBase is the base plugin type.
 type PluginBase struct {
-	Type         string                `json:"type"`
-	Name         string                `json:"name"`
-	Id           string                `json:"id"`
-	Info         PluginInfo            `json:"info"`
-	Dependencies PluginDependencies    `json:"dependencies"`
-	Includes     []*PluginInclude      `json:"includes"`
-	Module       string                `json:"module"`
-	BaseUrl      string                `json:"baseUrl"`
-	Category     string                `json:"category"`
-	HideFromList bool                  `json:"hideFromList,omitempty"`
-	Preload      bool                  `json:"preload"`
-	State        PluginState           `json:"state,omitempty"`
-	Signature    PluginSignatureStatus `json:"signature"`
-	Backend      bool                  `json:"backend"`
+	Type            string                `json:"type"`
+	Name            string                `json:"name"`
+	Id              string                `json:"id"`
+	Info            PluginInfo            `json:"info"`
+	Dependencies    PluginDependencies    `json:"dependencies"`
+	Includes        []*PluginInclude      `json:"includes"`
+	Module          string                `json:"module"`
+	BaseUrl         string                `json:"baseUrl"`
+	Category        string                `json:"category"`
+	HideFromList    bool                  `json:"hideFromList,omitempty"`
+	Preload         bool                  `json:"preload"`
+	PreloadPriority int                   `json:"preloadPriority,omitempty"`
+	State           PluginState           `json:"state,omitempty"`
+	Signature       PluginSignatureStatus `json:"signature"`
+	Backend         bool                  `json:"backend"`
+	SettingsSchema  *SettingsSchema       `json:"settingsSchema,omitempty"`
+	// Resources are the resource routes of the backend of the plugin, see PluginResourceRoute.
+	Resources []*PluginResourceRoute `json:"resources,omitempty"`
+	// Roles are the access control roles declared by the plugin, see PluginRoleRegistration.
+	Roles []*PluginRoleRegistration `json:"roles,omitempty"`
+	// LiveChannels are the rules of the Live channels of the plugin, see PluginLiveChannel.
+	LiveChannels []*PluginLiveChannel `json:"liveChannels,omitempty"`
 
 	IncludedInAppId string              `json:"-"`
 	PluginDir       string              `json:"-"`
@@ -77,6 +126,25 @@ type PluginBase struct {
 
 	GrafanaNetVersion   string `json:"-"`
 	GrafanaNetHasUpdate bool   `json:"-"`
+	// Advisories are the security advisories affecting the installed version, from the plugin advisories feed.
+	Advisories []PluginAdvisory `json:"-"`
+	// Deprecations are the deprecated frontend APIs the plugin relies on, detected in its module.
+	Deprecations []PluginDeprecation `json:"-"`
+	// IsDevPlugin is set for the plugins loaded from the development directories, the development plugins directory,
+	// whose plugins are reloaded when their files change, and the unsigned plugin paths. It's the dev badge of the
+	// plugin payloads.
+	IsDevPlugin bool `json:"dev,omitempty"`
+	// FeatureToggles are the feature toggles enabled for the plugin with the feature_toggles setting of its
+	// [plugin.<plugin id>] section.
+	FeatureToggles []string `json:"featureToggles,omitempty"`
+	// Locales are the locales the plugin has translations for in its locales directory, see DiscoverLocales.
+	Locales []string `json:"locales,omitempty"`
+	// AssetVersion is the version of the assets of external plugins, set when versioned asset URLs are enabled,
+	// see ComputeAssetVersion.
+	AssetVersion string `json:"-"`
+	// ModuleIntegrity is the Subresource Integrity hash of the module of external plugins, see
+	// ComputeModuleIntegrity.
+	ModuleIntegrity string `json:"moduleIntegrity,omitempty"`
 
 	Root *PluginBase
 }
@@ -114,6 +182,9 @@ type PluginInclude struct {
 	Slug       string          `json:"slug"`
 	Icon       string          `json:"icon"`
 	UID        string          `json:"uid"`
+	// Action is the access control action required to access the include, instead of its role, when access control
+	// is enabled.
+	Action string `json:"action,omitempty"`
 
 	Id string `json:"-"`
 }
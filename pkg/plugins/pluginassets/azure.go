@@ -0,0 +1,95 @@
+package pluginassets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	azureDateLayout = "Mon, 02 Jan 2006 15:04:05 GMT"
+	azureAPIVersion = "2017-04-17"
+)
+
+// azureBlobStorage uses the Azure Blob Storage REST API with the shared key client of the image uploader.
+type azureBlobStorage struct {
+	container string
+	client    *imguploader.StorageClient
+}
+
+func newAzureBlobStorage(settings setting.PluginAssetStorageSettings) (*azureBlobStorage, error) {
+	if settings.AccountName == "" || settings.AccountKey == "" || settings.Bucket == "" {
+		return nil, errors.New("account_name, account_key and bucket are required")
+	}
+	return &azureBlobStorage{
+		container: settings.Bucket,
+		client:    imguploader.NewStorageClient(settings.AccountName, settings.AccountKey),
+	}, nil
+}
+
+// upload uploads the asset, whose content type is derived from its extension by the client.
+func (s *azureBlobStorage) upload(ctx context.Context, key string, _ string, body io.Reader) error {
+	resp, err := s.client.FileUpload(ctx, s.container, key, body)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 300 {
+		return azureBlobError(resp)
+	}
+	return nil
+}
+
+func (s *azureBlobStorage) open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	blobURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", s.client.Auth.Account, s.container,
+		(&url.URL{Path: key}).EscapedPath())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, blobURL, nil)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(azureDateLayout))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	if err := s.client.Auth.SignRequest(req); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	// nolint:bodyclose
+	// The body is closed by the caller on success.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ObjectInfo{}, ErrAssetNotFound
+		}
+		return nil, ObjectInfo{}, azureBlobError(resp)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, ObjectInfo{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+		ModTime:     modTime,
+	}, nil
+}
+
+func azureBlobError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	return fmt.Errorf("azure blob storage responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,69 @@
+package pluginassets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"cloud.google.com/go/storage"
+	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+type gcsStorage struct {
+	bucket *storage.BucketHandle
+}
+
+func newGCSStorage(ctx context.Context, settings setting.PluginAssetStorageSettings) (*gcsStorage, error) {
+	if settings.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	// without key file, the application default credentials are used
+	opts := []option.ClientOption{option.WithScopes(storage.ScopeReadWrite)}
+	if settings.KeyFile != "" {
+		keyData, err := ioutil.ReadFile(settings.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		creds, err := google.CredentialsFromJSON(ctx, keyData, storage.ScopeReadWrite)
+		if err != nil {
+			return nil, err
+		}
+		opts = []option.ClientOption{option.WithCredentials(creds)}
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsStorage{bucket: client.Bucket(settings.Bucket)}, nil
+}
+
+func (s *gcsStorage) upload(ctx context.Context, key string, contentType string, body io.Reader) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, body); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *gcsStorage) open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	r, err := s.bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ObjectInfo{}, ErrAssetNotFound
+		}
+		return nil, ObjectInfo{}, err
+	}
+
+	return r, ObjectInfo{
+		ContentType: r.Attrs.ContentType,
+		Size:        r.Attrs.Size,
+		ModTime:     r.Attrs.LastModified,
+	}, nil
+}
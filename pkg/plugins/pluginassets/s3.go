@@ -0,0 +1,84 @@
+package pluginassets
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type s3Storage struct {
+	bucket   string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+func newS3Storage(settings setting.PluginAssetStorageSettings) (*s3Storage, error) {
+	if settings.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	cfg := aws.NewConfig().WithS3ForcePathStyle(settings.PathStyleAccess)
+	if settings.Region != "" {
+		cfg = cfg.WithRegion(settings.Region)
+	}
+	if settings.Endpoint != "" {
+		cfg = cfg.WithEndpoint(settings.Endpoint)
+	}
+	// without access key, the credentials come from the default chain: environment, shared credentials, web identity,
+	// ECS task or EC2 instance role
+	if settings.AccessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(settings.AccessKey, settings.SecretKey, ""))
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Storage{
+		bucket:   settings.Bucket,
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+	}, nil
+}
+
+func (s *s3Storage) upload(ctx context.Context, key string, contentType string, body io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (s *s3Storage) open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ObjectInfo{}, ErrAssetNotFound
+		}
+		return nil, ObjectInfo{}, err
+	}
+
+	return out.Body, ObjectInfo{
+		ContentType: aws.StringValue(out.ContentType),
+		Size:        aws.Int64Value(out.ContentLength),
+		ModTime:     aws.TimeValue(out.LastModified),
+	}, nil
+}
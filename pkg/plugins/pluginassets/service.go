@@ -0,0 +1,204 @@
+// Package pluginassets syncs the frontend assets of the installed plugins to an object storage, and serves them from
+// it, so that the Grafana instances of a horizontally scaled deployment don't each need them on their disk.
+package pluginassets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrAssetNotFound is returned when the asset isn't in the object storage.
+var ErrAssetNotFound = errors.New("plugin asset not found")
+
+// assetExtensions are the extensions of the files of the plugins synced to the object storage, i.e. of their frontend
+// assets, leaving out their backend binaries and anything else served from the plugins directory only.
+var assetExtensions = map[string]bool{
+	".js": true, ".css": true, ".map": true, ".json": true, ".html": true, ".md": true, ".txt": true,
+	".svg": true, ".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".ico": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+}
+
+// latestVersionKey is the name of the object, next to the versions of a plugin, storing the version of the plugin
+// synced last. It lets the instances without the plugin on their disk serve its assets.
+const latestVersionKey = "latest"
+
+// ObjectInfo describes an asset of the object storage.
+type ObjectInfo struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// objectStorage is an object storage provider.
+type objectStorage interface {
+	upload(ctx context.Context, key string, contentType string, body io.Reader) error
+	open(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+}
+
+// Service syncs and serves the frontend assets of the plugins with the object storage configured in the
+// [plugins.asset_storage] section, if any.
+type Service struct {
+	storage objectStorage
+	prefix  string
+	log     log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) (*Service, error) {
+	s := &Service{
+		prefix: cfg.PluginAssetStorage.Path,
+		log:    log.New("plugins.assets"),
+	}
+
+	var err error
+	switch settings := cfg.PluginAssetStorage; settings.Provider {
+	case "":
+		return s, nil
+	case "s3":
+		s.storage, err = newS3Storage(settings)
+	case "gcs":
+		s.storage, err = newGCSStorage(context.Background(), settings)
+	case "azure_blob":
+		s.storage, err = newAzureBlobStorage(settings)
+	default:
+		err = fmt.Errorf("unsupported provider %q", settings.Provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin asset storage: %w", err)
+	}
+	s.log.Info("Serving plugin assets from object storage", "provider", cfg.PluginAssetStorage.Provider,
+		"bucket", cfg.PluginAssetStorage.Bucket)
+	return s, nil
+}
+
+// Enabled returns whether an object storage is configured.
+func (s *Service) Enabled() bool {
+	return s != nil && s.storage != nil
+}
+
+// Sync uploads the frontend assets of the plugin to the object storage, under its version, so that the instances
+// serving a different version of the plugin during a rollout still find theirs.
+func (s *Service) Sync(ctx context.Context, p *plugins.PluginBase) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	count := 0
+	err := filepath.Walk(p.PluginDir, func(filePath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// symbolic links are left out so that the plugin can't sync files outside of its directory
+		if !fi.Mode().IsRegular() || !assetExtensions[strings.ToLower(filepath.Ext(filePath))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(p.PluginDir, filePath)
+		if err != nil {
+			return err
+		}
+		if err := s.upload(ctx, p, filePath, filepath.ToSlash(rel)); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the version is stored once its assets are uploaded, so that it's only served once complete
+	if err := s.storage.upload(ctx, path.Join(s.prefix, p.Id, latestVersionKey), "text/plain",
+		strings.NewReader(p.Info.Version)); err != nil {
+		return fmt.Errorf("failed to upload the version: %w", err)
+	}
+
+	s.log.Info("Synced plugin assets to object storage", "pluginId", p.Id, "version", p.Info.Version, "files", count)
+	return nil
+}
+
+// SyncedPlugin returns the plugin as last synced to the object storage, with its ID and version only, for the
+// instances serving the assets of a plugin that isn't on their disk. Returns ErrAssetNotFound if the plugin was
+// never synced.
+func (s *Service) SyncedPlugin(ctx context.Context, pluginID string) (*plugins.PluginBase, error) {
+	if !s.Enabled() || !isPathElement(pluginID) {
+		return nil, ErrAssetNotFound
+	}
+
+	r, _, err := s.storage.open(ctx, path.Join(s.prefix, pluginID, latestVersionKey))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			s.log.Warn("Failed to close plugin version", "pluginId", pluginID, "err", err)
+		}
+	}()
+
+	content, err := ioutil.ReadAll(io.LimitReader(r, 256))
+	if err != nil {
+		return nil, err
+	}
+	version := strings.TrimSpace(string(content))
+	if !isPathElement(version) {
+		return nil, fmt.Errorf("invalid version %q of plugin %s", version, pluginID)
+	}
+	return &plugins.PluginBase{Id: pluginID, Info: plugins.PluginInfo{Version: version}}, nil
+}
+
+// isPathElement returns whether the plugin ID or version is a single element of the keys of the object storage.
+func isPathElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, "/\\")
+}
+
+func (s *Service) upload(ctx context.Context, p *plugins.PluginBase, filePath string, file string) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the files are based on the plugin folder
+	// structure on disk and not user input.
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			s.log.Warn("Failed to close file", "path", filePath, "err", err)
+		}
+	}()
+
+	contentType := mime.TypeByExtension(path.Ext(file))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return s.storage.upload(ctx, s.key(p, file), contentType, f)
+}
+
+// Open returns the asset of the plugin, from the object storage. The file is relative to the directory of the plugin.
+func (s *Service) Open(ctx context.Context, p *plugins.PluginBase, file string) (io.ReadCloser, ObjectInfo, error) {
+	if !s.Enabled() {
+		return nil, ObjectInfo{}, ErrAssetNotFound
+	}
+
+	file = filepath.ToSlash(file)
+	if path.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") ||
+		!assetExtensions[strings.ToLower(path.Ext(file))] {
+		return nil, ObjectInfo{}, ErrAssetNotFound
+	}
+	return s.storage.open(ctx, s.key(p, path.Clean(file)))
+}
+
+// key returns the key of the asset in the object storage, i.e. <path>/<plugin id>/<version>/<file>.
+func (s *Service) key(p *plugins.PluginBase, file string) string {
+	return path.Join(s.prefix, p.Id, p.Info.Version, file)
+}
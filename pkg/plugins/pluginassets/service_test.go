@@ -0,0 +1,117 @@
+package pluginassets
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	t.Run("Should be disabled without provider", func(t *testing.T) {
+		s, err := ProvideService(setting.NewCfg())
+		require.NoError(t, err)
+		require.False(t, s.Enabled())
+		require.NoError(t, s.Sync(context.Background(), &plugins.PluginBase{}))
+
+		var nilService *Service
+		require.False(t, nilService.Enabled())
+	})
+
+	t.Run("Should sync the frontend assets and serve them", func(t *testing.T) {
+		dir := t.TempDir()
+		for file, content := range map[string]string{
+			"module.js":          "define([])",
+			"img/logo.svg":       "<svg></svg>",
+			"locales/fr/ns.json": "{}",
+			"gpx_test_linux":     "binary",
+		} {
+			path := filepath.Join(dir, file)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+
+		storage := &fakeStorage{objects: map[string]fakeObject{}}
+		s := &Service{storage: storage, prefix: "grafana", log: log.New("test")}
+		p := &plugins.PluginBase{Id: "test-app", PluginDir: dir, Info: plugins.PluginInfo{Version: "1.0.0"}}
+		require.NoError(t, s.Sync(context.Background(), p))
+
+		require.Len(t, storage.objects, 4)
+		require.Equal(t, "1.0.0", string(storage.objects["grafana/test-app/latest"].content))
+		require.Contains(t, storage.objects, "grafana/test-app/1.0.0/img/logo.svg")
+		require.Equal(t, "image/svg+xml", storage.objects["grafana/test-app/1.0.0/img/logo.svg"].contentType)
+
+		r, info, err := s.Open(context.Background(), p, "module.js")
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "define([])", string(content))
+		require.Equal(t, int64(10), info.Size)
+
+		for _, file := range []string{"gpx_test_linux", "../other-app/module.js", "/module.js", "missing.js"} {
+			_, _, err = s.Open(context.Background(), p, file)
+			require.ErrorIs(t, err, ErrAssetNotFound, file)
+		}
+
+		synced, err := s.SyncedPlugin(context.Background(), "test-app")
+		require.NoError(t, err)
+		require.Equal(t, "test-app", synced.Id)
+		require.Equal(t, "1.0.0", synced.Info.Version)
+		r, _, err = s.Open(context.Background(), synced, "module.js")
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	})
+
+	t.Run("Should not find plugins that were never synced", func(t *testing.T) {
+		storage := &fakeStorage{objects: map[string]fakeObject{
+			"grafana/bad-app/latest": {content: []byte("../test-app")},
+		}}
+		s := &Service{storage: storage, prefix: "grafana", log: log.New("test")}
+
+		for _, pluginID := range []string{"other-app", "..", "test-app/1.0.0", ""} {
+			_, err := s.SyncedPlugin(context.Background(), pluginID)
+			require.ErrorIs(t, err, ErrAssetNotFound, pluginID)
+		}
+		_, err := s.SyncedPlugin(context.Background(), "bad-app")
+		require.Error(t, err)
+	})
+}
+
+type fakeObject struct {
+	content     []byte
+	contentType string
+}
+
+type fakeStorage struct {
+	objects map[string]fakeObject
+}
+
+func (s *fakeStorage) upload(_ context.Context, key string, contentType string, body io.Reader) error {
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = fakeObject{content: content, contentType: contentType}
+	return nil
+}
+
+func (s *fakeStorage) open(_ context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ObjectInfo{}, ErrAssetNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(object.content)), ObjectInfo{
+		ContentType: object.contentType,
+		Size:        int64(len(object.content)),
+		ModTime:     time.Now(),
+	}, nil
+}
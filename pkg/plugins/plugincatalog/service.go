@@ -0,0 +1,197 @@
+package plugincatalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	catalogCacheKey        = "plugincatalog-plugins"
+	catalogCacheExpiration = 10 * time.Minute
+)
+
+// SearchQuery filters the plugins of the catalog.
+type SearchQuery struct {
+	OrgID int64
+	// Query matches the ID, name, description or organization of the plugins, case insensitively.
+	Query string
+	// Type matches the type of the plugins, e.g. "datasource".
+	Type string
+}
+
+// SearchResult is the plugins of the catalog matching a search. Items are the plugins as returned by the catalog,
+// with the installedVersion, hasUpdate and enabled fields added for the plugins installed in Grafana.
+type SearchResult struct {
+	Items []map[string]interface{} `json:"items"`
+}
+
+type catalogPlugin struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OrgName     string `json:"orgName"`
+	TypeCode    string `json:"typeCode"`
+	Version     string `json:"version"`
+
+	fields map[string]interface{}
+}
+
+func ProvideService(cfg *setting.Cfg, pluginManager plugins.Manager) *Service {
+	return &Service{
+		Cfg:           cfg,
+		PluginManager: pluginManager,
+		cache:         localcache.New(catalogCacheExpiration, 2*catalogCacheExpiration),
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		},
+		logger: log.New("plugincatalog"),
+	}
+}
+
+// Service searches the plugin catalog of grafana.com on behalf of the browser, so that it doesn't need to reach
+// grafana.com. The catalog is cached, and the plugins which can't be installed because of [plugins]
+// install_allow_list and install_deny_list are left out of the results.
+type Service struct {
+	Cfg           *setting.Cfg
+	PluginManager plugins.Manager
+
+	cache  *localcache.CacheService
+	client *http.Client
+	logger log.Logger
+}
+
+// Search returns the plugins of the catalog matching the query, in the order of the catalog.
+func (s *Service) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	catalog, err := s.getCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginSettings, err := s.PluginManager.GetPluginSettings(query.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Items: []map[string]interface{}{}}
+	for _, p := range catalog {
+		if !s.Cfg.IsPluginInstallAllowed(p.Slug) || !p.matches(query) {
+			continue
+		}
+
+		item := make(map[string]interface{}, len(p.fields)+3)
+		for k, v := range p.fields {
+			item[k] = v
+		}
+		if installed := s.PluginManager.GetPlugin(p.Slug); installed != nil {
+			item["installedVersion"] = installed.Info.Version
+			item["hasUpdate"] = isNewer(p.Version, installed.Info.Version)
+			if ps, ok := pluginSettings[p.Slug]; ok {
+				item["enabled"] = ps.Enabled
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+func (p catalogPlugin) matches(query SearchQuery) bool {
+	if query.Type != "" && p.TypeCode != query.Type {
+		return false
+	}
+	if query.Query == "" {
+		return true
+	}
+	q := strings.ToLower(query.Query)
+	for _, field := range []string{p.Slug, p.Name, p.Description, p.OrgName} {
+		if strings.Contains(strings.ToLower(field), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNewer returns whether the catalog version is newer than the installed one. Versions which can't be parsed are
+// compared as strings.
+func isNewer(catalogVersion, installedVersion string) bool {
+	cv, err := version.NewVersion(catalogVersion)
+	if err != nil {
+		return catalogVersion != installedVersion
+	}
+	iv, err := version.NewVersion(installedVersion)
+	if err != nil {
+		return catalogVersion != installedVersion
+	}
+	return cv.GreaterThan(iv)
+}
+
+func (s *Service) getCatalog(ctx context.Context) ([]catalogPlugin, error) {
+	if cached, found := s.cache.Get(catalogCacheKey); found {
+		return cached.([]catalogPlugin), nil
+	}
+
+	u, err := url.Parse(s.Cfg.GrafanaComURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "api", "plugins")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "grafana "+s.Cfg.BuildVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search the plugin catalog: status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	catalog := make([]catalogPlugin, 0, len(response.Items))
+	for _, raw := range response.Items {
+		var p catalogPlugin
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &p.fields); err != nil {
+			return nil, err
+		}
+		catalog = append(catalog, p)
+	}
+
+	s.cache.Set(catalogCacheKey, catalog, catalogCacheExpiration)
+	return catalog, nil
+}
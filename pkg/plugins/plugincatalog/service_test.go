@@ -0,0 +1,112 @@
+package plugincatalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePluginManager struct {
+	plugins.Manager
+
+	plugins  map[string]*plugins.PluginBase
+	settings map[int64]map[string]*models.PluginSettingInfoDTO
+}
+
+func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
+	return pm.plugins[id]
+}
+
+func (pm *fakePluginManager) GetPluginSettings(orgID int64) (map[string]*models.PluginSettingInfoDTO, error) {
+	return pm.settings[orgID], nil
+}
+
+func slugs(result *SearchResult) []string {
+	var slugs []string
+	for _, item := range result.Items {
+		slugs = append(slugs, item["slug"].(string))
+	}
+	return slugs
+}
+
+func TestService_Search(t *testing.T) {
+	catalogRequests := 0
+	catalog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		catalogRequests++
+		if r.URL.Path != "/api/plugins" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [
+			{"slug": "test-app", "name": "Test App", "description": "An app", "orgName": "Acme", "typeCode": "app", "version": "2.0.0", "downloads": 10},
+			{"slug": "test-datasource", "name": "Test", "description": "A data source", "orgName": "Acme", "typeCode": "datasource", "version": "1.0.0"},
+			{"slug": "denied-panel", "name": "Denied", "description": "A panel", "orgName": "Other", "typeCode": "panel", "version": "1.0.0"}
+		]}`))
+	}))
+	t.Cleanup(catalog.Close)
+
+	cfg := setting.NewCfg()
+	cfg.GrafanaComURL = catalog.URL
+	cfg.PluginsInstallDenyList = []string{"denied-panel"}
+	pm := &fakePluginManager{
+		plugins: map[string]*plugins.PluginBase{
+			"test-app":        {Id: "test-app", Info: plugins.PluginInfo{Version: "1.0.0"}},
+			"test-datasource": {Id: "test-datasource", Info: plugins.PluginInfo{Version: "1.0.0"}},
+		},
+		settings: map[int64]map[string]*models.PluginSettingInfoDTO{
+			1: {"test-app": {PluginId: "test-app", OrgId: 1, Enabled: true}},
+			2: {"test-app": {PluginId: "test-app", OrgId: 2, Enabled: false}},
+		},
+	}
+	s := ProvideService(cfg, pm)
+
+	t.Run("Should leave out the plugins which can't be installed and annotate the installed ones", func(t *testing.T) {
+		result, err := s.Search(context.Background(), SearchQuery{OrgID: 1})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"test-app", "test-datasource"}, slugs(result))
+		app := result.Items[0]
+		require.Equal(t, float64(10), app["downloads"])
+		require.Equal(t, "1.0.0", app["installedVersion"])
+		require.Equal(t, true, app["hasUpdate"])
+		require.Equal(t, true, app["enabled"])
+		require.Equal(t, false, result.Items[1]["hasUpdate"])
+	})
+
+	t.Run("Should use the plugin settings of the organization", func(t *testing.T) {
+		result, err := s.Search(context.Background(), SearchQuery{OrgID: 2, Type: "app"})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"test-app"}, slugs(result))
+		require.Equal(t, false, result.Items[0]["enabled"])
+	})
+
+	t.Run("Should filter by query", func(t *testing.T) {
+		result, err := s.Search(context.Background(), SearchQuery{OrgID: 1, Query: "data SOURCE"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"test-datasource"}, slugs(result))
+
+		result, err = s.Search(context.Background(), SearchQuery{OrgID: 1, Query: "unknown"})
+		require.NoError(t, err)
+		require.Empty(t, result.Items)
+	})
+
+	t.Run("Should only include the plugins of the allow list when set", func(t *testing.T) {
+		cfg.PluginsInstallAllowList = []string{"test-datasource", "denied-panel"}
+		t.Cleanup(func() { cfg.PluginsInstallAllowList = nil })
+
+		result, err := s.Search(context.Background(), SearchQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"test-datasource"}, slugs(result))
+	})
+
+	t.Run("Should cache the catalog", func(t *testing.T) {
+		require.Equal(t, 1, catalogRequests)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -14,24 +15,27 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/datasources"
-	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
-	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 func ProvideService(bus bus.Bus, cacheService *localcache.CacheService, pluginManager plugins.Manager,
-	dataSourceCache datasources.CacheService, encryptionService encryption.Service,
+	dataSourceCache datasources.CacheService, secretsService secrets.Service,
 	pluginSettingsService *pluginsettings.Service) *Provider {
-	return &Provider{
+	p := &Provider{
 		Bus:                   bus,
 		CacheService:          cacheService,
 		PluginManager:         pluginManager,
 		DataSourceCache:       dataSourceCache,
-		EncryptionService:     encryptionService,
+		SecretsService:        secretsService,
 		PluginSettingsService: pluginSettingsService,
 		logger:                log.New("plugincontext"),
 	}
+
+	p.Bus.AddEventListener(p.handlePluginSettingUpdated)
+
+	return p
 }
 
 type Provider struct {
@@ -39,7 +43,7 @@ type Provider struct {
 	CacheService          *localcache.CacheService
 	PluginManager         plugins.Manager
 	DataSourceCache       datasources.CacheService
-	EncryptionService     encryption.Service
+	SecretsService        secrets.Service
 	PluginSettingsService *pluginsettings.Service
 	logger                log.Logger
 }
@@ -90,7 +94,7 @@ func (p *Provider) Get(pluginID string, datasourceUID string, user *models.Signe
 		if err != nil {
 			return pc, false, errutil.Wrap("Failed to get datasource", err)
 		}
-		datasourceSettings, err := adapters.ModelToInstanceSettings(ds, p.decryptSecureJsonDataFn())
+		datasourceSettings, err := adapters.ModelToInstanceSettings(ds, p.decryptSecureJsonDataFn(ds))
 		if err != nil {
 			return pc, false, errutil.Wrap("Failed to convert datasource", err)
 		}
@@ -103,14 +107,15 @@ func (p *Provider) Get(pluginID string, datasourceUID string, user *models.Signe
 const pluginSettingsCacheTTL = 5 * time.Second
 const pluginSettingsCachePrefix = "plugin-setting-"
 
+func pluginSettingsCacheKey(orgID int64, pluginID string) string {
+	return fmt.Sprintf("%s%d-%s", pluginSettingsCachePrefix, orgID, pluginID)
+}
+
 func (p *Provider) getCachedPluginSettings(pluginID string, user *models.SignedInUser) (*models.PluginSetting, error) {
-	cacheKey := pluginSettingsCachePrefix + pluginID
+	cacheKey := pluginSettingsCacheKey(user.OrgId, pluginID)
 
 	if cached, found := p.CacheService.Get(cacheKey); found {
-		ps := cached.(*models.PluginSetting)
-		if ps.OrgId == user.OrgId {
-			return ps, nil
-		}
+		return cached.(*models.PluginSetting), nil
 	}
 
 	query := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: user.OrgId}
@@ -122,11 +127,34 @@ func (p *Provider) getCachedPluginSettings(pluginID string, user *models.SignedI
 	return query.Result, nil
 }
 
-func (p *Provider) decryptSecureJsonDataFn() func(map[string][]byte) map[string]string {
+// handlePluginSettingUpdated removes the cached settings of the plugin, so that the app instance settings of the
+// next requests, and in particular their decrypted secure JSON data, are the updated ones.
+func (p *Provider) handlePluginSettingUpdated(event *models.PluginSettingUpdatedEvent) error {
+	p.CacheService.Delete(pluginSettingsCacheKey(event.OrgId, event.PluginId))
+	return nil
+}
+
+// decryptSecureJsonDataFn returns a function decrypting the secure JSON data of the data source at once, so that
+// each data key is only decrypted once for all the secrets.
+func (p *Provider) decryptSecureJsonDataFn(ds *models.DataSource) func(map[string][]byte) map[string]string {
+	ctx := secrets.WithAuditSecret(context.Background(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: ds.OrgId,
+		UID:   ds.Uid,
+		Name:  ds.Name,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{Service: "plugincontext", PluginID: ds.Type})
+
 	return func(m map[string][]byte) map[string]string {
-		decryptedJsonData, err := p.EncryptionService.DecryptJsonData(context.Background(), m, setting.SecretKey)
+		decrypted, err := p.SecretsService.DecryptMany(ctx, m)
 		if err != nil {
 			p.logger.Error("Failed to decrypt secure json data", "error", err)
+			return nil
+		}
+
+		decryptedJsonData := make(map[string]string, len(decrypted))
+		for key, value := range decrypted {
+			decryptedJsonData[key] = string(value)
 		}
 		return decryptedJsonData
 	}
@@ -0,0 +1,28 @@
+package plugincontext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginSettingsCache(t *testing.T) {
+	t.Run("Updating the settings of a plugin should only remove them from the cache for their organization", func(t *testing.T) {
+		cacheService := localcache.New(time.Minute, time.Minute)
+		p := &Provider{CacheService: cacheService}
+
+		cacheService.Set(pluginSettingsCacheKey(1, "test-app"), &models.PluginSetting{OrgId: 1, PluginId: "test-app"}, time.Minute)
+		cacheService.Set(pluginSettingsCacheKey(2, "test-app"), &models.PluginSetting{OrgId: 2, PluginId: "test-app"}, time.Minute)
+
+		err := p.handlePluginSettingUpdated(&models.PluginSettingUpdatedEvent{PluginId: "test-app", OrgId: 1})
+		require.NoError(t, err)
+
+		_, found := cacheService.Get(pluginSettingsCacheKey(1, "test-app"))
+		require.False(t, found)
+		_, found = cacheService.Get(pluginSettingsCacheKey(2, "test-app"))
+		require.True(t, found)
+	})
+}
@@ -0,0 +1,86 @@
+package plugindashboards
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+const (
+	// dashboardFolderJSONKey is the key of the JSON data of the plugin settings of an organization with the title of
+	// the folder the dashboards of the plugin are imported in by default.
+	dashboardFolderJSONKey = "dashboardFolder"
+	// dashboardFolderSetting is the key of the plugin settings, i.e. of the [plugin.<plugin id>] section of the
+	// configuration, with the title of the default folder of the organizations which don't set their own.
+	dashboardFolderSetting = "dashboard_folder"
+)
+
+// DefaultDashboardFolderTitle returns the title of the folder the dashboards of the plugin are imported in by
+// default in the organization, or an empty string if they're imported in the General folder. The folder set in the
+// plugin settings of the organization takes precedence over the one of the configuration.
+func (s *Service) DefaultDashboardFolderTitle(ctx context.Context, orgID int64, pluginID string) (string, error) {
+	var title string
+	query := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil && !errors.Is(err, models.ErrPluginSettingNotFound) {
+		return "", err
+	}
+	if query.Result != nil {
+		title, _ = query.Result.JsonData[dashboardFolderJSONKey].(string)
+	}
+	if strings.TrimSpace(title) == "" && s.Cfg != nil {
+		title = s.Cfg.PluginSettings[pluginID][dashboardFolderSetting]
+	}
+
+	title = strings.TrimSpace(title)
+	if strings.EqualFold(title, models.RootFolderName) {
+		return "", nil
+	}
+	return title, nil
+}
+
+// DefaultDashboardFolder returns the ID of the default folder of the dashboards of the plugin in the organization of
+// the user, or 0 for the General folder. If create is set, the folder is created if it doesn't exist, otherwise 0 is
+// returned for it. The folder is looked up and created with the permissions of the user, and the imported dashboards
+// inherit its permissions.
+func (s *Service) DefaultDashboardFolder(ctx context.Context, user *models.SignedInUser, pluginID string,
+	create bool) (int64, error) {
+	title, err := s.DefaultDashboardFolderTitle(ctx, user.OrgId, pluginID)
+	if err != nil || title == "" {
+		return 0, err
+	}
+	return s.folderByTitle(ctx, user, title, create)
+}
+
+// ensureFolder returns the ID of the folder of the organization with the given title, creating it if it doesn't
+// exist.
+func (s *Service) ensureFolder(ctx context.Context, orgID int64, title string) (int64, error) {
+	return s.folderByTitle(ctx, serviceUser(orgID), title, true)
+}
+
+// folderByTitle returns the ID of the folder of the organization of the user with the given title. If the folder
+// doesn't exist, it's created if create is set, and 0 is returned otherwise.
+func (s *Service) folderByTitle(ctx context.Context, user *models.SignedInUser, title string, create bool) (int64, error) {
+	folderService := dashboards.NewFolderService(user.OrgId, user, s.SQLStore)
+
+	folder, err := folderService.GetFolderByTitle(ctx, title)
+	if errors.Is(err, models.ErrFolderNotFound) {
+		if !create {
+			return 0, nil
+		}
+		s.logger.Info("Creating folder for plugin dashboards", "orgId", user.OrgId, "folder", title)
+		folder, err = folderService.CreateFolder(ctx, title, "")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return folder.Id, nil
+}
+
+// serviceUser is the user the dashboards of the plugins are synced and removed with.
+func serviceUser(orgID int64) *models.SignedInUser {
+	return &models.SignedInUser{UserId: 0, OrgId: orgID, OrgRole: models.ROLE_ADMIN}
+}
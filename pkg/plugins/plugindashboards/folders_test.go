@@ -0,0 +1,147 @@
+package plugindashboards
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_DefaultDashboardFolderTitle(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetPluginSettingByIdQuery) error {
+		switch query.OrgId {
+		case 1:
+			query.Result = &models.PluginSetting{JsonData: map[string]interface{}{dashboardFolderJSONKey: " Monitoring "}}
+		case 2:
+			query.Result = &models.PluginSetting{JsonData: map[string]interface{}{dashboardFolderJSONKey: "General"}}
+		case 3:
+			query.Result = &models.PluginSetting{JsonData: map[string]interface{}{}}
+		default:
+			return models.ErrPluginSettingNotFound
+		}
+		return nil
+	})
+
+	s := &Service{Cfg: setting.NewCfg()}
+	s.Cfg.PluginSettings = setting.PluginSettings{"test-app": {dashboardFolderSetting: "Kubernetes"}}
+
+	for orgID, expected := range map[int64]string{1: "Monitoring", 2: "", 3: "Kubernetes", 4: "Kubernetes"} {
+		title, err := s.DefaultDashboardFolderTitle(context.Background(), orgID, "test-app")
+		require.NoError(t, err)
+		assert.Equal(t, expected, title, orgID)
+	}
+
+	title, err := s.DefaultDashboardFolderTitle(context.Background(), 4, "other-app")
+	require.NoError(t, err)
+	assert.Empty(t, title)
+}
+
+func TestService_DefaultDashboardFolder(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetPluginSettingByIdQuery) error {
+		return models.ErrPluginSettingNotFound
+	})
+
+	newService := func(t *testing.T, folders *fakeFolderService) *Service {
+		origNewFolderService := dashboards.NewFolderService
+		t.Cleanup(func() {
+			dashboards.NewFolderService = origNewFolderService
+		})
+		dashboards.NewFolderService = func(orgID int64, user *models.SignedInUser, _ dboards.Store) dashboards.FolderService {
+			folders.users = append(folders.users, user)
+			return folders
+		}
+
+		s := &Service{Cfg: setting.NewCfg(), logger: log.New("test")}
+		s.Cfg.PluginSettings = setting.PluginSettings{"test-app": {dashboardFolderSetting: "Kubernetes"}}
+		return s
+	}
+	user := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_EDITOR}
+
+	t.Run("Should return the existing folder", func(t *testing.T) {
+		folders := &fakeFolderService{folders: map[string]*models.Folder{"Kubernetes": {Id: 3, Title: "Kubernetes"}}}
+		s := newService(t, folders)
+
+		for _, create := range []bool{false, true} {
+			folderID, err := s.DefaultDashboardFolder(context.Background(), user, "test-app", create)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), folderID)
+		}
+		assert.Empty(t, folders.created)
+		assert.Equal(t, []*models.SignedInUser{user, user}, folders.users)
+	})
+
+	t.Run("Should create the missing folder", func(t *testing.T) {
+		folders := &fakeFolderService{folders: map[string]*models.Folder{}}
+		s := newService(t, folders)
+
+		folderID, err := s.DefaultDashboardFolder(context.Background(), user, "test-app", true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), folderID)
+		assert.Equal(t, []string{"Kubernetes"}, folders.created)
+	})
+
+	t.Run("Should return the General folder for a missing folder without creating it", func(t *testing.T) {
+		folders := &fakeFolderService{folders: map[string]*models.Folder{}}
+		s := newService(t, folders)
+
+		folderID, err := s.DefaultDashboardFolder(context.Background(), user, "test-app", false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), folderID)
+		assert.Empty(t, folders.created)
+	})
+
+	t.Run("Should return the General folder without default folder", func(t *testing.T) {
+		folders := &fakeFolderService{folders: map[string]*models.Folder{}}
+		s := newService(t, folders)
+
+		folderID, err := s.DefaultDashboardFolder(context.Background(), user, "other-app", true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), folderID)
+		assert.Empty(t, folders.users)
+	})
+
+	t.Run("Should return the errors of the folder service", func(t *testing.T) {
+		folders := &fakeFolderService{err: models.ErrFolderAccessDenied}
+		s := newService(t, folders)
+
+		_, err := s.DefaultDashboardFolder(context.Background(), user, "test-app", true)
+		require.ErrorIs(t, err, models.ErrFolderAccessDenied)
+	})
+}
+
+// fakeFolderService returns the folders by title, and creates the missing ones.
+type fakeFolderService struct {
+	dashboards.FolderService
+
+	folders map[string]*models.Folder
+	err     error
+	users   []*models.SignedInUser
+	created []string
+}
+
+func (s *fakeFolderService) GetFolderByTitle(_ context.Context, title string) (*models.Folder, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	folder, ok := s.folders[title]
+	if !ok {
+		return nil, models.ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+func (s *fakeFolderService) CreateFolder(_ context.Context, title, _ string) (*models.Folder, error) {
+	s.created = append(s.created, title)
+	folder := &models.Folder{Id: int64(len(s.created)), Title: title}
+	s.folders[title] = folder
+	return folder, nil
+}
@@ -0,0 +1,63 @@
+package plugindashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// pinnedDashboardsNamespace is the namespace of the pinned plugin dashboards in the key-value store. The keys
+// are the plugin ID and the dashboard ID separated by a slash.
+const pinnedDashboardsNamespace = "plugindashboards.pinned"
+
+// ErrNotPluginDashboard is returned when pinning a dashboard that wasn't imported from the plugin.
+var ErrNotPluginDashboard = errors.New("dashboard was not imported from the plugin")
+
+// PinDashboard excludes a dashboard imported from the plugin from the automatic updates and removals of the
+// plugin dashboards, e.g. because it has been customized.
+func (s *Service) PinDashboard(ctx context.Context, orgID int64, pluginID string, dashboardID int64) error {
+	query := models.GetDashboardQuery{OrgId: orgID, Id: dashboardID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return err
+	}
+	if query.Result.PluginId != pluginID {
+		return ErrNotPluginDashboard
+	}
+
+	s.logger.Info("Pinning plugin dashboard", "pluginId", pluginID, "dashboardId", dashboardID)
+	return s.kvStore.Set(ctx, orgID, pinnedDashboardsNamespace, pinnedDashboardKey(pluginID, dashboardID), "true")
+}
+
+// UnpinDashboard lets the automatic updates of the plugin dashboards update the dashboard again.
+func (s *Service) UnpinDashboard(ctx context.Context, orgID int64, pluginID string, dashboardID int64) error {
+	s.logger.Info("Unpinning plugin dashboard", "pluginId", pluginID, "dashboardId", dashboardID)
+	return s.kvStore.Del(ctx, orgID, pinnedDashboardsNamespace, pinnedDashboardKey(pluginID, dashboardID))
+}
+
+// PinnedDashboards returns the IDs of the pinned dashboards of the plugin.
+func (s *Service) PinnedDashboards(ctx context.Context, orgID int64, pluginID string) (map[int64]bool, error) {
+	keys, err := s.kvStore.Keys(ctx, orgID, pinnedDashboardsNamespace, pluginID+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[int64]bool, len(keys))
+	for _, key := range keys {
+		dashboardID, err := strconv.ParseInt(strings.TrimPrefix(key.Key, pluginID+"/"), 10, 64)
+		if err != nil {
+			s.logger.Warn("Ignoring invalid pinned plugin dashboard", "key", key.Key)
+			continue
+		}
+		pinned[dashboardID] = true
+	}
+	return pinned, nil
+}
+
+func pinnedDashboardKey(pluginID string, dashboardID int64) string {
+	return fmt.Sprintf("%s/%d", pluginID, dashboardID)
+}
@@ -1,32 +1,42 @@
 package plugindashboards
 
 import (
+	"context"
+
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 )
 
-func ProvideService(dataService *tsdb.Service, pluginManager plugins.Manager, sqlStore *sqlstore.SQLStore) *Service {
+func ProvideService(cfg *setting.Cfg, dataService *tsdb.Service, pluginManager plugins.Manager,
+	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore) *Service {
 	s := &Service{
+		Cfg:           cfg,
 		DataService:   dataService,
 		PluginManager: pluginManager,
 		SQLStore:      sqlStore,
+		kvStore:       kvStore,
 		logger:        log.New("plugindashboards"),
 	}
 	bus.AddEventListener(s.handlePluginStateChanged)
+	bus.AddEventListener(s.handlePluginUpgraded)
 	s.updateAppDashboards()
 	return s
 }
 
 type Service struct {
+	Cfg           *setting.Cfg
 	DataService   *tsdb.Service
 	PluginManager plugins.Manager
 	SQLStore      *sqlstore.SQLStore
 
-	logger log.Logger
+	kvStore kvstore.KVStore
+	logger  log.Logger
 }
 
 func (s *Service) updateAppDashboards() {
@@ -62,8 +72,20 @@ func (s *Service) syncPluginDashboards(pluginDef *plugins.PluginBase, orgID int6
 		return
 	}
 
+	pinned, err := s.PinnedDashboards(context.Background(), orgID, pluginDef.Id)
+	if err != nil {
+		s.logger.Error("Failed to get pinned plugin dashboards", "pluginId", pluginDef.Id, "error", err)
+		return
+	}
+
 	// Update dashboards with updated revisions
 	for _, dash := range dashboards {
+		// leave the dashboards pinned by the users as they are
+		if dash.DashboardId != 0 && pinned[dash.DashboardId] {
+			s.logger.Debug("Skipping pinned plugin dashboard", "pluginId", pluginDef.Id, "dashboardId", dash.DashboardId)
+			continue
+		}
+
 		// remove removed ones
 		if dash.Removed {
 			s.logger.Info("Deleting plugin dashboard", "pluginId", pluginDef.Id, "dashboard", dash.Slug)
@@ -105,6 +127,34 @@ func (s *Service) syncPluginDashboards(pluginDef *plugins.PluginBase, orgID int6
 	}
 }
 
+// handlePluginUpgraded updates the dashboards of the upgraded plugin in the organizations where it's enabled.
+func (s *Service) handlePluginUpgraded(event *models.PluginUpgradedEvent) error {
+	pluginDef := s.PluginManager.GetPlugin(event.PluginId)
+	if pluginDef == nil {
+		return nil
+	}
+	s.logger.Info("Plugin upgraded", "pluginId", event.PluginId, "previousVersion", event.PreviousVersion,
+		"version", event.Version)
+
+	pluginSettings, err := s.SQLStore.GetPluginSettings(0)
+	if err != nil {
+		return err
+	}
+
+	for _, pluginSetting := range pluginSettings {
+		if pluginSetting.PluginId != event.PluginId || !pluginSetting.Enabled {
+			continue
+		}
+		if pluginSetting.PluginVersion != pluginDef.Info.Version {
+			s.syncPluginDashboards(pluginDef, pluginSetting.OrgId)
+		}
+	}
+
+	return nil
+}
+
+// handlePluginStateChanged syncs the dashboards of the plugin when it's enabled, and deletes them when it's disabled,
+// except for the pinned ones.
 func (s *Service) handlePluginStateChanged(event *models.PluginStateChangedEvent) error {
 	s.logger.Info("Plugin state changed", "pluginId", event.PluginId, "enabled", event.Enabled)
 
@@ -116,7 +166,16 @@ func (s *Service) handlePluginStateChanged(event *models.PluginStateChangedEvent
 			return err
 		}
 
+		pinned, err := s.PinnedDashboards(context.Background(), event.OrgId, event.PluginId)
+		if err != nil {
+			return err
+		}
+
 		for _, dash := range query.Result {
+			if pinned[dash.Id] {
+				s.logger.Debug("Keeping pinned plugin dashboard", "pluginId", event.PluginId, "dashboardId", dash.Id)
+				continue
+			}
 			s.logger.Info("Deleting plugin dashboard", "pluginId", event.PluginId, "dashboard", dash.Slug)
 			deleteCmd := models.DeleteDashboardCommand{OrgId: dash.OrgId, Id: dash.Id}
 			if err := bus.Dispatch(&deleteCmd); err != nil {
@@ -135,8 +194,17 @@ func (s *Service) autoUpdateAppDashboard(pluginDashInfo *plugins.PluginDashboard
 	}
 	s.logger.Info("Auto updating App dashboard", "dashboard", dash.Title, "newRev",
 		pluginDashInfo.Revision, "oldRev", pluginDashInfo.ImportedRevision)
-	user := &models.SignedInUser{UserId: 0, OrgRole: models.ROLE_ADMIN}
-	_, _, err = s.PluginManager.ImportDashboard(pluginDashInfo.PluginId, pluginDashInfo.Path, orgID, 0, dash.Data, true,
-		nil, user, s.DataService)
+
+	// updated dashboards stay in their folder, new ones are imported in the default folder of the plugin
+	folderID := pluginDashInfo.FolderId
+	if !pluginDashInfo.Imported {
+		if folderID, err = s.DefaultDashboardFolder(context.Background(), serviceUser(orgID), pluginDashInfo.PluginId, true); err != nil {
+			return err
+		}
+	}
+
+	user := serviceUser(orgID)
+	_, _, err = s.PluginManager.ImportDashboard(pluginDashInfo.PluginId, pluginDashInfo.Path, orgID, folderID, dash.Data,
+		true, nil, user, s.DataService)
 	return err
 }
@@ -0,0 +1,175 @@
+package plugindashboards
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_handlePluginUpgraded(t *testing.T) {
+	t.Run("Should sync the dashboards of the upgraded plugin, except for the pinned ones", func(t *testing.T) {
+		s, pm := setupTestService(t)
+		deleted := handleDashboardCommands(t)
+		var updatedVersion string
+		bus.AddHandlerCtx("test", func(_ context.Context, cmd *models.UpdatePluginSettingVersionCmd) error {
+			updatedVersion = cmd.PluginVersion
+			return nil
+		})
+
+		pm.dashboards = []*plugins.PluginDashboardInfoDTO{
+			{PluginId: "test-app", DashboardId: 1, Imported: true, Revision: 2, ImportedRevision: 1, Path: "updated.json"},
+			{PluginId: "test-app", DashboardId: 2, Imported: true, Revision: 2, ImportedRevision: 1, Path: "pinned.json"},
+			{PluginId: "test-app", DashboardId: 3, Imported: true, Removed: true, Path: "removed.json"},
+			{PluginId: "test-app", DashboardId: 4, Imported: true, Removed: true, Path: "pinned-removed.json"},
+		}
+		require.NoError(t, s.PinDashboard(context.Background(), 1, "test-app", 2))
+		require.NoError(t, s.PinDashboard(context.Background(), 1, "test-app", 4))
+
+		err := s.handlePluginUpgraded(&models.PluginUpgradedEvent{PluginId: "test-app", PreviousVersion: "1.0.0",
+			Version: "2.0.0"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"updated.json"}, pm.imported)
+		assert.Equal(t, []int64{3}, *deleted)
+		assert.Equal(t, "2.0.0", updatedVersion)
+		// the plugin isn't enabled in the second organization
+		assert.Equal(t, []int64{1}, pm.dashboardsOrgIDs)
+	})
+
+	t.Run("Should ignore plugins which aren't installed", func(t *testing.T) {
+		s, pm := setupTestService(t)
+
+		err := s.handlePluginUpgraded(&models.PluginUpgradedEvent{PluginId: "other-app", Version: "2.0.0"})
+		require.NoError(t, err)
+		assert.Empty(t, pm.dashboardsOrgIDs)
+	})
+}
+
+func TestService_handlePluginStateChanged(t *testing.T) {
+	t.Run("Should delete the dashboards of the disabled plugin, except for the pinned ones", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		deleted := handleDashboardCommands(t)
+		bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetDashboardsByPluginIdQuery) error {
+			query.Result = []*models.Dashboard{
+				{Id: 1, OrgId: 1, PluginId: "test-app"},
+				{Id: 2, OrgId: 1, PluginId: "test-app"},
+			}
+			return nil
+		})
+		require.NoError(t, s.PinDashboard(context.Background(), 1, "test-app", 2))
+
+		err := s.handlePluginStateChanged(&models.PluginStateChangedEvent{PluginId: "test-app", OrgId: 1, Enabled: false})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, *deleted)
+	})
+}
+
+func TestService_PinDashboard(t *testing.T) {
+	s, _ := setupTestService(t)
+	handleDashboardCommands(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.PinDashboard(ctx, 1, "test-app", 1))
+	pinned, err := s.PinnedDashboards(ctx, 1, "test-app")
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{1: true}, pinned)
+
+	// the pins are scoped to the plugin and the organization
+	pinned, err = s.PinnedDashboards(ctx, 2, "test-app")
+	require.NoError(t, err)
+	assert.Empty(t, pinned)
+
+	err = s.PinDashboard(ctx, 1, "other-app", 1)
+	require.ErrorIs(t, err, ErrNotPluginDashboard)
+
+	require.NoError(t, s.UnpinDashboard(ctx, 1, "test-app", 1))
+	pinned, err = s.PinnedDashboards(ctx, 1, "test-app")
+	require.NoError(t, err)
+	assert.Empty(t, pinned)
+}
+
+// setupTestService returns a service with the test-app plugin at version 2.0.0, enabled at version 1.0.0 in the
+// first organization and disabled in the second one.
+func setupTestService(t *testing.T) (*Service, *fakePluginManager) {
+	t.Helper()
+	t.Cleanup(bus.ClearBusHandlers)
+
+	sqlStore := sqlstore.InitTestDB(t)
+	for _, cmd := range []*models.UpdatePluginSettingCmd{
+		{PluginId: "test-app", OrgId: 1, Enabled: true, PluginVersion: "1.0.0"},
+		{PluginId: "test-app", OrgId: 2, Enabled: false, PluginVersion: "1.0.0"},
+	} {
+		require.NoError(t, sqlStore.UpdatePluginSetting(cmd))
+	}
+
+	pm := &fakePluginManager{plugin: &plugins.PluginBase{Id: "test-app", Info: plugins.PluginInfo{Version: "2.0.0"}}}
+	s := &Service{
+		SQLStore:      sqlStore,
+		PluginManager: pm,
+		kvStore:       kvstore.ProvideService(sqlStore),
+		logger:        log.New("test"),
+	}
+	return s, pm
+}
+
+// handleDashboardCommands handles the dashboard queries and commands of the service, all the dashboards being
+// imported from test-app, and returns the IDs of the deleted dashboards.
+func handleDashboardCommands(t *testing.T) *[]int64 {
+	t.Helper()
+
+	var deleted []int64
+	bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetDashboardQuery) error {
+		query.Result = &models.Dashboard{Id: query.Id, OrgId: query.OrgId, PluginId: "test-app"}
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(_ context.Context, cmd *models.DeleteDashboardCommand) error {
+		deleted = append(deleted, cmd.Id)
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(_ context.Context, query *models.GetPluginSettingByIdQuery) error {
+		query.Result = &models.PluginSetting{PluginId: query.PluginId, OrgId: query.OrgId, Enabled: true}
+		return nil
+	})
+	return &deleted
+}
+
+type fakePluginManager struct {
+	plugins.Manager
+
+	plugin           *plugins.PluginBase
+	dashboards       []*plugins.PluginDashboardInfoDTO
+	dashboardsOrgIDs []int64
+	imported         []string
+}
+
+func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
+	if pm.plugin != nil && pm.plugin.Id == id {
+		return pm.plugin
+	}
+	return nil
+}
+
+func (pm *fakePluginManager) GetPluginDashboards(orgID int64, _ string) ([]*plugins.PluginDashboardInfoDTO, error) {
+	pm.dashboardsOrgIDs = append(pm.dashboardsOrgIDs, orgID)
+	return pm.dashboards, nil
+}
+
+func (pm *fakePluginManager) LoadPluginDashboard(_, path string) (*models.Dashboard, error) {
+	return models.NewDashboard(path), nil
+}
+
+func (pm *fakePluginManager) ImportDashboard(pluginID, path string, orgID, folderID int64, _ *simplejson.Json,
+	_ bool, _ []plugins.ImportDashboardInput, _ *models.SignedInUser,
+	_ plugins.DataRequestHandler) (plugins.PluginDashboardInfoDTO, *models.Dashboard, error) {
+	pm.imported = append(pm.imported, path)
+	return plugins.PluginDashboardInfoDTO{PluginId: pluginID, Path: path}, nil, nil
+}
@@ -0,0 +1,104 @@
+package plugindashboards
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// UninstalledDashboardsAction is what's done with the dashboards imported from a plugin when it's uninstalled.
+type UninstalledDashboardsAction string
+
+const (
+	// KeepDashboards leaves the dashboards as they are.
+	KeepDashboards UninstalledDashboardsAction = "keep"
+	// DeleteDashboards deletes the dashboards, except for the pinned ones which are archived.
+	DeleteDashboards UninstalledDashboardsAction = "delete"
+	// ArchiveDashboards moves the dashboards to the archive folder of the organization.
+	ArchiveDashboards UninstalledDashboardsAction = "archive"
+)
+
+// ArchiveFolderTitle is the title of the folder the dashboards of uninstalled plugins are archived in.
+const ArchiveFolderTitle = "Archived plugin dashboards"
+
+// ParseUninstalledDashboardsAction parses the action, which defaults to KeepDashboards.
+func ParseUninstalledDashboardsAction(action string) (UninstalledDashboardsAction, error) {
+	switch UninstalledDashboardsAction(action) {
+	case "", KeepDashboards:
+		return KeepDashboards, nil
+	case DeleteDashboards, ArchiveDashboards:
+		return UninstalledDashboardsAction(action), nil
+	default:
+		return "", fmt.Errorf("invalid action %q for the dashboards of the plugin", action)
+	}
+}
+
+// RemoveUninstalledPluginDashboards deletes or archives the dashboards imported from the uninstalled plugin
+// in all organizations, as they would fail with missing data source or panel errors. Archived dashboards are
+// unlinked from the plugin.
+func (s *Service) RemoveUninstalledPluginDashboards(ctx context.Context, pluginID string, action UninstalledDashboardsAction) error {
+	if action == KeepDashboards {
+		return nil
+	}
+
+	orgsQuery := models.SearchOrgsQuery{}
+	if err := bus.Dispatch(&orgsQuery); err != nil {
+		return err
+	}
+
+	for _, org := range orgsQuery.Result {
+		if err := s.removeOrgPluginDashboards(ctx, org.Id, pluginID, action); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) removeOrgPluginDashboards(ctx context.Context, orgID int64, pluginID string, action UninstalledDashboardsAction) error {
+	query := models.GetDashboardsByPluginIdQuery{OrgId: orgID, PluginId: pluginID}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return err
+	}
+	if len(query.Result) == 0 {
+		return nil
+	}
+
+	pinned, err := s.PinnedDashboards(ctx, orgID, pluginID)
+	if err != nil {
+		return err
+	}
+
+	var archiveFolderID int64
+	for _, dash := range query.Result {
+		// the pinned dashboards have been customized by the users, so they're archived rather than deleted
+		if action == DeleteDashboards && !pinned[dash.Id] {
+			s.logger.Info("Deleting dashboard of uninstalled plugin", "pluginId", pluginID, "orgId", orgID, "dashboard", dash.Slug)
+			deleteCmd := models.DeleteDashboardCommand{OrgId: orgID, Id: dash.Id}
+			if err := bus.DispatchCtx(ctx, &deleteCmd); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if archiveFolderID == 0 {
+			if archiveFolderID, err = s.ensureFolder(ctx, orgID, ArchiveFolderTitle); err != nil {
+				return err
+			}
+		}
+		s.logger.Info("Archiving dashboard of uninstalled plugin", "pluginId", pluginID, "orgId", orgID, "dashboard", dash.Slug)
+		if err := s.SQLStore.ArchivePluginDashboard(ctx, orgID, dash.Id, archiveFolderID); err != nil {
+			return err
+		}
+	}
+
+	for dashboardID := range pinned {
+		if err := s.UnpinDashboard(ctx, orgID, pluginID, dashboardID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
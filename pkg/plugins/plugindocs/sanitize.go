@@ -0,0 +1,104 @@
+package plugindocs
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var allowedTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true, "code": true, "dd": true, "del": true,
+	"details": true, "div": true, "dl": true, "dt": true, "em": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "hr": true, "i": true, "img": true, "kbd": true, "li": true, "ol": true, "p": true,
+	"pre": true, "s": true, "span": true, "strong": true, "sub": true, "summary": true, "sup": true, "table": true,
+	"tbody": true, "td": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+var allowedAttrs = map[string]bool{
+	"alt": true, "align": true, "class": true, "colspan": true, "height": true, "href": true, "id": true,
+	"rowspan": true, "src": true, "title": true, "width": true,
+}
+
+// droppedTags are the tags removed with their content, rather than only the tags.
+var droppedTags = map[string]bool{
+	"embed": true, "iframe": true, "math": true, "noscript": true, "object": true, "script": true, "style": true,
+	"svg": true, "template": true, "textarea": true, "title": true,
+}
+
+var allowedSchemes = map[string]bool{"": true, "http": true, "https": true, "mailto": true}
+
+// Sanitize returns the HTML with only the allowed tags and attributes, and without links to other URL schemes than
+// http, https and mailto. The relative images are resolved against baseURL if not empty.
+func Sanitize(content string, baseURL string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(content))
+	dropped := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return ""
+			}
+			return b.String()
+		}
+
+		token := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if tt == html.StartTagToken {
+					dropped++
+				}
+				continue
+			}
+			if dropped > 0 || !allowedTags[token.Data] {
+				continue
+			}
+			token.Attr = sanitizeAttrs(token.Attr, token.Data, baseURL)
+			b.WriteString(token.String())
+		case html.EndTagToken:
+			if droppedTags[token.Data] {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if dropped > 0 || !allowedTags[token.Data] {
+				continue
+			}
+			b.WriteString(token.String())
+		case html.TextToken:
+			if dropped == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+func sanitizeAttrs(attrs []html.Attribute, tag string, baseURL string) []html.Attribute {
+	sanitized := make([]html.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !allowedAttrs[attr.Key] {
+			continue
+		}
+		if attr.Key == "href" || attr.Key == "src" {
+			u, err := url.Parse(strings.TrimSpace(attr.Val))
+			if err != nil || !allowedSchemes[strings.ToLower(u.Scheme)] {
+				continue
+			}
+			if tag == "img" && attr.Key == "src" && baseURL != "" && !u.IsAbs() && u.Host == "" && !strings.HasPrefix(u.Path, "/") {
+				u.Path = path.Join(baseURL, u.Path)
+			}
+			attr.Val = u.String()
+		}
+		if tag == "a" && attr.Key == "href" {
+			sanitized = append(sanitized, html.Attribute{Key: "rel", Val: "noopener noreferrer"})
+		}
+		sanitized = append(sanitized, attr)
+	}
+	return sanitized
+}
@@ -0,0 +1,64 @@
+package plugindocs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		baseURL  string
+		expected string
+	}{
+		{
+			desc:     "keeps allowed tags",
+			content:  `<h1 id="title">Title</h1><p>Some <strong>bold</strong> &amp; <code>code</code></p>`,
+			expected: `<h1 id="title">Title</h1><p>Some <strong>bold</strong> &amp; <code>code</code></p>`,
+		},
+		{
+			desc:     "removes scripts and styles with their content",
+			content:  `<p>before</p><script>alert("x")</script><style>p { color: red }</style><p>after</p>`,
+			expected: `<p>before</p><p>after</p>`,
+		},
+		{
+			desc:     "removes unknown tags but keeps their text",
+			content:  `<form action="/login"><p>text</p></form>`,
+			expected: `<p>text</p>`,
+		},
+		{
+			desc:     "removes event handlers and styles",
+			content:  `<p onclick="alert(1)" style="color: red">text</p><img src="https://example.com/a.png" onerror="alert(1)">`,
+			expected: `<p>text</p><img src="https://example.com/a.png">`,
+		},
+		{
+			desc:     "removes javascript links",
+			content:  `<a href="javascript:alert(1)">link</a><a href="JaVaScRiPt&#58;alert(1)">link</a>`,
+			expected: `<a>link</a><a>link</a>`,
+		},
+		{
+			desc:     "keeps http links",
+			content:  `<a href="https://grafana.com/docs" target="_blank" rel="opener">docs</a>`,
+			expected: `<a rel="noopener noreferrer" href="https://grafana.com/docs">docs</a>`,
+		},
+		{
+			desc:     "resolves relative images against the base URL",
+			content:  `<img src="img/logo.png" alt="logo"><img src="/public/img/logo.png"><img src="https://example.com/logo.png">`,
+			baseURL:  "public/plugins/test-app",
+			expected: `<img src="public/plugins/test-app/img/logo.png" alt="logo"><img src="/public/img/logo.png"><img src="https://example.com/logo.png">`,
+		},
+		{
+			desc:     "escapes text",
+			content:  `<p>1 &lt; 2</p>`,
+			expected: `<p>1 &lt; 2</p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, Sanitize(tc.content, tc.baseURL))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package plugindocs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/russross/blackfriday/v2"
+)
+
+const (
+	Readme    = "readme"
+	Changelog = "changelog"
+
+	SourceInstalled = "installed"
+	SourceCatalog   = "catalog"
+
+	catalogCacheExpiration = time.Hour
+)
+
+var (
+	ErrUnknownDoc  = errors.New("unknown plugin doc, expected readme or changelog")
+	ErrDocNotFound = errors.New("plugin doc not found")
+)
+
+// Doc is the README or the changelog of a plugin, as sanitized HTML.
+type Doc struct {
+	PluginID string `json:"pluginId"`
+	Name     string `json:"name"`
+	// Source is "installed" if the doc comes from the directory of the installed plugin, and "catalog" if it comes
+	// from the plugin catalog.
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	Content string `json:"content"`
+}
+
+func ProvideService(cfg *setting.Cfg, pluginManager plugins.Manager) *Service {
+	return &Service{
+		Cfg:           cfg,
+		PluginManager: pluginManager,
+		cache:         localcache.New(catalogCacheExpiration, 2*catalogCacheExpiration),
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		},
+		logger: log.New("plugindocs"),
+	}
+}
+
+// Service serves the README and changelog of plugins, from the directory of the plugin when it's installed, so that
+// they're available offline, or else from the plugin catalog. The docs are sanitized and cached.
+type Service struct {
+	Cfg           *setting.Cfg
+	PluginManager plugins.Manager
+
+	cache  *localcache.CacheService
+	client *http.Client
+	logger log.Logger
+}
+
+// GetDoc returns the README or the changelog of the plugin.
+func (s *Service) GetDoc(ctx context.Context, pluginID, name string) (*Doc, error) {
+	if name != Readme && name != Changelog {
+		return nil, ErrUnknownDoc
+	}
+
+	if p := s.PluginManager.GetPlugin(pluginID); p != nil {
+		doc, err := s.getInstalledDoc(p, name)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			return doc, nil
+		}
+	}
+
+	return s.getCatalogDoc(ctx, pluginID, name)
+}
+
+func (s *Service) getInstalledDoc(p *plugins.PluginBase, name string) (*Doc, error) {
+	// the version is part of the key so that the docs of upgraded plugins aren't stale
+	key := fmt.Sprintf("plugindocs-installed-%s-%s-%s", p.Id, p.Info.Version, name)
+	if cached, found := s.cache.Get(key); found {
+		return cached.(*Doc), nil
+	}
+
+	markdown, err := s.PluginManager.GetPluginMarkdown(p.Id, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(markdown) == 0 {
+		return nil, nil
+	}
+
+	doc := &Doc{
+		PluginID: p.Id,
+		Name:     name,
+		Source:   SourceInstalled,
+		Version:  p.Info.Version,
+		Content:  Sanitize(string(blackfriday.Run(markdown)), p.BaseUrl),
+	}
+	s.cache.Set(key, doc, 0)
+	return doc, nil
+}
+
+type catalogPlugin struct {
+	Version   string `json:"version"`
+	Readme    string `json:"readme"`
+	Changelog string `json:"changelog"`
+}
+
+func (s *Service) getCatalogDoc(ctx context.Context, pluginID, name string) (*Doc, error) {
+	key := fmt.Sprintf("plugindocs-catalog-%s-%s", pluginID, name)
+	if cached, found := s.cache.Get(key); found {
+		return cached.(*Doc), nil
+	}
+
+	u, err := url.Parse(s.Cfg.GrafanaComURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "api", "plugins", pluginID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "grafana "+s.Cfg.BuildVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrDocNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get plugin %s from the catalog: status %d", pluginID, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var plugin catalogPlugin
+	if err := json.Unmarshal(body, &plugin); err != nil {
+		return nil, err
+	}
+
+	// the catalog renders the markdown of the docs as HTML
+	content := plugin.Readme
+	if name == Changelog {
+		content = plugin.Changelog
+	}
+	if content == "" {
+		return nil, ErrDocNotFound
+	}
+
+	doc := &Doc{
+		PluginID: pluginID,
+		Name:     name,
+		Source:   SourceCatalog,
+		Version:  plugin.Version,
+		Content:  Sanitize(content, ""),
+	}
+	s.cache.Set(key, doc, catalogCacheExpiration)
+	return doc, nil
+}
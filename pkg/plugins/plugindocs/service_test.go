@@ -0,0 +1,87 @@
+package plugindocs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePluginManager struct {
+	plugins.Manager
+
+	plugins  map[string]*plugins.PluginBase
+	markdown map[string][]byte
+}
+
+func (pm *fakePluginManager) GetPlugin(id string) *plugins.PluginBase {
+	return pm.plugins[id]
+}
+
+func (pm *fakePluginManager) GetPluginMarkdown(pluginID string, name string) ([]byte, error) {
+	return pm.markdown[pluginID+"/"+name], nil
+}
+
+func TestService_GetDoc(t *testing.T) {
+	catalogRequests := 0
+	catalog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		catalogRequests++
+		if r.URL.Path != "/api/plugins/remote-app" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"2.0.0","readme":"<h1>Remote</h1><script>alert(1)</script>","changelog":""}`))
+	}))
+	t.Cleanup(catalog.Close)
+
+	cfg := setting.NewCfg()
+	cfg.GrafanaComURL = catalog.URL
+	pm := &fakePluginManager{
+		plugins: map[string]*plugins.PluginBase{
+			"local-app": {Id: "local-app", Info: plugins.PluginInfo{Version: "1.0.0"}, BaseUrl: "public/plugins/local-app"},
+		},
+		markdown: map[string][]byte{
+			"local-app/readme": []byte("# Local\n\n![logo](img/logo.png)\n"),
+		},
+	}
+	s := ProvideService(cfg, pm)
+
+	t.Run("Unknown doc", func(t *testing.T) {
+		_, err := s.GetDoc(context.Background(), "local-app", "license")
+		require.Equal(t, ErrUnknownDoc, err)
+	})
+
+	t.Run("Installed plugin doc", func(t *testing.T) {
+		doc, err := s.GetDoc(context.Background(), "local-app", Readme)
+		require.NoError(t, err)
+		require.Equal(t, SourceInstalled, doc.Source)
+		require.Equal(t, "1.0.0", doc.Version)
+		require.Contains(t, doc.Content, "<h1>Local</h1>")
+		require.Contains(t, doc.Content, `src="public/plugins/local-app/img/logo.png"`)
+		require.Equal(t, 0, catalogRequests)
+	})
+
+	t.Run("Catalog doc is sanitized and cached", func(t *testing.T) {
+		doc, err := s.GetDoc(context.Background(), "remote-app", Readme)
+		require.NoError(t, err)
+		require.Equal(t, SourceCatalog, doc.Source)
+		require.Equal(t, "2.0.0", doc.Version)
+		require.Equal(t, "<h1>Remote</h1>", doc.Content)
+
+		_, err = s.GetDoc(context.Background(), "remote-app", Readme)
+		require.NoError(t, err)
+		require.Equal(t, 1, catalogRequests)
+	})
+
+	t.Run("Missing doc", func(t *testing.T) {
+		_, err := s.GetDoc(context.Background(), "remote-app", Changelog)
+		require.Equal(t, ErrDocNotFound, err)
+
+		_, err = s.GetDoc(context.Background(), "unknown-app", Readme)
+		require.Equal(t, ErrDocNotFound, err)
+	})
+}
@@ -0,0 +1,90 @@
+package plugins
+
+import "sort"
+
+// SortPreloadPlugins returns the provided plugins in the order they should be preloaded by the frontend.
+// A plugin is always loaded after the plugins it depends on, either through the dependencies in its
+// plugin.json or by being included in an app. Plugins that don't depend on each other are ordered by
+// descending preload priority and then by ID, so that the order is deterministic. Duplicates are removed.
+func SortPreloadPlugins(plugins []*PluginBase) []*PluginBase {
+	byID := make(map[string]*PluginBase, len(plugins))
+	ids := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		if _, exists := byID[p.Id]; exists {
+			continue
+		}
+		byID[p.Id] = p
+		ids = append(ids, p.Id)
+	}
+
+	// dependents maps a plugin ID to the IDs of the plugins that have to be loaded after it
+	dependents := make(map[string][]string)
+	pending := make(map[string]int, len(ids))
+	for _, id := range ids {
+		for _, dep := range preloadDependencies(byID[id]) {
+			if _, exists := byID[dep]; !exists || dep == id {
+				continue
+			}
+			dependents[dep] = append(dependents[dep], id)
+			pending[id]++
+		}
+	}
+
+	less := func(a, b string) bool {
+		if byID[a].PreloadPriority != byID[b].PreloadPriority {
+			return byID[a].PreloadPriority > byID[b].PreloadPriority
+		}
+		return a < b
+	}
+
+	ready := []string{}
+	for _, id := range ids {
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	sorted := make([]*PluginBase, 0, len(ids))
+	loaded := make(map[string]bool, len(ids))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		id := ready[0]
+		ready = ready[1:]
+
+		sorted = append(sorted, byID[id])
+		loaded[id] = true
+		for _, dependent := range dependents[id] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	// plugins with circular dependencies are loaded last
+	if len(sorted) < len(ids) {
+		remaining := make([]string, 0, len(ids)-len(sorted))
+		for _, id := range ids {
+			if !loaded[id] {
+				remaining = append(remaining, id)
+			}
+		}
+		sort.Slice(remaining, func(i, j int) bool { return less(remaining[i], remaining[j]) })
+		for _, id := range remaining {
+			sorted = append(sorted, byID[id])
+		}
+	}
+
+	return sorted
+}
+
+func preloadDependencies(p *PluginBase) []string {
+	deps := make([]string, 0, len(p.Dependencies.Plugins)+1)
+	if p.IncludedInAppId != "" {
+		deps = append(deps, p.IncludedInAppId)
+	}
+	for _, dep := range p.Dependencies.Plugins {
+		deps = append(deps, dep.Id)
+	}
+	return deps
+}
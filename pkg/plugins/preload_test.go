@@ -0,0 +1,54 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortPreloadPlugins(t *testing.T) {
+	ids := func(plugins []*PluginBase) []string {
+		result := make([]string, 0, len(plugins))
+		for _, p := range plugins {
+			result = append(result, p.Id)
+		}
+		return result
+	}
+
+	t.Run("Should order by priority and ID", func(t *testing.T) {
+		sorted := SortPreloadPlugins([]*PluginBase{
+			{Id: "b"},
+			{Id: "a"},
+			{Id: "c", PreloadPriority: 10},
+			{Id: "a"},
+		})
+		require.Equal(t, []string{"c", "a", "b"}, ids(sorted))
+	})
+
+	t.Run("Should load apps before the plugins they include", func(t *testing.T) {
+		sorted := SortPreloadPlugins([]*PluginBase{
+			{Id: "app-panel", IncludedInAppId: "app", PreloadPriority: 10},
+			{Id: "app"},
+			{Id: "other"},
+		})
+		require.Equal(t, []string{"app", "app-panel", "other"}, ids(sorted))
+	})
+
+	t.Run("Should load dependencies first", func(t *testing.T) {
+		sorted := SortPreloadPlugins([]*PluginBase{
+			{Id: "a", Dependencies: PluginDependencies{Plugins: []PluginDependencyItem{{Id: "b"}, {Id: "missing"}}}},
+			{Id: "b", Dependencies: PluginDependencies{Plugins: []PluginDependencyItem{{Id: "c"}}}},
+			{Id: "c"},
+		})
+		require.Equal(t, []string{"c", "b", "a"}, ids(sorted))
+	})
+
+	t.Run("Should load plugins with circular dependencies last", func(t *testing.T) {
+		sorted := SortPreloadPlugins([]*PluginBase{
+			{Id: "a", Dependencies: PluginDependencies{Plugins: []PluginDependencyItem{{Id: "b"}}}},
+			{Id: "b", Dependencies: PluginDependencies{Plugins: []PluginDependencyItem{{Id: "a"}}}},
+			{Id: "c"},
+		})
+		require.Equal(t, []string{"c", "a", "b"}, ids(sorted))
+	})
+}
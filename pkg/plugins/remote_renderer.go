@@ -0,0 +1,226 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"google.golang.org/grpc"
+)
+
+// RemoteRendererPluginID is the ID of the renderer plugin rendering via a remote HTTP rendering service.
+const RemoteRendererPluginID = "grafana-image-renderer"
+
+var remoteRendererTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	Dial: (&net.Dialer{
+		Timeout: 30 * time.Second,
+	}).Dial,
+	TLSHandshakeTimeout: 5 * time.Second,
+}
+
+var remoteRendererClient = &http.Client{
+	Transport: remoteRendererTransport,
+}
+
+// NewRemoteRendererPlugin returns a renderer plugin rendering via the remote HTTP rendering service with the given
+// URL, e.g. a grafana-image-renderer running as a standalone service, so that it's used the same way as a renderer
+// plugin installed locally. The auth token, if set, is sent to the service in the X-Auth-Token header.
+func NewRemoteRendererPlugin(serverURL, authToken, buildVersion string) *RendererPlugin {
+	r := &RendererPlugin{Remote: true}
+	r.Id = RemoteRendererPluginID
+	r.Type = "renderer"
+	r.Name = "Remote image renderer"
+	r.GrpcPluginV2 = &remoteRenderer{
+		serverURL: serverURL,
+		authToken: authToken,
+		userAgent: fmt.Sprintf("Grafana/%s", buildVersion),
+		log:       log.New("plugins.renderer.remote"),
+	}
+	return r
+}
+
+// remoteRenderer implements the renderer plugin interface with HTTP requests to a remote rendering service.
+type remoteRenderer struct {
+	serverURL string
+	authToken string
+	userAgent string
+	log       log.Logger
+}
+
+var _ pluginextensionv2.RendererPlugin = &remoteRenderer{}
+
+func (r *remoteRenderer) Render(ctx context.Context, req *pluginextensionv2.RenderRequest,
+	_ ...grpc.CallOption) (*pluginextensionv2.RenderResponse, error) {
+	rendererURL, err := url.Parse(r.serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := rendererURL.Query()
+	queryParams.Add("url", req.Url)
+	queryParams.Add("renderKey", req.RenderKey)
+	queryParams.Add("width", strconv.Itoa(int(req.Width)))
+	queryParams.Add("height", strconv.Itoa(int(req.Height)))
+	queryParams.Add("domain", req.Domain)
+	queryParams.Add("timezone", req.Timezone)
+	queryParams.Add("encoding", "png")
+	queryParams.Add("timeout", strconv.Itoa(int(req.Timeout)))
+	queryParams.Add("deviceScaleFactor", fmt.Sprintf("%f", req.DeviceScaleFactor))
+	rendererURL.RawQuery = queryParams.Encode()
+
+	resp, err := r.doRequest(ctx, rendererURL, req.Headers)
+	if err != nil {
+		return nil, err
+	}
+	defer r.closeBody(resp)
+
+	if err := r.readFileResponse(ctx, resp, req.FilePath); err != nil {
+		return nil, err
+	}
+	return &pluginextensionv2.RenderResponse{}, nil
+}
+
+func (r *remoteRenderer) RenderCSV(ctx context.Context, req *pluginextensionv2.RenderCSVRequest,
+	_ ...grpc.CallOption) (*pluginextensionv2.RenderCSVResponse, error) {
+	rendererURL, err := url.Parse(r.serverURL + "/csv")
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := rendererURL.Query()
+	queryParams.Add("url", req.Url)
+	queryParams.Add("renderKey", req.RenderKey)
+	queryParams.Add("domain", req.Domain)
+	queryParams.Add("timezone", req.Timezone)
+	queryParams.Add("timeout", strconv.Itoa(int(req.Timeout)))
+	rendererURL.RawQuery = queryParams.Encode()
+
+	resp, err := r.doRequest(ctx, rendererURL, req.Headers)
+	if err != nil {
+		return nil, err
+	}
+	defer r.closeBody(resp)
+
+	var fileName string
+	if resp.StatusCode == http.StatusOK {
+		_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+		if err != nil {
+			return nil, err
+		}
+		fileName = params["filename"]
+	}
+
+	if err := r.readFileResponse(ctx, resp, req.FilePath); err != nil {
+		return nil, err
+	}
+	return &pluginextensionv2.RenderCSVResponse{FileName: fileName}, nil
+}
+
+// Version returns the version of the remote rendering service.
+func (r *remoteRenderer) Version(ctx context.Context) (string, error) {
+	rendererURL, err := url.Parse(r.serverURL + "/version")
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.doRequest(ctx, rendererURL, nil)
+	if err != nil {
+		return "", err
+	}
+	defer r.closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("remote rendering request to get version failed, status code: %d, status: %s", resp.StatusCode,
+			resp.Status)
+	}
+
+	var info struct {
+		Version string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+func (r *remoteRenderer) doRequest(ctx context.Context, url *url.URL, headers map[string]*pluginextensionv2.StringList) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", r.userAgent)
+	for k, v := range headers {
+		req.Header[k] = v.Values
+	}
+	if r.authToken != "" {
+		req.Header.Set("X-Auth-Token", r.authToken)
+	}
+
+	r.log.Debug("calling remote rendering service", "url", url)
+
+	// make request to renderer server
+	resp, err := remoteRendererClient.Do(req)
+	if err != nil {
+		r.log.Error("Failed to send request to remote rendering service", "error", err)
+		return nil, fmt.Errorf("failed to send request to remote rendering service: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (r *remoteRenderer) readFileResponse(ctx context.Context, resp *http.Response, filePath string) error {
+	// if we didn't get a 200 response, something went wrong.
+	if resp.StatusCode != http.StatusOK {
+		r.log.Error("Remote rendering request failed", "error", resp.Status)
+		return fmt.Errorf("remote rendering request failed, status code: %d, status: %s", resp.StatusCode,
+			resp.Status)
+	}
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := out.Close(); err != nil && !errors.Is(err, fs.ErrClosed) {
+			// We already close the file explicitly in the non-error path, so shouldn't be a problem
+			r.log.Warn("Failed to close file", "path", filePath, "err", err)
+		}
+	}()
+
+	_, err = io.Copy(out, resp.Body)
+	if err != nil {
+		// the renderer service checks whether the request timed out
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		r.log.Error("Remote rendering request failed", "error", err)
+		return fmt.Errorf("remote rendering request failed: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write to %q: %w", filePath, err)
+	}
+
+	return nil
+}
+
+func (r *remoteRenderer) closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		r.log.Warn("Failed to close response body", "err", err)
+	}
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteRendererPlugin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/render":
+			assert.Equal(t, "http://grafana/d/abc?render=1", r.URL.Query().Get("url"))
+			assert.Equal(t, "key", r.URL.Query().Get("renderKey"))
+			assert.Equal(t, "800", r.URL.Query().Get("width"))
+			assert.Equal(t, "value", r.Header.Get("X-Header"))
+			_, _ = w.Write([]byte("png"))
+		case "/render/csv":
+			w.Header().Set("Content-Disposition", `attachment; filename="data.csv"`)
+			_, _ = w.Write([]byte("csv"))
+		case "/render/version":
+			_, _ = w.Write([]byte(`{"version": "3.3.0"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	renderer := NewRemoteRendererPlugin(server.URL+"/render", "token", "8.3.0")
+	require.True(t, renderer.Remote)
+
+	t.Run("start looks up the version of the rendering service", func(t *testing.T) {
+		require.NoError(t, renderer.Start(ctx))
+		require.Equal(t, "3.3.0", renderer.Info.Version)
+	})
+
+	t.Run("images are rendered by the rendering service", func(t *testing.T) {
+		filePath := filepath.Join(dir, "image.png")
+		rsp, err := renderer.GrpcPluginV2.Render(ctx, &pluginextensionv2.RenderRequest{
+			Url:       "http://grafana/d/abc?render=1",
+			RenderKey: "key",
+			Width:     800,
+			Height:    400,
+			FilePath:  filePath,
+			Headers: map[string]*pluginextensionv2.StringList{
+				"X-Header": {Values: []string{"value"}},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, rsp.Error)
+
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.Equal(t, "png", string(content))
+	})
+
+	t.Run("CSVs are rendered by the rendering service", func(t *testing.T) {
+		filePath := filepath.Join(dir, "data.csv")
+		rsp, err := renderer.GrpcPluginV2.RenderCSV(ctx, &pluginextensionv2.RenderCSVRequest{FilePath: filePath})
+		require.NoError(t, err)
+		require.Equal(t, "data.csv", rsp.FileName)
+
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.Equal(t, "csv", string(content))
+	})
+
+	t.Run("failed requests return an error", func(t *testing.T) {
+		unauthorized := NewRemoteRendererPlugin(server.URL+"/render", "", "8.3.0")
+		_, err := unauthorized.GrpcPluginV2.Render(ctx, &pluginextensionv2.RenderRequest{
+			FilePath: filepath.Join(dir, "unauthorized.png"),
+		})
+		require.Error(t, err)
+	})
+}
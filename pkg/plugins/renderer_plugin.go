@@ -3,8 +3,10 @@ package plugins
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"path/filepath"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin/grpcplugin"
@@ -15,8 +17,11 @@ import (
 type RendererPlugin struct {
 	FrontendPluginBase
 
-	Executable           string `json:"executable,omitempty"`
-	GrpcPluginV2         pluginextensionv2.RendererPlugin
+	Executable   string `json:"executable,omitempty"`
+	GrpcPluginV2 pluginextensionv2.RendererPlugin
+	// Remote is true if the plugin renders via a remote HTTP rendering service rather than a local plugin process.
+	Remote bool `json:"-"`
+
 	backendPluginManager backendplugin.Manager
 }
 
@@ -39,6 +44,16 @@ func (r *RendererPlugin) Load(decoder *json.Decoder, base *PluginBase,
 }
 
 func (r *RendererPlugin) Start(ctx context.Context) error {
+	if r.Remote {
+		// the remote rendering service runs on its own, only its version is looked up
+		version, err := r.GrpcPluginV2.(*remoteRenderer).Version(ctx)
+		if err != nil {
+			log.New("plugins.renderer.remote").Info("Couldn't get remote renderer version", "err", err)
+		}
+		r.Info.Version = version
+		return nil
+	}
+
 	if err := r.backendPluginManager.StartPlugin(ctx, r.Id); err != nil {
 		return errutil.Wrapf(err, "Failed to start renderer plugin")
 	}
@@ -46,6 +61,34 @@ func (r *RendererPlugin) Start(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth checks the health of the renderer plugin, or of the remote rendering service. The status is unknown
+// if the plugin doesn't implement health checks.
+func (r *RendererPlugin) CheckHealth(ctx context.Context) (*backend.CheckHealthResult, error) {
+	if r.Remote {
+		version, err := r.GrpcPluginV2.(*remoteRenderer).Version(ctx)
+		if err != nil {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: err.Error(),
+			}, nil
+		}
+		r.Info.Version = version
+		return &backend.CheckHealthResult{Status: backend.HealthStatusOk}, nil
+	}
+
+	result, err := r.backendPluginManager.CheckHealth(ctx, backend.PluginContext{PluginID: r.Id})
+	if errors.Is(err, backendplugin.ErrMethodNotImplemented) {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Health check not implemented by the renderer plugin",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (r *RendererPlugin) onPluginStart(pluginID string, renderer pluginextensionv2.RendererPlugin, logger log.Logger) error {
 	r.GrpcPluginV2 = renderer
 	return nil
@@ -0,0 +1,62 @@
+package plugins
+
+import (
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// PluginResourceRoute is a resource route of a backend plugin declared in its plugin.json. When a plugin declares
+// resource routes, only the resource calls matching one of them are forwarded to the plugin.
+type PluginResourceRoute struct {
+	// Path is the pattern of the resource path, without leading slash, using the syntax of path.Match, e.g.
+	// "dashboards/*".
+	Path string `json:"path"`
+	// Method is the comma separated list of HTTP methods of the route. Empty or "*" matches any method.
+	Method string `json:"method"`
+	// ReqRole is the role required to call the route.
+	ReqRole models.RoleType `json:"reqRole"`
+	// ReqAction is the access control action required to call the route, instead of its role, when access control is
+	// enabled.
+	ReqAction string `json:"reqAction,omitempty"`
+}
+
+// MatchResourceRoute returns the first of the resource routes matching the method and path of a resource call, or
+// nil. The path is cleaned first, so that relative elements can't be used to escape a route.
+func MatchResourceRoute(routes []*PluginResourceRoute, method, resourcePath string) *PluginResourceRoute {
+	resourcePath = strings.TrimPrefix(path.Clean("/"+resourcePath), "/")
+	for _, route := range routes {
+		if !route.matchesMethod(method) {
+			continue
+		}
+		if ok, err := path.Match(strings.TrimPrefix(route.Path, "/"), resourcePath); err == nil && ok {
+			return route
+		}
+	}
+	return nil
+}
+
+// IsCleanResourcePath returns whether the path of a resource call is in its canonical form, without empty, "." or
+// ".." elements, so that the path forwarded to the plugin is the one its resource routes were matched against. A
+// trailing slash is allowed.
+func IsCleanResourcePath(resourcePath string) bool {
+	cleaned := strings.TrimPrefix(path.Clean("/"+resourcePath), "/")
+	if cleaned != "" && strings.HasSuffix(resourcePath, "/") {
+		cleaned += "/"
+	}
+	return cleaned == resourcePath
+}
+
+func (r *PluginResourceRoute) matchesMethod(method string) bool {
+	if r.Method == "" {
+		return true
+	}
+	for _, m := range strings.Split(r.Method, ",") {
+		m = strings.TrimSpace(m)
+		if m == "*" || strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
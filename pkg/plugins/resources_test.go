@@ -0,0 +1,45 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchResourceRoute(t *testing.T) {
+	routes := []*PluginResourceRoute{
+		{Path: "dashboards/*", Method: "GET"},
+		{Path: "dashboards/*", Method: "POST, PUT", ReqRole: models.ROLE_EDITOR},
+		{Path: "/settings", ReqRole: models.ROLE_ADMIN},
+	}
+
+	tcs := []struct {
+		method, path string
+		expected     *PluginResourceRoute
+	}{
+		{method: "GET", path: "dashboards/abc", expected: routes[0]},
+		{method: "put", path: "dashboards/abc", expected: routes[1]},
+		{method: "DELETE", path: "dashboards/abc"},
+		{method: "GET", path: "dashboards/abc/versions"},
+		{method: "GET", path: "dashboards"},
+		{method: "PATCH", path: "/settings", expected: routes[2]},
+		{method: "GET", path: "dashboards/../settings", expected: routes[2]},
+		{method: "GET", path: "other"},
+	}
+	for _, tc := range tcs {
+		require.Equal(t, tc.expected, MatchResourceRoute(routes, tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+
+	require.Nil(t, MatchResourceRoute(nil, "GET", "dashboards/abc"))
+}
+
+func TestIsCleanResourcePath(t *testing.T) {
+	for _, resourcePath := range []string{"", "dashboards/abc", "dashboards/", "api/v1/query"} {
+		require.True(t, IsCleanResourcePath(resourcePath), resourcePath)
+	}
+	for _, resourcePath := range []string{"public/../admin/x", "../admin", "dashboards/./abc", "dashboards//abc",
+		"/settings", "dashboards/..", "/"} {
+		require.False(t, IsCleanResourcePath(resourcePath), resourcePath)
+	}
+}
@@ -0,0 +1,136 @@
+package plugins
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// pluginRoleVersion is the version of the fixed roles registered for the plugins. The roles are registered in memory
+// when Grafana starts, from the plugin.json of the installed version of the plugins.
+const pluginRoleVersion = 1
+
+var rePluginRoleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// coreActionNamespaces are the namespaces of the actions of Grafana, i.e. the part of the actions before the first
+// "." or ":", which plugins can't use even if their ID is the same, e.g. a plugin with the ID "users" can't grant
+// users:write.
+var coreActionNamespaces = map[string]bool{
+	"alert": true, "annotations": true, "apikeys": true, "basic": true, "dashboards": true, "datasources": true,
+	"fixed": true, "folders": true, "ldap": true, "licensing": true, "managed": true, "org": true, "orgs": true,
+	"permissions": true, "plugins": true, "provisioning": true, "reports": true, "roles": true, "server": true,
+	"serviceaccounts": true, "settings": true, "snapshots": true, "status": true, "teams": true, "users": true,
+}
+
+// PluginRoleRegistration is a role declared by a plugin in its plugin.json, with the basic roles it's granted to,
+// i.e. Viewer, Editor, Admin or Grafana Admin. The role is registered as the fixed role
+// fixed:plugins:<plugin id>:<name>, and its permissions can be required by the pages, dashboards and resource routes
+// of the plugin, with their action.
+type PluginRoleRegistration struct {
+	Role   PluginRole `json:"role"`
+	Grants []string   `json:"grants"`
+}
+
+// PluginRole is a role declared by a plugin. Its name is unique within the plugin, and the actions of its
+// permissions are namespaced by the plugin ID, e.g. grafana-example-app.reports:read, so that a plugin can't grant
+// the permissions of Grafana or of other plugins.
+type PluginRole struct {
+	Name        string             `json:"name"`
+	DisplayName string             `json:"displayName,omitempty"`
+	Description string             `json:"description"`
+	Permissions []PluginPermission `json:"permissions"`
+}
+
+type PluginPermission struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope,omitempty"`
+}
+
+// PluginRoleName returns the name of the fixed role registered for the role of the plugin.
+func PluginRoleName(pluginID, name string) string {
+	return accesscontrol.FixedRolePrefix + "plugins:" + pluginID + ":" + name
+}
+
+// IsPluginAction returns whether the action is namespaced by the plugin ID, and not in the namespace of the actions
+// of Grafana.
+func IsPluginAction(pluginID, action string) bool {
+	return len(action) > len(pluginID)+1 && strings.HasPrefix(action, pluginID) &&
+		(action[len(pluginID)] == '.' || action[len(pluginID)] == ':') && !isCoreAction(action)
+}
+
+// isCoreAction returns whether the action is in the namespace of the actions of Grafana.
+func isCoreAction(action string) bool {
+	namespace := action
+	if i := strings.IndexAny(action, ".:"); i >= 0 {
+		namespace = action[:i]
+	}
+	return coreActionNamespaces[namespace]
+}
+
+// ValidatePluginRoles returns an error if one of the roles declared by the plugin has no name or a duplicate one,
+// grants permissions which aren't namespaced by the plugin ID or are in the namespace of the actions of Grafana, or is
+// granted to unknown basic roles.
+func ValidatePluginRoles(pluginID string, roles []*PluginRoleRegistration) error {
+	names := map[string]bool{}
+	for i, registration := range roles {
+		if registration == nil {
+			return fmt.Errorf("role %d is empty", i)
+		}
+		name := registration.Role.Name
+		if !rePluginRoleName.MatchString(name) {
+			return fmt.Errorf("role %d has an invalid name %q", i, name)
+		}
+		if names[name] {
+			return fmt.Errorf("role %q is declared twice", name)
+		}
+		names[name] = true
+
+		if len(registration.Role.Permissions) == 0 {
+			return fmt.Errorf("role %q has no permissions", name)
+		}
+		for _, permission := range registration.Role.Permissions {
+			if isCoreAction(permission.Action) {
+				return fmt.Errorf("role %q has the action %q which is in the namespace of the actions of Grafana", name,
+					permission.Action)
+			}
+			if !IsPluginAction(pluginID, permission.Action) {
+				return fmt.Errorf("role %q has the action %q which isn't prefixed with the plugin ID", name,
+					permission.Action)
+			}
+			if permission.Scope != "" && !accesscontrol.ValidateScope(permission.Scope) {
+				return fmt.Errorf("role %q has an invalid scope %q", name, permission.Scope)
+			}
+		}
+		if err := accesscontrol.ValidateBuiltInRoles(registration.Grants); err != nil {
+			return fmt.Errorf("role %q is granted to an invalid role: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RoleRegistrations returns the fixed roles to register for the roles declared by the plugin.
+func (p *PluginBase) RoleRegistrations() []accesscontrol.RoleRegistration {
+	registrations := make([]accesscontrol.RoleRegistration, 0, len(p.Roles))
+	for _, registration := range p.Roles {
+		permissions := make([]accesscontrol.Permission, 0, len(registration.Role.Permissions))
+		for _, permission := range registration.Role.Permissions {
+			permissions = append(permissions, accesscontrol.Permission{
+				Action: permission.Action,
+				Scope:  permission.Scope,
+			})
+		}
+		registrations = append(registrations, accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{
+				Version:     pluginRoleVersion,
+				Name:        PluginRoleName(p.Id, registration.Role.Name),
+				DisplayName: registration.Role.DisplayName,
+				Description: registration.Role.Description,
+				Permissions: permissions,
+			},
+			Grants: registration.Grants,
+		})
+	}
+	return registrations
+}
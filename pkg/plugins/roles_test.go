@@ -0,0 +1,77 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePluginRoles(t *testing.T) {
+	role := func(name string, grants []string, actions ...string) *PluginRoleRegistration {
+		registration := &PluginRoleRegistration{Role: PluginRole{Name: name}, Grants: grants}
+		for _, action := range actions {
+			registration.Role.Permissions = append(registration.Role.Permissions, PluginPermission{Action: action})
+		}
+		return registration
+	}
+
+	require.NoError(t, ValidatePluginRoles("test-app", []*PluginRoleRegistration{
+		role("reader", []string{"Viewer"}, "test-app.reports:read"),
+		role("admin", []string{"Admin", "Grafana Admin"}, "test-app.reports:read", "test-app:configure"),
+	}))
+
+	tcs := []struct {
+		desc  string
+		roles []*PluginRoleRegistration
+	}{
+		{desc: "no name", roles: []*PluginRoleRegistration{role("", nil, "test-app:read")}},
+		{desc: "invalid name", roles: []*PluginRoleRegistration{role("fixed:reader", nil, "test-app:read")}},
+		{desc: "duplicate name", roles: []*PluginRoleRegistration{
+			role("reader", nil, "test-app:read"),
+			role("reader", nil, "test-app:write"),
+		}},
+		{desc: "no permissions", roles: []*PluginRoleRegistration{role("reader", nil)}},
+		{desc: "core action", roles: []*PluginRoleRegistration{role("admin", nil, "users:write")}},
+		{desc: "action of another plugin", roles: []*PluginRoleRegistration{role("reader", nil, "test-application:read")}},
+		{desc: "invalid grant", roles: []*PluginRoleRegistration{role("reader", []string{"Owner"}, "test-app:read")}},
+	}
+	for _, tc := range tcs {
+		t.Run("Should reject roles with "+tc.desc, func(t *testing.T) {
+			require.Error(t, ValidatePluginRoles("test-app", tc.roles))
+		})
+	}
+
+	t.Run("Should reject core actions of plugins with a colliding ID", func(t *testing.T) {
+		for pluginID, action := range map[string]string{
+			"plugins":     "plugins:install",
+			"datasources": "datasources:read",
+			"users":       "users.roles:add",
+			"org":         "org.users:write",
+		} {
+			require.Error(t, ValidatePluginRoles(pluginID, []*PluginRoleRegistration{role("admin", nil, action)}), pluginID)
+			require.False(t, IsPluginAction(pluginID, action), pluginID)
+		}
+	})
+}
+
+func TestPluginBase_RoleRegistrations(t *testing.T) {
+	plugin := &PluginBase{
+		Id: "test-app",
+		Roles: []*PluginRoleRegistration{{
+			Role: PluginRole{
+				Name:        "reader",
+				Description: "Read the reports",
+				Permissions: []PluginPermission{{Action: "test-app.reports:read", Scope: "test-app.reports:*"}},
+			},
+			Grants: []string{"Viewer"},
+		}},
+	}
+
+	registrations := plugin.RoleRegistrations()
+	require.Len(t, registrations, 1)
+	require.Equal(t, "fixed:plugins:test-app:reader", registrations[0].Role.Name)
+	require.Equal(t, "Read the reports", registrations[0].Role.Description)
+	require.Equal(t, "test-app.reports:read", registrations[0].Role.Permissions[0].Action)
+	require.Equal(t, "test-app.reports:*", registrations[0].Role.Permissions[0].Scope)
+	require.Equal(t, []string{"Viewer"}, registrations[0].Grants)
+}
@@ -0,0 +1,77 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/grpcplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// SecretsManagerPlugin is a backend plugin encrypting the data keys of the secrets service, e.g. with a KMS.
+// It's started when the secrets service first needs it.
+type SecretsManagerPlugin struct {
+	PluginBase
+
+	Executable           string `json:"executable,omitempty"`
+	backendPluginManager backendplugin.Manager
+
+	startMu      sync.Mutex
+	mu           sync.RWMutex
+	grpcPluginV2 pluginextensionv2.SecretsManagerPlugin
+}
+
+func (p *SecretsManagerPlugin) Load(decoder *json.Decoder, base *PluginBase,
+	backendPluginManager backendplugin.Manager) (interface{}, error) {
+	if err := decoder.Decode(p); err != nil {
+		return nil, err
+	}
+
+	p.backendPluginManager = backendPluginManager
+
+	cmd := ComposePluginStartCommand("plugin_start")
+	fullpath := filepath.Join(base.PluginDir, cmd)
+	factory := grpcplugin.NewSecretsManagerPlugin(p.Id, fullpath, p.onPluginStart)
+	if err := backendPluginManager.Register(p.Id, factory); err != nil {
+		return nil, errutil.Wrapf(err, "failed to register backend plugin")
+	}
+
+	return p, nil
+}
+
+// Client returns the gRPC client of the plugin, starting the plugin if it's not running yet.
+func (p *SecretsManagerPlugin) Client() (pluginextensionv2.SecretsManagerPlugin, error) {
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	if p.client() == nil {
+		// the plugin is restarted in the background if its process is killed, until Grafana stops
+		if err := p.backendPluginManager.StartPlugin(context.Background(), p.Id); err != nil {
+			return nil, errutil.Wrapf(err, "failed to start secrets manager plugin")
+		}
+	}
+
+	client := p.client()
+	if client == nil {
+		return nil, errutil.Wrapf(backendplugin.ErrMethodNotImplemented, "plugin %s doesn't implement the secrets manager", p.Id)
+	}
+	return client, nil
+}
+
+func (p *SecretsManagerPlugin) client() pluginextensionv2.SecretsManagerPlugin {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.grpcPluginV2
+}
+
+func (p *SecretsManagerPlugin) onPluginStart(pluginID string, secretsManager pluginextensionv2.SecretsManagerPlugin, logger log.Logger) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.grpcPluginV2 = secretsManager
+	return nil
+}
@@ -0,0 +1,260 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SettingsSchema is declared by the settingsSchema property of plugin.json, and describes the jsonData and the
+// secureJsonData of the plugin settings of an app, or of the data sources of a data source plugin. The settings are
+// validated against it when they're saved through the HTTP API.
+type SettingsSchema struct {
+	JSONData       *JSONSchema `json:"jsonData,omitempty"`
+	SecureJSONData *JSONSchema `json:"secureJsonData,omitempty"`
+}
+
+// JSONSchema is the subset of JSON Schema supported to validate plugin settings.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// UnmarshalJSON decodes the schema and compiles its pattern, so that a plugin with an invalid pattern fails to
+// load rather than to validate its settings.
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	type jsonSchema JSONSchema
+	if err := json.Unmarshal(data, (*jsonSchema)(s)); err != nil {
+		return err
+	}
+
+	switch s.Type {
+	case "", "object", "array", "string", "number", "integer", "boolean":
+	default:
+		return fmt.Errorf("unsupported type %q in settings schema", s.Type)
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in settings schema: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+	return nil
+}
+
+// SettingsFieldError is the error of a field of plugin settings not matching the settings schema of the plugin.
+// Field is the path of the field, e.g. jsonData.auth.type or secureJsonData.apiKey.
+type SettingsFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SettingsValidationError is returned when plugin settings don't match the settings schema of the plugin.
+type SettingsValidationError struct {
+	Fields []SettingsFieldError
+}
+
+func (e SettingsValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return "invalid plugin settings: " + strings.Join(messages, ", ")
+}
+
+// Validate validates the JSON data and the secure JSON data of plugin settings against the schemas, which describe
+// objects whatever their type. Since the stored secure JSON data is only sent again when it's changed,
+// storedSecureKeys are the keys of the secure JSON data already stored, which satisfy the required secure fields.
+// It returns a SettingsValidationError listing the invalid fields, or nil if the settings are valid or the schema
+// is nil.
+func (s *SettingsSchema) Validate(jsonData map[string]interface{}, secureJSONData map[string]string,
+	storedSecureKeys []string) error {
+	if s == nil {
+		return nil
+	}
+
+	var errs []SettingsFieldError
+	if s.JSONData != nil {
+		errs = s.JSONData.validateObject("jsonData", jsonData, errs)
+	}
+
+	if schema := s.SecureJSONData; schema != nil {
+		present := make(map[string]bool, len(secureJSONData)+len(storedSecureKeys))
+		for _, key := range storedSecureKeys {
+			present[key] = true
+		}
+		values := make(map[string]interface{}, len(secureJSONData))
+		for key, value := range secureJSONData {
+			present[key] = true
+			values[key] = value
+		}
+
+		for _, key := range schema.Required {
+			if !present[key] {
+				errs = append(errs, SettingsFieldError{Field: "secureJsonData." + key, Message: "is required"})
+			}
+		}
+		errs = schema.validateProperties("secureJsonData", values, errs)
+	}
+
+	if len(errs) > 0 {
+		return SettingsValidationError{Fields: errs}
+	}
+	return nil
+}
+
+func (s *JSONSchema) validate(path string, value interface{}, errs []SettingsFieldError) []SettingsFieldError {
+	fieldError := func(format string, args ...interface{}) []SettingsFieldError {
+		return append(errs, SettingsFieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if number, ok := toFloat(value); ok {
+		value = number
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fieldError("must be an object")
+		}
+		errs = s.validateObject(path, object, errs)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fieldError("must be an array")
+		}
+		if s.Items != nil {
+			for i, item := range array {
+				errs = s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fieldError("must be a string")
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fieldError("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fieldError("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			return fieldError("must match the pattern %q", s.Pattern)
+		}
+	case "number", "integer":
+		number, ok := value.(float64)
+		if s.Type == "integer" && (!ok || number != float64(int64(number))) {
+			return fieldError("must be an integer")
+		}
+		if !ok {
+			return fieldError("must be a number")
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return fieldError("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			return fieldError("must be less than or equal to %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fieldError("must be a boolean")
+		}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return fieldError("must be one of %s", s.enumString())
+	}
+	return errs
+}
+
+func (s *JSONSchema) validateObject(path string, object map[string]interface{}, errs []SettingsFieldError) []SettingsFieldError {
+	for _, key := range s.Required {
+		if object[key] == nil {
+			errs = append(errs, SettingsFieldError{Field: path + "." + key, Message: "is required"})
+		}
+	}
+	return s.validateProperties(path, object, errs)
+}
+
+// validateProperties validates the properties of the object against the schemas of the properties, in the order of
+// their keys so that the errors are stable.
+func (s *JSONSchema) validateProperties(path string, object map[string]interface{}, errs []SettingsFieldError) []SettingsFieldError {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if propertySchema, ok := s.Properties[key]; ok {
+			if object[key] != nil {
+				errs = propertySchema.validate(path+"."+key, object[key], errs)
+			}
+			continue
+		}
+		if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			errs = append(errs, SettingsFieldError{Field: path + "." + key, Message: "is not allowed"})
+		}
+	}
+	return errs
+}
+
+func (s *JSONSchema) inEnum(value interface{}) bool {
+	for _, allowed := range s.Enum {
+		if number, ok := toFloat(allowed); ok {
+			allowed = number
+		}
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *JSONSchema) enumString() string {
+	values := make([]string, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		values = append(values, string(encoded))
+	}
+	return strings.Join(values, ", ")
+}
+
+// toFloat converts the numbers decoded from JSON, with or without json.Number, to float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsSchema(t *testing.T) {
+	var schema SettingsSchema
+	err := json.Unmarshal([]byte(`{
+		"jsonData": {
+			"required": ["url"],
+			"additionalProperties": false,
+			"properties": {
+				"url": {"type": "string", "pattern": "^https?://"},
+				"timeout": {"type": "integer", "minimum": 1, "maximum": 300},
+				"mode": {"type": "string", "enum": ["basic", "advanced"]},
+				"hosts": {"type": "array", "items": {"type": "string", "minLength": 1}},
+				"tls": {
+					"type": "object",
+					"required": ["skipVerify"],
+					"properties": {"skipVerify": {"type": "boolean"}}
+				}
+			}
+		},
+		"secureJsonData": {
+			"required": ["apiKey"],
+			"properties": {"apiKey": {"type": "string", "minLength": 8}}
+		}
+	}`), &schema)
+	require.NoError(t, err)
+
+	validate := func(jsonData string, secureJSONData map[string]string, storedSecureKeys ...string) []SettingsFieldError {
+		t.Helper()
+
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(jsonData), &data))
+
+		err := schema.Validate(data, secureJSONData, storedSecureKeys)
+		if err == nil {
+			return nil
+		}
+		var validationErr SettingsValidationError
+		require.ErrorAs(t, err, &validationErr)
+		return validationErr.Fields
+	}
+
+	t.Run("Valid settings should pass", func(t *testing.T) {
+		errs := validate(`{"url": "https://example.com", "timeout": 30, "mode": "basic", "hosts": ["a"], "tls": {"skipVerify": true}}`,
+			map[string]string{"apiKey": "12345678"})
+		require.Empty(t, errs)
+	})
+
+	t.Run("Stored secure JSON data should satisfy the required secure fields", func(t *testing.T) {
+		errs := validate(`{"url": "https://example.com"}`, nil, "apiKey")
+		require.Empty(t, errs)
+	})
+
+	t.Run("Invalid settings should return an error for each invalid field", func(t *testing.T) {
+		errs := validate(`{"timeout": 1.5, "mode": "other", "hosts": [""], "tls": {}, "unknown": 1}`,
+			map[string]string{"apiKey": "short"})
+		require.Equal(t, []SettingsFieldError{
+			{Field: "jsonData.url", Message: "is required"},
+			{Field: "jsonData.hosts[0]", Message: "must be at least 1 characters long"},
+			{Field: "jsonData.mode", Message: `must be one of "basic", "advanced"`},
+			{Field: "jsonData.timeout", Message: "must be an integer"},
+			{Field: "jsonData.tls.skipVerify", Message: "is required"},
+			{Field: "jsonData.unknown", Message: "is not allowed"},
+			{Field: "secureJsonData.apiKey", Message: "must be at least 8 characters long"},
+		}, errs)
+	})
+
+	t.Run("Missing settings should return an error for each required field", func(t *testing.T) {
+		errs := validate(`{"url": "ftp://example.com", "timeout": 0}`, nil)
+		require.Equal(t, []SettingsFieldError{
+			{Field: "jsonData.timeout", Message: "must be greater than or equal to 1"},
+			{Field: "jsonData.url", Message: `must match the pattern "^https?://"`},
+			{Field: "secureJsonData.apiKey", Message: "is required"},
+		}, errs)
+	})
+
+	t.Run("A nil schema should accept any settings", func(t *testing.T) {
+		var schema *SettingsSchema
+		require.NoError(t, schema.Validate(map[string]interface{}{"url": 1}, nil, nil))
+	})
+
+	t.Run("A schema with an invalid pattern should fail to decode", func(t *testing.T) {
+		var schema SettingsSchema
+		err := json.Unmarshal([]byte(`{"jsonData": {"properties": {"url": {"type": "string", "pattern": "("}}}}`), &schema)
+		require.Error(t, err)
+	})
+}
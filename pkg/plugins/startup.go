@@ -0,0 +1,22 @@
+package plugins
+
+// The phases of the startup of a plugin profiled by the plugin manager.
+const (
+	// StartupPhaseScan is reading the plugin.json of the plugin while scanning the plugin directories.
+	StartupPhaseScan = "scan"
+	// StartupPhaseSignature is computing and validating the signature of the plugin, i.e. hashing its files.
+	StartupPhaseSignature = "signature"
+	// StartupPhaseBackendStart is registering and starting the backend of the plugin.
+	StartupPhaseBackendStart = "backend_start"
+	// StartupPhaseInitialize is initializing the frontend of the plugin, e.g. its static routes and includes.
+	StartupPhaseInitialize = "initialize"
+)
+
+// PluginStartupProfile is the time a plugin took to start up, by phase.
+type PluginStartupProfile struct {
+	PluginID string `json:"pluginId"`
+	// Phases are the durations of the phases of the startup of the plugin in milliseconds, by phase.
+	Phases map[string]float64 `json:"phases"`
+	// Total is the sum of the durations of the phases in milliseconds.
+	Total float64 `json:"total"`
+}
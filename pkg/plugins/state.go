@@ -1,5 +1,7 @@
 package plugins
 
+import "time"
+
 type PluginSignatureStatus string
 
 func (pss PluginSignatureStatus) IsValid() bool {
@@ -33,6 +35,36 @@ const (
 
 type PluginFiles map[string]struct{}
 
+// PluginSignatureDetails are the details of the manifest of a plugin and the result of the verification of its
+// signature.
+type PluginSignatureDetails struct {
+	Status          PluginSignatureStatus    `json:"status"`
+	Type            PluginSignatureType      `json:"type,omitempty"`
+	SigningOrg      string                   `json:"signingOrg,omitempty"`
+	SigningOrgID    string                   `json:"signingOrgId,omitempty"`
+	ManifestVersion string                   `json:"manifestVersion,omitempty"`
+	KeyID           string                   `json:"keyId,omitempty"`
+	SignedAt        time.Time                `json:"signedAt,omitempty"`
+	RootURLs        []string                 `json:"rootUrls,omitempty"`
+	Reason          string                   `json:"reason,omitempty"`
+	Files           []PluginFileVerification `json:"files"`
+}
+
+type PluginFileStatus string
+
+const (
+	PluginFileValid    PluginFileStatus = "valid"    // checksum matches the manifest
+	PluginFileModified PluginFileStatus = "modified" // checksum does not match the manifest
+	PluginFileMissing  PluginFileStatus = "missing"  // listed in the manifest but not found
+	PluginFileUnsigned PluginFileStatus = "unsigned" // not listed in the manifest
+)
+
+// PluginFileVerification is the result of the verification of a plugin file against the manifest.
+type PluginFileVerification struct {
+	Path   string           `json:"path"`
+	Status PluginFileStatus `json:"status"`
+}
+
 type PluginSignatureState struct {
 	Status     PluginSignatureStatus
 	Type       PluginSignatureType
@@ -381,6 +381,10 @@ func (s *testRenderService) Version() string {
 	return ""
 }
 
+func (s *testRenderService) Status(ctx context.Context) *rendering.Status {
+	return &rendering.Status{Available: s.IsAvailable()}
+}
+
 var _ rendering.Service = &testRenderService{}
 
 type testImageUploader struct {
@@ -15,25 +15,28 @@ import (
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service) *CleanUpService {
+	shortURLService shorturls.Service, queryHistoryService queryhistory.Service) *CleanUpService {
 	s := &CleanUpService{
-		Cfg:               cfg,
-		ServerLockService: serverLockService,
-		ShortURLService:   shortURLService,
-		log:               log.New("cleanup"),
+		Cfg:                 cfg,
+		ServerLockService:   serverLockService,
+		ShortURLService:     shortURLService,
+		QueryHistoryService: queryHistoryService,
+		log:                 log.New("cleanup"),
 	}
 	return s
 }
 
 type CleanUpService struct {
-	log               log.Logger
-	Cfg               *setting.Cfg
-	ServerLockService *serverlock.ServerLockService
-	ShortURLService   shorturls.Service
+	log                 log.Logger
+	Cfg                 *setting.Cfg
+	ServerLockService   *serverlock.ServerLockService
+	ShortURLService     shorturls.Service
+	QueryHistoryService queryhistory.Service
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -52,6 +55,7 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites()
 			srv.deleteStaleShortURLs()
+			srv.deleteStaleQueryHistory(ctxWithTimeout)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts()
@@ -181,3 +185,17 @@ func (srv *CleanUpService) deleteStaleShortURLs() {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
+	if srv.Cfg.QueryHistoryRetention <= 0 {
+		return
+	}
+
+	olderThan := time.Now().Add(-srv.Cfg.QueryHistoryRetention).Unix()
+	rowsCount, err := srv.QueryHistoryService.DeleteStaleQueriesInQueryHistory(ctx, olderThan)
+	if err != nil {
+		srv.log.Error("Problem deleting stale queries in query history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted stale queries from query history", "rows affected", rowsCount)
+	}
+}
@@ -0,0 +1,209 @@
+// Package datasourcehealth checks the health of the data sources in the background, so that broken credentials
+// are detected before a dashboard using the data source fails.
+package datasourcehealth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const serverLockName = "data source health checks"
+
+// Status is the result of the last health check of a data source.
+type Status struct {
+	Id           int64
+	OrgId        int64
+	DataSourceId int64
+	Status       string
+	Message      string
+	LatencyMs    int64
+	Checked      time.Time
+}
+
+func (s Status) TableName() string {
+	return "data_source_health"
+}
+
+type Service struct {
+	cfg                  *setting.Cfg
+	sqlStore             *sqlstore.SQLStore
+	serverLock           *serverlock.ServerLockService
+	pluginManager        plugins.Manager
+	backendPluginManager backendplugin.Manager
+	dataSourcesService   *datasources.Service
+	log                  log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, serverLock *serverlock.ServerLockService,
+	pluginManager plugins.Manager, backendPluginManager backendplugin.Manager,
+	dataSourcesService *datasources.Service) *Service {
+	return &Service{
+		cfg:                  cfg,
+		sqlStore:             sqlStore,
+		serverLock:           serverLock,
+		pluginManager:        pluginManager,
+		backendPluginManager: backendPluginManager,
+		dataSourcesService:   dataSourcesService,
+		log:                  log.New("datasources.health"),
+	}
+}
+
+// IsDisabled returns true if the background health checks are disabled.
+func (s *Service) IsDisabled() bool {
+	return s.cfg.DataSourceHealthCheckInterval <= 0
+}
+
+// Run checks the health of all data sources on the configured interval. In a high availability setup, a single
+// instance checks them on each interval.
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.cfg.DataSourceHealthCheckInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, serverLockName, interval/2, func(ctx context.Context) {
+				if err := s.checkAll(ctx); err != nil {
+					s.log.Error("Failed to check the health of the data sources", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock data source health checks", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// GetStatuses returns the results of the last health check of the data sources of an organization, by data
+// source ID.
+func (s *Service) GetStatuses(ctx context.Context, orgID int64) (map[int64]Status, error) {
+	var statuses []Status
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Find(&statuses)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]Status, len(statuses))
+	for _, status := range statuses {
+		result[status.DataSourceId] = status
+	}
+	return result, nil
+}
+
+// checkAll checks the health of the data sources of all organizations which have a backend plugin, and deletes
+// the results of the deleted data sources.
+func (s *Service) checkAll(ctx context.Context) error {
+	var dataSources []*models.DataSource
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Find(&dataSources)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ds := range dataSources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		plugin := s.pluginManager.GetDataSource(ds.Type)
+		if plugin == nil || !plugin.Backend {
+			continue
+		}
+
+		status, checked := s.check(ctx, plugin, ds)
+		if !checked {
+			continue
+		}
+		if err := s.saveStatus(ctx, status); err != nil {
+			s.log.Error("Failed to save data source health", "orgId", ds.OrgId, "uid", ds.Uid, "error", err)
+		}
+	}
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM data_source_health WHERE data_source_id NOT IN (SELECT id FROM data_source)")
+		return err
+	})
+}
+
+// check checks the health of a data source, and returns false if the plugin doesn't implement health checks. The
+// check fails if it doesn't complete before the configured timeout.
+func (s *Service) check(ctx context.Context, plugin *plugins.DataSourcePlugin, ds *models.DataSource) (Status, bool) {
+	status := Status{
+		OrgId:        ds.OrgId,
+		DataSourceId: ds.Id,
+		Status:       backend.HealthStatusUnknown.String(),
+	}
+
+	settings, err := adapters.ModelToInstanceSettings(ds, func(map[string][]byte) map[string]string {
+		return s.dataSourcesService.DecryptedValues(ds)
+	})
+	if err != nil {
+		status.Message = err.Error()
+		status.Checked = time.Now()
+		return status, true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.DataSourceHealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := s.backendPluginManager.CheckHealth(ctx, backend.PluginContext{
+		OrgID:                      ds.OrgId,
+		PluginID:                   plugin.Id,
+		DataSourceInstanceSettings: settings,
+	})
+	status.Checked = time.Now()
+	status.LatencyMs = status.Checked.Sub(start).Milliseconds()
+
+	switch {
+	case errors.Is(err, backendplugin.ErrMethodNotImplemented):
+		return status, false
+	case err != nil:
+		status.Status = backend.HealthStatusError.String()
+		status.Message = err.Error()
+	case res != nil:
+		status.Status = res.Status.String()
+		status.Message = res.Message
+	}
+
+	if status.Status == backend.HealthStatusError.String() {
+		s.log.Warn("Data source health check failed", "orgId", ds.OrgId, "uid", ds.Uid, "name", ds.Name,
+			"message", status.Message)
+	}
+	return status, true
+}
+
+func (s *Service) saveStatus(ctx context.Context, status Status) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := Status{}
+		has, err := sess.Where("org_id = ? AND data_source_id = ?", status.OrgId, status.DataSourceId).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			status.Id = existing.Id
+			_, err = sess.ID(existing.Id).AllCols().Update(&status)
+			return err
+		}
+		_, err = sess.Insert(&status)
+		return err
+	})
+}
@@ -0,0 +1,99 @@
+package datasourcehealth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CheckAll(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	backendPM := &fakeBackendPluginManager{results: map[string]*backend.CheckHealthResult{
+		"healthy": {Status: backend.HealthStatusOk, Message: "Data source is working"},
+		"broken":  {Status: backend.HealthStatusError, Message: "401 Unauthorized"},
+	}}
+	cfg := setting.NewCfg()
+	cfg.DataSourceHealthCheckInterval = time.Minute
+	cfg.DataSourceHealthCheckTimeout = time.Second
+	s := ProvideService(cfg, sqlStore, nil, &fakePluginManager{}, backendPM,
+		datasources.ProvideService(bus.New(), sqlStore, ossencryption.ProvideService(), secretsManager.SetupTestService(t, nil)))
+
+	addDataSource := func(orgID int64, name, dsType string) int64 {
+		cmd := &models.AddDataSourceCommand{OrgId: orgID, Name: name, Type: dsType, Access: models.DS_ACCESS_PROXY}
+		require.NoError(t, sqlStore.AddDataSource(cmd))
+		return cmd.Result.Id
+	}
+	healthyID := addDataSource(1, "healthy", "backend")
+	brokenID := addDataSource(1, "broken", "backend")
+	addDataSource(1, "frontend", "frontend")
+	addDataSource(1, "no-health-check", "backend")
+	otherOrgID := addDataSource(2, "healthy", "backend")
+
+	require.NoError(t, s.checkAll(context.Background()))
+
+	statuses, err := s.GetStatuses(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, "OK", statuses[healthyID].Status)
+	require.Equal(t, "Data source is working", statuses[healthyID].Message)
+	require.Equal(t, "ERROR", statuses[brokenID].Status)
+	require.Equal(t, "401 Unauthorized", statuses[brokenID].Message)
+	require.False(t, statuses[brokenID].Checked.IsZero())
+
+	statuses, err = s.GetStatuses(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	require.Equal(t, "OK", statuses[otherOrgID].Status)
+
+	t.Run("updates the status of the data sources and deletes the status of the deleted ones", func(t *testing.T) {
+		backendPM.results["broken"] = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+		require.NoError(t, sqlStore.DeleteDataSource(&models.DeleteDataSourceCommand{ID: healthyID, OrgID: 1}))
+
+		require.NoError(t, s.checkAll(context.Background()))
+
+		statuses, err := s.GetStatuses(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		require.Equal(t, "OK", statuses[brokenID].Status)
+		require.Empty(t, statuses[brokenID].Message)
+	})
+}
+
+type fakePluginManager struct {
+	plugins.Manager
+}
+
+func (pm *fakePluginManager) GetDataSource(id string) *plugins.DataSourcePlugin {
+	if id == "frontend" {
+		return &plugins.DataSourcePlugin{FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: id}}}
+	}
+	return &plugins.DataSourcePlugin{
+		FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: id}},
+		Backend:            true,
+	}
+}
+
+type fakeBackendPluginManager struct {
+	backendplugin.Manager
+	results map[string]*backend.CheckHealthResult
+}
+
+func (m *fakeBackendPluginManager) CheckHealth(_ context.Context, pCtx backend.PluginContext) (*backend.CheckHealthResult, error) {
+	res, ok := m.results[pCtx.DataSourceInstanceSettings.Name]
+	if !ok {
+		return nil, backendplugin.ErrMethodNotImplemented
+	}
+	return res, nil
+}
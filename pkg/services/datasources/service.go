@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,7 +15,9 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources/tenancy"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/azcredentials"
@@ -24,6 +27,7 @@ type Service struct {
 	Bus               bus.Bus
 	SQLStore          *sqlstore.SQLStore
 	EncryptionService encryption.Service
+	SecretsService    secrets.Service
 
 	ptc               proxyTransportCache
 	dsDecryptionCache secureJSONDecryptionCache
@@ -49,11 +53,13 @@ type cachedDecryptedJSON struct {
 	json    map[string]string
 }
 
-func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, encryptionService encryption.Service) *Service {
+func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, encryptionService encryption.Service,
+	secretsService secrets.Service) *Service {
 	s := &Service{
 		Bus:               bus,
 		SQLStore:          store,
 		EncryptionService: encryptionService,
+		SecretsService:    secretsService,
 		ptc: proxyTransportCache{
 			cache: make(map[int64]cachedRoundTripper),
 		},
@@ -87,7 +93,13 @@ func (s *Service) GetDataSourcesByType(query *models.GetDataSourcesByTypeQuery)
 
 func (s *Service) AddDataSource(ctx context.Context, cmd *models.AddDataSourceCommand) error {
 	var err error
-	cmd.EncryptedSecureJsonData, err = s.EncryptionService.EncryptJsonData(ctx, cmd.SecureJsonData, setting.SecretKey)
+	ctx = secrets.WithAuditSecret(ctx, secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: cmd.OrgId,
+		UID:   cmd.Uid,
+		Name:  cmd.Name,
+	})
+	cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
 	if err != nil {
 		return err
 	}
@@ -96,17 +108,42 @@ func (s *Service) AddDataSource(ctx context.Context, cmd *models.AddDataSourceCo
 }
 
 func (s *Service) DeleteDataSource(cmd *models.DeleteDataSourceCommand) error {
-	return s.SQLStore.DeleteDataSource(cmd)
+	if err := s.SQLStore.DeleteDataSource(cmd); err != nil {
+		return err
+	}
+
+	s.SecretsService.InvalidateDecryptionCache(secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: cmd.OrgID,
+		UID:   cmd.UID,
+		Name:  cmd.Name,
+	})
+	return nil
 }
 
 func (s *Service) UpdateDataSource(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
 	var err error
-	cmd.EncryptedSecureJsonData, err = s.EncryptionService.EncryptJsonData(ctx, cmd.SecureJsonData, setting.SecretKey)
+	ctx = secrets.WithAuditSecret(ctx, secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: cmd.OrgId,
+		UID:   cmd.Uid,
+		Name:  cmd.Name,
+	})
+	cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
 	if err != nil {
 		return err
 	}
 
-	return s.SQLStore.UpdateDataSource(cmd)
+	if err := s.SQLStore.UpdateDataSource(cmd); err != nil {
+		return err
+	}
+
+	s.SecretsService.InvalidateDecryptionCache(secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: cmd.OrgId,
+		UID:   cmd.Result.Uid,
+	})
+	return nil
 }
 
 func (s *Service) GetDefaultDataSource(query *models.GetDefaultDataSourceQuery) error {
@@ -170,7 +207,14 @@ func (s *Service) DecryptedValues(ds *models.DataSource) map[string]string {
 		return item.json
 	}
 
-	json, err := s.EncryptionService.DecryptJsonData(context.Background(), ds.SecureJsonData, setting.SecretKey)
+	ctx := secrets.WithAuditSecret(context.Background(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindDataSource,
+		OrgID: ds.OrgId,
+		UID:   ds.Uid,
+		Name:  ds.Name,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{Service: "datasources"})
+	json, err := s.SecretsService.DecryptJsonData(ctx, ds.SecureJsonData)
 	if err != nil {
 		return map[string]string{}
 	}
@@ -219,7 +263,7 @@ func (s *Service) httpClientOptions(ds *models.DataSource) (*sdkhttpclient.Optio
 	}
 	opts := &sdkhttpclient.Options{
 		Timeouts: timeouts,
-		Headers:  s.getCustomHeaders(ds.JsonData, s.DecryptedValues(ds)),
+		Headers:  s.getHeaders(ds),
 		Labels: map[string]string{
 			"datasource_name": ds.Name,
 			"datasource_uid":  ds.Uid,
@@ -333,6 +377,26 @@ func (s *Service) getTimeout(ds *models.DataSource) time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// getHeaders returns the custom headers of the data source, and its tenant header if it has one.
+func (s *Service) getHeaders(ds *models.DataSource) map[string]string {
+	headers := s.getCustomHeaders(ds.JsonData, s.DecryptedValues(ds))
+	if ds.JsonData == nil || s.SQLStore == nil {
+		return headers
+	}
+
+	name, value, ok := tenancy.Header(s.SQLStore.Cfg, ds.OrgId, ds.JsonData.MustMap())
+	if !ok {
+		return headers
+	}
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			delete(headers, k)
+		}
+	}
+	headers[name] = value
+	return headers
+}
+
 // getCustomHeaders returns a map with all the to be set headers
 // The map key represents the HeaderName and the value represents this header's value
 func (s *Service) getCustomHeaders(jsonData *simplejson.Json, decryptedValues map[string]string) map[string]string {
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/azcredentials"
@@ -24,7 +25,7 @@ import (
 func TestService(t *testing.T) {
 	sqlStore := sqlstore.InitTestDB(t)
 
-	s := ProvideService(bus.New(), sqlStore, ossencryption.ProvideService())
+	s := ProvideService(bus.New(), sqlStore, ossencryption.ProvideService(), secretsManager.SetupTestService(t, nil))
 
 	origSecret := setting.SecretKey
 	setting.SecretKey = "datasources_service_test"
@@ -44,7 +45,8 @@ func TestService(t *testing.T) {
 		require.NoError(t, err)
 
 		ds = cmd.Result
-		decrypted, err := s.EncryptionService.DecryptJsonData(ctx, ds.SecureJsonData, setting.SecretKey)
+		require.Equal(t, byte('#'), ds.SecureJsonData["password"][0], "secrets should be encrypted with a data key")
+		decrypted, err := s.SecretsService.DecryptJsonData(ctx, ds.SecureJsonData)
 		require.NoError(t, err)
 		require.Equal(t, sjd, decrypted)
 	})
@@ -56,7 +58,8 @@ func TestService(t *testing.T) {
 		err := s.UpdateDataSource(ctx, &cmd)
 		require.NoError(t, err)
 
-		decrypted, err := s.EncryptionService.DecryptJsonData(ctx, cmd.Result.SecureJsonData, setting.SecretKey)
+		require.Equal(t, byte('#'), cmd.Result.SecureJsonData["password"][0], "secrets should be encrypted with a data key")
+		decrypted, err := s.SecretsService.DecryptJsonData(ctx, cmd.Result.SecureJsonData)
 		require.NoError(t, err)
 		require.Equal(t, sjd, decrypted)
 	})
@@ -78,7 +81,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 			Type: "Kubernetes",
 		}
 
-		dsService := ProvideService(bus.New(), nil, ossencryption.ProvideService())
+		dsService := ProvideService(bus.New(), nil, ossencryption.ProvideService(), secretsManager.SetupTestService(t, nil))
 
 		rt1, err := dsService.GetHTTPTransport(&ds, provider)
 		require.NoError(t, err)
@@ -111,7 +114,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsAuthWithCACert", true)
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		tlsCaCert, err := encryptionService.Encrypt(context.Background(), []byte(caCert), "password")
 		require.NoError(t, err)
@@ -161,7 +164,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsAuth", true)
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		tlsClientCert, err := encryptionService.Encrypt(context.Background(), []byte(clientCert), "password")
 		require.NoError(t, err)
@@ -204,7 +207,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("serverName", "server-name")
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		tlsCaCert, err := encryptionService.Encrypt(context.Background(), []byte(caCert), "password")
 		require.NoError(t, err)
@@ -241,7 +244,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsSkipVerify", true)
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -272,7 +275,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		})
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		encryptedData, err := encryptionService.Encrypt(context.Background(), []byte(`Bearer xf5yhfkpsnmgo`), setting.SecretKey)
 		require.NoError(t, err)
@@ -331,7 +334,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		})
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		ds := models.DataSource{
 			Id:       1,
@@ -364,7 +367,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		require.NoError(t, err)
 
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		ds := models.DataSource{
 			Type:     models.DS_ES,
@@ -398,7 +401,7 @@ func TestService_getTimeout(t *testing.T) {
 	}
 
 	encryptionService := ossencryption.ProvideService()
-	dsService := ProvideService(bus.New(), nil, encryptionService)
+	dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 	for _, tc := range testCases {
 		ds := &models.DataSource{
@@ -411,7 +414,7 @@ func TestService_getTimeout(t *testing.T) {
 func TestService_DecryptedValue(t *testing.T) {
 	t.Run("When datasource hasn't been updated, encrypted JSON should be fetched from cache", func(t *testing.T) {
 		encryptionService := ossencryption.ProvideService()
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		encryptedJsonData, err := encryptionService.EncryptJsonData(
 			context.Background(),
@@ -465,7 +468,7 @@ func TestService_DecryptedValue(t *testing.T) {
 			SecureJsonData: encryptedJsonData,
 		}
 
-		dsService := ProvideService(bus.New(), nil, encryptionService)
+		dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		// Populate cache
 		password, ok := dsService.DecryptedValue(&ds, "password")
@@ -501,7 +504,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			t.Cleanup(func() { ds.JsonData = emptyJsonData; ds.SecureJsonData = emptySecureJsonData })
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -518,7 +521,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -538,7 +541,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -562,7 +565,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -580,7 +583,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			_, err := dsService.httpClientOptions(&ds)
 			assert.Error(t, err)
@@ -594,7 +597,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			encryptionService := ossencryption.ProvideService()
-			dsService := ProvideService(bus.New(), nil, encryptionService)
+			dsService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -0,0 +1,78 @@
+// Package tenancy resolves the tenant header sent to multi-tenant data sources, such as Cortex, Mimir or Loki, for
+// the Grafana organization the data source belongs to.
+package tenancy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// HeaderNameKey is the key of the JSON data of the data source with the name of the tenant header,
+	// e.g. X-Scope-OrgID. No tenant header is sent if it's empty.
+	HeaderNameKey = "tenantHeaderName"
+	// SourceKey is the key of the JSON data of the data source with the source of the tenant ID.
+	SourceKey = "tenantIdSource"
+)
+
+const (
+	// SourceOrg sends the ID of the organization as tenant ID. It's the default source.
+	SourceOrg = "org"
+	// SourceMapping sends the tenant ID mapped to the organization in the [datasources.tenants] section of the
+	// configuration.
+	SourceMapping = "mapping"
+)
+
+// Header returns the name and the value of the tenant header of a data source of an organization, and false if the
+// data source has no tenant header, or if no tenant ID is mapped to the organization.
+func Header(cfg *setting.Cfg, orgID int64, jsonData map[string]interface{}) (string, string, bool) {
+	name, _ := jsonData[HeaderNameKey].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", false
+	}
+
+	source, _ := jsonData[SourceKey].(string)
+	switch source {
+	case "", SourceOrg:
+		return name, strconv.FormatInt(orgID, 10), true
+	case SourceMapping:
+		if cfg == nil {
+			return "", "", false
+		}
+		tenantID, ok := cfg.DataSourceTenants[orgID]
+		if !ok || tenantID == "" {
+			return "", "", false
+		}
+		return name, tenantID, true
+	default:
+		return "", "", false
+	}
+}
+
+// ApplyToCustomHeaders sets the tenant header in the custom HTTP headers of the JSON data and the decrypted secure
+// JSON data of a data source, i.e. the httpHeaderName<n> and httpHeaderValue<n> keys, replacing a custom header with
+// the same name. Both maps are modified. It returns false if the data source has no tenant header.
+func ApplyToCustomHeaders(cfg *setting.Cfg, orgID int64, jsonData map[string]interface{},
+	secureJSONData map[string]string) bool {
+	name, value, ok := Header(cfg, orgID, jsonData)
+	if !ok {
+		return false
+	}
+
+	index := 1
+	for {
+		headerName, exists := jsonData[fmt.Sprintf("httpHeaderName%d", index)].(string)
+		if !exists || strings.EqualFold(headerName, name) {
+			break
+		}
+		index++
+	}
+
+	jsonData[fmt.Sprintf("httpHeaderName%d", index)] = name
+	secureJSONData[fmt.Sprintf("httpHeaderValue%d", index)] = value
+	return true
+}
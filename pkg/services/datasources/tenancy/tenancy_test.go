@@ -0,0 +1,93 @@
+package tenancy
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	cfg := &setting.Cfg{DataSourceTenants: map[int64]string{2: "tenant-b"}}
+
+	tcs := []struct {
+		desc          string
+		orgID         int64
+		jsonData      map[string]interface{}
+		expectedName  string
+		expectedValue string
+		expectedOK    bool
+	}{
+		{
+			desc:     "no tenant header",
+			orgID:    1,
+			jsonData: map[string]interface{}{},
+		},
+		{
+			desc:          "organization ID by default",
+			orgID:         1,
+			jsonData:      map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID"},
+			expectedName:  "X-Scope-OrgID",
+			expectedValue: "1",
+			expectedOK:    true,
+		},
+		{
+			desc:          "mapped tenant ID",
+			orgID:         2,
+			jsonData:      map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID", "tenantIdSource": "mapping"},
+			expectedName:  "X-Scope-OrgID",
+			expectedValue: "tenant-b",
+			expectedOK:    true,
+		},
+		{
+			desc:     "unmapped organization",
+			orgID:    3,
+			jsonData: map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID", "tenantIdSource": "mapping"},
+		},
+		{
+			desc:     "unknown source",
+			orgID:    1,
+			jsonData: map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID", "tenantIdSource": "user"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			name, value, ok := Header(cfg, tc.orgID, tc.jsonData)
+			require.Equal(t, tc.expectedOK, ok)
+			require.Equal(t, tc.expectedName, name)
+			require.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestApplyToCustomHeaders(t *testing.T) {
+	cfg := &setting.Cfg{}
+
+	t.Run("adds the tenant header after the custom headers", func(t *testing.T) {
+		jsonData := map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID", "httpHeaderName1": "X-Custom"}
+		secureJSONData := map[string]string{"httpHeaderValue1": "custom"}
+
+		require.True(t, ApplyToCustomHeaders(cfg, 1, jsonData, secureJSONData))
+		require.Equal(t, "X-Scope-OrgID", jsonData["httpHeaderName2"])
+		require.Equal(t, map[string]string{"httpHeaderValue1": "custom", "httpHeaderValue2": "1"}, secureJSONData)
+	})
+
+	t.Run("replaces a custom header with the same name", func(t *testing.T) {
+		jsonData := map[string]interface{}{"tenantHeaderName": "X-Scope-OrgID", "httpHeaderName1": "x-scope-orgid"}
+		secureJSONData := map[string]string{"httpHeaderValue1": "spoofed"}
+
+		require.True(t, ApplyToCustomHeaders(cfg, 1, jsonData, secureJSONData))
+		require.Equal(t, "X-Scope-OrgID", jsonData["httpHeaderName1"])
+		require.Equal(t, map[string]string{"httpHeaderValue1": "1"}, secureJSONData)
+	})
+
+	t.Run("does nothing without tenant header", func(t *testing.T) {
+		jsonData := map[string]interface{}{}
+		secureJSONData := map[string]string{}
+
+		require.False(t, ApplyToCustomHeaders(cfg, 1, jsonData, secureJSONData))
+		require.Empty(t, jsonData)
+		require.Empty(t, secureJSONData)
+	})
+}
@@ -0,0 +1,92 @@
+// Package featuretoggles provides access to feature toggles that can be changed while Grafana is running.
+package featuretoggles
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const configSection = "feature_toggles"
+
+// Service holds the current state of the feature toggles. It is initialized from the
+// [feature_toggles] configuration section and can be updated at runtime, either through
+// the admin API or by a settings provider reloading the configuration section.
+type Service struct {
+	log log.Logger
+
+	mu      sync.RWMutex
+	enabled map[string]bool
+}
+
+func ProvideService(cfg *setting.Cfg, settingsProvider setting.Provider) *Service {
+	s := New(cfg.FeatureToggles)
+	settingsProvider.RegisterReloadHandler(configSection, s)
+	return s
+}
+
+// New returns a feature toggle service with the provided toggles as initial state.
+func New(toggles map[string]bool) *Service {
+	enabled := make(map[string]bool, len(toggles))
+	for name, value := range toggles {
+		enabled[name] = value
+	}
+
+	return &Service{
+		log:     log.New("featuretoggles"),
+		enabled: enabled,
+	}
+}
+
+// IsEnabled returns whether the feature toggle with the provided name is enabled.
+func (s *Service) IsEnabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.enabled[name]
+}
+
+// GetToggles returns a copy of the current feature toggles.
+func (s *Service) GetToggles() map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	toggles := make(map[string]bool, len(s.enabled))
+	for name, value := range s.enabled {
+		toggles[name] = value
+	}
+	return toggles
+}
+
+// SetToggles updates the provided feature toggles, leaving other toggles untouched.
+func (s *Service) SetToggles(toggles map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, value := range toggles {
+		s.log.Info("Feature toggle updated", "name", name, "enabled", value)
+		s.enabled[name] = value
+	}
+}
+
+// Reload replaces the feature toggles with the ones listed in the reloaded configuration section.
+func (s *Service) Reload(section setting.Section) error {
+	enabled := make(map[string]bool)
+	for _, name := range util.SplitString(section.KeyValue("enable").MustString("")) {
+		enabled[name] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log.Info("Feature toggles reloaded", "enabled", len(enabled))
+	s.enabled = enabled
+	return nil
+}
+
+// Validate validates the feature toggles configuration section. Any list of toggle names is valid.
+func (s *Service) Validate(setting.Section) error {
+	return nil
+}
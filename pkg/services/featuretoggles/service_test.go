@@ -0,0 +1,41 @@
+package featuretoggles
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestService(t *testing.T) {
+	initial := map[string]bool{"live": true}
+	s := New(initial)
+
+	require.True(t, s.IsEnabled("live"))
+	require.False(t, s.IsEnabled("tempoSearch"))
+
+	t.Run("Runtime updates are reflected without touching the initial toggles", func(t *testing.T) {
+		s.SetToggles(map[string]bool{"live": false, "tempoSearch": true})
+
+		require.Equal(t, map[string]bool{"live": false, "tempoSearch": true}, s.GetToggles())
+		require.Equal(t, map[string]bool{"live": true}, initial)
+	})
+
+	t.Run("Returned toggles are a copy", func(t *testing.T) {
+		toggles := s.GetToggles()
+		toggles["live"] = true
+		require.False(t, s.IsEnabled("live"))
+	})
+
+	t.Run("Reload replaces toggles with the configured ones", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.Raw = ini.Empty()
+		_, err := cfg.Raw.Section(configSection).NewKey("enable", "ngalert, accesscontrol")
+		require.NoError(t, err)
+
+		provider := setting.ProvideProvider(cfg)
+		require.NoError(t, s.Reload(provider.Section(configSection)))
+		require.Equal(t, map[string]bool{"ngalert": true, "accesscontrol": true}, s.GetToggles())
+	})
+}
@@ -82,7 +82,8 @@ func (r *PluginPathRunner) OnSubscribe(ctx context.Context, user *models.SignedI
 		return models.SubscribeReply{}, resp.Status, nil
 	}
 
-	submitResult, err := r.runStreamManager.SubmitStream(ctx, user, orgchannel.PrependOrgID(user.OrgId, e.Channel), r.path, pCtx, r.handler, false)
+	channel := orgchannel.PrependOrgID(user.OrgId, e.Channel)
+	submitResult, err := r.runStreamManager.SubmitStream(ctx, user, channel, r.path, pCtx, r.handler, false)
 	if err != nil {
 		logger.Error("Error submitting stream to manager", "error", err, "path", r.path)
 		return models.SubscribeReply{}, 0, centrifuge.ErrorInternal
@@ -98,6 +99,9 @@ func (r *PluginPathRunner) OnSubscribe(ctx context.Context, user *models.SignedI
 	}
 	if resp.InitialData != nil {
 		reply.Data = resp.InitialData.Data()
+	} else if data, ok := r.runStreamManager.ReplayFrame(channel); ok {
+		// the new subscriber gets the last frames of the running stream instead of waiting for the next one
+		reply.Data = data
 	}
 	return reply, backend.SubscribeStreamStatusOK, nil
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/manager"
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
@@ -63,7 +64,7 @@ type CoreGrafanaScope struct {
 func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, routeRegister routing.RouteRegister,
 	logsService *cloudwatch.LogsService, pluginManager *manager.PluginManager, cacheService *localcache.CacheService,
 	dataSourceCache datasources.CacheService, sqlStore *sqlstore.SQLStore,
-	usageStatsService usagestats.Service) (*GrafanaLive, error) {
+	usageStatsService usagestats.Service, accessControl accesscontrol.AccessControl) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		PluginContextProvider: plugCtxProvider,
@@ -73,11 +74,16 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		CacheService:          cacheService,
 		DataSourceCache:       dataSourceCache,
 		SQLStore:              sqlStore,
+		AccessControl:         accessControl,
 		channels:              make(map[string]models.ChannelHandler),
 		GrafanaScope: CoreGrafanaScope{
 			Features: make(map[string]models.ChannelHandlerFactory),
 		},
 		usageStatsService: usageStatsService,
+		pluginSubscribeLimiters: newPluginChannelLimiters(
+			cfg.LivePluginSubscribeRateLimitPerUser, cfg.LivePluginSubscribeRateLimitPerChannel),
+		pluginPublishLimiters: newPluginChannelLimiters(
+			cfg.LivePluginPublishRateLimitPerUser, cfg.LivePluginPublishRateLimitPerChannel),
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -217,7 +223,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
-	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter)
+	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter,
+		runstream.WithReplayFrames(g.Cfg.LivePluginStreamReplayFrames))
 
 	// Initialize the main features
 	dash := &features.DashboardHandler{
@@ -365,6 +372,7 @@ type GrafanaLive struct {
 	CacheService          *localcache.CacheService
 	DataSourceCache       datasources.CacheService
 	SQLStore              *sqlstore.SQLStore
+	AccessControl         accesscontrol.AccessControl
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -390,6 +398,11 @@ type GrafanaLive struct {
 
 	usageStatsService usagestats.Service
 	usageStats        usageStats
+
+	pluginSubscribeLimiters    *pluginChannelLimiters
+	pluginPublishLimiters      *pluginChannelLimiters
+	pluginChannelAuthorizers   map[string]PluginChannelAuthorizer
+	pluginChannelAuthorizersMu sync.RWMutex
 }
 
 func (g *GrafanaLive) getStreamPlugin(pluginID string) (backend.StreamHandler, error) {
@@ -603,6 +616,15 @@ func (g *GrafanaLive) handleOnSubscribe(client *centrifuge.Client, e centrifuge.
 			logger.Error("Error getting channel handler", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
 			return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
 		}
+		code, err := g.checkPluginChannelAccess(client.Context(), user, addr, PluginChannelActionSubscribe)
+		if err != nil {
+			logger.Error("Error checking plugin channel access", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+			return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+		}
+		if code != 0 {
+			logger.Debug("Plugin channel subscribe rejected", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "code", code)
+			return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: http.StatusText(code)}
+		}
 		reply, status, err = handler.OnSubscribe(client.Context(), user, models.SubscribeEvent{
 			Channel: channel,
 			Path:    addr.Path,
@@ -695,6 +717,15 @@ func (g *GrafanaLive) handleOnPublish(client *centrifuge.Client, e centrifuge.Pu
 		logger.Error("Error getting channel handler", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
 		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
 	}
+	code, err := g.checkPluginChannelAccess(client.Context(), user, addr, PluginChannelActionPublish)
+	if err != nil {
+		logger.Error("Error checking plugin channel access", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+	}
+	if code != 0 {
+		logger.Debug("Plugin channel publish rejected", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "code", code)
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: http.StatusText(code)}
+	}
 	reply, status, err := handler.OnPublish(client.Context(), user, models.PublishEvent{
 		Channel: channel,
 		Path:    addr.Path,
@@ -922,6 +953,14 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *models.ReqContext, cmd dtos.LivePub
 		logger.Error("Error getting channels handler", "error", err, "channel", cmd.Channel)
 		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
 	}
+	code, err := g.checkPluginChannelAccess(ctx.Req.Context(), user, addr, PluginChannelActionPublish)
+	if err != nil {
+		logger.Error("Error checking plugin channel access", "error", err, "channel", cmd.Channel)
+		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	}
+	if code != 0 {
+		return response.Error(code, http.StatusText(code), nil)
+	}
 
 	reply, status, err := channelHandler.OnPublish(ctx.Req.Context(), ctx.SignedInUser, models.PublishEvent{Channel: cmd.Channel, Path: addr.Path, Data: cmd.Data})
 	if err != nil {
@@ -0,0 +1,220 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiterIdleTimeout is how long the rate limiter of a user or a channel is kept after its last use. A limiter
+// unused for that long has its full burst available again, so dropping it doesn't change the limits.
+const rateLimiterIdleTimeout = time.Minute
+
+var timeNow = time.Now
+
+// PluginChannelAction is the action a user attempts on a channel of a plugin.
+type PluginChannelAction string
+
+const (
+	PluginChannelActionSubscribe PluginChannelAction = "subscribe"
+	PluginChannelActionPublish   PluginChannelAction = "publish"
+)
+
+// PluginChannelAuthorizer decides whether the user can subscribe or publish to a channel of plugin or data source
+// scope, before the plugin handles the subscription or the publication.
+type PluginChannelAuthorizer func(ctx context.Context, user *models.SignedInUser, channel live.Channel, action PluginChannelAction) (bool, error)
+
+// RegisterPluginChannelAuthorizer registers the authorizer of the channels of the plugin, and of the data sources of
+// the plugin. It replaces the authorizer previously registered for the plugin, if any.
+func (g *GrafanaLive) RegisterPluginChannelAuthorizer(pluginID string, authorizer PluginChannelAuthorizer) {
+	g.pluginChannelAuthorizersMu.Lock()
+	defer g.pluginChannelAuthorizersMu.Unlock()
+
+	if g.pluginChannelAuthorizers == nil {
+		g.pluginChannelAuthorizers = map[string]PluginChannelAuthorizer{}
+	}
+	g.pluginChannelAuthorizers[pluginID] = authorizer
+}
+
+func (g *GrafanaLive) getPluginChannelAuthorizer(pluginID string) (PluginChannelAuthorizer, bool) {
+	g.pluginChannelAuthorizersMu.RLock()
+	defer g.pluginChannelAuthorizersMu.RUnlock()
+
+	authorizer, ok := g.pluginChannelAuthorizers[pluginID]
+	return authorizer, ok
+}
+
+func (g *GrafanaLive) hasPluginChannelAuthorizers() bool {
+	g.pluginChannelAuthorizersMu.RLock()
+	defer g.pluginChannelAuthorizersMu.RUnlock()
+
+	return len(g.pluginChannelAuthorizers) > 0
+}
+
+// pluginChannelLimiters limits the rate of an action on the channels of plugin and data source scope, per user and
+// per channel.
+type pluginChannelLimiters struct {
+	perUser    *keyedRateLimiter
+	perChannel *keyedRateLimiter
+}
+
+func newPluginChannelLimiters(perUser, perChannel int) *pluginChannelLimiters {
+	return &pluginChannelLimiters{
+		perUser:    newKeyedRateLimiter(perUser),
+		perChannel: newKeyedRateLimiter(perChannel),
+	}
+}
+
+// allow returns whether the user can act on the channel, which must include the organization ID. Anonymous users of
+// an organization share their limit.
+func (l *pluginChannelLimiters) allow(user *models.SignedInUser, orgChannel string) bool {
+	if l == nil {
+		return true
+	}
+	return l.perUser.allow(fmt.Sprintf("%d/%d", user.OrgId, user.UserId)) && l.perChannel.allow(orgChannel)
+}
+
+// keyedRateLimiter allows an average of a number of events per second for each key, with bursts of the same number
+// of events.
+type keyedRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu          sync.Mutex
+	limiters    map[string]*keyedLimiter
+	lastCleanup time.Time
+}
+
+type keyedLimiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+// newKeyedRateLimiter returns nil, allowing all the events, if perSecond isn't positive.
+func newKeyedRateLimiter(perSecond int) *keyedRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &keyedRateLimiter{
+		limit:       rate.Limit(perSecond),
+		burst:       perSecond,
+		limiters:    map[string]*keyedLimiter{},
+		lastCleanup: timeNow(),
+	}
+}
+
+func (l *keyedRateLimiter) allow(key string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := timeNow()
+	if now.Sub(l.lastCleanup) > rateLimiterIdleTimeout {
+		for k, limiter := range l.limiters {
+			if now.Sub(limiter.lastUsed) > rateLimiterIdleTimeout {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &keyedLimiter{Limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastUsed = now
+	return limiter.AllowN(now, 1)
+}
+
+// checkPluginChannelAccess applies the rate limits, the Live channels declared by the plugin and the authorizer of the
+// plugin to an action on a channel of plugin or data source scope. It returns the HTTP status code to reply with if the
+// action isn't allowed, or 0 if it is. Channels of other scopes are always allowed.
+func (g *GrafanaLive) checkPluginChannelAccess(ctx context.Context, user *models.SignedInUser, addr live.Channel, action PluginChannelAction) (int, error) {
+	if addr.Scope != live.ScopePlugin && addr.Scope != live.ScopeDatasource {
+		return 0, nil
+	}
+
+	limiters := g.pluginPublishLimiters
+	if action == PluginChannelActionSubscribe {
+		limiters = g.pluginSubscribeLimiters
+	}
+	if !limiters.allow(user, orgchannel.PrependOrgID(user.OrgId, addr.String())) {
+		return http.StatusTooManyRequests, nil
+	}
+
+	if g.PluginManager == nil && !g.hasPluginChannelAuthorizers() {
+		return 0, nil
+	}
+	pluginID := addr.Namespace
+	if addr.Scope == live.ScopeDatasource {
+		ds, err := g.DataSourceCache.GetDatasourceByUID(addr.Namespace, user, false)
+		if err != nil {
+			return 0, fmt.Errorf("error getting datasource: %w", err)
+		}
+		pluginID = ds.Type
+	}
+
+	if g.PluginManager != nil {
+		if plugin := g.PluginManager.GetPlugin(pluginID); plugin != nil {
+			allowed, err := g.checkPluginLiveChannels(ctx, user, plugin, addr, action)
+			if err != nil {
+				return 0, fmt.Errorf("error checking %s access: %w", action, err)
+			}
+			if !allowed {
+				return http.StatusForbidden, nil
+			}
+		}
+	}
+
+	authorizer, ok := g.getPluginChannelAuthorizer(pluginID)
+	if !ok {
+		return 0, nil
+	}
+	allowed, err := authorizer(ctx, user, addr, action)
+	if err != nil {
+		return 0, fmt.Errorf("error authorizing %s: %w", action, err)
+	}
+	if !allowed {
+		return http.StatusForbidden, nil
+	}
+	return 0, nil
+}
+
+// checkPluginLiveChannels returns whether the action on the channel matches one of the Live channels declared by the
+// plugin, and the user has the role or the action required by the channel. The channels of plugins not declaring Live
+// channels are allowed, unless Live channels are required for external plugins.
+func (g *GrafanaLive) checkPluginLiveChannels(ctx context.Context, user *models.SignedInUser, plugin *plugins.PluginBase, addr live.Channel, action PluginChannelAction) (bool, error) {
+	if len(plugin.LiveChannels) == 0 {
+		return !g.Cfg.PluginsRequireLiveChannels || plugin.IsCorePlugin, nil
+	}
+
+	channel := plugins.MatchLiveChannel(plugin.LiveChannels, addr.Path)
+	if channel == nil {
+		return false, nil
+	}
+	access := channel.Publish
+	if action == PluginChannelActionSubscribe {
+		access = channel.Subscribe
+	}
+	if access == nil {
+		return false, nil
+	}
+
+	if access.Action != "" && g.AccessControl != nil && !g.AccessControl.IsDisabled() {
+		return g.AccessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(access.Action))
+	}
+	return !access.Role.IsValid() || user.HasRole(access.Role), nil
+}
@@ -0,0 +1,164 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedRateLimiter(t *testing.T) {
+	t.Cleanup(func() { timeNow = time.Now })
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	l := newKeyedRateLimiter(2)
+	require.True(t, l.allow("a"))
+	require.True(t, l.allow("a"))
+	require.False(t, l.allow("a"))
+	require.True(t, l.allow("b"))
+
+	now = now.Add(time.Second)
+	require.True(t, l.allow("a"))
+
+	now = now.Add(2 * rateLimiterIdleTimeout)
+	require.True(t, l.allow("b"))
+	require.Len(t, l.limiters, 1)
+
+	var disabled *keyedRateLimiter
+	require.Nil(t, newKeyedRateLimiter(0))
+	require.True(t, disabled.allow("a"))
+}
+
+func TestCheckPluginChannelAccess(t *testing.T) {
+	user := &models.SignedInUser{OrgId: 1, UserId: 1}
+	pluginChannel := live.Channel{Scope: live.ScopePlugin, Namespace: "test-app", Path: "stream"}
+	grafanaChannel := live.Channel{Scope: live.ScopeGrafana, Namespace: "dashboard", Path: "uid/abc"}
+
+	t.Run("Should allow the channels by default", func(t *testing.T) {
+		g := &GrafanaLive{}
+
+		code, err := g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Zero(t, code)
+	})
+
+	t.Run("Should rate limit the publications per user and per channel", func(t *testing.T) {
+		g := &GrafanaLive{pluginPublishLimiters: newPluginChannelLimiters(1, 2)}
+		other := &models.SignedInUser{OrgId: 1, UserId: 2}
+		third := &models.SignedInUser{OrgId: 1, UserId: 3}
+
+		code, err := g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Zero(t, code)
+		code, err = g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, code)
+
+		code, err = g.checkPluginChannelAccess(context.Background(), other, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Zero(t, code)
+		code, err = g.checkPluginChannelAccess(context.Background(), third, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, code)
+
+		code, err = g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionSubscribe)
+		require.NoError(t, err)
+		require.Zero(t, code)
+		code, err = g.checkPluginChannelAccess(context.Background(), user, grafanaChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Zero(t, code)
+	})
+
+	t.Run("Should check the authorizer of the plugin", func(t *testing.T) {
+		g := &GrafanaLive{}
+		var actions []PluginChannelAction
+		g.RegisterPluginChannelAuthorizer("test-app", func(_ context.Context, _ *models.SignedInUser, channel live.Channel, action PluginChannelAction) (bool, error) {
+			actions = append(actions, action)
+			return action == PluginChannelActionSubscribe, nil
+		})
+
+		code, err := g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionSubscribe)
+		require.NoError(t, err)
+		require.Zero(t, code)
+		code, err = g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, code)
+		require.Equal(t, []PluginChannelAction{PluginChannelActionSubscribe, PluginChannelActionPublish}, actions)
+
+		otherPlugin := live.Channel{Scope: live.ScopePlugin, Namespace: "other-app", Path: "stream"}
+		code, err = g.checkPluginChannelAccess(context.Background(), user, otherPlugin, PluginChannelActionPublish)
+		require.NoError(t, err)
+		require.Zero(t, code)
+	})
+
+	t.Run("Should return the errors of the authorizer", func(t *testing.T) {
+		g := &GrafanaLive{}
+		g.RegisterPluginChannelAuthorizer("test-app", func(context.Context, *models.SignedInUser, live.Channel, PluginChannelAction) (bool, error) {
+			return false, errors.New("failed")
+		})
+
+		_, err := g.checkPluginChannelAccess(context.Background(), user, pluginChannel, PluginChannelActionSubscribe)
+		require.Error(t, err)
+	})
+}
+
+func TestCheckPluginLiveChannels(t *testing.T) {
+	viewer := &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_VIEWER}
+	editor := &models.SignedInUser{OrgId: 1, UserId: 2, OrgRole: models.ROLE_EDITOR}
+	plugin := &plugins.PluginBase{Id: "test-app", LiveChannels: []*plugins.PluginLiveChannel{
+		{
+			Path:      "stream/*",
+			Subscribe: &plugins.PluginLiveChannelAccess{},
+			Publish:   &plugins.PluginLiveChannelAccess{Role: models.ROLE_EDITOR, Action: "test-app.streams:write"},
+		},
+	}}
+	stream := live.Channel{Scope: live.ScopePlugin, Namespace: "test-app", Path: "stream/cpu"}
+	other := live.Channel{Scope: live.ScopePlugin, Namespace: "test-app", Path: "other"}
+
+	check := func(t *testing.T, g *GrafanaLive, user *models.SignedInUser, p *plugins.PluginBase, addr live.Channel, action PluginChannelAction) bool {
+		t.Helper()
+		allowed, err := g.checkPluginLiveChannels(context.Background(), user, p, addr, action)
+		require.NoError(t, err)
+		return allowed
+	}
+
+	t.Run("Should check the role required by the channel", func(t *testing.T) {
+		g := &GrafanaLive{Cfg: setting.NewCfg(), AccessControl: accesscontrolmock.New().WithDisabled()}
+
+		require.True(t, check(t, g, viewer, plugin, stream, PluginChannelActionSubscribe))
+		require.False(t, check(t, g, viewer, plugin, stream, PluginChannelActionPublish))
+		require.True(t, check(t, g, editor, plugin, stream, PluginChannelActionPublish))
+		require.False(t, check(t, g, editor, plugin, other, PluginChannelActionSubscribe))
+	})
+
+	t.Run("Should check the action required by the channel with access control", func(t *testing.T) {
+		g := &GrafanaLive{Cfg: setting.NewCfg(), AccessControl: accesscontrolmock.New()}
+		require.False(t, check(t, g, editor, plugin, stream, PluginChannelActionPublish))
+
+		g.AccessControl = accesscontrolmock.New().WithPermissions([]*accesscontrol.Permission{
+			{Action: "test-app.streams:write"},
+		})
+		require.True(t, check(t, g, viewer, plugin, stream, PluginChannelActionPublish))
+	})
+
+	t.Run("Should require channels for external plugins when configured", func(t *testing.T) {
+		g := &GrafanaLive{Cfg: setting.NewCfg()}
+		undeclared := &plugins.PluginBase{Id: "other-app"}
+		require.True(t, check(t, g, viewer, undeclared, stream, PluginChannelActionPublish))
+
+		g.Cfg.PluginsRequireLiveChannels = true
+		require.False(t, check(t, g, viewer, undeclared, stream, PluginChannelActionPublish))
+		undeclared.IsCorePlugin = true
+		require.True(t, check(t, g, viewer, undeclared, stream, PluginChannelActionPublish))
+	})
+}
@@ -40,9 +40,13 @@ type StreamRunner interface {
 type packetSender struct {
 	channelLocalPublisher ChannelLocalPublisher
 	channel               string
+	replay                *replayBuffer
 }
 
 func (p *packetSender) Send(packet *backend.StreamPacket) error {
+	if p.replay != nil {
+		p.replay.add(packet.Data)
+	}
 	return p.channelLocalPublisher.PublishLocal(p.channel, packet.Data)
 }
 
@@ -60,11 +64,19 @@ type Manager struct {
 	checkInterval           time.Duration
 	maxChecks               int
 	datasourceCheckInterval time.Duration
+	replayFrames            int
 }
 
 // ManagerOption modifies Manager behavior (used for tests for example).
 type ManagerOption func(*Manager)
 
+// WithReplayFrames enables the replay of the last frames of each stream to the new subscribers of its channel.
+func WithReplayFrames(frames int) ManagerOption {
+	return func(sm *Manager) {
+		sm.replayFrames = frames
+	}
+}
+
 // WithCheckConfig allows setting custom check rules.
 func WithCheckConfig(interval time.Duration, maxChecks int) ManagerOption {
 	return func(sm *Manager) {
@@ -238,7 +250,7 @@ func getDelay(numErrors int) time.Duration {
 }
 
 // run stream until context canceled or stream finished without an error.
-func (s *Manager) runStream(ctx context.Context, cancelFn func(), sr streamRequest) {
+func (s *Manager) runStream(ctx context.Context, cancelFn func(), sr streamRequest, replay *replayBuffer) {
 	defer func() { s.stopStream(sr, cancelFn) }()
 	var numFastErrors int
 	var delay time.Duration
@@ -302,7 +314,7 @@ func (s *Manager) runStream(ctx context.Context, cancelFn func(), sr streamReque
 				PluginContext: pluginCtx,
 				Path:          sr.Path,
 			},
-			backend.NewStreamSender(&packetSender{channelLocalPublisher: s.channelSender, channel: sr.Channel}),
+			backend.NewStreamSender(&packetSender{channelLocalPublisher: s.channelSender, channel: sr.Channel, replay: replay}),
 		)
 		if err != nil {
 			if errors.Is(ctx.Err(), context.Canceled) {
@@ -324,6 +336,7 @@ type streamContext struct {
 	CloseCh       chan struct{}
 	cancelFn      func()
 	streamRequest streamRequest
+	replay        *replayBuffer
 }
 
 func (s *Manager) registerStream(ctx context.Context, sr submitRequest) {
@@ -336,10 +349,15 @@ func (s *Manager) registerStream(ctx context.Context, sr submitRequest) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	closeCh := make(chan struct{})
+	var replay *replayBuffer
+	if s.replayFrames > 0 {
+		replay = newReplayBuffer(s.replayFrames)
+	}
 	s.streams[sr.streamRequest.Channel] = streamContext{
 		CloseCh:       closeCh,
 		cancelFn:      cancel,
 		streamRequest: sr.streamRequest,
+		replay:        replay,
 	}
 	if sr.streamRequest.PluginContext.DataSourceInstanceSettings != nil {
 		dsUID := sr.streamRequest.PluginContext.DataSourceInstanceSettings.UID
@@ -352,7 +370,20 @@ func (s *Manager) registerStream(ctx context.Context, sr submitRequest) {
 	s.mu.Unlock()
 	sr.responseCh <- submitResponse{Result: submitResult{StreamExists: false, CloseNotify: closeCh}}
 	go s.watchStream(ctx, cancel, sr.streamRequest)
-	s.runStream(ctx, cancel, sr.streamRequest)
+	s.runStream(ctx, cancel, sr.streamRequest, replay)
+}
+
+// ReplayFrame returns the last frames of the running stream of the channel merged in a single data frame, with its
+// schema, to be sent to a new subscriber of the channel. It returns false if the replay is disabled, or no frame
+// with a schema was published since the stream started.
+func (s *Manager) ReplayFrame(channel string) ([]byte, bool) {
+	s.mu.RLock()
+	streamCtx, ok := s.streams[channel]
+	s.mu.RUnlock()
+	if !ok || streamCtx.replay == nil {
+		return nil, false
+	}
+	return streamCtx.replay.frame()
 }
 
 // Run Manager till context canceled.
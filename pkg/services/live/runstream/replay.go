@@ -0,0 +1,107 @@
+package runstream
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// framePacket is a data frame packet of a stream, i.e. a data frame in the JSON format of the SDK, with its schema
+// only sent when it changes.
+type framePacket struct {
+	Schema json.RawMessage            `json:"schema,omitempty"`
+	Data   map[string]json.RawMessage `json:"data,omitempty"`
+}
+
+type frameSchema struct {
+	Fields []json.RawMessage `json:"fields"`
+}
+
+// replayBuffer is a ring buffer of the last frames published by a stream, replayed to the new subscribers of its
+// channel so that they don't wait for the next frame. It only holds the frames since the last schema, since the
+// previous ones don't match it, and is reset when the stream publishes packets which aren't data frames, or data
+// frames which can't be merged, e.g. with nanosecond timestamps.
+type replayBuffer struct {
+	mu        sync.Mutex
+	schema    json.RawMessage
+	numFields int
+	frames    [][][]json.RawMessage
+	next      int
+	count     int
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{frames: make([][][]json.RawMessage, size)}
+}
+
+func (b *replayBuffer) add(data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var packet framePacket
+	if err := json.Unmarshal(data, &packet); err != nil || (packet.Schema == nil && packet.Data == nil) {
+		b.reset(nil, 0)
+		return
+	}
+	if packet.Schema != nil {
+		var schema frameSchema
+		if err := json.Unmarshal(packet.Schema, &schema); err != nil {
+			b.reset(nil, 0)
+			return
+		}
+		b.reset(packet.Schema, len(schema.Fields))
+	}
+	if b.schema == nil || packet.Data == nil {
+		return
+	}
+
+	var values [][]json.RawMessage
+	if rawValues, ok := packet.Data["values"]; !ok || len(packet.Data) != 1 ||
+		json.Unmarshal(rawValues, &values) != nil || len(values) != b.numFields {
+		b.reset(nil, 0)
+		return
+	}
+	b.frames[b.next] = values
+	b.next = (b.next + 1) % len(b.frames)
+	if b.count < len(b.frames) {
+		b.count++
+	}
+}
+
+func (b *replayBuffer) reset(schema json.RawMessage, numFields int) {
+	b.schema = schema
+	b.numFields = numFields
+	b.next = 0
+	b.count = 0
+	for i := range b.frames {
+		b.frames[i] = nil
+	}
+}
+
+// frame returns the buffered frames merged in a single data frame packet, with the schema, if any.
+func (b *replayBuffer) frame() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.schema == nil {
+		return nil, false
+	}
+	values := make([][]json.RawMessage, b.numFields)
+	for i := range values {
+		values[i] = []json.RawMessage{}
+	}
+	start := (b.next - b.count + len(b.frames)) % len(b.frames)
+	for i := 0; i < b.count; i++ {
+		for field, fieldValues := range b.frames[(start+i)%len(b.frames)] {
+			values[field] = append(values[field], fieldValues...)
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"schema": b.schema,
+		"data":   map[string]interface{}{"values": values},
+	})
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
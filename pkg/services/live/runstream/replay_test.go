@@ -0,0 +1,48 @@
+package runstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayBuffer(t *testing.T) {
+	const schema = `{"fields":[{"name":"time","type":"time"},{"name":"value","type":"number"}]}`
+
+	t.Run("Should replay the last frames merged with their schema", func(t *testing.T) {
+		b := newReplayBuffer(2)
+		_, ok := b.frame()
+		require.False(t, ok)
+
+		b.add([]byte(`{"schema":` + schema + `,"data":{"values":[[1],[10]]}}`))
+		b.add([]byte(`{"data":{"values":[[2,3],[20,30]]}}`))
+		b.add([]byte(`{"data":{"values":[[4],[40]]}}`))
+
+		data, ok := b.frame()
+		require.True(t, ok)
+		require.JSONEq(t, `{"schema":`+schema+`,"data":{"values":[[2,3,4],[20,30,40]]}}`, string(data))
+	})
+
+	t.Run("Should drop the frames of the previous schema", func(t *testing.T) {
+		b := newReplayBuffer(2)
+		b.add([]byte(`{"schema":{"fields":[{"name":"value"}]},"data":{"values":[[1]]}}`))
+		b.add([]byte(`{"schema":` + schema + `}`))
+
+		data, ok := b.frame()
+		require.True(t, ok)
+		require.JSONEq(t, `{"schema":`+schema+`,"data":{"values":[[],[]]}}`, string(data))
+	})
+
+	t.Run("Should not replay packets which aren't mergeable data frames", func(t *testing.T) {
+		b := newReplayBuffer(2)
+		b.add([]byte(`{"schema":` + schema + `,"data":{"values":[[1],[10]]}}`))
+		b.add([]byte(`{"data":{"values":[[2],[20]],"nanos":[[1],null]}}`))
+		_, ok := b.frame()
+		require.False(t, ok)
+
+		b.add([]byte(`{"schema":` + schema + `,"data":{"values":[[1],[10]]}}`))
+		b.add([]byte(`{"message":"hello"}`))
+		_, ok = b.frame()
+		require.False(t, ok)
+	})
+}
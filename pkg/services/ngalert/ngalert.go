@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
@@ -39,7 +40,8 @@ const (
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, encryptionService encryption.Service, m *metrics.NGAlert) (*AlertNG, error) {
+	quotaService *quota.QuotaService, encryptionService encryption.Service, m *metrics.NGAlert,
+	pluginFailures backendplugin.FailureReporter) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:               cfg,
 		DataSourceCache:   dataSourceCache,
@@ -51,6 +53,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		QuotaService:      quotaService,
 		EncryptionService: encryptionService,
 		Metrics:           m,
+		PluginFailures:    pluginFailures,
 		Log:               log.New("ngalert"),
 	}
 
@@ -77,6 +80,7 @@ type AlertNG struct {
 	QuotaService      *quota.QuotaService
 	EncryptionService encryption.Service
 	Metrics           *metrics.NGAlert
+	PluginFailures    backendplugin.FailureReporter
 	Log               log.Logger
 	schedule          schedule.ScheduleService
 	stateManager      *state.Manager
@@ -177,6 +181,15 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	if ng.Cfg.UnifiedAlerting.SystemAlertsEnabled && ng.PluginFailures != nil {
+		orgID := ng.Cfg.UnifiedAlerting.SystemAlertsOrgID
+		systemAlerts := newSystemAlerts(ng.PluginFailures, func() (alertsPutter, error) {
+			return ng.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+		}, ng.Cfg.UnifiedAlerting.SystemAlertsInterval, log.New("ngalert.systemalerts"))
+		children.Go(func() error {
+			return systemAlerts.run(subCtx)
+		})
+	}
 	return children.Wait()
 }
 
@@ -0,0 +1,121 @@
+package ngalert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	// SystemAlertLabel is the label of the built-in system alerts, to route them in the notification policies.
+	SystemAlertLabel = "grafana_system"
+
+	pluginRestartingAlert  = "PluginRestarting"
+	pluginUnavailableAlert = "PluginUnavailable"
+	pluginUnhealthyAlert   = "PluginUnhealthy"
+)
+
+// alertsPutter is the Alertmanager receiving the system alerts.
+type alertsPutter interface {
+	PutAlerts(postableAlerts apimodels.PostableAlerts) error
+}
+
+// systemAlerts raises alerts for the failures of the backend plugins, e.g. restarts of their process, since the
+// previous check. The alerts end after a few intervals without new failures.
+type systemAlerts struct {
+	failures     backendplugin.FailureReporter
+	alertmanager func() (alertsPutter, error)
+	interval     time.Duration
+	log          log.Logger
+	now          func() time.Time
+
+	previous map[string]backendplugin.PluginFailures
+}
+
+func newSystemAlerts(failures backendplugin.FailureReporter, alertmanager func() (alertsPutter, error), interval time.Duration, logger log.Logger) *systemAlerts {
+	return &systemAlerts{
+		failures:     failures,
+		alertmanager: alertmanager,
+		interval:     interval,
+		log:          logger,
+		now:          time.Now,
+		// failures before the first check are counted, e.g. restarts during startup
+		previous: map[string]backendplugin.PluginFailures{},
+	}
+}
+
+func (s *systemAlerts) run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.check(); err != nil {
+				s.log.Error("Failed to send system alerts", "err", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// check sends the alerts for the plugin failures since the previous check.
+func (s *systemAlerts) check() error {
+	alerts := s.alerts()
+	if len(alerts.PostableAlerts) == 0 {
+		return nil
+	}
+
+	am, err := s.alertmanager()
+	if err != nil {
+		return err
+	}
+	return am.PutAlerts(alerts)
+}
+
+func (s *systemAlerts) alerts() apimodels.PostableAlerts {
+	now := s.now()
+	alerts := apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{}}
+	add := func(name, pluginID, severity string, count int64, description string) {
+		if count <= 0 {
+			return
+		}
+		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
+			Annotations: models.LabelSet{
+				"summary":     fmt.Sprintf("Plugin %s is failing", pluginID),
+				"description": fmt.Sprintf(description, pluginID, count, s.interval),
+			},
+			StartsAt: strfmt.DateTime(now),
+			EndsAt:   strfmt.DateTime(now.Add(3 * s.interval)),
+			Alert: models.Alert{
+				Labels: models.LabelSet{
+					"alertname":      name,
+					"plugin_id":      pluginID,
+					"severity":       severity,
+					SystemAlertLabel: "true",
+				},
+			},
+		})
+	}
+
+	current := s.failures.PluginFailures()
+	for pluginID, f := range current {
+		prev := s.previous[pluginID]
+		add(pluginRestartingAlert, pluginID, "warning", f.Restarts-prev.Restarts,
+			"The process of plugin %s was restarted %d times in the last %s.")
+		add(pluginUnavailableAlert, pluginID, "critical", f.Unavailable-prev.Unavailable,
+			"%[2]d requests to plugin %[1]s failed in the last %[3]s because the plugin was unavailable.")
+		add(pluginUnhealthyAlert, pluginID, "warning", f.HealthCheckFailures-prev.HealthCheckFailures,
+			"%[2]d health checks of plugin %[1]s failed in the last %[3]s.")
+	}
+	s.previous = current
+
+	return alerts
+}
@@ -0,0 +1,74 @@
+package ngalert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFailureReporter struct {
+	failures map[string]backendplugin.PluginFailures
+}
+
+func (f *fakeFailureReporter) PluginFailures() map[string]backendplugin.PluginFailures {
+	return f.failures
+}
+
+type fakeAlertsPutter struct {
+	alerts []apimodels.PostableAlerts
+}
+
+func (f *fakeAlertsPutter) PutAlerts(alerts apimodels.PostableAlerts) error {
+	f.alerts = append(f.alerts, alerts)
+	return nil
+}
+
+func TestSystemAlerts(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	reporter := &fakeFailureReporter{failures: map[string]backendplugin.PluginFailures{}}
+	am := &fakeAlertsPutter{}
+	s := newSystemAlerts(reporter, func() (alertsPutter, error) { return am, nil }, time.Minute, log.New("test"))
+	s.now = func() time.Time { return now }
+
+	t.Run("No alerts without failures", func(t *testing.T) {
+		require.NoError(t, s.check())
+		require.Empty(t, am.alerts)
+	})
+
+	t.Run("Alerts for new failures", func(t *testing.T) {
+		reporter.failures = map[string]backendplugin.PluginFailures{
+			"test-datasource": {Restarts: 2, Unavailable: 5},
+		}
+		require.NoError(t, s.check())
+		require.Len(t, am.alerts, 1)
+
+		alerts := am.alerts[0].PostableAlerts
+		require.Len(t, alerts, 2)
+		require.Equal(t, pluginRestartingAlert, alerts[0].Labels["alertname"])
+		require.Equal(t, "test-datasource", alerts[0].Labels["plugin_id"])
+		require.Equal(t, "true", alerts[0].Labels[SystemAlertLabel])
+		require.Equal(t, "The process of plugin test-datasource was restarted 2 times in the last 1m0s.", alerts[0].Annotations["description"])
+		require.Equal(t, now.Add(3*time.Minute), time.Time(alerts[0].EndsAt))
+		require.Equal(t, pluginUnavailableAlert, alerts[1].Labels["alertname"])
+		require.Equal(t, "5 requests to plugin test-datasource failed in the last 1m0s because the plugin was unavailable.", alerts[1].Annotations["description"])
+	})
+
+	t.Run("Only failures since the previous check raise alerts", func(t *testing.T) {
+		am.alerts = nil
+		reporter.failures = map[string]backendplugin.PluginFailures{
+			"test-datasource": {Restarts: 2, Unavailable: 5, HealthCheckFailures: 1},
+		}
+		require.NoError(t, s.check())
+		require.Len(t, am.alerts, 1)
+		require.Len(t, am.alerts[0].PostableAlerts, 1)
+		require.Equal(t, pluginUnhealthyAlert, am.alerts[0].PostableAlerts[0].Labels["alertname"])
+
+		am.alerts = nil
+		require.NoError(t, s.check())
+		require.Empty(t, am.alerts)
+	})
+}
@@ -0,0 +1,79 @@
+// Package orgsettings stores per-organization overrides of instance wide settings.
+package orgsettings
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	kvNamespace              = "orgsettings"
+	frontendSettingsOverride = "frontend"
+)
+
+// FrontendSettingsOverrides are org specific values that take precedence over the instance wide
+// configuration when the frontend settings are built.
+type FrontendSettingsOverrides struct {
+	// DefaultDatasource is the name of the data source used as default for the organization.
+	DefaultDatasource string `json:"defaultDatasource,omitempty"`
+	// DefaultTheme is the theme, light or dark, of the users of the organization who haven't chosen one, in
+	// place of the instance wide default theme.
+	DefaultTheme string `json:"defaultTheme,omitempty"`
+	// HiddenPanels lists panel plugin IDs that should not be offered in the panel picker.
+	HiddenPanels []string `json:"hiddenPanels,omitempty"`
+	// PanelSortOrder lists panel plugin IDs that are listed first in the panel picker, in order.
+	PanelSortOrder []string `json:"panelSortOrder,omitempty"`
+	// FeatureToggles are merged on top of the instance wide feature toggles.
+	FeatureToggles map[string]bool `json:"featureToggles,omitempty"`
+}
+
+type Service struct {
+	kvStore kvstore.KVStore
+	log     log.Logger
+}
+
+func ProvideService(kvStore kvstore.KVStore) *Service {
+	return &Service{
+		kvStore: kvStore,
+		log:     log.New("orgsettings"),
+	}
+}
+
+// GetFrontendSettingsOverrides returns the frontend settings overrides for an organization.
+// An empty set of overrides is returned if none have been saved.
+func (s *Service) GetFrontendSettingsOverrides(ctx context.Context, orgID int64) (*FrontendSettingsOverrides, error) {
+	overrides := &FrontendSettingsOverrides{}
+
+	value, exists, err := s.kvStore.Get(ctx, orgID, kvNamespace, frontendSettingsOverride)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return overrides, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), overrides); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+// SaveFrontendSettingsOverrides replaces the frontend settings overrides of an organization.
+func (s *Service) SaveFrontendSettingsOverrides(ctx context.Context, orgID int64, overrides *FrontendSettingsOverrides) error {
+	value, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Saving frontend settings overrides", "orgId", orgID)
+	return s.kvStore.Set(ctx, orgID, kvNamespace, frontendSettingsOverride, string(value))
+}
+
+// DeleteFrontendSettingsOverrides removes all frontend settings overrides of an organization.
+func (s *Service) DeleteFrontendSettingsOverrides(ctx context.Context, orgID int64) error {
+	return s.kvStore.Del(ctx, orgID, kvNamespace, frontendSettingsOverride)
+}
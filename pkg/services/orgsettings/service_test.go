@@ -0,0 +1,47 @@
+package orgsettings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontendSettingsOverrides(t *testing.T) {
+	s := ProvideService(kvstore.ProvideService(sqlstore.InitTestDB(t)))
+	ctx := context.Background()
+
+	t.Run("Returns empty overrides when none are saved", func(t *testing.T) {
+		overrides, err := s.GetFrontendSettingsOverrides(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, &FrontendSettingsOverrides{}, overrides)
+	})
+
+	t.Run("Saved overrides are scoped to the organization", func(t *testing.T) {
+		saved := &FrontendSettingsOverrides{
+			DefaultDatasource: "Prometheus",
+			DefaultTheme:      "light",
+			HiddenPanels:      []string{"graph"},
+			FeatureToggles:    map[string]bool{"tempoSearch": true},
+		}
+		require.NoError(t, s.SaveFrontendSettingsOverrides(ctx, 1, saved))
+
+		overrides, err := s.GetFrontendSettingsOverrides(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, saved, overrides)
+
+		overrides, err = s.GetFrontendSettingsOverrides(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, &FrontendSettingsOverrides{}, overrides)
+	})
+
+	t.Run("Deleted overrides are no longer returned", func(t *testing.T) {
+		require.NoError(t, s.DeleteFrontendSettingsOverrides(ctx, 1))
+
+		overrides, err := s.GetFrontendSettingsOverrides(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, &FrontendSettingsOverrides{}, overrides)
+	})
+}
@@ -0,0 +1,147 @@
+// Package pluginencryption lets app plugins encrypt, decrypt and sign the data they store in their own tables with
+// the secrets service of Grafana, rather than with keys of their own.
+package pluginencryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	// ErrRateLimited is returned when a caller exceeds the encryption_rate_limit of the [plugins] section for a plugin
+	// in an organization.
+	ErrRateLimited = errors.New("plugin encryption rate limit exceeded")
+	// ErrInvalidPayload is returned when decrypting a payload which wasn't encrypted for the plugin and organization.
+	ErrInvalidPayload = errors.New("payload not encrypted for this plugin")
+)
+
+// Service encrypts and signs the data of app plugins. The payloads are scoped to the plugin and the organization:
+// a payload encrypted or signed for a plugin in an organization can't be decrypted or verified by another plugin, or
+// in another organization.
+type Service struct {
+	secretsService secrets.Service
+	limiter        *keyedRateLimiter
+	log            log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, secretsService secrets.Service) *Service {
+	return &Service{
+		secretsService: secretsService,
+		limiter:        newKeyedRateLimiter(cfg.PluginsEncryptionRateLimit),
+		log:            log.New("plugins.encryption"),
+	}
+}
+
+// Encrypt encrypts the payload with a data key of the plugin. The caller identifies the user or API key calling, for
+// the rate limit.
+func (s *Service) Encrypt(ctx context.Context, pluginID string, orgID int64, caller string, payload []byte) ([]byte, error) {
+	if !s.limiter.allow(pluginID, orgID, caller) {
+		return nil, ErrRateLimited
+	}
+	header := payloadHeader(pluginID, orgID)
+	return s.secretsService.Encrypt(ctx, append(header, payload...), secrets.WithScope("plugin:"+pluginID))
+}
+
+// Decrypt decrypts a payload encrypted by Encrypt for the same plugin and organization. The decryption is recorded
+// in the audit sinks of the secrets service.
+func (s *Service) Decrypt(ctx context.Context, pluginID string, orgID int64, caller string, payload []byte) ([]byte, error) {
+	if !s.limiter.allow(pluginID, orgID, caller) {
+		return nil, ErrRateLimited
+	}
+
+	ctx = secrets.WithAuditSecret(ctx, secrets.AuditSecret{Kind: secrets.SecretKindPluginPayload, OrgID: orgID,
+		Name: pluginID})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{Service: "pluginencryption", PluginID: pluginID})
+	decrypted, err := s.secretsService.Decrypt(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	header := payloadHeader(pluginID, orgID)
+	if !bytes.HasPrefix(decrypted, header) {
+		s.log.Warn("Plugin tried to decrypt a payload of another plugin or organization", "pluginId", pluginID,
+			"orgId", orgID)
+		return nil, ErrInvalidPayload
+	}
+	return decrypted[len(header):], nil
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload with a key of the plugin and organization derived from the
+// secret_key of the [security] section.
+func (s *Service) Sign(pluginID string, orgID int64, caller string, payload []byte) ([]byte, error) {
+	if !s.limiter.allow(pluginID, orgID, caller) {
+		return nil, ErrRateLimited
+	}
+	return sign(pluginID, orgID, payload), nil
+}
+
+// Verify returns whether the signature is a signature of the payload returned by Sign for the same plugin and
+// organization.
+func (s *Service) Verify(pluginID string, orgID int64, caller string, payload, signature []byte) (bool, error) {
+	if !s.limiter.allow(pluginID, orgID, caller) {
+		return false, ErrRateLimited
+	}
+	return hmac.Equal(signature, sign(pluginID, orgID, payload)), nil
+}
+
+// payloadHeader is prepended to the payloads before they are encrypted, to check that they are decrypted for the
+// same plugin and organization.
+func payloadHeader(pluginID string, orgID int64) []byte {
+	return []byte(fmt.Sprintf("%s\n%d\n", pluginID, orgID))
+}
+
+func sign(pluginID string, orgID int64, payload []byte) []byte {
+	keyMAC := hmac.New(sha256.New, []byte(setting.SecretKey))
+	keyMAC.Write([]byte("plugin:" + pluginID + ":" + strconv.FormatInt(orgID, 10)))
+
+	mac := hmac.New(sha256.New, keyMAC.Sum(nil))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// keyedRateLimiter allows an average of a number of operations per second for each caller of a plugin in an
+// organization, with bursts of the same number of operations, so that a caller can't exhaust the budget of the others.
+// The number of limiters is bounded by the number of installed plugins and of the users and API keys allowed to use
+// them, so they are never removed.
+type keyedRateLimiter struct {
+	limit rate.Limit
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newKeyedRateLimiter returns nil, allowing all the operations, if perSecond isn't positive.
+func newKeyedRateLimiter(perSecond int) *keyedRateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &keyedRateLimiter{limit: rate.Limit(perSecond), limiters: map[string]*rate.Limiter{}}
+}
+
+func (l *keyedRateLimiter) allow(pluginID string, orgID int64, caller string) bool {
+	if l == nil {
+		return true
+	}
+
+	key := fmt.Sprintf("%d/%s/%s", orgID, pluginID, caller)
+	l.mu.Lock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, int(l.limit))
+		l.limiters[key] = limiter
+	}
+	l.mu.Unlock()
+	return limiter.Allow()
+}
@@ -0,0 +1,75 @@
+package pluginencryption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	newService := func(rateLimit int) *Service {
+		cfg := setting.NewCfg()
+		cfg.PluginsEncryptionRateLimit = rateLimit
+		return ProvideService(cfg, fakes.NewFakeSecretsService())
+	}
+
+	t.Run("Should decrypt the payloads of the same plugin and organization only", func(t *testing.T) {
+		s := newService(0)
+		encrypted, err := s.Encrypt(ctx, "test-app", 1, "user:1", []byte("secret"))
+		require.NoError(t, err)
+
+		decrypted, err := s.Decrypt(ctx, "test-app", 1, "user:1", encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("secret"), decrypted)
+
+		_, err = s.Decrypt(ctx, "other-app", 1, "user:1", encrypted)
+		require.ErrorIs(t, err, ErrInvalidPayload)
+		_, err = s.Decrypt(ctx, "test-app", 2, "user:1", encrypted)
+		require.ErrorIs(t, err, ErrInvalidPayload)
+	})
+
+	t.Run("Should verify the signatures of the same plugin and organization only", func(t *testing.T) {
+		s := newService(0)
+		signature, err := s.Sign("test-app", 1, "user:1", []byte("payload"))
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			pluginID string
+			orgID    int64
+			payload  string
+			valid    bool
+		}{
+			{"test-app", 1, "payload", true},
+			{"test-app", 1, "tampered", false},
+			{"other-app", 1, "payload", false},
+			{"test-app", 2, "payload", false},
+		} {
+			valid, err := s.Verify(tc.pluginID, tc.orgID, "user:1", []byte(tc.payload), signature)
+			require.NoError(t, err)
+			require.Equal(t, tc.valid, valid, tc)
+		}
+	})
+
+	t.Run("Should rate limit each caller of a plugin and organization", func(t *testing.T) {
+		s := newService(2)
+		for i := 0; i < 2; i++ {
+			_, err := s.Sign("test-app", 1, "user:1", []byte("payload"))
+			require.NoError(t, err)
+		}
+		_, err := s.Encrypt(ctx, "test-app", 1, "user:1", []byte("payload"))
+		require.ErrorIs(t, err, ErrRateLimited)
+
+		_, err = s.Sign("test-app", 1, "user:2", []byte("payload"))
+		require.NoError(t, err)
+		_, err = s.Sign("test-app", 1, "apikey:1", []byte("payload"))
+		require.NoError(t, err)
+		_, err = s.Sign("test-app", 2, "user:1", []byte("payload"))
+		require.NoError(t, err)
+		_, err = s.Sign("other-app", 1, "user:1", []byte("payload"))
+		require.NoError(t, err)
+	})
+}
@@ -9,14 +9,15 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
-	"github.com/grafana/grafana/pkg/setting"
 )
 
 type Service struct {
 	Bus               bus.Bus
 	SQLStore          *sqlstore.SQLStore
 	EncryptionService encryption.Service
+	SecretsService    secrets.Service
 
 	logger                       log.Logger
 	pluginSettingDecryptionCache secureJSONDecryptionCache
@@ -32,11 +33,13 @@ type secureJSONDecryptionCache struct {
 	sync.Mutex
 }
 
-func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, encryptionService encryption.Service) *Service {
+func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, encryptionService encryption.Service,
+	secretsService secrets.Service) *Service {
 	s := &Service{
 		Bus:               bus,
 		SQLStore:          store,
 		EncryptionService: encryptionService,
+		SecretsService:    secretsService,
 		logger:            log.New("pluginsettings"),
 		pluginSettingDecryptionCache: secureJSONDecryptionCache{
 			cache: make(map[int64]cachedDecryptedJSON),
@@ -46,6 +49,9 @@ func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, encryptionService enc
 	s.Bus.AddHandler(s.GetPluginSettingById)
 	s.Bus.AddHandlerCtx(s.UpdatePluginSetting)
 	s.Bus.AddHandler(s.UpdatePluginSettingVersion)
+	s.Bus.AddHandlerCtx(s.GetPluginSettingVersions)
+	s.Bus.AddHandlerCtx(s.GetPluginSettingVersion)
+	s.Bus.AddHandlerCtx(s.RestorePluginSettingVersion)
 
 	return s
 }
@@ -56,18 +62,55 @@ func (s *Service) GetPluginSettingById(query *models.GetPluginSettingByIdQuery)
 
 func (s *Service) UpdatePluginSetting(ctx context.Context, cmd *models.UpdatePluginSettingCmd) error {
 	var err error
-	cmd.EncryptedSecureJsonData, err = s.EncryptionService.EncryptJsonData(ctx, cmd.SecureJsonData, setting.SecretKey)
+	ctx = secrets.WithAuditSecret(ctx, secrets.AuditSecret{
+		Kind:  secrets.SecretKindPluginSetting,
+		OrgID: cmd.OrgId,
+		Name:  cmd.PluginId,
+	})
+	cmd.EncryptedSecureJsonData, err = s.SecretsService.EncryptJsonData(ctx, cmd.SecureJsonData, secrets.WithoutScope())
 	if err != nil {
 		return err
 	}
 
-	return s.SQLStore.UpdatePluginSetting(cmd)
+	if err := s.SQLStore.UpdatePluginSetting(cmd); err != nil {
+		return err
+	}
+
+	s.SecretsService.InvalidateDecryptionCache(secrets.AuditSecret{
+		Kind:  secrets.SecretKindPluginSetting,
+		OrgID: cmd.OrgId,
+		Name:  cmd.PluginId,
+	})
+	return nil
 }
 
 func (s *Service) UpdatePluginSettingVersion(cmd *models.UpdatePluginSettingVersionCmd) error {
 	return s.SQLStore.UpdatePluginSettingVersion(cmd)
 }
 
+func (s *Service) GetPluginSettingVersions(ctx context.Context, query *models.GetPluginSettingVersionsQuery) error {
+	return s.SQLStore.GetPluginSettingVersions(ctx, query)
+}
+
+func (s *Service) GetPluginSettingVersion(ctx context.Context, query *models.GetPluginSettingVersionQuery) error {
+	return s.SQLStore.GetPluginSettingVersion(ctx, query)
+}
+
+// RestorePluginSettingVersion rolls the settings of a plugin back to one of their versions, with the secure JSON data
+// of the version, so the decrypted secrets of the plugin are invalidated.
+func (s *Service) RestorePluginSettingVersion(ctx context.Context, cmd *models.RestorePluginSettingVersionCmd) error {
+	if err := s.SQLStore.RestorePluginSettingVersion(ctx, cmd); err != nil {
+		return err
+	}
+
+	s.SecretsService.InvalidateDecryptionCache(secrets.AuditSecret{
+		Kind:  secrets.SecretKindPluginSetting,
+		OrgID: cmd.OrgId,
+		Name:  cmd.PluginId,
+	})
+	return nil
+}
+
 func (s *Service) DecryptedValues(ps *models.PluginSetting) map[string]string {
 	s.pluginSettingDecryptionCache.Lock()
 	defer s.pluginSettingDecryptionCache.Unlock()
@@ -76,7 +119,13 @@ func (s *Service) DecryptedValues(ps *models.PluginSetting) map[string]string {
 		return item.json
 	}
 
-	json, err := s.EncryptionService.DecryptJsonData(context.Background(), ps.SecureJsonData, setting.SecretKey)
+	ctx := secrets.WithAuditSecret(context.Background(), secrets.AuditSecret{
+		Kind:  secrets.SecretKindPluginSetting,
+		OrgID: ps.OrgId,
+		Name:  ps.PluginId,
+	})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{Service: "pluginsettings", PluginID: ps.PluginId})
+	json, err := s.SecretsService.DecryptJsonData(ctx, ps.SecureJsonData)
 	if err != nil {
 		s.logger.Error("Failed to decrypt secure json data", "error", err)
 		return map[string]string{}
@@ -8,16 +8,32 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 )
 
+func TestService_UpdatePluginSetting(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	psService := ProvideService(bus.New(), sqlStore, ossencryption.ProvideService(), secretsManager.SetupTestService(t, nil))
+
+	cmd := &models.UpdatePluginSettingCmd{OrgId: 1, PluginId: "test-app", Enabled: true,
+		SecureJsonData: map[string]string{"password": "password"}}
+	require.NoError(t, psService.UpdatePluginSetting(context.Background(), cmd))
+
+	query := &models.GetPluginSettingByIdQuery{OrgId: 1, PluginId: "test-app"}
+	require.NoError(t, psService.GetPluginSettingById(query))
+	require.Equal(t, byte('#'), query.Result.SecureJsonData["password"][0], "secrets should be encrypted with a data key")
+	require.Equal(t, map[string]string{"password": "password"}, psService.DecryptedValues(query.Result))
+}
+
 func TestService_DecryptedValuesCache(t *testing.T) {
 	t.Run("When plugin settings hasn't been updated, encrypted JSON should be fetched from cache", func(t *testing.T) {
 		ctx := context.Background()
 
 		encryptionService := ossencryption.ProvideService()
-		psService := ProvideService(bus.New(), nil, encryptionService)
+		psService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		encryptedJsonData, err := encryptionService.EncryptJsonData(
 			ctx,
@@ -55,7 +71,7 @@ func TestService_DecryptedValuesCache(t *testing.T) {
 		ctx := context.Background()
 
 		encryptionService := ossencryption.ProvideService()
-		psService := ProvideService(bus.New(), nil, encryptionService)
+		psService := ProvideService(bus.New(), nil, encryptionService, secretsManager.SetupTestService(t, nil))
 
 		encryptedJsonData, err := encryptionService.EncryptJsonData(
 			ctx,
@@ -0,0 +1,109 @@
+package queryhistory
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-macaron/binding"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *QueryHistoryService) registerAPIEndpoints() {
+	s.RouteRegister.Group("/api/query-history", func(entities routing.RouteRegister) {
+		entities.Post("/", middleware.ReqSignedIn, binding.Bind(CreateQueryInQueryHistoryCommand{}), routing.Wrap(s.createHandler))
+		entities.Get("/", middleware.ReqSignedIn, routing.Wrap(s.searchHandler))
+		entities.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(s.deleteHandler))
+		entities.Patch("/:uid", middleware.ReqSignedIn, binding.Bind(PatchQueryCommentInQueryHistoryCommand{}), routing.Wrap(s.patchCommentHandler))
+		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.starHandler))
+		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.unstarHandler))
+	})
+}
+
+// createHandler handles POST /api/query-history.
+func (s *QueryHistoryService) createHandler(c *models.ReqContext, cmd CreateQueryInQueryHistoryCommand) response.Response {
+	query, err := s.createQuery(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to create query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// searchHandler handles GET /api/query-history.
+func (s *QueryHistoryService) searchHandler(c *models.ReqContext) response.Response {
+	query := SearchInQueryHistoryQuery{
+		DatasourceUIDs: c.QueryStrings("datasourceUid"),
+		SearchString:   c.Query("searchString"),
+		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
+		Sort:           c.Query("sort"),
+		Page:           c.QueryInt("page"),
+		Limit:          c.QueryInt("limit"),
+	}
+
+	result, err := s.searchQueries(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to get query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistorySearchResponse{Result: result})
+}
+
+// deleteHandler handles DELETE /api/query-history/:uid.
+func (s *QueryHistoryService) deleteHandler(c *models.ReqContext) response.Response {
+	id, err := s.deleteQuery(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to delete query from query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryDeleteQueryResponse{
+		Message: "Query deleted",
+		ID:      id,
+	})
+}
+
+// patchCommentHandler handles PATCH /api/query-history/:uid.
+func (s *QueryHistoryService) patchCommentHandler(c *models.ReqContext, cmd PatchQueryCommentInQueryHistoryCommand) response.Response {
+	query, err := s.patchQueryComment(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to update comment of query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// starHandler handles POST /api/query-history/star/:uid.
+func (s *QueryHistoryService) starHandler(c *models.ReqContext) response.Response {
+	query, err := s.starQuery(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to star query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// unstarHandler handles DELETE /api/query-history/star/:uid.
+func (s *QueryHistoryService) unstarHandler(c *models.ReqContext) response.Response {
+	query, err := s.unstarQuery(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toQueryHistoryError(err, "Failed to unstar query in query history")
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+func toQueryHistoryError(err error, message string) response.Response {
+	if errors.Is(err, ErrQueryNotFound) {
+		return response.Error(http.StatusNotFound, ErrQueryNotFound.Error(), err)
+	}
+	if errors.Is(err, ErrStarredQueryNotFound) {
+		return response.Error(http.StatusNotFound, ErrStarredQueryNotFound.Error(), err)
+	}
+	if errors.Is(err, ErrQueryAlreadyStarred) {
+		return response.Error(http.StatusBadRequest, ErrQueryAlreadyStarred.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
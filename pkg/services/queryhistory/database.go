@@ -0,0 +1,241 @@
+package queryhistory
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+var getTime = time.Now
+
+func (s QueryHistoryService) createQuery(ctx context.Context, user *models.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	query := QueryHistory{
+		OrgID:         user.OrgId,
+		UID:           util.GenerateShortUID(),
+		Queries:       cmd.Queries,
+		DatasourceUID: cmd.DatasourceUID,
+		CreatedBy:     user.UserId,
+		CreatedAt:     getTime().Unix(),
+		Comment:       "",
+	}
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		_, err := session.Insert(&query)
+		return err
+	})
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	return toQueryHistoryDTO(query, false), nil
+}
+
+func (s QueryHistoryService) searchQueries(ctx context.Context, user *models.SignedInUser, query SearchInQueryHistoryQuery) (QueryHistorySearchResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	var dtos []QueryHistoryDTO
+	var totalCount int64
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		dialect := s.SQLStore.Dialect
+
+		builder := sqlstore.SQLBuilder{}
+		builder.Write(`SELECT
+			query_history.uid,
+			query_history.datasource_uid,
+			query_history.created_by,
+			query_history.created_at,
+			query_history.comment,
+			query_history.queries,
+			CASE WHEN query_history_star.query_uid IS NULL THEN ` + dialect.BooleanStr(false) + ` ELSE ` + dialect.BooleanStr(true) + ` END AS starred
+			FROM query_history`)
+		writeStarredJoinSQL(query, user, &builder)
+		writeFiltersSQL(query, user, s.SQLStore, &builder)
+		writeSortSQL(query, &builder)
+		writeLimitSQL(query, s.SQLStore, &builder)
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&dtos); err != nil {
+			return err
+		}
+
+		countBuilder := sqlstore.SQLBuilder{}
+		countBuilder.Write("SELECT COUNT(*) FROM query_history")
+		writeStarredJoinSQL(query, user, &countBuilder)
+		writeFiltersSQL(query, user, s.SQLStore, &countBuilder)
+		_, err := session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Get(&totalCount)
+		return err
+	})
+	if err != nil {
+		return QueryHistorySearchResult{}, err
+	}
+
+	if dtos == nil {
+		dtos = make([]QueryHistoryDTO, 0)
+	}
+	return QueryHistorySearchResult{
+		TotalCount:   totalCount,
+		QueryHistory: dtos,
+		Page:         query.Page,
+		PerPage:      query.Limit,
+	}, nil
+}
+
+func (s QueryHistoryService) deleteQuery(ctx context.Context, user *models.SignedInUser, UID string) (int64, error) {
+	var queryID int64
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		query, err := getQueryByUID(session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := session.Exec("DELETE FROM query_history_star WHERE query_uid = ?", UID); err != nil {
+			return err
+		}
+		if _, err := session.ID(query.ID).Delete(&QueryHistory{}); err != nil {
+			return err
+		}
+
+		queryID = query.ID
+		return nil
+	})
+
+	return queryID, err
+}
+
+func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *models.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	var dto QueryHistoryDTO
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		query, err := getQueryByUID(session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		query.Comment = cmd.Comment
+		if _, err := session.Exec("UPDATE query_history SET comment = ? WHERE id = ?", query.Comment, query.ID); err != nil {
+			return err
+		}
+
+		starred, err := isQueryStarred(session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		dto = toQueryHistoryDTO(query, starred)
+		return nil
+	})
+
+	return dto, err
+}
+
+func (s QueryHistoryService) starQuery(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error) {
+	var dto QueryHistoryDTO
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		query, err := getQueryByUID(session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		star := QueryHistoryStar{
+			QueryUID: UID,
+			UserID:   user.UserId,
+		}
+		if _, err := session.Insert(&star); err != nil {
+			if s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ErrQueryAlreadyStarred
+			}
+			return err
+		}
+
+		dto = toQueryHistoryDTO(query, true)
+		return nil
+	})
+
+	return dto, err
+}
+
+func (s QueryHistoryService) unstarQuery(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error) {
+	var dto QueryHistoryDTO
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		query, err := getQueryByUID(session, user, UID)
+		if err != nil {
+			return err
+		}
+
+		res, err := session.Exec("DELETE FROM query_history_star WHERE query_uid = ? AND user_id = ?", UID, user.UserId)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			return ErrStarredQueryNotFound
+		}
+
+		dto = toQueryHistoryDTO(query, false)
+		return nil
+	})
+
+	return dto, err
+}
+
+func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan int64) (int, error) {
+	var rowsCount int64
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		sql := `DELETE FROM query_history
+			WHERE created_at < ?
+			AND uid NOT IN (SELECT query_uid FROM query_history_star)`
+
+		res, err := session.Exec(sql, olderThan)
+		if err != nil {
+			return err
+		}
+
+		rowsCount, err = res.RowsAffected()
+		return err
+	})
+
+	return int(rowsCount), err
+}
+
+// getQueryByUID returns the query of the query history with the given UID, if it was created by the user.
+func getQueryByUID(session *sqlstore.DBSession, user *models.SignedInUser, UID string) (QueryHistory, error) {
+	var query QueryHistory
+	exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgId, user.UserId, UID).Get(&query)
+	if err != nil {
+		return QueryHistory{}, err
+	}
+	if !exists {
+		return QueryHistory{}, ErrQueryNotFound
+	}
+	return query, nil
+}
+
+func isQueryStarred(session *sqlstore.DBSession, user *models.SignedInUser, UID string) (bool, error) {
+	return session.Where("query_uid = ? AND user_id = ?", UID, user.UserId).Exist(&QueryHistoryStar{})
+}
+
+func toQueryHistoryDTO(query QueryHistory, starred bool) QueryHistoryDTO {
+	return QueryHistoryDTO{
+		UID:           query.UID,
+		DatasourceUID: query.DatasourceUID,
+		CreatedBy:     query.CreatedBy,
+		CreatedAt:     query.CreatedAt,
+		Comment:       query.Comment,
+		Queries:       query.Queries,
+		Starred:       starred,
+	}
+}
@@ -0,0 +1,97 @@
+package queryhistory
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	// ErrQueryNotFound is an error for when a query can't be found in the query history.
+	ErrQueryNotFound = errors.New("query in query history not found")
+	// ErrStarredQueryNotFound is an error for when a query isn't starred by the user.
+	ErrStarredQueryNotFound = errors.New("starred query not found")
+	// ErrQueryAlreadyStarred is an error for when a query is already starred by the user.
+	ErrQueryAlreadyStarred = errors.New("query was already starred")
+)
+
+// QueryHistory is the model for the queries of the query history.
+type QueryHistory struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	UID           string `xorm:"uid"`
+	DatasourceUID string `xorm:"datasource_uid"`
+	OrgID         int64  `xorm:"org_id"`
+	CreatedBy     int64
+	CreatedAt     int64
+	Comment       string
+	Queries       *simplejson.Json
+}
+
+// QueryHistoryStar is the model for the queries of the query history starred by a user.
+type QueryHistoryStar struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	QueryUID string `xorm:"query_uid"`
+	UserID   int64  `xorm:"user_id"`
+}
+
+// Sort orders of the query history search.
+const (
+	SortTimeDesc = "time-desc"
+	SortTimeAsc  = "time-asc"
+)
+
+// CreateQueryInQueryHistoryCommand is the command for adding a query to the query history.
+type CreateQueryInQueryHistoryCommand struct {
+	DatasourceUID string           `json:"datasourceUid"`
+	Queries       *simplejson.Json `json:"queries"`
+}
+
+// PatchQueryCommentInQueryHistoryCommand is the command for updating the comment of a query of the query history.
+type PatchQueryCommentInQueryHistoryCommand struct {
+	Comment string `json:"comment"`
+}
+
+// SearchInQueryHistoryQuery is the query used for searching in the query history.
+type SearchInQueryHistoryQuery struct {
+	DatasourceUIDs []string
+	SearchString   string
+	OnlyStarred    bool
+	Sort           string
+	Page           int
+	Limit          int
+}
+
+// QueryHistoryDTO is the frontend DTO for the queries of the query history.
+type QueryHistoryDTO struct {
+	UID           string           `json:"uid" xorm:"uid"`
+	DatasourceUID string           `json:"datasourceUid" xorm:"datasource_uid"`
+	CreatedBy     int64            `json:"createdBy"`
+	CreatedAt     int64            `json:"createdAt"`
+	Comment       string           `json:"comment"`
+	Queries       *simplejson.Json `json:"queries"`
+	Starred       bool             `json:"starred"`
+}
+
+// QueryHistorySearchResult is the search result for the query history.
+type QueryHistorySearchResult struct {
+	TotalCount   int64             `json:"totalCount"`
+	QueryHistory []QueryHistoryDTO `json:"queryHistory"`
+	Page         int               `json:"page"`
+	PerPage      int               `json:"perPage"`
+}
+
+// QueryHistoryResponse is a response struct for QueryHistoryDTO.
+type QueryHistoryResponse struct {
+	Result QueryHistoryDTO `json:"result"`
+}
+
+// QueryHistorySearchResponse is a response struct for QueryHistorySearchResult.
+type QueryHistorySearchResponse struct {
+	Result QueryHistorySearchResult `json:"result"`
+}
+
+// QueryHistoryDeleteQueryResponse is the response struct for deleting a query from the query history.
+type QueryHistoryDeleteQueryResponse struct {
+	ID      int64  `json:"id"`
+	Message string `json:"message"`
+}
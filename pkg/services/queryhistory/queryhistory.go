@@ -0,0 +1,82 @@
+package queryhistory
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister) *QueryHistoryService {
+	s := &QueryHistoryService{
+		Cfg:           cfg,
+		SQLStore:      sqlStore,
+		RouteRegister: routeRegister,
+		log:           log.New("query-history"),
+	}
+
+	// the queries are kept in the database when the query history is disabled, they're still removed once stale
+	if cfg.QueryHistoryEnabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+// Service is a service for operating on the query history.
+type Service interface {
+	CreateQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error)
+	SearchInQueryHistory(ctx context.Context, user *models.SignedInUser, query SearchInQueryHistoryQuery) (QueryHistorySearchResult, error)
+	DeleteQueryFromQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (int64, error)
+	PatchQueryCommentInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error)
+	StarQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error)
+	UnstarQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error)
+	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
+}
+
+// QueryHistoryService is the service for the query history of Explore.
+type QueryHistoryService struct {
+	Cfg           *setting.Cfg
+	SQLStore      *sqlstore.SQLStore
+	RouteRegister routing.RouteRegister
+	log           log.Logger
+}
+
+// CreateQueryInQueryHistory adds the queries run by the user to the query history.
+func (s QueryHistoryService) CreateQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.createQuery(ctx, user, cmd)
+}
+
+// SearchInQueryHistory searches the query history of the user.
+func (s QueryHistoryService) SearchInQueryHistory(ctx context.Context, user *models.SignedInUser, query SearchInQueryHistoryQuery) (QueryHistorySearchResult, error) {
+	return s.searchQueries(ctx, user, query)
+}
+
+// DeleteQueryFromQueryHistory deletes a query of the user from the query history, and returns its ID.
+func (s QueryHistoryService) DeleteQueryFromQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (int64, error) {
+	return s.deleteQuery(ctx, user, UID)
+}
+
+// PatchQueryCommentInQueryHistory updates the comment of a query of the user.
+func (s QueryHistoryService) PatchQueryCommentInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.patchQueryComment(ctx, user, UID, cmd)
+}
+
+// StarQueryInQueryHistory stars a query of the user, so that it's kept whatever the retention of the query history.
+func (s QueryHistoryService) StarQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error) {
+	return s.starQuery(ctx, user, UID)
+}
+
+// UnstarQueryInQueryHistory unstars a query of the user.
+func (s QueryHistoryService) UnstarQueryInQueryHistory(ctx context.Context, user *models.SignedInUser, UID string) (QueryHistoryDTO, error) {
+	return s.unstarQuery(ctx, user, UID)
+}
+
+// DeleteStaleQueriesInQueryHistory deletes the queries created before olderThan, a Unix time in seconds, which
+// aren't starred, and returns the number of deleted queries.
+func (s QueryHistoryService) DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error) {
+	return s.deleteStaleQueries(ctx, olderThan)
+}
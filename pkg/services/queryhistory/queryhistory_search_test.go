@@ -0,0 +1,95 @@
+package queryhistory
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchInQueryHistory(t *testing.T) {
+	setSearchParams := func(sc scenarioContext, params url.Values) {
+		sc.ctx.Req = &http.Request{URL: &url.URL{RawQuery: params.Encode()}}
+	}
+
+	testScenarioWithQueryInQueryHistory(t, "When users search without filters, it should return their queries",
+		func(t *testing.T, sc scenarioContext) {
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(1), result.TotalCount)
+			require.Len(t, result.QueryHistory, 1)
+			require.Equal(t, sc.initialQuery.UID, result.QueryHistory[0].UID)
+			require.Equal(t, 1, result.Page)
+			require.Equal(t, defaultSearchLimit, result.PerPage)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users search the queries of another data source, it should return no queries",
+		func(t *testing.T, sc scenarioContext) {
+			setSearchParams(sc, url.Values{"datasourceUid": []string{"other"}})
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(0), result.TotalCount)
+			require.Len(t, result.QueryHistory, 0)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users search with a search string, it should match the queries and the comments",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.CreateQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, getCreateCommand(testDatasourceUID, "other"))
+			require.NoError(t, err)
+			_, err = sc.service.PatchQueryCommentInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialQuery.UID,
+				PatchQueryCommentInQueryHistoryCommand{Comment: "latency"})
+			require.NoError(t, err)
+
+			setSearchParams(sc, url.Values{"searchString": []string{"other"}})
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(1), result.TotalCount)
+			require.Equal(t, "other", result.QueryHistory[0].Queries.GetIndex(0).Get("expr").MustString())
+
+			setSearchParams(sc, url.Values{"searchString": []string{"latency"}})
+			result = validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(1), result.TotalCount)
+			require.Equal(t, sc.initialQuery.UID, result.QueryHistory[0].UID)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users search only starred queries, it should return the starred queries",
+		func(t *testing.T, sc scenarioContext) {
+			_, err := sc.service.CreateQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, getCreateCommand(testDatasourceUID, "other"))
+			require.NoError(t, err)
+			_, err = sc.service.StarQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, sc.initialQuery.UID)
+			require.NoError(t, err)
+
+			setSearchParams(sc, url.Values{"onlyStarred": []string{"true"}})
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(1), result.TotalCount)
+			require.Equal(t, sc.initialQuery.UID, result.QueryHistory[0].UID)
+			require.True(t, result.QueryHistory[0].Starred)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users search with paging, it should return a page of queries sorted by time",
+		func(t *testing.T, sc scenarioContext) {
+			origGetTime := getTime
+			t.Cleanup(func() { getTime = origGetTime })
+			getTime = func() time.Time { return origGetTime().Add(time.Minute) }
+			_, err := sc.service.CreateQueryInQueryHistory(context.Background(), sc.reqContext.SignedInUser, getCreateCommand(testDatasourceUID, "other"))
+			require.NoError(t, err)
+
+			setSearchParams(sc, url.Values{"limit": []string{"1"}, "page": []string{"2"}})
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(2), result.TotalCount)
+			require.Len(t, result.QueryHistory, 1)
+			require.Equal(t, sc.initialQuery.UID, result.QueryHistory[0].UID)
+
+			setSearchParams(sc, url.Values{"limit": []string{"1"}, "sort": []string{SortTimeAsc}})
+			result = validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Len(t, result.QueryHistory, 1)
+			require.Equal(t, sc.initialQuery.UID, result.QueryHistory[0].UID)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users search the queries of another user, it should return no queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.UserId = 2
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(0), result.TotalCount)
+		})
+}
@@ -0,0 +1,78 @@
+package queryhistory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStarQueryInQueryHistory(t *testing.T) {
+	testScenarioWithQueryInQueryHistory(t, "When users try to star a query that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to star a query, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			result := validateAndUnMarshalResponse(t, sc.service.starHandler(sc.reqContext))
+			require.True(t, result.Starred)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to star a query that is already starred, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			resp := sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
+
+func TestUnstarQueryInQueryHistory(t *testing.T) {
+	testScenarioWithQueryInQueryHistory(t, "When users try to unstar a query that is not starred, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			resp := sc.service.unstarHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to unstar a starred query, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			resp := sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			result := validateAndUnMarshalResponse(t, sc.service.unstarHandler(sc.reqContext))
+			require.False(t, result.Starred)
+		})
+}
+
+func TestDeleteStaleQueriesInQueryHistory(t *testing.T) {
+	testScenarioWithQueryInQueryHistory(t, "When stale queries are deleted, it should keep the recent and the starred queries",
+		func(t *testing.T, sc scenarioContext) {
+			ctx := context.Background()
+			user := sc.reqContext.SignedInUser
+			starred, err := sc.service.CreateQueryInQueryHistory(ctx, user, getCreateCommand(testDatasourceUID, "starred"))
+			require.NoError(t, err)
+			_, err = sc.service.StarQueryInQueryHistory(ctx, user, starred.UID)
+			require.NoError(t, err)
+
+			deleted, err := sc.service.DeleteStaleQueriesInQueryHistory(ctx, sc.initialQuery.CreatedAt)
+			require.NoError(t, err)
+			require.Equal(t, 0, deleted)
+
+			deleted, err = sc.service.DeleteStaleQueriesInQueryHistory(ctx, sc.initialQuery.CreatedAt+1)
+			require.NoError(t, err)
+			require.Equal(t, 1, deleted)
+
+			result, err := sc.service.SearchInQueryHistory(ctx, user, SearchInQueryHistoryQuery{})
+			require.NoError(t, err)
+			require.Len(t, result.QueryHistory, 1)
+			require.Equal(t, starred.UID, result.QueryHistory[0].UID)
+		})
+}
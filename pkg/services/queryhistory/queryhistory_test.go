@@ -0,0 +1,191 @@
+package queryhistory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+var testDatasourceUID = "NCzh67i"
+
+type scenarioContext struct {
+	ctx          *web.Context
+	service      *QueryHistoryService
+	reqContext   *models.ReqContext
+	sqlStore     *sqlstore.SQLStore
+	initialQuery QueryHistoryDTO
+}
+
+// testScenario is a wrapper around t.Run performing common setup for query history tests.
+// It takes your real test function as a callback.
+func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	t.Run(desc, func(t *testing.T) {
+		ctx := web.Context{Req: &http.Request{}}
+		sqlStore := sqlstore.InitTestDB(t)
+		service := QueryHistoryService{
+			Cfg:      setting.NewCfg(),
+			SQLStore: sqlStore,
+		}
+
+		user := models.SignedInUser{
+			UserId:     1,
+			Name:       "Signed In User",
+			Login:      "signed_in_user",
+			Email:      "signed.in.user@test.com",
+			OrgId:      1,
+			OrgRole:    models.ROLE_VIEWER,
+			LastSeenAt: time.Now(),
+		}
+
+		sc := scenarioContext{
+			ctx:      &ctx,
+			service:  &service,
+			sqlStore: sqlStore,
+			reqContext: &models.ReqContext{
+				Context:      &ctx,
+				SignedInUser: &user,
+			},
+		}
+
+		fn(t, sc)
+	})
+}
+
+// testScenarioWithQueryInQueryHistory is a testScenario with a query already added to the query history.
+func testScenarioWithQueryInQueryHistory(t *testing.T, desc string, fn func(t *testing.T, sc scenarioContext)) {
+	t.Helper()
+
+	testScenario(t, desc, func(t *testing.T, sc scenarioContext) {
+		resp := sc.service.createHandler(sc.reqContext, getCreateCommand(testDatasourceUID, "test"))
+		sc.initialQuery = validateAndUnMarshalResponse(t, resp)
+
+		fn(t, sc)
+	})
+}
+
+func getCreateCommand(datasourceUID string, expr string) CreateQueryInQueryHistoryCommand {
+	return CreateQueryInQueryHistoryCommand{
+		DatasourceUID: datasourceUID,
+		Queries: simplejson.NewFromAny([]interface{}{
+			map[string]interface{}{
+				"refId": "A",
+				"expr":  expr,
+			},
+		}),
+	}
+}
+
+func validateAndUnMarshalResponse(t *testing.T, resp response.Response) QueryHistoryDTO {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+
+	var result QueryHistoryResponse
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+
+	return result.Result
+}
+
+func validateAndUnMarshalSearchResponse(t *testing.T, resp response.Response) QueryHistorySearchResult {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+
+	var result QueryHistorySearchResponse
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+
+	return result.Result
+}
+
+func TestCreateQueryInQueryHistory(t *testing.T) {
+	testScenario(t, "When users try to add a query to the query history, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.createHandler(sc.reqContext, getCreateCommand(testDatasourceUID, "test"))
+			result := validateAndUnMarshalResponse(t, resp)
+
+			require.NotEmpty(t, result.UID)
+			require.Equal(t, testDatasourceUID, result.DatasourceUID)
+			require.Equal(t, int64(1), result.CreatedBy)
+			require.Equal(t, "", result.Comment)
+			require.False(t, result.Starred)
+			require.Equal(t, "test", result.Queries.GetIndex(0).Get("expr").MustString())
+		})
+}
+
+func TestPatchQueryCommentInQueryHistory(t *testing.T) {
+	testScenarioWithQueryInQueryHistory(t, "When users try to update the comment of a query that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.patchCommentHandler(sc.reqContext, PatchQueryCommentInQueryHistoryCommand{Comment: "comment"})
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to update the comment of a query, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			resp := sc.service.patchCommentHandler(sc.reqContext, PatchQueryCommentInQueryHistoryCommand{Comment: "comment"})
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "comment", result.Comment)
+
+			resp = sc.service.patchCommentHandler(sc.reqContext, PatchQueryCommentInQueryHistoryCommand{Comment: ""})
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "", result.Comment)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to update the comment of a query of another user, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			sc.reqContext.SignedInUser.UserId = 2
+			resp := sc.service.patchCommentHandler(sc.reqContext, PatchQueryCommentInQueryHistoryCommand{Comment: "comment"})
+			require.Equal(t, 404, resp.Status())
+		})
+}
+
+func TestDeleteQueryFromQueryHistory(t *testing.T) {
+	testScenarioWithQueryInQueryHistory(t, "When users try to delete a query that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to delete a query, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			resp := sc.service.starHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			result := validateAndUnMarshalSearchResponse(t, sc.service.searchHandler(sc.reqContext))
+			require.Equal(t, int64(0), result.TotalCount)
+
+			err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				starred, err := session.Exist(&QueryHistoryStar{})
+				require.False(t, starred)
+				return err
+			})
+			require.NoError(t, err)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When users try to delete a query in another org, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialQuery.UID})
+			sc.reqContext.SignedInUser.OrgId = 2
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+}
@@ -0,0 +1,43 @@
+package queryhistory
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func writeStarredJoinSQL(query SearchInQueryHistoryQuery, user *models.SignedInUser, builder *sqlstore.SQLBuilder) {
+	if query.OnlyStarred {
+		builder.Write(" INNER JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ?", user.UserId)
+	} else {
+		builder.Write(" LEFT JOIN query_history_star ON query_history_star.query_uid = query_history.uid AND query_history_star.user_id = ?", user.UserId)
+	}
+}
+
+func writeFiltersSQL(query SearchInQueryHistoryQuery, user *models.SignedInUser, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
+	builder.Write(" WHERE query_history.org_id = ? AND query_history.created_by = ?", user.OrgId, user.UserId)
+	if len(query.DatasourceUIDs) > 0 {
+		params := make([]interface{}, 0, len(query.DatasourceUIDs))
+		for _, uid := range query.DatasourceUIDs {
+			params = append(params, uid)
+		}
+		builder.Write(" AND query_history.datasource_uid IN (?"+strings.Repeat(",?", len(query.DatasourceUIDs)-1)+")", params...)
+	}
+	if len(strings.TrimSpace(query.SearchString)) > 0 {
+		builder.Write(" AND (query_history.queries "+sqlStore.Dialect.LikeStr()+" ?", "%"+query.SearchString+"%")
+		builder.Write(" OR query_history.comment "+sqlStore.Dialect.LikeStr()+" ?)", "%"+query.SearchString+"%")
+	}
+}
+
+func writeSortSQL(query SearchInQueryHistoryQuery, builder *sqlstore.SQLBuilder) {
+	if query.Sort == SortTimeAsc {
+		builder.Write(" ORDER BY query_history.created_at ASC")
+	} else {
+		builder.Write(" ORDER BY query_history.created_at DESC")
+	}
+}
+
+func writeLimitSQL(query SearchInQueryHistoryQuery, sqlStore *sqlstore.SQLStore, builder *sqlstore.SQLBuilder) {
+	builder.Write(sqlStore.Dialect.LimitOffset(int64(query.Limit), int64(query.Limit*(query.Page-1))))
+}
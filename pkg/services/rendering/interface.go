@@ -11,6 +11,8 @@ import (
 var ErrTimeout = errors.New("timeout error - you can set timeout in seconds with &timeout url parameter")
 var ErrConcurrentLimitReached = errors.New("rendering concurrent limit reached")
 var ErrRenderUnavailable = errors.New("rendering plugin not available")
+var ErrRenderQueueFull = errors.New("rendering queue is full")
+var ErrRenderQuotaExceeded = errors.New("rendering quota exceeded")
 
 type RenderType string
 
@@ -65,4 +67,5 @@ type Service interface {
 	RenderCSV(ctx context.Context, opts CSVOpts) (*RenderCSVResult, error)
 	RenderErrorImage(error error) (*RenderResult, error)
 	GetRenderUser(key string) (*RenderUser, bool)
+	Status(ctx context.Context) *Status
 }
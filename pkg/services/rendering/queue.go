@@ -0,0 +1,143 @@
+package rendering
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// renderQueue limits the number of renders running at the same time in the renderer, the number of render
+// requests waiting for a free rendering slot, and the number of render requests of a single user or organization.
+type renderQueue struct {
+	// slots has a buffer of the maximum number of concurrent renders, or is nil if they aren't limited
+	slots      chan struct{}
+	maxWaiting int
+	maxPerUser int
+	maxPerOrg  int
+
+	mu      sync.Mutex
+	waiting int
+	perUser map[int64]int
+	perOrg  map[int64]int
+}
+
+func newRenderQueue(maxConcurrent, maxWaiting, maxPerUser, maxPerOrg int) *renderQueue {
+	q := &renderQueue{
+		maxWaiting: maxWaiting,
+		maxPerUser: maxPerUser,
+		maxPerOrg:  maxPerOrg,
+		perUser:    map[int64]int{},
+		perOrg:     map[int64]int{},
+	}
+	if maxConcurrent > 0 {
+		q.slots = make(chan struct{}, maxConcurrent)
+	}
+	return q
+}
+
+// acquire waits for a free rendering slot for a render request of the user and organization, and returns the
+// function releasing it once the render is done. It fails right away if the quota of the user or of the
+// organization is exceeded, or if the queue is full. Requests without user, e.g. the renders of alert
+// notifications, only count towards the quota of their organization.
+func (q *renderQueue) acquire(ctx context.Context, orgID, userID int64) (func(), error) {
+	if err := q.reserve(orgID, userID); err != nil {
+		return nil, err
+	}
+	release := func() {
+		if q.slots != nil {
+			<-q.slots
+		}
+		q.unreserve(orgID, userID)
+	}
+
+	if q.slots == nil {
+		return release, nil
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if !q.wait() {
+		q.unreserve(orgID, userID)
+		metrics.MRenderingRejectedTotal.WithLabelValues("queue_full").Inc()
+		return nil, ErrRenderQueueFull
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		q.doneWaiting()
+		return release, nil
+	case <-ctx.Done():
+		q.doneWaiting()
+		q.unreserve(orgID, userID)
+		return nil, ctx.Err()
+	}
+}
+
+func (q *renderQueue) reserve(orgID, userID int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxPerUser > 0 && userID != 0 && q.perUser[userID] >= q.maxPerUser {
+		metrics.MRenderingRejectedTotal.WithLabelValues("user_quota").Inc()
+		return ErrRenderQuotaExceeded
+	}
+	if q.maxPerOrg > 0 && q.perOrg[orgID] >= q.maxPerOrg {
+		metrics.MRenderingRejectedTotal.WithLabelValues("org_quota").Inc()
+		return ErrRenderQuotaExceeded
+	}
+
+	if userID != 0 {
+		q.perUser[userID]++
+	}
+	q.perOrg[orgID]++
+	return nil
+}
+
+// wait registers a request waiting for a free rendering slot, and returns false if the queue is full.
+func (q *renderQueue) wait() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiting >= q.maxWaiting {
+		return false
+	}
+	q.waiting++
+	metrics.MRenderingQueueWaiting.Set(float64(q.waiting))
+	return true
+}
+
+// doneWaiting unregisters a request that stopped waiting for a free rendering slot.
+func (q *renderQueue) doneWaiting() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.waiting--
+	metrics.MRenderingQueueWaiting.Set(float64(q.waiting))
+}
+
+// unreserve releases the quotas of the user, if any, and of the organization.
+func (q *renderQueue) unreserve(orgID, userID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if userID != 0 {
+		if q.perUser[userID]--; q.perUser[userID] <= 0 {
+			delete(q.perUser, userID)
+		}
+	}
+	if q.perOrg[orgID]--; q.perOrg[orgID] <= 0 {
+		delete(q.perOrg, orgID)
+	}
+}
+
+// waitingCount returns the number of requests waiting for a free rendering slot.
+func (q *renderQueue) waitingCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.waiting
+}
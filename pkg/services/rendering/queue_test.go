@@ -0,0 +1,89 @@
+package rendering
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Without limits should not wait", func(t *testing.T) {
+		q := newRenderQueue(0, 0, 0, 0)
+		for i := 0; i < 10; i++ {
+			_, err := q.acquire(ctx, 1, 1)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Should wait for a free rendering slot", func(t *testing.T) {
+		q := newRenderQueue(1, 1, 0, 0)
+		release, err := q.acquire(ctx, 1, 1)
+		require.NoError(t, err)
+
+		acquired := make(chan struct{})
+		go func() {
+			release, err := q.acquire(ctx, 1, 2)
+			require.NoError(t, err)
+			release()
+			close(acquired)
+		}()
+
+		require.Eventually(t, func() bool { return q.waitingCount() == 1 }, time.Second, time.Millisecond)
+
+		_, err = q.acquire(ctx, 1, 3)
+		require.ErrorIs(t, err, ErrRenderQueueFull)
+
+		release()
+		<-acquired
+		require.Zero(t, q.waitingCount())
+		require.Empty(t, q.perUser)
+		require.Empty(t, q.perOrg)
+	})
+
+	t.Run("Should stop waiting when the context is done", func(t *testing.T) {
+		q := newRenderQueue(1, 1, 0, 0)
+		_, err := q.acquire(ctx, 1, 1)
+		require.NoError(t, err)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = q.acquire(waitCtx, 1, 2)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Zero(t, q.waitingCount())
+		require.NotContains(t, q.perUser, int64(2))
+	})
+
+	t.Run("Should reject requests exceeding the user quota", func(t *testing.T) {
+		q := newRenderQueue(0, 0, 1, 0)
+		release, err := q.acquire(ctx, 1, 1)
+		require.NoError(t, err)
+
+		_, err = q.acquire(ctx, 1, 1)
+		require.ErrorIs(t, err, ErrRenderQuotaExceeded)
+
+		_, err = q.acquire(ctx, 1, 2)
+		require.NoError(t, err)
+
+		release()
+		_, err = q.acquire(ctx, 1, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("Should reject requests exceeding the org quota, including the ones without user", func(t *testing.T) {
+		q := newRenderQueue(0, 0, 0, 2)
+		_, err := q.acquire(ctx, 1, 0)
+		require.NoError(t, err)
+		_, err = q.acquire(ctx, 1, 0)
+		require.NoError(t, err)
+
+		_, err = q.acquire(ctx, 1, 1)
+		require.ErrorIs(t, err, ErrRenderQuotaExceeded)
+
+		_, err = q.acquire(ctx, 2, 1)
+		require.NoError(t, err)
+	})
+}
@@ -44,6 +44,8 @@ type RenderingService struct {
 	domain          string
 	inProgressCount int32
 	version         string
+	failures        failureTracker
+	queue           *renderQueue
 
 	Cfg                *setting.Cfg
 	RemoteCacheService *remotecache.RemoteCache
@@ -63,10 +65,14 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, pm p
 		return nil, fmt.Errorf("failed to create CSVs directory %q: %w", cfg.CSVsDir, err)
 	}
 
+	// a renderer plugin installed locally takes precedence over the remote rendering service
+	renderer := pm.Renderer()
+	remote := cfg.RendererUrl != "" && (renderer == nil || renderer.Remote)
+
 	var domain string
 	// set value used for domain attribute of renderKey cookie
 	switch {
-	case cfg.RendererUrl != "":
+	case remote:
 		// RendererCallbackUrl has already been passed, it won't generate an error.
 		u, err := url.Parse(cfg.RendererCallbackUrl)
 		if err != nil {
@@ -86,40 +92,37 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, pm p
 		PluginManager:      pm,
 		log:                log.New("rendering"),
 		domain:             domain,
+		queue: newRenderQueue(cfg.RendererMaxConcurrentRenders, cfg.RendererQueueSize, cfg.RendererMaxRendersPerUser,
+			cfg.RendererMaxRendersPerOrg),
 	}
 	return s, nil
 }
 
 func (rs *RenderingService) Run(ctx context.Context) error {
-	if rs.remoteAvailable() {
-		rs.log = rs.log.New("renderer", "http")
-
-		version, err := rs.getRemotePluginVersion()
-		if err != nil {
-			rs.log.Info("Couldn't get remote renderer version", "err", err)
-		}
-
-		rs.log.Info("Backend rendering via external http server", "version", version)
-		rs.version = version
-		rs.renderAction = rs.renderViaHTTP
-		rs.renderCSVAction = rs.renderCSVViaHTTP
-		<-ctx.Done()
-		return nil
-	}
-
 	if rs.pluginAvailable() {
-		rs.log = rs.log.New("renderer", "plugin")
 		rs.pluginInfo = rs.PluginManager.Renderer()
+		if rs.pluginInfo.Remote {
+			rs.log = rs.log.New("renderer", "http")
+		} else {
+			rs.log = rs.log.New("renderer", "plugin")
+		}
 
 		if err := rs.startPlugin(ctx); err != nil {
 			return err
 		}
 
 		rs.version = rs.pluginInfo.Info.Version
+		if rs.pluginInfo.Remote {
+			rs.log.Info("Backend rendering via external http server", "version", rs.version)
+		}
 		rs.renderAction = rs.renderViaPlugin
 		rs.renderCSVAction = rs.renderCSVViaPlugin
 		<-ctx.Done()
 
+		if rs.pluginInfo.Remote {
+			return nil
+		}
+
 		// On Windows, Chromium is generating a debug.log file that breaks signature check on next restart
 		debugFilePath := path.Join(rs.pluginInfo.PluginDir, "chrome-win/debug.log")
 		if _, err := os.Stat(debugFilePath); err == nil {
@@ -141,22 +144,33 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 	return nil
 }
 
+// pluginAvailable returns whether a renderer plugin is installed locally or a remote rendering service is
+// configured, both are used through the renderer plugin interface.
 func (rs *RenderingService) pluginAvailable() bool {
 	return rs.PluginManager.Renderer() != nil
 }
 
-func (rs *RenderingService) remoteAvailable() bool {
+// remoteRendering returns whether the rendering is done by a remote rendering service, which needs to reach this
+// Grafana instance through the callback URL.
+func (rs *RenderingService) remoteRendering() bool {
+	if rs.pluginInfo != nil {
+		return rs.pluginInfo.Remote
+	}
 	return rs.Cfg.RendererUrl != ""
 }
 
 func (rs *RenderingService) IsAvailable() bool {
-	return rs.remoteAvailable() || rs.pluginAvailable()
+	return rs.pluginAvailable()
 }
 
 func (rs *RenderingService) Version() string {
 	return rs.version
 }
 
+func (rs *RenderingService) inProgress() int32 {
+	return atomic.LoadInt32(&rs.inProgressCount)
+}
+
 func (rs *RenderingService) RenderErrorImage(err error) (*RenderResult, error) {
 	imgUrl := "public/img/rendering_error.png"
 
@@ -179,6 +193,9 @@ func (rs *RenderingService) Render(ctx context.Context, opts Opts) (*RenderResul
 
 	elapsedTime := time.Since(startTime).Milliseconds()
 	saveMetrics(elapsedTime, err, RenderPNG)
+	if err != nil {
+		rs.failures.record(err)
+	}
 
 	return result, err
 }
@@ -197,6 +214,18 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts) (*RenderResul
 		return rs.renderUnavailableImage(), nil
 	}
 
+	release, err := rs.acquireRenderSlot(ctx, opts.OrgID, opts.UserID, opts.Timeout)
+	if errors.Is(err, ErrRenderQueueFull) || errors.Is(err, ErrRenderQuotaExceeded) {
+		rs.log.Warn("Rendering request rejected", "path", opts.Path, "orgId", opts.OrgID, "userId", opts.UserID, "err", err)
+		return &RenderResult{
+			FilePath: filepath.Join(setting.HomePath, "public/img/rendering_limit.png"),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rs.log.Info("Rendering", "path", opts.Path)
 	if math.IsInf(opts.DeviceScaleFactor, 0) || math.IsNaN(opts.DeviceScaleFactor) || opts.DeviceScaleFactor <= 0 {
 		opts.DeviceScaleFactor = 1
@@ -222,6 +251,9 @@ func (rs *RenderingService) RenderCSV(ctx context.Context, opts CSVOpts) (*Rende
 
 	elapsedTime := time.Since(startTime).Milliseconds()
 	saveMetrics(elapsedTime, err, RenderCSV)
+	if err != nil {
+		rs.failures.record(err)
+	}
 
 	return result, err
 }
@@ -235,6 +267,12 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts) (*Rende
 		return nil, ErrRenderUnavailable
 	}
 
+	release, err := rs.acquireRenderSlot(ctx, opts.OrgID, opts.UserID, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rs.log.Info("Rendering", "path", opts.Path)
 	renderKey, err := rs.generateAndStoreRenderKey(opts.OrgID, opts.UserID, opts.OrgRole)
 	if err != nil {
@@ -251,6 +289,23 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts) (*Rende
 	return rs.renderCSVAction(ctx, renderKey, opts)
 }
 
+// acquireRenderSlot waits for a free rendering slot in the render queue, for at most the rendering timeout, and
+// returns the function releasing it.
+func (rs *RenderingService) acquireRenderSlot(ctx context.Context, orgID, userID int64, timeout time.Duration) (func(), error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	release, err := rs.queue.acquire(ctx, orgID, userID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		rs.log.Info("Rendering timed out waiting in the render queue")
+		return nil, ErrTimeout
+	}
+	return release, err
+}
+
 func (rs *RenderingService) GetRenderUser(key string) (*RenderUser, bool) {
 	val, err := rs.RemoteCacheService.Get(fmt.Sprintf(renderKeyPrefix, key))
 	if err != nil {
@@ -283,7 +338,7 @@ func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
 }
 
 func (rs *RenderingService) getURL(path string) string {
-	if rs.Cfg.RendererUrl != "" {
+	if rs.remoteRendering() {
 		// The backend rendering service can potentially be remote.
 		// So we need to use the root_url to ensure the rendering service
 		// can reach this Grafana instance.
@@ -0,0 +1,126 @@
+package rendering
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// failureWindow is the period over which the rendering failures are counted in the status.
+const failureWindow = time.Hour
+
+// healthCheckTimeout is how long the renderer health check can take before it's considered failed.
+const healthCheckTimeout = 5 * time.Second
+
+// Status is the status of the image renderer reported to the server admins.
+type Status struct {
+	Available bool `json:"available"`
+	// Mode is "plugin" when rendering with a renderer plugin installed locally, "http" when rendering with a remote
+	// rendering service.
+	Mode    string       `json:"mode,omitempty"`
+	Version string       `json:"version,omitempty"`
+	Formats []RenderType `json:"formats"`
+	// Health is the status of the renderer health check, i.e. OK, ERROR or UNKNOWN.
+	Health         string     `json:"health,omitempty"`
+	HealthMessage  string     `json:"healthMessage,omitempty"`
+	InProgress     int32      `json:"inProgress"`
+	Waiting        int        `json:"waiting"`
+	RecentFailures int        `json:"recentFailures"`
+	FailureWindow  string     `json:"failureWindow"`
+	LastFailure    *time.Time `json:"lastFailure,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+}
+
+// failureTracker keeps the times of the rendering failures within the failure window, and the last error.
+type failureTracker struct {
+	mu        sync.Mutex
+	failures  []time.Time
+	lastError string
+	now       func() time.Time
+}
+
+func (f *failureTracker) record(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.prune(), f.clock())
+	f.lastError = err.Error()
+}
+
+// recent returns the number of failures within the failure window, the time of the last one and its error.
+func (f *failureTracker) recent() (int, *time.Time, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = f.prune()
+	if len(f.failures) == 0 {
+		return 0, nil, ""
+	}
+	last := f.failures[len(f.failures)-1]
+	return len(f.failures), &last, f.lastError
+}
+
+func (f *failureTracker) prune() []time.Time {
+	cutoff := f.clock().Add(-failureWindow)
+	i := 0
+	for i < len(f.failures) && f.failures[i].Before(cutoff) {
+		i++
+	}
+	return f.failures[i:]
+}
+
+func (f *failureTracker) clock() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
+
+// Status returns the availability, version, supported formats and health of the image renderer, along with the
+// failures of the recent rendering requests.
+func (rs *RenderingService) Status(ctx context.Context) *Status {
+	status := &Status{
+		Formats:       []RenderType{},
+		InProgress:    rs.inProgress(),
+		FailureWindow: failureWindow.String(),
+	}
+	status.RecentFailures, status.LastFailure, status.LastError = rs.failures.recent()
+	if rs.queue != nil {
+		status.Waiting = rs.queue.waitingCount()
+	}
+
+	renderer := rs.pluginInfo
+	if renderer == nil {
+		renderer = rs.PluginManager.Renderer()
+	}
+	if renderer == nil {
+		return status
+	}
+
+	status.Available = true
+	status.Mode = "plugin"
+	if renderer.Remote {
+		status.Mode = "http"
+	}
+	status.Formats = []RenderType{RenderPNG, RenderCSV}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	result, err := renderer.CheckHealth(ctx)
+	if err != nil {
+		rs.log.Warn("Failed to check renderer health", "err", err)
+		result = &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}
+	}
+	status.Health = result.Status.String()
+	status.HealthMessage = result.Message
+
+	status.Version = renderer.Info.Version
+	if status.Version == "" {
+		status.Version = rs.version
+	}
+
+	return status
+}
@@ -0,0 +1,66 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureTracker(t *testing.T) {
+	now := time.Date(2021, 10, 6, 15, 0, 0, 0, time.UTC)
+	tracker := &failureTracker{now: func() time.Time { return now }}
+
+	count, last, lastErr := tracker.recent()
+	require.Zero(t, count)
+	require.Nil(t, last)
+	require.Empty(t, lastErr)
+
+	tracker.record(errors.New("first"))
+	now = now.Add(30 * time.Minute)
+	tracker.record(ErrTimeout)
+
+	count, last, lastErr = tracker.recent()
+	require.Equal(t, 2, count)
+	require.Equal(t, now, *last)
+	require.Equal(t, ErrTimeout.Error(), lastErr)
+
+	now = now.Add(45 * time.Minute)
+	count, _, _ = tracker.recent()
+	require.Equal(t, 1, count)
+
+	now = now.Add(time.Hour)
+	count, last, _ = tracker.recent()
+	require.Zero(t, count)
+	require.Nil(t, last)
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("Without renderer should report renderer unavailable with recent failures", func(t *testing.T) {
+		rs := &RenderingService{
+			log:           log.New("test"),
+			PluginManager: &fakePluginManager{},
+		}
+		rs.failures.record(ErrRenderUnavailable)
+
+		status := rs.Status(context.Background())
+		require.False(t, status.Available)
+		require.Empty(t, status.Mode)
+		require.Empty(t, status.Formats)
+		require.Empty(t, status.Health)
+		require.Equal(t, 1, status.RecentFailures)
+		require.Equal(t, ErrRenderUnavailable.Error(), status.LastError)
+	})
+}
+
+type fakePluginManager struct {
+	plugins.Manager
+}
+
+func (pm *fakePluginManager) Renderer() *plugins.RendererPlugin {
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package secretexpiry reminds the admins of the secrets of data sources and plugins which expire or are due for
+// rotation soon, according to the expiry dates stored alongside their secure JSON data.
+package secretexpiry
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const serverLockName = "secret expiry warnings"
+
+// ExpiringSecret is a secret of the secure JSON data of a data source or of the settings of a plugin in an
+// organization, which expires or is due for rotation soon, or already did.
+type ExpiringSecret struct {
+	// Kind is either secrets.SecretKindDataSource or secrets.SecretKindPluginSetting.
+	Kind  string `json:"kind"`
+	OrgId int64  `json:"orgId"`
+	// UID is the UID of the data source or the ID of the plugin.
+	UID       string    `json:"uid"`
+	Name      string    `json:"name"`
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Expired   bool      `json:"expired"`
+}
+
+type Service struct {
+	cfg        *setting.Cfg
+	sqlStore   *sqlstore.SQLStore
+	serverLock *serverlock.ServerLockService
+	log        log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, serverLock *serverlock.ServerLockService) *Service {
+	return &Service{
+		cfg:        cfg,
+		sqlStore:   sqlStore,
+		serverLock: serverLock,
+		log:        log.New("secrets.expiry"),
+	}
+}
+
+// IsDisabled returns true if the warnings about the expiring secrets are disabled.
+func (s *Service) IsDisabled() bool {
+	return s.cfg.SecretsExpiryCheckInterval <= 0
+}
+
+// Run logs a warning for each expiring secret at startup and on the configured interval. In a high availability
+// setup, a single instance logs them on each interval.
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.cfg.SecretsExpiryCheckInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.serverLock.LockAndExecute(ctx, serverLockName, interval/2, func(ctx context.Context) {
+			if err := s.warn(ctx); err != nil {
+				s.log.Error("Failed to check the expiry of the secrets", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to lock secret expiry warnings", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) warn(ctx context.Context) error {
+	expiring, err := s.ExpiringSecrets(ctx, s.cfg.SecretsExpiryWarningPeriod)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range expiring {
+		msg := "Secret expires soon"
+		if secret.Expired {
+			msg = "Secret has expired"
+		}
+		s.log.Warn(msg, "kind", secret.Kind, "orgId", secret.OrgId, "uid", secret.UID, "name", secret.Name,
+			"key", secret.Key, "expiresAt", secret.ExpiresAt)
+	}
+	return nil
+}
+
+// ExpiringSecrets returns the secrets of the data sources and plugins of all organizations which expire within the
+// period, or already did, ordered by expiry date.
+func (s *Service) ExpiringSecrets(ctx context.Context, within time.Duration) ([]ExpiringSecret, error) {
+	var dataSources []*models.DataSource
+	var pluginSettings []*models.PluginSetting
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		err := sess.Cols("id", "org_id", "uid", "name", "secure_json_expiry").
+			Where("secure_json_expiry IS NOT NULL").Find(&dataSources)
+		if err != nil {
+			return err
+		}
+		return sess.Cols("id", "org_id", "plugin_id", "secure_json_expiry").
+			Where("secure_json_expiry IS NOT NULL").Find(&pluginSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deadline := now.Add(within)
+	expiring := []ExpiringSecret{}
+	add := func(kind string, orgID int64, uid, name string, expiry map[string]time.Time) {
+		for key, expiresAt := range expiry {
+			if expiresAt.After(deadline) {
+				continue
+			}
+			expiring = append(expiring, ExpiringSecret{
+				Kind:      kind,
+				OrgId:     orgID,
+				UID:       uid,
+				Name:      name,
+				Key:       key,
+				ExpiresAt: expiresAt,
+				Expired:   !expiresAt.After(now),
+			})
+		}
+	}
+	for _, ds := range dataSources {
+		add(secrets.SecretKindDataSource, ds.OrgId, ds.Uid, ds.Name, ds.SecureJsonExpiry)
+	}
+	for _, ps := range pluginSettings {
+		add(secrets.SecretKindPluginSetting, ps.OrgId, ps.PluginId, ps.PluginId, ps.SecureJsonExpiry)
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		if !expiring[i].ExpiresAt.Equal(expiring[j].ExpiresAt) {
+			return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt)
+		}
+		if expiring[i].UID != expiring[j].UID {
+			return expiring[i].UID < expiring[j].UID
+		}
+		return expiring[i].Key < expiring[j].Key
+	})
+	return expiring, nil
+}
@@ -0,0 +1,65 @@
+package secretexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExpiringSecrets(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	s := ProvideService(setting.NewCfg(), sqlStore, nil)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Hour)
+	soon := now.Add(24 * time.Hour)
+	later := now.Add(90 * 24 * time.Hour)
+
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{
+		OrgId: 1, Name: "prometheus", Type: "prometheus", Access: models.DS_ACCESS_PROXY, Uid: "prom",
+		SecureJsonExpiry: map[string]time.Time{"basicAuthPassword": soon, "httpHeaderValue1": later},
+	}))
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{
+		OrgId: 1, Name: "loki", Type: "loki", Access: models.DS_ACCESS_PROXY, Uid: "loki",
+	}))
+	require.NoError(t, sqlStore.UpdatePluginSetting(&models.UpdatePluginSettingCmd{
+		OrgId: 2, PluginId: "test-app", SecureJsonData: map[string]string{"apiToken": "token"},
+		EncryptedSecureJsonData: map[string][]byte{"apiToken": []byte("token")},
+		SecureJsonExpiry:        map[string]time.Time{"apiToken": expired},
+	}))
+
+	expiring, err := s.ExpiringSecrets(context.Background(), 7*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, expiring, 2)
+
+	require.Equal(t, secrets.SecretKindPluginSetting, expiring[0].Kind)
+	require.Equal(t, int64(2), expiring[0].OrgId)
+	require.Equal(t, "test-app", expiring[0].UID)
+	require.Equal(t, "apiToken", expiring[0].Key)
+	require.True(t, expiring[0].ExpiresAt.Equal(expired))
+	require.True(t, expiring[0].Expired)
+
+	require.Equal(t, secrets.SecretKindDataSource, expiring[1].Kind)
+	require.Equal(t, "prom", expiring[1].UID)
+	require.Equal(t, "prometheus", expiring[1].Name)
+	require.Equal(t, "basicAuthPassword", expiring[1].Key)
+	require.False(t, expiring[1].Expired)
+
+	t.Run("Should keep the expiry dates of the secrets which aren't replaced", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdatePluginSetting(&models.UpdatePluginSettingCmd{
+			OrgId: 2, PluginId: "test-app", SecureJsonData: map[string]string{"apiToken": "rotated"},
+			EncryptedSecureJsonData: map[string][]byte{"apiToken": []byte("rotated")},
+		}))
+
+		expiring, err := s.ExpiringSecrets(context.Background(), 7*24*time.Hour)
+		require.NoError(t, err)
+		require.Len(t, expiring, 1)
+		require.Equal(t, "prom", expiring[0].UID)
+	})
+}
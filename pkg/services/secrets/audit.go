@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of the secrets recorded in decryption audit events.
+const (
+	SecretKindDataSource    = "datasource"
+	SecretKindPluginSetting = "plugin_setting"
+	// SecretKindPluginPayload is a payload encrypted by an app plugin for its own storage.
+	SecretKindPluginPayload = "plugin_payload"
+)
+
+// AuditSink records the decryptions of secrets, e.g. to ship them to a compliance system.
+// RecordDecryption is called synchronously after each decryption, it should not block.
+type AuditSink interface {
+	RecordDecryption(ctx context.Context, event DecryptionEvent)
+}
+
+// DecryptionEvent describes a decryption of secrets by the secrets service.
+type DecryptionEvent struct {
+	Time time.Time
+	// Secret is the decrypted secret and Requester what triggered the decryption, as given by the context.
+	Secret    AuditSecret
+	Requester AuditRequester
+	// Keys are the decrypted keys of the secure JSON data, if known.
+	Keys  []string
+	Error error
+}
+
+// AuditSecret identifies an encrypted secret. The fields are empty if the caller didn't set them.
+type AuditSecret struct {
+	Kind  string
+	OrgID int64
+	UID   string
+	Name  string
+}
+
+// AuditRequester identifies the service, user or plugin accessing a secret.
+type AuditRequester struct {
+	Service  string
+	UserID   int64
+	Login    string
+	PluginID string
+}
+
+type auditSecretKey struct{}
+
+type auditRequesterKey struct{}
+
+// WithAuditSecret returns a context recording in the audit events of the decryptions which secret is decrypted.
+func WithAuditSecret(ctx context.Context, secret AuditSecret) context.Context {
+	return context.WithValue(ctx, auditSecretKey{}, secret)
+}
+
+// WithAuditRequester returns a context recording in the audit events of the decryptions who requested them.
+// Fields left empty are taken from the requester already in the context, if any.
+func WithAuditRequester(ctx context.Context, requester AuditRequester) context.Context {
+	parent := AuditRequesterFromContext(ctx)
+	if requester.Service == "" {
+		requester.Service = parent.Service
+	}
+	if requester.UserID == 0 && requester.Login == "" {
+		requester.UserID, requester.Login = parent.UserID, parent.Login
+	}
+	if requester.PluginID == "" {
+		requester.PluginID = parent.PluginID
+	}
+	return context.WithValue(ctx, auditRequesterKey{}, requester)
+}
+
+func AuditSecretFromContext(ctx context.Context) AuditSecret {
+	secret, _ := ctx.Value(auditSecretKey{}).(AuditSecret)
+	return secret
+}
+
+func AuditRequesterFromContext(ctx context.Context) AuditRequester {
+	requester, _ := ctx.Value(auditRequesterKey{}).(AuditRequester)
+	return requester
+}
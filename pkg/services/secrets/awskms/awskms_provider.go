@@ -0,0 +1,110 @@
+package awskms
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProviderID is the name of the AWS KMS provider, as used in the configuration and stored with the data keys.
+const ProviderID = "awskms"
+
+// SettingsSection is the configuration section of the AWS KMS provider.
+const SettingsSection = "security.encryption.awskms"
+
+// dataKeySize is the size of the data keys generated by KMS, the same as the ones generated by the secrets service.
+const dataKeySize = 16
+
+// kmsProvider generates and wraps the data keys with a customer master key stored in AWS KMS. The
+// ciphertext blob returned by KMS contains the metadata it needs to find the master key on decryption.
+type kmsProvider struct {
+	client kmsiface.KMSAPI
+	keyID  string
+}
+
+// IsConfigured returns whether a master key is configured for the AWS KMS provider.
+func IsConfigured(settings setting.Provider) bool {
+	return settings.KeyValue(SettingsSection, "key_id").Value() != ""
+}
+
+func New(settings setting.Provider) (secrets.Provider, error) {
+	section := settings.Section(SettingsSection)
+	keyID := section.KeyValue("key_id").Value()
+	if keyID == "" {
+		return nil, errors.New("missing key_id for the AWS KMS encryption provider")
+	}
+
+	config := aws.NewConfig()
+	if region := section.KeyValue("region").Value(); region != "" {
+		config = config.WithRegion(region)
+	}
+
+	accessKeyID := section.KeyValue("access_key_id").Value()
+	secretAccessKey := section.KeyValue("secret_access_key").Value()
+	if accessKeyID != "" && secretAccessKey != "" {
+		config = config.WithCredentials(credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if roleARN := section.KeyValue("assume_role_arn").Value(); roleARN != "" {
+		config = config.WithCredentials(stscreds.NewCredentials(sess, roleARN))
+		if sess, err = session.NewSession(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return newProvider(kms.New(sess), keyID), nil
+}
+
+func newProvider(client kmsiface.KMSAPI, keyID string) secrets.Provider {
+	return kmsProvider{
+		client: client,
+		keyID:  keyID,
+	}
+}
+
+func (p kmsProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	out, err := p.client.GenerateDataKeyWithContext(ctx, &kms.GenerateDataKeyInput{
+		KeyId:         aws.String(p.keyID),
+		NumberOfBytes: aws.Int64(dataKeySize),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+func (p kmsProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	out, err := p.client.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(p.keyID),
+		Plaintext: blob,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (p kmsProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	// the key ID is passed so that KMS refuses blobs that were encrypted with another master key
+	out, err := p.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(p.keyID),
+		CiphertextBlob: blob,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,80 @@
+package awskms
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS "encrypts" by prefixing the plaintext with the key ID.
+type fakeKMS struct {
+	kmsiface.KMSAPI
+}
+
+func (f fakeKMS) EncryptWithContext(_ aws.Context, in *kms.EncryptInput, _ ...request.Option) (*kms.EncryptOutput, error) {
+	return &kms.EncryptOutput{
+		KeyId:          in.KeyId,
+		CiphertextBlob: append([]byte(aws.StringValue(in.KeyId)+":"), in.Plaintext...),
+	}, nil
+}
+
+func (f fakeKMS) DecryptWithContext(_ aws.Context, in *kms.DecryptInput, _ ...request.Option) (*kms.DecryptOutput, error) {
+	prefix := []byte(aws.StringValue(in.KeyId) + ":")
+	if len(in.CiphertextBlob) < len(prefix) || string(in.CiphertextBlob[:len(prefix)]) != string(prefix) {
+		return nil, errors.New("IncorrectKeyException")
+	}
+	return &kms.DecryptOutput{KeyId: in.KeyId, Plaintext: in.CiphertextBlob[len(prefix):]}, nil
+}
+
+func (f fakeKMS) GenerateDataKeyWithContext(ctx aws.Context, in *kms.GenerateDataKeyInput, _ ...request.Option) (*kms.GenerateDataKeyOutput, error) {
+	plaintext := make([]byte, aws.Int64Value(in.NumberOfBytes))
+	out, err := f.EncryptWithContext(ctx, &kms.EncryptInput{KeyId: in.KeyId, Plaintext: plaintext})
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GenerateDataKeyOutput{KeyId: in.KeyId, Plaintext: plaintext, CiphertextBlob: out.CiphertextBlob}, nil
+}
+
+func TestKMSProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := newProvider(fakeKMS{}, "alias/grafana")
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		assert.NotEqual(t, []byte("data key"), encrypted)
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("decrypting a blob of another master key fails", func(t *testing.T) {
+		encrypted, err := newProvider(fakeKMS{}, "alias/other").Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+
+		_, err = provider.Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("generate data key", func(t *testing.T) {
+		generator, ok := provider.(secrets.DataKeyGenerator)
+		require.True(t, ok)
+
+		dataKey, encrypted, err := generator.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		assert.Len(t, dataKey, dataKeySize)
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, dataKey, decrypted)
+	})
+}
@@ -0,0 +1,214 @@
+package azurekv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProviderID is the name of the Azure Key Vault provider, as used in the configuration and stored with the data keys.
+const ProviderID = "azurekv"
+
+// SettingsSection is the configuration section of the Azure Key Vault provider.
+const SettingsSection = "security.encryption.azurekv"
+
+const (
+	apiVersion = "7.2"
+	algorithm  = "RSA-OAEP-256"
+)
+
+var b64 = base64.RawURLEncoding
+
+// tokenFunc returns an access token for the Key Vault API.
+type tokenFunc func(ctx context.Context) (string, error)
+
+// keyVaultProvider wraps the data keys with an RSA key stored in Azure Key Vault. The wrapped data key is
+// stored together with the versioned ID of the key that wrapped it, so that data keys remain readable
+// after the key is rotated in Key Vault.
+type keyVaultProvider struct {
+	client   *http.Client
+	token    tokenFunc
+	vaultURL string
+	keyName  string
+}
+
+// wrappedKey is the request and response body of the wrap and unwrap operations, and the format
+// of the data keys encrypted by the provider.
+type wrappedKey struct {
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Value     string `json:"value"`
+}
+
+// IsConfigured returns whether a key vault is configured for the Azure Key Vault provider.
+func IsConfigured(settings setting.Provider) bool {
+	return settings.KeyValue(SettingsSection, "vault_url").Value() != ""
+}
+
+// New returns an Azure Key Vault provider. It authenticates with the configured client secret, or with the
+// managed identity of the [azure] section if no client secret is configured.
+func New(settings setting.Provider, azure setting.AzureSettings) (secrets.Provider, error) {
+	section := settings.Section(SettingsSection)
+	vaultURL := strings.TrimSuffix(section.KeyValue("vault_url").Value(), "/")
+	keyName := section.KeyValue("key_name").Value()
+	if vaultURL == "" || keyName == "" {
+		return nil, errors.New("vault_url and key_name are required for the Azure Key Vault encryption provider")
+	}
+
+	credential, err := newCredential(section, azure)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := vaultScope(azure.Cloud)
+	token := func(ctx context.Context) (string, error) {
+		accessToken, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+		if err != nil {
+			return "", err
+		}
+		return accessToken.Token, nil
+	}
+
+	return newProvider(&http.Client{Timeout: 30 * time.Second}, token, vaultURL, keyName), nil
+}
+
+func newCredential(section setting.Section, azure setting.AzureSettings) (azcore.TokenCredential, error) {
+	clientID := section.KeyValue("client_id").Value()
+	clientSecret := section.KeyValue("client_secret").Value()
+	if clientSecret != "" {
+		options := &azidentity.ClientSecretCredentialOptions{AuthorityHost: authorityHost(azure.Cloud)}
+		return azidentity.NewClientSecretCredential(section.KeyValue("tenant_id").Value(), clientID, clientSecret, options)
+	}
+
+	if !azure.ManagedIdentityEnabled {
+		return nil, errors.New("either a client secret or managed identity authentication is required for the Azure Key Vault encryption provider")
+	}
+
+	if clientID == "" {
+		clientID = azure.ManagedIdentityClientId
+	}
+	return azidentity.NewManagedIdentityCredential(clientID, nil)
+}
+
+func authorityHost(cloud string) string {
+	switch cloud {
+	case setting.AzureChina:
+		return azidentity.AzureChina
+	case setting.AzureUSGovernment:
+		return azidentity.AzureGovernment
+	case setting.AzureGermany:
+		return azidentity.AzureGermany
+	default:
+		return azidentity.AzurePublicCloud
+	}
+}
+
+func vaultScope(cloud string) string {
+	switch cloud {
+	case setting.AzureChina:
+		return "https://vault.azure.cn/.default"
+	case setting.AzureUSGovernment:
+		return "https://vault.usgovcloudapi.net/.default"
+	case setting.AzureGermany:
+		return "https://vault.microsoftazure.de/.default"
+	default:
+		return "https://vault.azure.net/.default"
+	}
+}
+
+func newProvider(client *http.Client, token tokenFunc, vaultURL, keyName string) secrets.Provider {
+	return keyVaultProvider{
+		client:   client,
+		token:    token,
+		vaultURL: vaultURL,
+		keyName:  keyName,
+	}
+}
+
+func (p keyVaultProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	// without a version, the current version of the key is used
+	wrapped, err := p.do(ctx, fmt.Sprintf("%s/keys/%s/wrapkey", p.vaultURL, p.keyName), blob)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(wrappedKey{KeyID: wrapped.KeyID, Value: wrapped.Value})
+}
+
+func (p keyVaultProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var wrapped wrappedKey
+	if err := json.Unmarshal(blob, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	// the key ID is read from the database, make sure it refers to the configured key
+	if !strings.HasPrefix(wrapped.KeyID, fmt.Sprintf("%s/keys/%s/", p.vaultURL, p.keyName)) {
+		return nil, fmt.Errorf("data key was wrapped by another key: %s", wrapped.KeyID)
+	}
+
+	value, err := b64.DecodeString(wrapped.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	unwrapped, err := p.do(ctx, wrapped.KeyID+"/unwrapkey", value)
+	if err != nil {
+		return nil, err
+	}
+
+	return b64.DecodeString(unwrapped.Value)
+}
+
+func (p keyVaultProvider) do(ctx context.Context, url string, value []byte) (*wrappedKey, error) {
+	body, err := json.Marshal(wrappedKey{Algorithm: algorithm, Value: b64.EncodeToString(value)})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure Key Vault access token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"?api-version="+apiVersion, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("azure key vault request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result wrappedKey
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,78 @@
+package azurekv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeKeyVault returns a key vault that "wraps" keys by reversing them with the given key version.
+func newFakeKeyVault(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, apiVersion, r.URL.Query().Get("api-version"))
+
+		var req wrappedKey
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, algorithm, req.Algorithm)
+
+		value, err := b64.DecodeString(req.Value)
+		require.NoError(t, err)
+		for i, j := 0, len(value)-1; i < j; i, j = i+1, j-1 {
+			value[i], value[j] = value[j], value[i]
+		}
+
+		switch {
+		case r.URL.Path == "/keys/grafana/wrapkey":
+		case strings.HasSuffix(r.URL.Path, "/unwrapkey"):
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		resp := wrappedKey{KeyID: server.URL + "/keys/grafana/" + version, Value: b64.EncodeToString(value)}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestKeyVaultProvider(t *testing.T) {
+	ctx := context.Background()
+	token := func(context.Context) (string, error) { return "token", nil }
+	server := newFakeKeyVault(t, "v1")
+	provider := newProvider(server.Client(), token, server.URL, "grafana")
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+
+		var wrapped wrappedKey
+		require.NoError(t, json.Unmarshal(encrypted, &wrapped))
+		assert.Equal(t, server.URL+"/keys/grafana/v1", wrapped.KeyID)
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("decrypting a data key wrapped by another key fails", func(t *testing.T) {
+		encrypted, err := newProvider(server.Client(), token, server.URL, "other").Encrypt(ctx, []byte("data key"))
+		require.Error(t, err)
+		assert.Nil(t, encrypted)
+
+		blob, err := json.Marshal(wrappedKey{KeyID: "https://attacker.example.com/keys/grafana/v1", Value: "YWJj"})
+		require.NoError(t, err)
+		_, err = provider.Decrypt(ctx, blob)
+		require.Error(t, err)
+	})
+}
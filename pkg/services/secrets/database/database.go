@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -11,30 +13,102 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const dataKeysTable = "data_keys"
 
+// dedicatedDatabaseSection configures a database storing the data keys apart from the main database.
+const dedicatedDatabaseSection = "security.encryption.database"
+
 var logger = log.New("secrets-store")
 
 type SecretsStoreImpl struct {
 	sqlStore *sqlstore.SQLStore
+	// keysStore stores the data keys, it's the main store unless a dedicated database is configured
+	keysStore *sqlstore.SQLStore
 }
 
+// ProvideService returns the secrets store, with the data keys stored in the database configured in the
+// [security.encryption.database] section if any. The secrets themselves stay in the main database.
+func ProvideService(sqlStore *sqlstore.SQLStore) (*SecretsStoreImpl, error) {
+	if !isDedicatedDatabaseConfigured(sqlStore.Cfg) {
+		return ProvideSecretsStore(sqlStore), nil
+	}
+
+	keysStore, err := sqlstore.NewDedicatedStore(sqlStore.Cfg, dedicatedDatabaseSection, "secrets.db", &migrations.SecretsMigrations{})
+	if err != nil {
+		return nil, err
+	}
+
+	ss := &SecretsStoreImpl{
+		sqlStore:  sqlStore,
+		keysStore: keysStore,
+	}
+	if err := ss.copyDataKeys(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to copy the data keys to the dedicated database: %w", err)
+	}
+	return ss, nil
+}
+
+// copyDataKeys copies the data keys of the main database to the empty dedicated database, so that the secrets
+// encrypted before it was configured can still be decrypted.
+func (ss *SecretsStoreImpl) copyDataKeys(ctx context.Context) error {
+	dataKeys := make([]*secrets.DataKey, 0)
+	err := ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table(dataKeysTable).Find(&dataKeys)
+	})
+	if err != nil || len(dataKeys) == 0 {
+		return err
+	}
+
+	return ss.keysStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		count, err := sess.Table(dataKeysTable).Count()
+		if err != nil || count > 0 {
+			return err
+		}
+
+		for _, dataKey := range dataKeys {
+			if _, err := sess.Table(dataKeysTable).Insert(dataKey); err != nil {
+				return err
+			}
+		}
+		logger.Warn("Copied the data keys of the main database to the dedicated database, delete them from the data_keys table of the main database",
+			"count", len(dataKeys))
+		return nil
+	})
+}
+
+// ProvideSecretsStore returns a secrets store with the data keys stored in the main database.
 func ProvideSecretsStore(sqlStore *sqlstore.SQLStore) *SecretsStoreImpl {
 	return &SecretsStoreImpl{
-		sqlStore: sqlStore,
+		sqlStore:  sqlStore,
+		keysStore: sqlStore,
 	}
 }
 
+func isDedicatedDatabaseConfigured(cfg *setting.Cfg) bool {
+	if cfg == nil || cfg.Raw == nil {
+		return false
+	}
+	sec := cfg.Raw.Section(dedicatedDatabaseSection)
+	return sec.Key("type").String() != "" || sec.Key("url").String() != ""
+}
+
+// isDedicated reports whether the data keys are stored in another database than the secrets.
+func (ss *SecretsStoreImpl) isDedicated() bool {
+	return ss.keysStore != ss.sqlStore
+}
+
 func (ss *SecretsStoreImpl) GetDataKey(ctx context.Context, name string) (*secrets.DataKey, error) {
 	dataKey := &secrets.DataKey{}
 	var exists bool
 
-	err := ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+	err := ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		var err error
 		exists, err = sess.Table(dataKeysTable).
-			Where("name = ? AND active = ?", name, ss.sqlStore.Dialect.BooleanStr(true)).
+			Where("name = ?", name).
 			Get(dataKey)
 		return err
 	})
@@ -51,9 +125,33 @@ func (ss *SecretsStoreImpl) GetDataKey(ctx context.Context, name string) (*secre
 	return dataKey, nil
 }
 
+func (ss *SecretsStoreImpl) GetCurrentDataKey(ctx context.Context, label string) (*secrets.DataKey, error) {
+	dataKey := &secrets.DataKey{}
+	var exists bool
+
+	err := ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Table(dataKeysTable).
+			Where("label = ? AND active = ?", label, ss.keysStore.Dialect.BooleanStr(true)).
+			Get(dataKey)
+		return err
+	})
+
+	if err != nil {
+		logger.Error("Failed getting current data key", "err", err, "label", label)
+		return nil, fmt.Errorf("failed getting current data key: %w", err)
+	}
+
+	if !exists {
+		return nil, secrets.ErrDataKeyNotFound
+	}
+
+	return dataKey, nil
+}
+
 func (ss *SecretsStoreImpl) GetAllDataKeys(ctx context.Context) ([]*secrets.DataKey, error) {
 	result := make([]*secrets.DataKey, 0)
-	err := ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+	err := ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		err := sess.Table(dataKeysTable).Find(&result)
 		return err
 	})
@@ -61,12 +159,23 @@ func (ss *SecretsStoreImpl) GetAllDataKeys(ctx context.Context) ([]*secrets.Data
 }
 
 func (ss *SecretsStoreImpl) CreateDataKey(ctx context.Context, dataKey secrets.DataKey) error {
-	return ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
-		return ss.CreateDataKeyWithDBSession(ctx, dataKey, sess.Session)
+	return ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return insertDataKey(dataKey, sess.Session)
 	})
 }
 
-func (ss *SecretsStoreImpl) CreateDataKeyWithDBSession(_ context.Context, dataKey secrets.DataKey, sess *xorm.Session) error {
+// CreateDataKeyWithDBSession creates the data key with the session of a transaction of the main database, unless
+// the data keys are stored in a dedicated database.
+func (ss *SecretsStoreImpl) CreateDataKeyWithDBSession(ctx context.Context, dataKey secrets.DataKey, sess *xorm.Session) error {
+	if ss.isDedicated() {
+		return ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return insertDataKey(dataKey, sess.Session)
+		})
+	}
+	return insertDataKey(dataKey, sess)
+}
+
+func insertDataKey(dataKey secrets.DataKey, sess *xorm.Session) error {
 	if !dataKey.Active {
 		return fmt.Errorf("cannot insert deactivated data keys")
 	}
@@ -83,9 +192,151 @@ func (ss *SecretsStoreImpl) DeleteDataKey(ctx context.Context, name string) erro
 		return fmt.Errorf("data key name is missing")
 	}
 
-	return ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+	return ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		_, err := sess.Table(dataKeysTable).Delete(&secrets.DataKey{Name: name})
 
 		return err
 	})
 }
+
+func (ss *SecretsStoreImpl) DisableDataKeys(ctx context.Context, scopes []string) error {
+	return ss.keysStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		query := fmt.Sprintf("UPDATE %s SET active = ?, updated = ? WHERE active = ?", dataKeysTable)
+		args := []interface{}{query, ss.keysStore.Dialect.BooleanStr(false), time.Now(), ss.keysStore.Dialect.BooleanStr(true)}
+		if len(scopes) > 0 {
+			args[0] = query + " AND scope IN (?" + strings.Repeat(",?", len(scopes)-1) + ")"
+			for _, scope := range scopes {
+				args = append(args, scope)
+			}
+		}
+		_, err := sess.Exec(args...)
+		return err
+	})
+}
+
+func (ss *SecretsStoreImpl) ImportDataKey(ctx context.Context, dataKey secrets.DataKey) (bool, error) {
+	var imported bool
+	err := ss.keysStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Table(dataKeysTable).Where("name = ?", dataKey.Name).Exist()
+		if err != nil || exists {
+			return err
+		}
+
+		dataKey.Active = false
+		dataKey.Created = time.Now()
+		dataKey.Updated = dataKey.Created
+		_, err = sess.Table(dataKeysTable).Insert(&dataKey)
+		imported = err == nil
+		return err
+	})
+	return imported, err
+}
+
+type secureJsonDataRecord struct {
+	Id    int64  `xorm:"id"`
+	OrgId int64  `xorm:"org_id"`
+	Key   string `xorm:"row_key"`
+	Data  string `xorm:"data"`
+}
+
+func (ss *SecretsStoreImpl) CountSecureJsonData(ctx context.Context, column secrets.SecureJsonDataColumn) (int64, error) {
+	var count int64
+	err := ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		count, err = sess.Table(column.Table).Where(ss.quote(column.Column) + " IS NOT NULL").Count()
+		return err
+	})
+	return count, err
+}
+
+func (ss *SecretsStoreImpl) ListSecureJsonData(ctx context.Context, column secrets.SecureJsonDataColumn, afterID int64, limit int) ([]*secrets.SecureJsonDataRow, error) {
+	records := make([]*secureJsonDataRecord, 0)
+	err := ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := fmt.Sprintf("SELECT id, org_id, %s AS row_key, %s AS data FROM %s WHERE id > ? AND %s IS NOT NULL ORDER BY id ASC %s",
+			ss.quote(column.KeyColumn), ss.quote(column.Column), ss.quote(column.Table), ss.quote(column.Column),
+			ss.sqlStore.Dialect.Limit(int64(limit)))
+		return sess.SQL(rawSQL, afterID).Find(&records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*secrets.SecureJsonDataRow, 0, len(records))
+	for _, record := range records {
+		row := &secrets.SecureJsonDataRow{Id: record.Id, OrgId: record.OrgId, Key: record.Key, Raw: []byte(record.Data)}
+		if err := json.Unmarshal(row.Raw, &row.Data); err != nil {
+			return nil, fmt.Errorf("invalid %s of %s %d: %w", column.Column, column.Table, record.Id, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (ss *SecretsStoreImpl) UpdateSecureJsonData(ctx context.Context, column secrets.SecureJsonDataColumn, row *secrets.SecureJsonDataRow, data map[string][]byte) (bool, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+
+	var updated bool
+	err = ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ? AND %s = ?", ss.quote(column.Table), ss.quote(column.Column), ss.quote(column.Column))
+		result, err := sess.Exec(rawSQL, string(raw), row.Id, string(row.Raw))
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		updated = affected > 0
+		return err
+	})
+	return updated, err
+}
+
+func (ss *SecretsStoreImpl) ImportSecureJsonData(ctx context.Context, column secrets.SecureJsonDataColumn, orgID int64, key string, data map[string][]byte) (bool, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+
+	var updated bool
+	err = ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE org_id = ? AND %s = ?", ss.quote(column.Table), ss.quote(column.Column), ss.quote(column.KeyColumn))
+		result, err := sess.Exec(rawSQL, string(raw), orgID, key)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		updated = affected > 0
+		return err
+	})
+	return updated, err
+}
+
+func (ss *SecretsStoreImpl) ReplaceSecureJsonData(ctx context.Context, column secrets.SecureJsonDataColumn, orgID int64, key string, current map[string][]byte, data map[string][]byte) (bool, error) {
+	currentRaw, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+
+	var updated bool
+	err = ss.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE org_id = ? AND %s = ? AND %s = ?", ss.quote(column.Table), ss.quote(column.Column),
+			ss.quote(column.KeyColumn), ss.quote(column.Column))
+		result, err := sess.Exec(rawSQL, string(raw), orgID, key, string(currentRaw))
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		updated = affected > 0
+		return err
+	})
+	return updated, err
+}
+
+func (ss *SecretsStoreImpl) quote(name string) string {
+	return ss.sqlStore.Dialect.Quote(name)
+}
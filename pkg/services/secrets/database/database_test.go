@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsStore_DedicatedDatabase(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	mainStore := ProvideSecretsStore(sqlStore)
+	require.NoError(t, mainStore.CreateDataKey(ctx, secrets.DataKey{Active: true, Name: "existing", Label: "existing", Provider: "secretKey",
+		Scope: "root", EncryptedData: []byte("existing")}))
+
+	sec, err := sqlStore.Cfg.Raw.NewSection(dedicatedDatabaseSection)
+	require.NoError(t, err)
+	sec.Key("type").SetValue("sqlite3")
+	sec.Key("path").SetValue(filepath.Join(t.TempDir(), "secrets.db"))
+	t.Cleanup(func() {
+		sqlStore.Cfg.Raw.DeleteSection(dedicatedDatabaseSection)
+	})
+
+	store, err := ProvideService(sqlStore)
+	require.NoError(t, err)
+	require.True(t, store.isDedicated())
+
+	t.Run("existing data keys are copied to the dedicated database", func(t *testing.T) {
+		dataKey, err := store.GetDataKey(ctx, "existing")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("existing"), dataKey.EncryptedData)
+	})
+
+	t.Run("new data keys are only stored in the dedicated database", func(t *testing.T) {
+		require.NoError(t, store.CreateDataKey(ctx, secrets.DataKey{Active: true, Name: "new", Label: "new", Provider: "secretKey",
+			Scope: "root", EncryptedData: []byte("new")}))
+
+		_, err := store.GetDataKey(ctx, "new")
+		require.NoError(t, err)
+		_, err = mainStore.GetDataKey(ctx, "new")
+		require.ErrorIs(t, err, secrets.ErrDataKeyNotFound)
+	})
+}
@@ -33,9 +33,14 @@ func (f FakeSecretsService) DecryptJsonData(_ context.Context, sjd map[string][]
 	}
 	return result, nil
 }
+func (f FakeSecretsService) DecryptMany(_ context.Context, payloads map[string][]byte) (map[string][]byte, error) {
+	return payloads, nil
+}
 func (f FakeSecretsService) GetDecryptedValue(_ context.Context, sjd map[string][]byte, key, fallback string) string {
 	if value, ok := sjd[key]; ok {
 		return string(value)
 	}
 	return fallback
 }
+
+func (f FakeSecretsService) InvalidateDecryptionCache(_ secrets.AuditSecret) {}
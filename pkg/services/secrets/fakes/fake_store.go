@@ -45,3 +45,60 @@ func (f FakeSecretsStore) DeleteDataKey(_ context.Context, name string) error {
 	delete(f.store, name)
 	return nil
 }
+
+func (f FakeSecretsStore) GetCurrentDataKey(_ context.Context, label string) (*secrets.DataKey, error) {
+	for _, key := range f.store {
+		if key.Label == label && key.Active {
+			return key, nil
+		}
+	}
+	return nil, secrets.ErrDataKeyNotFound
+}
+
+func (f FakeSecretsStore) DisableDataKeys(_ context.Context, scopes []string) error {
+	for _, key := range f.store {
+		if len(scopes) > 0 && !containsString(scopes, key.Scope) {
+			continue
+		}
+		key.Active = false
+	}
+	return nil
+}
+
+func (f FakeSecretsStore) ImportDataKey(_ context.Context, dataKey secrets.DataKey) (bool, error) {
+	if _, exists := f.store[dataKey.Name]; exists {
+		return false, nil
+	}
+	dataKey.Active = false
+	f.store[dataKey.Name] = &dataKey
+	return true, nil
+}
+
+func (f FakeSecretsStore) CountSecureJsonData(_ context.Context, _ secrets.SecureJsonDataColumn) (int64, error) {
+	return 0, nil
+}
+
+func (f FakeSecretsStore) ListSecureJsonData(_ context.Context, _ secrets.SecureJsonDataColumn, _ int64, _ int) ([]*secrets.SecureJsonDataRow, error) {
+	return []*secrets.SecureJsonDataRow{}, nil
+}
+
+func (f FakeSecretsStore) UpdateSecureJsonData(_ context.Context, _ secrets.SecureJsonDataColumn, _ *secrets.SecureJsonDataRow, _ map[string][]byte) (bool, error) {
+	return false, nil
+}
+
+func (f FakeSecretsStore) ImportSecureJsonData(_ context.Context, _ secrets.SecureJsonDataColumn, _ int64, _ string, _ map[string][]byte) (bool, error) {
+	return false, nil
+}
+
+func (f FakeSecretsStore) ReplaceSecureJsonData(_ context.Context, _ secrets.SecureJsonDataColumn, _ int64, _ string, _ map[string][]byte, _ map[string][]byte) (bool, error) {
+	return false, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
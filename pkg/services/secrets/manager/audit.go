@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// RegisterAuditSink adds a sink recording the decryptions of secrets.
+func (s *SecretsService) RegisterAuditSink(sink secrets.AuditSink) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.auditSinks = append(s.auditSinks, sink)
+}
+
+// auditDecryption records the decryption in the audit sinks, with the secret and requester of the context.
+func (s *SecretsService) auditDecryption(ctx context.Context, keys []string, err error) {
+	s.mtx.RLock()
+	sinks := s.auditSinks
+	s.mtx.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	event := secrets.DecryptionEvent{
+		Time:      time.Now(),
+		Secret:    secrets.AuditSecretFromContext(ctx),
+		Requester: secrets.AuditRequesterFromContext(ctx),
+		Keys:      keys,
+		Error:     err,
+	}
+	for _, sink := range sinks {
+		sink.RecordDecryption(ctx, event)
+	}
+}
+
+func sortedKeys(sjd map[string][]byte) []string {
+	keys := make([]string, 0, len(sjd))
+	for key := range sjd {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logAuditSink records the decryptions in the server log, enabled by audit_decryption in the
+// [security.encryption] section.
+type logAuditSink struct {
+	log log.Logger
+}
+
+func newLogAuditSink() logAuditSink {
+	return logAuditSink{log: log.New("secrets.audit")}
+}
+
+func (s logAuditSink) RecordDecryption(_ context.Context, event secrets.DecryptionEvent) {
+	ctx := []interface{}{
+		"kind", event.Secret.Kind,
+		"orgId", event.Secret.OrgID,
+		"uid", event.Secret.UID,
+		"name", event.Secret.Name,
+		"keys", event.Keys,
+		"service", event.Requester.Service,
+		"userId", event.Requester.UserID,
+		"login", event.Requester.Login,
+		"pluginId", event.Requester.PluginID,
+	}
+
+	if event.Error != nil {
+		s.log.Warn("Failed to decrypt secret", append(ctx, "error", event.Error)...)
+		return
+	}
+	s.log.Info("Decrypted secret", ctx...)
+}
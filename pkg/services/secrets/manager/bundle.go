@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// ErrInvalidSecretsBundle is returned when a secrets bundle can't be imported by this instance.
+var ErrInvalidSecretsBundle = errors.New("invalid secrets bundle")
+
+// SecretsImportResult reports the import of a secrets bundle.
+type SecretsImportResult struct {
+	// DataKeys is the number of data keys stored, the others already existed.
+	DataKeys int `json:"dataKeys"`
+	// Imported is the number of rows whose secrets were replaced, NotFound the rows of the bundle missing
+	// from this instance.
+	Imported int `json:"imported"`
+	NotFound int `json:"notFound"`
+}
+
+// ExportSecrets returns a bundle of the stored secrets encrypted with data keys and of these data keys. The
+// secrets encrypted with the secret key can't be decrypted by another instance, so the rows with such secrets
+// are skipped and their number is returned. They can be migrated to an encryption provider beforehand.
+func (s *SecretsService) ExportSecrets(ctx context.Context) (*secrets.SecretsBundle, int64, error) {
+	bundle := &secrets.SecretsBundle{
+		Version:    secrets.SecretsBundleVersion,
+		ExportedAt: time.Now(),
+		DataKeys:   []secrets.SecretsBundleDataKey{},
+		Secrets:    []secrets.SecretsBundleSecret{},
+	}
+	dataKeys := make(map[string]bool)
+	var skipped int64
+
+	for _, column := range secureJsonDataColumns {
+		var afterID int64
+		for {
+			rows, err := s.store.ListSecureJsonData(ctx, column, afterID, reEncryptionBatchSize)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			for _, row := range rows {
+				afterID = row.Id
+				keyNames, err := rowDataKeys(row)
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid %s of %s %d: %w", column.Column, column.Table, row.Id, err)
+				}
+				if keyNames == nil {
+					logger.Warn("Skipping secrets encrypted with the secret key", "table", column.Table, "id", row.Id)
+					skipped++
+					continue
+				}
+
+				for _, name := range keyNames {
+					if dataKeys[name] {
+						continue
+					}
+					dataKey, err := s.store.GetDataKey(ctx, name)
+					if err != nil {
+						return nil, 0, fmt.Errorf("failed to get data key %s: %w", name, err)
+					}
+					bundle.DataKeys = append(bundle.DataKeys, secrets.SecretsBundleDataKey{
+						Name:          dataKey.Name,
+						Label:         dataKey.Label,
+						Scope:         dataKey.Scope,
+						Provider:      dataKey.Provider,
+						EncryptedData: dataKey.EncryptedData,
+					})
+					dataKeys[name] = true
+				}
+
+				bundle.Secrets = append(bundle.Secrets, secrets.SecretsBundleSecret{
+					Table:  column.Table,
+					Column: column.Column,
+					OrgId:  row.OrgId,
+					Key:    row.Key,
+					Data:   row.Data,
+				})
+			}
+
+			if len(rows) < reEncryptionBatchSize {
+				break
+			}
+		}
+	}
+
+	return bundle, skipped, nil
+}
+
+// rowDataKeys returns the names of the data keys encrypting the secrets of the row, or nil if any of them is
+// encrypted with the secret key.
+func rowDataKeys(row *secrets.SecureJsonDataRow) ([]string, error) {
+	names := make([]string, 0, len(row.Data))
+	for _, value := range row.Data {
+		env, err := parseEnvelope(value)
+		if err != nil {
+			return nil, err
+		}
+		if env.keyName == "" {
+			return nil, nil
+		}
+		names = append(names, env.keyName)
+	}
+	return names, nil
+}
+
+// ImportSecrets stores the data keys of the bundle and replaces the secrets of the rows of the bundle with the
+// same organization and key. Nothing is written unless all the data keys can be decrypted by the configured
+// encryption providers. The imported data keys are inactive, new secrets are encrypted with data keys of
+// this instance.
+func (s *SecretsService) ImportSecrets(ctx context.Context, bundle *secrets.SecretsBundle) (SecretsImportResult, error) {
+	var result SecretsImportResult
+	if bundle.Version != secrets.SecretsBundleVersion {
+		return result, fmt.Errorf("%w: unsupported version %d", ErrInvalidSecretsBundle, bundle.Version)
+	}
+
+	for _, dataKey := range bundle.DataKeys {
+		_, err := s.decryptDataKey(ctx, &secrets.DataKey{Provider: dataKey.Provider, EncryptedData: dataKey.EncryptedData})
+		if err != nil {
+			return result, fmt.Errorf("%w: failed to decrypt data key %s with encryption provider '%s': %s", ErrInvalidSecretsBundle,
+				dataKey.Name, dataKey.Provider, err)
+		}
+	}
+
+	columns := make([]secrets.SecureJsonDataColumn, 0, len(bundle.Secrets))
+	for _, secret := range bundle.Secrets {
+		column, exists := findSecureJsonDataColumn(secret.Table, secret.Column)
+		if !exists {
+			return result, fmt.Errorf("%w: unknown secrets column %s of %s", ErrInvalidSecretsBundle, secret.Column, secret.Table)
+		}
+		columns = append(columns, column)
+	}
+
+	for _, dataKey := range bundle.DataKeys {
+		imported, err := s.store.ImportDataKey(ctx, secrets.DataKey{
+			Name:          dataKey.Name,
+			Label:         dataKey.Label,
+			Scope:         dataKey.Scope,
+			Provider:      dataKey.Provider,
+			EncryptedData: dataKey.EncryptedData,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to import data key %s: %w", dataKey.Name, err)
+		}
+		if imported {
+			result.DataKeys++
+		}
+	}
+
+	for i, secret := range bundle.Secrets {
+		imported, err := s.store.ImportSecureJsonData(ctx, columns[i], secret.OrgId, secret.Key, secret.Data)
+		if err != nil {
+			return result, fmt.Errorf("failed to import the secrets of %s %s of organization %d: %w", secret.Table, secret.Key, secret.OrgId, err)
+		}
+		if !imported {
+			logger.Warn("Skipping secrets of a missing row", "table", secret.Table, "orgId", secret.OrgId, "key", secret.Key)
+			result.NotFound++
+			continue
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+func findSecureJsonDataColumn(table, column string) (secrets.SecureJsonDataColumn, bool) {
+	for _, c := range secureJsonDataColumns {
+		if c.Table == table && c.Column == column {
+			return c, true
+		}
+	}
+	return secrets.SecureJsonDataColumn{}, false
+}
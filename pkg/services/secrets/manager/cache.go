@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	decryptionCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "secrets",
+		Name:      "decryption_cache_hits_total",
+		Help:      "Number of decryptions served from the cache of decrypted values",
+	})
+	decryptionCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "secrets",
+		Name:      "decryption_cache_misses_total",
+		Help:      "Number of decryptions not found in the cache of decrypted values",
+	})
+	decryptionCacheInvalidations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "secrets",
+		Name:      "decryption_cache_invalidations_total",
+		Help:      "Number of decrypted values removed from the cache because their secret was updated",
+	})
+	decryptionCacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.ExporterName,
+		Subsystem: "secrets",
+		Name:      "decryption_cache_entries",
+		Help:      "Number of decrypted values in the cache",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(decryptionCacheHits, decryptionCacheMisses, decryptionCacheInvalidations, decryptionCacheEntries)
+}
+
+// decryptionCache caches decrypted values by the hash of their ciphertext for a short time, to avoid decrypting
+// the same secrets on every request. A TTL of zero disables it.
+type decryptionCache struct {
+	ttl time.Duration
+
+	mtx         sync.Mutex
+	entries     map[[sha256.Size]byte]decryptionCacheItem
+	nextCleanup time.Time
+}
+
+type decryptionCacheItem struct {
+	value  []byte
+	secret secrets.AuditSecret
+	expiry time.Time
+}
+
+func newDecryptionCache(ttl time.Duration) *decryptionCache {
+	return &decryptionCache{
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]decryptionCacheItem),
+	}
+}
+
+func (c *decryptionCache) get(payload []byte) ([]byte, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mtx.Lock()
+	item, exists := c.entries[sha256.Sum256(payload)]
+	c.mtx.Unlock()
+	if !exists || item.expiry.Before(time.Now()) {
+		decryptionCacheMisses.Inc()
+		return nil, false
+	}
+
+	decryptionCacheHits.Inc()
+	return append([]byte(nil), item.value...), true
+}
+
+// set caches the decrypted value of the payload, with the secret it belongs to for invalidation.
+func (c *decryptionCache) set(payload []byte, value []byte, secret secrets.AuditSecret) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if now.After(c.nextCleanup) {
+		for key, item := range c.entries {
+			if item.expiry.Before(now) {
+				delete(c.entries, key)
+			}
+		}
+		c.nextCleanup = now.Add(c.ttl)
+	}
+
+	c.entries[sha256.Sum256(payload)] = decryptionCacheItem{
+		value:  append([]byte(nil), value...),
+		secret: secret,
+		expiry: now.Add(c.ttl),
+	}
+	decryptionCacheEntries.Set(float64(len(c.entries)))
+}
+
+// invalidate removes the decrypted values of the secret. Secrets are matched by kind and organization, then
+// by UID, or by name if the UID is empty. All the secrets of the kind in the organization are removed if
+// both are empty.
+func (c *decryptionCache) invalidate(secret secrets.AuditSecret) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for key, item := range c.entries {
+		cached := item.secret
+		if cached.Kind != secret.Kind || cached.OrgID != secret.OrgID {
+			continue
+		}
+		if secret.UID != "" && cached.UID != secret.UID {
+			continue
+		}
+		if secret.UID == "" && secret.Name != "" && cached.Name != secret.Name {
+			continue
+		}
+
+		delete(c.entries, key)
+		decryptionCacheInvalidations.Inc()
+	}
+	decryptionCacheEntries.Set(float64(len(c.entries)))
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptionCache(t *testing.T) {
+	secret := secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "abc", Name: "test"}
+
+	t.Run("a zero TTL disables the cache", func(t *testing.T) {
+		c := newDecryptionCache(0)
+		c.set([]byte("payload"), []byte("value"), secret)
+
+		_, ok := c.get([]byte("payload"))
+		assert.False(t, ok)
+	})
+
+	t.Run("expired values are not returned", func(t *testing.T) {
+		c := newDecryptionCache(time.Minute)
+		c.set([]byte("payload"), []byte("value"), secret)
+		item := c.entries[sha256.Sum256([]byte("payload"))]
+		item.expiry = time.Now().Add(-time.Second)
+		c.entries[sha256.Sum256([]byte("payload"))] = item
+
+		_, ok := c.get([]byte("payload"))
+		assert.False(t, ok)
+	})
+
+	tcs := []struct {
+		desc        string
+		invalidated secrets.AuditSecret
+		removed     bool
+	}{
+		{desc: "same UID", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "abc"}, removed: true},
+		{desc: "other UID", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "def", Name: "test"}},
+		{desc: "same name", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, Name: "test"}, removed: true},
+		{desc: "other name", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, Name: "other"}},
+		{desc: "whole organization", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1}, removed: true},
+		{desc: "other organization", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 2, UID: "abc"}},
+		{desc: "other kind", invalidated: secrets.AuditSecret{Kind: secrets.SecretKindPluginSetting, OrgID: 1, Name: "test"}},
+	}
+	for _, tc := range tcs {
+		t.Run("invalidating "+tc.desc, func(t *testing.T) {
+			c := newDecryptionCache(time.Minute)
+			c.set([]byte("payload"), []byte("value"), secret)
+			c.invalidate(tc.invalidated)
+
+			value, ok := c.get([]byte("payload"))
+			assert.Equal(t, !tc.removed, ok)
+			if ok {
+				assert.Equal(t, []byte("value"), value)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"golang.org/x/sync/errgroup"
+)
+
+// DecryptMany decrypts the payloads keyed by name, e.g. the secure JSON data of a data source, and records the
+// decryption in the audit sinks. Unlike decrypting the payloads one by one, the data keys of the payloads
+// are looked up concurrently, once each, so that the encryption providers are called at most once per data
+// key that isn't cached.
+func (s *SecretsService) DecryptMany(ctx context.Context, payloads map[string][]byte) (map[string][]byte, error) {
+	decrypted, err := s.decryptMany(ctx, payloads)
+	s.auditDecryption(ctx, sortedKeys(payloads), err)
+	return decrypted, err
+}
+
+func (s *SecretsService) decryptMany(ctx context.Context, payloads map[string][]byte) (map[string][]byte, error) {
+	decrypted := make(map[string][]byte, len(payloads))
+	envelopes := make(map[string]envelope, len(payloads))
+	keyNames := make(map[string]struct{})
+	for name, payload := range payloads {
+		if value, ok := s.decryptionCache.get(payload); ok {
+			decrypted[name] = value
+			continue
+		}
+
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("unable to decrypt empty payload")
+		}
+		env, err := parseEnvelope(payload)
+		if err != nil {
+			return nil, err
+		}
+		envelopes[name] = env
+		if env.keyName != "" {
+			keyNames[env.keyName] = struct{}{}
+		}
+	}
+
+	dataKeys, err := s.dataKeys(ctx, keyNames)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := secrets.AuditSecretFromContext(ctx)
+	for name, env := range envelopes {
+		op, opCtx := startOperation(ctx, opDecrypt, LegacyProvider)
+		dataKey := dataKeys[env.keyName]
+		if env.keyName != "" {
+			op.provider = dataKey.provider
+		}
+
+		value, err := s.decryptEnvelope(opCtx, env, dataKey)
+		op.finish(err)
+		if err != nil {
+			return nil, err
+		}
+
+		s.decryptionCache.set(payloads[name], value, secret)
+		decrypted[name] = value
+	}
+
+	return decrypted, nil
+}
+
+// dataKeys looks up the data keys with the given names concurrently.
+func (s *SecretsService) dataKeys(ctx context.Context, names map[string]struct{}) (map[string]dataKeyCacheItem, error) {
+	dataKeys := make(map[string]dataKeyCacheItem, len(names))
+	var mtx sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	for name := range names {
+		name := name
+		g.Go(func() error {
+			item, err := s.dataKey(ctx, name)
+			if err != nil {
+				return err
+			}
+
+			mtx.Lock()
+			dataKeys[name] = item
+			mtx.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return dataKeys, nil
+}
@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+)
+
+// Payloads encrypted by the secrets service are wrapped in an envelope naming the data key they were
+// encrypted with, so that several data keys can be in use at the same time. The format of the envelope:
+//
+//	version 1: #<base64 key name>#<ciphertext>
+//	version 2: #2:<algorithm>:<base64 key name>#<ciphertext>
+//
+// Payloads without an envelope are encrypted with the secret key.
+const (
+	envelopeV1             = 1
+	envelopeV2             = 2
+	currentEnvelopeVersion = envelopeV2
+)
+
+// algorithmAESCFB is AES-256 in CFB mode with a key derived by PBKDF2, as implemented by the encryption
+// service. Version 1 envelopes and payloads without an envelope always use it.
+const algorithmAESCFB = "aescfb"
+
+const currentAlgorithm = algorithmAESCFB
+
+var b64 = base64.RawStdEncoding
+
+type envelope struct {
+	version    int
+	algorithm  string
+	keyName    string
+	ciphertext []byte
+}
+
+func (e envelope) encode() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('#')
+	if e.version >= envelopeV2 {
+		buf.WriteString(strconv.Itoa(e.version))
+		buf.WriteByte(':')
+		buf.WriteString(e.algorithm)
+		buf.WriteByte(':')
+	}
+	buf.WriteString(b64.EncodeToString([]byte(e.keyName)))
+	buf.WriteByte('#')
+	buf.Write(e.ciphertext)
+	return buf.Bytes()
+}
+
+// parseEnvelope returns the envelope of the payload. The version and key name are empty for payloads
+// encrypted directly with the secret key.
+func parseEnvelope(payload []byte) (envelope, error) {
+	if len(payload) == 0 || payload[0] != '#' {
+		return envelope{algorithm: algorithmAESCFB, ciphertext: payload}, nil
+	}
+
+	payload = payload[1:]
+	endOfHeader := bytes.IndexByte(payload, '#')
+	if endOfHeader == -1 {
+		return envelope{}, fmt.Errorf("could not find valid key in encrypted payload")
+	}
+	header := payload[:endOfHeader]
+	env := envelope{version: envelopeV1, algorithm: algorithmAESCFB, ciphertext: payload[endOfHeader+1:]}
+
+	// the base64 alphabet doesn't contain colons, only versioned headers do
+	if parts := bytes.Split(header, []byte{':'}); len(parts) > 1 {
+		if len(parts) != 3 {
+			return envelope{}, fmt.Errorf("invalid encrypted payload header")
+		}
+
+		version, err := strconv.Atoi(string(parts[0]))
+		if err != nil || version < envelopeV2 {
+			return envelope{}, fmt.Errorf("invalid encrypted payload version '%s'", parts[0])
+		}
+		if version > currentEnvelopeVersion {
+			return envelope{}, fmt.Errorf("unsupported encrypted payload version %d", version)
+		}
+
+		env.version = version
+		env.algorithm = string(parts[1])
+		header = parts[2]
+	}
+
+	keyName, err := b64.DecodeString(string(header))
+	if err != nil {
+		return envelope{}, err
+	}
+	env.keyName = string(keyName)
+
+	return env, nil
+}
@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Run("current version round trip", func(t *testing.T) {
+		encoded := envelope{version: currentEnvelopeVersion, algorithm: currentAlgorithm, keyName: "abc123", ciphertext: []byte("ciphertext#with:separators")}.encode()
+		assert.Equal(t, "#2:aescfb:YWJjMTIz#ciphertext#with:separators", string(encoded))
+
+		env, err := parseEnvelope(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, envelope{version: envelopeV2, algorithm: algorithmAESCFB, keyName: "abc123", ciphertext: []byte("ciphertext#with:separators")}, env)
+	})
+
+	t.Run("version 1", func(t *testing.T) {
+		env, err := parseEnvelope([]byte("#MjAyMS0xMC0wNi9yb290QHNlY3JldEtleQ#ciphertext"))
+		require.NoError(t, err)
+		assert.Equal(t, envelope{version: envelopeV1, algorithm: algorithmAESCFB, keyName: "2021-10-06/root@secretKey", ciphertext: []byte("ciphertext")}, env)
+	})
+
+	t.Run("encrypted with the secret key", func(t *testing.T) {
+		env, err := parseEnvelope([]byte("ciphertext"))
+		require.NoError(t, err)
+		assert.Equal(t, envelope{algorithm: algorithmAESCFB, ciphertext: []byte("ciphertext")}, env)
+	})
+
+	t.Run("invalid headers", func(t *testing.T) {
+		for _, payload := range []string{"#YWJj", "#3:aescfb:YWJj#ciphertext", "#x:aescfb:YWJj#ciphertext", "#2:YWJj#ciphertext", "#1:aescfb:YWJj#ciphertext"} {
+			_, err := parseEnvelope([]byte(payload))
+			assert.Error(t, err, payload)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+// providerHealthProbe is the payload encrypted and decrypted by each provider to check it's reachable.
+var providerHealthProbe = []byte("grafana-secrets-health-probe")
+
+// ProviderHealth is the result of the check of an encryption provider.
+type ProviderHealth struct {
+	// Default is whether the provider encrypts the new data keys.
+	Default bool
+	Error   error
+}
+
+// CheckProviders checks that each configured encryption provider is reachable, by encrypting and decrypting a probe
+// payload with it. Nothing is stored.
+func (s *SecretsService) CheckProviders(ctx context.Context) map[string]ProviderHealth {
+	results := make(map[string]ProviderHealth, len(s.providers))
+	for id, provider := range s.providers {
+		health := ProviderHealth{Default: id == s.defaultProvider}
+		encrypted, err := provider.Encrypt(ctx, providerHealthProbe)
+		if err == nil {
+			var decrypted []byte
+			decrypted, err = provider.Decrypt(ctx, encrypted)
+			if err == nil && !bytes.Equal(decrypted, providerHealthProbe) {
+				err = errors.New("decrypted probe doesn't match")
+			}
+		}
+		health.Error = err
+		results[id] = health
+	}
+	return results
+}
@@ -0,0 +1,31 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/stretchr/testify/require"
+)
+
+type unreachableProvider struct {
+	fakeGeneratorProvider
+}
+
+func (unreachableProvider) Encrypt(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSecretsService_CheckProviders(t *testing.T) {
+	svc := setupTestService(t, fakes.NewFakeSecretsStore())
+	svc.providers["generator"] = fakeGeneratorProvider{}
+	svc.providers["unreachable"] = unreachableProvider{}
+
+	results := svc.CheckProviders(context.Background())
+	require.Len(t, results, 3)
+	require.Equal(t, ProviderHealth{Default: true}, results[defaultProvider])
+	require.Equal(t, ProviderHealth{}, results["generator"])
+	require.EqualError(t, results["unreachable"].Error, "connection refused")
+	require.False(t, results["unreachable"].Default)
+}
@@ -24,20 +24,23 @@ func SetupTestService(tb testing.TB, db *sqlstore.SQLStore) *SecretsService {
 
 func setupTestService(tb testing.TB, store secrets.Store) *SecretsService {
 	tb.Helper()
-	defaultKey := "SdlklWklckeLS"
-	if len(setting.SecretKey) > 0 {
-		defaultKey = setting.SecretKey
-	}
+	// the secret key matches the global one, so that the service decrypts the secrets encrypted with it directly
 	raw, err := ini.Load([]byte(`
 		[security]
-		secret_key = ` + defaultKey))
+		secret_key = ` + setting.SecretKey))
 	require.NoError(tb, err)
-	settings := &setting.OSSImpl{Cfg: &setting.Cfg{Raw: raw}}
+	cfg := &setting.Cfg{Raw: raw}
+	settings := &setting.OSSImpl{Cfg: cfg}
 
-	return ProvideSecretsService(
+	s, err := ProvideSecretsService(
 		store,
 		bus.New(),
 		ossencryption.ProvideService(),
 		settings,
+		cfg,
+		nil,
 	)
+	require.NoError(tb, err)
+
+	return s
 }
@@ -0,0 +1,61 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	ol "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operations of the secrets service recorded in metrics and traces. Data key operations are performed by
+// the encryption providers, e.g. by calling a KMS.
+const (
+	opEncrypt         = "encrypt"
+	opDecrypt         = "decrypt"
+	opEncryptDataKey  = "encrypt_data_key"
+	opDecryptDataKey  = "decrypt_data_key"
+	opGenerateDataKey = "generate_data_key"
+)
+
+var operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.ExporterName,
+	Subsystem: "secrets",
+	Name:      "operation_duration_seconds",
+	Help:      "Duration of the encryption operations of the secrets service, by encryption provider and outcome",
+	Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"operation", "provider", "outcome"})
+
+func init() {
+	prometheus.MustRegister(operationDuration)
+}
+
+// operation records the duration and the outcome of an encryption operation in metrics and in a span.
+type operation struct {
+	name     string
+	provider string
+	start    time.Time
+	span     opentracing.Span
+}
+
+func startOperation(ctx context.Context, name string, provider string) (*operation, context.Context) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "secrets."+name)
+	return &operation{name: name, provider: provider, start: time.Now(), span: span}, ctx
+}
+
+// finish records the operation, which failed if err isn't nil.
+func (o *operation) finish(err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+		ext.Error.Set(o.span, true)
+		o.span.LogFields(ol.String("error", err.Error()))
+	}
+
+	o.span.SetTag("provider", o.provider)
+	o.span.Finish()
+	operationDuration.WithLabelValues(o.name, o.provider, outcome).Observe(time.Since(o.start).Seconds())
+}
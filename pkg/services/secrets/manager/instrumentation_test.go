@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsService_Instrumentation(t *testing.T) {
+	svc := setupTestService(t, fakes.NewFakeSecretsStore())
+	ctx := context.Background()
+
+	operationCount := func(operation, provider, outcome string) uint64 {
+		m := &dto.Metric{}
+		require.NoError(t, operationDuration.WithLabelValues(operation, provider, outcome).(prometheus.Metric).Write(m))
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	encrypts := operationCount(opEncrypt, defaultProvider, "success")
+	encryptedDataKeys := operationCount(opEncryptDataKey, defaultProvider, "success")
+	decrypts := operationCount(opDecrypt, defaultProvider, "success")
+	failures := operationCount(opDecrypt, LegacyProvider, "failure")
+
+	encrypted, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithScope("org:1"))
+	require.NoError(t, err)
+	_, err = svc.decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	_, err = svc.decrypt(ctx, []byte("#invalid"))
+	require.Error(t, err)
+
+	assert.Equal(t, encrypts+1, operationCount(opEncrypt, defaultProvider, "success"))
+	assert.Equal(t, encryptedDataKeys+1, operationCount(opEncryptDataKey, defaultProvider, "success"))
+	assert.Equal(t, decrypts+1, operationCount(opDecrypt, defaultProvider, "success"))
+	assert.Equal(t, failures+1, operationCount(opDecrypt, LegacyProvider, "failure"))
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DataKeyUsage describes a data key and the number of stored secrets encrypted with it.
+type DataKeyUsage struct {
+	Name     string    `json:"name"`
+	Label    string    `json:"label"`
+	Scope    string    `json:"scope"`
+	Provider string    `json:"provider"`
+	Active   bool      `json:"active"`
+	Created  time.Time `json:"created"`
+	// Rotated is when the data key was deactivated, it's not set for active data keys.
+	Rotated *time.Time `json:"rotated,omitempty"`
+	// Secrets is the number of stored secrets encrypted with the data key. Inactive data keys without secrets
+	// can be deleted.
+	Secrets int64 `json:"secrets"`
+}
+
+// DataKeyInventory lists the data keys and the use of the stored secrets.
+type DataKeyInventory struct {
+	DataKeys []DataKeyUsage `json:"dataKeys"`
+	// LegacySecrets is the number of secrets encrypted with the secret key only, MissingSecrets the ones
+	// encrypted with data keys that don't exist.
+	LegacySecrets  int64 `json:"legacySecrets"`
+	MissingSecrets int64 `json:"missingSecrets"`
+}
+
+// DataKeyInventory returns the data keys with the number of stored secrets encrypted with each of them, ordered
+// by creation date.
+func (s *SecretsService) DataKeyInventory(ctx context.Context) (DataKeyInventory, error) {
+	inventory := DataKeyInventory{DataKeys: []DataKeyUsage{}}
+
+	dataKeys, err := s.store.GetAllDataKeys(ctx)
+	if err != nil {
+		return inventory, err
+	}
+	sort.Slice(dataKeys, func(i, j int) bool {
+		return dataKeys[i].Created.Before(dataKeys[j].Created)
+	})
+
+	usages := make(map[string]int, len(dataKeys))
+	for _, dataKey := range dataKeys {
+		usage := DataKeyUsage{
+			Name:     dataKey.Name,
+			Label:    dataKey.Label,
+			Scope:    dataKey.Scope,
+			Provider: dataKey.Provider,
+			Active:   dataKey.Active,
+			Created:  dataKey.Created,
+		}
+		if !dataKey.Active {
+			rotated := dataKey.Updated
+			usage.Rotated = &rotated
+		}
+		usages[dataKey.Name] = len(inventory.DataKeys)
+		inventory.DataKeys = append(inventory.DataKeys, usage)
+	}
+
+	for _, column := range secureJsonDataColumns {
+		var afterID int64
+		for {
+			rows, err := s.store.ListSecureJsonData(ctx, column, afterID, reEncryptionBatchSize)
+			if err != nil {
+				return inventory, err
+			}
+
+			for _, row := range rows {
+				afterID = row.Id
+				for key, value := range row.Data {
+					env, err := parseEnvelope(value)
+					if err != nil {
+						return inventory, fmt.Errorf("invalid %s of %s of %s %d: %w", key, column.Column, column.Table, row.Id, err)
+					}
+
+					i, exists := usages[env.keyName]
+					switch {
+					case env.keyName == "":
+						inventory.LegacySecrets++
+					case !exists:
+						inventory.MissingSecrets++
+					default:
+						inventory.DataKeys[i].Secrets++
+					}
+				}
+			}
+
+			if len(rows) < reEncryptionBatchSize {
+				break
+			}
+		}
+	}
+
+	return inventory, nil
+}
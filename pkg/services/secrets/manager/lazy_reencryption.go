@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// reEncryptLegacySecrets re-encrypts the secrets of the secure JSON data encrypted with the secret key only
+// with a data key of the current provider, and replaces them in the row of the secret of the context. The row
+// is left unchanged if it has been modified in the meantime. Failures are only logged, as the secrets are
+// re-encrypted again by a later decryption.
+func (s *SecretsService) reEncryptLegacySecrets(ctx context.Context, sjd map[string][]byte, decrypted map[string]string) {
+	if !s.reEncryptLegacyOnRead {
+		return
+	}
+
+	secret := secrets.AuditSecretFromContext(ctx)
+	column, key, ok := secretRow(secret)
+	if !ok {
+		return
+	}
+
+	scope := secrets.WithoutScope()
+	if s.dataKeysPerOrg && secret.OrgID > 0 {
+		scope = secrets.WithOrgScope(secret.OrgID)
+	}
+
+	var data map[string][]byte
+	for name, value := range sjd {
+		env, err := parseEnvelope(value)
+		if err != nil || env.keyName != "" {
+			continue
+		}
+
+		if data == nil {
+			data = make(map[string][]byte, len(sjd))
+			for name, value := range sjd {
+				data[name] = value
+			}
+		}
+		data[name], err = s.Encrypt(ctx, []byte(decrypted[name]), scope)
+		if err != nil {
+			logger.Warn("Failed to re-encrypt legacy secrets", "table", column.Table, "orgId", secret.OrgID, "key", key, "error", err)
+			return
+		}
+	}
+	if data == nil {
+		return
+	}
+
+	replaced, err := s.store.ReplaceSecureJsonData(ctx, column, secret.OrgID, key, sjd, data)
+	if err != nil {
+		logger.Warn("Failed to store re-encrypted legacy secrets", "table", column.Table, "orgId", secret.OrgID, "key", key, "error", err)
+		return
+	}
+	if replaced {
+		logger.Debug("Re-encrypted legacy secrets", "table", column.Table, "orgId", secret.OrgID, "key", key)
+	}
+}
+
+// secretRow returns the column and the key of the row storing the secret, if it's known.
+func secretRow(secret secrets.AuditSecret) (secrets.SecureJsonDataColumn, string, bool) {
+	var column secrets.SecureJsonDataColumn
+	var key string
+	switch secret.Kind {
+	case secrets.SecretKindDataSource:
+		column, _ = findSecureJsonDataColumn("data_source", "secure_json_data")
+		key = secret.UID
+	case secrets.SecretKindPluginSetting:
+		column, _ = findSecureJsonDataColumn("plugin_setting", "secure_json_data")
+		key = secret.Name
+	}
+	return column, key, key != "" && secret.OrgID > 0
+}
@@ -1,23 +1,36 @@
 package manager
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/awskms"
+	"github.com/grafana/grafana/pkg/services/secrets/azurekv"
 	grafana "github.com/grafana/grafana/pkg/services/secrets/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/secrets/pkcs11"
+	"github.com/grafana/grafana/pkg/services/secrets/pluginprovider"
+	"github.com/grafana/grafana/pkg/services/secrets/vault"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
+var logger = log.New("secrets")
+
 const defaultProvider = "secretKey"
 
+// encryptionSection is the configuration section selecting the provider used to encrypt new data keys.
+const encryptionSection = "security.encryption"
+
 type SecretsService struct {
 	store    secrets.Store
 	bus      bus.Bus
@@ -26,42 +39,161 @@ type SecretsService struct {
 
 	defaultProvider string
 	providers       map[string]secrets.Provider
-	dataKeyCache    map[string]dataKeyCacheItem
+	// dataKeysPerOrg moves the secrets of organizations from root data keys to data keys of their organization
+	dataKeysPerOrg bool
+	// reEncryptLegacyOnRead re-encrypts the decrypted secrets encrypted with the secret key only
+	reEncryptLegacyOnRead bool
+	// algorithms are the encryption services able to decrypt payloads, keyed by the algorithm of the envelope
+	algorithms map[string]encryption.Service
+
+	mtx          sync.RWMutex
+	dataKeyCache map[string]dataKeyCacheItem
+	// currentDataKeys caches the name of the active data key of each label
+	currentDataKeys map[string]dataKeyCacheItem
+
+	auditSinks []secrets.AuditSink
+	// decryptionCache caches the decrypted values returned to the callers of the service
+	decryptionCache *decryptionCache
+
+	reEncryptionMtx    sync.Mutex
+	reEncryptionStatus secrets.ReEncryptionStatus
+}
+
+func ProvideSecretsService(store secrets.Store, bus bus.Bus, enc encryption.Service, settings setting.Provider, cfg *setting.Cfg,
+	pluginManager plugins.Manager) (*SecretsService, error) {
+	providers, err := newProviders(settings, cfg, enc, pluginManager)
+	if err != nil {
+		return nil, err
+	}
+
+	currentProvider := settings.KeyValue(encryptionSection, "provider").MustString(defaultProvider)
+	if _, exists := providers[currentProvider]; !exists {
+		return nil, fmt.Errorf("encryption provider '%s' is not configured", currentProvider)
+	}
+
+	decryptionCacheTTL, err := gtime.ParseDuration(settings.KeyValue(encryptionSection, "decryption_cache_ttl").MustString("1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid decryption_cache_ttl: %w", err)
+	}
+
+	s := &SecretsService{
+		store:                 store,
+		bus:                   bus,
+		enc:                   enc,
+		settings:              settings,
+		defaultProvider:       currentProvider,
+		providers:             providers,
+		dataKeysPerOrg:        settings.KeyValue(encryptionSection, "data_keys_per_org").MustBool(false),
+		reEncryptLegacyOnRead: settings.KeyValue(encryptionSection, "reencrypt_legacy_on_read").MustBool(false),
+		algorithms:            map[string]encryption.Service{algorithmAESCFB: enc},
+		dataKeyCache:          make(map[string]dataKeyCacheItem),
+		currentDataKeys:       make(map[string]dataKeyCacheItem),
+		decryptionCache:       newDecryptionCache(decryptionCacheTTL),
+	}
+
+	if settings.KeyValue(encryptionSection, "audit_decryption").MustBool(false) {
+		s.auditSinks = append(s.auditSinks, newLogAuditSink())
+	}
+
+	return s, nil
 }
 
-func ProvideSecretsService(store secrets.Store, bus bus.Bus, enc encryption.Service, settings setting.Provider) *SecretsService {
+// newProviders returns the configured encryption providers keyed by their name. The secret key provider is
+// always available, as it's needed to decrypt the data keys created before another provider was selected.
+// The plugin provider is available when a secrets manager plugin is installed.
+func newProviders(settings setting.Provider, cfg *setting.Cfg, enc encryption.Service, pluginManager plugins.Manager) (map[string]secrets.Provider, error) {
 	providers := map[string]secrets.Provider{
 		defaultProvider: grafana.New(settings, enc),
 	}
 
-	s := &SecretsService{
-		store:           store,
-		bus:             bus,
-		enc:             enc,
-		settings:        settings,
-		defaultProvider: defaultProvider,
-		providers:       providers,
-		dataKeyCache:    make(map[string]dataKeyCacheItem),
+	if awskms.IsConfigured(settings) {
+		provider, err := awskms.New(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the AWS KMS encryption provider: %w", err)
+		}
+		providers[awskms.ProviderID] = provider
+	}
+
+	if azurekv.IsConfigured(settings) {
+		provider, err := azurekv.New(settings, cfg.Azure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the Azure Key Vault encryption provider: %w", err)
+		}
+		providers[azurekv.ProviderID] = provider
+	}
+
+	if vault.IsConfigured(settings) {
+		provider, err := vault.New(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the Vault encryption provider: %w", err)
+		}
+		providers[vault.ProviderID] = provider
+	}
+
+	if pkcs11.IsConfigured(settings) {
+		provider, err := pkcs11.New(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the PKCS#11 encryption provider: %w", err)
+		}
+		providers[pkcs11.ProviderID] = provider
+	}
+
+	if pluginManager != nil {
+		if plugin := pluginManager.SecretsManager(); plugin != nil {
+			providers[pluginprovider.ProviderID] = pluginprovider.New(plugin)
+		}
 	}
 
-	return s
+	return providers, nil
 }
 
+// dataKeyCacheTTL is how long decrypted data keys are cached. It's also the delay for other Grafana
+// instances to stop using data keys that have been rotated.
+const dataKeyCacheTTL = 15 * time.Minute
+
 type dataKeyCacheItem struct {
-	expiry  time.Time
-	dataKey []byte
+	expiry   time.Time
+	name     string
+	provider string
+	dataKey  []byte
 }
 
-var b64 = base64.RawStdEncoding
+func (i dataKeyCacheItem) expired() bool {
+	return i.expiry.Before(time.Now()) && !i.expiry.IsZero()
+}
 
+// Encrypt encrypts the payload with the current data key of the scope, encrypted by the default provider. When
+// data keys per organization are enabled, the secrets of an organization given by the secret of the context are
+// encrypted with data keys of the organization instead of root data keys.
 func (s *SecretsService) Encrypt(ctx context.Context, payload []byte, opt secrets.EncryptionOptions) ([]byte, error) {
 	scope := opt()
-	keyName := fmt.Sprintf("%s/%s@%s", time.Now().Format("2006-01-02"), scope, s.defaultProvider)
+	if secret := secrets.AuditSecretFromContext(ctx); s.dataKeysPerOrg && scope == secrets.WithoutScope()() && secret.OrgID > 0 {
+		scope = secrets.WithOrgScope(secret.OrgID)()
+	}
+	return s.encrypt(ctx, payload, scope, s.defaultProvider)
+}
+
+// rowScope returns the scope of the data key re-encrypting the secrets of a row, whose current data key has
+// the given scope. When data keys per organization are enabled, the secrets of an organization encrypted
+// with root data keys are moved to the data keys of the organization.
+func (s *SecretsService) rowScope(row *secrets.SecureJsonDataRow, scope string) string {
+	if s.dataKeysPerOrg && scope == secrets.WithoutScope()() && row.OrgId > 0 {
+		return secrets.WithOrgScope(row.OrgId)()
+	}
+	return scope
+}
+
+// encrypt encrypts the payload with the current data key of the scope encrypted by the given provider.
+func (s *SecretsService) encrypt(ctx context.Context, payload []byte, scope string, providerID string) (_ []byte, err error) {
+	op, ctx := startOperation(ctx, opEncrypt, providerID)
+	defer func() { op.finish(err) }()
+
+	label := fmt.Sprintf("%s/%s@%s", time.Now().Format("2006-01-02"), scope, providerID)
 
-	dataKey, err := s.dataKey(ctx, keyName)
+	keyName, dataKey, err := s.currentDataKey(ctx, label)
 	if err != nil {
 		if errors.Is(err, secrets.ErrDataKeyNotFound) {
-			dataKey, err = s.newDataKey(ctx, keyName, scope)
+			keyName, dataKey, err = s.newDataKey(ctx, label, scope, providerID)
 			if err != nil {
 				return nil, err
 			}
@@ -75,49 +207,82 @@ func (s *SecretsService) Encrypt(ctx context.Context, payload []byte, opt secret
 		return nil, err
 	}
 
-	prefix := make([]byte, b64.EncodedLen(len(keyName))+2)
-	b64.Encode(prefix[1:], []byte(keyName))
-	prefix[0] = '#'
-	prefix[len(prefix)-1] = '#'
+	return envelope{
+		version:    currentEnvelopeVersion,
+		algorithm:  currentAlgorithm,
+		keyName:    keyName,
+		ciphertext: encrypted,
+	}.encode(), nil
+}
 
-	blob := make([]byte, len(prefix)+len(encrypted))
-	copy(blob, prefix)
-	copy(blob[len(prefix):], encrypted)
+// Decrypt decrypts the payload and records the decryption in the audit sinks.
+func (s *SecretsService) Decrypt(ctx context.Context, payload []byte) ([]byte, error) {
+	decrypted, err := s.decryptCached(ctx, payload)
+	s.auditDecryption(ctx, nil, err)
+	return decrypted, err
+}
 
-	return blob, nil
+// InvalidateDecryptionCache removes the cached decrypted values of the secret, it's called when the secret
+// is updated or deleted.
+func (s *SecretsService) InvalidateDecryptionCache(secret secrets.AuditSecret) {
+	s.decryptionCache.invalidate(secret)
 }
 
-func (s *SecretsService) Decrypt(ctx context.Context, payload []byte) ([]byte, error) {
+// decryptCached decrypts the payload, or returns its decrypted value from the cache. The value is cached
+// for the secret of the context, so that it can be invalidated.
+func (s *SecretsService) decryptCached(ctx context.Context, payload []byte) ([]byte, error) {
+	if decrypted, ok := s.decryptionCache.get(payload); ok {
+		return decrypted, nil
+	}
+
+	decrypted, err := s.decrypt(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.decryptionCache.set(payload, decrypted, secrets.AuditSecretFromContext(ctx))
+	return decrypted, nil
+}
+
+func (s *SecretsService) decrypt(ctx context.Context, payload []byte) (_ []byte, err error) {
+	op, ctx := startOperation(ctx, opDecrypt, LegacyProvider)
+	defer func() { op.finish(err) }()
+
 	if len(payload) == 0 {
 		return nil, fmt.Errorf("unable to decrypt empty payload")
 	}
 
-	var dataKey []byte
+	env, err := parseEnvelope(payload)
+	if err != nil {
+		return nil, err
+	}
 
-	if payload[0] != '#' {
-		secretKey := s.settings.KeyValue("security", "secret_key").Value()
-		dataKey = []byte(secretKey)
-	} else {
-		payload = payload[1:]
-		endOfKey := bytes.Index(payload, []byte{'#'})
-		if endOfKey == -1 {
-			return nil, fmt.Errorf("could not find valid key in encrypted payload")
-		}
-		b64Key := payload[:endOfKey]
-		payload = payload[endOfKey+1:]
-		key := make([]byte, b64.DecodedLen(len(b64Key)))
-		_, err := b64.Decode(key, b64Key)
+	var dataKey dataKeyCacheItem
+	if env.keyName != "" {
+		dataKey, err = s.dataKey(ctx, env.keyName)
 		if err != nil {
 			return nil, err
 		}
+		op.provider = dataKey.provider
+	}
 
-		dataKey, err = s.dataKey(ctx, string(key))
-		if err != nil {
-			return nil, err
-		}
+	return s.decryptEnvelope(ctx, env, dataKey)
+}
+
+// decryptEnvelope decrypts the ciphertext of the envelope with the data key, or with the secret key if the
+// envelope has no data key.
+func (s *SecretsService) decryptEnvelope(ctx context.Context, env envelope, dataKey dataKeyCacheItem) ([]byte, error) {
+	enc, exists := s.algorithms[env.algorithm]
+	if !exists {
+		return nil, fmt.Errorf("unsupported encryption algorithm '%s'", env.algorithm)
 	}
 
-	return s.enc.Decrypt(ctx, payload, string(dataKey))
+	key := dataKey.dataKey
+	if env.keyName == "" {
+		key = []byte(s.settings.KeyValue("security", "secret_key").Value())
+	}
+
+	return enc.Decrypt(ctx, env.ciphertext, string(key))
 }
 
 func (s *SecretsService) EncryptJsonData(ctx context.Context, kv map[string]string, opt secrets.EncryptionOptions) (map[string][]byte, error) {
@@ -133,22 +298,27 @@ func (s *SecretsService) EncryptJsonData(ctx context.Context, kv map[string]stri
 	return encrypted, nil
 }
 
+// DecryptJsonData decrypts the secure JSON data. If enabled, the secrets encrypted with the secret key only
+// are re-encrypted with a data key and replaced in the row of the secret of the context.
 func (s *SecretsService) DecryptJsonData(ctx context.Context, sjd map[string][]byte) (map[string]string, error) {
-	decrypted := make(map[string]string)
-	for key, data := range sjd {
-		decryptedData, err := s.Decrypt(ctx, data)
-		if err != nil {
-			return nil, err
-		}
+	decryptedData, err := s.decryptMany(ctx, sjd)
+	s.auditDecryption(ctx, sortedKeys(sjd), err)
+	if err != nil {
+		return nil, err
+	}
 
-		decrypted[key] = string(decryptedData)
+	decrypted := make(map[string]string, len(decryptedData))
+	for key, value := range decryptedData {
+		decrypted[key] = string(value)
 	}
+	s.reEncryptLegacySecrets(ctx, sjd, decrypted)
 	return decrypted, nil
 }
 
 func (s *SecretsService) GetDecryptedValue(ctx context.Context, sjd map[string][]byte, key, fallback string) string {
 	if value, ok := sjd[key]; ok {
-		decryptedData, err := s.Decrypt(ctx, value)
+		decryptedData, err := s.decryptCached(ctx, value)
+		s.auditDecryption(ctx, []string{key}, err)
 		if err != nil {
 			return fallback
 		}
@@ -168,77 +338,139 @@ func newRandomDataKey() ([]byte, error) {
 	return rawDataKey, nil
 }
 
-// newDataKey creates a new random DEK, caches it and returns its value
-func (s *SecretsService) newDataKey(ctx context.Context, name string, scope string) ([]byte, error) {
-	// 1. Create new DEK
-	dataKey, err := newRandomDataKey()
-	if err != nil {
-		return nil, err
-	}
-	provider, exists := s.providers[s.defaultProvider]
+// newDataKey creates a new random DEK, caches it and returns its name and value
+func (s *SecretsService) newDataKey(ctx context.Context, label string, scope string, providerID string) (string, []byte, error) {
+	provider, exists := s.providers[providerID]
 	if !exists {
-		return nil, fmt.Errorf("could not find encryption provider '%s'", s.defaultProvider)
+		return "", nil, fmt.Errorf("could not find encryption provider '%s'", providerID)
 	}
 
-	// 2. Encrypt it
-	encrypted, err := provider.Encrypt(ctx, dataKey)
-	if err != nil {
-		return nil, err
+	// 1. Create new DEK and 2. encrypt it, by the provider itself if it generates data keys
+	var dataKey, encrypted []byte
+	var err error
+	if generator, ok := provider.(secrets.DataKeyGenerator); ok {
+		op, ctx := startOperation(ctx, opGenerateDataKey, providerID)
+		dataKey, encrypted, err = generator.GenerateDataKey(ctx)
+		op.finish(err)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		dataKey, err = newRandomDataKey()
+		if err != nil {
+			return "", nil, err
+		}
+
+		op, ctx := startOperation(ctx, opEncryptDataKey, providerID)
+		encrypted, err = provider.Encrypt(ctx, dataKey)
+		op.finish(err)
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
-	// 3. Store its encrypted value in db
+	// 3. Store its encrypted value in db, the name is unique as rotated keys share the same label
+	name := util.GenerateShortUID()
 	err = s.store.CreateDataKey(ctx, secrets.DataKey{
-		Active:        true, // TODO: right now we never mark a key as deactivated
+		Active:        true,
 		Name:          name,
-		Provider:      s.defaultProvider,
+		Label:         label,
+		Provider:      providerID,
 		EncryptedData: encrypted,
 		Scope:         scope,
 	})
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	// 4. Cache its unencrypted value and return it
-	s.dataKeyCache[name] = dataKeyCacheItem{
-		expiry:  time.Now().Add(15 * time.Minute),
-		dataKey: dataKey,
+	s.cacheDataKey(label, name, providerID, dataKey)
+
+	return name, dataKey, nil
+}
+
+// currentDataKey looks up the active DEK of the label in cache or database, and returns its name and value
+func (s *SecretsService) currentDataKey(ctx context.Context, label string) (string, []byte, error) {
+	s.mtx.RLock()
+	item, exists := s.currentDataKeys[label]
+	s.mtx.RUnlock()
+	if exists && !item.expired() {
+		current, err := s.dataKey(ctx, item.name)
+		return item.name, current.dataKey, err
 	}
 
-	return dataKey, nil
+	current, err := s.store.GetCurrentDataKey(ctx, label)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dataKey, err := s.decryptDataKey(ctx, current)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.cacheDataKey(label, current.Name, current.Provider, dataKey)
+	return current.Name, dataKey, nil
 }
 
 // dataKey looks up DEK in cache or database, and decrypts it
-func (s *SecretsService) dataKey(ctx context.Context, name string) ([]byte, error) {
-	if item, exists := s.dataKeyCache[name]; exists {
-		if item.expiry.Before(time.Now()) && !item.expiry.IsZero() {
-			delete(s.dataKeyCache, name)
-		} else {
-			return item.dataKey, nil
-		}
+func (s *SecretsService) dataKey(ctx context.Context, name string) (dataKeyCacheItem, error) {
+	s.mtx.RLock()
+	item, exists := s.dataKeyCache[name]
+	s.mtx.RUnlock()
+	if exists && !item.expired() {
+		return item, nil
 	}
 
 	// 1. get encrypted data key from database
 	dataKey, err := s.store.GetDataKey(ctx, name)
 	if err != nil {
-		return nil, err
+		return dataKeyCacheItem{}, err
 	}
 
 	// 2. decrypt data key
+	decrypted, err := s.decryptDataKey(ctx, dataKey)
+	if err != nil {
+		return dataKeyCacheItem{}, err
+	}
+
+	// 3. cache data key
+	item = dataKeyCacheItem{
+		expiry:   time.Now().Add(dataKeyCacheTTL),
+		name:     name,
+		provider: dataKey.Provider,
+		dataKey:  decrypted,
+	}
+	s.mtx.Lock()
+	s.dataKeyCache[name] = item
+	s.mtx.Unlock()
+
+	return item, nil
+}
+
+func (s *SecretsService) decryptDataKey(ctx context.Context, dataKey *secrets.DataKey) (_ []byte, err error) {
 	provider, exists := s.providers[dataKey.Provider]
 	if !exists {
 		return nil, fmt.Errorf("could not find encryption provider '%s'", dataKey.Provider)
 	}
 
-	decrypted, err := provider.Decrypt(ctx, dataKey.EncryptedData)
-	if err != nil {
-		return nil, err
-	}
+	op, ctx := startOperation(ctx, opDecryptDataKey, dataKey.Provider)
+	defer func() { op.finish(err) }()
 
-	// 3. cache data key
-	s.dataKeyCache[name] = dataKeyCacheItem{
-		expiry:  time.Now().Add(15 * time.Minute),
-		dataKey: decrypted,
+	return provider.Decrypt(ctx, dataKey.EncryptedData)
+}
+
+// cacheDataKey caches the decrypted value of the active data key of the label.
+func (s *SecretsService) cacheDataKey(label string, name string, provider string, dataKey []byte) {
+	item := dataKeyCacheItem{
+		expiry:   time.Now().Add(dataKeyCacheTTL),
+		name:     name,
+		provider: provider,
+		dataKey:  dataKey,
 	}
 
-	return decrypted, nil
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.dataKeyCache[name] = item
+	s.currentDataKeys[label] = item
 }
@@ -1,11 +1,21 @@
 package manager
 
 import (
+	"bytes"
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"gopkg.in/ini.v1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -142,3 +152,606 @@ func TestSecretsService_DataKeys(t *testing.T) {
 		assert.Nil(t, res)
 	})
 }
+
+func TestSecretsService_Providers(t *testing.T) {
+	t.Run("unknown current provider fails", func(t *testing.T) {
+		raw, err := ini.Load([]byte(`
+		[security.encryption]
+		provider = awskms`))
+		require.NoError(t, err)
+		cfg := &setting.Cfg{Raw: raw}
+
+		_, err = ProvideSecretsService(fakes.NewFakeSecretsStore(), bus.New(), ossencryption.ProvideService(), &setting.OSSImpl{Cfg: cfg}, cfg, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("data keys are generated by the provider if supported", func(t *testing.T) {
+		store := fakes.NewFakeSecretsStore()
+		svc := setupTestService(t, store)
+		svc.providers["generator"] = fakeGeneratorProvider{}
+		svc.defaultProvider = "generator"
+		ctx := context.Background()
+
+		encrypted, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+		require.NoError(t, err)
+
+		// drop the cached data key to decrypt it through the provider
+		svc.dataKeyCache = make(map[string]dataKeyCacheItem)
+		decrypted, err := svc.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		keys, err := store.GetAllDataKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "generator", keys[0].Provider)
+		assert.Equal(t, []byte("wrapped:generated data key"), keys[0].EncryptedData)
+	})
+}
+
+type fakeGeneratorProvider struct{}
+
+func (fakeGeneratorProvider) GenerateDataKey(_ context.Context) ([]byte, []byte, error) {
+	return []byte("generated data key"), []byte("wrapped:generated data key"), nil
+}
+
+func (fakeGeneratorProvider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	return append([]byte("wrapped:"), blob...), nil
+}
+
+func (fakeGeneratorProvider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	return bytes.TrimPrefix(blob, []byte("wrapped:")), nil
+}
+
+// countingProvider counts the data keys it decrypts.
+type countingProvider struct {
+	fakeGeneratorProvider
+	decryptions *int32
+}
+
+func (p countingProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	atomic.AddInt32(p.decryptions, 1)
+	return p.fakeGeneratorProvider.Decrypt(ctx, blob)
+}
+
+func TestSecretsService_DecryptMany(t *testing.T) {
+	svc := setupTestService(t, fakes.NewFakeSecretsStore())
+	var decryptions int32
+	svc.providers["counting"] = countingProvider{decryptions: &decryptions}
+	svc.defaultProvider = "counting"
+	ctx := context.Background()
+
+	payloads := make(map[string][]byte)
+	for _, scope := range []string{"org:1", "org:2"} {
+		for _, key := range []string{"user", "password"} {
+			encrypted, err := svc.Encrypt(ctx, []byte(scope+"/"+key), secrets.WithScope(scope))
+			require.NoError(t, err)
+			payloads[scope+"/"+key] = encrypted
+		}
+	}
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	payloads["legacy"] = legacy
+
+	// drop the cached data keys to decrypt them through the provider
+	svc.dataKeyCache = make(map[string]dataKeyCacheItem)
+	decrypted, err := svc.DecryptMany(ctx, payloads)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"org:1/user":     []byte("org:1/user"),
+		"org:1/password": []byte("org:1/password"),
+		"org:2/user":     []byte("org:2/user"),
+		"org:2/password": []byte("org:2/password"),
+		"legacy":         []byte("legacy"),
+	}, decrypted)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&decryptions), "each data key should be decrypted once")
+
+	t.Run("fails if any payload can't be decrypted", func(t *testing.T) {
+		env, err := parseEnvelope(payloads["org:1/user"])
+		require.NoError(t, err)
+		env.keyName = "missing"
+
+		_, err = svc.DecryptMany(ctx, map[string][]byte{"user": payloads["org:1/user"], "missing": env.encode()})
+		require.Error(t, err)
+	})
+}
+
+func TestSecretsService_RotateDataKeys(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	store := database.ProvideSecretsStore(sqlStore)
+	svc := setupTestService(t, store)
+	ctx := context.Background()
+
+	encrypted, err := svc.EncryptJsonData(ctx, map[string]string{"password": "grafana"}, secrets.WithScope("org:1"))
+	require.NoError(t, err)
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	encrypted["legacy"] = legacy
+
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "test", Type: "test", Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: encrypted}
+	require.NoError(t, sqlStore.AddDataSource(cmd))
+	oldEnvelope, err := parseEnvelope(encrypted["password"])
+	require.NoError(t, err)
+	oldKeyName := oldEnvelope.keyName
+
+	require.NoError(t, svc.RotateDataKeys(ctx))
+	require.Eventually(t, func() bool { return !svc.ReEncryptionStatus().Running }, 5*time.Second, 10*time.Millisecond)
+
+	status := svc.ReEncryptionStatus()
+	assert.Empty(t, status.Error)
+	assert.Equal(t, int64(1), status.Total)
+	assert.Equal(t, int64(1), status.ReEncrypted)
+	assert.Equal(t, int64(0), status.Failed)
+
+	query := &models.GetDataSourceQuery{OrgId: 1, Name: "test"}
+	require.NoError(t, sqlStore.GetDataSource(query))
+	assert.Equal(t, legacy, query.Result.SecureJsonData["legacy"], "secrets encrypted with the secret key should be left unchanged")
+
+	newEnvelope, err := parseEnvelope(query.Result.SecureJsonData["password"])
+	require.NoError(t, err)
+	newKeyName := newEnvelope.keyName
+	assert.NotEqual(t, oldKeyName, newKeyName)
+
+	decrypted, err := svc.DecryptJsonData(ctx, query.Result.SecureJsonData)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "grafana", "legacy": "legacy"}, decrypted)
+
+	oldKey, err := store.GetDataKey(ctx, oldKeyName)
+	require.NoError(t, err)
+	assert.False(t, oldKey.Active)
+
+	newKey, err := store.GetDataKey(ctx, newKeyName)
+	require.NoError(t, err)
+	assert.True(t, newKey.Active)
+	assert.Equal(t, "org:1", newKey.Scope)
+	assert.Equal(t, oldKey.Label, newKey.Label)
+}
+
+func TestSecretsService_ReEncryptSecrets(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := setupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	encrypted := make(map[string][]byte)
+	for _, scope := range []string{"org:1", "org:2"} {
+		value, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithScope(scope))
+		require.NoError(t, err)
+		encrypted[scope] = value
+	}
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "test", Type: "test", Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: encrypted}
+	require.NoError(t, sqlStore.AddDataSource(cmd))
+
+	status, err := svc.ReEncryptSecrets(ctx, true, []string{"org:2"})
+	require.NoError(t, err)
+	assert.False(t, status.Running)
+	assert.Equal(t, int64(1), status.ReEncrypted)
+
+	query := &models.GetDataSourceQuery{OrgId: 1, Name: "test"}
+	require.NoError(t, sqlStore.GetDataSource(query))
+	assert.Equal(t, encrypted["org:1"], query.Result.SecureJsonData["org:1"], "secrets of other scopes should be left unchanged")
+	assert.NotEqual(t, encrypted["org:2"], query.Result.SecureJsonData["org:2"])
+
+	decrypted, err := svc.DecryptJsonData(ctx, query.Result.SecureJsonData)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"org:1": "grafana", "org:2": "grafana"}, decrypted)
+}
+
+func TestSecretsService_DataKeysPerOrg(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	store := database.ProvideSecretsStore(sqlStore)
+	svc := setupTestService(t, store)
+	svc.dataKeysPerOrg = true
+	ctx := context.Background()
+
+	shared, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+	for _, orgID := range []int64{1, 2} {
+		cmd := &models.AddDataSourceCommand{OrgId: orgID, Name: "test", Type: "test", Access: models.DS_ACCESS_PROXY,
+			EncryptedSecureJsonData: map[string][]byte{"password": shared}}
+		require.NoError(t, sqlStore.AddDataSource(cmd))
+	}
+
+	dataKeyOf := func(t *testing.T, orgID int64) *secrets.DataKey {
+		t.Helper()
+		query := &models.GetDataSourceQuery{OrgId: orgID, Name: "test"}
+		require.NoError(t, sqlStore.GetDataSource(query))
+		decrypted, err := svc.DecryptJsonData(ctx, query.Result.SecureJsonData)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "grafana"}, decrypted)
+
+		env, err := parseEnvelope(query.Result.SecureJsonData["password"])
+		require.NoError(t, err)
+		dataKey, err := store.GetDataKey(ctx, env.keyName)
+		require.NoError(t, err)
+		return dataKey
+	}
+
+	t.Run("re-encrypting the root scope moves the secrets to the data keys of their organization", func(t *testing.T) {
+		status, err := svc.ReEncryptSecrets(ctx, false, []string{"root"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), status.ReEncrypted)
+
+		assert.Equal(t, "org:1", dataKeyOf(t, 1).Scope)
+		assert.Equal(t, "org:2", dataKeyOf(t, 2).Scope)
+	})
+
+	t.Run("rotating the data keys of an organization leaves the others active", func(t *testing.T) {
+		org1Key, org2Key := dataKeyOf(t, 1), dataKeyOf(t, 2)
+
+		status, err := svc.ReEncryptSecrets(ctx, true, []string{"org:2"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), status.ReEncrypted)
+
+		assert.Equal(t, org1Key.Name, dataKeyOf(t, 1).Name)
+		rotated := dataKeyOf(t, 2)
+		assert.NotEqual(t, org2Key.Name, rotated.Name)
+		assert.Equal(t, "org:2", rotated.Scope)
+
+		org1Key, err = store.GetDataKey(ctx, org1Key.Name)
+		require.NoError(t, err)
+		assert.True(t, org1Key.Active)
+		org2Key, err = store.GetDataKey(ctx, org2Key.Name)
+		require.NoError(t, err)
+		assert.False(t, org2Key.Active)
+	})
+
+	t.Run("new secrets of an organization are encrypted with the data keys of the organization", func(t *testing.T) {
+		orgCtx := secrets.WithAuditSecret(ctx, secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 3})
+		encrypted, err := svc.Encrypt(orgCtx, []byte("grafana"), secrets.WithoutScope())
+		require.NoError(t, err)
+
+		env, err := parseEnvelope(encrypted)
+		require.NoError(t, err)
+		dataKey, err := store.GetDataKey(ctx, env.keyName)
+		require.NoError(t, err)
+		assert.Equal(t, "org:3", dataKey.Scope)
+	})
+}
+
+func TestSecretsService_ReEncryptLegacySecretsOnRead(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := setupTestService(t, database.ProvideSecretsStore(sqlStore))
+	svc.reEncryptLegacyOnRead = true
+	ctx := context.Background()
+
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	current, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "test", Uid: "test", Type: "test", Access: models.DS_ACCESS_PROXY,
+		EncryptedSecureJsonData: map[string][]byte{"legacy": legacy, "password": current}}
+	require.NoError(t, sqlStore.AddDataSource(cmd))
+
+	getSecrets := func(t *testing.T) map[string][]byte {
+		t.Helper()
+		query := &models.GetDataSourceQuery{OrgId: 1, Name: "test"}
+		require.NoError(t, sqlStore.GetDataSource(query))
+		return query.Result.SecureJsonData
+	}
+
+	t.Run("secrets decrypted without a known row are left unchanged", func(t *testing.T) {
+		_, err := svc.DecryptJsonData(ctx, getSecrets(t))
+		require.NoError(t, err)
+		assert.Equal(t, legacy, getSecrets(t)["legacy"])
+	})
+
+	t.Run("legacy secrets of the row are re-encrypted with a data key", func(t *testing.T) {
+		dsCtx := secrets.WithAuditSecret(ctx, secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "test"})
+		decrypted, err := svc.DecryptJsonData(dsCtx, getSecrets(t))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"legacy": "legacy", "password": "grafana"}, decrypted)
+
+		stored := getSecrets(t)
+		assert.Equal(t, current, stored["password"], "secrets encrypted with a data key should be left unchanged")
+		env, err := parseEnvelope(stored["legacy"])
+		require.NoError(t, err)
+		assert.NotEmpty(t, env.keyName)
+
+		decrypted, err = svc.DecryptJsonData(ctx, stored)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"legacy": "legacy", "password": "grafana"}, decrypted)
+	})
+}
+
+func TestSecretsService_DataKeyInventory(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := setupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	encrypted, err := svc.EncryptJsonData(ctx, map[string]string{"user": "admin", "password": "grafana"}, secrets.WithoutScope())
+	require.NoError(t, err)
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	encrypted["legacy"] = legacy
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "test", Type: "test", Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: encrypted}
+	require.NoError(t, sqlStore.AddDataSource(cmd))
+
+	inventory, err := svc.DataKeyInventory(ctx)
+	require.NoError(t, err)
+	require.Len(t, inventory.DataKeys, 1)
+	assert.True(t, inventory.DataKeys[0].Active)
+	assert.Nil(t, inventory.DataKeys[0].Rotated)
+	assert.Equal(t, int64(2), inventory.DataKeys[0].Secrets)
+	assert.Equal(t, int64(1), inventory.LegacySecrets)
+	assert.Equal(t, int64(0), inventory.MissingSecrets)
+
+	_, err = svc.ReEncryptSecrets(ctx, true, nil)
+	require.NoError(t, err)
+
+	inventory, err = svc.DataKeyInventory(ctx)
+	require.NoError(t, err)
+	require.Len(t, inventory.DataKeys, 2)
+	rotated, current := inventory.DataKeys[0], inventory.DataKeys[1]
+	if rotated.Active {
+		rotated, current = current, rotated
+	}
+	assert.NotNil(t, rotated.Rotated)
+	assert.Equal(t, int64(0), rotated.Secrets, "the secrets should be re-encrypted with the new data key")
+	assert.True(t, current.Active)
+	assert.Equal(t, int64(2), current.Secrets)
+}
+
+func TestSecretsService_ValidateSecrets(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := setupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	valid, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	env, err := parseEnvelope(valid)
+	require.NoError(t, err)
+	env.keyName = "missing"
+
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{OrgId: 1, Name: "valid", Uid: "valid", Type: "test",
+		Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: map[string][]byte{"password": valid, "legacy": legacy}}))
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{OrgId: 1, Name: "invalid", Uid: "invalid", Type: "test",
+		Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: map[string][]byte{"password": env.encode()}}))
+
+	results, err := svc.ValidateSecrets(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, len(secureJsonDataColumns))
+
+	result := results[0]
+	assert.Equal(t, int64(2), result.Processed)
+	assert.Equal(t, int64(1), result.Legacy)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "invalid", result.Failures[0].RowKey)
+	assert.Equal(t, "password", result.Failures[0].Key)
+	assert.Error(t, result.Failures[0].Error)
+}
+
+func TestSecretsService_Algorithms(t *testing.T) {
+	svc := setupTestService(t, fakes.NewFakeSecretsStore())
+	ctx := context.Background()
+
+	encrypted, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+
+	env, err := parseEnvelope(encrypted)
+	require.NoError(t, err)
+	env.algorithm = "unknown"
+
+	_, err = svc.Decrypt(ctx, env.encode())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported encryption algorithm")
+}
+
+func TestSecretsService_MigrateProvider(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	svc := setupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	legacy, err := svc.enc.Encrypt(ctx, []byte("grafana"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	cmd := &models.AddDataSourceCommand{OrgId: 1, Name: "test", Type: "test", Access: models.DS_ACCESS_PROXY,
+		EncryptedSecureJsonData: map[string][]byte{"password": legacy}}
+	require.NoError(t, sqlStore.AddDataSource(cmd))
+	notifierCmd := &models.CreateAlertNotificationCommand{OrgId: 1, Uid: "notifier", Name: "test", Type: "slack",
+		Settings: simplejson.New(), EncryptedSecureSettings: map[string][]byte{"url": legacy}}
+	require.NoError(t, sqlStore.CreateAlertNotificationCommand(notifierCmd))
+
+	getSecureJsonData := func() map[string][]byte {
+		query := &models.GetDataSourceQuery{OrgId: 1, Name: "test"}
+		require.NoError(t, sqlStore.GetDataSource(query))
+		return query.Result.SecureJsonData
+	}
+
+	// legacy alerting decrypts the secure settings of the notifiers with the secret key only
+	requireNotifierDecryptable := func(t *testing.T) {
+		t.Helper()
+		query := &models.GetAlertNotificationsWithUidQuery{OrgId: 1, Uid: "notifier"}
+		require.NoError(t, sqlStore.GetAlertNotificationsWithUid(query))
+		decrypted, err := svc.enc.DecryptJsonData(ctx, query.Result.SecureSettings,
+			svc.settings.KeyValue("security", "secret_key").Value())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"url": "grafana"}, decrypted)
+	}
+
+	t.Run("invalid providers", func(t *testing.T) {
+		_, err := svc.MigrateProvider(ctx, LegacyProvider, LegacyProvider, false)
+		require.Error(t, err)
+
+		_, err = svc.MigrateProvider(ctx, LegacyProvider, "awskms", false)
+		require.Error(t, err)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		results, err := svc.MigrateProvider(ctx, LegacyProvider, defaultProvider, true)
+		require.NoError(t, err)
+		require.Len(t, results, len(secureJsonDataColumns))
+		assert.Equal(t, ProviderMigrationResult{Column: secureJsonDataColumns[0], Processed: 1, Migrated: 1}, results[0])
+		assert.Equal(t, legacy, getSecureJsonData()["password"])
+	})
+
+	t.Run("from legacy to a provider", func(t *testing.T) {
+		results, err := svc.MigrateProvider(ctx, LegacyProvider, defaultProvider, false)
+		require.NoError(t, err)
+		assert.Equal(t, ProviderMigrationResult{Column: secureJsonDataColumns[0], Processed: 1, Migrated: 1}, results[0])
+
+		env, err := parseEnvelope(getSecureJsonData()["password"])
+		require.NoError(t, err)
+		assert.NotEmpty(t, env.keyName)
+
+		decrypted, err := svc.DecryptJsonData(ctx, getSecureJsonData())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "grafana"}, decrypted)
+
+		results, err = svc.MigrateProvider(ctx, LegacyProvider, defaultProvider, false)
+		require.NoError(t, err)
+		assert.Equal(t, ProviderMigrationResult{Column: secureJsonDataColumns[0], Processed: 1}, results[0], "migrated secrets should be skipped")
+
+		requireNotifierDecryptable(t)
+	})
+
+	t.Run("from a provider to legacy", func(t *testing.T) {
+		results, err := svc.MigrateProvider(ctx, defaultProvider, LegacyProvider, false)
+		require.NoError(t, err)
+		assert.Equal(t, ProviderMigrationResult{Column: secureJsonDataColumns[0], Processed: 1, Migrated: 1}, results[0])
+
+		env, err := parseEnvelope(getSecureJsonData()["password"])
+		require.NoError(t, err)
+		assert.Empty(t, env.keyName)
+
+		decrypted, err := svc.DecryptJsonData(ctx, getSecureJsonData())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "grafana"}, decrypted)
+	})
+}
+
+func TestSecretsService_ExportImportSecrets(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	store := database.ProvideSecretsStore(sqlStore)
+	svc := setupTestService(t, store)
+	ctx := context.Background()
+
+	encrypted, err := svc.EncryptJsonData(ctx, map[string]string{"password": "grafana"}, secrets.WithoutScope())
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{OrgId: 1, Name: "test", Uid: "test", Type: "test",
+		Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: encrypted}))
+	legacy, err := svc.enc.Encrypt(ctx, []byte("legacy"), svc.settings.KeyValue("security", "secret_key").Value())
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.AddDataSource(&models.AddDataSourceCommand{OrgId: 1, Name: "legacy", Type: "test",
+		Access: models.DS_ACCESS_PROXY, EncryptedSecureJsonData: map[string][]byte{"password": legacy}}))
+
+	bundle, skipped, err := svc.ExportSecrets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), skipped, "secrets encrypted with the secret key should be skipped")
+	require.Len(t, bundle.Secrets, 1)
+	assert.Equal(t, secrets.SecretsBundleSecret{Table: "data_source", Column: "secure_json_data", OrgId: 1, Key: "test", Data: encrypted}, bundle.Secrets[0])
+	require.Len(t, bundle.DataKeys, 1)
+
+	t.Run("importing a bundle with data keys that can't be decrypted fails", func(t *testing.T) {
+		invalid := *bundle
+		invalid.DataKeys = []secrets.SecretsBundleDataKey{{Name: "unknown", Provider: "unknown"}}
+
+		_, err := svc.ImportSecrets(ctx, &invalid)
+		require.ErrorIs(t, err, ErrInvalidSecretsBundle)
+	})
+
+	t.Run("importing a bundle into an instance without its data keys", func(t *testing.T) {
+		require.NoError(t, store.DeleteDataKey(ctx, bundle.DataKeys[0].Name))
+		target := setupTestService(t, store)
+
+		imported := *bundle
+		imported.Secrets = append(imported.Secrets, secrets.SecretsBundleSecret{Table: "data_source", Column: "secure_json_data", OrgId: 1, Key: "missing"})
+		result, err := target.ImportSecrets(ctx, &imported)
+		require.NoError(t, err)
+		assert.Equal(t, SecretsImportResult{DataKeys: 1, Imported: 1, NotFound: 1}, result)
+
+		dataKey, err := store.GetDataKey(ctx, bundle.DataKeys[0].Name)
+		require.NoError(t, err)
+		assert.False(t, dataKey.Active, "imported data keys should be inactive")
+
+		query := &models.GetDataSourceQuery{OrgId: 1, Uid: "test"}
+		require.NoError(t, sqlStore.GetDataSource(query))
+		decrypted, err := target.DecryptJsonData(ctx, query.Result.SecureJsonData)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "grafana"}, decrypted)
+	})
+}
+
+type fakeAuditSink struct {
+	events []secrets.DecryptionEvent
+}
+
+func (s *fakeAuditSink) RecordDecryption(_ context.Context, event secrets.DecryptionEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestSecretsService_AuditSink(t *testing.T) {
+	svc := setupTestService(t, fakes.NewFakeSecretsStore())
+	sink := &fakeAuditSink{}
+	svc.RegisterAuditSink(sink)
+
+	secret := secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "abc", Name: "test"}
+	ctx := secrets.WithAuditSecret(context.Background(), secret)
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{Service: "datasources"})
+	ctx = secrets.WithAuditRequester(ctx, secrets.AuditRequester{UserID: 2, Login: "admin"})
+
+	encrypted, err := svc.EncryptJsonData(ctx, map[string]string{"password": "grafana", "token": "secret"}, secrets.WithoutScope())
+	require.NoError(t, err)
+	require.Empty(t, sink.events, "encryption should not be recorded")
+
+	_, err = svc.DecryptJsonData(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "grafana", svc.GetDecryptedValue(ctx, encrypted, "password", ""))
+	_, err = svc.Decrypt(ctx, []byte("#invalid"))
+	require.Error(t, err)
+
+	require.Len(t, sink.events, 3)
+	expectedRequester := secrets.AuditRequester{Service: "datasources", UserID: 2, Login: "admin"}
+	for _, event := range sink.events {
+		assert.Equal(t, secret, event.Secret)
+		assert.Equal(t, expectedRequester, event.Requester)
+	}
+	assert.Equal(t, []string{"password", "token"}, sink.events[0].Keys)
+	assert.NoError(t, sink.events[0].Error)
+	assert.Equal(t, []string{"password"}, sink.events[1].Keys)
+	assert.Error(t, sink.events[2].Error)
+}
+
+func TestSecretsService_DecryptionCache(t *testing.T) {
+	store := fakes.NewFakeSecretsStore()
+	svc := setupTestService(t, store)
+
+	secret := secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "abc", Name: "test"}
+	ctx := secrets.WithAuditSecret(context.Background(), secret)
+
+	encrypted, err := svc.Encrypt(ctx, []byte("grafana"), secrets.WithoutScope())
+	require.NoError(t, err)
+	decrypted, err := svc.Decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("grafana"), decrypted)
+
+	// without its data key the payload can only be decrypted from the cache
+	dataKeys, err := store.GetAllDataKeys(ctx)
+	require.NoError(t, err)
+	for _, dataKey := range dataKeys {
+		require.NoError(t, store.DeleteDataKey(ctx, dataKey.Name))
+	}
+	svc.dataKeyCache = make(map[string]dataKeyCacheItem)
+
+	t.Run("decrypted values are cached", func(t *testing.T) {
+		decrypted, err := svc.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+		assert.Equal(t, "grafana", svc.GetDecryptedValue(ctx, map[string][]byte{"password": encrypted}, "password", ""))
+	})
+
+	t.Run("invalidating another secret keeps the cached values", func(t *testing.T) {
+		svc.InvalidateDecryptionCache(secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "def"})
+		svc.InvalidateDecryptionCache(secrets.AuditSecret{Kind: secrets.SecretKindPluginSetting, OrgID: 1})
+
+		_, err := svc.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalidating the secret removes its cached values", func(t *testing.T) {
+		svc.InvalidateDecryptionCache(secrets.AuditSecret{Kind: secrets.SecretKindDataSource, OrgID: 1, UID: "abc"})
+
+		_, err := svc.Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+}
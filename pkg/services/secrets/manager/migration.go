@@ -0,0 +1,175 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// LegacyProvider names the secrets encrypted directly with the secret key, without a data key.
+const LegacyProvider = "legacy"
+
+var errRowModified = errors.New("the secrets were modified during the migration")
+
+// ProviderMigrationResult reports the migration of the secrets of a column between encryption providers.
+type ProviderMigrationResult struct {
+	Column secrets.SecureJsonDataColumn
+	// Processed is the number of rows with secrets, Migrated the ones with secrets of the source provider.
+	Processed int64
+	Migrated  int64
+	Failed    int64
+}
+
+// DefaultProvider returns the provider encrypting the data keys of new secrets.
+func (s *SecretsService) DefaultProvider() string {
+	return s.defaultProvider
+}
+
+// MigrateProvider re-encrypts the stored secrets protected by one encryption provider with another one. The
+// secrets of each row are decrypted and re-encrypted, then replaced by a single update that fails if the row
+// was modified meanwhile. Secrets already migrated are skipped, so an interrupted migration resumes where it
+// stopped when it's run again. In dry-run mode nothing is written and Migrated counts the rows to migrate.
+func (s *SecretsService) MigrateProvider(ctx context.Context, from, to string, dryRun bool) ([]ProviderMigrationResult, error) {
+	if from == to {
+		return nil, fmt.Errorf("the source and target encryption providers are the same")
+	}
+	for _, providerID := range []string{from, to} {
+		if _, exists := s.providers[providerID]; !exists && providerID != LegacyProvider {
+			return nil, fmt.Errorf("encryption provider '%s' is not configured", providerID)
+		}
+	}
+
+	m := &providerMigration{
+		s:        s,
+		from:     from,
+		to:       to,
+		dryRun:   dryRun,
+		dataKeys: make(map[string]*secrets.DataKey),
+	}
+
+	results := make([]ProviderMigrationResult, 0, len(secureJsonDataColumns))
+	for _, column := range secureJsonDataColumns {
+		result, err := m.migrateColumn(ctx, column)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+type providerMigration struct {
+	s        *SecretsService
+	from     string
+	to       string
+	dryRun   bool
+	dataKeys map[string]*secrets.DataKey
+}
+
+func (m *providerMigration) migrateColumn(ctx context.Context, column secrets.SecureJsonDataColumn) (ProviderMigrationResult, error) {
+	result := ProviderMigrationResult{Column: column}
+
+	var afterID int64
+	for {
+		rows, err := m.s.store.ListSecureJsonData(ctx, column, afterID, reEncryptionBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, row := range rows {
+			afterID = row.Id
+			result.Processed++
+
+			migrated, err := m.migrateRow(ctx, column, row)
+			switch {
+			case err != nil:
+				logger.Warn("Failed to migrate secrets", "table", column.Table, "id", row.Id, "error", err)
+				result.Failed++
+			case migrated:
+				result.Migrated++
+			}
+		}
+
+		if len(rows) < reEncryptionBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// migrateRow re-encrypts the secrets of the row protected by the source provider and reports whether
+// there were any.
+func (m *providerMigration) migrateRow(ctx context.Context, column secrets.SecureJsonDataColumn, row *secrets.SecureJsonDataRow) (bool, error) {
+	data := make(map[string][]byte, len(row.Data))
+	changed := false
+	for key, value := range row.Data {
+		providerID, scope, err := m.payloadProvider(ctx, value)
+		if err != nil {
+			return false, err
+		}
+		if providerID != m.from {
+			data[key] = value
+			continue
+		}
+		changed = true
+		if m.dryRun {
+			continue
+		}
+
+		decrypted, err := m.s.decrypt(ctx, value)
+		if err != nil {
+			return false, err
+		}
+
+		data[key], err = m.encrypt(ctx, decrypted, m.s.rowScope(row, scope))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if !changed || m.dryRun {
+		return changed, nil
+	}
+
+	updated, err := m.s.store.UpdateSecureJsonData(ctx, column, row, data)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		return false, errRowModified
+	}
+	return true, nil
+}
+
+// payloadProvider returns the provider protecting the encrypted payload and the scope of its data key.
+func (m *providerMigration) payloadProvider(ctx context.Context, payload []byte) (string, string, error) {
+	env, err := parseEnvelope(payload)
+	if err != nil {
+		return "", "", err
+	}
+	if env.keyName == "" {
+		return LegacyProvider, secrets.WithoutScope()(), nil
+	}
+
+	dataKey, exists := m.dataKeys[env.keyName]
+	if !exists {
+		dataKey, err = m.s.store.GetDataKey(ctx, env.keyName)
+		if err != nil {
+			return "", "", err
+		}
+		m.dataKeys[env.keyName] = dataKey
+	}
+
+	return dataKey.Provider, dataKey.Scope, nil
+}
+
+func (m *providerMigration) encrypt(ctx context.Context, payload []byte, scope string) ([]byte, error) {
+	if m.to == LegacyProvider {
+		secretKey := m.s.settings.KeyValue("security", "secret_key").Value()
+		return m.s.enc.Encrypt(ctx, payload, secretKey)
+	}
+
+	return m.s.encrypt(ctx, payload, scope, m.to)
+}
@@ -0,0 +1,209 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// reEncryptionBatchSize is the number of rows read at once when re-encrypting the stored secrets.
+const reEncryptionBatchSize = 100
+
+var ErrReEncryptionInProgress = errors.New("secrets are being re-encrypted")
+
+// secureJsonDataColumns are the columns storing secrets as JSON objects of encrypted values. The secure settings
+// of the legacy alert notifications aren't part of them, as legacy alerting decrypts them with the secret key only.
+var secureJsonDataColumns = []secrets.SecureJsonDataColumn{
+	{Table: "data_source", Column: "secure_json_data", KeyColumn: "uid"},
+	{Table: "plugin_setting", Column: "secure_json_data", KeyColumn: "plugin_id"},
+}
+
+// RotateDataKeys deactivates the current data keys, so that new data keys are created for the next
+// secrets, and starts re-encrypting the stored secrets with new data keys in the background.
+func (s *SecretsService) RotateDataKeys(ctx context.Context) error {
+	if err := s.startReEncryption(ctx, true, nil); err != nil {
+		return err
+	}
+
+	go s.reEncryptSecrets(context.Background(), nil)
+	return nil
+}
+
+// ReEncryptSecrets re-encrypts the stored secrets with the current data keys of their scope and returns once
+// it's finished. If rotate is true, the data keys are deactivated first, as by RotateDataKeys. Only the
+// secrets encrypted with data keys of the given scopes are re-encrypted and only these data keys are
+// deactivated, or all of them if there are none.
+//
+// Re-encrypting the secrets of the root scope after enabling data keys per organization migrates them to the
+// data keys of their organization.
+func (s *SecretsService) ReEncryptSecrets(ctx context.Context, rotate bool, scopes []string) (secrets.ReEncryptionStatus, error) {
+	if err := s.startReEncryption(ctx, rotate, scopes); err != nil {
+		return secrets.ReEncryptionStatus{}, err
+	}
+
+	s.reEncryptSecrets(ctx, scopes)
+	return s.ReEncryptionStatus(), nil
+}
+
+func (s *SecretsService) startReEncryption(ctx context.Context, rotate bool, scopes []string) error {
+	s.reEncryptionMtx.Lock()
+	defer s.reEncryptionMtx.Unlock()
+
+	if s.reEncryptionStatus.Running {
+		return ErrReEncryptionInProgress
+	}
+
+	if rotate {
+		if err := s.store.DisableDataKeys(ctx, scopes); err != nil {
+			return err
+		}
+
+		s.mtx.Lock()
+		s.currentDataKeys = make(map[string]dataKeyCacheItem)
+		s.mtx.Unlock()
+	}
+
+	s.reEncryptionStatus = secrets.ReEncryptionStatus{Running: true, Started: time.Now()}
+	return nil
+}
+
+// ReEncryptionStatus returns the progress of the last re-encryption of the stored secrets.
+func (s *SecretsService) ReEncryptionStatus() secrets.ReEncryptionStatus {
+	s.reEncryptionMtx.Lock()
+	defer s.reEncryptionMtx.Unlock()
+
+	return s.reEncryptionStatus
+}
+
+func (s *SecretsService) updateReEncryptionStatus(update func(status *secrets.ReEncryptionStatus)) {
+	s.reEncryptionMtx.Lock()
+	defer s.reEncryptionMtx.Unlock()
+
+	update(&s.reEncryptionStatus)
+}
+
+// reEncryptSecrets re-encrypts the secrets of all the secure JSON data columns in batches. Secrets that are
+// encrypted directly with the secret key instead of a data key, or with data keys of other scopes than the
+// given ones if any, are left unchanged.
+func (s *SecretsService) reEncryptSecrets(ctx context.Context, scopes []string) {
+	err := s.reEncryptColumns(ctx, scopes)
+	if err != nil {
+		logger.Error("Failed to re-encrypt secrets", "error", err)
+	}
+
+	s.updateReEncryptionStatus(func(status *secrets.ReEncryptionStatus) {
+		status.Running = false
+		status.Finished = time.Now()
+		if err != nil {
+			status.Error = err.Error()
+		}
+	})
+
+	status := s.ReEncryptionStatus()
+	logger.Info("Finished re-encrypting secrets", "processed", status.Processed, "reEncrypted", status.ReEncrypted,
+		"failed", status.Failed, "duration", status.Finished.Sub(status.Started))
+}
+
+func (s *SecretsService) reEncryptColumns(ctx context.Context, scopes []string) error {
+	var total int64
+	for _, column := range secureJsonDataColumns {
+		count, err := s.store.CountSecureJsonData(ctx, column)
+		if err != nil {
+			return err
+		}
+		total += count
+	}
+	s.updateReEncryptionStatus(func(status *secrets.ReEncryptionStatus) {
+		status.Total = total
+	})
+
+	for _, column := range secureJsonDataColumns {
+		var afterID int64
+		for {
+			rows, err := s.store.ListSecureJsonData(ctx, column, afterID, reEncryptionBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				afterID = row.Id
+
+				reEncrypted, err := s.reEncryptRow(ctx, column, row, scopes)
+				if err != nil {
+					logger.Warn("Failed to re-encrypt secrets", "table", column.Table, "id", row.Id, "error", err)
+				}
+
+				s.updateReEncryptionStatus(func(status *secrets.ReEncryptionStatus) {
+					status.Processed++
+					switch {
+					case err != nil:
+						status.Failed++
+					case reEncrypted:
+						status.ReEncrypted++
+					}
+				})
+			}
+
+			if len(rows) < reEncryptionBatchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// reEncryptRow re-encrypts the secrets of the row with the current data key of their scope, as given by
+// rowScope. The row is left unchanged if it has been modified in the meantime, as it's then encrypted with
+// a current key.
+func (s *SecretsService) reEncryptRow(ctx context.Context, column secrets.SecureJsonDataColumn, row *secrets.SecureJsonDataRow, scopes []string) (bool, error) {
+	data := make(map[string][]byte, len(row.Data))
+	changed := false
+	for key, value := range row.Data {
+		env, err := parseEnvelope(value)
+		if err != nil {
+			return false, err
+		}
+		if env.keyName == "" {
+			data[key] = value
+			continue
+		}
+
+		dataKey, err := s.store.GetDataKey(ctx, env.keyName)
+		if err != nil {
+			return false, err
+		}
+		if len(scopes) > 0 && !containsScope(scopes, dataKey.Scope) {
+			data[key] = value
+			continue
+		}
+
+		decrypted, err := s.decrypt(ctx, value)
+		if err != nil {
+			return false, err
+		}
+
+		data[key], err = s.Encrypt(ctx, decrypted, secrets.WithScope(s.rowScope(row, dataKey.Scope)))
+		if err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	return s.store.UpdateSecureJsonData(ctx, column, row, data)
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// SecretsValidationResult reports the validation of the secrets of a column.
+type SecretsValidationResult struct {
+	Column secrets.SecureJsonDataColumn
+	// Processed is the number of rows with secrets, Legacy the ones with secrets encrypted with the secret key
+	// instead of a data key.
+	Processed int64
+	Legacy    int64
+	Failures  []SecretsValidationFailure
+}
+
+// SecretsValidationFailure is a secret that can't be decrypted.
+type SecretsValidationFailure struct {
+	Id    int64
+	OrgId int64
+	// RowKey is the value of the key column of the row, Key the key of the secret in the secure JSON data.
+	RowKey string
+	Key    string
+	Error  error
+}
+
+// ValidateSecrets decrypts all the stored secrets, and reports the ones that can't be decrypted and the rows
+// still encrypted with the secret key. Nothing is written.
+func (s *SecretsService) ValidateSecrets(ctx context.Context) ([]SecretsValidationResult, error) {
+	results := make([]SecretsValidationResult, 0, len(secureJsonDataColumns))
+	for _, column := range secureJsonDataColumns {
+		result := SecretsValidationResult{Column: column}
+
+		var afterID int64
+		for {
+			rows, err := s.store.ListSecureJsonData(ctx, column, afterID, reEncryptionBatchSize)
+			if err != nil {
+				return results, err
+			}
+
+			for _, row := range rows {
+				afterID = row.Id
+				result.Processed++
+
+				legacy := false
+				for key, value := range row.Data {
+					if env, err := parseEnvelope(value); err == nil && env.keyName == "" {
+						legacy = true
+					}
+					if _, err := s.decrypt(ctx, value); err != nil {
+						result.Failures = append(result.Failures, SecretsValidationFailure{
+							Id:     row.Id,
+							OrgId:  row.OrgId,
+							RowKey: row.Key,
+							Key:    key,
+							Error:  err,
+						})
+					}
+				}
+				if legacy {
+					result.Legacy++
+				}
+			}
+
+			if len(rows) < reEncryptionBatchSize {
+				break
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,254 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/miekg/pkcs11"
+)
+
+// ProviderID is the name of the PKCS#11 provider, as used in the configuration and stored with the data keys.
+const ProviderID = "pkcs11"
+
+// SettingsSection is the configuration section of the PKCS#11 provider.
+const SettingsSection = "security.encryption.pkcs11"
+
+const (
+	ivSize  = 12
+	tagBits = 128
+)
+
+// wrappingKey encrypts and decrypts with AES-GCM using a key that never leaves the HSM.
+type wrappingKey interface {
+	encrypt(iv []byte, plaintext []byte) ([]byte, error)
+	decrypt(iv []byte, ciphertext []byte) ([]byte, error)
+}
+
+// hsmProvider wraps the data keys with an AES key stored in an HSM, accessed through its PKCS#11 module.
+// The wrapped data keys are prefixed with the random IV used to encrypt them.
+type hsmProvider struct {
+	key wrappingKey
+}
+
+// IsConfigured returns whether a PKCS#11 module is configured for the PKCS#11 provider.
+func IsConfigured(settings setting.Provider) bool {
+	return settings.KeyValue(SettingsSection, "module_path").Value() != ""
+}
+
+func New(settings setting.Provider) (secrets.Provider, error) {
+	section := settings.Section(SettingsSection)
+	modulePath := section.KeyValue("module_path").Value()
+	tokenLabel := section.KeyValue("token_label").Value()
+	slot, err := strconv.ParseUint(section.KeyValue("slot").MustString("0"), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slot of the PKCS#11 encryption provider: %w", err)
+	}
+	keyLabel := section.KeyValue("key_label").Value()
+	pinFile := section.KeyValue("pin_file").Value()
+
+	if modulePath == "" || keyLabel == "" || pinFile == "" {
+		return nil, errors.New("module_path, key_label and pin_file are required for the PKCS#11 encryption provider")
+	}
+
+	pin, err := ioutil.ReadFile(pinFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the PIN of the PKCS#11 token: %w", err)
+	}
+
+	key, err := openHSMKey(modulePath, tokenLabel, uint(slot), strings.TrimSpace(string(pin)), keyLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hsmProvider{key: key}, nil
+}
+
+func (p *hsmProvider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	iv := make([]byte, ivSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := p.key.encrypt(iv, blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with the PKCS#11 key: %w", err)
+	}
+
+	return append(iv, encrypted...), nil
+}
+
+func (p *hsmProvider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	if len(blob) <= ivSize {
+		return nil, errors.New("invalid data key encrypted by the PKCS#11 provider")
+	}
+
+	decrypted, err := p.key.decrypt(blob[:ivSize], blob[ivSize:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with the PKCS#11 key: %w", err)
+	}
+
+	return decrypted, nil
+}
+
+// hsmKey is an AES key of a PKCS#11 token. The operations share a single logged in session, as PKCS#11
+// sessions can't be used concurrently, which is reopened if the token closes it.
+type hsmKey struct {
+	ctx      *pkcs11.Ctx
+	slot     uint
+	pin      string
+	keyLabel string
+
+	mu      sync.Mutex
+	session pkcs11.SessionHandle
+	handle  pkcs11.ObjectHandle
+}
+
+func openHSMKey(modulePath string, tokenLabel string, slot uint, pin string, keyLabel string) (*hsmKey, error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load the PKCS#11 module %s", modulePath)
+	}
+	if err := ctx.Initialize(); err != nil && !isError(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, fmt.Errorf("failed to initialize the PKCS#11 module %s: %w", modulePath, err)
+	}
+
+	if tokenLabel != "" {
+		var err error
+		slot, err = findSlot(ctx, tokenLabel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k := &hsmKey{ctx: ctx, slot: slot, pin: pin, keyLabel: keyLabel}
+	if err := k.openSession(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// findSlot returns the slot of the token with the given label.
+func findSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the PKCS#11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the PKCS#11 token of slot %d: %w", slot, err)
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("PKCS#11 token '%s' not found", tokenLabel)
+}
+
+// openSession logs in a new session and looks up the key. It must be called with the lock held, or before
+// the key is shared.
+func (k *hsmKey) openSession() error {
+	session, err := k.ctx.OpenSession(k.slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open a session with the PKCS#11 token of slot %d: %w", k.slot, err)
+	}
+
+	if err := k.ctx.Login(session, pkcs11.CKU_USER, k.pin); err != nil && !isError(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		_ = k.ctx.CloseSession(session)
+		return fmt.Errorf("failed to log in the PKCS#11 token of slot %d: %w", k.slot, err)
+	}
+
+	handle, err := findKey(k.ctx, session, k.keyLabel)
+	if err != nil {
+		_ = k.ctx.CloseSession(session)
+		return err
+	}
+
+	k.session = session
+	k.handle = handle
+	return nil
+}
+
+func findKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, fmt.Errorf("failed to look up the PKCS#11 key: %w", err)
+	}
+	defer func() { _ = ctx.FindObjectsFinal(session) }()
+
+	handles, _, err := ctx.FindObjects(session, 2)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up the PKCS#11 key: %w", err)
+	}
+	switch len(handles) {
+	case 0:
+		return 0, fmt.Errorf("AES key '%s' not found in the PKCS#11 token", label)
+	case 1:
+		return handles[0], nil
+	default:
+		return 0, fmt.Errorf("several AES keys labeled '%s' found in the PKCS#11 token", label)
+	}
+}
+
+func (k *hsmKey) encrypt(iv []byte, plaintext []byte) ([]byte, error) {
+	return k.do(iv, func(mechanism []*pkcs11.Mechanism) ([]byte, error) {
+		if err := k.ctx.EncryptInit(k.session, mechanism, k.handle); err != nil {
+			return nil, err
+		}
+		return k.ctx.Encrypt(k.session, plaintext)
+	})
+}
+
+func (k *hsmKey) decrypt(iv []byte, ciphertext []byte) ([]byte, error) {
+	return k.do(iv, func(mechanism []*pkcs11.Mechanism) ([]byte, error) {
+		if err := k.ctx.DecryptInit(k.session, mechanism, k.handle); err != nil {
+			return nil, err
+		}
+		return k.ctx.Decrypt(k.session, ciphertext)
+	})
+}
+
+// do runs an AES-GCM operation with the IV, and runs it again in a new session if the session was closed.
+func (k *hsmKey) do(iv []byte, operation func(mechanism []*pkcs11.Mechanism) ([]byte, error)) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	params := pkcs11.NewGCMParams(iv, nil, tagBits)
+	defer params.Free()
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}
+
+	result, err := operation(mechanism)
+	if err == nil || !isSessionError(err) {
+		return result, err
+	}
+
+	if err := k.openSession(); err != nil {
+		return nil, err
+	}
+	return operation(mechanism)
+}
+
+func isSessionError(err error) bool {
+	return isError(err, pkcs11.CKR_SESSION_HANDLE_INVALID) || isError(err, pkcs11.CKR_SESSION_CLOSED) ||
+		isError(err, pkcs11.CKR_USER_NOT_LOGGED_IN) || isError(err, pkcs11.CKR_DEVICE_REMOVED) ||
+		isError(err, pkcs11.CKR_TOKEN_NOT_PRESENT)
+}
+
+func isError(err error, code uint) bool {
+	var pkcs11Err pkcs11.Error
+	return errors.As(err, &pkcs11Err) && uint(pkcs11Err) == code
+}
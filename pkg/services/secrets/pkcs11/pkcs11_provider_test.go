@@ -0,0 +1,71 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKey performs the AES-GCM operations of an HSM in software.
+type fakeKey struct {
+	gcm cipher.AEAD
+}
+
+func newFakeKey(t *testing.T) *fakeKey {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return &fakeKey{gcm: gcm}
+}
+
+func (k *fakeKey) encrypt(iv []byte, plaintext []byte) ([]byte, error) {
+	return k.gcm.Seal(nil, iv, plaintext, nil), nil
+}
+
+func (k *fakeKey) decrypt(iv []byte, ciphertext []byte) ([]byte, error) {
+	return k.gcm.Open(nil, iv, ciphertext, nil)
+}
+
+func TestHSMProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := &hsmProvider{key: newFakeKey(t)}
+
+	t.Run("encrypted data keys can be decrypted", func(t *testing.T) {
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(encrypted), "data key")
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("each encryption uses a new IV", func(t *testing.T) {
+		first, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		second, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		assert.NotEqual(t, first[:ivSize], second[:ivSize])
+	})
+
+	t.Run("tampered data keys are rejected", func(t *testing.T) {
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		encrypted[len(encrypted)-1] ^= 1
+
+		_, err = provider.Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated data keys are rejected", func(t *testing.T) {
+		_, err := provider.Decrypt(ctx, make([]byte, ivSize))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package pluginprovider
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProviderID is the name of the provider delegating to the installed secrets manager plugin, as used in the
+// configuration and stored with the data keys.
+const ProviderID = "plugin"
+
+// dataKeySize is the size in bytes of the data keys generated by the plugin.
+const dataKeySize = 16
+
+// Plugin gives access to the client of a secrets manager plugin, see plugins.SecretsManagerPlugin.
+type Plugin interface {
+	Client() (pluginextensionv2.SecretsManagerPlugin, error)
+}
+
+// pluginProvider delegates the wrapping of the data keys to a secrets manager plugin, letting vendors
+// integrate their KMS as a plugin.
+type pluginProvider struct {
+	plugin Plugin
+}
+
+func New(plugin Plugin) secrets.Provider {
+	return &pluginProvider{plugin: plugin}
+}
+
+func (p *pluginProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	client, err := p.plugin.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Encrypt(ctx, &pluginextensionv2.EncryptRequest{Plaintext: blob})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with the secrets manager plugin: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to encrypt with the secrets manager plugin: %s", resp.Error)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (p *pluginProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	client, err := p.plugin.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Decrypt(ctx, &pluginextensionv2.DecryptRequest{Ciphertext: blob})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with the secrets manager plugin: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to decrypt with the secrets manager plugin: %s", resp.Error)
+	}
+	return resp.Plaintext, nil
+}
+
+// GenerateDataKey lets the plugin generate and wrap the data key. Plugins that don't implement it only
+// wrap data keys generated by Grafana.
+func (p *pluginProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	client, err := p.plugin.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.GenerateDataKey(ctx, &pluginextensionv2.GenerateDataKeyRequest{Size: dataKeySize})
+	if status.Code(err) == codes.Unimplemented {
+		return p.wrapRandomDataKey(ctx)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key with the secrets manager plugin: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, nil, fmt.Errorf("failed to generate data key with the secrets manager plugin: %s", resp.Error)
+	}
+	if len(resp.Plaintext) == 0 || len(resp.Ciphertext) == 0 {
+		return nil, nil, errors.New("the secrets manager plugin returned an empty data key")
+	}
+	return resp.Plaintext, resp.Ciphertext, nil
+}
+
+func (p *pluginProvider) wrapRandomDataKey(ctx context.Context) ([]byte, []byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	encrypted, err := p.Encrypt(ctx, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataKey, encrypted, nil
+}
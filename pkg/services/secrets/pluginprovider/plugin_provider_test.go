@@ -0,0 +1,92 @@
+package pluginprovider
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const wrappingPrefix = "wrapped:"
+
+func wrap(dataKey []byte) []byte {
+	return append([]byte(wrappingPrefix), dataKey...)
+}
+
+type fakeSecretsManager struct {
+	generateErr error
+	failWith    string
+}
+
+func (f *fakeSecretsManager) Client() (pluginextensionv2.SecretsManagerPlugin, error) {
+	return f, nil
+}
+
+func (f *fakeSecretsManager) Encrypt(_ context.Context, req *pluginextensionv2.EncryptRequest, _ ...grpc.CallOption) (*pluginextensionv2.EncryptResponse, error) {
+	return &pluginextensionv2.EncryptResponse{Ciphertext: wrap(req.Plaintext), Error: f.failWith}, nil
+}
+
+func (f *fakeSecretsManager) Decrypt(_ context.Context, req *pluginextensionv2.DecryptRequest, _ ...grpc.CallOption) (*pluginextensionv2.DecryptResponse, error) {
+	return &pluginextensionv2.DecryptResponse{Plaintext: bytes.TrimPrefix(req.Ciphertext, []byte(wrappingPrefix)), Error: f.failWith}, nil
+}
+
+func (f *fakeSecretsManager) GenerateDataKey(_ context.Context, req *pluginextensionv2.GenerateDataKeyRequest, _ ...grpc.CallOption) (*pluginextensionv2.GenerateDataKeyResponse, error) {
+	if f.generateErr != nil {
+		return nil, f.generateErr
+	}
+	dataKey := bytes.Repeat([]byte{1}, int(req.Size))
+	return &pluginextensionv2.GenerateDataKeyResponse{Plaintext: dataKey, Ciphertext: wrap(dataKey)}, nil
+}
+
+func TestPluginProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("encrypts and decrypts with the plugin", func(t *testing.T) {
+		provider := New(&fakeSecretsManager{})
+
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("wrapped:data key"), encrypted)
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("returns the errors of the plugin", func(t *testing.T) {
+		provider := New(&fakeSecretsManager{failWith: "key disabled"})
+
+		_, err := provider.Encrypt(ctx, []byte("data key"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key disabled")
+
+		_, err = provider.Decrypt(ctx, []byte("wrapped:data key"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key disabled")
+	})
+
+	t.Run("generates data keys with the plugin", func(t *testing.T) {
+		provider := New(&fakeSecretsManager{}).(secrets.DataKeyGenerator)
+
+		dataKey, encrypted, err := provider.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{1}, dataKeySize), dataKey)
+		assert.Equal(t, wrap(dataKey), encrypted)
+	})
+
+	t.Run("wraps a random data key if the plugin doesn't generate data keys", func(t *testing.T) {
+		provider := New(&fakeSecretsManager{generateErr: status.Error(codes.Unimplemented, "unimplemented")}).(secrets.DataKeyGenerator)
+
+		dataKey, encrypted, err := provider.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		assert.Len(t, dataKey, dataKeySize)
+		assert.Equal(t, wrap(dataKey), encrypted)
+	})
+}
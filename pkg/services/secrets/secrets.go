@@ -11,18 +11,50 @@ type Service interface {
 	Decrypt(ctx context.Context, payload []byte) ([]byte, error)
 	EncryptJsonData(ctx context.Context, kv map[string]string, opt EncryptionOptions) (map[string][]byte, error)
 	DecryptJsonData(ctx context.Context, sjd map[string][]byte) (map[string]string, error)
+	// DecryptMany decrypts the payloads keyed by name, decrypting each of their data keys once.
+	DecryptMany(ctx context.Context, payloads map[string][]byte) (map[string][]byte, error)
 	GetDecryptedValue(ctx context.Context, sjd map[string][]byte, key, fallback string) string
+	// InvalidateDecryptionCache removes the cached decrypted values of a secret that was updated or deleted.
+	InvalidateDecryptionCache(secret AuditSecret)
 }
 
 type Store interface {
+	// GetDataKey returns the data key with the given name, whether it's active or not.
 	GetDataKey(ctx context.Context, name string) (*DataKey, error)
+	// GetCurrentDataKey returns the active data key with the given label.
+	GetCurrentDataKey(ctx context.Context, label string) (*DataKey, error)
 	GetAllDataKeys(ctx context.Context) ([]*DataKey, error)
 	CreateDataKey(ctx context.Context, dataKey DataKey) error
 	CreateDataKeyWithDBSession(ctx context.Context, dataKey DataKey, sess *xorm.Session) error
 	DeleteDataKey(ctx context.Context, name string) error
+	// DisableDataKeys deactivates the data keys of the given scopes, or all data keys if there are none, so that
+	// new data keys are created for the next secrets.
+	DisableDataKeys(ctx context.Context, scopes []string) error
+	// ImportDataKey stores an inactive copy of a data key from another instance, unless a data key with the same
+	// name exists, and reports whether it was stored.
+	ImportDataKey(ctx context.Context, dataKey DataKey) (bool, error)
+
+	CountSecureJsonData(ctx context.Context, column SecureJsonDataColumn) (int64, error)
+	// ListSecureJsonData returns up to limit rows with secure JSON data, ordered by ID, starting after the given ID.
+	ListSecureJsonData(ctx context.Context, column SecureJsonDataColumn, afterID int64, limit int) ([]*SecureJsonDataRow, error)
+	// UpdateSecureJsonData replaces the secure JSON data of the row, unless it has been modified since it was listed.
+	UpdateSecureJsonData(ctx context.Context, column SecureJsonDataColumn, row *SecureJsonDataRow, data map[string][]byte) (bool, error)
+	// ImportSecureJsonData replaces the secure JSON data of the row with the given organization and key, and
+	// reports whether there is such a row.
+	ImportSecureJsonData(ctx context.Context, column SecureJsonDataColumn, orgID int64, key string, data map[string][]byte) (bool, error)
+	// ReplaceSecureJsonData replaces the secure JSON data of the row with the given organization and key, unless
+	// it differs from the current data, and reports whether it was replaced.
+	ReplaceSecureJsonData(ctx context.Context, column SecureJsonDataColumn, orgID int64, key string, current map[string][]byte, data map[string][]byte) (bool, error)
 }
 
 type Provider interface {
 	Encrypt(ctx context.Context, blob []byte) ([]byte, error)
 	Decrypt(ctx context.Context, blob []byte) ([]byte, error)
 }
+
+// DataKeyGenerator is implemented by providers that generate the data keys themselves,
+// e.g. by a KMS, instead of wrapping keys generated by the secrets service.
+type DataKeyGenerator interface {
+	// GenerateDataKey returns a new data key and its encrypted value.
+	GenerateDataKey(ctx context.Context) (plaintext []byte, encrypted []byte, err error)
+}
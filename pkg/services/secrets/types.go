@@ -2,14 +2,18 @@ package secrets
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
 var ErrDataKeyNotFound = errors.New("data key not found")
 
 type DataKey struct {
-	Active        bool
-	Name          string
+	Active bool
+	Name   string
+	// Label identifies the data keys that can be used for the same scope, provider and day.
+	// Only one of them is active at a time.
+	Label         string
 	Scope         string
 	Provider      string
 	EncryptedData []byte
@@ -34,3 +38,69 @@ func WithScope(scope string) EncryptionOptions {
 		return scope
 	}
 }
+
+// WithOrgScope uses a data key for encryption bound to the organization, so that the data keys of the
+// organization can be rotated separately.
+func WithOrgScope(orgID int64) EncryptionOptions {
+	return WithScope(fmt.Sprintf("org:%d", orgID))
+}
+
+// SecureJsonDataColumn is a table column storing secrets as a JSON object of encrypted values.
+type SecureJsonDataColumn struct {
+	Table  string
+	Column string
+	// KeyColumn identifies the rows of an organization across Grafana instances, unlike their ID.
+	KeyColumn string
+}
+
+// SecureJsonDataRow is the secure JSON data of a row. Raw is the JSON as stored in the database.
+type SecureJsonDataRow struct {
+	Id    int64
+	OrgId int64
+	// Key is the value of the key column of the row.
+	Key  string
+	Raw  []byte
+	Data map[string][]byte
+}
+
+// ReEncryptionStatus reports the progress of the re-encryption of the stored secrets.
+type ReEncryptionStatus struct {
+	Running  bool      `json:"running"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// Total is the number of rows with secrets, Processed the ones that have been processed so far.
+	Total       int64  `json:"total"`
+	Processed   int64  `json:"processed"`
+	ReEncrypted int64  `json:"reEncrypted"`
+	Failed      int64  `json:"failed"`
+	Error       string `json:"error,omitempty"`
+}
+
+// SecretsBundleVersion is the version of the format of the secrets bundles.
+const SecretsBundleVersion = 1
+
+// SecretsBundle is an export of the stored secrets encrypted with data keys, with the data keys encrypted by
+// their encryption provider. It can be imported by a Grafana instance with access to the same providers.
+type SecretsBundle struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exportedAt"`
+	DataKeys   []SecretsBundleDataKey `json:"dataKeys"`
+	Secrets    []SecretsBundleSecret  `json:"secrets"`
+}
+
+type SecretsBundleDataKey struct {
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	Scope         string `json:"scope"`
+	Provider      string `json:"provider"`
+	EncryptedData []byte `json:"encryptedData"`
+}
+
+// SecretsBundleSecret is the secure JSON data of a row, identified by its organization and key column.
+type SecretsBundleSecret struct {
+	Table  string            `json:"table"`
+	Column string            `json:"column"`
+	OrgId  int64             `json:"orgId"`
+	Key    string            `json:"key"`
+	Data   map[string][]byte `json:"data"`
+}
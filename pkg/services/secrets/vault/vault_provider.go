@@ -0,0 +1,184 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProviderID is the name of the Vault transit provider, as used in the configuration and stored with the data keys.
+const ProviderID = "vault"
+
+// SettingsSection is the configuration section of the Vault transit provider.
+const SettingsSection = "security.encryption.vault"
+
+// tokenRenewalMargin is how long before its lease expires an AppRole token is replaced.
+const tokenRenewalMargin = time.Minute
+
+// transitProvider delegates the wrapping of the data keys to the transit secrets engine of HashiCorp Vault.
+// The ciphertext returned by Vault is prefixed with the key version, so the transit key can be rotated in
+// Vault without re-encrypting existing data keys.
+type transitProvider struct {
+	client       *http.Client
+	address      string
+	namespace    string
+	transitMount string
+	keyName      string
+
+	// static token, or AppRole credentials used to log in
+	token        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+
+	mu          sync.Mutex
+	loginToken  string
+	tokenExpiry time.Time
+}
+
+// IsConfigured returns whether a Vault server is configured for the Vault transit provider.
+func IsConfigured(settings setting.Provider) bool {
+	return settings.KeyValue(SettingsSection, "address").Value() != ""
+}
+
+func New(settings setting.Provider) (secrets.Provider, error) {
+	section := settings.Section(SettingsSection)
+	p := &transitProvider{
+		client:       &http.Client{Timeout: 30 * time.Second},
+		address:      strings.TrimSuffix(section.KeyValue("address").Value(), "/"),
+		namespace:    section.KeyValue("namespace").Value(),
+		transitMount: strings.Trim(section.KeyValue("transit_mount").MustString("transit"), "/"),
+		keyName:      section.KeyValue("key_name").Value(),
+		token:        section.KeyValue("token").Value(),
+		roleID:       section.KeyValue("role_id").Value(),
+		secretID:     section.KeyValue("secret_id").Value(),
+		appRoleMount: strings.Trim(section.KeyValue("approle_mount").MustString("approle"), "/"),
+	}
+
+	if p.address == "" || p.keyName == "" {
+		return nil, errors.New("address and key_name are required for the Vault encryption provider")
+	}
+	if p.token == "" && (p.roleID == "" || p.secretID == "") {
+		return nil, errors.New("either a token or AppRole credentials are required for the Vault encryption provider")
+	}
+
+	return p, nil
+}
+
+func (p *transitProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+
+	req := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(blob)}
+	if err := p.do(ctx, fmt.Sprintf("%s/encrypt/%s", p.transitMount, p.keyName), req, &resp); err != nil {
+		return nil, err
+	}
+
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (p *transitProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+
+	req := map[string]string{"ciphertext": string(blob)}
+	if err := p.do(ctx, fmt.Sprintf("%s/decrypt/%s", p.transitMount, p.keyName), req, &resp); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+// getToken returns the static token, or logs in with the AppRole credentials if the previous
+// login token is about to expire.
+func (p *transitProvider) getToken(ctx context.Context) (string, error) {
+	if p.token != "" {
+		return p.token, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.loginToken != "" && time.Now().Before(p.tokenExpiry) {
+		return p.loginToken, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	req := map[string]string{"role_id": p.roleID, "secret_id": p.secretID}
+	if err := p.request(ctx, fmt.Sprintf("auth/%s/login", p.appRoleMount), "", req, &resp); err != nil {
+		return "", fmt.Errorf("failed to log in to Vault: %w", err)
+	}
+
+	p.loginToken = resp.Auth.ClientToken
+	p.tokenExpiry = time.Now().Add(time.Duration(resp.Auth.LeaseDuration)*time.Second - tokenRenewalMargin)
+	return p.loginToken, nil
+}
+
+func (p *transitProvider) do(ctx context.Context, path string, body interface{}, result interface{}) error {
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return p.request(ctx, path, token, body, result)
+}
+
+func (p *transitProvider) request(ctx context.Context, path string, token string, body interface{}, result interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/%s", p.address, path), bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return json.Unmarshal(respBody, result)
+}
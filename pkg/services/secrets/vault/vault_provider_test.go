@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeVault returns a Vault server with a transit engine that "encrypts" by prefixing the plaintext.
+func newFakeVault(t *testing.T, logins *int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var resp interface{}
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			*logins++
+			assert.Equal(t, "role", req["role_id"])
+			assert.Equal(t, "secret", req["secret_id"])
+			resp = map[string]interface{}{"auth": map[string]interface{}{"client_token": "login-token", "lease_duration": 3600}}
+		case "/v1/transit/encrypt/grafana":
+			assert.Equal(t, "login-token", r.Header.Get("X-Vault-Token"))
+			resp = map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + req["plaintext"]}}
+		case "/v1/transit/decrypt/grafana":
+			assert.Equal(t, "login-token", r.Header.Get("X-Vault-Token"))
+			if !strings.HasPrefix(req["ciphertext"], "vault:v1:") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp = map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], "vault:v1:")}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransitProvider(t *testing.T) {
+	ctx := context.Background()
+	logins := 0
+	server := newFakeVault(t, &logins)
+	provider := &transitProvider{
+		client:       server.Client(),
+		address:      server.URL,
+		transitMount: "transit",
+		keyName:      "grafana",
+		roleID:       "role",
+		secretID:     "secret",
+		appRoleMount: "approle",
+	}
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		encrypted, err := provider.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		assert.Equal(t, "vault:v1:"+base64.StdEncoding.EncodeToString([]byte("data key")), string(encrypted))
+
+		decrypted, err := provider.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("login token is reused until it expires", func(t *testing.T) {
+		assert.Equal(t, 1, logins)
+	})
+
+	t.Run("decrypting an invalid ciphertext fails", func(t *testing.T) {
+		_, err := provider.Decrypt(ctx, []byte("invalid"))
+		require.Error(t, err)
+	})
+}
@@ -600,6 +600,38 @@ func GetDashboardsByPluginId(ctx context.Context, query *models.GetDashboardsByP
 	return err
 }
 
+// ArchivePluginDashboard moves a dashboard imported from a plugin to the folder and unlinks it from the plugin,
+// so that it's left as is if the plugin is installed again. The dashboard is renamed if the folder already has
+// a dashboard with the same title.
+func (ss *SQLStore) ArchivePluginDashboard(ctx context.Context, orgID, dashboardID, folderID int64) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		dash := models.Dashboard{Id: dashboardID, OrgId: orgID}
+		has, err := sess.Get(&dash)
+		if err != nil {
+			return err
+		} else if !has {
+			return models.ErrDashboardNotFound
+		}
+
+		titleExists, err := sess.Table("dashboard").Where("org_id=? AND folder_id=? AND title=? AND id<>?",
+			orgID, folderID, dash.Title, dash.Id).Exist()
+		if err != nil {
+			return err
+		}
+		if titleExists {
+			dash.Title = fmt.Sprintf("%s (%s)", dash.Title, dash.Uid)
+			dash.Data.Set("title", dash.Title)
+			dash.UpdateSlug()
+		}
+
+		dash.FolderId = folderID
+		dash.PluginId = ""
+		dash.Updated = time.Now()
+		_, err = sess.ID(dash.Id).MustCols("folder_id", "plugin_id").Cols("title", "slug", "data", "updated").Update(&dash)
+		return err
+	})
+}
+
 type DashboardSlugDTO struct {
 	Slug string
 }
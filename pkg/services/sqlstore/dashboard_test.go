@@ -460,6 +460,32 @@ func TestDashboard_SortingOptions(t *testing.T) {
 	})
 }
 
+func TestArchivePluginDashboard(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	folder := insertTestDashboard(t, sqlStore, "Archived plugin dashboards", 1, 0, true)
+	insertTestDashboard(t, sqlStore, "Overview", 1, folder.Id, false)
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     1,
+		PluginId:  "test-app",
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Overview"}),
+	})
+	require.NoError(t, err)
+
+	err = sqlStore.ArchivePluginDashboard(context.Background(), 1, dash.Id, folder.Id)
+	require.NoError(t, err)
+
+	query := models.GetDashboardQuery{OrgId: 1, Id: dash.Id}
+	require.NoError(t, GetDashboard(context.Background(), &query))
+	require.Equal(t, folder.Id, query.Result.FolderId)
+	require.Empty(t, query.Result.PluginId)
+	require.Equal(t, "Overview ("+dash.Uid+")", query.Result.Title)
+	require.Equal(t, query.Result.Title, query.Result.Data.Get("title").MustString())
+
+	pluginQuery := models.GetDashboardsByPluginIdQuery{OrgId: 1, PluginId: "test-app"}
+	require.NoError(t, GetDashboardsByPluginId(context.Background(), &pluginQuery))
+	require.Empty(t, pluginQuery.Result)
+}
+
 func insertTestDashboard(t *testing.T, sqlStore *SQLStore, title string, orgId int64,
 	folderId int64, isFolder bool, tags ...interface{}) *models.Dashboard {
 	t.Helper()
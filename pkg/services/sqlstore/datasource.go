@@ -147,6 +147,7 @@ func (ss *SQLStore) AddDataSource(cmd *models.AddDataSourceCommand) error {
 			WithCredentials:   cmd.WithCredentials,
 			JsonData:          cmd.JsonData,
 			SecureJsonData:    cmd.EncryptedSecureJsonData,
+			SecureJsonExpiry:  cmd.SecureJsonExpiry,
 			Created:           time.Now(),
 			Updated:           time.Now(),
 			Version:           1,
@@ -211,6 +212,7 @@ func (ss *SQLStore) UpdateDataSource(cmd *models.UpdateDataSourceCommand) error
 			WithCredentials:   cmd.WithCredentials,
 			JsonData:          cmd.JsonData,
 			SecureJsonData:    cmd.EncryptedSecureJsonData,
+			SecureJsonExpiry:  cmd.SecureJsonExpiry,
 			Updated:           time.Now(),
 			ReadOnly:          cmd.ReadOnly,
 			Version:           cmd.Version + 1,
@@ -226,6 +228,10 @@ func (ss *SQLStore) UpdateDataSource(cmd *models.UpdateDataSourceCommand) error
 		sess.MustCols("password")
 		sess.MustCols("basic_auth_password")
 		sess.MustCols("user")
+		// The expiry dates are left unchanged unless set, but they can all be removed.
+		if cmd.SecureJsonExpiry != nil {
+			sess.MustCols("secure_json_expiry")
+		}
 
 		var updateSession *xorm.Session
 		if cmd.Version != 0 {
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataSourceHealthMigrations(mg *Migrator) {
+	dataSourceHealthV1 := Table{
+		Name: "data_source_health",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "latency_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "checked", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "data_source_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create data_source_health table v1", NewAddTableMigration(dataSourceHealthV1))
+
+	mg.AddMigration("add unique index data_source_health.org_id-data_source_id", NewAddIndexMigration(dataSourceHealthV1, dataSourceHealthV1.Indices[0]))
+}
@@ -154,4 +154,9 @@ func addDataSourceMigration(mg *Migrator) {
 
 	mg.AddMigration("add unique index datasource_org_id_is_default", NewAddIndexMigration(tableV2, &Index{
 		Cols: []string{"org_id", "is_default"}}))
+
+	// add column storing the expiry dates of the secrets of secure_json_data
+	mg.AddMigration("Add secure_json_expiry column", NewAddColumnMigration(tableV2, &Column{
+		Name: "secure_json_expiry", Type: DB_Text, Nullable: true,
+	}))
 }
@@ -55,6 +55,8 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	addSecretsMigration(mg)
 	addKVStoreMigrations(mg)
 	ualert.AddDashboardUIDPanelIDMigration(mg)
+	addDataSourceHealthMigrations(mg)
+	addQueryHistoryMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
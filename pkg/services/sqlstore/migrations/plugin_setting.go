@@ -37,4 +37,33 @@ func addAppSettingsMigration(mg *Migrator) {
 		{Name: "secure_json_data", Type: DB_Text, Nullable: true},
 		{Name: "plugin_version", Type: DB_NVarchar, Nullable: true, Length: 50},
 	}))
+
+	// add column storing the expiry dates of the secrets of secure_json_data
+	mg.AddMigration("Add column secure_json_expiry to plugin_setting", NewAddColumnMigration(pluginSettingTable, &Column{
+		Name: "secure_json_expiry", Type: DB_Text, Nullable: true,
+	}))
+
+	pluginSettingVersionTable := Table{
+		Name: "plugin_setting_version",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "plugin_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "restored_from", Type: DB_Int, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "pinned", Type: DB_Bool, Nullable: false},
+			{Name: "json_data", Type: DB_Text, Nullable: true},
+			{Name: "secure_json_data", Type: DB_Text, Nullable: true},
+			{Name: "plugin_version", Type: DB_NVarchar, Nullable: true, Length: 50},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "plugin_id", "version"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create plugin_setting_version table", NewAddTableMigration(pluginSettingVersionTable))
+	mg.AddMigration("add unique index plugin_setting_version.org_id_plugin_id_version", NewAddIndexMigration(pluginSettingVersionTable, pluginSettingVersionTable.Indices[0]))
 }
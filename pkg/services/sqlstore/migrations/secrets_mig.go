@@ -2,6 +2,14 @@ package migrations
 
 import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 
+// SecretsMigrations are the migrations of a database dedicated to the data keys of the secrets service.
+type SecretsMigrations struct{}
+
+func (*SecretsMigrations) AddMigration(mg *migrator.Migrator) {
+	addMigrationLogMigrations(mg)
+	addSecretsMigration(mg)
+}
+
 func addSecretsMigration(mg *migrator.Migrator) {
 	dataKeysV1 := migrator.Table{
 		Name: "data_keys",
@@ -18,4 +26,13 @@ func addSecretsMigration(mg *migrator.Migrator) {
 	}
 
 	mg.AddMigration("create data_keys table", migrator.NewAddTableMigration(dataKeysV1))
+
+	// data keys were looked up by name, the label allows to replace the active key of a scope when rotating keys
+	mg.AddMigration("add label column to data_keys", migrator.NewAddColumnMigration(dataKeysV1, &migrator.Column{
+		Name: "label", Type: migrator.DB_NVarchar, Length: 100, Nullable: false, Default: "''",
+	}))
+	mg.AddMigration("set label of existing data_keys", migrator.NewRawSQLMigration("UPDATE data_keys SET label = name"))
+	mg.AddMigration("add index data_keys.label", migrator.NewAddIndexMigration(dataKeysV1, &migrator.Index{
+		Cols: []string{"label"},
+	}))
 }
@@ -1,6 +1,8 @@
 package sqlstore
 
 import (
+	"bytes"
+	"context"
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -24,6 +26,15 @@ func (ss *SQLStore) GetPluginSettings(orgID int64) ([]*models.PluginSettingInfoD
 	return rslt, nil
 }
 
+// GetAllPluginSettings returns the plugin settings of all organizations, with their JSON data.
+func (ss *SQLStore) GetAllPluginSettings() ([]*models.PluginSetting, error) {
+	var settings []*models.PluginSetting
+	if err := x.OrderBy("org_id, plugin_id").Find(&settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
 func (ss *SQLStore) GetPluginSettingById(query *models.GetPluginSettingByIdQuery) error {
 	pluginSetting := models.PluginSetting{OrgId: query.OrgId, PluginId: query.PluginId}
 	has, err := x.Get(&pluginSetting)
@@ -48,15 +59,16 @@ func (ss *SQLStore) UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) erro
 		sess.UseBool("pinned")
 		if !exists {
 			pluginSetting = models.PluginSetting{
-				PluginId:       cmd.PluginId,
-				OrgId:          cmd.OrgId,
-				Enabled:        cmd.Enabled,
-				Pinned:         cmd.Pinned,
-				JsonData:       cmd.JsonData,
-				PluginVersion:  cmd.PluginVersion,
-				SecureJsonData: cmd.EncryptedSecureJsonData,
-				Created:        time.Now(),
-				Updated:        time.Now(),
+				PluginId:         cmd.PluginId,
+				OrgId:            cmd.OrgId,
+				Enabled:          cmd.Enabled,
+				Pinned:           cmd.Pinned,
+				JsonData:         cmd.JsonData,
+				PluginVersion:    cmd.PluginVersion,
+				SecureJsonData:   cmd.EncryptedSecureJsonData,
+				SecureJsonExpiry: models.UpdateSecureJsonExpiry(nil, nil, cmd.SecureJsonExpiry),
+				Created:          time.Now(),
+				Updated:          time.Now(),
 			}
 
 			// add state change event on commit success
@@ -64,9 +76,15 @@ func (ss *SQLStore) UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) erro
 				PluginId: cmd.PluginId,
 				OrgId:    cmd.OrgId,
 				Enabled:  cmd.Enabled,
+			}, &models.PluginSettingUpdatedEvent{
+				PluginId: cmd.PluginId,
+				OrgId:    cmd.OrgId,
 			})
 
-			_, err = sess.Insert(&pluginSetting)
+			if _, err = sess.Insert(&pluginSetting); err != nil {
+				return err
+			}
+			_, err = ss.savePluginSettingVersion(sess, &pluginSetting, cmd.UserId, 0)
 			return err
 		}
 
@@ -83,13 +101,167 @@ func (ss *SQLStore) UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) erro
 			})
 		}
 
+		sess.events = append(sess.events, &models.PluginSettingUpdatedEvent{
+			PluginId: cmd.PluginId,
+			OrgId:    cmd.OrgId,
+		})
+
 		pluginSetting.Updated = time.Now()
 		pluginSetting.Enabled = cmd.Enabled
 		pluginSetting.JsonData = cmd.JsonData
 		pluginSetting.Pinned = cmd.Pinned
 		pluginSetting.PluginVersion = cmd.PluginVersion
+		pluginSetting.SecureJsonExpiry = models.UpdateSecureJsonExpiry(pluginSetting.SecureJsonExpiry,
+			cmd.SecureJsonData, cmd.SecureJsonExpiry)
+		sess.MustCols("secure_json_expiry")
 
-		_, err = sess.ID(pluginSetting.Id).Update(&pluginSetting)
+		if _, err = sess.ID(pluginSetting.Id).Update(&pluginSetting); err != nil {
+			return err
+		}
+		_, err = ss.savePluginSettingVersion(sess, &pluginSetting, cmd.UserId, 0)
+		return err
+	})
+}
+
+// savePluginSettingVersion saves the settings as the next version of the settings of the plugin, and deletes the
+// versions beyond the number of versions to keep. It returns nil if the history of the settings is disabled.
+func (ss *SQLStore) savePluginSettingVersion(sess *DBSession, ps *models.PluginSetting, userID int64,
+	restoredFrom int) (*models.PluginSettingVersion, error) {
+	versionsToKeep := ss.Cfg.PluginSettingsVersionsToKeep
+	if versionsToKeep <= 0 {
+		return nil, nil
+	}
+
+	var latest models.PluginSettingVersion
+	if _, err := sess.Where("org_id=? AND plugin_id=?", ps.OrgId, ps.PluginId).Desc("version").
+		Get(&latest); err != nil {
+		return nil, err
+	}
+
+	version := models.PluginSettingVersion{
+		OrgId:          ps.OrgId,
+		PluginId:       ps.PluginId,
+		Version:        latest.Version + 1,
+		RestoredFrom:   restoredFrom,
+		Enabled:        ps.Enabled,
+		Pinned:         ps.Pinned,
+		JsonData:       ps.JsonData,
+		SecureJsonData: ps.SecureJsonData,
+		PluginVersion:  ps.PluginVersion,
+		Created:        time.Now(),
+		CreatedBy:      userID,
+	}
+	sess.UseBool("enabled")
+	sess.UseBool("pinned")
+	if _, err := sess.Insert(&version); err != nil {
+		return nil, err
+	}
+
+	// versions are numbered without gaps, so the versions to delete are the oldest ones
+	if _, err := sess.Exec("DELETE FROM plugin_setting_version WHERE org_id=? AND plugin_id=? AND version<=?",
+		ps.OrgId, ps.PluginId, version.Version-versionsToKeep); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (ss *SQLStore) GetPluginSettingVersions(ctx context.Context, query *models.GetPluginSettingVersionsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		sess.Where("org_id=? AND plugin_id=?", query.OrgId, query.PluginId).Desc("version")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+
+		versions := make([]*models.PluginSettingVersion, 0)
+		if err := sess.Find(&versions); err != nil {
+			return err
+		}
+		query.Result = versions
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetPluginSettingVersion(ctx context.Context, query *models.GetPluginSettingVersionQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		version, err := getPluginSettingVersion(sess, query.OrgId, query.PluginId, query.Version)
+		if err != nil {
+			return err
+		}
+		query.Result = version
+		return nil
+	})
+}
+
+func getPluginSettingVersion(sess *DBSession, orgID int64, pluginID string, v int) (*models.PluginSettingVersion, error) {
+	var version models.PluginSettingVersion
+	has, err := sess.Where("org_id=? AND plugin_id=? AND version=?", orgID, pluginID, v).Get(&version)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, models.ErrPluginSettingVersionNotFound
+	}
+	return &version, nil
+}
+
+// RestorePluginSettingVersion sets the settings of the plugin to those of the version, including the encrypted secure
+// JSON data, and saves them as a new version restored from it. The expiry dates of the secrets which are removed or
+// replaced by the version are removed.
+func (ss *SQLStore) RestorePluginSettingVersion(ctx context.Context, cmd *models.RestorePluginSettingVersionCmd) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		version, err := getPluginSettingVersion(sess, cmd.OrgId, cmd.PluginId, cmd.Version)
+		if err != nil {
+			return err
+		}
+
+		var pluginSetting models.PluginSetting
+		exists, err := sess.Where("org_id=? and plugin_id=?", cmd.OrgId, cmd.PluginId).Get(&pluginSetting)
+		if err != nil {
+			return err
+		}
+
+		if !exists || pluginSetting.Enabled != version.Enabled {
+			sess.events = append(sess.events, &models.PluginStateChangedEvent{
+				PluginId: cmd.PluginId,
+				OrgId:    cmd.OrgId,
+				Enabled:  version.Enabled,
+			})
+		}
+		sess.events = append(sess.events, &models.PluginSettingUpdatedEvent{
+			PluginId: cmd.PluginId,
+			OrgId:    cmd.OrgId,
+		})
+
+		secureJsonExpiry := map[string]time.Time{}
+		for key, expiry := range pluginSetting.SecureJsonExpiry {
+			if bytes.Equal(pluginSetting.SecureJsonData[key], version.SecureJsonData[key]) {
+				secureJsonExpiry[key] = expiry
+			}
+		}
+
+		pluginSetting.OrgId = cmd.OrgId
+		pluginSetting.PluginId = cmd.PluginId
+		pluginSetting.Enabled = version.Enabled
+		pluginSetting.Pinned = version.Pinned
+		pluginSetting.JsonData = version.JsonData
+		pluginSetting.SecureJsonData = version.SecureJsonData
+		pluginSetting.SecureJsonExpiry = secureJsonExpiry
+		pluginSetting.PluginVersion = version.PluginVersion
+		pluginSetting.Updated = time.Now()
+
+		sess.UseBool("enabled")
+		sess.UseBool("pinned")
+		if exists {
+			sess.MustCols("json_data", "secure_json_data", "secure_json_expiry")
+			_, err = sess.ID(pluginSetting.Id).Update(&pluginSetting)
+		} else {
+			pluginSetting.Created = pluginSetting.Updated
+			_, err = sess.Insert(&pluginSetting)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.Result, err = ss.savePluginSettingVersion(sess, &pluginSetting, cmd.UserId, version.Version)
 		return err
 	})
 }
@@ -97,6 +269,14 @@ func (ss *SQLStore) UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) erro
 func (ss *SQLStore) UpdatePluginSettingVersion(cmd *models.UpdatePluginSettingVersionCmd) error {
 	return inTransaction(func(sess *DBSession) error {
 		_, err := sess.Exec("UPDATE plugin_setting SET plugin_version=? WHERE org_id=? AND plugin_id=?", cmd.PluginVersion, cmd.OrgId, cmd.PluginId)
-		return err
+		if err != nil {
+			return err
+		}
+
+		sess.events = append(sess.events, &models.PluginSettingUpdatedEvent{
+			PluginId: cmd.PluginId,
+			OrgId:    cmd.OrgId,
+		})
+		return nil
 	})
 }
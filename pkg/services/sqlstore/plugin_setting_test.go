@@ -0,0 +1,74 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginSettingVersions(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	sqlStore.Cfg.PluginSettingsVersionsToKeep = 3
+	t.Cleanup(func() {
+		sqlStore.Cfg.PluginSettingsVersionsToKeep = 0
+	})
+	ctx := context.Background()
+
+	update := func(t *testing.T, enabled bool, url string, token string) {
+		t.Helper()
+		require.NoError(t, sqlStore.UpdatePluginSetting(&models.UpdatePluginSettingCmd{
+			OrgId: 1, PluginId: "test-app", UserId: 2, Enabled: enabled,
+			JsonData:                map[string]interface{}{"url": url},
+			SecureJsonData:          map[string]string{"apiToken": token},
+			EncryptedSecureJsonData: map[string][]byte{"apiToken": []byte(token)},
+			SecureJsonExpiry:        map[string]time.Time{"apiToken": time.Now().Add(time.Hour)},
+		}))
+	}
+
+	update(t, true, "https://example.com/v1", "token1")
+	update(t, true, "https://example.com/v2", "token2")
+	update(t, false, "https://example.com/v3", "token3")
+	update(t, false, "https://example.com/v4", "token4")
+
+	t.Run("Should keep the latest versions", func(t *testing.T) {
+		query := models.GetPluginSettingVersionsQuery{OrgId: 1, PluginId: "test-app"}
+		require.NoError(t, sqlStore.GetPluginSettingVersions(ctx, &query))
+		require.Len(t, query.Result, 3)
+		require.Equal(t, 4, query.Result[0].Version)
+		require.Equal(t, 2, query.Result[2].Version)
+		require.Equal(t, "https://example.com/v2", query.Result[2].JsonData["url"])
+		require.Equal(t, int64(2), query.Result[2].CreatedBy)
+
+		query = models.GetPluginSettingVersionsQuery{OrgId: 2, PluginId: "test-app"}
+		require.NoError(t, sqlStore.GetPluginSettingVersions(ctx, &query))
+		require.Empty(t, query.Result)
+
+		err := sqlStore.GetPluginSettingVersion(ctx, &models.GetPluginSettingVersionQuery{
+			OrgId: 1, PluginId: "test-app", Version: 1,
+		})
+		require.ErrorIs(t, err, models.ErrPluginSettingVersionNotFound)
+	})
+
+	t.Run("Should restore a version as a new version", func(t *testing.T) {
+		cmd := models.RestorePluginSettingVersionCmd{OrgId: 1, PluginId: "test-app", Version: 2, UserId: 3}
+		require.NoError(t, sqlStore.RestorePluginSettingVersion(ctx, &cmd))
+		require.Equal(t, 5, cmd.Result.Version)
+		require.Equal(t, 2, cmd.Result.RestoredFrom)
+		require.Equal(t, int64(3), cmd.Result.CreatedBy)
+
+		query := models.GetPluginSettingByIdQuery{OrgId: 1, PluginId: "test-app"}
+		require.NoError(t, sqlStore.GetPluginSettingById(&query))
+		require.True(t, query.Result.Enabled)
+		require.Equal(t, "https://example.com/v2", query.Result.JsonData["url"])
+		require.Equal(t, []byte("token2"), query.Result.SecureJsonData["apiToken"])
+		require.Empty(t, query.Result.SecureJsonExpiry)
+
+		err := sqlStore.RestorePluginSettingVersion(ctx, &models.RestorePluginSettingVersionCmd{
+			OrgId: 1, PluginId: "test-app", Version: 1,
+		})
+		require.ErrorIs(t, err, models.ErrPluginSettingVersionNotFound)
+	})
+}
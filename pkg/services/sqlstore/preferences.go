@@ -47,6 +47,10 @@ func (ss *SQLStore) GetPreferencesWithDefaults(ctx context.Context, query *model
 			HomeDashboardId: 0,
 		}
 
+		if query.DefaultTheme != "" {
+			res.Theme = query.DefaultTheme
+		}
+
 		for _, p := range prefs {
 			if p.Theme != "" {
 				res.Theme = p.Theme
@@ -115,4 +115,19 @@ func TestPreferencesDataAccess(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), query.Result.HomeDashboardId)
 	})
+
+	t.Run("GetPreferencesWithDefaults with default theme should return it unless a theme is saved", func(t *testing.T) {
+		err := SavePreferences(&models.SavePreferencesCommand{OrgId: 2, UserId: 1, Theme: "light"})
+		require.NoError(t, err)
+
+		query := &models.GetPreferencesWithDefaultsQuery{User: &models.SignedInUser{OrgId: 2, UserId: 2}, DefaultTheme: "dark"}
+		err = ss.GetPreferencesWithDefaults(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, "dark", query.Result.Theme)
+
+		query = &models.GetPreferencesWithDefaultsQuery{User: &models.SignedInUser{OrgId: 2, UserId: 1}, DefaultTheme: "dark"}
+		err = ss.GetPreferencesWithDefaults(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, "light", query.Result.Theme)
+	})
 }
@@ -50,6 +50,9 @@ type SQLStore struct {
 	Dialect                     migrator.Dialect
 	skipEnsureDefaultOrgAndUser bool
 	migrations                  registry.DatabaseMigrator
+	// dedicated stores are configured by another section than [database], and are not used by the bus handlers
+	dedicatedSection string
+	dedicatedPath    string
 }
 
 func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, bus bus.Bus, migrations registry.DatabaseMigrator) (*SQLStore, error) {
@@ -73,6 +76,32 @@ func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, bus
 	return s, nil
 }
 
+// NewDedicatedStore returns a store connected to the database configured in the given section of the
+// configuration, with the same keys as the [database] section, and migrated with the given migrations.
+// defaultPath is the path of the SQLite database when it's not configured. Unlike the main store, it doesn't
+// register bus handlers or replace the global engine, so that it can be used alongside the main store.
+func NewDedicatedStore(cfg *setting.Cfg, section string, defaultPath string, migrations registry.DatabaseMigrator) (*SQLStore, error) {
+	ss := &SQLStore{
+		Cfg:                         cfg,
+		log:                         log.New("sqlstore." + section),
+		skipEnsureDefaultOrgAndUser: true,
+		migrations:                  migrations,
+		dedicatedSection:            section,
+		dedicatedPath:               defaultPath,
+	}
+
+	if err := ss.initEngine(nil); err != nil {
+		return nil, errutil.Wrapf(err, "failed to connect to the database of [%s]", section)
+	}
+	ss.Dialect = migrator.NewDialect(ss.engine)
+
+	if err := ss.Migrate(); err != nil {
+		return nil, err
+	}
+
+	return ss, nil
+}
+
 func ProvideServiceForTests(migrations registry.DatabaseMigrator) (*SQLStore, error) {
 	return initTestDB(migrations, InitTestDBOpt{EnsureDefaultOrgAndUser: true})
 }
@@ -311,7 +340,8 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		return err
 	}
 
-	if ss.Cfg.IsDatabaseMetricsEnabled() {
+	// the drivers with hooks are registered once, by the main store
+	if ss.Cfg.IsDatabaseMetricsEnabled() && ss.dedicatedSection == "" {
 		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type)
 	}
 
@@ -358,7 +388,7 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	engine.SetConnMaxLifetime(time.Second * time.Duration(ss.dbCfg.ConnMaxLifetime))
 
 	// configure sql logging
-	debugSQL := ss.Cfg.Raw.Section("database").Key("log_queries").MustBool(false)
+	debugSQL := ss.Cfg.Raw.Section(ss.configSection()).Key("log_queries").MustBool(false)
 	if !debugSQL {
 		engine.SetLogger(&xorm.DiscardLogger{})
 	} else {
@@ -371,9 +401,21 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	return nil
 }
 
+// configSection returns the name of the configuration section of the database.
+func (ss *SQLStore) configSection() string {
+	if ss.dedicatedSection != "" {
+		return ss.dedicatedSection
+	}
+	return "database"
+}
+
 // readConfig initializes the SQLStore from its configuration.
 func (ss *SQLStore) readConfig() error {
-	sec := ss.Cfg.Raw.Section("database")
+	sec := ss.Cfg.Raw.Section(ss.configSection())
+	defaultPath := "data/grafana.db"
+	if ss.dedicatedPath != "" {
+		defaultPath = ss.dedicatedPath
+	}
 
 	cfgURL := sec.Key("url").String()
 	if len(cfgURL) != 0 {
@@ -414,7 +456,7 @@ func (ss *SQLStore) readConfig() error {
 	ss.dbCfg.ClientKeyPath = sec.Key("client_key_path").String()
 	ss.dbCfg.ClientCertPath = sec.Key("client_cert_path").String()
 	ss.dbCfg.ServerCertName = sec.Key("server_cert_name").String()
-	ss.dbCfg.Path = sec.Key("path").MustString("data/grafana.db")
+	ss.dbCfg.Path = sec.Key("path").MustString(defaultPath)
 	ss.dbCfg.IsolationLevel = sec.Key("isolation_level").String()
 
 	ss.dbCfg.CacheMode = sec.Key("cache_mode").MustString("private")
@@ -1,15 +1,185 @@
 package validations
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/setting"
 )
 
-type OSSPluginRequestValidator struct{}
+// ErrHostNotAllowed is returned when the URL of a data source points to a host which isn't allowed by the
+// configuration of the [datasources] section.
+var ErrHostNotAllowed = errors.New("data source host not allowed")
+
+// metadataHosts are the cloud instance metadata endpoints which aren't link-local, denied along with the link-local
+// addresses.
+var metadataHosts = []string{"metadata.google.internal", "100.100.100.200", "fd00:ec2::254"}
+
+// OSSPluginRequestValidator restricts the hosts data sources can point to, to mitigate server-side request forgery
+// through the data source URLs. The zero value allows every host.
+type OSSPluginRequestValidator struct {
+	cfg      *setting.Cfg
+	lookupIP func(host string) ([]net.IP, error)
+}
+
+func ProvideValidator(cfg *setting.Cfg) *OSSPluginRequestValidator {
+	return &OSSPluginRequestValidator{cfg: cfg, lookupIP: net.LookupIP}
+}
+
+// Validate checks the host of the data source URL against the allowed and denied hosts of the configuration. The
+// host is resolved when it needs to be matched against IP ranges, and all of its addresses must be allowed. URLs
+// without a host are denied.
+func (v *OSSPluginRequestValidator) Validate(dsURL string, _ *http.Request) error {
+	if v.cfg == nil || dsURL == "" {
+		return nil
+	}
+	allowed, denied := v.cfg.DataSourceAllowedHosts, v.cfg.DataSourceDeniedHosts
+	if len(allowed) == 0 && len(denied) == 0 && !v.cfg.DataSourceDenyLinkLocalHosts {
+		return nil
+	}
+
+	// fail closed, as the client of the data source could still determine a host from the URL
+	host := urlHost(dsURL)
+	if host == "" {
+		return fmt.Errorf("%w: failed to determine the host of the URL", ErrHostNotAllowed)
+	}
+	ips, err := v.resolve(host, allowed, denied)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve %s: %s", ErrHostNotAllowed, host, err)
+	}
+
+	if v.cfg.DataSourceDenyLinkLocalHosts && isLinkLocalOrMetadata(host, ips) {
+		return fmt.Errorf("%w: %s is a link-local or metadata address", ErrHostNotAllowed, host)
+	}
+	for _, entry := range denied {
+		if matchesHost(entry, host) || anyIPMatches(entry, ips) {
+			return fmt.Errorf("%w: %s is denied", ErrHostNotAllowed, host)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, entry := range allowed {
+		if matchesHost(entry, host) {
+			return nil
+		}
+	}
+	if len(ips) > 0 && allIPsMatch(allowed, ips) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not in the allowed hosts", ErrHostNotAllowed, host)
+}
+
+// resolve returns the addresses of the host, only looking them up when they are needed to match IP ranges.
+func (v *OSSPluginRequestValidator) resolve(host string, allowed, denied []string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if !v.cfg.DataSourceDenyLinkLocalHosts && !hasIPEntries(allowed) && !hasIPEntries(denied) {
+		return nil, nil
+	}
+	lookupIP := v.lookupIP
+	if lookupIP == nil {
+		lookupIP = net.LookupIP
+	}
+	return lookupIP(host)
+}
+
+// urlHost returns the host name of a data source URL, or an empty string if the URL is invalid or has no host. The
+// URLs of some data sources, e.g. SQL databases, have no scheme. The host name is normalized, in lowercase and without
+// the trailing dot of a fully qualified name, which resolves to the same host.
+func urlHost(dsURL string) string {
+	if !strings.Contains(dsURL, "://") {
+		dsURL = "//" + dsURL
+	}
+	u, err := url.Parse(dsURL)
+	if err != nil {
+		return ""
+	}
+	return normalizeHost(u.Hostname())
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func isLinkLocalOrMetadata(host string, ips []net.IP) bool {
+	for _, ip := range ips {
+		if ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			return true
+		}
+	}
+	for _, metadataHost := range metadataHosts {
+		if matchesHost(metadataHost, host) || anyIPMatches(metadataHost, ips) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost returns whether a host name entry, either a name or a wildcard like *.example.com, matches the host.
+func matchesHost(entry, host string) bool {
+	entry = normalizeHost(entry)
+	if strings.HasPrefix(entry, "*.") {
+		return strings.HasSuffix(host, entry[1:])
+	}
+	return entry == host
+}
+
+func hasIPEntries(entries []string) bool {
+	for _, entry := range entries {
+		if ipEntry(entry) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ipEntry returns the IP range of an entry, either a CIDR or a single IP, or nil if it's a host name.
+func ipEntry(entry string) *net.IPNet {
+	if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+		return ipNet
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
 
-func (*OSSPluginRequestValidator) Validate(string, *http.Request) error {
-	return nil
+func anyIPMatches(entry string, ips []net.IP) bool {
+	ipNet := ipEntry(entry)
+	if ipNet == nil {
+		return false
+	}
+	for _, ip := range ips {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
-func ProvideValidator() *OSSPluginRequestValidator {
-	return &OSSPluginRequestValidator{}
+func allIPsMatch(entries []string, ips []net.IP) bool {
+	for _, ip := range ips {
+		matched := false
+		for _, entry := range entries {
+			if anyIPMatches(entry, []net.IP{ip}) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
 }
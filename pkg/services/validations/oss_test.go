@@ -0,0 +1,94 @@
+package validations
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSSPluginRequestValidator_Validate(t *testing.T) {
+	newValidator := func(cfg *setting.Cfg) *OSSPluginRequestValidator {
+		v := ProvideValidator(cfg)
+		v.lookupIP = func(host string) ([]net.IP, error) {
+			switch host {
+			case "prometheus.internal":
+				return []net.IP{net.ParseIP("10.0.0.5")}, nil
+			case "rebind.example.com":
+				return []net.IP{net.ParseIP("10.0.0.6"), net.ParseIP("169.254.169.254")}, nil
+			case "logs.example.com":
+				return []net.IP{net.ParseIP("203.0.113.10")}, nil
+			case "metadata.google.internal":
+				return []net.IP{net.ParseIP("169.254.169.254")}, nil
+			}
+			return nil, errors.New("no such host")
+		}
+		return v
+	}
+
+	t.Run("Should allow every host by default", func(t *testing.T) {
+		require.NoError(t, (&OSSPluginRequestValidator{}).Validate("http://169.254.169.254", nil))
+		require.NoError(t, newValidator(setting.NewCfg()).Validate("http://169.254.169.254", nil))
+	})
+
+	t.Run("Should deny link-local and metadata hosts", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataSourceDenyLinkLocalHosts = true
+		v := newValidator(cfg)
+
+		for _, dsURL := range []string{"http://169.254.169.254/latest/meta-data", "http://[fe80::1]:8080",
+			"http://metadata.google.internal", "http://metadata.google.internal.", "http://100.100.100.200",
+			"http://rebind.example.com"} {
+			require.ErrorIs(t, v.Validate(dsURL, nil), ErrHostNotAllowed, dsURL)
+		}
+		require.NoError(t, v.Validate("http://prometheus.internal:9090", nil))
+		require.NoError(t, v.Validate("", nil))
+	})
+
+	t.Run("Should only allow the allowed hosts", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataSourceAllowedHosts = []string{"*.example.com", "10.0.0.0/24", "192.168.1.10"}
+		v := newValidator(cfg)
+
+		for _, dsURL := range []string{"https://logs.example.com", "https://logs.example.com.",
+			"http://prometheus.internal:9090", "192.168.1.10:3306", "http://10.0.0.20"} {
+			require.NoError(t, v.Validate(dsURL, nil), dsURL)
+		}
+		for _, dsURL := range []string{"https://example.org", "http://192.168.1.11", "http://unknown.internal",
+			"localhost:5432"} {
+			require.ErrorIs(t, v.Validate(dsURL, nil), ErrHostNotAllowed, dsURL)
+		}
+	})
+
+	t.Run("Should deny the denied hosts", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DataSourceAllowedHosts = []string{"10.0.0.0/8"}
+		cfg.DataSourceDeniedHosts = []string{"10.0.0.5", "secrets.internal"}
+		v := newValidator(cfg)
+
+		require.ErrorIs(t, v.Validate("http://prometheus.internal", nil), ErrHostNotAllowed)
+		require.ErrorIs(t, v.Validate("http://SECRETS.internal", nil), ErrHostNotAllowed)
+		require.ErrorIs(t, v.Validate("http://secrets.internal.", nil), ErrHostNotAllowed)
+		require.ErrorIs(t, v.Validate("http://SECRETS.internal.:8080/api", nil), ErrHostNotAllowed)
+		require.NoError(t, v.Validate("http://10.0.0.6", nil))
+	})
+
+	t.Run("Should deny the URLs without host when hosts are restricted", func(t *testing.T) {
+		for _, configure := range []func(cfg *setting.Cfg){
+			func(cfg *setting.Cfg) { cfg.DataSourceAllowedHosts = []string{"*.example.com"} },
+			func(cfg *setting.Cfg) { cfg.DataSourceDeniedHosts = []string{"secrets.internal"} },
+			func(cfg *setting.Cfg) { cfg.DataSourceDenyLinkLocalHosts = true },
+		} {
+			cfg := setting.NewCfg()
+			configure(cfg)
+			v := newValidator(cfg)
+
+			for _, dsURL := range []string{"http://[::1", "http://%zz", "http://", "http:///path", "file:///etc/passwd",
+				":9090", "http://."} {
+				require.ErrorIs(t, v.Validate(dsURL, nil), ErrHostNotAllowed, dsURL)
+			}
+		}
+	})
+}
@@ -52,6 +52,10 @@ const (
 	authProxySyncTTL = 60
 )
 
+// This constant corresponds to the default value for sort_order in the [panels] section of the .ini files
+// and defines the order of the built-in panels in the visualization picker.
+const defaultPanelsSortOrder = "timeseries,barchart,stat,gauge,bargauge,table,singlestat,piechart,state-timeline,heatmap,status-history,histogram,graph,text,alertlist,dashlist,news"
+
 // zoneInfo names environment variable for setting the path to look for the timezone database in go
 const zoneInfo = "ZONEINFO"
 
@@ -230,8 +234,13 @@ type Cfg struct {
 	ImagesDir                      string
 	CSVsDir                        string
 	RendererUrl                    string
+	RendererAuthToken              string
 	RendererCallbackUrl            string
 	RendererConcurrentRequestLimit int
+	RendererMaxConcurrentRenders   int
+	RendererQueueSize              int
+	RendererMaxRendersPerUser      int
+	RendererMaxRendersPerOrg       int
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -246,6 +255,13 @@ type Cfg struct {
 	StrictTransportSecurityMaxAge     int
 	StrictTransportSecurityPreload    bool
 	StrictTransportSecuritySubDomains bool
+	// CSRFTrustedOrigins are the hosts, besides the one of Grafana, state-changing plugin resource calls can
+	// originate from.
+	CSRFTrustedOrigins []string
+	// SecretsExpiryWarningPeriod is how long before their expiry date the secrets of data sources and plugins are
+	// reported as expiring, SecretsExpiryCheckInterval the interval of the warnings, which are disabled if it's zero.
+	SecretsExpiryWarningPeriod time.Duration
+	SecretsExpiryCheckInterval time.Duration
 	// CSPEnabled toggles Content Security Policy support.
 	CSPEnabled bool
 	// CSPTemplate contains the Content Security Policy template.
@@ -259,7 +275,41 @@ type Cfg struct {
 	PluginCatalogURL                 string
 	PluginAdminEnabled               bool
 	PluginAdminExternalManageEnabled bool
+	PluginDataSourceMetricLabels     bool
+	PluginDataSourceMetricLabelLimit int
+	PluginMetricsDurationBuckets     []float64
+	PluginMetricsDurationLabels      []string
+	PluginSlowQueryThreshold         time.Duration
+	PluginMaxQueryResponseSizeMB     int
+	PluginInstanceIdleTimeout        time.Duration
+	PluginInstanceEvictionHeapSizeMB int
+	PluginsBlockVulnerableInstalls   bool
+	PluginsBlockIncompatible         bool
+	PluginsBlockAngular              bool
+	PluginsRequireResourceRoutes     bool
+	PluginsRequireLiveChannels       bool
+	PluginsForbidEnvSecrets          bool
+	PluginsReadyRequired             []string
+	PluginsEncryptionRateLimit       int
+	PluginSettingsVersionsToKeep     int
+	PluginsVersionedAssetURLs        bool
+	PluginDevMode                    bool
+	PluginsDevPath                   string
+	PluginsDevWatchInterval          time.Duration
+	PluginsUnsignedPaths             []string
+	PluginsConformanceTests          bool
+	PluginsDisabled                  []string
+	PluginsCoreFailurePolicy         string
+	PluginsExternalFailurePolicy     string
+	PluginsInstallAllowList          []string
+	PluginsInstallDenyList           []string
+	PluginsDiskQuotaMB               int
+	PluginsDiskQuotaCheckInterval    time.Duration
+	PluginsDiskQuotaAction           string
+	PluginAssetStorage               PluginAssetStorageSettings
 	DisableSanitizeHtml              bool
+	PanelsSortOrder                  []string
+	PanelsHidden                     []string
 	EnterpriseLicensePath            string
 
 	// Metrics
@@ -290,6 +340,9 @@ type Cfg struct {
 	// Azure Cloud settings
 	Azure AzureSettings
 
+	// Branding
+	Branding Branding
+
 	// Auth proxy settings
 	AuthProxyEnabled          bool
 	AuthProxyHeaderName       string
@@ -360,6 +413,31 @@ type Cfg struct {
 	// Data sources
 	DataSourceLimit int
 
+	// DataSourcesExposeDirectAccessCredentials makes the frontend settings include the decrypted
+	// credentials of data sources with browser (direct) access. Deprecated.
+	DataSourcesExposeDirectAccessCredentials bool
+
+	// DataSourceHealthCheckInterval is the interval of the background health checks of the data sources, which are
+	// disabled if it's zero. DataSourceHealthCheckTimeout is the timeout of each health check.
+	DataSourceHealthCheckInterval time.Duration
+	DataSourceHealthCheckTimeout  time.Duration
+
+	// DataSourceTenants maps organization IDs to the tenant IDs sent in the tenant header of the data sources
+	// configured to use the mapping, from the [datasources.tenants] section.
+	DataSourceTenants map[int64]string
+
+	// DataSourceAllowedHosts and DataSourceDeniedHosts are the host names, IPs and CIDRs data sources can and can't
+	// point to. DataSourceDenyLinkLocalHosts denies the link-local addresses and the cloud metadata endpoints.
+	DataSourceAllowedHosts       []string
+	DataSourceDeniedHosts        []string
+	DataSourceDenyLinkLocalHosts bool
+
+	// Query history
+	QueryHistoryEnabled bool
+	// QueryHistoryRetention is how long the queries of the query history are kept, unless they're starred.
+	// The queries are kept forever if it's zero.
+	QueryHistoryRetention time.Duration
+
 	// Snapshots
 	SnapshotPublicMode bool
 
@@ -369,8 +447,14 @@ type Cfg struct {
 
 	// Analytics
 	CheckForUpdates                     bool
+	UpdateCheckGrafanaURL               string
+	UpdateCheckPluginsURL               string
+	UpdateCheckInterval                 time.Duration
+	UpdateCheckTimeout                  time.Duration
+	UpdateCheckAdvisoriesURL            string
 	ReportingDistributor                string
 	ReportingEnabled                    bool
+	ReportingPluginsEnabled             bool
 	ApplicationInsightsConnectionString string
 	ApplicationInsightsEndpointUrl      string
 
@@ -404,6 +488,18 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePluginSubscribeRateLimitPerUser and LivePluginSubscribeRateLimitPerChannel are the numbers of
+	// subscriptions per second allowed to channels of plugin and data source scope, per user and per channel.
+	// 0 disables the limit.
+	LivePluginSubscribeRateLimitPerUser    int
+	LivePluginSubscribeRateLimitPerChannel int
+	// LivePluginPublishRateLimitPerUser and LivePluginPublishRateLimitPerChannel are the numbers of publications
+	// per second allowed to channels of plugin and data source scope, per user and per channel. 0 disables the limit.
+	LivePluginPublishRateLimitPerUser    int
+	LivePluginPublishRateLimitPerChannel int
+	// LivePluginStreamReplayFrames is the number of the last frames of each plugin stream replayed to the new
+	// subscribers of its channel. 0 disables the replay.
+	LivePluginStreamReplayFrames int
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -422,6 +518,25 @@ type Cfg struct {
 	UnifiedAlerting UnifiedAlertingSettings
 }
 
+// IsPluginInstallAllowed returns true if the plugin isn't denied by [plugins] install_deny_list and, when
+// install_allow_list is set, is allowed by it.
+func (cfg Cfg) IsPluginInstallAllowed(pluginID string) bool {
+	for _, id := range cfg.PluginsInstallDenyList {
+		if id == pluginID {
+			return false
+		}
+	}
+	if len(cfg.PluginsInstallAllowList) == 0 {
+		return true
+	}
+	for _, id := range cfg.PluginsInstallAllowList {
+		if id == pluginID {
+			return true
+		}
+	}
+	return false
+}
+
 // IsLiveConfigEnabled returns true if live should be able to save configs to SQL tables
 func (cfg Cfg) IsLiveConfigEnabled() bool {
 	return cfg.FeatureToggles["live-config"]
@@ -895,11 +1010,20 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	analytics := iniFile.Section("analytics")
 	cfg.CheckForUpdates = analytics.Key("check_for_updates").MustBool(true)
+	cfg.UpdateCheckGrafanaURL = analytics.Key("update_check_grafana_url").MustString("https://raw.githubusercontent.com/grafana/grafana/main/latest.json")
+	cfg.UpdateCheckPluginsURL = analytics.Key("update_check_plugins_url").MustString("https://grafana.com/api/plugins/versioncheck")
+	cfg.UpdateCheckInterval = analytics.Key("update_check_interval").MustDuration(10 * time.Minute)
+	if cfg.UpdateCheckInterval <= 0 {
+		cfg.UpdateCheckInterval = 10 * time.Minute
+	}
+	cfg.UpdateCheckTimeout = analytics.Key("update_check_timeout").MustDuration(10 * time.Second)
+	cfg.UpdateCheckAdvisoriesURL = analytics.Key("update_check_advisories_url").MustString("")
 	GoogleAnalyticsId = analytics.Key("google_analytics_ua_id").String()
 	GoogleTagManagerId = analytics.Key("google_tag_manager_id").String()
 	RudderstackWriteKey = analytics.Key("rudderstack_write_key").String()
 	RudderstackDataPlaneUrl = analytics.Key("rudderstack_data_plane_url").String()
 	cfg.ReportingEnabled = analytics.Key("reporting_enabled").MustBool(true)
+	cfg.ReportingPluginsEnabled = analytics.Key("reporting_plugins_enabled").MustBool(true)
 	cfg.ReportingDistributor = analytics.Key("reporting_distributor").MustString("grafana-labs")
 	if len(cfg.ReportingDistributor) >= 100 {
 		cfg.ReportingDistributor = cfg.ReportingDistributor[:100]
@@ -917,8 +1041,14 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	explore := iniFile.Section("explore")
 	ExploreEnabled = explore.Key("enabled").MustBool(true)
 
+	if err := cfg.readQueryHistorySettings(); err != nil {
+		return err
+	}
+
 	panelsSection := iniFile.Section("panels")
 	cfg.DisableSanitizeHtml = panelsSection.Key("disable_sanitize_html").MustBool(false)
+	cfg.PanelsSortOrder = util.SplitString(panelsSection.Key("sort_order").MustString(defaultPanelsSortOrder))
+	cfg.PanelsHidden = util.SplitString(panelsSection.Key("hidden").MustString(""))
 
 	pluginsSection := iniFile.Section("plugins")
 	cfg.PluginsEnableAlpha = pluginsSection.Key("enable_alpha").MustBool(false)
@@ -932,6 +1062,45 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.PluginCatalogURL = pluginsSection.Key("plugin_catalog_url").MustString("https://grafana.com/grafana/plugins/")
 	cfg.PluginAdminEnabled = pluginsSection.Key("plugin_admin_enabled").MustBool(true)
 	cfg.PluginAdminExternalManageEnabled = pluginsSection.Key("plugin_admin_external_manage_enabled").MustBool(false)
+	cfg.PluginDataSourceMetricLabels = pluginsSection.Key("datasource_metric_labels").MustBool(false)
+	cfg.PluginDataSourceMetricLabelLimit = pluginsSection.Key("datasource_metric_label_limit").MustInt(100)
+	cfg.PluginMetricsDurationBuckets, err = pluginsSection.Key("metrics_duration_buckets").StrictFloat64s(",")
+	if err != nil {
+		return fmt.Errorf("invalid metrics_duration_buckets in [plugins]: %w", err)
+	}
+	cfg.PluginMetricsDurationLabels = util.SplitString(pluginsSection.Key("metrics_duration_labels").MustString(""))
+	cfg.PluginSlowQueryThreshold = pluginsSection.Key("slow_query_threshold").MustDuration(0)
+	cfg.PluginMaxQueryResponseSizeMB = pluginsSection.Key("max_query_response_size_mb").MustInt(0)
+	cfg.PluginInstanceIdleTimeout = pluginsSection.Key("instance_idle_timeout").MustDuration(30 * time.Minute)
+	cfg.PluginInstanceEvictionHeapSizeMB = pluginsSection.Key("instance_eviction_heap_size_mb").MustInt(0)
+	cfg.PluginsBlockVulnerableInstalls = pluginsSection.Key("block_vulnerable_installs").MustBool(false)
+	cfg.PluginsBlockIncompatible = pluginsSection.Key("block_incompatible_plugins").MustBool(false)
+	cfg.PluginsBlockAngular = pluginsSection.Key("block_angular_plugins").MustBool(false)
+	cfg.PluginsRequireResourceRoutes = pluginsSection.Key("require_resource_routes").MustBool(false)
+	cfg.PluginsRequireLiveChannels = pluginsSection.Key("require_live_channels").MustBool(false)
+	cfg.PluginsForbidEnvSecrets = pluginsSection.Key("forbid_env_secrets").MustBool(false)
+	cfg.PluginsReadyRequired = util.SplitString(pluginsSection.Key("ready_required_plugins").MustString(""))
+	cfg.PluginsEncryptionRateLimit = pluginsSection.Key("encryption_rate_limit").MustInt(100)
+	cfg.PluginSettingsVersionsToKeep = pluginsSection.Key("settings_versions_to_keep").MustInt(20)
+	cfg.PluginsVersionedAssetURLs = pluginsSection.Key("versioned_asset_urls").MustBool(false)
+	cfg.PluginDevMode = pluginsSection.Key("plugin_dev_mode").MustBool(false)
+	if devPath := valueAsString(pluginsSection, "dev_plugins_path", ""); devPath != "" {
+		cfg.PluginsDevPath = makeAbsolute(devPath, HomePath)
+	}
+	cfg.PluginsDevWatchInterval = pluginsSection.Key("dev_watch_interval").MustDuration(time.Second)
+	for _, path := range util.SplitString(pluginsSection.Key("unsigned_plugin_paths").MustString("")) {
+		cfg.PluginsUnsignedPaths = append(cfg.PluginsUnsignedPaths, makeAbsolute(path, HomePath))
+	}
+	cfg.PluginsConformanceTests = pluginsSection.Key("conformance_tests_enabled").MustBool(false)
+	cfg.PluginsDisabled = util.SplitString(pluginsSection.Key("disable").MustString(""))
+	failurePolicies := []string{"tolerate", "abort"}
+	cfg.PluginsCoreFailurePolicy = pluginsSection.Key("core_failure_policy").In("tolerate", failurePolicies)
+	cfg.PluginsExternalFailurePolicy = pluginsSection.Key("external_failure_policy").In("tolerate", failurePolicies)
+	cfg.PluginsInstallAllowList = util.SplitString(pluginsSection.Key("install_allow_list").MustString(""))
+	cfg.PluginsInstallDenyList = util.SplitString(pluginsSection.Key("install_deny_list").MustString(""))
+	cfg.PluginsDiskQuotaMB = pluginsSection.Key("disk_quota_mb").MustInt(0)
+	cfg.PluginsDiskQuotaCheckInterval = pluginsSection.Key("disk_quota_check_interval").MustDuration(time.Hour)
+	cfg.PluginsDiskQuotaAction = pluginsSection.Key("disk_quota_action").In("flag", []string{"flag", "stop"})
 
 	if err := cfg.readFeatureToggles(iniFile); err != nil {
 		return err
@@ -949,6 +1118,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.readLDAPConfig()
 	cfg.handleAWSConfig()
 	cfg.readAzureSettings()
+	cfg.readPluginAssetStorageSettings()
 	cfg.readSessionConfig()
 	cfg.readSmtpSettings()
 	cfg.readQuotaSettings()
@@ -1002,6 +1172,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.readDateFormats()
 	cfg.readSentryConfig()
+	cfg.readBrandingSettings()
 
 	if err := cfg.readLiveSettings(iniFile); err != nil {
 		return err
@@ -1171,6 +1342,7 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.StrictTransportSecurityMaxAge = security.Key("strict_transport_security_max_age_seconds").MustInt(86400)
 	cfg.StrictTransportSecurityPreload = security.Key("strict_transport_security_preload").MustBool(false)
 	cfg.StrictTransportSecuritySubDomains = security.Key("strict_transport_security_subdomains").MustBool(false)
+	cfg.CSRFTrustedOrigins = readCSRFTrustedOrigins(security.Key("csrf_trusted_origins").String())
 	cfg.CSPEnabled = security.Key("content_security_policy").MustBool(false)
 	cfg.CSPTemplate = security.Key("content_security_policy_template").MustString("")
 
@@ -1187,9 +1359,29 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.AdminUser = valueAsString(security, "admin_user", "")
 	cfg.AdminPassword = valueAsString(security, "admin_password", "")
 
+	encryption := iniFile.Section("security.encryption")
+	cfg.SecretsExpiryWarningPeriod = encryption.Key("expiry_warning_period").MustDuration(14 * 24 * time.Hour)
+	cfg.SecretsExpiryCheckInterval = encryption.Key("expiry_check_interval").MustDuration(24 * time.Hour)
+
 	return nil
 }
 
+// readCSRFTrustedOrigins returns the hosts of the comma or space separated list of trusted origins, which can be
+// either hosts or URLs.
+func readCSRFTrustedOrigins(value string) []string {
+	var origins []string
+	for _, origin := range util.SplitString(value) {
+		if origin == "" {
+			continue
+		}
+		if u, err := url.Parse(origin); err == nil && u.Host != "" {
+			origin = u.Host
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
 func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	auth := iniFile.Section("auth")
 
@@ -1345,6 +1537,7 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	renderSec := iniFile.Section("rendering")
 	cfg.RendererUrl = valueAsString(renderSec, "server_url", "")
+	cfg.RendererAuthToken = valueAsString(renderSec, "renderer_token", "")
 	cfg.RendererCallbackUrl = valueAsString(renderSec, "callback_url", "")
 
 	if cfg.RendererCallbackUrl == "" {
@@ -1361,6 +1554,10 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	}
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererMaxConcurrentRenders = renderSec.Key("max_concurrent_renders").MustInt(0)
+	cfg.RendererQueueSize = renderSec.Key("render_queue_size").MustInt(100)
+	cfg.RendererMaxRendersPerUser = renderSec.Key("max_renders_per_user").MustInt(0)
+	cfg.RendererMaxRendersPerOrg = renderSec.Key("max_renders_per_org").MustInt(0)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 
@@ -1485,6 +1682,38 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.DataSourcesExposeDirectAccessCredentials = datasources.Key("expose_direct_access_credentials").MustBool(false)
+	cfg.DataSourceHealthCheckInterval = datasources.Key("health_check_interval").MustDuration(0)
+	cfg.DataSourceHealthCheckTimeout = datasources.Key("health_check_timeout").MustDuration(30 * time.Second)
+	cfg.DataSourceAllowedHosts = util.SplitString(datasources.Key("allowed_hosts").String())
+	cfg.DataSourceDeniedHosts = util.SplitString(datasources.Key("denied_hosts").String())
+	cfg.DataSourceDenyLinkLocalHosts = datasources.Key("deny_link_local_hosts").MustBool(false)
+
+	cfg.DataSourceTenants = map[int64]string{}
+	for _, key := range cfg.Raw.Section("datasources.tenants").Keys() {
+		orgID, err := strconv.ParseInt(key.Name(), 10, 64)
+		if err != nil {
+			cfg.Logger.Warn("Invalid organization ID in [datasources.tenants], ignoring it", "key", key.Name())
+			continue
+		}
+		cfg.DataSourceTenants[orgID] = key.Value()
+	}
+	if cfg.DataSourcesExposeDirectAccessCredentials {
+		cfg.Logger.Warn("[Deprecated] the configuration setting 'expose_direct_access_credentials' is deprecated and will be removed in a future release, " +
+			"data sources with browser access and credentials are served through the data source proxy when it's disabled")
+	}
+}
+
+func (cfg *Cfg) readQueryHistorySettings() error {
+	queryHistory := cfg.Raw.Section("query_history")
+	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
+
+	retention, err := gtime.ParseDuration(valueAsString(queryHistory, "retention", "14d"))
+	if err != nil {
+		return fmt.Errorf("invalid query history retention: %w", err)
+	}
+	cfg.QueryHistoryRetention = retention
+	return nil
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1528,5 +1757,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LivePluginSubscribeRateLimitPerUser = section.Key("plugin_subscribe_rate_limit_per_user").MustInt(20)
+	cfg.LivePluginSubscribeRateLimitPerChannel = section.Key("plugin_subscribe_rate_limit_per_channel").MustInt(0)
+	cfg.LivePluginPublishRateLimitPerUser = section.Key("plugin_publish_rate_limit_per_user").MustInt(10)
+	cfg.LivePluginPublishRateLimitPerChannel = section.Key("plugin_publish_rate_limit_per_channel").MustInt(50)
+	cfg.LivePluginStreamReplayFrames = section.Key("plugin_stream_replay_frames").MustInt(0)
+	if cfg.LivePluginStreamReplayFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] plugin_stream_replay_frames", cfg.LivePluginStreamReplayFrames)
+	}
 	return nil
 }
@@ -0,0 +1,50 @@
+package setting
+
+import "github.com/grafana/grafana/pkg/util"
+
+// Branding contains the settings used to customize the look of a Grafana instance.
+type Branding struct {
+	AppTitle        string               `json:"appTitle"`
+	LoginTitle      string               `json:"loginTitle"`
+	LoginLogo       string               `json:"loginLogo"`
+	LoginBackground string               `json:"loginBackground"`
+	MenuLogo        string               `json:"menuLogo"`
+	FavIcon         string               `json:"favIcon"`
+	AppleTouchIcon  string               `json:"appleTouchIcon"`
+	FooterLinks     []BrandingFooterLink `json:"footerLinks"`
+}
+
+type BrandingFooterLink struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+	Icon string `json:"icon,omitempty"`
+}
+
+func (cfg *Cfg) readBrandingSettings() {
+	raw := cfg.Raw.Section("branding")
+	cfg.Branding = Branding{
+		AppTitle:        raw.Key("app_title").MustString(""),
+		LoginTitle:      raw.Key("login_title").MustString(""),
+		LoginLogo:       raw.Key("login_logo").MustString(""),
+		LoginBackground: raw.Key("login_background").MustString(""),
+		MenuLogo:        raw.Key("menu_logo").MustString(""),
+		FavIcon:         raw.Key("fav_icon").MustString(""),
+		AppleTouchIcon:  raw.Key("apple_touch_icon").MustString(""),
+		FooterLinks:     []BrandingFooterLink{},
+	}
+
+	// each footer link is configured with footer_links_<name>_text, footer_links_<name>_url
+	// and optionally footer_links_<name>_icon
+	for _, name := range util.SplitString(raw.Key("footer_links").MustString("")) {
+		link := BrandingFooterLink{
+			Text: raw.Key("footer_links_" + name + "_text").MustString(name),
+			URL:  raw.Key("footer_links_" + name + "_url").MustString(""),
+			Icon: raw.Key("footer_links_" + name + "_icon").MustString(""),
+		}
+		if link.URL == "" {
+			cfg.Logger.Warn("Skipping branding footer link without URL", "name", name)
+			continue
+		}
+		cfg.Branding.FooterLinks = append(cfg.Branding.FooterLinks, link)
+	}
+}
@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrandingSettings(t *testing.T) {
+	cfg := NewCfg()
+
+	brandingSection, err := cfg.Raw.NewSection("branding")
+	require.NoError(t, err)
+	for key, value := range map[string]string{
+		"app_title":                 "ACME Observability",
+		"fav_icon":                  "https://example.com/favicon.png",
+		"footer_links":              "support, docs missing",
+		"footer_links_support_text": "Support",
+		"footer_links_support_url":  "https://example.com/support",
+		"footer_links_support_icon": "question-circle",
+		"footer_links_docs_url":     "https://example.com/docs",
+	} {
+		_, err = brandingSection.NewKey(key, value)
+		require.NoError(t, err)
+	}
+
+	cfg.readBrandingSettings()
+
+	assert.Equal(t, "ACME Observability", cfg.Branding.AppTitle)
+	assert.Equal(t, "https://example.com/favicon.png", cfg.Branding.FavIcon)
+	assert.Empty(t, cfg.Branding.LoginLogo)
+	assert.Equal(t, []BrandingFooterLink{
+		{Text: "Support", URL: "https://example.com/support", Icon: "question-circle"},
+		{Text: "docs", URL: "https://example.com/docs"},
+	}, cfg.Branding.FooterLinks)
+}
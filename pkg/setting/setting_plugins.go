@@ -1,11 +1,24 @@
 package setting
 
 import (
+	"strconv"
 	"strings"
 
+	"github.com/grafana/grafana/pkg/util"
 	"gopkg.in/ini.v1"
 )
 
+// pluginFeatureTogglesSetting is the key of the plugin settings, i.e. of the [plugin.<plugin id>] section of the
+// configuration, with the comma-separated feature toggles of the plugin.
+const pluginFeatureTogglesSetting = "feature_toggles"
+
+// pluginDiskQuotaSetting is the key of the plugin settings with the disk quota of the plugin, in megabytes.
+const pluginDiskQuotaSetting = "disk_quota_mb"
+
+// pluginMaxQueryResponseSizeSetting is the key of the plugin settings with the maximum size of the response of each
+// query of the plugin, in megabytes.
+const pluginMaxQueryResponseSizeSetting = "max_query_response_size_mb"
+
 // PluginSettings maps plugin id to map of key/value settings.
 type PluginSettings map[string]map[string]string
 
@@ -23,3 +36,76 @@ func extractPluginSettings(sections []*ini.Section) PluginSettings {
 
 	return psMap
 }
+
+// PluginFeatureToggles returns the feature toggles enabled for the plugin, which gate its experimental features on
+// this instance. They're delivered to the frontend of the plugin in its meta, and to its backend in the
+// GF_PLUGIN_FEATURE_TOGGLES environment variable.
+func (cfg *Cfg) PluginFeatureToggles(pluginID string) []string {
+	return util.SplitString(cfg.PluginSettings[pluginID][pluginFeatureTogglesSetting])
+}
+
+// PluginDiskQuotaMB returns the disk quota of the plugin in megabytes, set with the disk_quota_mb setting of its
+// [plugin.<id>] section, or of the [plugins] section by default. 0 means the plugin has no quota.
+func (cfg *Cfg) PluginDiskQuotaMB(pluginID string) int {
+	if value, exists := cfg.PluginSettings[pluginID][pluginDiskQuotaSetting]; exists {
+		if quota, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && quota >= 0 {
+			return quota
+		}
+	}
+	return cfg.PluginsDiskQuotaMB
+}
+
+// PluginMaxQueryResponseSizeMB returns the maximum decoded size of the response of each query of the plugin in
+// megabytes, set with the max_query_response_size_mb setting of its [plugin.<id>] section, or of the [plugins] section
+// by default. 0 means the responses aren't limited.
+func (cfg *Cfg) PluginMaxQueryResponseSizeMB(pluginID string) int {
+	if value, exists := cfg.PluginSettings[pluginID][pluginMaxQueryResponseSizeSetting]; exists {
+		if size, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && size >= 0 {
+			return size
+		}
+	}
+	return cfg.PluginMaxQueryResponseSizeMB
+}
+
+// PluginAssetStorageSettings configures the object storage the frontend assets of the installed plugins are synced to,
+// and served from when they aren't in the plugins directory of the instance.
+type PluginAssetStorageSettings struct {
+	// Provider is s3, gcs or azure_blob, or empty to serve the assets from the plugins directory only.
+	Provider string
+	// Bucket is the bucket, or the container of Azure Blob Storage.
+	Bucket string
+	// Path is the prefix of the objects in the bucket.
+	Path string
+
+	// S3
+	Region          string
+	Endpoint        string
+	PathStyleAccess bool
+	AccessKey       string
+	SecretKey       string
+
+	// GCS
+	KeyFile string
+
+	// Azure Blob Storage
+	AccountName string
+	AccountKey  string
+}
+
+func (cfg *Cfg) readPluginAssetStorageSettings() {
+	section := cfg.Raw.Section("plugins.asset_storage")
+
+	cfg.PluginAssetStorage = PluginAssetStorageSettings{
+		Provider:        section.Key("provider").In("", []string{"", "s3", "gcs", "azure_blob"}),
+		Bucket:          section.Key("bucket").String(),
+		Path:            strings.Trim(section.Key("path").String(), "/"),
+		Region:          section.Key("region").String(),
+		Endpoint:        section.Key("endpoint").String(),
+		PathStyleAccess: section.Key("path_style_access").MustBool(false),
+		AccessKey:       section.Key("access_key").String(),
+		SecretKey:       section.Key("secret_key").String(),
+		KeyFile:         section.Key("key_file").String(),
+		AccountName:     section.Key("account_name").String(),
+		AccountKey:      section.Key("account_key").String(),
+	}
+}
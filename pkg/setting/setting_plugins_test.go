@@ -41,3 +41,45 @@ func TestPluginSettings(t *testing.T) {
 	require.Equal(t, ps["plugin2"]["key3"], "value3")
 	require.Equal(t, ps["plugin2"]["key4"], "value4")
 }
+
+func TestPluginFeatureToggles(t *testing.T) {
+	cfg := NewCfg()
+	cfg.PluginSettings = PluginSettings{
+		"plugin":  {"feature_toggles": "newEditor, streaming  liveTail"},
+		"plugin2": {"key": "value"},
+	}
+
+	require.Equal(t, []string{"newEditor", "streaming", "liveTail"}, cfg.PluginFeatureToggles("plugin"))
+	require.Empty(t, cfg.PluginFeatureToggles("plugin2"))
+	require.Empty(t, cfg.PluginFeatureToggles("unknown"))
+}
+
+func TestPluginDiskQuotaMB(t *testing.T) {
+	cfg := NewCfg()
+	cfg.PluginsDiskQuotaMB = 100
+	cfg.PluginSettings = PluginSettings{
+		"plugin":  {"disk_quota_mb": "500"},
+		"plugin2": {"disk_quota_mb": "0"},
+		"plugin3": {"disk_quota_mb": "invalid"},
+	}
+
+	require.Equal(t, 500, cfg.PluginDiskQuotaMB("plugin"))
+	require.Equal(t, 0, cfg.PluginDiskQuotaMB("plugin2"))
+	require.Equal(t, 100, cfg.PluginDiskQuotaMB("plugin3"))
+	require.Equal(t, 100, cfg.PluginDiskQuotaMB("unknown"))
+}
+
+func TestPluginMaxQueryResponseSizeMB(t *testing.T) {
+	cfg := NewCfg()
+	cfg.PluginMaxQueryResponseSizeMB = 10
+	cfg.PluginSettings = PluginSettings{
+		"plugin":  {"max_query_response_size_mb": "50"},
+		"plugin2": {"max_query_response_size_mb": "0"},
+		"plugin3": {"max_query_response_size_mb": "-1"},
+	}
+
+	require.Equal(t, 50, cfg.PluginMaxQueryResponseSizeMB("plugin"))
+	require.Equal(t, 0, cfg.PluginMaxQueryResponseSizeMB("plugin2"))
+	require.Equal(t, 10, cfg.PluginMaxQueryResponseSizeMB("plugin3"))
+	require.Equal(t, 10, cfg.PluginMaxQueryResponseSizeMB("unknown"))
+}
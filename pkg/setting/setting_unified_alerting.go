@@ -47,6 +47,8 @@ const (
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
 	schedulerDefaultMinInterval             = 10 * time.Second
+	systemAlertsDefaultOrgID                = 1
+	systemAlertsDefaultInterval             = time.Minute
 )
 
 type UnifiedAlertingSettings struct {
@@ -65,6 +67,9 @@ type UnifiedAlertingSettings struct {
 	DefaultConfiguration           string
 	Enabled                        bool
 	DisabledOrgs                   map[int64]struct{}
+	SystemAlertsEnabled            bool
+	SystemAlertsOrgID              int64
+	SystemAlertsInterval           time.Duration
 }
 
 // ReadUnifiedAlertingSettings reads both the `unified_alerting` and `alerting` sections of the configuration while preferring configuration the `alerting` section.
@@ -174,6 +179,13 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MinInterval = uaMinInterval
 
+	uaCfg.SystemAlertsEnabled = ua.Key("system_alerts_enabled").MustBool(false)
+	uaCfg.SystemAlertsOrgID = ua.Key("system_alerts_org_id").MustInt64(systemAlertsDefaultOrgID)
+	uaCfg.SystemAlertsInterval, err = gtime.ParseDuration(valueAsString(ua, "system_alerts_interval", systemAlertsDefaultInterval.String()))
+	if err != nil {
+		return err
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
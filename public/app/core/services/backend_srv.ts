@@ -161,6 +161,12 @@ export class BackendSrv implements BackendService {
         options.url = options.url.substring(1);
       }
 
+      const csrfToken = getConfig().csrfToken;
+      if (csrfToken && options.method && !['GET', 'HEAD', 'OPTIONS'].includes(options.method.toUpperCase())) {
+        options.headers = options.headers ?? {};
+        options.headers['X-Grafana-CSRF-Token'] = csrfToken;
+      }
+
       if (options.headers?.Authorization) {
         options.headers['X-DS-Authorization'] = options.headers.Authorization;
         delete options.headers.Authorization;
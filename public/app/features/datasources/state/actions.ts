@@ -1,6 +1,11 @@
 import { lastValueFrom } from 'rxjs';
 import { DataSourcePluginMeta, DataSourceSettings, locationUtil } from '@grafana/data';
-import { DataSourceWithBackend, getDataSourceSrv, locationService } from '@grafana/runtime';
+import {
+  DataSourceWithBackend,
+  getDataSourceSrv,
+  locationService,
+  resolveDataSourcePluginMeta,
+} from '@grafana/runtime';
 import { updateNavIndex } from 'app/core/actions';
 import { getBackendSrv } from 'app/core/services/backend_srv';
 import { getDatasourceSrv } from 'app/features/plugins/datasource_srv';
@@ -284,7 +289,9 @@ function updateFrontendSettings() {
   return getBackendSrv()
     .get('/api/frontend/settings')
     .then((settings: any) => {
+      resolveDataSourcePluginMeta(settings);
       config.datasources = settings.datasources;
+      config.datasourcesByUid = settings.datasourcesByUid;
       config.defaultDatasource = settings.defaultDatasource;
       getDatasourceSrv().init(config.datasources, settings.defaultDatasource);
     });
@@ -1,5 +1,5 @@
 import { getBackendSrv } from '@grafana/runtime';
-import { PluginError, renderMarkdown } from '@grafana/data';
+import { PluginError } from '@grafana/data';
 import { API_ROOT, GRAFANA_API_ROOT } from './constants';
 import { mergeLocalAndRemote } from './helpers';
 import {
@@ -23,10 +23,10 @@ export async function getPluginDetails(id: string): Promise<CatalogPluginDetails
   const localPlugins = await getLocalPlugins();
   const local = localPlugins.find((p) => p.id === id);
   const isInstalled = Boolean(local);
-  const [remote, versions, localReadme] = await Promise.all([
+  const [remote, versions, readme] = await Promise.all([
     getRemotePlugin(id, isInstalled),
     getPluginVersions(id),
-    getLocalPluginReadme(id),
+    getPluginReadme(id),
   ]);
   const dependencies = remote?.json?.dependencies;
   // Prepend semver range when we fallback to grafanaVersion (deprecated in favour of grafanaDependency)
@@ -41,13 +41,13 @@ export async function getPluginDetails(id: string): Promise<CatalogPluginDetails
     grafanaDependency,
     pluginDependencies: dependencies?.plugins || [],
     links: remote?.json?.info.links || local?.info.links || [],
-    readme: localReadme || remote?.readme,
+    readme: readme || remote?.readme,
     versions,
   };
 }
 
 export async function getRemotePlugins(): Promise<RemotePlugin[]> {
-  const res = await getBackendSrv().get(`${GRAFANA_API_ROOT}/plugins`);
+  const res = await getBackendSrv().get(`${API_ROOT}/catalog`);
   return res.items;
 }
 
@@ -99,12 +99,12 @@ async function getPluginVersions(id: string): Promise<Version[]> {
   }
 }
 
-async function getLocalPluginReadme(id: string): Promise<string> {
+// The README of the installed plugin, or else of the plugin in the catalog, rendered and sanitized by the backend.
+async function getPluginReadme(id: string): Promise<string> {
   try {
-    const markdown: string = await getBackendSrv().get(`${API_ROOT}/${id}/markdown/help`);
-    const markdownAsHtml = markdown ? renderMarkdown(markdown) : '';
+    const doc: { content: string } = await getBackendSrv().get(`${API_ROOT}/${id}/docs/readme`);
 
-    return markdownAsHtml;
+    return doc.content;
   } catch (error) {
     error.isHandled = true;
     return '';
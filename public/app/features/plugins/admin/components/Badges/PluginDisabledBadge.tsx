@@ -17,6 +17,10 @@ function errorCodeToTooltip(error?: PluginErrorCode): string | undefined {
       return 'Plugin disabled due to invalid plugin signature';
     case PluginErrorCode.missingSignature:
       return 'Plugin disabled due to missing plugin signature';
+    case PluginErrorCode.incompatibleGrafanaVersion:
+      return 'Plugin disabled as it is not compatible with this version of Grafana';
+    case PluginErrorCode.angularNotSupported:
+      return 'Plugin disabled as AngularJS plugins are blocked';
     default:
       return `Plugin disabled due to unkown error: ${error}`;
   }
@@ -64,6 +64,21 @@ function renderDescriptionFromError(error?: PluginErrorCode): React.ReactElement
           version of this plugin.
         </p>
       );
+    case PluginErrorCode.incompatibleGrafanaVersion:
+      return (
+        <p>
+          This plugin declares that it is not compatible with this version of Grafana, and incompatible plugins are
+          blocked by the configuration of Grafana. We recommend you to install a version of this plugin compatible with
+          this version of Grafana.
+        </p>
+      );
+    case PluginErrorCode.angularNotSupported:
+      return (
+        <p>
+          This plugin is based on AngularJS, which is deprecated and blocked by the configuration of Grafana. We
+          recommend you to install a version of this plugin which does not rely on AngularJS.
+        </p>
+      );
     default:
       return (
         <p>
@@ -80,8 +80,11 @@ export type RemotePlugin = {
   description: string;
   downloads: number;
   downloadSlug: string;
+  enabled?: boolean;
   featured: number;
+  hasUpdate?: boolean;
   id: number;
+  installedVersion?: string;
   internal: boolean;
   json?: {
     dependencies: PluginDependencies;
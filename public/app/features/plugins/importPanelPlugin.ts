@@ -30,7 +30,7 @@ export function importPanelPluginFromMeta(meta: grafanaData.PanelPluginMeta): Pr
 }
 
 function getPanelPlugin(meta: grafanaData.PanelPluginMeta): Promise<grafanaData.PanelPlugin> {
-  return importPluginModule(meta.module)
+  return importPluginModule(meta.module, meta.moduleIntegrity)
     .then((pluginExports) => {
       if (pluginExports.plugin) {
         return pluginExports.plugin as grafanaData.PanelPlugin;
@@ -0,0 +1,10 @@
+import { getBackendSrv } from '@grafana/runtime';
+import { NavModelItem } from '@grafana/data';
+
+/**
+ * Returns the navigation of an app plugin built by the server from the pages and dashboards it includes, with only
+ * the ones the signed in user has access to.
+ */
+export function getAppPluginNav(pluginId: string): Promise<NavModelItem> {
+  return getBackendSrv().get(`/api/plugins/${pluginId}/nav`);
+}
@@ -50,10 +50,12 @@ import * as rxjsOperators from 'rxjs/operators';
 // routing
 import * as reactRouter from 'react-router-dom';
 
-// add cache busting
+// add cache busting, the assets of plugins with a version are cached until it changes
 const bust = `?_cache=${Date.now()}`;
 function locate(load: { address: string }) {
-  return load.address + bust;
+  const match = load.address.match(/\/public\/plugins\/([^/]+)\//);
+  const version = match && config.pluginAssetVersions?.[match[1]];
+  return load.address + (version ? `?_v=${version}` : bust);
 }
 
 grafanaRuntime.SystemJS.registry.set('plugin-loader', grafanaRuntime.SystemJS.newModule({ locate: locate }));
@@ -174,7 +176,7 @@ for (const flotDep of flotDeps) {
   exposeToPlugin(flotDep, { fakeDep: 1 });
 }
 
-export async function importPluginModule(path: string): Promise<any> {
+export async function importPluginModule(path: string, integrity?: string): Promise<any> {
   const builtIn = builtInPlugins[path];
   if (builtIn) {
     // for handling dynamic imports
@@ -184,11 +186,16 @@ export async function importPluginModule(path: string): Promise<any> {
       return Promise.resolve(builtIn);
     }
   }
+  // the module is verified against the hash computed by the backend, e.g. when it's served by a CDN
+  if (integrity) {
+    const key = path.endsWith('.js') ? path : `${path}.js`;
+    grafanaRuntime.SystemJS.config({ meta: { [key]: { integrity } } });
+  }
   return grafanaRuntime.SystemJS.import(path);
 }
 
 export function importDataSourcePlugin(meta: grafanaData.DataSourcePluginMeta): Promise<GenericDataSourcePlugin> {
-  return importPluginModule(meta.module).then((pluginExports) => {
+  return importPluginModule(meta.module, meta.moduleIntegrity).then((pluginExports) => {
     if (pluginExports.plugin) {
       const dsPlugin = pluginExports.plugin as GenericDataSourcePlugin;
       dsPlugin.meta = meta;
@@ -211,7 +218,7 @@ export function importDataSourcePlugin(meta: grafanaData.DataSourcePluginMeta):
 }
 
 export function importAppPlugin(meta: grafanaData.PluginMeta): Promise<grafanaData.AppPlugin> {
-  return importPluginModule(meta.module).then((pluginExports) => {
+  return importPluginModule(meta.module, meta.moduleIntegrity).then((pluginExports) => {
     const plugin = pluginExports.plugin ? (pluginExports.plugin as grafanaData.AppPlugin) : new grafanaData.AppPlugin();
     plugin.init(meta);
     plugin.meta = meta;
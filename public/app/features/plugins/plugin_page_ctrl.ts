@@ -2,6 +2,7 @@ import angular from 'angular';
 import { find } from 'lodash';
 
 import { getPluginSettings } from './PluginSettingsCache';
+import { getAppPluginNav } from './pluginNav';
 import { PluginMeta, AppEvents } from '@grafana/data';
 import { NavModelSrv } from 'app/core/core';
 import { GrafanaRootScope } from 'app/routes/GrafanaCtrl';
@@ -45,17 +46,31 @@ export class AppPageCtrl {
       return;
     }
 
-    const pluginNav = this.navModelSrv.getNav('plugin-page-' + app.id);
-
-    this.navModel = {
-      main: {
-        img: app.info.logos.large,
-        subTitle: app.name,
-        url: '',
-        text: this.page.name,
-        breadcrumbs: [{ title: app.name, url: pluginNav.main.url }],
-      },
-    };
+    promiseToDigest(this.$rootScope)(
+      getAppPluginNav(app.id)
+        .then((appNav) => {
+          const pageNav = appNav.children?.find((child) => child.id === `plugin-page-${app.id}-${this.page.slug}`);
+          // the pages the user's role doesn't give access to aren't part of the navigation
+          if (!pageNav) {
+            this.$rootScope.appEvent(AppEvents.alertError, ['App Page Not Found']);
+            this.navModel = this.navModelSrv.getNotFoundNav();
+            return;
+          }
+
+          this.navModel = {
+            main: {
+              img: app.info.logos.large,
+              subTitle: app.name,
+              url: '',
+              text: pageNav.text,
+              breadcrumbs: [{ title: appNav.text, url: appNav.url }],
+            },
+          };
+        })
+        .catch(() => {
+          this.navModel = this.navModelSrv.getNotFoundNav();
+        })
+    );
   }
 }
 